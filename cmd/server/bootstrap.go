@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"backoffice/backend/internal/config"
+	approvaldomain "backoffice/backend/internal/domain/approval"
+	"backoffice/backend/internal/domain/event"
+	paymentdomain "backoffice/backend/internal/domain/payment"
+	retentiondomain "backoffice/backend/internal/domain/retention"
+	searchdomain "backoffice/backend/internal/domain/search"
+	shipmentdomain "backoffice/backend/internal/domain/shipment"
+	trashdomain "backoffice/backend/internal/domain/trash"
+	"backoffice/backend/internal/errreport"
+	"backoffice/backend/internal/httpserver"
+	"backoffice/backend/internal/infrastructure/broker"
+	"backoffice/backend/internal/infrastructure/carrier"
+	"backoffice/backend/internal/infrastructure/connector"
+	"backoffice/backend/internal/infrastructure/fieldcrypt"
+	"backoffice/backend/internal/infrastructure/mail"
+	"backoffice/backend/internal/infrastructure/notify"
+	"backoffice/backend/internal/infrastructure/payment"
+	"backoffice/backend/internal/infrastructure/pdf"
+	"backoffice/backend/internal/infrastructure/postgres"
+	"backoffice/backend/internal/infrastructure/sentry"
+	"backoffice/backend/internal/infrastructure/token"
+	alertusecase "backoffice/backend/internal/usecase/alert"
+	approvalusecase "backoffice/backend/internal/usecase/approval"
+	authusecase "backoffice/backend/internal/usecase/auth"
+	backupusecase "backoffice/backend/internal/usecase/backup"
+	customerusecase "backoffice/backend/internal/usecase/customer"
+	invoiceusecase "backoffice/backend/internal/usecase/invoice"
+	notificationusecase "backoffice/backend/internal/usecase/notification"
+	orderusecase "backoffice/backend/internal/usecase/order"
+	paymentusecase "backoffice/backend/internal/usecase/payment"
+	productusecase "backoffice/backend/internal/usecase/product"
+	quotausecase "backoffice/backend/internal/usecase/quota"
+	retentionusecase "backoffice/backend/internal/usecase/retention"
+	searchusecase "backoffice/backend/internal/usecase/search"
+	shipmentusecase "backoffice/backend/internal/usecase/shipment"
+	stocksyncusecase "backoffice/backend/internal/usecase/stocksync"
+	trashusecase "backoffice/backend/internal/usecase/trash"
+	userusecase "backoffice/backend/internal/usecase/user"
+	watchusecase "backoffice/backend/internal/usecase/watch"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
+)
+
+// loadConfig loads the configuration and logs the effective settings.
+func loadConfig() (config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.Config{}, fmt.Errorf("loading config: %w", err)
+	}
+	log.Printf("effective configuration:")
+	for _, line := range cfg.Summary() {
+		log.Printf("  %s", line)
+	}
+	for _, warning := range cfg.Warnings {
+		log.Printf("config warning: %s", warning)
+	}
+	return cfg, nil
+}
+
+// setupErrorReporting installs the Sentry reporter when a DSN is configured.
+// The returned function flushes pending reports and must be called on exit.
+func setupErrorReporting(cfg config.Config) (func(), error) {
+	if cfg.SentryDSN == "" {
+		return func() {}, nil
+	}
+	reporter, err := sentry.New(sentry.Options{DSN: cfg.SentryDSN, Environment: cfg.Environment, Release: cfg.Release})
+	if err != nil {
+		return nil, fmt.Errorf("configuring error reporting: %w", err)
+	}
+	errreport.SetDefault(reporter)
+	return func() { reporter.Flush(5 * time.Second) }, nil
+}
+
+// openDatabase connects the pool with the configured tuning.
+func openDatabase(ctx context.Context, cfg config.Config) (*postgres.Database, error) {
+	db, err := postgres.New(ctx, cfg.DatabaseURL, postgres.PoolOptions{
+		MaxConns:           int32(cfg.DatabasePool.MaxConns),
+		MinConns:           int32(cfg.DatabasePool.MinConns),
+		MaxConnLifetime:    cfg.DatabasePool.MaxConnLifetime,
+		MaxConnIdleTime:    cfg.DatabasePool.MaxConnIdleTime,
+		HealthCheckPeriod:  cfg.DatabasePool.HealthCheckPeriod,
+		AcquireTimeout:     cfg.DatabasePool.AcquireTimeout,
+		StatementCacheMode: cfg.DatabasePool.StatementCacheMode,
+		SlowQueryThreshold: cfg.QueryLog.SlowThreshold,
+		LogQueries:         cfg.QueryLog.All,
+		Retry: postgres.RetryPolicy{
+			Attempts:       cfg.DatabasePool.RetryAttempts,
+			InitialBackoff: cfg.DatabasePool.RetryBackoff,
+			MaxBackoff:     cfg.DatabasePool.RetryMaxBackoff,
+		},
+		ApplicationName: cfg.DatabasePool.ApplicationName,
+		TagRequests:     cfg.DatabasePool.TagRequests,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to database: %w", err)
+	}
+	return db, nil
+}
+
+// newEventBus fans domain events out to subscribers (webhooks, the activity
+// log, notifications) and the configured broker. closeBus flushes the
+// broker's queue.
+func newEventBus(cfg config.Config, subscribers ...event.Publisher) (events *event.Bus, closeBus func(), err error) {
+	if cfg.Events.Broker == "none" {
+		return event.NewBus(subscribers...), func() {}, nil
+	}
+	publisher, err := newBrokerPublisher(cfg.Events)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configuring event broker: %w", err)
+	}
+	closeBus = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		publisher.Close(ctx)
+	}
+	return event.NewBus(append(subscribers, publisher)...), closeBus, nil
+}
+
+// newBrokerPublisher builds the CloudEvents publisher for the configured
+// broker.
+func newBrokerPublisher(cfg config.EventBrokerConfig) (*broker.Publisher, error) {
+	var sender broker.Sender
+	var err error
+	switch cfg.Broker {
+	case "nats":
+		sender, err = broker.NewNATSSender(cfg.NATSURL, cfg.SubjectPrefix)
+	case "kafka":
+		sender, err = broker.NewKafkaRESTSender(cfg.KafkaRESTURL, cfg.KafkaTopic)
+	default:
+		err = fmt.Errorf("unknown broker %q", cfg.Broker)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return broker.NewPublisher(sender, cfg.Source, cfg.QueueSize), nil
+}
+
+// newTokenManager builds the configured token backend. The signed formats
+// share the issuer, audience and expiry settings; opaque tokens only use the
+// expiry.
+func newTokenManager(cfg config.Config, db *postgres.Database) (authusecase.TokenManager, error) {
+	switch cfg.TokenFormat {
+	case "opaque":
+		return token.NewOpaqueManager(postgres.NewSessionRepository(db.Retrying()), cfg.JWTExpiry), nil
+	case "paseto":
+		key, err := hex.DecodeString(cfg.PASETOKey)
+		if err != nil {
+			return nil, fmt.Errorf("decoding PASETO_KEY: %w", err)
+		}
+		return token.NewPASETOManager(key, cfg.JWTExpiry, cfg.JWTIssuer, cfg.JWTAudience)
+	default:
+		return token.NewJWTManager(cfg.JWTSecret, cfg.JWTExpiry, cfg.JWTIssuer, cfg.JWTAudience), nil
+	}
+}
+
+// newPIIKeyring builds the keyring customer phone numbers and addresses are
+// encrypted with, or nil when no keys are configured.
+func newPIIKeyring(keys []config.PIIKey) (*fieldcrypt.Keyring, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	ring := make([]fieldcrypt.Key, len(keys))
+	for i, key := range keys {
+		ring[i] = fieldcrypt.Key{ID: key.ID, Secret: key.Secret}
+	}
+	return fieldcrypt.NewKeyring(ring...)
+}
+
+// newTrashService wires the trash over every repository with soft deletes.
+func newTrashService(db *postgres.Database) *trashusecase.Service {
+	return trashusecase.NewService(map[string]trashdomain.Bin{
+		trashdomain.KindUser:    postgres.NewUserRepository(db.Retrying()),
+		trashdomain.KindProduct: postgres.NewProductRepository(db.Retrying()),
+	})
+}
+
+// newRetentionService enforces the retention policies over the audit trail,
+// the trash and the session store, defaulting to the configured retention.
+func newRetentionService(cfg config.Config, db *postgres.Database, trash *trashusecase.Service) *retentionusecase.Service {
+	return retentionusecase.NewService(postgres.NewRetentionRepository(db.Retrying()), map[string]retentiondomain.Purger{
+		retentiondomain.TargetActivity: postgres.NewActivityRepository(db.Retrying()),
+		retentiondomain.TargetTrash:    trash,
+		retentiondomain.TargetSessions: postgres.NewSessionRepository(db.Retrying()),
+	}, map[string]int{
+		retentiondomain.TargetActivity: config.RetentionDays(cfg.Retention.Activity),
+		retentiondomain.TargetTrash:    config.RetentionDays(cfg.Retention.Trash),
+		retentiondomain.TargetSessions: config.RetentionDays(cfg.Retention.Sessions),
+	})
+}
+
+// newApprovalService holds the configured actions for a second admin and
+// registers how each one runs once approved.
+func newApprovalService(cfg config.Config, db *postgres.Database, users *userusecase.Service, products *productusecase.Service) *approvalusecase.Service {
+	approvals := approvalusecase.NewService(postgres.NewApprovalRepository(db.Retrying()), cfg.Approvals.Actions, cfg.Approvals.TTL)
+	approvals.Register(approvaldomain.ActionDeleteUser, func(ctx context.Context, payload json.RawMessage) error {
+		// Bulk deletes wait for the same approval with the list of ids.
+		var target struct {
+			ID   string   `json:"id"`
+			IDs  []string `json:"ids"`
+			Mode string   `json:"mode"`
+		}
+		if err := json.Unmarshal(payload, &target); err != nil {
+			return err
+		}
+		if len(target.IDs) > 0 {
+			_, err := users.BulkDelete(ctx, trashdomain.BulkDelete{IDs: target.IDs})
+			return err
+		}
+		return users.Delete(ctx, target.ID, userusecase.DeleteMode(target.Mode))
+	})
+	approvals.Register(approvaldomain.ActionBulkPriceUpdate, func(ctx context.Context, payload json.RawMessage) error {
+		var input productusecase.BulkPriceInput
+		if err := json.Unmarshal(payload, &input); err != nil {
+			return err
+		}
+		_, err := products.BulkUpdatePrices(ctx, input, false)
+		return err
+	})
+	return approvals
+}
+
+// newSearchService wires the global search over the searchable repositories.
+func newSearchService(db *postgres.Database) *searchusecase.Service {
+	return searchusecase.NewService(map[string]searchdomain.Source{
+		searchdomain.GroupProducts:   postgres.NewProductRepository(db.Retrying()),
+		searchdomain.GroupUsers:      postgres.NewUserRepository(db.Retrying()),
+		searchdomain.GroupCategories: postgres.NewCategoryRepository(db.Retrying()),
+	})
+}
+
+// newQuotaService counts requests for every TOKEN_CLIENTS client.
+func newQuotaService(cfg config.Config, db *postgres.Database) *quotausecase.Service {
+	quotas := quotausecase.NewService(postgres.NewQuotaRepository(db.Retrying()))
+	configureQuotas(quotas, cfg)
+	return quotas
+}
+
+// configureQuotas applies the client limits and enforcement mode; clients
+// without a configured limit are counted but not limited.
+func configureQuotas(quotas *quotausecase.Service, cfg config.Config) {
+	limits := make(map[string]int64, len(cfg.TokenClients))
+	for client := range cfg.TokenClients {
+		limits[client] = cfg.Quotas.Limits[client]
+	}
+	quotas.SetLimits(limits)
+	quotas.SetEnforcement(cfg.Quotas.Block())
+}
+
+// newBackupService wires export and restore over the cloned repositories.
+func newBackupService(db *postgres.Database) *backupusecase.Service {
+	return backupusecase.NewService(
+		postgres.NewUserRepository(db.Retrying()),
+		postgres.NewCategoryRepository(db.Retrying()),
+		postgres.NewProductRepository(db.Retrying()),
+		postgres.NewWebhookRepository(db.Retrying()),
+	)
+}
+
+// newSyncService wires the configured product sync connectors.
+func newSyncService(cfg config.Config, db *postgres.Database, products *productusecase.Service) (*stocksyncusecase.Service, error) {
+	connectors := make([]stocksyncusecase.Connector, 0, len(cfg.Sync.Connectors))
+	for _, c := range cfg.Sync.Connectors {
+		source, err := connector.New(c.Type, c.Options)
+		if err != nil {
+			return nil, fmt.Errorf("sync connector %q: %w", c.Name, err)
+		}
+		mapping, err := stocksyncusecase.NewMapping(c.Mapping)
+		if err != nil {
+			return nil, fmt.Errorf("sync connector %q: %w", c.Name, err)
+		}
+		connectors = append(connectors, stocksyncusecase.Connector{
+			Name:     c.Name,
+			Type:     c.Type,
+			Interval: c.Interval,
+			Mapping:  mapping,
+			Source:   source,
+		})
+	}
+	return stocksyncusecase.NewService(postgres.NewSyncRunRepository(db.Retrying()), products, connectors, cfg.Sync.Timeout), nil
+}
+
+// newNotificationService delivers to the chat channels admins configured,
+// bounding each post by the webhook timeout.
+func newNotificationService(cfg config.Config, db *postgres.Database) *notificationusecase.Service {
+	return notificationusecase.NewService(postgres.NewNotificationChannelRepository(db.Retrying()), notify.NewSender(cfg.Webhooks.Timeout))
+}
+
+// newWatchService keeps watch notifications in the database and also emails
+// them through the configured SMTP server, if any.
+func newWatchService(cfg config.Config, db *postgres.Database) *watchusecase.Service {
+	watches := watchusecase.NewService(postgres.NewWatchRepository(db.Retrying()), postgres.NewProductRepository(db.Retrying()))
+	if cfg.Mail.Host != "" {
+		watches.SetSender(mail.NewSMTPSender(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From), postgres.NewUserRepository(db.Retrying()))
+	}
+	return watches
+}
+
+// newAlertService wires the business metrics job to Slack when a webhook is
+// configured.
+func newAlertService(cfg config.Config, products *productusecase.Service) (*alertusecase.Service, error) {
+	thresholds := alertusecase.Thresholds{
+		FailedLoginsPerHour:     cfg.Alerts.FailedLoginsPerHour,
+		LowStockProducts:        cfg.Alerts.LowStockProducts,
+		MinOrdersPerHour:        cfg.Alerts.MinOrdersPerHour,
+		MinRegistrationsPerHour: cfg.Alerts.MinRegistrationsPerHour,
+		SLOWindow:               cfg.Alerts.SLOWindow,
+		SLOBurnRate:             cfg.Alerts.SLOBurnRate,
+		SLOMinRequests:          cfg.Alerts.SLOMinRequests,
+	}
+	// The request counters live in httpserver and are only incremented in
+	// the serving process, which is where this job runs.
+	for _, slo := range cfg.Alerts.SLOs {
+		thresholds.SLOs = append(thresholds.SLOs, alertusecase.SLO{
+			Name:        slo.Prefix,
+			Objective:   slo.Objective,
+			Requests:    func() float64 { return httpserver.SLORequests.Value(slo.Prefix) },
+			BadRequests: func() float64 { return httpserver.SLOBadRequests.Value(slo.Prefix) },
+		})
+	}
+	if cfg.Alerts.SlackWebhookURL == "" {
+		return alertusecase.NewService(products, cfg.Alerts.LowStockQuantity, thresholds, nil), nil
+	}
+	slack, err := notify.NewSlack(cfg.Alerts.SlackWebhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return alertusecase.NewService(products, cfg.Alerts.LowStockQuantity, thresholds, slack), nil
+}
+
+// newInvoiceService prints invoices with the configured company details and
+// emails them through the configured SMTP server, if any.
+func newInvoiceService(cfg config.Config, db *postgres.Database, orders *orderusecase.Service, customers *customerusecase.Service) (*invoiceusecase.Service, error) {
+	company := invoiceusecase.Company{
+		Name:    cfg.Invoices.CompanyName,
+		Address: cfg.Invoices.CompanyAddress,
+		TaxID:   cfg.Invoices.CompanyTaxID,
+		Email:   cfg.Invoices.CompanyEmail,
+		Phone:   cfg.Invoices.CompanyPhone,
+	}
+	invoices, err := invoiceusecase.NewService(orders, customers, postgres.NewAttachmentStore(db.Retrying()), pdf.Text, company, cfg.Invoices.Template)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Mail.Host != "" {
+		invoices.SetSender(mail.NewSMTPSender(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From))
+	}
+	return invoices, nil
+}
+
+// newPaymentService takes payments with the configured providers, or
+// returns nil when none are configured.
+func newPaymentService(cfg config.Config, db *postgres.Database, orders *orderusecase.Service) *paymentusecase.Service {
+	if len(cfg.Payments.Providers) == 0 {
+		return nil
+	}
+	var providers []paymentdomain.Provider
+	for _, name := range cfg.Payments.Providers {
+		switch name {
+		case "mock":
+			providers = append(providers, payment.NewMock(cfg.Payments.MockSecret))
+		case "stripe":
+			providers = append(providers, payment.NewStripe(cfg.Payments.StripeSecretKey, cfg.Payments.StripeWebhookSecret, cfg.Payments.SuccessURL, cfg.Payments.CancelURL))
+		}
+	}
+	return paymentusecase.NewService(postgres.NewPaymentRepository(db.Retrying()), orders, cfg.Payments.Currency, providers...)
+}
+
+// newShipmentService builds the shipment service from the configured
+// carriers, or returns nil when shipping is off.
+func newShipmentService(cfg config.Config, db *postgres.Database, orders *orderusecase.Service, customers *customerusecase.Service) *shipmentusecase.Service {
+	if len(cfg.Shipping.Carriers) == 0 {
+		return nil
+	}
+	var carriers []shipmentdomain.Carrier
+	for _, name := range cfg.Shipping.Carriers {
+		switch name {
+		case "mock":
+			carriers = append(carriers, carrier.NewMock(cfg.Shipping.MockSecret, cfg.Shipping.MockStep))
+		case "easypost":
+			carriers = append(carriers, carrier.NewEasyPost(cfg.Shipping.EasyPostAPIKey, cfg.Shipping.EasyPostWebhookSecret))
+		}
+	}
+	from := cfg.Shipping.From
+	sender := shipmentdomain.Address{
+		Name:       from.Name,
+		Phone:      from.Phone,
+		Email:      cfg.Invoices.CompanyEmail,
+		Line1:      from.Line1,
+		Line2:      from.Line2,
+		City:       from.City,
+		Region:     from.Region,
+		PostalCode: from.PostalCode,
+		Country:    from.Country,
+	}
+	return shipmentusecase.NewService(postgres.NewShipmentRepository(db.Retrying()), orders, customers, sender, cfg.Shipping.DefaultWeightGrams, carriers...)
+}
+
+// migrationCheck summarizes the embedded migrations against the database
+// for the detailed health report.
+func migrationCheck(db *postgres.Database) httpserver.MigrationCheck {
+	return func(ctx context.Context) (httpserver.MigrationStatus, error) {
+		states, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return httpserver.MigrationStatus{}, err
+		}
+		var status httpserver.MigrationStatus
+		for _, state := range states {
+			status.Latest = max(status.Latest, state.Version)
+			switch {
+			case state.Dirty:
+				status.Dirty = append(status.Dirty, state.Version)
+			case state.Applied:
+				status.Current = max(status.Current, state.Version)
+			default:
+				status.Pending++
+			}
+		}
+		return status, nil
+	}
+}
+
+// newDispatcher builds the webhook dispatcher from the configured policy.
+func newDispatcher(cfg config.Config, webhookService *webhookusecase.Service) *webhookusecase.Dispatcher {
+	return webhookusecase.NewDispatcher(webhookService, webhookusecase.DispatcherOptions{
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: cfg.Webhooks.InitialBackoff,
+		MaxBackoff:     cfg.Webhooks.MaxBackoff,
+		Timeout:        cfg.Webhooks.Timeout,
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/config"
+)
+
+// runHealthcheck implements the "healthcheck" subcommand: it probes the local
+// server's /readyz and fails unless it answers 200, so images without curl
+// can still declare a HEALTHCHECK.
+func runHealthcheck(args []string) error {
+	fs := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	addr := fs.String("addr", "", "address to probe (default: the internal listener, else the first HTTP listener)")
+	path := fs.String("path", "/readyz", "endpoint to request")
+	timeout := fs.Duration("timeout", 3*time.Second, "request timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *addr == "" {
+		probeAddr, err := config.LoadProbeAddr()
+		if err != nil {
+			return err
+		}
+		*addr = probeAddr
+	}
+
+	client, url := probeClient(*addr, *path, *timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// probeClient returns a client and URL reaching path on a listen address,
+// which may be a Unix socket ("unix:/path") or a TCP address whose host is
+// empty or a wildcard.
+func probeClient(addr, path string, timeout time.Duration) (*http.Client, string) {
+	client := &http.Client{Timeout: timeout}
+	if socket, ok := strings.CutPrefix(addr, "unix:"); ok {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+		return client, "http://localhost" + path
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return client, "http://" + addr + path
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return client, "http://" + net.JoinHostPort(host, port) + path
+}
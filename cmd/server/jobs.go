@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backoffice/backend/internal/errreport"
+)
+
+// jobGroup runs background jobs and lets shutdown wait for them to return
+// before the resources they use, such as the database pool, are closed.
+type jobGroup struct {
+	wg sync.WaitGroup
+}
+
+// Go runs fn in the background with panic reporting, see errreport.Go.
+func (g *jobGroup) Go(ctx context.Context, job string, fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	errreport.Go(ctx, job, func(ctx context.Context) {
+		defer g.wg.Done()
+		fn(ctx)
+	})
+}
+
+// Wait blocks until every job has returned or timeout elapses, and reports
+// whether they all returned.
+func (g *jobGroup) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/app/loadtest"
+)
+
+// runLoadtest implements the "loadtest" subcommand: it drives sign-in and
+// product CRUD against a running server, prints latency percentiles and,
+// given a baseline, fails when p95 or p99 regressed beyond the budget.
+func runLoadtest(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	baseURL := fs.String("url", "http://localhost:8080", "base URL of the server under test")
+	email := fs.String("email", os.Getenv("LOADTEST_EMAIL"), "admin account to sign in with")
+	password := fs.String("password", os.Getenv("LOADTEST_PASSWORD"), "password of the account")
+	workers := fs.Int("workers", 4, "concurrent clients (sign-in is rate limited to a burst of 10 per client IP)")
+	iterations := fs.Int("iterations", 50, "product lifecycles per worker")
+	timeout := fs.Duration("timeout", 5*time.Minute, "overall timeout")
+	baseline := fs.String("baseline", "", "report to compare against")
+	save := fs.String("save", "", "write this run's report here, e.g. to use as a baseline")
+	maxRegression := fs.Float64("max-regression", 0.2, "allowed p95/p99 slowdown against the baseline, as a fraction")
+	slack := fs.Duration("slack", time.Millisecond, "absolute slowdown always allowed on top of -max-regression")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" || *password == "" {
+		return errors.New("-email and -password (or LOADTEST_EMAIL and LOADTEST_PASSWORD) are required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	report, err := loadtest.Run(ctx, loadtest.Options{
+		BaseURL:    strings.TrimRight(*baseURL, "/"),
+		Email:      *email,
+		Password:   *password,
+		Workers:    *workers,
+		Iterations: *iterations,
+	})
+	if report != nil {
+		report.Print(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if *save != "" {
+		if err := loadtest.WriteReport(*save, report); err != nil {
+			return fmt.Errorf("saving report: %w", err)
+		}
+	}
+	if n := report.Errors(); n > 0 {
+		return fmt.Errorf("%d requests failed", n)
+	}
+	if *baseline == "" {
+		return nil
+	}
+	base, err := loadtest.ReadReport(*baseline)
+	if err != nil {
+		return fmt.Errorf("reading baseline: %w", err)
+	}
+	regressions := loadtest.Compare(report, base, loadtest.Budget{MaxRegression: *maxRegression, Slack: *slack})
+	for _, r := range regressions {
+		fmt.Printf("over budget: %s\n", r)
+	}
+	if len(regressions) > 0 {
+		return fmt.Errorf("%d latency percentiles over budget", len(regressions))
+	}
+	return nil
+}
@@ -1,69 +1,78 @@
 package main
 
 import (
-	"context"
-	"errors"
+	"fmt"
 	"log"
-	"net/http"
-	"os/signal"
-	"syscall"
-	"time"
+	"os"
+	"strings"
+	// User timezones must resolve on hosts without a zone database.
+	_ "time/tzdata"
 
-	"backoffice/backend/internal/config"
-	"backoffice/backend/internal/httpserver"
-	"backoffice/backend/internal/infrastructure/postgres"
-	"backoffice/backend/internal/infrastructure/token"
-	authusecase "backoffice/backend/internal/usecase/auth"
-	productusecase "backoffice/backend/internal/usecase/product"
-	userusecase "backoffice/backend/internal/usecase/user"
+	"backoffice/backend/internal/buildinfo"
 )
 
-func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
-	}
+// command is one subcommand of the server binary.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	rootCtx := context.Background()
-	db, err := postgres.New(rootCtx, cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+var commands = []command{
+	{name: "serve", summary: "run the HTTP API (default)", run: runServe},
+	{name: "worker", summary: "run background jobs (webhook delivery) without the HTTP API", run: runWorker},
+	{name: "migrate", summary: "apply, roll back or inspect database migrations", run: runMigrate},
+	{name: "seed", summary: "load a fixture dataset into the database", run: runSeed},
+	{name: "reencrypt", summary: "rewrite customer personal data under the primary PII key", run: runReencrypt},
+	{name: "loadtest", summary: "measure sign-in and product CRUD latencies against a running server", run: runLoadtest},
+	{name: "healthcheck", summary: "probe the local server's readiness endpoint", run: runHealthcheck},
+	{name: "version", summary: "print the build version and commit", run: runVersion},
+}
+
+func main() {
+	name, args := "serve", os.Args[1:]
+	// Without a subcommand (or with only flags) the binary serves, so existing
+	// entrypoints keep working.
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
-	defer db.Close()
-	if err := db.Migrate(rootCtx); err != nil {
-		log.Fatalf("failed to run database migrations: %v", err)
+	if name == "help" {
+		printUsage()
+		return
 	}
 
-	tokenManager := token.NewJWTManager(cfg.JWTSecret, cfg.JWTExpiry, cfg.JWTIssuer)
-
-	userRepo := postgres.NewUserRepository(db.Pool)
-	authService := authusecase.NewService(userRepo, tokenManager)
-	userService := userusecase.NewService(userRepo)
-	productService := productusecase.NewService(postgres.NewProductRepository(db.Pool))
-
-	server := httpserver.NewServer(cfg, authService, userService, productService)
-	log.Printf("HTTP server listening on %s", server.Addr())
-
-	go func() {
-		if err := server.Start(); err != nil {
-			if errors.Is(err, http.ErrServerClosed) {
-				log.Printf("HTTP server closed: %v", err)
-				return
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(args); err != nil {
+				log.Fatalf("%s: %v", name, err)
 			}
-			log.Fatalf("server error: %v", err)
+			return
 		}
-		log.Printf("HTTP server stopped accepting new connections")
-	}()
+	}
+	printUsage()
+	os.Exit(2)
+}
 
-	shutdownCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-	<-shutdownCtx.Done()
+// runVersion implements the "version" subcommand.
+func runVersion(args []string) error {
+	info := buildinfo.Get()
+	unknown := func(v string) string {
+		if v == "" {
+			return "unknown"
+		}
+		return v
+	}
+	fmt.Printf("version %s\ncommit %s\nbuilt %s\n%s\n", info.Version, unknown(info.Commit), unknown(info.BuildTime), info.GoVersion)
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("graceful shutdown failed: %v\n", err)
-	} else {
-		log.Printf("graceful shutdown completed")
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: server <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `run "server <command> -h" for the command's flags`)
 }
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"backoffice/backend/internal/config"
+	"backoffice/backend/internal/infrastructure/postgres"
+)
+
+const migrateUsage = `usage: server migrate <command> [flags]
+
+commands:
+  up [-steps N]          apply pending migrations (all by default)
+  down [-steps N]        roll back the latest N migrations (default 1)
+  status                 list migrations and whether they are applied
+  force <version>        mark a version as cleanly applied after manual repair
+  create [-dir D] <name> write empty up/down files for a new migration
+`
+
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// runMigrate implements the "migrate" subcommand so deploy pipelines can run
+// schema changes as a separate job from server startup.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return errors.New("missing migrate command")
+	}
+	command, args := args[0], args[1:]
+
+	if command == "create" {
+		return createMigration(args)
+	}
+
+	switch command {
+	case "up", "down", "status", "force":
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+
+	fs := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	steps := fs.Int("steps", 0, "number of migrations to apply or roll back")
+	timeout := fs.Duration("timeout", 5*time.Minute, "overall timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dsn, err := config.LoadDatabaseURL()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	db, err := postgres.New(ctx, dsn, postgres.PoolOptions{MaxConns: 2})
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	switch command {
+	case "up":
+		applied, err := db.MigrateUp(ctx, *steps)
+		for _, v := range applied {
+			fmt.Printf("applied %d\n", v)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		reverted, err := db.MigrateDown(ctx, *steps)
+		for _, v := range reverted {
+			fmt.Printf("reverted %d\n", v)
+		}
+		return err
+	case "status":
+		states, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, st := range states {
+			state, appliedAt := "pending", ""
+			if st.Dirty {
+				state = "DIRTY"
+			} else if st.Applied {
+				state = "applied"
+			}
+			if st.AppliedAt != nil {
+				appliedAt = st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", st.Version, st.Name, state, appliedAt)
+		}
+		return tw.Flush()
+	case "force":
+		if fs.NArg() != 1 {
+			return errors.New("usage: server migrate force <version>")
+		}
+		version, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", fs.Arg(0))
+		}
+		if err := db.ForceMigrationVersion(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("marked %d as applied\n", version)
+	}
+	return nil
+}
+
+// createMigration writes the next numbered up/down pair into the migrations
+// directory of the source tree.
+func createMigration(args []string) error {
+	fs := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := fs.String("dir", filepath.Join("internal", "infrastructure", "postgres", "migrations"), "migrations directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: server migrate create [-dir D] <name>")
+	}
+	name := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fs.Arg(0)), "-", "_"))
+	if !migrationNamePattern.MatchString(name) {
+		return fmt.Errorf("migration name %q must contain only letters, digits and underscores", fs.Arg(0))
+	}
+
+	entries, err := os.ReadDir(*dir)
+	if err != nil {
+		return err
+	}
+	var next int64 = 1
+	for _, entry := range entries {
+		raw, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil && v >= next {
+			next = v + 1
+		}
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(*dir, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("-- %04d_%s (%s)\n", next, name, direction)), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"backoffice/backend/internal/config"
+	"backoffice/backend/internal/infrastructure/postgres"
+)
+
+// runReencrypt implements the "reencrypt" subcommand, which rewrites every
+// customer phone number and address under the primary PII key: after
+// encryption is first enabled, and after a new key is put first in
+// PII_ENCRYPTION_KEYS, so the old key can then be removed. It is safe to run
+// while the server is up and to run again after an interruption.
+func runReencrypt(args []string) error {
+	fs := flag.NewFlagSet("reencrypt", flag.ContinueOnError)
+	batch := fs.Int("batch", 500, "rows read per query")
+	timeout := fs.Duration("timeout", time.Hour, "overall timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *batch < 1 {
+		return errors.New("-batch must be at least 1")
+	}
+
+	keys, err := config.LoadPIIKeys()
+	if err != nil {
+		return err
+	}
+	keyring, err := newPIIKeyring(keys)
+	if err != nil {
+		return err
+	}
+	if keyring == nil {
+		return errors.New("PII_ENCRYPTION_KEYS is not set")
+	}
+	dsn, err := config.LoadDatabaseURL()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	db, err := postgres.New(ctx, dsn, postgres.PoolOptions{MaxConns: 2})
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	result, err := postgres.NewCustomerRepository(db.Pool, keyring).Reencrypt(ctx, *batch)
+	fmt.Printf("re-encrypted %d customers and %d addresses under key %s\n", result.Customers, result.Addresses, keyring.Primary())
+	return err
+}
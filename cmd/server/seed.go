@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/app/seed"
+	"backoffice/backend/internal/config"
+	"backoffice/backend/internal/infrastructure/postgres"
+	categoryusecase "backoffice/backend/internal/usecase/category"
+	productusecase "backoffice/backend/internal/usecase/product"
+	userusecase "backoffice/backend/internal/usecase/user"
+)
+
+// runSeed implements the "seed" subcommand, which loads an embedded dataset
+// into the configured database. It is safe to run more than once.
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	dataset := fs.String("dataset", "default", "fixture to load ("+strings.Join(seed.Datasets(), ", ")+")")
+	adminPassword := fs.String("admin-password", os.Getenv("SEED_ADMIN_PASSWORD"), "password for seeded accounts without one (generated when empty)")
+	timeout := fs.Duration("timeout", time.Minute, "overall timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fixture, err := seed.Load(*dataset)
+	if err != nil {
+		return err
+	}
+
+	dsn, err := config.LoadDatabaseURL()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	db, err := postgres.New(ctx, dsn, postgres.PoolOptions{MaxConns: 2})
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(ctx); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	seeder := seed.NewSeeder(
+		userusecase.NewService(postgres.NewUserRepository(db.Pool)),
+		categoryusecase.NewService(postgres.NewCategoryRepository(db.Pool)),
+		productusecase.NewService(postgres.NewProductRepository(db.Pool)),
+	)
+	result, err := seeder.Run(ctx, fixture, seed.Options{AdminPassword: *adminPassword})
+	if result != nil {
+		fmt.Printf("seeded %d users, %d categories, %d products (%d already present)\n",
+			len(result.Users), len(result.Categories), len(result.Products), result.Skipped)
+		for email, password := range result.GeneratedPasswords {
+			fmt.Printf("generated password for %s: %s\n", email, password)
+		}
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backoffice/backend/internal/config"
+	"backoffice/backend/internal/infrastructure/postgres"
+	authusecase "backoffice/backend/internal/usecase/auth"
+)
+
+// checkTokenConfig issues and validates a token with the configured signed
+// format before anything connects, so a secret or key the manager cannot
+// use fails the deploy instead of the first sign-in. Opaque tokens live in
+// the database and are not probed.
+func checkTokenConfig(cfg config.Config) error {
+	if cfg.TokenFormat == "opaque" {
+		return nil
+	}
+	tokens, err := newTokenManager(cfg, nil)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := authusecase.CheckTokens(ctx, tokens); err != nil {
+		return fmt.Errorf("token self-test (%s): %w", cfg.TokenFormat, err)
+	}
+	log.Printf("token self-test passed (%s)", cfg.TokenFormat)
+	return nil
+}
+
+// checkSchema fails when the database schema does not match this build:
+// a migration left dirty by a failed run, or migrations not yet applied.
+func checkSchema(ctx context.Context, db *postgres.Database) error {
+	status, err := migrationCheck(db)(ctx)
+	if err != nil {
+		return fmt.Errorf("reading the schema version: %w", err)
+	}
+	if len(status.Dirty) > 0 {
+		return fmt.Errorf("migration %d is dirty after a failed run; repair the schema and run `server migrate force %d`", status.Dirty[0], status.Dirty[0])
+	}
+	if status.Pending > 0 {
+		return fmt.Errorf("database schema is at version %d but this build expects %d (%d pending); run `server migrate up` or set MIGRATE_ON_START=true", status.Current, status.Latest, status.Pending)
+	}
+	log.Printf("database schema at version %d", status.Current)
+	return nil
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"backoffice/backend/internal/buildinfo"
+	"backoffice/backend/internal/config"
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/httpserver"
+	"backoffice/backend/internal/infrastructure/postgres"
+	activityusecase "backoffice/backend/internal/usecase/activity"
+	authusecase "backoffice/backend/internal/usecase/auth"
+	categoryusecase "backoffice/backend/internal/usecase/category"
+	consentusecase "backoffice/backend/internal/usecase/consent"
+	customerusecase "backoffice/backend/internal/usecase/customer"
+	integrationusecase "backoffice/backend/internal/usecase/integration"
+	orderusecase "backoffice/backend/internal/usecase/order"
+	privacyusecase "backoffice/backend/internal/usecase/privacy"
+	productusecase "backoffice/backend/internal/usecase/product"
+	promotionusecase "backoffice/backend/internal/usecase/promotion"
+	returnsusecase "backoffice/backend/internal/usecase/returns"
+	userusecase "backoffice/backend/internal/usecase/user"
+	viewusecase "backoffice/backend/internal/usecase/view"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
+
+	"github.com/google/uuid"
+)
+
+// runServe implements the "serve" subcommand: the HTTP API plus, unless
+// disabled, the background workers.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	workers := fs.Bool("workers", true, "also run background workers (disable when a separate \"worker\" process runs them)")
+	selfTest := fs.Bool("self-test", true, "check the token configuration and database schema before serving")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	flush, err := setupErrorReporting(cfg)
+	if err != nil {
+		return err
+	}
+	defer flush()
+	if *selfTest {
+		if err := checkTokenConfig(cfg); err != nil {
+			return err
+		}
+	}
+
+	rootCtx := context.Background()
+	db, err := openDatabase(rootCtx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// Background jobs stop after the HTTP server has drained and before the
+	// database closes; jobsCtx is cancelled explicitly during shutdown.
+	var jobs jobGroup
+	jobsCtx, stopJobs := context.WithCancel(rootCtx)
+	defer stopJobs()
+	jobs.Go(jobsCtx, "db-pool-stats", func(ctx context.Context) {
+		db.LogPoolStats(ctx, cfg.DatabasePool.StatsInterval)
+	})
+	jobs.Go(jobsCtx, "db-pool-monitor", func(ctx context.Context) {
+		db.MonitorPool(ctx, cfg.DatabasePool.SaturationWarn)
+	})
+	if cfg.MigrateOnStart {
+		if err := db.Migrate(rootCtx); err != nil {
+			return fmt.Errorf("running database migrations: %w", err)
+		}
+	}
+	if *selfTest {
+		if err := checkSchema(rootCtx, db); err != nil {
+			return err
+		}
+	}
+
+	tokenManager, err := newTokenManager(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	webhookService := webhookusecase.NewService(postgres.NewWebhookRepository(db.Retrying()))
+	activityService := activityusecase.NewService(postgres.NewActivityRepository(db.Retrying()), postgres.NewUserRepository(db.Retrying()))
+	notificationService := newNotificationService(cfg, db)
+	watchService := newWatchService(cfg, db)
+	subscribers := []event.Publisher{webhookService, activityService, notificationService, watchService}
+	var userCache *authusecase.UserCache
+	if cfg.UserCacheTTL > 0 {
+		userCache = authusecase.NewUserCache(cfg.UserCacheTTL)
+		subscribers = append(subscribers, userCache)
+	}
+	events, closeEvents, err := newEventBus(cfg, subscribers...)
+	if err != nil {
+		return err
+	}
+	defer closeEvents()
+
+	userRepo := postgres.NewUserRepository(db.Retrying())
+	authService := authusecase.NewService(userRepo, tokenManager)
+	authService.SetUserCache(userCache)
+	authService.SetClients(cfg.TokenClients)
+	authService.SetRenewGrace(cfg.RenewGrace)
+	authService.SetFailureDelay(cfg.LoginFailureDelay)
+	authService.SetRegistration(cfg.Registration.Enabled, cfg.Registration.Domains)
+	authService.SetAdminEmails(cfg.Registration.AdminEmails)
+	authService.SetPublisher(events)
+	userService := userusecase.NewService(userRepo)
+	userService.SetPublisher(events)
+	productRepo := postgres.NewProductRepository(db.Retrying())
+	productService := productusecase.NewService(productRepo)
+	productService.SetPublisher(events)
+	productService.SetReservations(productRepo, cfg.Reservations.TTL, cfg.Reservations.MaxTTL)
+	productService.SetTranslations(productRepo, cfg.ProductLocale)
+	approvalService := newApprovalService(cfg, db, userService, productService)
+	approvalService.SetPublisher(events)
+	piiKeys, err := cfg.ParsedPIIKeys()
+	if err != nil {
+		return err
+	}
+	keyring, err := newPIIKeyring(piiKeys)
+	if err != nil {
+		return err
+	}
+	customerService := customerusecase.NewService(postgres.NewCustomerRepository(db.Retrying(), keyring))
+	orderService := orderusecase.NewService(postgres.NewOrderRepository(db.Retrying()), productService)
+	orderService.SetPublisher(events)
+	orderService.SetCustomers(customerService)
+	invoiceService, err := newInvoiceService(cfg, db, orderService, customerService)
+	if err != nil {
+		return err
+	}
+	paymentService := newPaymentService(cfg, db, orderService)
+	if paymentService != nil {
+		paymentService.SetPublisher(events)
+	}
+	shipmentService := newShipmentService(cfg, db, orderService, customerService)
+	if shipmentService != nil {
+		shipmentService.SetPublisher(events)
+	}
+	returnService := returnsusecase.NewService(postgres.NewReturnRepository(db.Retrying()), orderService, productService)
+	returnService.SetPublisher(events)
+	categoryService := categoryusecase.NewService(postgres.NewCategoryRepository(db.Retrying()))
+	categoryService.SetPublisher(events)
+	promotionService := promotionusecase.NewService(postgres.NewPromotionRepository(db.Retrying()), orderService, productService, categoryService)
+	trashService := newTrashService(db)
+	trashService.SetPublisher(events)
+	retentionService := newRetentionService(cfg, db, trashService)
+	syncService, err := newSyncService(cfg, db, productService)
+	if err != nil {
+		return err
+	}
+	syncService.SetPublisher(events)
+	alertService, err := newAlertService(cfg, productService)
+	if err != nil {
+		return err
+	}
+	// Notifications and watched product changes are queued in the process
+	// that published the event.
+	jobs.Go(jobsCtx, "notifications", notificationService.Run)
+	jobs.Go(jobsCtx, "product-watches", watchService.Run)
+	// Login, registration and order counters live in this process, so the
+	// gauges derived from them are refreshed here rather than by workers.
+	jobs.Go(jobsCtx, "business-metrics", func(ctx context.Context) {
+		alertService.Run(ctx, cfg.Alerts.Interval)
+	})
+	// Client request counts are kept in memory until flushed.
+	quotaService := newQuotaService(cfg, db)
+	jobs.Go(jobsCtx, "quota-flush", func(ctx context.Context) {
+		quotaService.Run(ctx, cfg.Quotas.FlushInterval)
+	})
+
+	if *workers {
+		jobs.Go(jobsCtx, "webhook-dispatcher", newDispatcher(cfg, webhookService).Run)
+		jobs.Go(jobsCtx, "retention-purge", func(ctx context.Context) {
+			retentionService.Run(ctx, cfg.Retention.Interval)
+		})
+		jobs.Go(jobsCtx, "reservation-purge", func(ctx context.Context) {
+			productService.RunReservationPurge(ctx, cfg.Reservations.TTL)
+		})
+		jobs.Go(jobsCtx, "stock-sync", syncService.RunScheduled)
+		if shipmentService != nil {
+			jobs.Go(jobsCtx, "shipment-tracking", func(ctx context.Context) {
+				shipmentService.Run(ctx, cfg.Shipping.PollInterval)
+			})
+		}
+	}
+
+	server := httpserver.NewServer(cfg, authService, userService, productService, categoryService, webhookService, trashService)
+	server.AddReadinessCheck("database", db.Pool.Ping)
+	server.SetPoolSaturation(db.Saturation)
+	server.SetMigrationCheck(migrationCheck(db))
+	server.SetSearchService(newSearchService(db))
+	server.SetActivityService(activityService)
+	server.SetBackupService(newBackupService(db))
+	server.SetRetentionService(retentionService)
+	server.SetApprovalService(approvalService)
+	server.SetOrderService(orderService)
+	server.SetReturnService(returnService)
+	server.SetInvoiceService(invoiceService)
+	server.SetPaymentService(paymentService)
+	server.SetShipmentService(shipmentService)
+	server.SetPromotionService(promotionService)
+	server.SetCustomerService(customerService)
+	viewService := viewusecase.NewService(postgres.NewViewRepository(db.Retrying()))
+	server.SetViewService(viewService)
+	server.SetWatchService(watchService)
+	server.SetSyncService(syncService)
+	server.SetNotificationService(notificationService)
+	server.SetIntegrationService(integrationusecase.NewService(postgres.NewIntegrationRepository(db.Retrying())))
+	server.SetQuotaService(quotaService)
+	consentService := consentusecase.NewService(postgres.NewConsentRepository(db.Retrying()))
+	server.SetConsentService(consentService)
+	server.SetPrivacyService(privacyusecase.NewService(userService, authService, activityService, viewService, watchService, consentService))
+	server.OnReload(func(cfg config.Config) {
+		db.SetQueryLogging(cfg.QueryLog.SlowThreshold, cfg.QueryLog.All)
+		configureQuotas(quotaService, cfg)
+	})
+	log.Printf("HTTP server %s listening on %s", buildinfo.Get().Version, server.Addr())
+
+	// Keep replicas consistent: row changes arrive via table triggers and
+	// config reloads are broadcast explicitly, tagged with this instance's id
+	// so it does not react to its own announcements.
+	instanceID := uuid.NewString()
+	server.SetChangePublisher(func(ctx context.Context, event httpserver.ChangeEvent) error {
+		return db.NotifyChange(ctx, postgres.ChangeEvent{Table: event.Table, Op: event.Op, ID: event.ID, Origin: instanceID})
+	})
+	jobs.Go(jobsCtx, "change-listener", func(ctx context.Context) {
+		db.ListenChanges(ctx, instanceID, func(event postgres.ChangeEvent) {
+			server.HandleChange(httpserver.ChangeEvent{Table: event.Table, Op: event.Op, ID: event.ID})
+		})
+	})
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := server.Start(); err != nil {
+			if errors.Is(err, http.ErrServerClosed) {
+				log.Printf("HTTP server closed: %v", err)
+				return
+			}
+			serveErr <- err
+			return
+		}
+		log.Printf("HTTP server stopped accepting new connections")
+	}()
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for range reload {
+			if _, err := server.ReloadConfig(); err != nil {
+				log.Printf("configuration reload rejected: %v", err)
+			}
+		}
+	}()
+
+	shutdownCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var serveFailure error
+	select {
+	case <-shutdownCtx.Done():
+		// Restore default signal handling so a second signal exits at once.
+		stop()
+		server.Drain()
+		if cfg.ShutdownDrainDelay > 0 {
+			log.Printf("readiness failing; serving for %s before closing listeners", cfg.ShutdownDrainDelay)
+			time.Sleep(cfg.ShutdownDrainDelay)
+		}
+	case err := <-serveErr:
+		serveFailure = fmt.Errorf("server error: %w", err)
+	}
+
+	deadline := time.Now().Add(cfg.ShutdownTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("graceful shutdown failed: %v\n", err)
+	} else {
+		log.Printf("graceful shutdown completed")
+	}
+	stopJobs()
+	if !jobs.Wait(time.Until(deadline)) {
+		log.Printf("background jobs still running after %s; closing the database anyway", cfg.ShutdownTimeout)
+	}
+	return serveFailure
+}
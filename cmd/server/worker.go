@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"backoffice/backend/internal/infrastructure/postgres"
+	activityusecase "backoffice/backend/internal/usecase/activity"
+	productusecase "backoffice/backend/internal/usecase/product"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
+)
+
+// runWorker implements the "worker" subcommand, which runs the background
+// jobs without serving HTTP so they can be scaled separately from the API
+// (run the API with "serve -workers=false" in that case).
+func runWorker(args []string) error {
+	fs := flag.NewFlagSet("worker", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	flush, err := setupErrorReporting(cfg)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := openDatabase(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	var jobs jobGroup
+	jobs.Go(ctx, "db-pool-stats", func(ctx context.Context) {
+		db.LogPoolStats(ctx, cfg.DatabasePool.StatsInterval)
+	})
+	jobs.Go(ctx, "db-pool-monitor", func(ctx context.Context) {
+		db.MonitorPool(ctx, cfg.DatabasePool.SaturationWarn)
+	})
+
+	webhookService := webhookusecase.NewService(postgres.NewWebhookRepository(db.Retrying()))
+	dispatcher := newDispatcher(cfg, webhookService)
+
+	// Synced products publish events like changes made through the API.
+	activityService := activityusecase.NewService(postgres.NewActivityRepository(db.Retrying()), postgres.NewUserRepository(db.Retrying()))
+	notificationService := newNotificationService(cfg, db)
+	events, closeEvents, err := newEventBus(cfg, webhookService, activityService, notificationService)
+	if err != nil {
+		return err
+	}
+	defer closeEvents()
+
+	retentionService := newRetentionService(cfg, db, newTrashService(db))
+	productRepo := postgres.NewProductRepository(db.Retrying())
+	productService := productusecase.NewService(productRepo)
+	productService.SetPublisher(events)
+	productService.SetReservations(productRepo, cfg.Reservations.TTL, cfg.Reservations.MaxTTL)
+	syncService, err := newSyncService(cfg, db, productService)
+	if err != nil {
+		return err
+	}
+	syncService.SetPublisher(events)
+
+	log.Printf("worker started")
+	jobs.Go(ctx, "webhook-dispatcher", dispatcher.Run)
+	jobs.Go(ctx, "retention-purge", func(ctx context.Context) {
+		retentionService.Run(ctx, cfg.Retention.Interval)
+	})
+	jobs.Go(ctx, "reservation-purge", func(ctx context.Context) {
+		productService.RunReservationPurge(ctx, cfg.Reservations.TTL)
+	})
+	jobs.Go(ctx, "stock-sync", syncService.RunScheduled)
+	jobs.Go(ctx, "notifications", notificationService.Run)
+	<-ctx.Done()
+	if !jobs.Wait(cfg.ShutdownTimeout) {
+		log.Printf("jobs still running after %s; closing the database anyway", cfg.ShutdownTimeout)
+	}
+	log.Printf("worker stopped")
+	return nil
+}
@@ -0,0 +1,58 @@
+// Command smoketest verifies a deployment: it registers a disposable user,
+// signs in, creates, updates and deletes a product against -url, and exits
+// non-zero when any step fails. It needs an admin account, which it uses to
+// accept the mandatory policies and to purge the user and product
+// afterwards; point it at a staging deployment where possible.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"backoffice/backend/internal/app/smoketest"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		log.Fatalf("smoketest: %v", err)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("smoketest", flag.ContinueOnError)
+	baseURL := fs.String("url", envOr("SMOKETEST_URL", "http://localhost:8080"), "base URL of the deployment")
+	emailDomain := fs.String("email-domain", envOr("SMOKETEST_EMAIL_DOMAIN", "example.com"), "domain of the disposable user's email; must be allowed to register")
+	adminEmail := fs.String("admin-email", os.Getenv("SMOKETEST_ADMIN_EMAIL"), "admin account that purges the disposable user afterwards (required)")
+	adminPassword := fs.String("admin-password", os.Getenv("SMOKETEST_ADMIN_PASSWORD"), "password of the admin account (required)")
+	timeout := fs.Duration("timeout", time.Minute, "overall timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	fmt.Printf("smoke testing %s\n", *baseURL)
+	_, err := smoketest.Run(ctx, smoketest.Options{
+		BaseURL:       *baseURL,
+		EmailDomain:   *emailDomain,
+		AdminEmail:    *adminEmail,
+		AdminPassword: *adminPassword,
+		Log:           os.Stdout,
+	})
+	return err
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
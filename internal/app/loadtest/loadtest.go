@@ -0,0 +1,305 @@
+// Package loadtest drives signing in and product CRUD against a running API
+// and reports latency percentiles per operation, so a change in the handler
+// stack can be checked against the budget a baseline run set.
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"backoffice/backend/pkg/client"
+)
+
+// Operations are reported in this order.
+const (
+	OpLogin  = "login"
+	OpCreate = "create product"
+	OpGet    = "get product"
+	OpList   = "list products"
+	OpUpdate = "update product"
+	OpDelete = "delete product"
+)
+
+var operationOrder = []string{OpLogin, OpCreate, OpGet, OpList, OpUpdate, OpDelete}
+
+// Options describes a run. Every worker signs in once and then creates,
+// reads, lists, updates and deletes a product Iterations times.
+type Options struct {
+	BaseURL    string
+	Email      string
+	Password   string
+	Workers    int
+	Iterations int
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+// Stats summarizes one operation's successful latencies, which are encoded
+// in nanoseconds.
+type Stats struct {
+	Operation string        `json:"operation"`
+	Count     int           `json:"count"`
+	Errors    int           `json:"errors"`
+	P50       time.Duration `json:"p50"`
+	P95       time.Duration `json:"p95"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Workers    int     `json:"workers"`
+	Iterations int     `json:"iterations"`
+	Operations []Stats `json:"operations"`
+	// FirstError is the first failed request, if any, to explain Errors.
+	FirstError string `json:"firstError,omitempty"`
+}
+
+// Stats returns the summary of operation, if the report has one.
+func (r *Report) Stats(operation string) (Stats, bool) {
+	for _, s := range r.Operations {
+		if s.Operation == operation {
+			return s, true
+		}
+	}
+	return Stats{}, false
+}
+
+// Errors returns the number of failed requests.
+func (r *Report) Errors() int {
+	total := 0
+	for _, s := range r.Operations {
+		total += s.Errors
+	}
+	return total
+}
+
+// Print writes the report as a table.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "%d workers x %d iterations\n", r.Workers, r.Iterations)
+	fmt.Fprintf(w, "%-16s %7s %7s %10s %10s %10s %10s\n", "operation", "count", "errors", "p50", "p95", "p99", "max")
+	for _, s := range r.Operations {
+		fmt.Fprintf(w, "%-16s %7d %7d %10s %10s %10s %10s\n", s.Operation, s.Count, s.Errors,
+			s.P50.Round(time.Microsecond), s.P95.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+	if r.FirstError != "" {
+		fmt.Fprintf(w, "first error: %s\n", r.FirstError)
+	}
+}
+
+// Run performs the load test. It fails only when no worker can sign in;
+// failed requests are counted in the report.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Workers < 1 || opts.Iterations < 1 {
+		return nil, errors.New("workers and iterations must be at least 1")
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	rec := &recorder{samples: map[string][]time.Duration{}, errors: map[string]int{}}
+	var wg sync.WaitGroup
+	for worker := range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Retries would hide the latency being measured.
+			c, err := client.New(opts.BaseURL, client.WithHTTPClient(httpClient), client.WithRetries(0, 0), client.WithUserAgent("backoffice-loadtest"))
+			if err != nil {
+				rec.record(OpLogin, 0, err)
+				return
+			}
+			if !rec.time(OpLogin, func() error {
+				_, err := c.Login(ctx, client.LoginInput{Email: opts.Email, Password: opts.Password})
+				return err
+			}) {
+				return
+			}
+			for i := range opts.Iterations {
+				if ctx.Err() != nil {
+					return
+				}
+				runIteration(ctx, c, rec, fmt.Sprintf("LOADTEST-%d-%d-%d", time.Now().UnixNano(), worker, i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := rec.report(opts.Workers, opts.Iterations)
+	if stats, _ := report.Stats(OpLogin); stats.Count == stats.Errors {
+		return report, fmt.Errorf("no worker could sign in: %s", report.FirstError)
+	}
+	return report, ctx.Err()
+}
+
+// runIteration walks one product through its lifecycle, stopping at the
+// first failure since later steps need the product.
+func runIteration(ctx context.Context, c *client.Client, rec *recorder, sku string) {
+	var product *client.Product
+	ok := rec.time(OpCreate, func() (err error) {
+		product, err = c.CreateProduct(ctx, client.NewProduct{Name: "Load test " + sku, SKU: sku, Price: 9.99, Quantity: 10})
+		return err
+	})
+	if !ok {
+		return
+	}
+	rec.time(OpGet, func() error {
+		_, err := c.GetProduct(ctx, product.ID)
+		return err
+	})
+	rec.time(OpList, func() error {
+		_, err := c.ListProducts(ctx, nil)
+		return err
+	})
+	price := 12.5
+	rec.time(OpUpdate, func() error {
+		_, err := c.UpdateProduct(ctx, product.ID, client.ProductUpdate{Price: &price})
+		return err
+	})
+	rec.time(OpDelete, func() error {
+		return c.DeleteProduct(ctx, product.ID)
+	})
+}
+
+// recorder collects latencies from concurrent workers.
+type recorder struct {
+	mu         sync.Mutex
+	samples    map[string][]time.Duration
+	errors     map[string]int
+	firstError error
+}
+
+// time runs call, records how long it took and reports whether it
+// succeeded.
+func (r *recorder) time(operation string, call func() error) bool {
+	start := time.Now()
+	err := call()
+	r.record(operation, time.Since(start), err)
+	return err == nil
+}
+
+func (r *recorder) record(operation string, elapsed time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.errors[operation]++
+		if r.firstError == nil {
+			r.firstError = fmt.Errorf("%s: %w", operation, err)
+		}
+		return
+	}
+	r.samples[operation] = append(r.samples[operation], elapsed)
+}
+
+func (r *recorder) report(workers, iterations int) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := &Report{Workers: workers, Iterations: iterations}
+	if r.firstError != nil {
+		report.FirstError = r.firstError.Error()
+	}
+	for _, operation := range operationOrder {
+		samples := r.samples[operation]
+		if len(samples) == 0 && r.errors[operation] == 0 {
+			continue
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		stats := Stats{Operation: operation, Count: len(samples) + r.errors[operation], Errors: r.errors[operation]}
+		if len(samples) > 0 {
+			stats.P50 = percentile(samples, 0.50)
+			stats.P95 = percentile(samples, 0.95)
+			stats.P99 = percentile(samples, 0.99)
+			stats.Max = samples[len(samples)-1]
+		}
+		report.Operations = append(report.Operations, stats)
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// Budget bounds how much slower a run may be than its baseline.
+type Budget struct {
+	// MaxRegression is the allowed slowdown of p95 and p99 as a fraction,
+	// e.g. 0.2 for 20%.
+	MaxRegression float64
+	// Slack is added to every allowance so sub-millisecond operations do
+	// not fail on scheduler noise.
+	Slack time.Duration
+}
+
+// Regression is a percentile over budget.
+type Regression struct {
+	Operation  string
+	Percentile string
+	Baseline   time.Duration
+	Current    time.Duration
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s %s: %s, baseline %s (%+.0f%%)", r.Operation, r.Percentile,
+		r.Current.Round(time.Microsecond), r.Baseline.Round(time.Microsecond),
+		100*(float64(r.Current)/float64(r.Baseline)-1))
+}
+
+// Compare returns the operations whose p95 or p99 exceed the baseline's by
+// more than the budget allows. Operations missing from either report are
+// not compared.
+func Compare(current, baseline *Report, budget Budget) []Regression {
+	var regressions []Regression
+	for _, base := range baseline.Operations {
+		now, ok := current.Stats(base.Operation)
+		if !ok {
+			continue
+		}
+		for _, p := range []struct {
+			name          string
+			base, current time.Duration
+		}{{"p95", base.P95, now.P95}, {"p99", base.P99, now.P99}} {
+			if p.base <= 0 {
+				continue
+			}
+			allowed := time.Duration(float64(p.base)*(1+budget.MaxRegression)) + budget.Slack
+			if p.current > allowed {
+				regressions = append(regressions, Regression{Operation: base.Operation, Percentile: p.name, Baseline: p.base, Current: p.current})
+			}
+		}
+	}
+	return regressions
+}
+
+// ReadReport loads a report saved with WriteReport.
+func ReadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// WriteReport saves a report, e.g. as the baseline for later runs.
+func WriteReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,218 @@
+// Package seed populates a fresh database with the default admin account,
+// categories and, for demos, sample products from embedded fixtures.
+package seed
+
+import (
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	categorydomain "backoffice/backend/internal/domain/category"
+	productdomain "backoffice/backend/internal/domain/product"
+	categoryusecase "backoffice/backend/internal/usecase/category"
+	productusecase "backoffice/backend/internal/usecase/product"
+	userusecase "backoffice/backend/internal/usecase/user"
+)
+
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+// Fixture is the on-disk shape of a dataset.
+type Fixture struct {
+	Users      []UserFixture     `json:"users"`
+	Categories []CategoryFixture `json:"categories"`
+	Products   []ProductFixture  `json:"products"`
+}
+
+// UserFixture describes a seeded account. An empty password is replaced with
+// Options.AdminPassword or a generated one.
+type UserFixture struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Password string `json:"password"`
+}
+
+// CategoryFixture describes a seeded category.
+type CategoryFixture struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+}
+
+// ProductFixture describes a seeded product; Category is a category slug.
+type ProductFixture struct {
+	Name        string  `json:"name"`
+	SKU         string  `json:"sku"`
+	Barcode     string  `json:"barcode"`
+	Description string  `json:"description"`
+	Unit        string  `json:"unit"`
+	PackSize    int     `json:"packSize"`
+	Price       float64 `json:"price"`
+	Quantity    int     `json:"quantity"`
+	Category    string  `json:"category"`
+}
+
+// Options tunes a seeding run.
+type Options struct {
+	// AdminPassword is used for fixture users without a password.
+	AdminPassword string
+}
+
+// Result reports what a run created; existing records are skipped.
+type Result struct {
+	Users      []string
+	Categories []string
+	Products   []string
+	Skipped    int
+	// GeneratedPasswords maps email to a password created for this run.
+	GeneratedPasswords map[string]string
+}
+
+// Datasets lists the embedded fixture names.
+func Datasets() []string {
+	entries, _ := fs.ReadDir(fixtureFiles, "fixtures")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load parses an embedded dataset by name.
+func Load(dataset string) (Fixture, error) {
+	var fixture Fixture
+	body, err := fixtureFiles.ReadFile(path.Join("fixtures", dataset+".json"))
+	if err != nil {
+		return fixture, fmt.Errorf("unknown dataset %q (available: %s)", dataset, strings.Join(Datasets(), ", "))
+	}
+	if err := json.Unmarshal(body, &fixture); err != nil {
+		return fixture, fmt.Errorf("dataset %q: %w", dataset, err)
+	}
+	return fixture, nil
+}
+
+// Seeder writes fixtures through the use case services so validation and
+// password hashing match the API.
+type Seeder struct {
+	users      *userusecase.Service
+	categories *categoryusecase.Service
+	products   *productusecase.Service
+}
+
+// NewSeeder constructs a seeder.
+func NewSeeder(users *userusecase.Service, categories *categoryusecase.Service, products *productusecase.Service) *Seeder {
+	return &Seeder{users: users, categories: categories, products: products}
+}
+
+// Run applies fixture. It is idempotent: users, categories and products that
+// already exist (by email, slug and SKU) are left untouched.
+func (s *Seeder) Run(ctx context.Context, fixture Fixture, opts Options) (*Result, error) {
+	result := &Result{GeneratedPasswords: map[string]string{}}
+
+	for _, u := range fixture.Users {
+		password := u.Password
+		generated := false
+		if password == "" {
+			password = opts.AdminPassword
+		}
+		if password == "" {
+			password = generatePassword()
+			generated = true
+		}
+		user, err := s.users.Create(ctx, userusecase.CreateInput{
+			Email:    u.Email,
+			Name:     u.Name,
+			Password: password,
+			Role:     u.Role,
+		})
+		if errors.Is(err, authdomain.ErrEmailExists) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("user %s: %w", u.Email, err)
+		}
+		result.Users = append(result.Users, user.Email)
+		if generated {
+			result.GeneratedPasswords[user.Email] = password
+		}
+	}
+
+	slugToID := map[string]string{}
+	for _, c := range fixture.Categories {
+		category, err := s.categories.Create(ctx, categoryusecase.CreateInput{
+			Name:        c.Name,
+			Slug:        c.Slug,
+			Description: c.Description,
+		})
+		if errors.Is(err, categorydomain.ErrDuplicateSlug) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("category %s: %w", c.Name, err)
+		}
+		slugToID[category.Slug] = category.ID
+		result.Categories = append(result.Categories, category.Slug)
+	}
+
+	for _, p := range fixture.Products {
+		categoryID, err := s.categoryID(ctx, slugToID, p.Category)
+		if err != nil {
+			return result, fmt.Errorf("product %s: %w", p.SKU, err)
+		}
+		product, err := s.products.Create(ctx, productusecase.CreateInput{
+			Name:        p.Name,
+			Description: p.Description,
+			SKU:         p.SKU,
+			Barcode:     p.Barcode,
+			Price:       p.Price,
+			Unit:        p.Unit,
+			PackSize:    p.PackSize,
+			Quantity:    p.Quantity,
+			CategoryID:  categoryID,
+		})
+		if errors.Is(err, productdomain.ErrDuplicateSKU) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("product %s: %w", p.SKU, err)
+		}
+		result.Products = append(result.Products, product.SKU)
+	}
+
+	return result, nil
+}
+
+func (s *Seeder) categoryID(ctx context.Context, known map[string]string, slug string) (string, error) {
+	if slug == "" {
+		return "", nil
+	}
+	if id, ok := known[slug]; ok {
+		return id, nil
+	}
+	category, err := s.categories.GetBySlug(ctx, slug)
+	if err != nil {
+		return "", fmt.Errorf("category %q: %w", slug, err)
+	}
+	known[slug] = category.ID
+	return category.ID, nil
+}
+
+func generatePassword() string {
+	buf := make([]byte, 18)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
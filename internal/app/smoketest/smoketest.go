@@ -0,0 +1,202 @@
+// Package smoketest checks a deployment end to end: it registers a
+// disposable user, signs in and walks a product through create, update and
+// delete, stopping at the first step that fails. An admin account accepts
+// the current policies on the user's behalf and removes the user and the
+// product for good afterwards.
+package smoketest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"backoffice/backend/pkg/client"
+)
+
+// Options describes a run.
+type Options struct {
+	BaseURL string
+	// EmailDomain is the domain of the disposable user's email; it must be
+	// one the server lets register.
+	EmailDomain string
+	// AdminEmail and AdminPassword sign in the admin that looks up the
+	// mandatory policies and purges the disposable user and product. Both
+	// are required.
+	AdminEmail    string
+	AdminPassword string
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+	// Log receives one line per step.
+	Log io.Writer
+}
+
+// Step is the outcome of one step.
+type Step struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// ErrAdminRequired rejects a run without admin credentials, which would
+// leave its disposable user behind.
+var ErrAdminRequired = errors.New("admin credentials are required to remove the disposable user")
+
+// Run performs the steps in order and returns those it attempted. The
+// error is that of the first failed step.
+func Run(ctx context.Context, opts Options) ([]Step, error) {
+	if opts.AdminEmail == "" || opts.AdminPassword == "" {
+		return nil, ErrAdminRequired
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	logw := opts.Log
+	if logw == nil {
+		logw = io.Discard
+	}
+	c, err := client.New(opts.BaseURL, client.WithHTTPClient(httpClient), client.WithUserAgent("backoffice-smoketest"))
+	if err != nil {
+		return nil, err
+	}
+	admin, err := client.New(opts.BaseURL, client.WithHTTPClient(httpClient), client.WithUserAgent("backoffice-smoketest"))
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	run := &runner{log: logw}
+	email := fmt.Sprintf("smoketest-%s@%s", hex.EncodeToString(suffix), opts.EmailDomain)
+	password := "Smoke-" + hex.EncodeToString(suffix)
+	sku := "SMOKETEST-" + hex.EncodeToString(suffix)
+
+	var user *client.User
+	var product *client.Product
+	var accept []client.PolicyRef
+	// Signing the admin in first also proves the users table is not empty,
+	// so the server cannot make the disposable user its first admin.
+	run.step("admin login", func() error {
+		me, err := admin.Login(ctx, client.LoginInput{Email: opts.AdminEmail, Password: opts.AdminPassword})
+		if err != nil {
+			return err
+		}
+		if me.Role != "admin" {
+			return fmt.Errorf("%s is not an admin", opts.AdminEmail)
+		}
+		return nil
+	})
+	run.step("policies", func() error {
+		policies, err := admin.Policies(ctx)
+		if client.IsStatus(err, http.StatusNotFound) {
+			// Consent tracking is off; registration asks for nothing.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		accept = client.Required(policies)
+		return nil
+	})
+	run.step("register", func() (err error) {
+		user, err = c.Register(ctx, client.RegisterInput{Email: email, Password: password, Name: "Smoke test", Accept: accept})
+		if err == nil && user.Role == "admin" {
+			err = errors.New("disposable user was registered as an admin")
+		}
+		return err
+	})
+	run.step("login", func() error {
+		_, err := c.Login(ctx, client.LoginInput{Email: email, Password: password})
+		return err
+	})
+	run.step("create product", func() (err error) {
+		product, err = c.CreateProduct(ctx, client.NewProduct{Name: "Smoke test " + sku, SKU: sku, Price: 1, Quantity: 1})
+		return err
+	})
+	run.step("update product", func() error {
+		price := 2.5
+		updated, err := c.UpdateProduct(ctx, product.ID, client.ProductUpdate{Price: &price})
+		if err != nil {
+			return err
+		}
+		if updated.Price != price {
+			return fmt.Errorf("price is %v after updating it to %v", updated.Price, price)
+		}
+		return nil
+	})
+	run.step("delete product", func() error {
+		if err := c.DeleteProduct(ctx, product.ID); err != nil {
+			return err
+		}
+		_, err := c.GetProduct(ctx, product.ID)
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return errors.New("product still readable after deleting it")
+	})
+
+	// The product and the user are purged even when a step failed.
+	if product != nil {
+		run.cleanup("purge product", func() error {
+			// A failed delete step leaves the product live.
+			if err := admin.DeleteProduct(ctx, product.ID); err != nil && !client.IsStatus(err, http.StatusNotFound) {
+				return err
+			}
+			return admin.PurgeTrash(ctx, client.TrashProduct, product.ID)
+		})
+	}
+	if user != nil {
+		run.cleanup("purge user", func() error {
+			pending, err := admin.DeleteUser(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			if pending {
+				return fmt.Errorf("deleting %s awaits a second admin's approval", email)
+			}
+			return admin.PurgeTrash(ctx, client.TrashUser, user.ID)
+		})
+	}
+	return run.steps, run.err
+}
+
+// runner performs steps until one fails.
+type runner struct {
+	log   io.Writer
+	steps []Step
+	err   error
+}
+
+// step runs call unless an earlier step failed.
+func (r *runner) step(name string, call func() error) {
+	if r.err == nil {
+		r.cleanup(name, call)
+	}
+}
+
+// cleanup runs call even after a failed step.
+func (r *runner) cleanup(name string, call func() error) {
+	start := time.Now()
+	err := call()
+	step := Step{Name: name, Duration: time.Since(start), Err: err}
+	r.steps = append(r.steps, step)
+	if err != nil {
+		fmt.Fprintf(r.log, "FAIL %-16s %s\n", name, err)
+		if r.err == nil {
+			r.err = fmt.Errorf("%s: %w", name, err)
+		}
+		return
+	}
+	fmt.Fprintf(r.log, "ok   %-16s %s\n", name, step.Duration.Round(time.Millisecond))
+}
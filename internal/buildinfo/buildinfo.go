@@ -0,0 +1,66 @@
+// Package buildinfo exposes the version the binary was built from. Release
+// builds inject the values with the linker:
+//
+//	go build -ldflags "-X backoffice/backend/internal/buildinfo.Version=v1.4.0 \
+//	  -X backoffice/backend/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X backoffice/backend/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without them, the commit and time recorded by the go command (when built
+// from a git checkout) are used instead.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set with -ldflags "-X ...".
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	GoVersion string `json:"goVersion"`
+	// Modified is set when the go command saw uncommitted changes.
+	Modified bool `json:"modified,omitempty"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build information, falling back to the VCS stamp of the
+// go command for values the linker did not set.
+func Get() Info {
+	once.Do(func() {
+		info = Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+		build, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		fromVCS := info.Commit == ""
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = fromVCS && setting.Value == "true"
+			}
+		}
+	})
+	return info
+}
@@ -2,10 +2,14 @@ package config
 
 import (
 	"bufio"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/netip"
 	neturl "net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,15 +17,421 @@ import (
 
 // Config centralises runtime configuration.
 type Config struct {
-	HTTPPort        string
-	DatabaseURL     string
-	JWTSecret       string
-	JWTIssuer       string
-	JWTExpiry       time.Duration
+	Environment    string
+	HTTPPort       string
+	ListenAddrs    []string
+	AdminAddrs     []string
+	UnixSocketMode os.FileMode
+	DatabaseURL    string
+	MigrateOnStart bool
+	DatabasePool   DatabasePoolConfig
+	// TokenFormat is "jwt" (default, signed with JWTSecret), "paseto"
+	// (v4.local, encrypted with PASETOKey) or "opaque" (random tokens backed
+	// by sessions in the database).
+	TokenFormat string
+	PASETOKey   string
+	JWTSecret   string
+	JWTIssuer   string
+	JWTExpiry   time.Duration
+	// RenewGrace is how long after expiry /auth/renew still accepts a token.
+	RenewGrace time.Duration
+	// LoginFailureDelay caps the random delay added to failed sign-ins.
+	LoginFailureDelay time.Duration
+	// UserCacheTTL is how long a token's user is cached between requests;
+	// zero disables the cache.
+	UserCacheTTL time.Duration
+	// JWTAudience, when set, is embedded in tokens and required on
+	// validation.
+	JWTAudience string
+	// TokenClients maps API client ids to the scopes they may request.
+	TokenClients map[string][]string
+	// PIIKeys encrypt customer phone numbers and addresses at rest, as
+	// "id:hexkey" entries. The first encrypts new values and the others
+	// only decrypt, so a key can be rotated out. Empty stores them in plain
+	// text.
+	PIIKeys         []string
+	Quotas          QuotaConfig
 	AllowedOrigins  []string
+	CORS            CORSConfig
+	SecurityHeaders SecurityHeadersConfig
+	AccessLog       AccessLogConfig
+	Recording       RecordingConfig
 	ReadTimeoutSec  int
 	WriteTimeoutSec int
 	IdleTimeoutSec  int
+
+	ReadRequestTimeout  time.Duration
+	WriteRequestTimeout time.Duration
+	LongRequestTimeout  time.Duration
+	LongRequestPaths    []string
+
+	// ShutdownDrainDelay is how long the server keeps serving with /readyz
+	// failing after SIGTERM, so load balancers stop sending traffic before
+	// listeners close. ShutdownTimeout then bounds waiting for in-flight
+	// requests and background jobs.
+	ShutdownDrainDelay time.Duration
+	ShutdownTimeout    time.Duration
+
+	Webhooks     WebhookConfig
+	Events       EventBrokerConfig
+	Retention    RetentionConfig
+	Reservations ReservationConfig
+	Sync         SyncConfig
+	Alerts       AlertConfig
+	Approvals    ApprovalConfig
+	Registration RegistrationConfig
+	Invoices     InvoiceConfig
+	Mail         MailConfig
+	Payments     PaymentConfig
+	Shipping     ShippingConfig
+
+	// ProductLocale is the locale products' own names and descriptions are
+	// written in; translations add the others.
+	ProductLocale string
+
+	// SentryDSN enables error reporting to Sentry; Release tags reports
+	// with the deployed version.
+	SentryDSN string
+	Release   string
+
+	// SchemaValidation checks requests against the OpenAPI document; outside
+	// production responses are checked too, to catch drift between the
+	// document and the handlers.
+	SchemaValidation bool
+
+	// ResponseCacheTTLs enables the in-memory response cache for route
+	// prefixes (e.g. "/products") with the given lifetime.
+	ResponseCacheTTLs map[string]time.Duration
+
+	// The settings below can be changed at runtime via SIGHUP or the admin
+	// reload endpoint; everything else requires a restart.
+	LogLevel     string
+	RateLimit    RateLimitConfig
+	FeatureFlags map[string]bool
+	QueryLog     QueryLogConfig
+	// TrustedProxies lists the peers, as IPs or CIDRs (or "unix" for Unix
+	// socket connections), whose TrustedProxyHeader is believed when
+	// resolving the client's IP.
+	TrustedProxies []string
+	// TrustedProxyHeader is the one forwarding header the trusted proxies
+	// set: ProxyHeaderXForwardedFor, ProxyHeaderForwarded or
+	// ProxyHeaderXRealIP. The others are ignored, since proxies usually
+	// pass them through from the client unchanged.
+	TrustedProxyHeader string
+
+	// Warnings collects non-fatal validation findings for the startup report.
+	Warnings []string
+}
+
+// AccessLogConfig controls the structured HTTP access log.
+type AccessLogConfig struct {
+	// Format is "json" (default) or "text".
+	Format string
+	// Headers includes request headers in each entry (credentials redacted).
+	Headers bool
+	// Bodies includes JSON request bodies with secret fields redacted.
+	Bodies bool
+	// SampleRates maps path prefixes to the fraction of successful requests
+	// that are logged; errors are always logged.
+	SampleRates map[string]float64
+	// Exclude lists path prefixes, such as load balancer health checks,
+	// whose requests are only logged at debug level unless they fail.
+	Exclude []string
+	// Preflights logs CORS preflight (OPTIONS) requests at info level
+	// rather than debug.
+	Preflights bool
+}
+
+// RecordingConfig selects the requests whose request/response pairs are
+// kept, redacted, for GET /admin/debug/requests. Recording is off unless
+// Users or Routes is set.
+type RecordingConfig struct {
+	// Users are the IDs of users whose requests are recorded.
+	Users []string
+	// Routes are path prefixes whose requests are recorded for any caller.
+	Routes []string
+	// Size is how many exchanges are kept; older ones are dropped.
+	Size int
+}
+
+// QuotaConfig sets monthly request quotas for the TOKEN_CLIENTS clients.
+type QuotaConfig struct {
+	// Limits maps client ids to the requests they may make per calendar
+	// month (UTC). Clients without an entry are counted but not limited.
+	Limits map[string]int64
+	// Enforcement is "warn" (default), which only logs clients over their
+	// quota, or "block", which rejects their requests with 429.
+	Enforcement string
+	// FlushInterval is how often counts are written to the database.
+	FlushInterval time.Duration
+}
+
+// Block reports whether requests over quota are rejected.
+func (q QuotaConfig) Block() bool {
+	return q.Enforcement == "block"
+}
+
+// DatabasePoolConfig tunes the pgx connection pool; zero values keep the
+// driver defaults.
+type DatabasePoolConfig struct {
+	MaxConns           int
+	MinConns           int
+	MaxConnLifetime    time.Duration
+	MaxConnIdleTime    time.Duration
+	HealthCheckPeriod  time.Duration
+	AcquireTimeout     time.Duration
+	StatementCacheMode string
+	StatsInterval      time.Duration
+	// RetryAttempts bounds how often a statement is tried when it fails
+	// with a transient error (1 disables retries).
+	RetryAttempts   int
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+	// ApplicationName labels connections in pg_stat_activity; TagRequests
+	// appends the request and user ID while a request holds the connection.
+	ApplicationName string
+	TagRequests     bool
+	// SaturationWarn logs a warning when at least this share of the pool's
+	// connections is in use (0 disables it). ShedThreshold, when set,
+	// answers API requests with 503 while saturation is at or above it, so
+	// a load spike queues at the clients rather than in front of Postgres.
+	SaturationWarn float64
+	ShedThreshold  float64
+}
+
+// WebhookConfig tunes outgoing webhook delivery.
+type WebhookConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+}
+
+// RetentionConfig holds the default retention policies, used until an admin
+// sets one through the API, and how often the purge runs. A zero retention
+// keeps records forever. Policies are whole days; other durations round up.
+type RetentionConfig struct {
+	// Trash is how long soft-deleted records stay restorable.
+	Trash time.Duration
+	// Activity is how long audit trail entries are kept.
+	Activity time.Duration
+	// Sessions is how long expired opaque-token sessions are kept.
+	Sessions time.Duration
+	Interval time.Duration
+}
+
+// ReservationConfig controls how long product reservations hold stock.
+type ReservationConfig struct {
+	// TTL applies when a request does not ask for a lifetime; MaxTTL caps
+	// the ones that do.
+	TTL    time.Duration
+	MaxTTL time.Duration
+}
+
+// RegistrationConfig controls public self-registration via /auth/register.
+type RegistrationConfig struct {
+	// Enabled is false in invite-only deployments, where admins create
+	// every user.
+	Enabled bool
+	// Domains, when set, are the only email domains that may register.
+	Domains []string
+	// AdminEmails register as admins regardless of the settings above.
+	// Without any, the first user to register on a deployment with no
+	// admin becomes one.
+	AdminEmails []string
+}
+
+// SyncConfig lists the connectors that pull product and stock data from
+// external systems.
+type SyncConfig struct {
+	// ConnectorsFile is the JSON file the connectors were read from.
+	ConnectorsFile string
+	Connectors     []SyncConnectorConfig
+	// Timeout bounds a single run.
+	Timeout time.Duration
+}
+
+// SyncConnectorConfig is one entry of the connectors file.
+type SyncConnectorConfig struct {
+	Name string `json:"name"`
+	// Type is "shopify", "csv" or "rest".
+	Type string `json:"type"`
+	// Interval between scheduled runs; zero runs the connector only when an
+	// admin triggers it.
+	Interval time.Duration `json:"-"`
+	// Mapping maps product fields (sku, name, description, price,
+	// quantity) to the source's field names; unmapped fields keep their
+	// own name.
+	Mapping map[string]string `json:"mapping"`
+	// Options are the type-specific settings, such as url or token. $VAR
+	// references are expanded from the environment so secrets can stay out
+	// of the file.
+	Options map[string]string `json:"options"`
+}
+
+// AlertConfig controls the job that refreshes the business gauges and the
+// threshold alerts it raises. A zero threshold disables that alert.
+type AlertConfig struct {
+	// SlackWebhookURL receives alerts; without it they are only logged.
+	SlackWebhookURL string
+	Interval        time.Duration
+	// LowStockQuantity is the stock level at or below which a product
+	// counts as low on stock.
+	LowStockQuantity        int
+	FailedLoginsPerHour     int
+	LowStockProducts        int
+	MinOrdersPerHour        int
+	MinRegistrationsPerHour int
+	// SLOs are the per-route objectives whose error budget is watched.
+	// Burn alerts fire when the budget is spent at least SLOBurnRate times
+	// faster than the objective allows, measured over SLOWindow once it
+	// holds SLOMinRequests requests.
+	SLOs           []SLOTarget
+	SLOWindow      time.Duration
+	SLOBurnRate    float64
+	SLOMinRequests int
+}
+
+// SLOTarget is the objective for requests under a path prefix: Objective
+// percent of them must succeed, and, when Latency is set, finish within it.
+type SLOTarget struct {
+	Prefix    string
+	Objective float64
+	Latency   time.Duration
+}
+
+// ApprovalConfig lists the destructive admin actions that wait for a second
+// admin to approve them.
+type ApprovalConfig struct {
+	// Actions are "user.delete" and "product.bulk_price_update"; none by
+	// default.
+	Actions []string
+	// TTL is how long a request waits for a decision before it expires.
+	TTL time.Duration
+}
+
+// InvoiceConfig holds the seller details printed on invoices and the layout
+// they are printed with.
+type InvoiceConfig struct {
+	CompanyName string
+	// CompanyAddress lines are separated by "|" in COMPANY_ADDRESS.
+	CompanyAddress []string
+	CompanyTaxID   string
+	CompanyEmail   string
+	CompanyPhone   string
+	// Template is a text/template file replacing the built-in layout.
+	Template string
+}
+
+// MailConfig sets up outgoing email over SMTP. Mail is off without a host.
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// PaymentConfig selects the payment providers orders can be paid with.
+// Payments are off when no provider is listed.
+type PaymentConfig struct {
+	// Providers are "mock" and "stripe"; the first is the default.
+	Providers []string
+	Currency  string
+	// MockSecret signs the mock provider's webhooks.
+	MockSecret          string
+	StripeSecretKey     string
+	StripeWebhookSecret string
+	// SuccessURL and CancelURL are where the provider's checkout page sends
+	// the customer afterwards.
+	SuccessURL string
+	CancelURL  string
+}
+
+// ShippingConfig selects the carriers order parcels are shipped with and
+// where they are sent from. Shipping is off when no carrier is listed.
+type ShippingConfig struct {
+	// Carriers are "mock" and "easypost"; the first is the default.
+	Carriers []string
+	// MockSecret signs the mock carrier's webhooks; its parcels move one
+	// status along every MockStep.
+	MockSecret            string
+	MockStep              time.Duration
+	EasyPostAPIKey        string
+	EasyPostWebhookSecret string
+	// From is the sender address printed on labels. Its name defaults to
+	// COMPANY_NAME.
+	From ShipFromAddress
+	// DefaultWeightGrams is the parcel weight when a shipment gives none.
+	DefaultWeightGrams int
+	// PollInterval is how often carriers are asked for the tracking of
+	// parcels not delivered yet.
+	PollInterval time.Duration
+}
+
+// ShipFromAddress is the address parcels are sent from.
+type ShipFromAddress struct {
+	Name       string
+	Phone      string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// EventBrokerConfig selects where domain events are published as CloudEvents.
+type EventBrokerConfig struct {
+	// Broker is "none" (default), "nats" or "kafka".
+	Broker string
+	// Source is the CloudEvents source attribute.
+	Source        string
+	NATSURL       string
+	SubjectPrefix string
+	// KafkaRESTURL points at a Kafka REST Proxy.
+	KafkaRESTURL string
+	KafkaTopic   string
+	QueueSize    int
+}
+
+// QueryLogConfig controls SQL statement logging.
+type QueryLogConfig struct {
+	// SlowThreshold logs statements at least this slow; zero disables it.
+	SlowThreshold time.Duration
+	// All logs every statement, for short debugging sessions.
+	All bool
+}
+
+// RateLimitConfig configures the per-client token bucket. A zero rate
+// disables limiting.
+type RateLimitConfig struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// CORSConfig holds cross-origin settings beyond the origin whitelist.
+type CORSConfig struct {
+	AllowCredentials bool
+	MaxAge           time.Duration
+	ExposedHeaders   []string
+	// RouteOrigins overrides the allowed origins for requests whose path starts
+	// with the map key.
+	RouteOrigins map[string][]string
+}
+
+// SecurityHeadersConfig controls the security headers added to every
+// response.
+type SecurityHeadersConfig struct {
+	Enabled bool
+	// HSTSMaxAge sets Strict-Transport-Security; zero leaves it out.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	ReferrerPolicy        string
+	// ContentSecurityPolicy is sent as is; the default forbids everything,
+	// which suits JSON responses. A deployment serving the admin UI from
+	// this origin loosens it for the UI's scripts and styles.
+	ContentSecurityPolicy string
 }
 
 // Load reads configuration from environment variables providing sane defaults.
@@ -30,32 +440,371 @@ func Load() (Config, error) {
 		return Config{}, fmt.Errorf("loading .env: %w", err)
 	}
 
-	httpPort := getEnv("HTTP_PORT", "")
-	if httpPort == "" {
-		httpPort = getEnv("PORT", "8080")
+	httpPort := resolveHTTPPort()
+	allowedOrigins := splitCSV(getEnv("CORS_ALLOWED_ORIGINS", "*"))
+	if len(allowedOrigins) == 0 {
+		allowedOrigins = []string{"*"}
 	}
 
 	cfg := Config{
+		Environment:     strings.ToLower(getEnv("APP_ENV", "development")),
 		HTTPPort:        httpPort,
 		DatabaseURL:     resolveDatabaseURL(),
+		MigrateOnStart:  getBoolEnv("MIGRATE_ON_START", true),
+		TokenFormat:     strings.ToLower(getEnv("TOKEN_FORMAT", "jwt")),
+		PASETOKey:       getEnv("PASETO_KEY", ""),
 		JWTSecret:       getEnv("JWT_SECRET", ""),
 		JWTIssuer:       getEnv("JWT_ISSUER", "backoffice"),
 		JWTExpiry:       getDurationEnv("JWT_EXPIRY", 12*time.Hour),
-		AllowedOrigins:  splitCSV(getEnv("CORS_ALLOWED_ORIGINS", "*")),
+		RenewGrace:      getDurationEnv("TOKEN_RENEW_GRACE", time.Hour),
+		JWTAudience:     getEnv("JWT_AUDIENCE", ""),
+		TokenClients:    parseClientScopes(getEnv("TOKEN_CLIENTS", "")),
+		PIIKeys:         piiKeysFromEnv(),
+		AllowedOrigins:  allowedOrigins,
 		ReadTimeoutSec:  getIntEnv("HTTP_READ_TIMEOUT", 15),
 		WriteTimeoutSec: getIntEnv("HTTP_WRITE_TIMEOUT", 15),
 		IdleTimeoutSec:  getIntEnv("HTTP_IDLE_TIMEOUT", 60),
+		ListenAddrs:     splitCSV(getEnv("HTTP_LISTEN", "")),
+		AdminAddrs:      splitCSV(getEnv("ADMIN_LISTEN", "")),
+		UnixSocketMode:  getFileModeEnv("HTTP_UNIX_SOCKET_MODE", 0o660),
+
+		ReadRequestTimeout:  getDurationEnv("REQUEST_TIMEOUT_READ", 10*time.Second),
+		WriteRequestTimeout: getDurationEnv("REQUEST_TIMEOUT_WRITE", 15*time.Second),
+		LongRequestTimeout:  getDurationEnv("REQUEST_TIMEOUT_LONG", 5*time.Minute),
+		ShutdownDrainDelay:  getDurationEnv("SHUTDOWN_DRAIN_DELAY", 0),
+		ShutdownTimeout:     getDurationEnv("SHUTDOWN_TIMEOUT", 10*time.Second),
+		LongRequestPaths:    splitCSV(getEnv("REQUEST_TIMEOUT_LONG_PATHS", "")),
+		ResponseCacheTTLs:   parseDurationMap(getEnv("RESPONSE_CACHE_ROUTES", "")),
+		LoginFailureDelay:   getDurationEnv("LOGIN_FAILURE_DELAY", 250*time.Millisecond),
+		UserCacheTTL:        getDurationEnv("AUTH_USER_CACHE_TTL", 5*time.Second),
+
+		DatabasePool: DatabasePoolConfig{
+			MaxConns:           getIntEnv("DB_MAX_CONNS", 0),
+			MinConns:           getIntEnv("DB_MIN_CONNS", 0),
+			MaxConnLifetime:    getDurationEnv("DB_MAX_CONN_LIFETIME", time.Hour),
+			MaxConnIdleTime:    getDurationEnv("DB_MAX_CONN_IDLE_TIME", 30*time.Minute),
+			HealthCheckPeriod:  getDurationEnv("DB_HEALTH_CHECK_PERIOD", 0),
+			AcquireTimeout:     getDurationEnv("DB_ACQUIRE_TIMEOUT", 0),
+			StatementCacheMode: strings.ToLower(getEnv("DB_STATEMENT_CACHE_MODE", "")),
+			StatsInterval:      getDurationEnv("DB_POOL_STATS_INTERVAL", 0),
+			RetryAttempts:      getIntEnv("DB_RETRY_ATTEMPTS", 3),
+			RetryBackoff:       getDurationEnv("DB_RETRY_BACKOFF", 50*time.Millisecond),
+			RetryMaxBackoff:    getDurationEnv("DB_RETRY_MAX_BACKOFF", time.Second),
+			ApplicationName:    getEnv("DB_APPLICATION_NAME", "backoffice-api"),
+			TagRequests:        getBoolEnv("DB_TAG_REQUESTS", true),
+			SaturationWarn:     getFloatEnv("DB_POOL_SATURATION_WARN", 0.9),
+			ShedThreshold:      getFloatEnv("DB_POOL_SHED_THRESHOLD", 0),
+		},
+		Webhooks: WebhookConfig{
+			MaxAttempts:    getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+			InitialBackoff: getDurationEnv("WEBHOOK_RETRY_BACKOFF", 30*time.Second),
+			MaxBackoff:     getDurationEnv("WEBHOOK_RETRY_MAX_BACKOFF", 6*time.Hour),
+			Timeout:        getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+		},
+		Retention: RetentionConfig{
+			Trash:    getDurationEnv("TRASH_RETENTION", 30*24*time.Hour),
+			Activity: getDurationEnv("ACTIVITY_RETENTION", 0),
+			Sessions: getDurationEnv("SESSION_RETENTION", 30*24*time.Hour),
+			// TRASH_PURGE_INTERVAL predates the other policies.
+			Interval: getDurationEnv("RETENTION_INTERVAL", getDurationEnv("TRASH_PURGE_INTERVAL", time.Hour)),
+		},
+		Reservations: ReservationConfig{
+			TTL:    getDurationEnv("RESERVATION_TTL", 15*time.Minute),
+			MaxTTL: getDurationEnv("RESERVATION_MAX_TTL", 24*time.Hour),
+		},
+		Sync: SyncConfig{
+			ConnectorsFile: getEnv("SYNC_CONNECTORS_FILE", ""),
+			Timeout:        getDurationEnv("SYNC_TIMEOUT", 10*time.Minute),
+		},
+		Alerts: AlertConfig{
+			SlackWebhookURL:         getEnv("ALERT_SLACK_WEBHOOK_URL", ""),
+			Interval:                getDurationEnv("ALERT_INTERVAL", time.Minute),
+			LowStockQuantity:        getIntEnv("LOW_STOCK_QUANTITY", 5),
+			FailedLoginsPerHour:     getIntEnv("ALERT_FAILED_LOGINS_PER_HOUR", 0),
+			LowStockProducts:        getIntEnv("ALERT_LOW_STOCK_PRODUCTS", 0),
+			MinOrdersPerHour:        getIntEnv("ALERT_MIN_ORDERS_PER_HOUR", 0),
+			MinRegistrationsPerHour: getIntEnv("ALERT_MIN_REGISTRATIONS_PER_HOUR", 0),
+			SLOs:                    parseSLOTargets(getEnv("SLO_TARGETS", "")),
+			SLOWindow:               getDurationEnv("SLO_WINDOW", time.Hour),
+			SLOBurnRate:             getFloatEnv("SLO_BURN_RATE", 14.4),
+			SLOMinRequests:          getIntEnv("SLO_MIN_REQUESTS", 100),
+		},
+		Approvals: ApprovalConfig{
+			Actions: splitCSV(getEnv("APPROVAL_ACTIONS", "")),
+			TTL:     getDurationEnv("APPROVAL_TTL", 24*time.Hour),
+		},
+		Registration: RegistrationConfig{
+			Enabled:     getBoolEnv("REGISTRATION_ENABLED", true),
+			Domains:     splitCSV(strings.ToLower(getEnv("REGISTRATION_DOMAINS", ""))),
+			AdminEmails: splitCSV(strings.ToLower(getEnv("ADMIN_EMAILS", ""))),
+		},
+		Invoices: InvoiceConfig{
+			CompanyName:    getEnv("COMPANY_NAME", "Backoffice"),
+			CompanyAddress: splitAddress(getEnv("COMPANY_ADDRESS", "")),
+			CompanyTaxID:   getEnv("COMPANY_TAX_ID", ""),
+			CompanyEmail:   getEnv("COMPANY_EMAIL", ""),
+			CompanyPhone:   getEnv("COMPANY_PHONE", ""),
+			Template:       getEnv("INVOICE_TEMPLATE", ""),
+		},
+		Mail: MailConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getIntEnv("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", ""),
+		},
+		Payments: PaymentConfig{
+			Providers:           splitCSV(strings.ToLower(getEnv("PAYMENT_PROVIDERS", ""))),
+			Currency:            strings.ToUpper(getEnv("PAYMENT_CURRENCY", "USD")),
+			MockSecret:          getEnv("PAYMENT_MOCK_SECRET", ""),
+			StripeSecretKey:     getEnv("STRIPE_SECRET_KEY", ""),
+			StripeWebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
+			SuccessURL:          getEnv("PAYMENT_SUCCESS_URL", ""),
+			CancelURL:           getEnv("PAYMENT_CANCEL_URL", ""),
+		},
+		Shipping: ShippingConfig{
+			Carriers:              splitCSV(strings.ToLower(getEnv("SHIPPING_CARRIERS", ""))),
+			MockSecret:            getEnv("SHIPPING_MOCK_SECRET", ""),
+			MockStep:              getDurationEnv("SHIPPING_MOCK_STEP", 10*time.Minute),
+			EasyPostAPIKey:        getEnv("EASYPOST_API_KEY", ""),
+			EasyPostWebhookSecret: getEnv("EASYPOST_WEBHOOK_SECRET", ""),
+			From: ShipFromAddress{
+				Name:       getEnv("SHIP_FROM_NAME", getEnv("COMPANY_NAME", "Backoffice")),
+				Phone:      getEnv("SHIP_FROM_PHONE", ""),
+				Line1:      getEnv("SHIP_FROM_LINE1", ""),
+				Line2:      getEnv("SHIP_FROM_LINE2", ""),
+				City:       getEnv("SHIP_FROM_CITY", ""),
+				Region:     getEnv("SHIP_FROM_REGION", ""),
+				PostalCode: getEnv("SHIP_FROM_POSTAL_CODE", ""),
+				Country:    strings.ToUpper(getEnv("SHIP_FROM_COUNTRY", "")),
+			},
+			DefaultWeightGrams: getIntEnv("SHIPPING_DEFAULT_WEIGHT_GRAMS", 500),
+			PollInterval:       getDurationEnv("SHIPMENT_POLL_INTERVAL", 15*time.Minute),
+		},
+		ProductLocale:    getEnv("PRODUCT_LOCALE", "en"),
+		SentryDSN:        getEnv("SENTRY_DSN", ""),
+		Release:          getEnv("APP_RELEASE", ""),
+		SchemaValidation: getBoolEnv("OPENAPI_VALIDATION", false),
+		Events: EventBrokerConfig{
+			Broker:        strings.ToLower(getEnv("EVENT_BROKER", "none")),
+			Source:        getEnv("EVENT_SOURCE", "/backoffice/api"),
+			NATSURL:       getEnv("NATS_URL", "nats://localhost:4222"),
+			SubjectPrefix: getEnv("NATS_SUBJECT_PREFIX", "backoffice"),
+			KafkaRESTURL:  getEnv("KAFKA_REST_URL", ""),
+			KafkaTopic:    getEnv("KAFKA_TOPIC", "backoffice.events"),
+			QueueSize:     getIntEnv("EVENT_QUEUE_SIZE", 1024),
+		},
+		AccessLog: AccessLogConfig{
+			Format:      strings.ToLower(getEnv("LOG_FORMAT", "json")),
+			Headers:     getBoolEnv("ACCESS_LOG_HEADERS", false),
+			Bodies:      getBoolEnv("ACCESS_LOG_BODIES", false),
+			SampleRates: parseSampleRates(getEnv("ACCESS_LOG_SAMPLE", "")),
+			Exclude:     parseLogExclusions(getEnv("ACCESS_LOG_EXCLUDE", "/health,/readyz,/metrics")),
+			Preflights:  getBoolEnv("ACCESS_LOG_PREFLIGHTS", false),
+		},
+		Recording: RecordingConfig{
+			Users:  splitCSV(getEnv("DEBUG_RECORD_USERS", "")),
+			Routes: splitCSV(getEnv("DEBUG_RECORD_ROUTES", "")),
+			Size:   getIntEnv("DEBUG_RECORD_SIZE", 200),
+		},
+		Quotas: QuotaConfig{
+			Limits:        parseQuotaLimits(getEnv("TOKEN_CLIENT_QUOTAS", "")),
+			Enforcement:   strings.ToLower(getEnv("QUOTA_ENFORCEMENT", "warn")),
+			FlushInterval: getDurationEnv("QUOTA_FLUSH_INTERVAL", 10*time.Second),
+		},
+		LogLevel: strings.ToLower(getEnv("LOG_LEVEL", "info")),
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: getFloatEnv("RATE_LIMIT_RPS", 0),
+			Burst:             getIntEnv("RATE_LIMIT_BURST", 20),
+		},
+		FeatureFlags:       parseFlags(getEnv("FEATURE_FLAGS", "")),
+		TrustedProxies:     splitCSV(getEnv("TRUSTED_PROXIES", "")),
+		TrustedProxyHeader: strings.ToLower(getEnv("TRUSTED_PROXY_HEADER", ProxyHeaderXForwardedFor)),
+		QueryLog: QueryLogConfig{
+			SlowThreshold: getDurationEnv("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+			All:           getBoolEnv("DB_LOG_QUERIES", false),
+		},
+		CORS: CORSConfig{
+			AllowCredentials: getBoolEnv("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           getDurationEnv("CORS_MAX_AGE", 10*time.Minute),
+			ExposedHeaders:   splitCSV(getEnv("CORS_EXPOSED_HEADERS", "")),
+			RouteOrigins:     parseRouteOrigins(getEnv("CORS_ROUTE_ORIGINS", "")),
+		},
+		SecurityHeaders: SecurityHeadersConfig{
+			Enabled:               getBoolEnv("SECURITY_HEADERS", true),
+			HSTSIncludeSubdomains: getBoolEnv("HSTS_INCLUDE_SUBDOMAINS", false),
+			ReferrerPolicy:        getEnv("REFERRER_POLICY", "no-referrer"),
+			ContentSecurityPolicy: getEnv("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
+		},
 	}
 
-	if cfg.DatabaseURL == "" {
-		return Config{}, fmt.Errorf("database configuration missing: provide DATABASE_URL or PG* env vars (on Railway: Service → Variables → +New → reference your database's DATABASE_URL)")
+	// HSTS pins browsers to HTTPS, so it is only on by default in
+	// production, where the API is served over TLS.
+	hstsMaxAge := time.Duration(0)
+	if cfg.IsProduction() {
+		hstsMaxAge = 365 * 24 * time.Hour
 	}
-	if cfg.JWTSecret == "" {
-		return Config{}, fmt.Errorf("JWT_SECRET is required")
+	cfg.SecurityHeaders.HSTSMaxAge = getDurationEnv("HSTS_MAX_AGE", hstsMaxAge)
+
+	if len(cfg.ListenAddrs) == 0 {
+		cfg.ListenAddrs = []string{portAddr(httpPort)}
+	}
+	if cfg.Sync.ConnectorsFile != "" {
+		connectors, err := loadSyncConnectors(cfg.Sync.ConnectorsFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("SYNC_CONNECTORS_FILE: %w", err)
+		}
+		cfg.Sync.Connectors = connectors
 	}
+
+	warnings, err := cfg.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Warnings = warnings
 	return cfg, nil
 }
 
+// IsProduction reports whether APP_ENV selects the production profile.
+func (c Config) IsProduction() bool {
+	return c.Environment == "production" || c.Environment == "prod"
+}
+
+// unixProxy in TRUSTED_PROXIES trusts peers connected over a Unix socket.
+const unixProxy = "unix"
+
+// Forwarding headers TRUSTED_PROXY_HEADER can name.
+const (
+	ProxyHeaderXForwardedFor = "x-forwarded-for"
+	ProxyHeaderForwarded     = "forwarded"
+	ProxyHeaderXRealIP       = "x-real-ip"
+)
+
+// ProxyPrefixes returns TrustedProxies as address prefixes, and whether
+// peers connected over a Unix socket are trusted. Entries Validate rejects
+// are skipped.
+func (c Config) ProxyPrefixes() (prefixes []netip.Prefix, unix bool) {
+	for _, entry := range c.TrustedProxies {
+		if strings.EqualFold(entry, unixProxy) {
+			unix = true
+			continue
+		}
+		if prefix, err := parseProxy(entry); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes, unix
+}
+
+// parseProxy reads a CIDR, or a bare IP as a single-address prefix.
+func parseProxy(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// PIIKey is one decoded PII_ENCRYPTION_KEYS entry.
+type PIIKey struct {
+	ID     string
+	Secret []byte
+}
+
+// piiKeyID limits key ids to what is safe to store alongside every value.
+var piiKeyID = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+// piiKeysFromEnv reads PII_ENCRYPTION_KEYS, or the file named by
+// PII_ENCRYPTION_KEYS_FILE so the keys can come from a secrets mount.
+func piiKeysFromEnv() []string {
+	return splitCSV(firstNonEmpty(getEnv("PII_ENCRYPTION_KEYS", ""), readEnvFile("PII_ENCRYPTION_KEYS_FILE")))
+}
+
+// ParsedPIIKeys decodes PIIKeys, primary first.
+func (c Config) ParsedPIIKeys() ([]PIIKey, error) {
+	keys := make([]PIIKey, 0, len(c.PIIKeys))
+	seen := map[string]bool{}
+	for _, entry := range c.PIIKeys {
+		id, encoded, _ := strings.Cut(entry, ":")
+		secret, err := hex.DecodeString(encoded)
+		switch {
+		case !piiKeyID.MatchString(id):
+			return nil, fmt.Errorf("PII_ENCRYPTION_KEYS entries must be id:hexkey with an id of letters, digits, '-' or '.'")
+		case err != nil || len(secret) != 32:
+			return nil, fmt.Errorf("PII_ENCRYPTION_KEYS key %q must be 32 bytes, hex encoded (e.g. `openssl rand -hex 32`)", id)
+		case seen[id]:
+			return nil, fmt.Errorf("PII_ENCRYPTION_KEYS key id %q is used twice", id)
+		}
+		seen[id] = true
+		keys = append(keys, PIIKey{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
+
+// LoadPIIKeys resolves only the PII encryption keys, for operational
+// commands that do not need the full server configuration.
+func LoadPIIKeys() ([]PIIKey, error) {
+	if err := loadDotEnv(".env"); err != nil {
+		return nil, fmt.Errorf("loading .env: %w", err)
+	}
+	return Config{PIIKeys: piiKeysFromEnv()}.ParsedPIIKeys()
+}
+
+// LoadDatabaseURL resolves only the database connection string, for
+// operational commands that do not need the full server configuration.
+func LoadDatabaseURL() (string, error) {
+	if err := loadDotEnv(".env"); err != nil {
+		return "", fmt.Errorf("loading .env: %w", err)
+	}
+	dsn := resolveDatabaseURL()
+	if dsn == "" {
+		return "", fmt.Errorf("database configuration missing: provide DATABASE_URL or PG* env vars")
+	}
+	if msg := checkDSN(dsn); msg != "" {
+		return "", fmt.Errorf("database URL: %s", msg)
+	}
+	return dsn, nil
+}
+
+// LoadProbeAddr resolves the address a local health probe should reach: the
+// first internal listener when one is configured, otherwise the first public
+// one.
+func LoadProbeAddr() (string, error) {
+	if err := loadDotEnv(".env"); err != nil {
+		return "", fmt.Errorf("loading .env: %w", err)
+	}
+	if addrs := splitCSV(getEnv("ADMIN_LISTEN", "")); len(addrs) > 0 {
+		return addrs[0], nil
+	}
+	if addrs := splitCSV(getEnv("HTTP_LISTEN", "")); len(addrs) > 0 {
+		return addrs[0], nil
+	}
+	return portAddr(resolveHTTPPort()), nil
+}
+
+func resolveHTTPPort() string {
+	if port := getEnv("HTTP_PORT", ""); port != "" {
+		return port
+	}
+	return getEnv("PORT", "8080")
+}
+
+// portAddr turns a bare port into a listen address.
+func portAddr(port string) string {
+	if strings.Contains(port, ":") {
+		return port
+	}
+	return ":" + port
+}
+
 func getEnv(key, fallback string) string {
 	if val, ok := os.LookupEnv(key); ok && val != "" {
 		return val
@@ -81,20 +830,227 @@ func getIntEnv(key string, fallback int) int {
 	return fallback
 }
 
+func getFileModeEnv(key string, fallback os.FileMode) os.FileMode {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		if n, err := strconv.ParseUint(val, 8, 32); err == nil {
+			return os.FileMode(n)
+		}
+	}
+	return fallback
+}
+
+func getFloatEnv(key string, fallback float64) float64 {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+// parseFlags reads "name,name,-name" where a leading '-' disables a flag.
+func parseFlags(value string) map[string]bool {
+	flags := map[string]bool{}
+	for _, name := range splitCSV(value) {
+		if disabled, ok := strings.CutPrefix(name, "-"); ok {
+			flags[strings.ToLower(disabled)] = false
+			continue
+		}
+		flags[strings.ToLower(name)] = true
+	}
+	return flags
+}
+
+func getBoolEnv(key string, fallback bool) bool {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+// parseRouteOrigins reads "prefix=origin|origin;prefix=origin" pairs.
+func parseRouteOrigins(value string) map[string][]string {
+	routes := map[string][]string{}
+	for _, entry := range strings.Split(value, ";") {
+		prefix, origins, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || prefix == "" {
+			continue
+		}
+		routes[prefix] = splitCSV(strings.ReplaceAll(origins, "|", ","))
+	}
+	return routes
+}
+
+// parseClientScopes reads "client=scope|scope;client=scope" pairs. Clients
+// without scopes are dropped.
+func parseClientScopes(value string) map[string][]string {
+	clients := map[string][]string{}
+	for _, entry := range strings.Split(value, ";") {
+		client, scopes, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		client = strings.TrimSpace(client)
+		if !ok || client == "" {
+			continue
+		}
+		var list []string
+		for _, scope := range strings.Split(scopes, "|") {
+			if scope = strings.ToLower(strings.TrimSpace(scope)); scope != "" {
+				list = append(list, scope)
+			}
+		}
+		if len(list) > 0 {
+			clients[client] = list
+		}
+	}
+	return clients
+}
+
+// parseQuotaLimits reads "client=requests;client=requests" pairs. Limits
+// that do not parse are kept as -1 so validation reports them.
+func parseQuotaLimits(value string) map[string]int64 {
+	limits := map[string]int64{}
+	for _, entry := range strings.Split(value, ";") {
+		client, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		client = strings.TrimSpace(client)
+		if !ok || client == "" {
+			continue
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			limit = -1
+		}
+		limits[client] = limit
+	}
+	return limits
+}
+
+// parseSampleRates reads "prefix=rate;prefix=rate" pairs with rates in [0,1].
+func parseSampleRates(value string) map[string]float64 {
+	rates := map[string]float64{}
+	for _, entry := range strings.Split(value, ";") {
+		prefix, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || prefix == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			continue
+		}
+		rates[prefix] = rate
+	}
+	return rates
+}
+
+// parseLogExclusions reads a comma-separated list of path prefixes; "none"
+// excludes nothing.
+func parseLogExclusions(value string) []string {
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return nil
+	}
+	return splitCSV(value)
+}
+
+// parseDurationMap reads "key=duration;key=duration" pairs.
+func parseDurationMap(value string) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	for _, entry := range strings.Split(value, ";") {
+		key, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || d < 0 {
+			continue
+		}
+		durations[key] = d
+	}
+	return durations
+}
+
+// parseSLOTargets reads "prefix=objective[:latency]" entries separated by
+// semicolons, such as "/products=99.9:300ms;/auth/login=99.5". Entries that
+// do not parse are kept with a zero objective so validation reports them.
+func parseSLOTargets(value string) []SLOTarget {
+	var targets []SLOTarget
+	for _, entry := range strings.Split(value, ";") {
+		prefix, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || prefix == "" {
+			continue
+		}
+		target := SLOTarget{Prefix: prefix}
+		objective, latency, hasLatency := strings.Cut(strings.TrimSpace(raw), ":")
+		target.Objective, _ = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(objective), "%"), 64)
+		if hasLatency {
+			d, err := time.ParseDuration(strings.TrimSpace(latency))
+			if err != nil {
+				d = -1
+			}
+			target.Latency = d
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// loadSyncConnectors reads a JSON array of connectors. Intervals are
+// duration strings such as "15m".
+func loadSyncConnectors(path string) ([]SyncConnectorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		SyncConnectorConfig
+		Interval string `json:"interval"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	connectors := make([]SyncConnectorConfig, 0, len(entries))
+	for _, entry := range entries {
+		connector := entry.SyncConnectorConfig
+		if entry.Interval != "" {
+			interval, err := time.ParseDuration(entry.Interval)
+			if err != nil {
+				return nil, fmt.Errorf("connector %q: interval: %w", connector.Name, err)
+			}
+			connector.Interval = interval
+		}
+		for key, value := range connector.Options {
+			connector.Options[key] = os.ExpandEnv(value)
+		}
+		connectors = append(connectors, connector)
+	}
+	return connectors, nil
+}
+
+// splitCSV splits a comma-separated list, dropping blank entries.
 func splitCSV(value string) []string {
 	parts := []string{}
 	for _, part := range strings.Split(value, ",") {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
 			parts = append(parts, trimmed)
 		}
 	}
-	if len(parts) == 0 {
-		return []string{"*"}
-	}
 	return parts
 }
 
+// splitAddress splits "|"-separated address lines.
+func splitAddress(value string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(value, "|") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			lines = append(lines, trimmed)
+		}
+	}
+	return lines
+}
+
 func resolveDatabaseURL() string {
 	preferPublic := preferPublicRailwayURL()
 	for _, key := range []string{
@@ -237,7 +1193,9 @@ func coerceDatabaseURL(raw string) string {
 	raw = strings.TrimSpace(raw)
 	if len(raw) >= 2 {
 		if (raw[0] == '"' && raw[len(raw)-1] == '"') || (raw[0] == '\'' && raw[len(raw)-1] == '\'') {
-			raw = raw[1 : len(raw)-1]
+			// Spaces inside the quotes are as much a copy-paste slip as
+			// the quotes themselves.
+			raw = strings.TrimSpace(raw[1 : len(raw)-1])
 		}
 	}
 	if raw == "" {
@@ -0,0 +1,1036 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	neturl "net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every configuration problem found at startup so
+// operators can fix them in one pass.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d configuration problem(s):", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+const minJWTSecretLength = 32
+
+// weakSecrets are placeholder values copied from examples and docs.
+var weakSecrets = map[string]bool{
+	"secret":             true,
+	"change-me":          true,
+	"changeme":           true,
+	"super-secret":       true,
+	"your-strong-secret": true,
+	"my-strong-secret":   true,
+	"my-secret":          true,
+	"password":           true,
+}
+
+// approvalActions are the actions APPROVAL_ACTIONS may hold for approval.
+var approvalActions = []string{"user.delete", "product.bulk_price_update"}
+
+// typedEnv lists variables whose values are parsed; a value that fails to parse
+// silently falls back to the default in Load, so validation reports it here.
+var typedEnv = map[string]string{
+	"JWT_EXPIRY":                       "duration",
+	"TOKEN_RENEW_GRACE":                "duration",
+	"LOGIN_FAILURE_DELAY":              "duration",
+	"AUTH_USER_CACHE_TTL":              "duration",
+	"REQUEST_TIMEOUT_READ":             "duration",
+	"REQUEST_TIMEOUT_WRITE":            "duration",
+	"REQUEST_TIMEOUT_LONG":             "duration",
+	"SHUTDOWN_DRAIN_DELAY":             "duration",
+	"SHUTDOWN_TIMEOUT":                 "duration",
+	"CORS_MAX_AGE":                     "duration",
+	"HTTP_READ_TIMEOUT":                "int",
+	"HTTP_WRITE_TIMEOUT":               "int",
+	"HTTP_IDLE_TIMEOUT":                "int",
+	"CORS_ALLOW_CREDENTIALS":           "bool",
+	"SECURITY_HEADERS":                 "bool",
+	"HSTS_MAX_AGE":                     "duration",
+	"HSTS_INCLUDE_SUBDOMAINS":          "bool",
+	"ACCESS_LOG_HEADERS":               "bool",
+	"ACCESS_LOG_BODIES":                "bool",
+	"ACCESS_LOG_PREFLIGHTS":            "bool",
+	"DEBUG_RECORD_SIZE":                "int",
+	"QUOTA_FLUSH_INTERVAL":             "duration",
+	"REGISTRATION_ENABLED":             "bool",
+	"HTTP_UNIX_SOCKET_MODE":            "octal",
+	"RATE_LIMIT_RPS":                   "float",
+	"DB_POOL_SATURATION_WARN":          "float",
+	"DB_POOL_SHED_THRESHOLD":           "float",
+	"RATE_LIMIT_BURST":                 "int",
+	"MIGRATE_ON_START":                 "bool",
+	"DB_MAX_CONNS":                     "int",
+	"DB_MIN_CONNS":                     "int",
+	"DB_MAX_CONN_LIFETIME":             "duration",
+	"DB_MAX_CONN_IDLE_TIME":            "duration",
+	"DB_HEALTH_CHECK_PERIOD":           "duration",
+	"DB_ACQUIRE_TIMEOUT":               "duration",
+	"DB_POOL_STATS_INTERVAL":           "duration",
+	"DB_SLOW_QUERY_THRESHOLD":          "duration",
+	"DB_LOG_QUERIES":                   "bool",
+	"DB_RETRY_ATTEMPTS":                "int",
+	"DB_RETRY_BACKOFF":                 "duration",
+	"DB_RETRY_MAX_BACKOFF":             "duration",
+	"DB_TAG_REQUESTS":                  "bool",
+	"WEBHOOK_MAX_ATTEMPTS":             "int",
+	"WEBHOOK_RETRY_BACKOFF":            "duration",
+	"WEBHOOK_RETRY_MAX_BACKOFF":        "duration",
+	"WEBHOOK_TIMEOUT":                  "duration",
+	"TRASH_RETENTION":                  "duration",
+	"TRASH_PURGE_INTERVAL":             "duration",
+	"ACTIVITY_RETENTION":               "duration",
+	"SESSION_RETENTION":                "duration",
+	"RETENTION_INTERVAL":               "duration",
+	"APPROVAL_TTL":                     "duration",
+	"RESERVATION_TTL":                  "duration",
+	"RESERVATION_MAX_TTL":              "duration",
+	"SYNC_TIMEOUT":                     "duration",
+	"ALERT_INTERVAL":                   "duration",
+	"LOW_STOCK_QUANTITY":               "int",
+	"ALERT_FAILED_LOGINS_PER_HOUR":     "int",
+	"ALERT_LOW_STOCK_PRODUCTS":         "int",
+	"ALERT_MIN_ORDERS_PER_HOUR":        "int",
+	"ALERT_MIN_REGISTRATIONS_PER_HOUR": "int",
+	"SLO_WINDOW":                       "duration",
+	"SLO_BURN_RATE":                    "float",
+	"SLO_MIN_REQUESTS":                 "int",
+	"EVENT_QUEUE_SIZE":                 "int",
+	"OPENAPI_VALIDATION":               "bool",
+	"SMTP_PORT":                        "int",
+	"SHIPPING_MOCK_STEP":               "duration",
+	"SHIPPING_DEFAULT_WEIGHT_GRAMS":    "int",
+	"SHIPMENT_POLL_INTERVAL":           "duration",
+}
+
+// Validate checks the loaded values for consistency. Problems that make the
+// server unsafe or unable to start are returned as a *ValidationError; softer
+// issues are returned as warnings. In production warnings about secrets are
+// promoted to errors.
+func (c Config) Validate() (warnings []string, err error) {
+	var problems []string
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	addWarning := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	keys := make([]string, 0, len(typedEnv))
+	for key := range typedEnv {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		raw, ok := os.LookupEnv(key)
+		if !ok || raw == "" {
+			continue
+		}
+		if !parses(typedEnv[key], raw) {
+			addProblem("%s=%q is not a valid %s", key, raw, typedEnv[key])
+		}
+	}
+
+	for _, addr := range append(append([]string{}, c.ListenAddrs...), c.AdminAddrs...) {
+		if msg := checkListenAddr(addr); msg != "" {
+			addProblem("listen address %q: %s", addr, msg)
+		}
+	}
+
+	if c.DatabaseURL == "" {
+		addProblem("database configuration missing: provide DATABASE_URL or PG* env vars (on Railway: Service → Variables → +New → reference your database's DATABASE_URL)")
+	} else if msg := checkDSN(c.DatabaseURL); msg != "" {
+		addProblem("database URL: %s", msg)
+	}
+
+	switch c.TokenFormat {
+	case "jwt":
+		if c.JWTSecret == "" {
+			addProblem("JWT_SECRET is required")
+		} else {
+			var secretIssues []string
+			if weakSecrets[strings.ToLower(c.JWTSecret)] {
+				secretIssues = append(secretIssues, "JWT_SECRET is a well-known placeholder value")
+			}
+			if len(c.JWTSecret) < minJWTSecretLength {
+				secretIssues = append(secretIssues, fmt.Sprintf("JWT_SECRET is %d bytes; use at least %d", len(c.JWTSecret), minJWTSecretLength))
+			}
+			if bits := entropyBits(c.JWTSecret); bits < 128 {
+				secretIssues = append(secretIssues, fmt.Sprintf("JWT_SECRET has roughly %.0f bits of entropy; use at least 128 (e.g. `openssl rand -base64 48`)", bits))
+			}
+			for _, issue := range secretIssues {
+				if c.IsProduction() {
+					addProblem("%s", issue)
+				} else {
+					addWarning("%s", issue)
+				}
+			}
+		}
+	case "paseto":
+		if key, err := hex.DecodeString(c.PASETOKey); err != nil || len(key) != 32 {
+			addProblem("PASETO_KEY must be 32 bytes, hex encoded (e.g. `openssl rand -hex 32`)")
+		}
+	case "opaque":
+	default:
+		addProblem("TOKEN_FORMAT must be jwt, paseto or opaque, got %q", c.TokenFormat)
+	}
+
+	if c.JWTExpiry <= 0 {
+		addProblem("JWT_EXPIRY must be positive")
+	} else if c.JWTExpiry > 30*24*time.Hour {
+		addWarning("JWT_EXPIRY of %s is unusually long", c.JWTExpiry)
+	}
+	if c.RenewGrace < 0 {
+		addProblem("TOKEN_RENEW_GRACE must not be negative")
+	}
+	if c.LoginFailureDelay < 0 {
+		addProblem("LOGIN_FAILURE_DELAY must not be negative")
+	} else if c.LoginFailureDelay > 5*time.Second {
+		addWarning("LOGIN_FAILURE_DELAY of %s holds a connection open for every failed sign-in", c.LoginFailureDelay)
+	}
+	if _, err := c.ParsedPIIKeys(); err != nil {
+		addProblem("%s", err)
+	} else if len(c.PIIKeys) == 0 && c.IsProduction() {
+		addWarning("PII_ENCRYPTION_KEYS is not set, so customer phone numbers and addresses are stored in plain text")
+	}
+	if c.UserCacheTTL < 0 {
+		addProblem("AUTH_USER_CACHE_TTL must not be negative")
+	} else if c.UserCacheTTL > time.Minute {
+		addWarning("AUTH_USER_CACHE_TTL of %s lets other instances accept a revoked or demoted user for that long", c.UserCacheTTL)
+	}
+	for _, d := range c.Registration.Domains {
+		if strings.ContainsAny(strings.TrimPrefix(d, "@"), "@ ") || !strings.Contains(d, ".") {
+			addProblem("REGISTRATION_DOMAINS entries must be domain names like example.com, got %q", d)
+		}
+	}
+	for _, e := range c.Registration.AdminEmails {
+		if local, host, ok := strings.Cut(e, "@"); !ok || local == "" || host == "" {
+			addProblem("ADMIN_EMAILS entries must be email addresses, got %q", e)
+		}
+	}
+	if !c.Registration.Enabled && len(c.Registration.Domains) > 0 {
+		addWarning("REGISTRATION_DOMAINS has no effect while REGISTRATION_ENABLED is false")
+	}
+
+	for client, scopes := range c.TokenClients {
+		for _, scope := range scopes {
+			group, action, ok := strings.Cut(scope, ":")
+			if scope != "*" && (!ok || group == "" || action == "" || strings.ContainsAny(action, ": ")) {
+				addProblem("TOKEN_CLIENTS scope %q for client %q must be \"*\" or \"<group>:<action>\"", scope, client)
+			}
+		}
+	}
+	for client, limit := range c.Quotas.Limits {
+		if _, ok := c.TokenClients[client]; !ok {
+			addProblem("TOKEN_CLIENT_QUOTAS names client %q, which is not in TOKEN_CLIENTS", client)
+		}
+		if limit < 0 {
+			addProblem("TOKEN_CLIENT_QUOTAS limit for client %q must be a non-negative number of requests", client)
+		}
+	}
+	if c.Quotas.Enforcement != "warn" && c.Quotas.Enforcement != "block" {
+		addProblem("QUOTA_ENFORCEMENT must be warn or block, got %q", c.Quotas.Enforcement)
+	}
+	if c.Quotas.FlushInterval <= 0 {
+		addProblem("QUOTA_FLUSH_INTERVAL must be positive")
+	}
+
+	for name, seconds := range map[string]int{
+		"HTTP_READ_TIMEOUT":  c.ReadTimeoutSec,
+		"HTTP_WRITE_TIMEOUT": c.WriteTimeoutSec,
+		"HTTP_IDLE_TIMEOUT":  c.IdleTimeoutSec,
+	} {
+		if seconds <= 0 {
+			addProblem("%s must be a positive number of seconds", name)
+		}
+	}
+	if c.ReadRequestTimeout <= 0 || c.WriteRequestTimeout <= 0 || c.LongRequestTimeout <= 0 {
+		addProblem("REQUEST_TIMEOUT_READ, REQUEST_TIMEOUT_WRITE and REQUEST_TIMEOUT_LONG must be positive")
+	}
+	if c.LongRequestTimeout < c.WriteRequestTimeout {
+		addWarning("REQUEST_TIMEOUT_LONG (%s) is shorter than REQUEST_TIMEOUT_WRITE (%s)", c.LongRequestTimeout, c.WriteRequestTimeout)
+	}
+	if c.ShutdownDrainDelay < 0 {
+		addProblem("SHUTDOWN_DRAIN_DELAY must not be negative")
+	}
+	if c.ShutdownTimeout <= 0 {
+		addProblem("SHUTDOWN_TIMEOUT must be positive")
+	}
+	if c.CORS.MaxAge > 24*time.Hour {
+		addWarning("CORS_MAX_AGE of %s exceeds what browsers honour (24h)", c.CORS.MaxAge)
+	}
+	if c.CORS.AllowCredentials {
+		if slices.Contains(c.AllowedOrigins, "*") {
+			addProblem("CORS_ALLOW_CREDENTIALS cannot be combined with CORS_ALLOWED_ORIGINS=*, which would let any site send credentialed requests; list origins explicitly")
+		}
+		prefixes := make([]string, 0, len(c.CORS.RouteOrigins))
+		for prefix, origins := range c.CORS.RouteOrigins {
+			if slices.Contains(origins, "*") {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+		sort.Strings(prefixes)
+		for _, prefix := range prefixes {
+			addProblem("CORS_ALLOW_CREDENTIALS cannot be combined with origin * for %s in CORS_ROUTE_ORIGINS; list origins explicitly", prefix)
+		}
+	}
+	switch c.TrustedProxyHeader {
+	case ProxyHeaderXForwardedFor, ProxyHeaderForwarded, ProxyHeaderXRealIP:
+	default:
+		addProblem("TRUSTED_PROXY_HEADER must be %s, %s or %s, got %q", ProxyHeaderXForwardedFor, ProxyHeaderForwarded, ProxyHeaderXRealIP, c.TrustedProxyHeader)
+	}
+	for _, entry := range c.TrustedProxies {
+		if strings.EqualFold(entry, unixProxy) {
+			continue
+		}
+		if prefix, err := parseProxy(entry); err != nil {
+			addProblem("TRUSTED_PROXIES entry %q must be an IP address, a CIDR or %q", entry, unixProxy)
+		} else if prefix.Bits() == 0 {
+			addWarning("TRUSTED_PROXIES entry %s trusts every peer, so any client can choose its IP", entry)
+		}
+	}
+	if headers := c.SecurityHeaders; headers.Enabled {
+		if headers.HSTSMaxAge < 0 {
+			addProblem("HSTS_MAX_AGE must not be negative")
+		} else if headers.HSTSMaxAge%time.Second != 0 {
+			addProblem("HSTS_MAX_AGE must be a whole number of seconds")
+		}
+		if !validReferrerPolicies[headers.ReferrerPolicy] {
+			addProblem("REFERRER_POLICY %q is not a referrer policy", headers.ReferrerPolicy)
+		}
+		if c.IsProduction() && headers.HSTSMaxAge == 0 {
+			addWarning("HSTS_MAX_AGE=0 leaves Strict-Transport-Security out in production")
+		}
+	} else if c.IsProduction() {
+		addWarning("SECURITY_HEADERS=false leaves HSTS and the other security headers out in production")
+	}
+	if c.AccessLog.Format != "json" && c.AccessLog.Format != "text" {
+		addProblem("LOG_FORMAT must be json or text, got %q", c.AccessLog.Format)
+	}
+	for _, prefix := range c.AccessLog.Exclude {
+		if !strings.HasPrefix(prefix, "/") {
+			addProblem("ACCESS_LOG_EXCLUDE entries must be paths starting with /, got %q", prefix)
+		}
+	}
+	for _, prefix := range c.Recording.Routes {
+		if !strings.HasPrefix(prefix, "/") {
+			addProblem("DEBUG_RECORD_ROUTES entries must be paths starting with /, got %q", prefix)
+		}
+	}
+	if c.Recording.Size < 1 {
+		addProblem("DEBUG_RECORD_SIZE must be at least 1")
+	}
+	if (len(c.Recording.Users) > 0 || len(c.Recording.Routes) > 0) && c.IsProduction() {
+		addWarning("request recording (DEBUG_RECORD_USERS, DEBUG_RECORD_ROUTES) keeps redacted request and response bodies in memory; turn it off once done debugging")
+	}
+
+	pool := c.DatabasePool
+	if pool.MaxConns < 0 || pool.MinConns < 0 {
+		addProblem("DB_MAX_CONNS and DB_MIN_CONNS must not be negative")
+	}
+	if pool.MaxConns > 0 && pool.MinConns > pool.MaxConns {
+		addProblem("DB_MIN_CONNS (%d) exceeds DB_MAX_CONNS (%d)", pool.MinConns, pool.MaxConns)
+	}
+	if pool.MaxConnLifetime < 0 || pool.MaxConnIdleTime < 0 || pool.HealthCheckPeriod < 0 || pool.AcquireTimeout < 0 {
+		addProblem("DB_* durations must not be negative")
+	}
+	if pool.RetryAttempts < 1 {
+		addProblem("DB_RETRY_ATTEMPTS must be at least 1")
+	}
+	if pool.RetryBackoff < 0 || pool.RetryMaxBackoff < 0 {
+		addProblem("DB_RETRY_BACKOFF and DB_RETRY_MAX_BACKOFF must not be negative")
+	}
+	if pool.SaturationWarn < 0 || pool.SaturationWarn > 1 || pool.ShedThreshold < 0 || pool.ShedThreshold > 1 {
+		addProblem("DB_POOL_SATURATION_WARN and DB_POOL_SHED_THRESHOLD must be between 0 and 1")
+	}
+	if pool.ShedThreshold > 0 && pool.SaturationWarn > pool.ShedThreshold {
+		addWarning("DB_POOL_SHED_THRESHOLD (%g) is below DB_POOL_SATURATION_WARN (%g); requests are shed before the pool is reported saturated", pool.ShedThreshold, pool.SaturationWarn)
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		addProblem("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Webhooks.InitialBackoff < 0 || c.Webhooks.MaxBackoff < 0 || c.Webhooks.Timeout < 0 {
+		addProblem("WEBHOOK_* durations must not be negative")
+	}
+	for _, policy := range []struct {
+		name      string
+		retention time.Duration
+	}{
+		{"TRASH_RETENTION", c.Retention.Trash},
+		{"ACTIVITY_RETENTION", c.Retention.Activity},
+		{"SESSION_RETENTION", c.Retention.Sessions},
+	} {
+		switch {
+		case policy.retention < 0:
+			addProblem("%s must not be negative", policy.name)
+		case policy.retention%(24*time.Hour) != 0:
+			addWarning("%s of %s is rounded up to %d days", policy.name, policy.retention, RetentionDays(policy.retention))
+		}
+	}
+	if c.Retention.Interval <= 0 {
+		addProblem("RETENTION_INTERVAL must be positive")
+	}
+	if c.Reservations.TTL <= 0 || c.Reservations.MaxTTL <= 0 {
+		addProblem("RESERVATION_TTL and RESERVATION_MAX_TTL must be positive")
+	} else if c.Reservations.TTL > c.Reservations.MaxTTL {
+		addProblem("RESERVATION_TTL must not exceed RESERVATION_MAX_TTL")
+	}
+	if c.Sync.Timeout <= 0 {
+		addProblem("SYNC_TIMEOUT must be positive")
+	}
+	connectorNames := map[string]bool{}
+	for _, connector := range c.Sync.Connectors {
+		switch {
+		case connector.Name == "":
+			addProblem("SYNC_CONNECTORS_FILE: every connector needs a name")
+		case connectorNames[connector.Name]:
+			addProblem("SYNC_CONNECTORS_FILE: connector name %q is used twice", connector.Name)
+		}
+		connectorNames[connector.Name] = true
+		switch connector.Type {
+		case "shopify", "csv", "rest":
+		default:
+			addProblem("SYNC_CONNECTORS_FILE: connector %q type must be shopify, csv or rest, got %q", connector.Name, connector.Type)
+		}
+		if connector.Interval < 0 {
+			addProblem("SYNC_CONNECTORS_FILE: connector %q interval must not be negative", connector.Name)
+		} else if connector.Interval > 0 && connector.Interval < time.Minute {
+			addWarning("sync connector %q runs every %s; external APIs may rate limit it", connector.Name, connector.Interval)
+		}
+	}
+	alerts := c.Alerts
+	if alerts.Interval <= 0 {
+		addProblem("ALERT_INTERVAL must be positive")
+	}
+	if alerts.LowStockQuantity < 0 {
+		addProblem("LOW_STOCK_QUANTITY must not be negative")
+	}
+	if alerts.FailedLoginsPerHour < 0 || alerts.LowStockProducts < 0 || alerts.MinOrdersPerHour < 0 || alerts.MinRegistrationsPerHour < 0 {
+		addProblem("ALERT_* thresholds must not be negative")
+	}
+	if alerts.SlackWebhookURL != "" {
+		if msg := checkBrokerURL(alerts.SlackWebhookURL, "https", "http"); msg != "" {
+			addProblem("ALERT_SLACK_WEBHOOK_URL %s", msg)
+		}
+	} else if alerts.enabled() {
+		addWarning("alert thresholds are set but ALERT_SLACK_WEBHOOK_URL is not; alerts are only logged")
+	}
+	for _, slo := range alerts.SLOs {
+		if !strings.HasPrefix(slo.Prefix, "/") {
+			addProblem("SLO_TARGETS prefix %q must start with /", slo.Prefix)
+		}
+		if slo.Objective <= 0 || slo.Objective >= 100 {
+			addProblem("SLO_TARGETS objective for %s must be a percentage between 0 and 100, exclusive", slo.Prefix)
+		}
+		if slo.Latency < 0 {
+			addProblem("SLO_TARGETS latency for %s must be a positive duration", slo.Prefix)
+		}
+	}
+	if len(alerts.SLOs) > 0 {
+		if alerts.SLOWindow <= 0 {
+			addProblem("SLO_WINDOW must be positive")
+		}
+		if alerts.SLOBurnRate <= 0 {
+			addProblem("SLO_BURN_RATE must be positive")
+		}
+		if alerts.SLOMinRequests < 0 {
+			addProblem("SLO_MIN_REQUESTS must not be negative")
+		}
+	}
+	for _, action := range c.Approvals.Actions {
+		if !slices.Contains(approvalActions, action) {
+			addProblem("APPROVAL_ACTIONS: unknown action %q (supported: %s)", action, strings.Join(approvalActions, ", "))
+		}
+	}
+	if c.Approvals.TTL <= 0 {
+		addProblem("APPROVAL_TTL must be positive")
+	}
+	if c.Invoices.Template != "" {
+		if _, err := os.Stat(c.Invoices.Template); err != nil {
+			addProblem("INVOICE_TEMPLATE: %v", err)
+		}
+	}
+	if c.Mail.Host != "" {
+		if c.Mail.Port <= 0 || c.Mail.Port > 65535 {
+			addProblem("SMTP_PORT must be a port number")
+		}
+		if _, err := mail.ParseAddress(c.Mail.From); err != nil {
+			addProblem("SMTP_FROM must be an email address when SMTP_HOST is set")
+		}
+	}
+	for _, provider := range c.Payments.Providers {
+		switch provider {
+		case "mock":
+			if c.IsProduction() {
+				addProblem("PAYMENT_PROVIDERS: the mock provider is not allowed in production")
+			}
+			if c.Payments.MockSecret == "" {
+				addProblem("PAYMENT_MOCK_SECRET is required for the mock provider")
+			}
+		case "stripe":
+			if c.Payments.StripeSecretKey == "" {
+				addProblem("STRIPE_SECRET_KEY is required for the stripe provider")
+			}
+			if c.Payments.StripeWebhookSecret == "" {
+				addProblem("STRIPE_WEBHOOK_SECRET is required for the stripe provider")
+			}
+			if msg := checkBrokerURL(c.Payments.SuccessURL, "https", "http"); msg != "" {
+				addProblem("PAYMENT_SUCCESS_URL %s", msg)
+			}
+			if c.Payments.CancelURL != "" {
+				if msg := checkBrokerURL(c.Payments.CancelURL, "https", "http"); msg != "" {
+					addProblem("PAYMENT_CANCEL_URL %s", msg)
+				}
+			}
+		default:
+			addProblem("PAYMENT_PROVIDERS: unknown provider %q (supported: mock, stripe)", provider)
+		}
+	}
+	if len(c.Payments.Providers) > 0 && !validCurrency(c.Payments.Currency) {
+		addProblem("PAYMENT_CURRENCY must be a three-letter currency code, got %q", c.Payments.Currency)
+	}
+	for _, carrier := range c.Shipping.Carriers {
+		switch carrier {
+		case "mock":
+			if c.IsProduction() {
+				addProblem("SHIPPING_CARRIERS: the mock carrier is not allowed in production")
+			}
+			if c.Shipping.MockSecret == "" {
+				addProblem("SHIPPING_MOCK_SECRET is required for the mock carrier")
+			}
+			if c.Shipping.MockStep <= 0 {
+				addProblem("SHIPPING_MOCK_STEP must be positive")
+			}
+		case "easypost":
+			if c.Shipping.EasyPostAPIKey == "" {
+				addProblem("EASYPOST_API_KEY is required for the easypost carrier")
+			}
+			if c.Shipping.EasyPostWebhookSecret == "" {
+				addWarning("EASYPOST_WEBHOOK_SECRET is not set; easypost tracking is only updated by polling")
+			}
+			if c.Shipping.From.Line1 == "" || c.Shipping.From.City == "" || c.Shipping.From.Country == "" {
+				addProblem("SHIP_FROM_LINE1, SHIP_FROM_CITY and SHIP_FROM_COUNTRY are required for the easypost carrier")
+			}
+		default:
+			addProblem("SHIPPING_CARRIERS: unknown carrier %q (supported: mock, easypost)", carrier)
+		}
+	}
+	if len(c.Shipping.Carriers) > 0 {
+		if c.Shipping.DefaultWeightGrams <= 0 {
+			addProblem("SHIPPING_DEFAULT_WEIGHT_GRAMS must be positive")
+		}
+		if c.Shipping.PollInterval <= 0 {
+			addProblem("SHIPMENT_POLL_INTERVAL must be positive")
+		}
+	}
+	if !validLocale(c.ProductLocale) {
+		addProblem("PRODUCT_LOCALE must be a language such as en or lo-LA, got %q", c.ProductLocale)
+	}
+	switch c.Events.Broker {
+	case "none":
+	case "nats":
+		if msg := checkBrokerURL(c.Events.NATSURL, "nats", "tcp"); msg != "" {
+			addProblem("NATS_URL %s", msg)
+		}
+	case "kafka":
+		if msg := checkBrokerURL(c.Events.KafkaRESTURL, "http", "https"); msg != "" {
+			addProblem("KAFKA_REST_URL %s", msg)
+		}
+		if strings.TrimSpace(c.Events.KafkaTopic) == "" {
+			addProblem("KAFKA_TOPIC must not be empty")
+		}
+	default:
+		addProblem("EVENT_BROKER must be none, nats or kafka, got %q", c.Events.Broker)
+	}
+	if c.SentryDSN != "" {
+		if parsed, err := neturl.Parse(c.SentryDSN); err != nil || parsed.User == nil || parsed.Host == "" {
+			addProblem("SENTRY_DSN must look like https://<key>@<host>/<project id>")
+		}
+	}
+	if c.QueryLog.SlowThreshold < 0 {
+		addProblem("DB_SLOW_QUERY_THRESHOLD must not be negative")
+	}
+	switch pool.StatementCacheMode {
+	case "", "cache_statement", "cache_describe", "describe_exec", "exec", "simple_protocol":
+	default:
+		addProblem("DB_STATEMENT_CACHE_MODE must be cache_statement, cache_describe, describe_exec, exec or simple_protocol, got %q", pool.StatementCacheMode)
+	}
+
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		addProblem("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	if c.RateLimit.RequestsPerSecond < 0 {
+		addProblem("RATE_LIMIT_RPS must not be negative")
+	}
+	if c.RateLimit.RequestsPerSecond > 0 && c.RateLimit.Burst < 1 {
+		addProblem("RATE_LIMIT_BURST must be at least 1 when rate limiting is enabled")
+	}
+
+	if len(problems) > 0 {
+		return warnings, &ValidationError{Problems: problems}
+	}
+	return warnings, nil
+}
+
+// Summary returns the effective configuration with credentials redacted, one
+// "key: value" line per setting, for the startup log.
+func (c Config) Summary() []string {
+	lines := []string{
+		"environment: " + c.Environment,
+		"listen: " + strings.Join(c.ListenAddrs, ", "),
+		"admin listen: " + strings.Join(c.AdminAddrs, ", "),
+		"database: " + RedactDSN(c.DatabaseURL),
+		fmt.Sprintf("database pool: max=%d min=%d lifetime=%s idle=%s acquire_timeout=%s mode=%s",
+			c.DatabasePool.MaxConns, c.DatabasePool.MinConns, c.DatabasePool.MaxConnLifetime, c.DatabasePool.MaxConnIdleTime,
+			c.DatabasePool.AcquireTimeout, c.DatabasePool.StatementCacheMode),
+		fmt.Sprintf("database retries: attempts=%d backoff=%s max=%s",
+			c.DatabasePool.RetryAttempts, c.DatabasePool.RetryBackoff, c.DatabasePool.RetryMaxBackoff),
+		fmt.Sprintf("database application name: %q tag_requests=%t", c.DatabasePool.ApplicationName, c.DatabasePool.TagRequests),
+		fmt.Sprintf("database saturation: warn=%g shed=%g", c.DatabasePool.SaturationWarn, c.DatabasePool.ShedThreshold),
+		"token format: " + c.TokenFormat,
+		"jwt secret: " + redactSecret(c.JWTSecret),
+		"paseto key: " + redactSecret(c.PASETOKey),
+		"pii encryption: " + c.piiKeysSummary(),
+		"jwt issuer: " + c.JWTIssuer,
+		"jwt expiry: " + c.JWTExpiry.String(),
+		"token renew grace: " + c.RenewGrace.String(),
+		"login failure delay: up to " + c.LoginFailureDelay.String(),
+		"user cache ttl: " + c.UserCacheTTL.String(),
+		"registration: " + c.registrationSummary(),
+		"bootstrap admins: " + c.adminEmailsSummary(),
+		"jwt audience: " + c.jwtAudienceSummary(),
+		"token clients: " + formatClients(c.TokenClients),
+		"client quotas: " + c.Quotas.summary(),
+		"cors origins: " + strings.Join(c.AllowedOrigins, ", "),
+		"cors credentials: " + strconv.FormatBool(c.CORS.AllowCredentials),
+		"security headers: " + c.SecurityHeaders.summary(),
+		fmt.Sprintf("http timeouts: read=%ds write=%ds idle=%ds", c.ReadTimeoutSec, c.WriteTimeoutSec, c.IdleTimeoutSec),
+		fmt.Sprintf("request timeouts: read=%s write=%s long=%s", c.ReadRequestTimeout, c.WriteRequestTimeout, c.LongRequestTimeout),
+		fmt.Sprintf("shutdown: drain=%s timeout=%s", c.ShutdownDrainDelay, c.ShutdownTimeout),
+		"log format: " + c.AccessLog.Format,
+		"access log excludes: " + c.accessLogExcludeSummary(),
+		"log level: " + c.LogLevel,
+		fmt.Sprintf("rate limit: %g req/s burst %d", c.RateLimit.RequestsPerSecond, c.RateLimit.Burst),
+		"trusted proxies: " + c.trustedProxiesSummary(),
+		"feature flags: " + formatFlags(c.FeatureFlags),
+		"event broker: " + c.Events.summary(),
+		"error reporting: " + c.errorReportingSummary(),
+		fmt.Sprintf("query log: slow>=%s all=%t", c.QueryLog.SlowThreshold, c.QueryLog.All),
+		"schema validation: " + c.schemaValidationSummary(),
+		"retention: " + c.Retention.summary(),
+		fmt.Sprintf("reservations: ttl=%s max=%s", c.Reservations.TTL, c.Reservations.MaxTTL),
+		"sync connectors: " + c.Sync.summary(),
+		"alerts: " + c.Alerts.summary(),
+		"approvals: " + c.Approvals.summary(),
+		"invoices: " + c.Invoices.summary(),
+		"mail: " + c.Mail.summary(),
+		"payments: " + c.Payments.summary(),
+		"shipping: " + c.Shipping.summary(),
+		"product locale: " + c.ProductLocale,
+	}
+	return lines
+}
+
+func (s SyncConfig) summary() string {
+	if len(s.Connectors) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(s.Connectors))
+	for _, connector := range s.Connectors {
+		schedule := "on demand"
+		if connector.Interval > 0 {
+			schedule = "every " + connector.Interval.String()
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s, %s)", connector.Name, connector.Type, schedule))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (a ApprovalConfig) summary() string {
+	if len(a.Actions) == 0 {
+		return "none required"
+	}
+	return fmt.Sprintf("%s, expire after %s", strings.Join(a.Actions, ", "), a.TTL)
+}
+
+func (i InvoiceConfig) summary() string {
+	layout := "built-in layout"
+	if i.Template != "" {
+		layout = "template " + i.Template
+	}
+	return fmt.Sprintf("%s, %s", i.CompanyName, layout)
+}
+
+func (m MailConfig) summary() string {
+	if m.Host == "" {
+		return "off"
+	}
+	// The password is a credential.
+	return fmt.Sprintf("smtp %s:%d from %s", m.Host, m.Port, m.From)
+}
+
+// validCurrency reports whether code looks like an ISO 4217 code, such as
+// "USD".
+func validCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (p PaymentConfig) summary() string {
+	if len(p.Providers) == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%s in %s", strings.Join(p.Providers, ", "), p.Currency)
+}
+
+func (s ShippingConfig) summary() string {
+	if len(s.Carriers) == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%s, polled every %s", strings.Join(s.Carriers, ", "), s.PollInterval)
+}
+
+func (a AlertConfig) enabled() bool {
+	return a.FailedLoginsPerHour > 0 || a.LowStockProducts > 0 || a.MinOrdersPerHour > 0 || a.MinRegistrationsPerHour > 0 || len(a.SLOs) > 0
+}
+
+func (a AlertConfig) summary() string {
+	if !a.enabled() {
+		return fmt.Sprintf("off (gauges refreshed every %s, low stock<=%d)", a.Interval, a.LowStockQuantity)
+	}
+	target := "log"
+	if a.SlackWebhookURL != "" {
+		// The webhook URL is a credential.
+		target = "slack"
+	}
+	summary := fmt.Sprintf("%s every %s, low stock<=%d, failed logins/h>=%d, low stock products>=%d, orders/h<%d, registrations/h<%d",
+		target, a.Interval, a.LowStockQuantity, a.FailedLoginsPerHour, a.LowStockProducts, a.MinOrdersPerHour, a.MinRegistrationsPerHour)
+	if len(a.SLOs) == 0 {
+		return summary
+	}
+	slos := make([]string, len(a.SLOs))
+	for i, slo := range a.SLOs {
+		slos[i] = fmt.Sprintf("%s %g%%", slo.Prefix, slo.Objective)
+		if slo.Latency > 0 {
+			slos[i] += " within " + slo.Latency.String()
+		}
+	}
+	return summary + fmt.Sprintf(", slo burn>=%g over %s (%s)", a.SLOBurnRate, a.SLOWindow, strings.Join(slos, ", "))
+}
+
+func (r RetentionConfig) summary() string {
+	days := func(d time.Duration) string {
+		if d == 0 {
+			return "forever"
+		}
+		return fmt.Sprintf("%dd", RetentionDays(d))
+	}
+	return fmt.Sprintf("trash %s, activity %s, sessions %s by default, purged every %s",
+		days(r.Trash), days(r.Activity), days(r.Sessions), r.Interval)
+}
+
+// RetentionDays converts a retention duration to whole days, rounding up.
+func RetentionDays(d time.Duration) int {
+	return int((d + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// RedactDSN hides the password component of a connection URL.
+func RedactDSN(dsn string) string {
+	if dsn == "" {
+		return "(unset)"
+	}
+	parsed, err := neturl.Parse(dsn)
+	if err != nil {
+		return "(unparseable)"
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = neturl.UserPassword(parsed.User.Username(), "xxxxx")
+	}
+	return parsed.String()
+}
+
+func (c Config) schemaValidationSummary() string {
+	switch {
+	case !c.SchemaValidation:
+		return "off"
+	case c.IsProduction():
+		return "requests"
+	default:
+		return "requests and responses"
+	}
+}
+
+func redactSecret(secret string) string {
+	if secret == "" {
+		return "(unset)"
+	}
+	return fmt.Sprintf("(set, %d bytes)", len(secret))
+}
+
+func parses(kind, raw string) bool {
+	var err error
+	switch kind {
+	case "duration":
+		_, err = time.ParseDuration(raw)
+	case "int":
+		_, err = strconv.Atoi(raw)
+	case "bool":
+		_, err = strconv.ParseBool(raw)
+	case "float":
+		_, err = strconv.ParseFloat(raw, 64)
+	case "octal":
+		_, err = strconv.ParseUint(raw, 8, 32)
+	}
+	return err == nil
+}
+
+func checkListenAddr(addr string) string {
+	if strings.HasPrefix(addr, "unix:") {
+		if strings.TrimPrefix(addr, "unix:") == "" {
+			return "unix socket path is empty"
+		}
+		return ""
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err.Error()
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 0 || n > 65535 {
+		return "port must be a number between 0 and 65535"
+	}
+	return ""
+}
+
+func checkDSN(dsn string) string {
+	parsed, err := neturl.Parse(dsn)
+	if err != nil {
+		return "cannot be parsed"
+	}
+	if parsed.Scheme != "postgres" && parsed.Scheme != "postgresql" {
+		return fmt.Sprintf("unsupported scheme %q", parsed.Scheme)
+	}
+	if parsed.Hostname() == "" {
+		return "host is missing"
+	}
+	if port := parsed.Port(); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Sprintf("invalid port %q", port)
+		}
+	}
+	if strings.TrimPrefix(parsed.Path, "/") == "" {
+		return "database name is missing"
+	}
+	return ""
+}
+
+// entropyBits estimates the entropy of s from its character distribution.
+func entropyBits(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var perChar float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		perChar -= p * math.Log2(p)
+	}
+	return perChar * float64(total)
+}
+
+func formatFlags(flags map[string]bool) string {
+	names := make([]string, 0, len(flags))
+	for name, enabled := range flags {
+		if !enabled {
+			name = "-" + name
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (c Config) jwtAudienceSummary() string {
+	if c.JWTAudience == "" {
+		return "(none)"
+	}
+	return c.JWTAudience
+}
+
+func (q QuotaConfig) summary() string {
+	if len(q.Limits) == 0 {
+		return "none"
+	}
+	entries := make([]string, 0, len(q.Limits))
+	for client, limit := range q.Limits {
+		entries = append(entries, fmt.Sprintf("%s=%d/month", client, limit))
+	}
+	sort.Strings(entries)
+	return fmt.Sprintf("%s (%s, flushed every %s)", strings.Join(entries, ", "), q.Enforcement, q.FlushInterval)
+}
+
+func formatClients(clients map[string][]string) string {
+	if len(clients) == 0 {
+		return "(none)"
+	}
+	entries := make([]string, 0, len(clients))
+	for client, scopes := range clients {
+		entries = append(entries, client+"="+strings.Join(scopes, "|"))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ", ")
+}
+
+func checkBrokerURL(raw string, schemes ...string) string {
+	parsed, err := neturl.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "must be an absolute URL"
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return ""
+		}
+	}
+	return "must use the " + strings.Join(schemes, " or ") + " scheme"
+}
+
+func (e EventBrokerConfig) summary() string {
+	switch e.Broker {
+	case "nats":
+		return "nats " + RedactDSN(e.NATSURL) + " subjects " + e.SubjectPrefix + ".*"
+	case "kafka":
+		return "kafka " + RedactDSN(e.KafkaRESTURL) + " topic " + e.KafkaTopic
+	default:
+		return e.Broker
+	}
+}
+
+func (c Config) errorReportingSummary() string {
+	if c.SentryDSN == "" {
+		return "log"
+	}
+	parsed, err := neturl.Parse(c.SentryDSN)
+	if err != nil {
+		return "sentry (unparseable)"
+	}
+	return "sentry " + parsed.Host + parsed.Path
+}
+
+// validReferrerPolicies are the values browsers accept in Referrer-Policy.
+var validReferrerPolicies = map[string]bool{
+	"no-referrer":                     true,
+	"no-referrer-when-downgrade":      true,
+	"origin":                          true,
+	"origin-when-cross-origin":        true,
+	"same-origin":                     true,
+	"strict-origin":                   true,
+	"strict-origin-when-cross-origin": true,
+	"unsafe-url":                      true,
+}
+
+func (h SecurityHeadersConfig) summary() string {
+	if !h.Enabled {
+		return "off"
+	}
+	hsts := "off"
+	if h.HSTSMaxAge > 0 {
+		hsts = h.HSTSMaxAge.String()
+		if h.HSTSIncludeSubdomains {
+			hsts += " incl. subdomains"
+		}
+	}
+	return fmt.Sprintf("hsts=%s referrer=%s csp=%q", hsts, h.ReferrerPolicy, h.ContentSecurityPolicy)
+}
+
+func (c Config) piiKeysSummary() string {
+	keys, err := c.ParsedPIIKeys()
+	if err != nil || len(keys) == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("primary key %s, %d keys", keys[0].ID, len(keys))
+}
+
+func (c Config) trustedProxiesSummary() string {
+	if len(c.TrustedProxies) == 0 {
+		return "none (forwarding headers ignored)"
+	}
+	return strings.Join(c.TrustedProxies, ", ") + " (via " + c.TrustedProxyHeader + ")"
+}
+
+func (c Config) accessLogExcludeSummary() string {
+	if len(c.AccessLog.Exclude) == 0 {
+		return "none"
+	}
+	return strings.Join(c.AccessLog.Exclude, ", ")
+}
+
+func (c Config) registrationSummary() string {
+	switch {
+	case !c.Registration.Enabled:
+		return "closed"
+	case len(c.Registration.Domains) > 0:
+		return "open to " + strings.Join(c.Registration.Domains, ", ")
+	default:
+		return "open"
+	}
+}
+
+func (c Config) adminEmailsSummary() string {
+	if len(c.Registration.AdminEmails) == 0 {
+		return "first user to register"
+	}
+	return strings.Join(c.Registration.AdminEmails, ", ")
+}
+
+// validLocale reports whether tag is a language, optionally with a region,
+// such as "lo" or "lo-LA".
+func validLocale(tag string) bool {
+	language, region, hasRegion := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	if len(language) < 2 || len(language) > 3 || !isLetters(language) {
+		return false
+	}
+	return !hasRegion || (len(region) == 2 && isLetters(region))
+}
+
+func isLetters(s string) bool {
+	for _, r := range strings.ToLower(s) {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
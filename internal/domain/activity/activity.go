@@ -0,0 +1,100 @@
+// Package activity describes the audit trail of changes made to users,
+// products and categories.
+package activity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Entry records one change and who made it.
+type Entry struct {
+	ID         string    `json:"id"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// ActorID is empty for changes made by background jobs.
+	ActorID string `json:"actorId"`
+	// ActorIP is the address the change was requested from. It is not
+	// chained, so it can be erased when the actor is anonymized.
+	ActorIP string `json:"actorIp,omitempty"`
+	// ActorName is resolved when entries are listed and is empty when the
+	// actor no longer exists.
+	ActorName  string `json:"actorName"`
+	EntityType string `json:"entityType"`
+	Action     string `json:"action"`
+	EntityID   string `json:"entityId"`
+	// EntityName is the record's label when the change happened. Like
+	// ActorIP it is not chained, since a user's label is their name or
+	// email.
+	EntityName string `json:"entityName"`
+	// Seq orders entries in the hash chain, in the order they were recorded.
+	Seq int64 `json:"seq,omitempty"`
+	// PrevHash is the Hash of the entry recorded before this one, empty for
+	// the first entry ever recorded.
+	PrevHash string `json:"prevHash,omitempty"`
+	// Hash is ChainHash(PrevHash), set by the repository when it records
+	// the entry.
+	Hash string `json:"hash,omitempty"`
+}
+
+// ChainAlgorithm names the hash function behind ChainHash.
+const ChainAlgorithm = "sha256"
+
+// ChainHash returns the hex SHA-256 of prev and the entry's chained fields,
+// each written as its length in bytes, a colon, the value and a newline, so
+// no two different entries hash the same input. The timestamp is written in
+// UTC with microseconds, the precision PostgreSQL keeps. The chain covers
+// who changed what and when; ActorIP and EntityName are personal data that
+// anonymizing a user erases, so they are left out. activity_chain_hash,
+// installed by migration 0037, computes the same hash in SQL; the two must
+// not diverge.
+func (e *Entry) ChainHash(prev string) string {
+	h := sha256.New()
+	for _, field := range []string{
+		prev,
+		e.ID,
+		e.OccurredAt.UTC().Format("2006-01-02T15:04:05.000000") + "Z",
+		e.ActorID,
+		e.EntityType,
+		e.Action,
+		e.EntityID,
+	} {
+		fmt.Fprintf(h, "%d:%s\n", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Position identifies an entry in the feed's newest-first order.
+type Position struct {
+	OccurredAt time.Time
+	ID         string
+}
+
+// Filter selects entries. Empty fields match everything; After, when set,
+// skips entries up to and including that position.
+type Filter struct {
+	ActorID    string
+	EntityType string
+	EntityID   string
+	Action     string
+	After      *Position
+	Limit      int
+}
+
+// Repository stores the audit trail.
+type Repository interface {
+	// Record appends entry to the hash chain, setting its Seq, PrevHash and
+	// Hash. Entries are chained one at a time, in the order recorded.
+	Record(ctx context.Context, entry *Entry) error
+	// List returns matching entries, newest first.
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+	// ChainRange returns the Seq of the first and last entries that occurred
+	// in [from, to), both zero when there are none. A zero from or to leaves
+	// that end open.
+	ChainRange(ctx context.Context, from, to time.Time) (first, last int64, err error)
+	// Chain returns up to limit entries with first <= Seq <= last, in chain
+	// order.
+	Chain(ctx context.Context, first, last int64, limit int) ([]*Entry, error)
+}
@@ -0,0 +1,45 @@
+package activity_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"backoffice/backend/internal/domain/activity"
+)
+
+// chainFixture is one case of testdata/chain_hash.json, which the
+// PostgreSQL tests also check activity_chain_hash against.
+type chainFixture struct {
+	Name  string         `json:"name"`
+	Prev  string         `json:"prev"`
+	Entry activity.Entry `json:"entry"`
+	Hash  string         `json:"hash"`
+}
+
+func TestChainHash(t *testing.T) {
+	raw, err := os.ReadFile("testdata/chain_hash.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []chainFixture
+	if err := json.Unmarshal(raw, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			if got := f.Entry.ChainHash(f.Prev); got != f.Hash {
+				t.Fatalf("ChainHash = %s, want %s", got, f.Hash)
+			}
+			// Personal data is left out of the chain so it can be erased.
+			unchained := f.Entry
+			unchained.ActorIP, unchained.EntityName, unchained.ActorName = "203.0.113.9", "Jane Doe", "Jane"
+			if got := unchained.ChainHash(f.Prev); got != f.Hash {
+				t.Fatalf("ChainHash with unchained fields set = %s, want %s", got, f.Hash)
+			}
+			if got := f.Entry.ChainHash(f.Prev + "0"); got == f.Hash {
+				t.Fatal("ChainHash ignores prev")
+			}
+		})
+	}
+}
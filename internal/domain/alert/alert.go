@@ -0,0 +1,25 @@
+// Package alert describes threshold alerts raised from business metrics.
+package alert
+
+import (
+	"context"
+	"time"
+)
+
+// Alert reports that a check crossed its threshold, or recovered from an
+// earlier breach when Firing is false.
+type Alert struct {
+	// Check names the measurement, such as "failed_logins_last_hour".
+	Check     string
+	Firing    bool
+	Value     float64
+	Threshold float64
+	// Message is a human-readable summary suitable for chat.
+	Message string
+	At      time.Time
+}
+
+// Notifier delivers alerts, for example to a chat channel.
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
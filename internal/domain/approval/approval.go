@@ -0,0 +1,80 @@
+// Package approval describes destructive admin actions that wait for a
+// second admin to confirm them.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Actions that can be configured to require approval.
+const (
+	ActionDeleteUser      = "user.delete"
+	ActionBulkPriceUpdate = "product.bulk_price_update"
+)
+
+// Actions lists every action in a stable order.
+var Actions = []string{ActionDeleteUser, ActionBulkPriceUpdate}
+
+// Statuses an approval moves through. An approved action is running and
+// ends as executed or failed.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusExecuted = "executed"
+	StatusFailed   = "failed"
+	StatusRejected = "rejected"
+	StatusExpired  = "expired"
+)
+
+// Statuses lists every status in a stable order.
+var Statuses = []string{StatusPending, StatusApproved, StatusExecuted, StatusFailed, StatusRejected, StatusExpired}
+
+var (
+	// ErrNotFound indicates the approval does not exist.
+	ErrNotFound = errors.New("approval not found")
+	// ErrNotPending indicates the approval was already decided or expired.
+	ErrNotPending = errors.New("approval is no longer pending")
+	// ErrSelfApproval rejects an admin approving their own request.
+	ErrSelfApproval = errors.New("approval must come from a different admin")
+)
+
+// Approval is a request to run Action with Payload once a second admin
+// agrees.
+type Approval struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Status string `json:"status"`
+	// Subject identifies what the action applies to, such as a user id.
+	Subject string          `json:"subject,omitempty"`
+	Summary string          `json:"summary"`
+	Payload json.RawMessage `json:"payload"`
+	// Error explains why an approved action failed; Reason is the
+	// explanation given with a rejection.
+	Error       string     `json:"error,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	RequestedBy string     `json:"requestedBy"`
+	DecidedBy   string     `json:"decidedBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty"`
+}
+
+// Repository persists approvals.
+type Repository interface {
+	Create(ctx context.Context, approval *Approval) error
+	GetByID(ctx context.Context, id string) (*Approval, error)
+	// List returns approvals with the status, or all when it is empty,
+	// newest first.
+	List(ctx context.Context, status string) ([]*Approval, error)
+	// Decide records the outcome of a pending approval and returns
+	// ErrNotPending if it was decided concurrently.
+	Decide(ctx context.Context, approval *Approval) error
+	// Finish records the result of executing an approved action.
+	Finish(ctx context.Context, approval *Approval) error
+	// Expire marks pending approvals whose expiry has passed by now as
+	// expired.
+	Expire(ctx context.Context, now time.Time) (int, error)
+}
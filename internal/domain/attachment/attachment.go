@@ -0,0 +1,28 @@
+// Package attachment describes files the service generates and keeps, such
+// as invoices, so they are produced once and served again as stored.
+package attachment
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound indicates nothing is stored under the key.
+var ErrNotFound = errors.New("attachment not found")
+
+// Attachment is a stored file. Key names it, like a path: "invoices/<id>".
+type Attachment struct {
+	Key         string
+	Name        string
+	ContentType string
+	Data        []byte
+	CreatedAt   time.Time
+}
+
+// Store keeps attachments.
+type Store interface {
+	Get(ctx context.Context, key string) (*Attachment, error)
+	// Put stores an attachment, replacing any under the same key.
+	Put(ctx context.Context, attachment *Attachment) error
+}
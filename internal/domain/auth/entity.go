@@ -1,27 +1,43 @@
 package auth
 
 import (
-	"errors"
 	"time"
+
+	"backoffice/backend/internal/domain/errcode"
 )
 
 var (
 	// ErrInvalidCredentials indicates a login failure.
-	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidCredentials = errcode.New(errcode.Unauthenticated, "email_password_invalid", "invalid email or password")
 	// ErrEmailExists signals a duplicate email registration.
-	ErrEmailExists = errors.New("email already registered")
+	ErrEmailExists = errcode.New(errcode.Conflict, "email_exists", "email already registered")
 	// ErrTokenInvalid means a supplied token cannot be validated.
-	ErrTokenInvalid = errors.New("token invalid or expired")
+	ErrTokenInvalid = errcode.New(errcode.Unauthenticated, "token_invalid_or_expired", "token invalid or expired")
 	// ErrUserNotFound indicates missing user.
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound = errcode.New(errcode.NotFound, "user_not_found", "user not found")
 	// ErrInvalidRole indicates the provided role is not supported.
-	ErrInvalidRole = errors.New("invalid role")
+	ErrInvalidRole = errcode.New(errcode.Invalid, "role_invalid", "invalid role")
 	// ErrPasswordMismatch indicates the current password is incorrect.
-	ErrPasswordMismatch = errors.New("current password does not match")
+	ErrPasswordMismatch = errcode.New(errcode.Invalid, "password_current_incorrect", "current password is incorrect")
+	// ErrPasswordTooLong indicates a password bcrypt cannot hash.
+	ErrPasswordTooLong = errcode.New(errcode.Invalid, "password_too_long", "password must be at most 72 bytes")
 	// ErrPasswordUnchanged indicates the new password matches the current one.
-	ErrPasswordUnchanged = errors.New("new password must be different from current password")
+	ErrPasswordUnchanged = errcode.New(errcode.Invalid, "password_unchanged", "new password must be different from current password")
+	// ErrSessionsUnsupported indicates session management while the token
+	// format keeps no server-side state.
+	ErrSessionsUnsupported = errcode.New(errcode.Unavailable, "sessions_unsupported", "sessions are only tracked for opaque tokens")
+	// ErrLastAdmin prevents demoting or deleting the only remaining admin.
+	ErrLastAdmin = errcode.New(errcode.Conflict, "last_admin", "cannot demote or delete the last admin")
+	// ErrRegistrationClosed is returned when public registration is disabled.
+	ErrRegistrationClosed = errcode.New(errcode.Forbidden, "registration_closed", "registration is closed")
+	// ErrEmailDomainNotAllowed is returned when an email's domain may not register.
+	ErrEmailDomainNotAllowed = errcode.New(errcode.Forbidden, "registration_domain_not_allowed", "email domain not allowed to register")
 )
 
+// MaxPasswordBytes is the longest password bcrypt hashes; it refuses
+// longer ones rather than silently truncate them.
+const MaxPasswordBytes = 72
+
 // UserRole identifies the privileges assigned to a user.
 type UserRole string
 
@@ -39,12 +55,51 @@ type User struct {
 	Name         string
 	Role         UserRole
 	PasswordHash string
+	// Locale is the preferred language of API messages; empty defers to the
+	// request's Accept-Language header.
+	Locale string
+	// Timezone is the IANA zone API timestamps are shown in; empty means
+	// UTC. Stored timestamps are always UTC.
+	Timezone string
+	// TokenVersion is embedded in issued tokens; bumping it invalidates
+	// every token issued before.
+	TokenVersion int
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
-// Credentials captures raw credential input for login.
+// Summary is the user representation shared with other systems (events,
+// webhooks); it never includes the password hash.
+type Summary struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      UserRole  `json:"role"`
+	Locale    string    `json:"locale,omitempty"`
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Summary returns the shareable view of the user.
+func (u *User) Summary() Summary {
+	return Summary{
+		ID:        u.ID,
+		Email:     u.Email,
+		Name:      u.Name,
+		Role:      u.Role,
+		Locale:    u.Locale,
+		Timezone:  u.Timezone,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+// Credentials captures raw credential input for login. ClientID and Scopes
+// optionally request a restricted token.
 type Credentials struct {
 	Email    string
 	Password string
+	ClientID string
+	Scopes   []string
 }
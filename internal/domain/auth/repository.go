@@ -8,12 +8,54 @@ import (
 // UserRepository defines persistence operations for auth users.
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
+	// CreatePromotingFirst is Create, except that the user is stored as an
+	// admin when the users table holds no rows at all, trashed and
+	// anonymized users included. Checking and inserting are atomic, so of
+	// concurrent first registrations only one is promoted. user.Role is set
+	// to the stored role.
+	CreatePromotingFirst(ctx context.Context, user *User) error
+	// GetByEmail matches email regardless of case.
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetByID(ctx context.Context, id string) (*User, error)
+	// GetByIDs fetches the live users among ids in one query; ids that
+	// match none are skipped.
+	GetByIDs(ctx context.Context, ids []string) ([]*User, error)
 	List(ctx context.Context, filter UserFilter) ([]*User, error)
 	Update(ctx context.Context, user *User) error
 	Delete(ctx context.Context, id string) error
+	// DeleteMany moves every one of ids to the trash, or none, failing with
+	// ErrUserNotFound if any is missing or already trashed.
+	DeleteMany(ctx context.Context, ids []string) error
+	// Anonymize overwrites the live user with user's scrubbed fields, bumps
+	// its token version and takes it out of use like Delete. Unlike a
+	// trashed user it can never be restored or purged, so records that name
+	// it keep a valid reference. Data only the user owns (sessions, saved
+	// views, watches) is removed with it.
+	Anonymize(ctx context.Context, user *User) error
+	// UpdatePassword also bumps the token version, signing the user out
+	// everywhere.
 	UpdatePassword(ctx context.Context, id, passwordHash string, updatedAt time.Time) error
+	BumpTokenVersion(ctx context.Context, id string) error
+	CountByRole(ctx context.Context, role UserRole) (int, error)
+	RecordLogin(ctx context.Context, id string, at time.Time) error
+	Stats(ctx context.Context, activeSince, signupsSince time.Time) (*UserStats, error)
+}
+
+// UserStats aggregates user counts for dashboards.
+type UserStats struct {
+	Total  int              `json:"total"`
+	ByRole map[UserRole]int `json:"byRole"`
+	// Active counts users who logged in since the requested cut-off; the
+	// rest, including users who never logged in, are dormant.
+	Active  int          `json:"active"`
+	Dormant int          `json:"dormant"`
+	Signups []DailyCount `json:"signups"`
+}
+
+// DailyCount is a count for one UTC calendar day (YYYY-MM-DD).
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
 }
 
 // UserFilter allows narrowing user queries.
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"strings"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrUnknownClient indicates a login for a client that is not configured.
+	ErrUnknownClient = errcode.New(errcode.Invalid, "client_unknown", "unknown client")
+	// ErrScopeNotAllowed indicates a requested scope outside the client's
+	// allowance.
+	ErrScopeNotAllowed = errcode.New(errcode.Invalid, "scope_not_allowed", "scope not allowed for this client")
+)
+
+// Scope actions. A scope is "<group>:<action>", e.g. "products:read"; route
+// groups require read for safe methods and write for everything else.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// Grant limits what a token may be used for. A grant without scopes is
+// unrestricted; that is what interactive logins receive.
+type Grant struct {
+	ClientID string
+	Scopes   []string
+}
+
+// Restricted reports whether the grant is limited to its scopes.
+func (g Grant) Restricted() bool {
+	return len(g.Scopes) > 0
+}
+
+// Allows reports whether the grant covers scope. Granted scopes may use "*"
+// for either part ("*:read", "products:*"); a bare "*" covers everything.
+func (g Grant) Allows(scope string) bool {
+	if !g.Restricted() {
+		return true
+	}
+	group, action, _ := strings.Cut(scope, ":")
+	for _, granted := range g.Scopes {
+		if granted == "*" {
+			return true
+		}
+		grantedGroup, grantedAction, ok := strings.Cut(granted, ":")
+		if !ok {
+			continue
+		}
+		if (grantedGroup == "*" || grantedGroup == group) && (grantedAction == "*" || grantedAction == action) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidScope reports whether scope is "*" or "<group>:<action>".
+func ValidScope(scope string) bool {
+	if scope == "*" {
+		return true
+	}
+	group, action, ok := strings.Cut(scope, ":")
+	return ok && group != "" && action != "" && !strings.ContainsAny(scope, " \t") && !strings.Contains(action, ":")
+}
@@ -0,0 +1,26 @@
+package category
+
+import (
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrNotFound indicates a category could not be located.
+	ErrNotFound = errcode.New(errcode.NotFound, "category_not_found", "category not found")
+	// ErrDuplicateSlug signals slug uniqueness constraint breaches.
+	ErrDuplicateSlug = errcode.New(errcode.Conflict, "category_slug_exists", "category with slug already exists")
+	// ErrInUse indicates the category is still referenced by products.
+	ErrInUse = errcode.New(errcode.Conflict, "category_in_use", "category is assigned to products")
+)
+
+// Category groups products for navigation and reporting.
+type Category struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,16 @@
+package category
+
+import "context"
+
+// Repository defines persistence behaviours for categories.
+type Repository interface {
+	Create(ctx context.Context, category *Category) error
+	GetByID(ctx context.Context, id string) (*Category, error)
+	// GetByIDs fetches the categories among ids in one query; ids that
+	// match none are skipped.
+	GetByIDs(ctx context.Context, ids []string) ([]*Category, error)
+	GetBySlug(ctx context.Context, slug string) (*Category, error)
+	List(ctx context.Context) ([]*Category, error)
+	Update(ctx context.Context, category *Category) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,76 @@
+// Package consent describes the published versions of the terms of service
+// and privacy policy, and users' acceptance of them.
+package consent
+
+import (
+	"context"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Documents users accept.
+const (
+	DocumentTerms   = "terms"
+	DocumentPrivacy = "privacy"
+)
+
+// Documents lists every document versions can be published of.
+var Documents = []string{DocumentTerms, DocumentPrivacy}
+
+var (
+	// ErrVersionNotFound indicates no such version of the document has been
+	// published.
+	ErrVersionNotFound = errcode.New(errcode.NotFound, "policy_version_not_found", "policy version not found")
+	// ErrVersionExists signals that the version was already published.
+	ErrVersionExists = errcode.New(errcode.Conflict, "policy_version_exists", "policy version already published")
+)
+
+// Ref names one version of a document.
+type Ref struct {
+	Document string `json:"document"`
+	Version  string `json:"version"`
+}
+
+// Version is one published version of a document. The newest mandatory
+// version of each document must be accepted before its users can do
+// anything else; other versions are recorded when accepted but never
+// required.
+type Version struct {
+	Document  string `json:"document"`
+	Version   string `json:"version"`
+	Mandatory bool   `json:"mandatory"`
+	// URL is where the text of the version can be read.
+	URL         string    `json:"url,omitempty"`
+	PublishedBy string    `json:"publishedBy,omitempty"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// Ref returns the version's reference.
+func (v *Version) Ref() Ref {
+	return Ref{Document: v.Document, Version: v.Version}
+}
+
+// Consent records a user accepting a version. ClientIP is the address it
+// was accepted from.
+type Consent struct {
+	UserID     string    `json:"-"`
+	Document   string    `json:"document"`
+	Version    string    `json:"version"`
+	ClientIP   string    `json:"clientIp,omitempty"`
+	AcceptedAt time.Time `json:"acceptedAt"`
+}
+
+// Repository persists published versions and consents.
+type Repository interface {
+	// Publish stores a new version, failing with ErrVersionExists when the
+	// document already has it.
+	Publish(ctx context.Context, v *Version) error
+	// Versions returns every published version, newest first.
+	Versions(ctx context.Context) ([]*Version, error)
+	// Accept records a consent. Accepting a version again keeps the first
+	// record.
+	Accept(ctx context.Context, c *Consent) error
+	// Consents returns the user's consents, newest first.
+	Consents(ctx context.Context, userID string) ([]*Consent, error)
+}
@@ -0,0 +1,125 @@
+package customer
+
+import (
+	"regexp"
+	"strings"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrLine1Required rejects an address without a street line.
+	ErrLine1Required = errcode.New(errcode.Invalid, "address_line1_required", "address line1 is required")
+	// ErrCityRequired rejects an address without a city.
+	ErrCityRequired = errcode.New(errcode.Invalid, "address_city_required", "address city is required")
+	// ErrInvalidCountry rejects a country that is not an ISO 3166-1 alpha-2
+	// code.
+	ErrInvalidCountry = errcode.New(errcode.Invalid, "address_country_invalid", "country must be an ISO 3166-1 alpha-2 code")
+	// ErrPostalCodeRequired rejects an address without a postal code in a
+	// country that uses them.
+	ErrPostalCodeRequired = errcode.New(errcode.Invalid, "address_postal_code_required", "postal code is required for this country")
+	// ErrInvalidPostalCode rejects a postal code that does not match the
+	// country's format.
+	ErrInvalidPostalCode = errcode.New(errcode.Invalid, "address_postal_code_invalid", "postal code does not match the country's format")
+)
+
+// countries holds every ISO 3166-1 alpha-2 code.
+var countries = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, code := range strings.Fields(`
+AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL
+BM BN BO BQ BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV
+CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD
+GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM
+IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK
+LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW
+MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR
+PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS
+ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY
+UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`) {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// postalFormats are the postal code formats of the countries the business
+// ships to most, with an example of each for error messages. Codes of other
+// countries are only required to be short and alphanumeric.
+var postalFormats = map[string]struct {
+	pattern *regexp.Regexp
+	example string
+}{
+	"LA": {regexp.MustCompile(`^\d{5}$`), "01000"},
+	"TH": {regexp.MustCompile(`^\d{5}$`), "10200"},
+	"KH": {regexp.MustCompile(`^\d{5,6}$`), "120101"},
+	"VN": {regexp.MustCompile(`^\d{6}$`), "100000"},
+	"MM": {regexp.MustCompile(`^\d{5}$`), "11181"},
+	"CN": {regexp.MustCompile(`^\d{6}$`), "100000"},
+	"SG": {regexp.MustCompile(`^\d{6}$`), "018956"},
+	"MY": {regexp.MustCompile(`^\d{5}$`), "50050"},
+	"JP": {regexp.MustCompile(`^\d{3}-\d{4}$`), "100-0001"},
+	"KR": {regexp.MustCompile(`^\d{5}$`), "03051"},
+	"AU": {regexp.MustCompile(`^\d{4}$`), "2000"},
+	"US": {regexp.MustCompile(`^\d{5}(-\d{4})?$`), "94105"},
+	"CA": {regexp.MustCompile(`^[A-Z]\d[A-Z] \d[A-Z]\d$`), "K1A 0B1"},
+	"GB": {regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? \d[A-Z]{2}$`), "SW1A 1AA"},
+	"DE": {regexp.MustCompile(`^\d{5}$`), "10115"},
+	"FR": {regexp.MustCompile(`^\d{5}$`), "75001"},
+	"NL": {regexp.MustCompile(`^\d{4} [A-Z]{2}$`), "1012 JS"},
+}
+
+// noPostalCodes lists countries without a postal code system in common use.
+var noPostalCodes = map[string]bool{
+	"AE": true, "AG": true, "AO": true, "BS": true, "BZ": true,
+	"HK": true, "MO": true, "QA": true, "YE": true, "ZW": true,
+}
+
+// otherPostalCode is the loose format accepted for countries without an
+// entry in postalFormats.
+var otherPostalCode = regexp.MustCompile(`^[A-Z\d][A-Z\d -]{1,9}$`)
+
+// Normalize trims the address's fields, upper-cases its country and postal
+// code and collapses the spaces in the postal code.
+func (a *Address) Normalize() {
+	a.Label = strings.TrimSpace(a.Label)
+	a.Recipient = strings.TrimSpace(a.Recipient)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.TrimSpace(a.Region)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.PostalCode = strings.ToUpper(strings.Join(strings.Fields(a.PostalCode), " "))
+}
+
+// Validate checks a normalized address: it needs a street line, a city and
+// a known country, and a postal code in the country's format. Countries
+// without postal codes accept none.
+func (a *Address) Validate() error {
+	switch {
+	case a.Line1 == "":
+		return ErrLine1Required
+	case a.City == "":
+		return ErrCityRequired
+	case !countries[a.Country]:
+		return ErrInvalidCountry.With("country", a.Country)
+	}
+	if noPostalCodes[a.Country] {
+		if a.PostalCode != "" {
+			return ErrInvalidPostalCode.With("country", a.Country)
+		}
+		return nil
+	}
+	if a.PostalCode == "" {
+		return ErrPostalCodeRequired.With("country", a.Country)
+	}
+	if format, ok := postalFormats[a.Country]; ok {
+		if !format.pattern.MatchString(a.PostalCode) {
+			return ErrInvalidPostalCode.With("country", a.Country).With("example", format.example)
+		}
+		return nil
+	}
+	if !otherPostalCode.MatchString(a.PostalCode) {
+		return ErrInvalidPostalCode.With("country", a.Country)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package customer describes the customers orders are placed for and the
+// addresses they ship to and are billed at.
+package customer
+
+import (
+	"context"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrNotFound indicates the customer does not exist.
+	ErrNotFound = errcode.New(errcode.NotFound, "customer_not_found", "customer not found")
+	// ErrAddressNotFound indicates the customer has no address with the id.
+	ErrAddressNotFound = errcode.New(errcode.NotFound, "address_not_found", "address not found")
+)
+
+// Customer is someone orders are placed for. Only admins see a customer's
+// email and phone in full.
+type Customer struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty" mask:"email"`
+	Phone     string    `json:"phone,omitempty" mask:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Address is one entry of a customer's address book. At most one address
+// of a customer is the default for billing, and at most one for shipping.
+type Address struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customerId"`
+	// Label tells a customer's addresses apart, such as "Home" or "Office".
+	Label           string    `json:"label,omitempty"`
+	Recipient       string    `json:"recipient,omitempty"`
+	Line1           string    `json:"line1"`
+	Line2           string    `json:"line2,omitempty"`
+	City            string    `json:"city"`
+	Region          string    `json:"region,omitempty"`
+	PostalCode      string    `json:"postalCode,omitempty"`
+	Country         string    `json:"country"`
+	DefaultBilling  bool      `json:"defaultBilling"`
+	DefaultShipping bool      `json:"defaultShipping"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// Repository persists customers and their address books.
+type Repository interface {
+	Create(ctx context.Context, customer *Customer) error
+	GetByID(ctx context.Context, id string) (*Customer, error)
+	// List returns customers ordered by name.
+	List(ctx context.Context) ([]*Customer, error)
+
+	// Addresses returns a customer's addresses, oldest first.
+	Addresses(ctx context.Context, customerID string) ([]*Address, error)
+	GetAddress(ctx context.Context, customerID, id string) (*Address, error)
+	// SaveAddress inserts or replaces an address. When it is a default,
+	// the customer's other addresses stop being the default of that kind
+	// in the same transaction.
+	SaveAddress(ctx context.Context, address *Address) error
+	DeleteAddress(ctx context.Context, customerID, id string) error
+}
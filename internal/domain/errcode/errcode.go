@@ -0,0 +1,78 @@
+// Package errcode defines the typed errors that domain packages and use
+// cases return for outcomes a client can act on. Each carries a Kind, which
+// the HTTP layer maps to a status, and a Code that identifies it in every
+// language. Errors without one are unexpected and are never shown to
+// clients.
+package errcode
+
+import (
+	"errors"
+	"maps"
+)
+
+// Kind classifies an error by what the caller should do about it.
+type Kind int
+
+const (
+	// Invalid means the request itself is wrong.
+	Invalid Kind = iota + 1
+	// Unauthenticated means the caller's credentials were missing or wrong.
+	Unauthenticated
+	// Forbidden means the caller may not do this.
+	Forbidden
+	// NotFound means a referenced resource does not exist.
+	NotFound
+	// Conflict means the request clashes with the resource's current state.
+	Conflict
+	// Unprocessable means the request is well-formed but its content cannot
+	// be applied.
+	Unprocessable
+	// Unavailable means the feature is not configured on this deployment.
+	Unavailable
+)
+
+// Error is an expected failure with a stable code. Messages are English and
+// safe to show to clients; Meta holds structured details such as the value
+// that was rejected.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Meta    map[string]any
+}
+
+// New returns an error of kind with the given code and message.
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches any error with the same code, so errors.Is still recognises a
+// sentinel after With has copied it.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// With returns a copy of e that also carries key in its metadata.
+func (e *Error) With(key string, value any) *Error {
+	c := *e
+	c.Meta = maps.Clone(e.Meta)
+	if c.Meta == nil {
+		c.Meta = map[string]any{}
+	}
+	c.Meta[key] = value
+	return &c
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
@@ -0,0 +1,124 @@
+// Package event defines the domain events emitted by the use case services.
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types emitted by the services.
+const (
+	ProductCreated  = "product.created"
+	ProductUpdated  = "product.updated"
+	ProductDeleted  = "product.deleted"
+	ProductRestored = "product.restored"
+	CategoryCreated = "category.created"
+	CategoryUpdated = "category.updated"
+	CategoryDeleted = "category.deleted"
+	UserCreated     = "user.created"
+	UserUpdated     = "user.updated"
+	UserRoleChanged = "user.role_changed"
+	UserDeleted     = "user.deleted"
+	UserRestored    = "user.restored"
+
+	// ProductOutOfStock follows the ProductUpdated event of a change that
+	// used up the last of a product's stock.
+	ProductOutOfStock = "product.out_of_stock"
+	// SyncRunFailed reports a product sync run that could not complete.
+	SyncRunFailed = "sync_run.failed"
+
+	// Approval events audit destructive actions held for a second admin.
+	// The approved action publishes its own events when it runs.
+	ApprovalRequested = "approval.requested"
+	ApprovalApproved  = "approval.approved"
+	ApprovalRejected  = "approval.rejected"
+
+	// OrderCreated follows an order being placed; OrderStatusChanged
+	// carries the order after each fulfilment transition.
+	OrderCreated       = "order.created"
+	OrderStatusChanged = "order.status_changed"
+
+	// Return events follow a return through authorisation; ReturnReceived
+	// is published before the goods are restocked.
+	ReturnRequested = "return.requested"
+	ReturnApproved  = "return.approved"
+	ReturnRejected  = "return.rejected"
+	ReturnReceived  = "return.received"
+
+	// Payment events follow a payment being settled by its provider and
+	// refunded.
+	PaymentSucceeded = "payment.succeeded"
+	PaymentFailed    = "payment.failed"
+	PaymentRefunded  = "payment.refunded"
+
+	// Shipment events follow a parcel from its label being bought to the
+	// carrier's tracking updates.
+	ShipmentCreated       = "shipment.created"
+	ShipmentStatusChanged = "shipment.status_changed"
+)
+
+// Types lists every event type in a stable order.
+var Types = []string{
+	ProductCreated, ProductUpdated, ProductDeleted, ProductRestored, ProductOutOfStock,
+	CategoryCreated, CategoryUpdated, CategoryDeleted,
+	UserCreated, UserUpdated, UserRoleChanged, UserDeleted, UserRestored,
+	SyncRunFailed,
+	ApprovalRequested, ApprovalApproved, ApprovalRejected,
+	OrderCreated, OrderStatusChanged,
+	ReturnRequested, ReturnApproved, ReturnRejected, ReturnReceived,
+	PaymentSucceeded, PaymentFailed, PaymentRefunded,
+	ShipmentCreated, ShipmentStatusChanged,
+}
+
+// Event records something that happened to an aggregate.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Subject    string    `json:"subject"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       any       `json:"data"`
+}
+
+// New builds an event for the aggregate identified by subject.
+func New(eventType, subject string, data any) Event {
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		Subject:    subject,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publisher receives events after the change they describe has been
+// persisted. Implementations handle their own failures; publishing never
+// fails the operation that produced the event.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Discard is a Publisher that drops every event.
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(context.Context, Event) {}
+
+// Bus fans each event out to every registered publisher.
+type Bus struct {
+	publishers []Publisher
+}
+
+// NewBus constructs a bus delivering to publishers in order.
+func NewBus(publishers ...Publisher) *Bus {
+	return &Bus{publishers: publishers}
+}
+
+// Publish forwards e to every publisher.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	for _, p := range b.publishers {
+		p.Publish(ctx, e)
+	}
+}
@@ -0,0 +1,40 @@
+// Package integration describes external systems allowed to push data into
+// the backoffice with signed requests.
+package integration
+
+import (
+	"context"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrNotFound indicates an integration could not be located.
+	ErrNotFound = errcode.New(errcode.NotFound, "integration_not_found", "integration not found")
+	// ErrDuplicateName indicates another integration has the name.
+	ErrDuplicateName = errcode.New(errcode.Conflict, "integration_name_exists", "integration name already exists")
+)
+
+// Integration is an external system that posts payloads of one kind, signed
+// with its own secret.
+type Integration struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Kind selects the handler its payloads are delivered to.
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+	Secret      string    `json:"-"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Repository defines persistence behaviours for integrations.
+type Repository interface {
+	Create(ctx context.Context, integration *Integration) error
+	GetByID(ctx context.Context, id string) (*Integration, error)
+	List(ctx context.Context) ([]*Integration, error)
+	Update(ctx context.Context, integration *Integration) error
+	Delete(ctx context.Context, id string) error
+}
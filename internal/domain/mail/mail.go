@@ -0,0 +1,31 @@
+// Package mail describes email sent to customers, such as their invoices.
+package mail
+
+import (
+	"context"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// ErrUnavailable is returned when no mail server is configured.
+var ErrUnavailable = errcode.New(errcode.Unavailable, "mail_unavailable", "email is not configured")
+
+// Attachment is a file sent with a message.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Message is a plain-text email.
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
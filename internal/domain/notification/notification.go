@@ -0,0 +1,86 @@
+// Package notification describes chat channels that are told about notable
+// events, such as a failed import or a product running out of stock.
+package notification
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrNotFound indicates the channel does not exist.
+	ErrNotFound = errcode.New(errcode.NotFound, "notification_channel_not_found", "notification channel not found")
+	// ErrDuplicateName signals that another channel already uses the name.
+	ErrDuplicateName = errcode.New(errcode.Conflict, "notification_channel_name_exists", "notification channel name already exists")
+)
+
+// Kinds of channel, named after the chat tool whose incoming webhook they
+// post to.
+const (
+	KindSlack = "slack"
+	KindTeams = "teams"
+)
+
+// Kinds lists every supported kind.
+var Kinds = []string{KindSlack, KindTeams}
+
+// Events a channel can subscribe to.
+const (
+	// EventImportFailed is a product sync run that failed.
+	EventImportFailed = "import.failed"
+	// EventAdminCreated is a new admin account, or a user promoted to admin.
+	EventAdminCreated = "admin.created"
+	// EventStockOut is a product whose last unit of stock was used up.
+	EventStockOut = "stock.out"
+	// EventShipmentDelivered is a parcel the carrier reports delivered.
+	EventShipmentDelivered = "shipment.delivered"
+	// EventShipmentException is a parcel the carrier reports held up or
+	// returned to sender.
+	EventShipmentException = "shipment.exception"
+)
+
+// Events lists every event in a stable order.
+var Events = []string{EventImportFailed, EventAdminCreated, EventStockOut, EventShipmentDelivered, EventShipmentException}
+
+// Channel is a chat destination and the events it is told about.
+type Channel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// WebhookURL embeds the chat tool's credential, so it is never
+	// returned by the API.
+	WebhookURL string    `json:"-"`
+	Events     []string  `json:"events"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Wants reports whether the channel should be told about event.
+func (c *Channel) Wants(event string) bool {
+	return c.Active && slices.Contains(c.Events, event)
+}
+
+// Message is what a channel is sent.
+type Message struct {
+	Event string
+	Title string
+	Text  string
+}
+
+// Sender posts a message to a channel's webhook.
+type Sender interface {
+	Send(ctx context.Context, channel *Channel, msg Message) error
+}
+
+// Repository persists channels.
+type Repository interface {
+	Create(ctx context.Context, channel *Channel) error
+	GetByID(ctx context.Context, id string) (*Channel, error)
+	List(ctx context.Context) ([]*Channel, error)
+	Update(ctx context.Context, channel *Channel) error
+	Delete(ctx context.Context, id string) error
+}
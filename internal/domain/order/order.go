@@ -0,0 +1,184 @@
+// Package order describes customer orders and the fulfilment statuses they
+// move through.
+package order
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Statuses an order moves through. Delivered and cancelled orders are final.
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusPicking   = "picking"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+)
+
+// PaymentUnpaid is the payment status of an order nobody has started
+// paying for; after that an order carries its latest payment's status.
+const PaymentUnpaid = "unpaid"
+
+// Statuses lists every status in fulfilment order.
+var Statuses = []string{StatusPending, StatusPaid, StatusPicking, StatusShipped, StatusDelivered, StatusCancelled}
+
+// transitions lists the statuses each status may move to. An order can be
+// cancelled until it leaves the warehouse.
+var transitions = map[string][]string{
+	StatusPending: {StatusPaid, StatusCancelled},
+	StatusPaid:    {StatusPicking, StatusCancelled},
+	StatusPicking: {StatusShipped, StatusCancelled},
+	StatusShipped: {StatusDelivered},
+}
+
+var (
+	// ErrNotFound indicates the order does not exist.
+	ErrNotFound = errcode.New(errcode.NotFound, "order_not_found", "order not found")
+	// ErrInvalidStatus rejects a value that is not an order status.
+	ErrInvalidStatus = errcode.New(errcode.Invalid, "order_status_invalid", "status is not an order status").With("supported", Statuses)
+	// ErrIllegalTransition rejects a move the state machine does not allow.
+	ErrIllegalTransition = errcode.New(errcode.Conflict, "order_transition_illegal", "order cannot move to that status")
+	// ErrStatusChanged indicates another transition was recorded since the
+	// order was read.
+	ErrStatusChanged = errcode.New(errcode.Conflict, "order_status_changed", "order status was changed concurrently")
+)
+
+// ValidStatus reports whether status is an order status.
+func ValidStatus(status string) bool {
+	return slices.Contains(Statuses, status)
+}
+
+// Next returns the statuses an order in status may move to, which is empty
+// for final statuses.
+func Next(status string) []string {
+	return append([]string{}, transitions[status]...)
+}
+
+// CanTransition reports whether an order may move from one status to
+// another.
+func CanTransition(from, to string) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// Line is a product on an order, priced when the order was placed.
+type Line struct {
+	ProductID string  `json:"productId"`
+	SKU       string  `json:"sku"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unitPrice"`
+	Total     float64 `json:"total"`
+}
+
+// Transition records an order moving between statuses. The first entry of
+// an order's history has no From and marks its creation.
+type Transition struct {
+	From string    `json:"from,omitempty"`
+	To   string    `json:"to"`
+	Note string    `json:"note,omitempty"`
+	By   string    `json:"by,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// Order is a set of lines and the fulfilment status they have reached.
+type Order struct {
+	ID string `json:"id"`
+	// Reference is the caller's own identifier, such as a shop order number.
+	Reference string `json:"reference,omitempty"`
+	// CustomerID names the customer the order is for, who is billed on
+	// its invoice.
+	CustomerID string `json:"customerId,omitempty"`
+	Status     string `json:"status"`
+	// PaymentStatus is PaymentUnpaid or the status of the order's latest
+	// payment.
+	PaymentStatus string `json:"paymentStatus"`
+	// TrackingNumbers lists the carrier tracking numbers of the parcels
+	// sent for the order.
+	TrackingNumbers []string `json:"trackingNumbers"`
+	Lines           []Line   `json:"lines"`
+	// PromotionCode is the discount code redeemed on the order, which took
+	// Discount off its lines.
+	PromotionCode string  `json:"promotionCode,omitempty"`
+	Discount      float64 `json:"discount"`
+	// Total is what is left to pay once Discount is taken off the lines.
+	Total     float64      `json:"total"`
+	CreatedBy string       `json:"createdBy,omitempty"`
+	History   []Transition `json:"history"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// Transition moves the order to status and appends the move to its
+// history, or fails with ErrIllegalTransition naming the allowed statuses.
+func (o *Order) Transition(to, by, note string, at time.Time) error {
+	if !ValidStatus(to) {
+		return ErrInvalidStatus
+	}
+	if !CanTransition(o.Status, to) {
+		return ErrIllegalTransition.With("from", o.Status).With("to", to).With("allowed", Next(o.Status))
+	}
+	o.History = append(o.History, Transition{From: o.Status, To: to, Note: note, By: by, At: at})
+	o.Status = to
+	o.UpdatedAt = at
+	return nil
+}
+
+// Subtotal is the sum of the order's lines before any discount.
+func (o *Order) Subtotal() float64 {
+	var subtotal float64
+	for _, l := range o.Lines {
+		subtotal += l.Total
+	}
+	return math.Round(subtotal*100) / 100
+}
+
+// Quantities totals the order's lines by product.
+func (o *Order) Quantities() map[string]int {
+	quantities := make(map[string]int, len(o.Lines))
+	for _, l := range o.Lines {
+		quantities[l.ProductID] += l.Quantity
+	}
+	return quantities
+}
+
+// Last returns the most recent entry of the order's history.
+func (o *Order) Last() Transition {
+	if len(o.History) == 0 {
+		return Transition{To: o.Status, At: o.CreatedAt}
+	}
+	return o.History[len(o.History)-1]
+}
+
+// Repository persists orders with their lines and history.
+type Repository interface {
+	// Create inserts an order with its lines and history and, in the same
+	// transaction, takes each line's quantity from its product's stock. A
+	// product whose stock left unreserved cannot cover its lines fails the
+	// order with the product package's ErrInsufficientStock, and a missing
+	// one with its ErrNotFound; either way nothing is written.
+	Create(ctx context.Context, order *Order) error
+	GetByID(ctx context.Context, id string) (*Order, error)
+	// List returns orders with the status, or all when it is empty, newest
+	// first.
+	List(ctx context.Context, status string) ([]*Order, error)
+	// Transition stores the order's status and the latest entry of its
+	// history, and fails with ErrStatusChanged if the stored order is no
+	// longer in that entry's From status. Moving to StatusCancelled returns
+	// the lines' stock to their products, skipping products deleted since,
+	// in the same transaction.
+	Transition(ctx context.Context, order *Order) error
+	// SetPaymentStatus records the status of the order's latest payment.
+	SetPaymentStatus(ctx context.Context, id, status string, at time.Time) error
+	// AddTrackingNumber records the tracking number of a parcel sent for
+	// the order.
+	AddTrackingNumber(ctx context.Context, id, number string, at time.Time) error
+	// SetPromotion records the discount code redeemed on the order, or its
+	// removal when code is empty, along with the discount and new total.
+	SetPromotion(ctx context.Context, id, code string, discount, total float64, at time.Time) error
+}
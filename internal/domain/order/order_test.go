@@ -0,0 +1,75 @@
+package order_test
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/order"
+)
+
+func TestTransition(t *testing.T) {
+	at := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		from    string
+		allowed []string
+	}{
+		{order.StatusPending, []string{order.StatusPaid, order.StatusCancelled}},
+		{order.StatusPaid, []string{order.StatusPicking, order.StatusCancelled}},
+		{order.StatusPicking, []string{order.StatusShipped, order.StatusCancelled}},
+		{order.StatusShipped, []string{order.StatusDelivered}},
+		{order.StatusDelivered, nil},
+		{order.StatusCancelled, nil},
+	} {
+		if got := order.Next(tc.from); !slices.Equal(got, tc.allowed) {
+			t.Errorf("Next(%s) = %v, want %v", tc.from, got, tc.allowed)
+		}
+		for _, to := range order.Statuses {
+			o := &order.Order{Status: tc.from, History: []order.Transition{{To: tc.from, At: at}}}
+			err := o.Transition(to, "user-1", "note", at.Add(time.Hour))
+			if !slices.Contains(tc.allowed, to) {
+				if !errors.Is(err, order.ErrIllegalTransition) {
+					t.Errorf("%s -> %s: err = %v, want %v", tc.from, to, err, order.ErrIllegalTransition)
+				}
+				if e, ok := errcode.As(err); ok && !slices.Equal(e.Meta["allowed"].([]string), order.Next(tc.from)) {
+					t.Errorf("%s -> %s: allowed = %v, want %v", tc.from, to, e.Meta["allowed"], order.Next(tc.from))
+				}
+				if o.Status != tc.from || len(o.History) != 1 {
+					t.Errorf("%s -> %s: refused move changed the order to %s with %d entries", tc.from, to, o.Status, len(o.History))
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s -> %s: %v", tc.from, to, err)
+				continue
+			}
+			want := order.Transition{From: tc.from, To: to, Note: "note", By: "user-1", At: at.Add(time.Hour)}
+			if o.Status != to || !o.UpdatedAt.Equal(want.At) || o.Last() != want {
+				t.Errorf("%s -> %s: order is %s updated %s with last entry %+v", tc.from, to, o.Status, o.UpdatedAt, o.Last())
+			}
+		}
+	}
+
+	o := &order.Order{Status: order.StatusPending}
+	if err := o.Transition("lost", "", "", at); !errors.Is(err, order.ErrInvalidStatus) {
+		t.Fatalf("unknown status: err = %v, want %v", err, order.ErrInvalidStatus)
+	}
+}
+
+func TestQuantities(t *testing.T) {
+	o := &order.Order{Lines: []order.Line{
+		{ProductID: "rice", Quantity: 2, Total: 5},
+		{ProductID: "noodles", Quantity: 1, Total: 1.2},
+		{ProductID: "rice", Quantity: 6, Total: 15},
+	}}
+	// Cancelling returns these; a product on two lines gets both back.
+	if got, want := o.Quantities(), map[string]int{"rice": 8, "noodles": 1}; !maps.Equal(got, want) {
+		t.Fatalf("Quantities = %v, want %v", got, want)
+	}
+	if got := o.Subtotal(); got != 21.2 {
+		t.Fatalf("Subtotal = %v, want 21.2", got)
+	}
+}
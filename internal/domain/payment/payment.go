@@ -0,0 +1,116 @@
+// Package payment describes payments taken for orders through external
+// payment providers.
+package payment
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Payment statuses. A payment starts pending while the customer pays with
+// the provider and is settled by the provider's webhook.
+const (
+	StatusPending           = "pending"
+	StatusSucceeded         = "succeeded"
+	StatusFailed            = "failed"
+	StatusPartiallyRefunded = "partially_refunded"
+	StatusRefunded          = "refunded"
+)
+
+// Statuses lists every payment status.
+var Statuses = []string{StatusPending, StatusSucceeded, StatusFailed, StatusPartiallyRefunded, StatusRefunded}
+
+var (
+	// ErrNotFound indicates the payment does not exist.
+	ErrNotFound = errcode.New(errcode.NotFound, "payment_not_found", "payment not found")
+	// ErrUnknownProvider rejects a provider that is not configured.
+	ErrUnknownProvider = errcode.New(errcode.Invalid, "payment_provider_unknown", "unknown payment provider")
+	// ErrInvalidSignature rejects a webhook whose signature is missing or
+	// does not match its payload.
+	ErrInvalidSignature = errcode.New(errcode.Unauthenticated, "payment_signature_invalid", "invalid payment webhook signature")
+	// ErrInvalidWebhook rejects a signed webhook the provider's format
+	// cannot be read from.
+	ErrInvalidWebhook = errcode.New(errcode.Invalid, "payment_webhook_invalid", "invalid payment webhook payload")
+	// ErrNotRefundable rejects refunding a payment that has not succeeded or
+	// is already fully refunded.
+	ErrNotRefundable = errcode.New(errcode.Conflict, "payment_not_refundable", "payment cannot be refunded")
+)
+
+// Payment is one attempt to pay for an order.
+type Payment struct {
+	ID       string `json:"id"`
+	OrderID  string `json:"orderId"`
+	Provider string `json:"provider"`
+	// Reference is the provider's id for the payment, which its webhooks
+	// refer to.
+	Reference string  `json:"reference,omitempty"`
+	Status    string  `json:"status"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	Refunded  float64 `json:"refunded"`
+	// CheckoutURL is where the customer completes the payment.
+	CheckoutURL   string    `json:"checkoutUrl,omitempty"`
+	FailureReason string    `json:"failureReason,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Refundable is the amount not refunded yet.
+func (p *Payment) Refundable() float64 {
+	if p.Status != StatusSucceeded && p.Status != StatusPartiallyRefunded {
+		return 0
+	}
+	return p.Amount - p.Refunded
+}
+
+// Checkout asks a provider to take a payment.
+type Checkout struct {
+	PaymentID   string
+	OrderID     string
+	Amount      float64
+	Currency    string
+	Description string
+}
+
+// Session is a payment the provider has started.
+type Session struct {
+	Reference   string
+	CheckoutURL string
+}
+
+// Outcome is what a provider's webhook reports about a payment: it
+// succeeded or failed.
+type Outcome struct {
+	Reference string
+	Status    string
+	Reason    string
+}
+
+// Provider takes payments with an external payment service.
+type Provider interface {
+	// Name identifies the provider in requests and stored payments.
+	Name() string
+	// Start opens a payment the customer completes at the session's
+	// CheckoutURL.
+	Start(ctx context.Context, checkout Checkout) (*Session, error)
+	// Webhook verifies a webhook's signature and reads its outcome. A nil
+	// outcome means the webhook is not about a payment's result and can be
+	// acknowledged and ignored.
+	Webhook(payload []byte, header http.Header) (*Outcome, error)
+	// Refund returns amount of a settled payment to the customer.
+	Refund(ctx context.Context, p *Payment, amount float64) error
+}
+
+// Repository persists payments.
+type Repository interface {
+	Create(ctx context.Context, p *Payment) error
+	GetByID(ctx context.Context, id string) (*Payment, error)
+	// GetByReference finds the payment a provider's webhook is about.
+	GetByReference(ctx context.Context, provider, reference string) (*Payment, error)
+	// ListByOrder returns an order's payments, newest first.
+	ListByOrder(ctx context.Context, orderID string) ([]*Payment, error)
+	Update(ctx context.Context, p *Payment) error
+}
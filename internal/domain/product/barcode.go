@@ -0,0 +1,37 @@
+package product
+
+import "backoffice/backend/internal/domain/errcode"
+
+var (
+	// ErrInvalidBarcode rejects barcodes that are not a GTIN with a correct
+	// check digit.
+	ErrInvalidBarcode = errcode.New(errcode.Invalid, "barcode_invalid", "barcode must be an EAN-8, UPC-A, EAN-13 or GTIN-14 with a valid check digit")
+	// ErrDuplicateBarcode signals that another product already carries the
+	// barcode.
+	ErrDuplicateBarcode = errcode.New(errcode.Conflict, "product_barcode_exists", "product with barcode already exists")
+)
+
+// ValidBarcode reports whether code is an EAN-8, UPC-A (12 digits), EAN-13 or
+// GTIN-14 whose last digit is the GS1 check digit of the others.
+func ValidBarcode(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+	// Weights alternate 3, 1, ... from the digit next to the check digit.
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		d := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
@@ -1,25 +1,42 @@
 package product
 
 import (
-	"errors"
 	"time"
+
+	"backoffice/backend/internal/domain/errcode"
 )
 
 var (
 	// ErrNotFound indicates a product could not be located.
-	ErrNotFound = errors.New("product not found")
+	ErrNotFound = errcode.New(errcode.NotFound, "product_not_found", "product not found")
 	// ErrDuplicateSKU signals SKU uniqueness constraint breaches.
-	ErrDuplicateSKU = errors.New("product with SKU already exists")
+	ErrDuplicateSKU = errcode.New(errcode.Conflict, "product_sku_exists", "product with SKU already exists")
+	// ErrUnknownCategory indicates the referenced category does not exist.
+	ErrUnknownCategory = errcode.New(errcode.Invalid, "category_unknown", "category does not exist")
 )
 
-// Product captures the state of an individual product.
+// Product captures the state of an individual product. Quantity is the stock
+// on hand, counted in Unit; Reserved is the part of it held by active
+// reservations and Available what is left to sell. PackSize is how many
+// units the product is packaged in. CostPrice, what one unit costs to buy
+// or make, is nil until recorded and is only shown to admins. Locale is
+// only set on products read in a requested language, and names the locale
+// Name and Description are in.
 type Product struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	SKU         string    `json:"sku"`
+	Barcode     string    `json:"barcode,omitempty"`
 	Price       float64   `json:"price"`
+	CostPrice   *float64  `json:"costPrice,omitempty" mask:"hide"`
+	Unit        Unit      `json:"unit"`
+	PackSize    int       `json:"packSize"`
 	Quantity    int       `json:"quantity"`
+	Reserved    int       `json:"reserved"`
+	Available   int       `json:"available"`
+	CategoryID  string    `json:"categoryId,omitempty"`
+	Locale      string    `json:"locale,omitempty"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
@@ -41,5 +58,17 @@ func (p *Product) Update(name, description, sku *string, price *float64, quantit
 	if quantity != nil {
 		p.Quantity = *quantity
 	}
+	p.SetReserved(p.Reserved)
 	p.UpdatedAt = time.Now().UTC()
 }
+
+// SetReserved records the quantity held by active reservations and derives
+// the available quantity. Stock lowered below what is reserved leaves
+// nothing available rather than a negative amount.
+func (p *Product) SetReserved(reserved int) {
+	p.Reserved = reserved
+	p.Available = p.Quantity - reserved
+	if p.Available < 0 {
+		p.Available = 0
+	}
+}
@@ -0,0 +1,39 @@
+package product
+
+// ProductMargin is what one product earns over its cost price. Margin and
+// MarginPercent are per unit; StockMargin is Margin over the stock on hand.
+type ProductMargin struct {
+	ProductID     string  `json:"productId"`
+	SKU           string  `json:"sku"`
+	Name          string  `json:"name"`
+	CategoryID    string  `json:"categoryId"`
+	Price         float64 `json:"price"`
+	CostPrice     float64 `json:"costPrice"`
+	Margin        float64 `json:"margin"`
+	MarginPercent float64 `json:"marginPercent"`
+	Quantity      int     `json:"quantity"`
+	StockMargin   float64 `json:"stockMargin"`
+}
+
+// CategoryMargin totals the stock of a category's costed products at sale
+// and at cost price.
+type CategoryMargin struct {
+	// CategoryID is empty for uncategorised products.
+	CategoryID    string  `json:"categoryId"`
+	CategoryName  string  `json:"categoryName"`
+	Products      int     `json:"products"`
+	Quantity      int     `json:"quantity"`
+	Revenue       float64 `json:"revenue"`
+	Cost          float64 `json:"cost"`
+	Margin        float64 `json:"margin"`
+	MarginPercent float64 `json:"marginPercent"`
+}
+
+// MarginReport is the margin report. Products without a cost price cannot
+// be costed; they are counted in Uncosted and left out of every line.
+type MarginReport struct {
+	Products   []ProductMargin  `json:"products"`
+	Categories []CategoryMargin `json:"categories"`
+	Total      CategoryMargin   `json:"total"`
+	Uncosted   int              `json:"uncosted"`
+}
@@ -0,0 +1,16 @@
+package product
+
+import "backoffice/backend/internal/domain/errcode"
+
+// ErrPriceChanged indicates a bulk price update found a product whose price
+// no longer matched the one its new price was computed from.
+var ErrPriceChanged = errcode.New(errcode.Conflict, "price_changed", "a product's price changed during the update")
+
+// PriceChange sets one product's price, provided it still has OldPrice.
+type PriceChange struct {
+	ProductID string  `json:"id"`
+	SKU       string  `json:"sku"`
+	Name      string  `json:"name"`
+	OldPrice  float64 `json:"oldPrice"`
+	NewPrice  float64 `json:"newPrice"`
+}
@@ -1,13 +1,45 @@
 package product
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository defines persistence behaviours for products.
 type Repository interface {
 	Create(ctx context.Context, product *Product) error
 	GetByID(ctx context.Context, id string) (*Product, error)
+	// GetByIDs fetches the live products among ids in one query, in the
+	// order of ids; ids that match no product are skipped.
+	GetByIDs(ctx context.Context, ids []string) ([]*Product, error)
 	GetBySKU(ctx context.Context, sku string) (*Product, error)
+	GetByBarcode(ctx context.Context, barcode string) (*Product, error)
 	List(ctx context.Context) ([]*Product, error)
-	Update(ctx context.Context, product *Product) error
+	// Update writes everything but the quantity and then, in the same
+	// transaction, adds delta to the stored quantity. It fails with
+	// ErrInsufficientStock, writing nothing, when a reduction would leave
+	// less than the reserved stock (see KeepsReserved), and refreshes
+	// product's quantity and reserved stock.
+	Update(ctx context.Context, product *Product, delta int) error
+	// AdjustQuantity atomically adds delta to the product's quantity and
+	// returns the updated product, failing with ErrInsufficientStock rather
+	// than letting the quantity go below its reserved stock.
+	AdjustQuantity(ctx context.Context, id string, delta int, at time.Time) (*Product, error)
+	// SetQuantity atomically sets the product's quantity to an absolute
+	// level and returns the updated product along with the quantity it
+	// replaced. Setting the level the product already has changes nothing;
+	// lowering it below the reserved stock fails with ErrInsufficientStock.
+	SetQuantity(ctx context.Context, id string, quantity int, at time.Time) (*Product, int, error)
 	Delete(ctx context.Context, id string) error
+	// DeleteMany moves every one of ids to the trash, or none, failing with
+	// ErrNotFound if any is missing or already trashed.
+	DeleteMany(ctx context.Context, ids []string) error
+	// StockValuation values stock (quantity × price) per category, either
+	// now or, when asOf is set, from the movement ledger at that instant.
+	StockValuation(ctx context.Context, asOf *time.Time) ([]ValuationLine, error)
+	// CountLowStock counts products whose quantity is at or below quantity.
+	CountLowStock(ctx context.Context, quantity int) (int, error)
+	// UpdatePrices applies every change or none, failing with
+	// ErrPriceChanged if a product's price is no longer its OldPrice.
+	UpdatePrices(ctx context.Context, changes []PriceChange, at time.Time) error
 }
@@ -0,0 +1,61 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrInsufficientStock indicates a reservation larger than the product's
+	// available quantity, or a stock change that would take its quantity
+	// below zero or below the stock its active reservations hold.
+	ErrInsufficientStock = errcode.New(errcode.Conflict, "insufficient_stock", "insufficient stock available")
+	// ErrReservationNotFound indicates the reservation does not exist, has
+	// expired or belongs to another product.
+	ErrReservationNotFound = errcode.New(errcode.NotFound, "reservation_not_found", "reservation not found")
+)
+
+// Reservation holds stock for a pending order until it is confirmed,
+// released or expires.
+type Reservation struct {
+	ID        string `json:"id"`
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	// Reference identifies the order the stock is held for.
+	Reference string    `json:"reference,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// KeepsReserved reports whether adding delta to a quantity of which
+// reserved is held by active reservations leaves them covered. Stock can
+// always be added; taking it away must leave at least the reserved stock on
+// hand, and never less than zero.
+func KeepsReserved(quantity, reserved, delta int) bool {
+	return delta >= 0 || quantity+delta >= max(reserved, 0)
+}
+
+// ReservationRepository persists reservations. Only reservations that have
+// not expired at the given instant count against a product's stock, so
+// expired ones are released without any write.
+type ReservationRepository interface {
+	// Reserve stores r if the product's quantity minus its active
+	// reservations covers r.Quantity, atomically with respect to concurrent
+	// reservations of the same product.
+	Reserve(ctx context.Context, r *Reservation, now time.Time) error
+	ListReservations(ctx context.Context, productID string, now time.Time) ([]*Reservation, error)
+	// ListReservationsFor returns the active reservations of all of
+	// productIDs in one query, ordered by product and then oldest first.
+	ListReservationsFor(ctx context.Context, productIDs []string, now time.Time) ([]*Reservation, error)
+	// Release deletes an active reservation.
+	Release(ctx context.Context, productID, id string, now time.Time) error
+	// Confirm deletes an active reservation and deducts its quantity from
+	// the product's stock, returning the updated product.
+	Confirm(ctx context.Context, productID, id string, now time.Time) (*Product, error)
+	// PurgeExpiredReservations deletes reservations that expired before
+	// cutoff.
+	PurgeExpiredReservations(ctx context.Context, cutoff time.Time) (int, error)
+}
@@ -0,0 +1,108 @@
+package product
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrTranslationNotFound indicates the product has no translation in
+	// the locale.
+	ErrTranslationNotFound = errcode.New(errcode.NotFound, "product_translation_not_found", "product translation not found")
+	// ErrInvalidLocale rejects a locale that is not a language tag such as
+	// lo or lo-LA.
+	ErrInvalidLocale = errcode.New(errcode.Invalid, "locale_invalid", "locale must be a language such as lo or lo-LA")
+)
+
+var localePattern = regexp.MustCompile(`^([a-z]{2,3})(?:[-_]([a-z]{2}))?$`)
+
+// NormalizeLocale returns tag as a language, lower case, with an optional
+// region, upper case: "LO_la" becomes "lo-LA". It reports false for
+// anything else.
+func NormalizeLocale(tag string) (string, bool) {
+	m := localePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(tag)))
+	if m == nil {
+		return "", false
+	}
+	if m[2] == "" {
+		return m[1], true
+	}
+	return m[1] + "-" + strings.ToUpper(m[2]), true
+}
+
+// FallbackChain expands a comma-separated list of locales, most preferred
+// first, into the locales to try in turn: each regional locale is followed
+// by its language, so "lo-LA,th" becomes lo-LA, lo, th. It fails with
+// ErrInvalidLocale on a malformed locale.
+func FallbackChain(tags string) ([]string, error) {
+	var chain []string
+	for _, tag := range strings.Split(tags, ",") {
+		if strings.TrimSpace(tag) == "" {
+			continue
+		}
+		locale, ok := NormalizeLocale(tag)
+		if !ok {
+			return nil, ErrInvalidLocale.With("locale", strings.TrimSpace(tag))
+		}
+		language, _, _ := strings.Cut(locale, "-")
+		for _, l := range []string{locale, language} {
+			if !slices.Contains(chain, l) {
+				chain = append(chain, l)
+			}
+		}
+	}
+	return chain, nil
+}
+
+// Translation is a product's name and description in one locale.
+type Translation struct {
+	ProductID   string    `json:"productId"`
+	Locale      string    `json:"locale"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	UpdatedBy   string    `json:"updatedBy,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Translated returns a copy of p whose name and description come from the
+// first locale in chain it has a translation for, or p's own when chain
+// reaches base first or has none of them; an untranslated description
+// keeps p's own. The copy's Locale says which locale was used.
+func (p *Product) Translated(translations []*Translation, chain []string, base string) *Product {
+	c := *p
+	c.Locale = base
+	for _, locale := range chain {
+		if locale == base {
+			break
+		}
+		i := slices.IndexFunc(translations, func(t *Translation) bool { return t.Locale == locale })
+		if i >= 0 {
+			c.Name = translations[i].Name
+			if translations[i].Description != "" {
+				c.Description = translations[i].Description
+			}
+			c.Locale = locale
+			break
+		}
+	}
+	return &c
+}
+
+// TranslationRepository persists product translations.
+type TranslationRepository interface {
+	// PutTranslation creates or replaces the product's translation in
+	// t.Locale, failing with ErrNotFound when the product does not exist.
+	PutTranslation(ctx context.Context, t *Translation) error
+	// ListTranslations returns the product's translations ordered by
+	// locale.
+	ListTranslations(ctx context.Context, productID string) ([]*Translation, error)
+	// ListTranslationsFor returns the translations of productIDs in locales
+	// in one query, ordered by product and then locale.
+	ListTranslationsFor(ctx context.Context, productIDs, locales []string) ([]*Translation, error)
+	DeleteTranslation(ctx context.Context, productID, locale string) error
+}
@@ -0,0 +1,61 @@
+package product
+
+import (
+	"fmt"
+	"strings"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Unit is the base unit a product's stock is counted in.
+type Unit string
+
+const (
+	UnitPiece Unit = "piece"
+	UnitKg    Unit = "kg"
+	UnitLiter Unit = "liter"
+)
+
+// UnitPack names the product's pack in stock adjustments; one pack is
+// PackSize base units.
+const UnitPack = "pack"
+
+// Units lists the supported base units.
+var Units = []Unit{UnitPiece, UnitKg, UnitLiter}
+
+var (
+	// ErrInvalidUnit rejects units other than those in Units.
+	ErrInvalidUnit = errcode.New(errcode.Invalid, "unit_invalid", "unit must be one of piece, kg, liter")
+	// ErrInvalidPackSize rejects packs that hold less than one base unit.
+	ErrInvalidPackSize = errcode.New(errcode.Invalid, "pack_size_invalid", "pack size must be at least 1")
+	// ErrUnitMismatch rejects amounts given in a unit the product cannot be
+	// converted from.
+	ErrUnitMismatch = errcode.New(errcode.Invalid, "unit_mismatch", "amount unit does not match the product")
+)
+
+// ParseUnit normalises a unit name; an empty name is a piece.
+func ParseUnit(name string) (Unit, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return UnitPiece, nil
+	}
+	for _, u := range Units {
+		if string(u) == name {
+			return u, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidUnit.With("supported", Units), name)
+}
+
+// ToBase converts amount, given in unit, to the product's base unit. unit is
+// either empty or the base unit, which leave amount as is, or UnitPack.
+func (p *Product) ToBase(amount int, unit string) (int, error) {
+	switch unit = strings.ToLower(strings.TrimSpace(unit)); unit {
+	case "", string(p.Unit):
+		return amount, nil
+	case UnitPack:
+		return amount * p.PackSize, nil
+	default:
+		return 0, fmt.Errorf("%w: %s is counted in %s or packs of %d", ErrUnitMismatch.With("unit", p.Unit), p.SKU, p.Unit, p.PackSize)
+	}
+}
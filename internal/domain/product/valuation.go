@@ -0,0 +1,23 @@
+package product
+
+import "time"
+
+// ValuationLine is the stock value of the products in one category.
+type ValuationLine struct {
+	// CategoryID is empty for uncategorised products.
+	CategoryID   string  `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	Products     int     `json:"products"`
+	Quantity     int     `json:"quantity"`
+	Value        float64 `json:"value"`
+}
+
+// StockValuation is the inventory value report.
+type StockValuation struct {
+	// AsOf is nil for a valuation of current stock.
+	AsOf          *time.Time      `json:"asOf,omitempty"`
+	Lines         []ValuationLine `json:"lines"`
+	TotalProducts int             `json:"totalProducts"`
+	TotalQuantity int             `json:"totalQuantity"`
+	TotalValue    float64         `json:"totalValue"`
+}
@@ -0,0 +1,118 @@
+// Package promotion describes discount codes customers redeem on orders.
+package promotion
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Kinds of discount.
+const (
+	// KindPercentage takes Value percent off the eligible lines.
+	KindPercentage = "percentage"
+	// KindFixed takes Value off the eligible lines, down to zero.
+	KindFixed = "fixed"
+)
+
+// Kinds lists every kind.
+var Kinds = []string{KindPercentage, KindFixed}
+
+var (
+	// ErrNotFound indicates the promotion does not exist.
+	ErrNotFound = errcode.New(errcode.NotFound, "promotion_not_found", "promotion not found")
+	// ErrDuplicateCode signals that another promotion already uses the code.
+	ErrDuplicateCode = errcode.New(errcode.Conflict, "promotion_code_exists", "promotion code already exists")
+	// ErrInactive rejects redeeming a promotion that was switched off.
+	ErrInactive = errcode.New(errcode.Unprocessable, "promotion_inactive", "promotion is not active")
+	// ErrNotStarted rejects redeeming a promotion before its window opens.
+	ErrNotStarted = errcode.New(errcode.Unprocessable, "promotion_not_started", "promotion has not started yet")
+	// ErrExpired rejects redeeming a promotion after its window closed.
+	ErrExpired = errcode.New(errcode.Unprocessable, "promotion_expired", "promotion has expired")
+	// ErrExhausted rejects redeeming a promotion whose uses are all taken.
+	ErrExhausted = errcode.New(errcode.Unprocessable, "promotion_exhausted", "promotion has no uses left")
+	// ErrNotApplicable rejects a promotion none of the order's lines are
+	// eligible for.
+	ErrNotApplicable = errcode.New(errcode.Unprocessable, "promotion_not_applicable", "promotion does not apply to these products")
+	// ErrMinimumNotMet rejects a promotion when the eligible lines come to
+	// less than its minimum.
+	ErrMinimumNotMet = errcode.New(errcode.Unprocessable, "promotion_minimum_not_met", "order does not reach the promotion's minimum")
+)
+
+// Promotion is a discount code. Without product or category scoping it
+// covers every line of an order.
+type Promotion struct {
+	ID          string  `json:"id"`
+	Code        string  `json:"code"`
+	Description string  `json:"description,omitempty"`
+	Kind        string  `json:"kind"`
+	Value       float64 `json:"value"`
+	// MinSubtotal is the least the eligible lines must come to.
+	MinSubtotal float64    `json:"minSubtotal"`
+	StartsAt    *time.Time `json:"startsAt,omitempty"`
+	EndsAt      *time.Time `json:"endsAt,omitempty"`
+	// UsageLimit caps how many orders may redeem the code; zero is
+	// unlimited.
+	UsageLimit  int       `json:"usageLimit"`
+	Used        int       `json:"used"`
+	ProductIDs  []string  `json:"productIds"`
+	CategoryIDs []string  `json:"categoryIds"`
+	Active      bool      `json:"active"`
+	CreatedBy   string    `json:"createdBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Redeemable fails with the reason the promotion cannot be redeemed at the
+// time, if any.
+func (p *Promotion) Redeemable(at time.Time) error {
+	switch {
+	case !p.Active:
+		return ErrInactive
+	case p.StartsAt != nil && at.Before(*p.StartsAt):
+		return ErrNotStarted.With("startsAt", p.StartsAt)
+	case p.EndsAt != nil && !at.Before(*p.EndsAt):
+		return ErrExpired.With("endsAt", p.EndsAt)
+	case p.UsageLimit > 0 && p.Used >= p.UsageLimit:
+		return ErrExhausted
+	}
+	return nil
+}
+
+// Covers reports whether a product, in the category, is eligible.
+func (p *Promotion) Covers(productID, categoryID string) bool {
+	if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return true
+	}
+	return slices.Contains(p.ProductIDs, productID) || (categoryID != "" && slices.Contains(p.CategoryIDs, categoryID))
+}
+
+// DiscountOn returns the discount on an eligible amount, rounded to cents.
+func (p *Promotion) DiscountOn(eligible float64) float64 {
+	discount := p.Value
+	if p.Kind == KindPercentage {
+		discount = eligible * p.Value / 100
+	}
+	return math.Round(min(discount, eligible)*100) / 100
+}
+
+// Repository persists promotions.
+type Repository interface {
+	Create(ctx context.Context, p *Promotion) error
+	GetByID(ctx context.Context, id string) (*Promotion, error)
+	// GetByCode finds a promotion by its upper-case code.
+	GetByCode(ctx context.Context, code string) (*Promotion, error)
+	// List returns every promotion ordered by code.
+	List(ctx context.Context) ([]*Promotion, error)
+	// Update stores every field but the usage count.
+	Update(ctx context.Context, p *Promotion) error
+	Delete(ctx context.Context, id string) error
+	// Redeem takes one use of the promotion, failing with ErrExhausted
+	// when its limit is reached.
+	Redeem(ctx context.Context, id string, at time.Time) error
+	// Release gives back a use taken by Redeem.
+	Release(ctx context.Context, id string, at time.Time) error
+}
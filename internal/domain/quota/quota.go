@@ -0,0 +1,44 @@
+// Package quota describes the monthly request quotas of API clients, the
+// clients configured in TOKEN_CLIENTS that sign in with a client id.
+package quota
+
+import (
+	"context"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// ErrClientNotFound indicates a client that is not configured.
+var ErrClientNotFound = errcode.New(errcode.NotFound, "client_not_found", "API client not found")
+
+// MonthOf returns the start of the UTC month t falls in; usage is counted
+// per such month.
+func MonthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Usage is what a client has used of its quota in a month.
+type Usage struct {
+	ClientID string    `json:"clientId"`
+	Month    time.Time `json:"month"`
+	Requests int64     `json:"requests"`
+	// Limit is zero for clients without a quota, and Remaining is then
+	// omitted.
+	Limit     int64     `json:"limit,omitempty"`
+	Remaining *int64    `json:"remaining,omitempty"`
+	ResetsAt  time.Time `json:"resetsAt"`
+	// Enforced reports whether requests over the limit are rejected rather
+	// than only warned about.
+	Enforced bool `json:"enforced"`
+}
+
+// Repository persists request counts per client and month.
+type Repository interface {
+	// Add adds requests to the client's count for month and returns the
+	// new total, which includes what other instances added.
+	Add(ctx context.Context, clientID string, month time.Time, requests int64) (int64, error)
+	// Get returns the client's count for month, zero when it made none.
+	Get(ctx context.Context, clientID string, month time.Time) (int64, error)
+}
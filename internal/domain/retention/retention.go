@@ -0,0 +1,61 @@
+// Package retention describes how long old records are kept before the
+// scheduled purge removes them.
+package retention
+
+import (
+	"context"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Targets are the kinds of data a policy applies to.
+const (
+	// TargetActivity is the audit trail behind the activity feed.
+	TargetActivity = "activity"
+	// TargetTrash is soft-deleted users and products.
+	TargetTrash = "trash"
+	// TargetSessions is opaque-token sessions that have expired.
+	TargetSessions = "sessions"
+)
+
+// Targets lists every target in a stable order.
+var Targets = []string{TargetActivity, TargetTrash, TargetSessions}
+
+// ErrUnknownTarget indicates a policy for data that has no retention.
+var ErrUnknownTarget = errcode.New(errcode.NotFound, "retention_target_not_found", "retention target not found")
+
+// Policy keeps a target's records for Days days; 0 keeps them forever.
+type Policy struct {
+	Target string `json:"target"`
+	Days   int    `json:"days"`
+	// UpdatedAt and UpdatedBy are empty while the configured default
+	// applies.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string     `json:"updatedBy,omitempty"`
+}
+
+// Result reports one target's purge, or with DryRun what a purge would
+// delete.
+type Result struct {
+	Target string `json:"target"`
+	Days   int    `json:"days"`
+	// Cutoff is nil for targets kept forever, which are skipped.
+	Cutoff *time.Time `json:"cutoff,omitempty"`
+	// Count is how many records were deleted, or would be on a dry run.
+	Count  int    `json:"count"`
+	DryRun bool   `json:"dryRun"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Repository stores the policies admins have saved.
+type Repository interface {
+	ListPolicies(ctx context.Context) ([]Policy, error)
+	SavePolicy(ctx context.Context, policy Policy) error
+}
+
+// Purger deletes one target's records that are older than a cutoff.
+type Purger interface {
+	CountBefore(ctx context.Context, cutoff time.Time) (int, error)
+	PurgeBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
@@ -0,0 +1,130 @@
+// Package returns describes goods a customer sends back from an order and
+// the authorisation (RMA) they move through.
+package returns
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Statuses a return moves through. Received and rejected returns are final.
+const (
+	StatusRequested = "requested"
+	StatusApproved  = "approved"
+	StatusReceived  = "received"
+	StatusRejected  = "rejected"
+)
+
+// Statuses lists every status in a stable order.
+var Statuses = []string{StatusRequested, StatusApproved, StatusReceived, StatusRejected}
+
+// Reason codes a returned line can give.
+const (
+	ReasonDamaged        = "damaged"
+	ReasonDefective      = "defective"
+	ReasonWrongItem      = "wrong_item"
+	ReasonNotAsDescribed = "not_as_described"
+	ReasonUnwanted       = "unwanted"
+	ReasonOther          = "other"
+)
+
+// Reasons lists every reason code in a stable order.
+var Reasons = []string{ReasonDamaged, ReasonDefective, ReasonWrongItem, ReasonNotAsDescribed, ReasonUnwanted, ReasonOther}
+
+var (
+	// ErrNotFound indicates the return does not exist.
+	ErrNotFound = errcode.New(errcode.NotFound, "return_not_found", "return not found")
+	// ErrInvalidStatus rejects a value that is not a return status.
+	ErrInvalidStatus = errcode.New(errcode.Invalid, "return_status_invalid", "status is not a return status").With("supported", Statuses)
+	// ErrInvalidReason rejects a line whose reason is not a reason code.
+	ErrInvalidReason = errcode.New(errcode.Invalid, "return_reason_invalid", "reason is not a return reason").With("supported", Reasons)
+	// ErrIllegalTransition rejects a decision the return's status does not
+	// allow, such as receiving goods that were never approved.
+	ErrIllegalTransition = errcode.New(errcode.Conflict, "return_transition_illegal", "return cannot move to that status")
+	// ErrStatusChanged indicates the return was decided concurrently.
+	ErrStatusChanged = errcode.New(errcode.Conflict, "return_status_changed", "return status was changed concurrently")
+)
+
+// transitions lists the statuses each status may move to.
+var transitions = map[string][]string{
+	StatusRequested: {StatusApproved, StatusRejected},
+	StatusApproved:  {StatusReceived},
+}
+
+// ValidStatus reports whether status is a return status.
+func ValidStatus(status string) bool {
+	return slices.Contains(Statuses, status)
+}
+
+// ValidReason reports whether reason is a reason code.
+func ValidReason(reason string) bool {
+	return slices.Contains(Reasons, reason)
+}
+
+// Line is a quantity of one product from the order being sent back.
+type Line struct {
+	ProductID string `json:"productId"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason"`
+}
+
+// Return is a request to send back lines of a shipped order.
+type Return struct {
+	ID      string `json:"id"`
+	OrderID string `json:"orderId"`
+	Status  string `json:"status"`
+	Lines   []Line `json:"lines"`
+	Note    string `json:"note,omitempty"`
+	// Reason explains a rejection.
+	Reason      string     `json:"reason,omitempty"`
+	RequestedBy string     `json:"requestedBy,omitempty"`
+	DecidedBy   string     `json:"decidedBy,omitempty"`
+	ReceivedBy  string     `json:"receivedBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty"`
+	ReceivedAt  *time.Time `json:"receivedAt,omitempty"`
+}
+
+// Move sets the return's status to status on behalf of by, or fails with
+// ErrIllegalTransition naming the allowed statuses.
+func (r *Return) Move(status, by string, at time.Time) error {
+	if !slices.Contains(transitions[r.Status], status) {
+		return ErrIllegalTransition.With("from", r.Status).With("to", status).With("allowed", append([]string{}, transitions[r.Status]...))
+	}
+	r.Status = status
+	r.UpdatedAt = at
+	if status == StatusReceived {
+		r.ReceivedBy = by
+		r.ReceivedAt = &at
+	} else {
+		r.DecidedBy = by
+		r.DecidedAt = &at
+	}
+	return nil
+}
+
+// Filter selects returns to list; empty fields match every return.
+type Filter struct {
+	Status  string
+	OrderID string
+}
+
+// Repository persists returns with their lines.
+type Repository interface {
+	Create(ctx context.Context, r *Return) error
+	GetByID(ctx context.Context, id string) (*Return, error)
+	// List returns the returns the filter selects, newest first.
+	List(ctx context.Context, filter Filter) ([]*Return, error)
+	// Update stores a decision on the return and fails with
+	// ErrStatusChanged if the stored return is no longer in status from.
+	Update(ctx context.Context, r *Return, from string) error
+	// Returned sums, per product, the quantity of an order covered by
+	// returns that were not rejected.
+	Returned(ctx context.Context, orderID string) (map[string]int, error)
+}
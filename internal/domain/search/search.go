@@ -0,0 +1,29 @@
+// Package search describes typeahead matches across entity types.
+package search
+
+import "context"
+
+// Groups of results, named after the collections they come from.
+const (
+	GroupProducts   = "products"
+	GroupUsers      = "users"
+	GroupCategories = "categories"
+)
+
+// Hit is one match, with just enough to render a suggestion and link to the
+// record.
+type Hit struct {
+	ID string `json:"id"`
+	// Title is the record's main label and Subtitle adds context, such as
+	// a product's SKU or a user's email.
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle,omitempty"`
+	Score    float64 `json:"score"`
+}
+
+// Source is implemented by repositories that can be searched. query is
+// matched case-insensitively; hits are ordered best first, with records whose
+// label starts with the query ahead of the rest.
+type Source interface {
+	Search(ctx context.Context, query string, limit int) ([]Hit, error)
+}
@@ -0,0 +1,43 @@
+// Package session describes server-side records of issued opaque tokens.
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound indicates a session could not be located.
+var ErrNotFound = errors.New("session not found")
+
+// Session is one issued opaque token. Only a hash of the token is stored.
+type Session struct {
+	ID        string   `json:"id"`
+	UserID    string   `json:"userId"`
+	TokenHash string   `json:"-"`
+	ClientID  string   `json:"clientId,omitempty"`
+	Scopes    []string `json:"scopes"`
+	// ClientIP is the address the session was opened from.
+	ClientIP string `json:"clientIp,omitempty"`
+	// TokenVersion is the user's token version when the session began.
+	TokenVersion int       `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastUsedAt   time.Time `json:"lastUsedAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// Repository persists sessions.
+type Repository interface {
+	Create(ctx context.Context, s *Session) error
+	GetByTokenHash(ctx context.Context, hash string) (*Session, error)
+	Touch(ctx context.Context, id string, at time.Time) error
+	// ListActive returns the user's unexpired sessions, newest first.
+	ListActive(ctx context.Context, userID string, now time.Time) ([]*Session, error)
+	// ListActiveFor returns the unexpired sessions of all of userIDs in one
+	// query, newest first.
+	ListActiveFor(ctx context.Context, userIDs []string, now time.Time) ([]*Session, error)
+	Delete(ctx context.Context, userID, id string) error
+	DeleteByTokenHash(ctx context.Context, hash string) error
+	// DeleteExpired removes the user's sessions that expired before cutoff.
+	DeleteExpired(ctx context.Context, userID string, cutoff time.Time) error
+}
@@ -0,0 +1,148 @@
+// Package shipment describes parcels sent to customers through shipping
+// carriers and the tracking updates the carriers report for them.
+package shipment
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Shipment statuses, in the order a parcel normally goes through them.
+// Delivered and returned parcels are final; a parcel with an exception may
+// still recover.
+const (
+	StatusLabelCreated   = "label_created"
+	StatusInTransit      = "in_transit"
+	StatusOutForDelivery = "out_for_delivery"
+	StatusDelivered      = "delivered"
+	StatusException      = "exception"
+	StatusReturned       = "returned"
+)
+
+// Statuses lists every status.
+var Statuses = []string{StatusLabelCreated, StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusException, StatusReturned}
+
+var (
+	// ErrNotFound indicates the shipment does not exist.
+	ErrNotFound = errcode.New(errcode.NotFound, "shipment_not_found", "shipment not found")
+	// ErrUnknownCarrier rejects a carrier that is not configured.
+	ErrUnknownCarrier = errcode.New(errcode.Invalid, "shipment_carrier_unknown", "unknown shipping carrier")
+	// ErrWebhookUnsupported rejects webhooks for a carrier that only
+	// reports tracking when polled.
+	ErrWebhookUnsupported = errcode.New(errcode.NotFound, "shipment_webhook_unsupported", "carrier does not send webhooks")
+	// ErrInvalidSignature rejects a webhook whose signature is missing or
+	// does not match its payload.
+	ErrInvalidSignature = errcode.New(errcode.Unauthenticated, "shipment_signature_invalid", "invalid carrier webhook signature")
+	// ErrInvalidWebhook rejects a signed webhook the carrier's format cannot
+	// be read from.
+	ErrInvalidWebhook = errcode.New(errcode.Invalid, "shipment_webhook_invalid", "invalid carrier webhook payload")
+)
+
+// Final reports whether a parcel with status will not move again.
+func Final(status string) bool {
+	return status == StatusDelivered || status == StatusReturned
+}
+
+// TrackingEvent is one scan or update the carrier reports.
+type TrackingEvent struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Shipment is a parcel sent for an order.
+type Shipment struct {
+	ID             string `json:"id"`
+	OrderID        string `json:"orderId"`
+	Carrier        string `json:"carrier"`
+	Service        string `json:"service,omitempty"`
+	TrackingNumber string `json:"trackingNumber"`
+	LabelURL       string `json:"labelUrl,omitempty"`
+	// Reference is the carrier's own id for tracking the parcel.
+	Reference   string          `json:"-"`
+	Status      string          `json:"status"`
+	Events      []TrackingEvent `json:"events"`
+	CreatedBy   string          `json:"createdBy,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	DeliveredAt *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// Address is where a parcel is sent from or to.
+type Address struct {
+	Name       string
+	Phone      string
+	Email      string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// LabelRequest asks a carrier for a shipping label.
+type LabelRequest struct {
+	ShipmentID string
+	OrderID    string
+	// Reference is printed on the label, such as the order reference.
+	Reference string
+	// Service picks the carrier's service level; empty picks the cheapest.
+	Service     string
+	From        Address
+	To          Address
+	WeightGrams int
+}
+
+// Label is a bought shipping label.
+type Label struct {
+	TrackingNumber string
+	Service        string
+	LabelURL       string
+	// Reference is the carrier's id for tracking the parcel.
+	Reference string
+}
+
+// Tracking is a parcel's status and history as the carrier reports it.
+type Tracking struct {
+	TrackingNumber string
+	Status         string
+	// Events is the parcel's whole history, or empty when the carrier only
+	// reports its current status.
+	Events []TrackingEvent
+}
+
+// Carrier buys labels and tracks parcels with a shipping carrier.
+type Carrier interface {
+	// Name identifies the carrier in requests and stored shipments.
+	Name() string
+	CreateLabel(ctx context.Context, req LabelRequest) (*Label, error)
+	Track(ctx context.Context, s *Shipment) (*Tracking, error)
+}
+
+// WebhookCarrier is a carrier that also pushes tracking updates.
+type WebhookCarrier interface {
+	Carrier
+	// Webhook verifies a webhook's signature and reads the tracking it
+	// carries. A nil result means the webhook is not a tracking update and
+	// can be acknowledged and ignored.
+	Webhook(payload []byte, header http.Header) (*Tracking, error)
+}
+
+// Repository persists shipments with their tracking events.
+type Repository interface {
+	Create(ctx context.Context, s *Shipment) error
+	GetByID(ctx context.Context, id string) (*Shipment, error)
+	// GetByTracking finds the shipment a carrier's webhook is about.
+	GetByTracking(ctx context.Context, carrier, trackingNumber string) (*Shipment, error)
+	// ListByOrder returns an order's shipments, newest first.
+	ListByOrder(ctx context.Context, orderID string) ([]*Shipment, error)
+	// ListOpen returns the shipments that are not final, oldest first.
+	ListOpen(ctx context.Context) ([]*Shipment, error)
+	// Update stores a shipment's status and tracking events.
+	Update(ctx context.Context, s *Shipment) error
+}
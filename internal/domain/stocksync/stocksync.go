@@ -0,0 +1,64 @@
+// Package stocksync describes pulling product and stock data from external
+// systems and the history of those runs.
+package stocksync
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRunNotFound indicates a sync run could not be located.
+var ErrRunNotFound = errors.New("sync run not found")
+
+// Record is one product as read from a source, keyed by the source's own
+// field names. Nested fields are flattened with dots ("variant.sku").
+type Record map[string]string
+
+// Connector reads the current product data of an external system.
+type Connector interface {
+	Fetch(ctx context.Context) ([]Record, error)
+}
+
+// Run statuses.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Run is one execution of a connector and what it changed.
+type Run struct {
+	ID         string     `json:"id"`
+	Connector  string     `json:"connector"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Fetched    int        `json:"fetched"`
+	Created    int        `json:"created"`
+	Updated    int        `json:"updated"`
+	Unchanged  int        `json:"unchanged"`
+	// Duplicates counts records dropped because a later record had the
+	// same SKU.
+	Duplicates int `json:"duplicates"`
+	// Rejected counts records that could not be applied; Problems explains
+	// the first few.
+	Rejected int      `json:"rejected"`
+	Problems []string `json:"problems"`
+	// Error is why a failed run stopped.
+	Error string `json:"error,omitempty"`
+}
+
+// RunFilter selects runs, newest first.
+type RunFilter struct {
+	Connector string
+	Limit     int
+}
+
+// RunRepository persists the run history.
+type RunRepository interface {
+	CreateRun(ctx context.Context, run *Run) error
+	UpdateRun(ctx context.Context, run *Run) error
+	GetRun(ctx context.Context, id string) (*Run, error)
+	ListRuns(ctx context.Context, filter RunFilter) ([]*Run, error)
+}
@@ -0,0 +1,83 @@
+// Package trash describes soft-deleted records that can be restored or
+// purged.
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Kinds of records that can be trashed.
+const (
+	KindUser    = "user"
+	KindProduct = "product"
+)
+
+// ErrUnknownKind indicates a trash operation on an unsupported record kind.
+var ErrUnknownKind = errors.New("unknown trash item kind")
+
+// MaxBulkDelete caps how many records one bulk delete may trash.
+const MaxBulkDelete = 100
+
+// ErrInvalidBulkDelete wraps bulk deletes that name no records, too many,
+// or blank ids.
+var ErrInvalidBulkDelete = errcode.New(errcode.Invalid, "bulk_delete_invalid", "invalid bulk delete")
+
+// BulkDelete names the records to move to the trash together.
+type BulkDelete struct {
+	IDs []string `json:"ids"`
+}
+
+// Normalize returns the ids trimmed and without repeats, in the order
+// given, and rejects lists that are empty or exceed MaxBulkDelete.
+func (b BulkDelete) Normalize() ([]string, error) {
+	ids := make([]string, 0, len(b.IDs))
+	for _, id := range b.IDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, fmt.Errorf("%w: ids must not be blank", ErrInvalidBulkDelete)
+		}
+		if slices.Contains(ids, id) {
+			continue
+		}
+		if len(ids) == MaxBulkDelete {
+			return nil, fmt.Errorf("%w: at most %d ids can be deleted at once", ErrInvalidBulkDelete, MaxBulkDelete)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one id is required", ErrInvalidBulkDelete)
+	}
+	return ids, nil
+}
+
+// BulkDeleteReport lists the records a bulk delete moved to the trash.
+type BulkDeleteReport struct {
+	Deleted int      `json:"deleted"`
+	IDs     []string `json:"ids"`
+}
+
+// Item is one soft-deleted record.
+type Item struct {
+	Kind      string    `json:"kind"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// Bin is implemented by repositories whose deletes are recoverable. Restore
+// and Purge only act on trashed records and return the repository's
+// not-found error otherwise.
+type Bin interface {
+	ListDeleted(ctx context.Context) ([]Item, error)
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, id string) error
+	CountDeletedBefore(ctx context.Context, cutoff time.Time) (int, error)
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
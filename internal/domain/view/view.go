@@ -0,0 +1,188 @@
+// Package view describes the filter and sort presets users save for the
+// lists they browse.
+package view
+
+import (
+	"cmp"
+	"context"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Lists views can be saved for.
+const (
+	ResourceProducts = "products"
+	ResourceUsers    = "users"
+)
+
+// Resources lists every list views can be saved for.
+var Resources = []string{ResourceProducts, ResourceUsers}
+
+// Types of the fields views filter and sort on.
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	// TypeTime fields hold RFC 3339 timestamps.
+	TypeTime = "time"
+)
+
+// Fields maps each list to the fields its views may use, by their name in
+// the list's JSON, and their type.
+var Fields = map[string]map[string]string{
+	ResourceProducts: {
+		"name":        TypeString,
+		"description": TypeString,
+		"sku":         TypeString,
+		"barcode":     TypeString,
+		"unit":        TypeString,
+		"categoryId":  TypeString,
+		"price":       TypeNumber,
+		"packSize":    TypeNumber,
+		"quantity":    TypeNumber,
+		"reserved":    TypeNumber,
+		"available":   TypeNumber,
+		"createdAt":   TypeTime,
+		"updatedAt":   TypeTime,
+	},
+	ResourceUsers: {
+		"email":      TypeString,
+		"name":       TypeString,
+		"role":       TypeString,
+		"locale":     TypeString,
+		"timezone":   TypeString,
+		"created_at": TypeTime,
+		"updated_at": TypeTime,
+	},
+}
+
+// Filter operators. Contains matches strings case-insensitively; the
+// others compare values of the field's type.
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpContains = "contains"
+)
+
+// Ops lists every operator.
+var Ops = []string{OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpContains}
+
+var (
+	// ErrNotFound indicates the view does not exist or belongs to another
+	// user.
+	ErrNotFound = errcode.New(errcode.NotFound, "view_not_found", "view not found")
+	// ErrDuplicateName signals that the user already has a view of the list
+	// with the name.
+	ErrDuplicateName = errcode.New(errcode.Conflict, "view_name_exists", "a view with this name already exists")
+)
+
+// Filter keeps the list items whose Field compares to Value with Op.
+// Value is a string for string and time fields and a number for number
+// fields.
+type Filter struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value any    `json:"value"`
+}
+
+// View is a named filter and sort combination a user saved for a list.
+type View struct {
+	ID       string   `json:"id"`
+	UserID   string   `json:"-"`
+	Resource string   `json:"resource"`
+	Name     string   `json:"name"`
+	Filters  []Filter `json:"filters"`
+	// Sort is the field to order by, prefixed with "-" for descending
+	// order; empty keeps the list's own order.
+	Sort      string    `json:"sort,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Matches reports whether item, a list item as a JSON object, passes every
+// filter. A field the item leaves out counts as its type's zero value.
+func (v *View) Matches(item map[string]any) bool {
+	fields := Fields[v.Resource]
+	for _, f := range v.Filters {
+		typ := fields[f.Field]
+		if f.Op == OpContains {
+			s, _ := item[f.Field].(string)
+			want, _ := f.Value.(string)
+			if !strings.Contains(strings.ToLower(s), strings.ToLower(want)) {
+				return false
+			}
+			continue
+		}
+		c := compare(typ, item[f.Field], f.Value)
+		var ok bool
+		switch f.Op {
+		case OpEq:
+			ok = c == 0
+		case OpNe:
+			ok = c != 0
+		case OpLt:
+			ok = c < 0
+		case OpLte:
+			ok = c <= 0
+		case OpGt:
+			ok = c > 0
+		case OpGte:
+			ok = c >= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare orders two list items by the view's sort field.
+func (v *View) Compare(a, b map[string]any) int {
+	field, descending := strings.CutPrefix(v.Sort, "-")
+	if field == "" {
+		return 0
+	}
+	c := compare(Fields[v.Resource][field], a[field], b[field])
+	if descending {
+		return -c
+	}
+	return c
+}
+
+// compare orders two JSON values of a field of typ. Values of the wrong
+// type, such as a missing field, count as the type's zero value.
+func compare(typ string, a, b any) int {
+	switch typ {
+	case TypeNumber:
+		x, _ := a.(float64)
+		y, _ := b.(float64)
+		return cmp.Compare(x, y)
+	case TypeTime:
+		return parseTime(a).Compare(parseTime(b))
+	}
+	x, _ := a.(string)
+	y, _ := b.(string)
+	return strings.Compare(x, y)
+}
+
+func parseTime(v any) time.Time {
+	s, _ := v.(string)
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+// Repository persists views.
+type Repository interface {
+	Create(ctx context.Context, v *View) error
+	GetByID(ctx context.Context, id string) (*View, error)
+	// List returns the user's views of resource, or of every list when
+	// resource is empty, ordered by list and then name.
+	List(ctx context.Context, userID, resource string) ([]*View, error)
+	Update(ctx context.Context, v *View) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,110 @@
+// Package watch describes users' subscriptions to changes of individual
+// products and the notifications those changes produce.
+package watch
+
+import (
+	"context"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+// Fields a watch can follow.
+const (
+	FieldPrice  = "price"
+	FieldStock  = "stock"
+	FieldStatus = "status"
+)
+
+// Fields lists every field in a stable order.
+var Fields = []string{FieldPrice, FieldStock, FieldStatus}
+
+// Product statuses: a deleted product is in the trash and may be restored.
+const (
+	StatusActive  = "active"
+	StatusDeleted = "deleted"
+)
+
+// ErrNotFound indicates the user does not watch the product.
+var ErrNotFound = errcode.New(errcode.NotFound, "watch_not_found", "product is not watched")
+
+// Snapshot is the state of a product's watched fields.
+type Snapshot struct {
+	Price  float64 `json:"price"`
+	Stock  int     `json:"stock"`
+	Status string  `json:"status"`
+}
+
+// Watch subscribes a user to changes of some fields of a product.
+type Watch struct {
+	UserID    string   `json:"-"`
+	ProductID string   `json:"productId"`
+	Fields    []string `json:"fields"`
+	// ProductName and Last are the product as the watcher was last told
+	// about it.
+	ProductName string    `json:"productName"`
+	Last        Snapshot  `json:"last"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Change is one watched field that changed.
+type Change struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Diff returns the changes from the watch's last snapshot to next in the
+// fields it follows.
+func (w *Watch) Diff(next Snapshot) []Change {
+	var changes []Change
+	for _, field := range w.Fields {
+		switch field {
+		case FieldPrice:
+			if w.Last.Price != next.Price {
+				changes = append(changes, Change{Field: field, From: w.Last.Price, To: next.Price})
+			}
+		case FieldStock:
+			if w.Last.Stock != next.Stock {
+				changes = append(changes, Change{Field: field, From: w.Last.Stock, To: next.Stock})
+			}
+		case FieldStatus:
+			if w.Last.Status != next.Status {
+				changes = append(changes, Change{Field: field, From: w.Last.Status, To: next.Status})
+			}
+		}
+	}
+	return changes
+}
+
+// Notification tells a watcher about changes to a product.
+type Notification struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"-"`
+	ProductID   string    `json:"productId"`
+	ProductName string    `json:"productName"`
+	Changes     []Change  `json:"changes"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Repository persists watches and their notifications.
+type Repository interface {
+	// Put creates or replaces the user's watch of w.ProductID.
+	Put(ctx context.Context, w *Watch) error
+	Get(ctx context.Context, userID, productID string) (*Watch, error)
+	// ListByUser returns the user's watches, newest first.
+	ListByUser(ctx context.Context, userID string) ([]*Watch, error)
+	// ListByProduct returns every watch of the product.
+	ListByProduct(ctx context.Context, productID string) ([]*Watch, error)
+	// SetLast records the name and snapshot every watcher of the product
+	// was told about.
+	SetLast(ctx context.Context, productID, name string, last Snapshot) error
+	Delete(ctx context.Context, userID, productID string) error
+
+	AddNotification(ctx context.Context, n *Notification) error
+	// ListNotifications returns the user's latest notifications, newest
+	// first.
+	ListNotifications(ctx context.Context, userID string, limit int) ([]*Notification, error)
+	ClearNotifications(ctx context.Context, userID string) error
+}
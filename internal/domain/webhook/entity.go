@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+)
+
+var (
+	// ErrNotFound indicates a subscription could not be located.
+	ErrNotFound = errcode.New(errcode.NotFound, "webhook_not_found", "webhook not found")
+	// ErrDeliveryNotFound indicates a delivery could not be located.
+	ErrDeliveryNotFound = errcode.New(errcode.NotFound, "webhook_delivery_not_found", "webhook delivery not found")
+)
+
+// AllEvents subscribes to every event type.
+const AllEvents = "*"
+
+// Payload schema versions. A subscription is pinned to one and receives
+// payloads in that shape however the models have changed since.
+const (
+	// PayloadV1 is the shape before products had a barcode, unit, pack
+	// size and cost price.
+	PayloadV1 = 1
+	// PayloadV2 adds those product fields.
+	PayloadV2 = 2
+	// LatestPayloadVersion is what new subscriptions are pinned to.
+	LatestPayloadVersion = PayloadV2
+)
+
+// ErrInvalidPayloadVersion rejects versions this server cannot produce.
+var ErrInvalidPayloadVersion = errcode.New(errcode.Invalid, "webhook_version_invalid", "payload version is not supported")
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Subscription is an endpoint that receives signed event notifications.
+type Subscription struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Secret      string   `json:"-"`
+	Active      bool     `json:"active"`
+	// Version is the payload schema version deliveries are converted to.
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Matches reports whether the subscription wants events of eventType.
+func (s *Subscription) Matches(eventType string) bool {
+	for _, e := range s.Events {
+		if e == AllEvents || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event queued for one subscription, with the outcome of the
+// latest attempt.
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
@@ -0,0 +1,25 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines persistence behaviours for subscriptions and their
+// delivery log.
+type Repository interface {
+	CreateSubscription(ctx context.Context, sub *Subscription) error
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+	UpdateSubscription(ctx context.Context, sub *Subscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	GetDelivery(ctx context.Context, id string) (*Delivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+	// ClaimDue returns up to limit pending deliveries whose next attempt is
+	// due and pushes their next attempt back by lease, so concurrent
+	// dispatchers do not pick up the same delivery.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+}
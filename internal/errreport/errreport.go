@@ -0,0 +1,124 @@
+// Package errreport forwards unexpected errors and panics to an external
+// error tracker (e.g. Sentry) together with the request they happened in.
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// Reporter sends error reports to a tracking service. Implementations must
+// not block the caller for long.
+type Reporter interface {
+	Report(ctx context.Context, report Report)
+	// Flush waits up to timeout for queued reports to be sent.
+	Flush(timeout time.Duration)
+}
+
+// Report is one captured error.
+type Report struct {
+	Err   error
+	Level string
+	// Stack is set for panics; reporters fall back to the caller's stack.
+	Stack []byte
+	Scope Scope
+	// Tags are extra searchable key/values, e.g. the background job name.
+	Tags map[string]string
+}
+
+// Scope describes what was going on when the error happened. The HTTP layer
+// stores one per request and fills in the user once authenticated.
+type Scope struct {
+	RequestID string
+	Method    string
+	Route     string
+	UserID    string
+	// ClientIP is the caller's address, resolved through trusted proxies.
+	ClientIP string
+}
+
+type ctxKeyScope struct{}
+
+// WithScope attaches scope to ctx; later changes to *scope are visible to
+// reports made with the returned context.
+func WithScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, ctxKeyScope{}, scope)
+}
+
+// ScopeFromContext returns the scope attached to ctx, if any.
+func ScopeFromContext(ctx context.Context) *Scope {
+	scope, _ := ctx.Value(ctxKeyScope{}).(*Scope)
+	return scope
+}
+
+var defaultReporter atomic.Pointer[Reporter]
+
+// SetDefault installs the process-wide reporter.
+func SetDefault(r Reporter) {
+	defaultReporter.Store(&r)
+}
+
+// Default returns the process-wide reporter, which logs until SetDefault is
+// called.
+func Default() Reporter {
+	if r := defaultReporter.Load(); r != nil {
+		return *r
+	}
+	return LogReporter{}
+}
+
+// Error reports err at error level with the scope found in ctx.
+func Error(ctx context.Context, err error, tags map[string]string) {
+	if err == nil {
+		return
+	}
+	report := Report{Err: err, Level: "error", Tags: tags}
+	if scope := ScopeFromContext(ctx); scope != nil {
+		report.Scope = *scope
+	}
+	Default().Report(ctx, report)
+}
+
+// Panic reports a recovered panic value with the current stack.
+func Panic(ctx context.Context, recovered any, tags map[string]string) {
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", recovered)
+	}
+	report := Report{Err: err, Level: "fatal", Stack: debug.Stack(), Tags: tags}
+	if scope := ScopeFromContext(ctx); scope != nil {
+		report.Scope = *scope
+	}
+	Default().Report(ctx, report)
+}
+
+// Go runs fn on a new goroutine, reporting (instead of crashing on) a panic.
+// Background jobs use it so a bug in one job does not take the server down.
+func Go(ctx context.Context, job string, fn func(ctx context.Context)) {
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				Panic(ctx, recovered, map[string]string{"job": job})
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// LogReporter writes reports to the standard logger.
+type LogReporter struct{}
+
+// Report logs the error and its scope.
+func (LogReporter) Report(_ context.Context, r Report) {
+	log.Printf("%s: %v (request_id=%s route=%s %s user=%s tags=%v)", r.Level, r.Err, r.Scope.RequestID, r.Scope.Method, r.Scope.Route, r.Scope.UserID, r.Tags)
+	if len(r.Stack) > 0 {
+		log.Printf("%s", r.Stack)
+	}
+}
+
+// Flush is a no-op.
+func (LogReporter) Flush(time.Duration) {}
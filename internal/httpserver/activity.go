@@ -0,0 +1,132 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	activitydomain "backoffice/backend/internal/domain/activity"
+	"backoffice/backend/internal/errreport"
+	activityusecase "backoffice/backend/internal/usecase/activity"
+)
+
+// SetActivityService enables GET /admin/activity; without it the route
+// answers 404.
+func (s *Server) SetActivityService(activity *activityusecase.Service) {
+	s.activityService = activity
+}
+
+// handleActivity serves GET /admin/activity?actor=&action=&entityType=&entityId=&cursor=&limit=,
+// the admin dashboard's feed of recent changes, newest first.
+func (s *Server) handleActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.activityService == nil {
+		writeError(w, http.StatusNotFound, "activity log is not configured")
+		return
+	}
+	query := r.URL.Query()
+	q := activityusecase.Query{
+		ActorID:    query.Get("actor"),
+		EntityType: query.Get("entityType"),
+		EntityID:   query.Get("entityId"),
+		Action:     query.Get("action"),
+		Cursor:     query.Get("cursor"),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, activityusecase.ErrInvalidLimit.Error())
+			return
+		}
+		q.Limit = limit
+	}
+
+	page, err := s.activityService.List(r.Context(), q)
+	if err != nil {
+		switch {
+		case errors.Is(err, activityusecase.ErrInvalidCursor),
+			errors.Is(err, activityusecase.ErrInvalidLimit),
+			errors.Is(err, activityusecase.ErrInvalidFilter):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleAuditExport serves GET /admin/audit-events/export?from=&to=, the
+// hash-chained audit trail as newline-delimited JSON: one entry per line in
+// chain order, then {"summary":{...}} with the anchor, head and the result
+// of verifying the chain. from and to are dates or RFC 3339 timestamps; a
+// bare date means the start of that UTC day.
+func (s *Server) handleAuditExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.activityService == nil {
+		writeError(w, http.StatusNotFound, "activity log is not configured")
+		return
+	}
+	var q activityusecase.ExportQuery
+	bounds := []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &q.From}, {"to", &q.To}}
+	for _, bound := range bounds {
+		raw := strings.TrimSpace(r.URL.Query().Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseExportTime(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, bound.name+" must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+			return
+		}
+		*bound.dst = parsed
+	}
+
+	// The stream starts with the first entry, so failures before it still
+	// get a proper status.
+	var out *ndjsonStream
+	start := func() {
+		if out == nil {
+			w.Header().Set("Content-Disposition", `attachment; filename="audit-events.ndjson"`)
+			out = newNDJSONStream(w, http.StatusOK)
+		}
+	}
+	summary, err := s.activityService.Export(r.Context(), q, func(e *activitydomain.Entry) error {
+		start()
+		return out.Encode(e)
+	})
+	switch {
+	case errors.Is(err, activityusecase.ErrInvalidRange) && out == nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil && out == nil:
+		writeInternalError(w, r, err)
+		return
+	case err != nil:
+		// Without its summary line the export reads as incomplete.
+		errreport.Error(r.Context(), err, nil)
+		_ = out.Close()
+		return
+	}
+	start()
+	_ = out.Encode(map[string]*activityusecase.ExportSummary{"summary": summary})
+	_ = out.Close()
+}
+
+func parseExportTime(raw string) (time.Time, error) {
+	if day, err := time.Parse(time.DateOnly, raw); err == nil {
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
@@ -0,0 +1,140 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	approvaldomain "backoffice/backend/internal/domain/approval"
+	approvalusecase "backoffice/backend/internal/usecase/approval"
+)
+
+// approvalCaches names the response cache group each action changes.
+var approvalCaches = map[string]string{
+	approvaldomain.ActionBulkPriceUpdate: "/products",
+}
+
+// SetApprovalService enables /admin/approvals and holds the configured
+// actions for a second admin; without it actions run immediately and the
+// endpoints answer 404.
+func (s *Server) SetApprovalService(approvals *approvalusecase.Service) {
+	s.approvalService = approvals
+}
+
+// requiresApproval reports whether action must wait for a second admin.
+func (s *Server) requiresApproval(action string) bool {
+	return s.approvalService != nil && s.approvalService.Required(action)
+}
+
+// requestApproval holds an action for a second admin and answers 202 with
+// the pending approval.
+func (s *Server) requestApproval(w http.ResponseWriter, r *http.Request, input approvalusecase.RequestInput) {
+	if user, ok := currentUserFromContext(r.Context()); ok {
+		input.RequestedBy = user.ID
+	}
+	approval, err := s.approvalService.Request(r.Context(), input)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, approval)
+}
+
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.approvalService == nil {
+		writeError(w, http.StatusNotFound, "approvals are not configured")
+		return
+	}
+	approvals, err := s.approvalService.List(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeApprovalError(w, r, err)
+		return
+	}
+	if approvals == nil {
+		approvals = []*approvaldomain.Approval{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": approvals})
+}
+
+// handleApprovalByID serves GET /admin/approvals/{id} and
+// POST /admin/approvals/{id}/approve|reject.
+func (s *Server) handleApprovalByID(w http.ResponseWriter, r *http.Request) {
+	if s.approvalService == nil {
+		writeError(w, http.StatusNotFound, "approvals are not configured")
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/approvals/"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+	if id == "" || (action != "" && action != "approve" && action != "reject") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+
+	ctx := r.Context()
+	if action == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		approval, err := s.approvalService.Get(ctx, id)
+		if err != nil {
+			writeApprovalError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, approval)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	user, ok := currentUserFromContext(ctx)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	var approval *approvaldomain.Approval
+	var err error
+	if action == "approve" {
+		approval, err = s.approvalService.Approve(ctx, id, user.ID)
+	} else {
+		var payload struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		approval, err = s.approvalService.Reject(ctx, id, user.ID, payload.Reason)
+	}
+	if err != nil {
+		writeApprovalError(w, r, err)
+		return
+	}
+	if group, ok := approvalCaches[approval.Action]; ok && approval.Status == approvaldomain.StatusExecuted {
+		s.cache.invalidate(group)
+	}
+	writeJSON(w, http.StatusOK, approval)
+}
+
+func writeApprovalError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, approvaldomain.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, approvaldomain.ErrNotPending):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, approvaldomain.ErrSelfApproval):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, approvalusecase.ErrInvalidStatus):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
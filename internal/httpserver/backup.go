@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	backupusecase "backoffice/backend/internal/usecase/backup"
+)
+
+// SetBackupService enables GET /admin/backup and POST /admin/restore;
+// without it both answer 404.
+func (s *Server) SetBackupService(backup *backupusecase.Service) {
+	s.backupService = backup
+}
+
+// handleBackup serves GET /admin/backup, a JSON archive of the backoffice
+// data for cloning an environment.
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.backupService == nil {
+		writeError(w, http.StatusNotFound, "backups are not configured")
+		return
+	}
+	archive, err := s.backupService.Export(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	filename := "backoffice-backup-" + archive.CreatedAt.Format("20060102-150405") + ".json"
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	writeJSON(w, http.StatusOK, archive)
+}
+
+// handleRestore serves POST /admin/restore?dryRun=true|false, loading an
+// archive from GET /admin/backup into this environment.
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if s.backupService == nil {
+		writeError(w, http.StatusNotFound, "backups are not configured")
+		return
+	}
+	var input backupusecase.RestoreInput
+	if raw := r.URL.Query().Get("dryRun"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "dryRun must be true or false")
+			return
+		}
+		input.DryRun = dryRun
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	report, err := s.backupService.Restore(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, backupusecase.ErrInvalidArchive) {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if !report.DryRun {
+		s.cache.invalidate("/products")
+		s.cache.invalidate("/categories")
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,22 @@
+package httpserver
+
+import "net/http"
+
+// handleProductLookup serves GET /products/lookup?barcode=..., which returns
+// the product carrying the scanned barcode.
+func (s *Server) handleProductLookup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	item, err := s.productService.Lookup(r.Context(), r.URL.Query().Get("barcode"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if item, err = s.translateProduct(r, item); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, masked(r.Context(), item))
+}
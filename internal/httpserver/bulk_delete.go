@@ -0,0 +1,66 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	approvaldomain "backoffice/backend/internal/domain/approval"
+	"backoffice/backend/internal/domain/trash"
+	approvalusecase "backoffice/backend/internal/usecase/approval"
+)
+
+// handleBulkDeleteProducts serves POST /products/bulk-delete, moving up to
+// trash.MaxBulkDelete products to the trash together.
+func (s *Server) handleBulkDeleteProducts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var input trash.BulkDelete
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	report, err := s.productService.BulkDelete(r.Context(), input)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleBulkDeleteUsers serves POST /admin/users/bulk-delete. When deleting
+// users needs approval, the whole list waits for one.
+func (s *Server) handleBulkDeleteUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var input trash.BulkDelete
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if s.requiresApproval(approvaldomain.ActionDeleteUser) {
+		ids, err := input.Normalize()
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		s.requestApproval(w, r, approvalusecase.RequestInput{
+			Action:  approvaldomain.ActionDeleteUser,
+			Summary: fmt.Sprintf("Delete %d users", len(ids)),
+			Payload: trash.BulkDelete{IDs: ids},
+		})
+		return
+	}
+
+	report, err := s.userService.BulkDelete(r.Context(), input)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,203 @@
+package httpserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	maxCacheEntries   = 1000
+	maxCachedBodySize = 1 << 20
+)
+
+// responseCache keeps recent GET responses in memory for routes with a
+// configured TTL. Entries belong to a route group (the path prefix they were
+// registered under) and a successful write to the group drops all of them.
+type responseCache struct {
+	mu      sync.RWMutex
+	ttls    map[string]time.Duration
+	entries map[string]*cachedResponse
+	nowFunc func() time.Time
+}
+
+type cachedResponse struct {
+	group   string
+	status  int
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+func newResponseCache(ttls map[string]time.Duration) *responseCache {
+	return &responseCache{
+		ttls:    ttls,
+		entries: make(map[string]*cachedResponse),
+		nowFunc: time.Now,
+	}
+}
+
+// invalidate drops every cached response belonging to group.
+func (c *responseCache) invalidate(group string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.group == group {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || c.nowFunc().After(entry.expires) {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *responseCache) put(key string, entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		now := c.nowFunc()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = make(map[string]*cachedResponse)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// middleware serves GET requests for group from the cache when a TTL is
+// configured and invalidates the group after successful writes. It must run
+// after authentication so cached bodies are never served to anonymous callers.
+func (c *responseCache) middleware(group string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Cache-Control", "no-store")
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status < http.StatusBadRequest {
+				c.invalidate(group)
+			}
+			return
+		}
+
+		ttl := c.ttls[group]
+		// A saved view belongs to one user and changes without a write to
+		// the group, so lists filtered by one are never cached.
+		if ttl <= 0 || r.URL.Query().Has("view") {
+			w.Header().Set("Cache-Control", "private, no-cache")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := c.key(r)
+		if entry, ok := c.get(key); ok {
+			writeCached(w, r, entry, c.nowFunc())
+			return
+		}
+
+		buf := &bufferedResponse{header: http.Header{}}
+		next.ServeHTTP(buf, r)
+		entry := &cachedResponse{
+			group:   group,
+			status:  buf.statusCode(),
+			header:  buf.header,
+			body:    buf.body.Bytes(),
+			expires: c.nowFunc().Add(ttl),
+		}
+		if entry.status == http.StatusOK && len(entry.body) <= maxCachedBodySize {
+			sum := sha256.Sum256(entry.body)
+			entry.etag = `"` + hex.EncodeToString(sum[:8]) + `"`
+			c.put(key, entry)
+		}
+		writeCached(w, r, entry, c.nowFunc())
+	})
+}
+
+// key scopes entries by role because response shaping may differ per role.
+func (c *responseCache) key(r *http.Request) string {
+	role := ""
+	if user, ok := currentUserFromContext(r.Context()); ok {
+		role = string(user.Role)
+	}
+	return role + "|" + r.URL.RequestURI()
+}
+
+func writeCached(w http.ResponseWriter, r *http.Request, entry *cachedResponse, now time.Time) {
+	for key, values := range entry.header {
+		w.Header()[key] = values
+	}
+	if entry.etag == "" {
+		w.WriteHeader(entry.status)
+		_, _ = w.Write(entry.body)
+		return
+	}
+
+	maxAge := int(entry.expires.Sub(now).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	w.Header().Set("ETag", entry.etag)
+	if match := r.Header.Get("If-None-Match"); match != "" && match == entry.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(entry.status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(entry.body)
+	}
+}
+
+// bufferedResponse captures a handler's output so it can be cached.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) statusCode() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
+
+// withNoStore marks responses (e.g. freshly issued tokens) as uncacheable.
+func withNoStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
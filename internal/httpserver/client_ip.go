@@ -0,0 +1,169 @@
+package httpserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+
+	"backoffice/backend/internal/config"
+)
+
+type ctxKeyClientIP struct{}
+
+// proxyTrust decides which peers may report the client's address, and in
+// which forwarding header (one of the config.ProxyHeader names).
+type proxyTrust struct {
+	prefixes []netip.Prefix
+	unix     bool
+	header   string
+}
+
+func (p *proxyTrust) trusts(addr netip.Addr) bool {
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the client's address for r. Requests from untrusted peers
+// are attributed to the peer whatever their headers say. From a trusted
+// peer only the configured header is read; the others may have come from
+// the client. A chain (Forwarded or X-Forwarded-For) is walked from the
+// nearest hop back, skipping trusted proxies, so a client cannot pass
+// itself off as another address by sending the header itself. X-Real-IP
+// holds a single address, the last one set winning.
+func (p *proxyTrust) resolve(r *http.Request) string {
+	peer, ok := peerAddr(r.RemoteAddr)
+	switch {
+	case ok && !p.trusts(peer):
+		return peer.String()
+	case !ok && !(p.unix && isUnixPeer(r.RemoteAddr)):
+		return remoteHost(r.RemoteAddr)
+	}
+
+	client := remoteHost(r.RemoteAddr)
+	var hops []string
+	switch p.header {
+	case config.ProxyHeaderForwarded:
+		hops = forwardedFor(r.Header.Values("Forwarded"))
+	case config.ProxyHeaderXRealIP:
+		if values := r.Header.Values("X-Real-IP"); len(values) > 0 {
+			if addr, ok := parseHop(values[len(values)-1]); ok {
+				return addr.String()
+			}
+		}
+		return client
+	default:
+		hops = splitHops(r.Header.Values("X-Forwarded-For"))
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			// An obfuscated or malformed hop ends the chain we can
+			// follow; the proxy that reported it is the best we know.
+			break
+		}
+		client = addr.String()
+		if !p.trusts(addr) {
+			break
+		}
+	}
+	return client
+}
+
+// withClientIP resolves the client's address once per request and stores
+// it in the context for rate limiting, logging and audit records.
+func withClientIP(next http.Handler, current *atomic.Pointer[proxyTrust]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := current.Load().resolve(r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKeyClientIP{}, ip)))
+	})
+}
+
+// clientIP returns the client's address resolved by withClientIP, or the
+// directly connected peer on listeners without it.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ctxKeyClientIP{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r.RemoteAddr)
+}
+
+// remoteHost strips the port from a RemoteAddr.
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+func peerAddr(remoteAddr string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(remoteHost(remoteAddr))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// isUnixPeer reports whether remoteAddr is that of a Unix socket
+// connection, which net/http reports as "@" or not at all.
+func isUnixPeer(remoteAddr string) bool {
+	return remoteAddr == "" || remoteAddr == "@" || strings.HasPrefix(remoteAddr, "/")
+}
+
+// splitHops flattens comma-separated X-Forwarded-For header lines.
+func splitHops(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// forwardedFor returns the for= parameter of each element of RFC 7239
+// Forwarded header lines, e.g. `for=192.0.2.60;proto=https,
+// for="[2001:db8::17]:4711"`. Elements without one are kept as empty hops
+// so the chain stays in step with the proxies that added them.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			if strings.TrimSpace(element) == "" {
+				continue
+			}
+			hop := ""
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					hop = strings.Trim(val, `"`)
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// parseHop reads an address as it appears in forwarding headers: a bare
+// IP, or an IP with a port ("192.0.2.1:8080", "[2001:db8::1]:443").
+func parseHop(hop string) (netip.Addr, bool) {
+	hop = strings.TrimSpace(hop)
+	if addrPort, err := netip.ParseAddrPort(hop); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
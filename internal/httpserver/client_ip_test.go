@@ -0,0 +1,161 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"backoffice/backend/internal/config"
+)
+
+func TestProxyTrustResolve(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+	for _, tc := range []struct {
+		name    string
+		header  string
+		remote  string
+		headers http.Header
+		want    string
+	}{
+		{
+			name:    "untrusted peer ignores every header",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "203.0.113.9:4000",
+			headers: http.Header{"X-Forwarded-For": {"1.2.3.4"}, "Forwarded": {"for=1.2.3.4"}, "X-Real-Ip": {"1.2.3.4"}},
+			want:    "203.0.113.9",
+		},
+		{
+			name:   "trusted peer without headers",
+			header: config.ProxyHeaderXForwardedFor,
+			remote: "10.0.0.1:4000",
+			want:   "10.0.0.1",
+		},
+		{
+			name:    "x-forwarded-for single hop",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "x-forwarded-for ignores a spoofed Forwarded",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Forwarded-For": {"198.51.100.7"}, "Forwarded": {"for=1.2.3.4"}},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "x-forwarded-for ignores a spoofed X-Real-IP",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Real-Ip": {"1.2.3.4"}},
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "x-forwarded-for spoofed first hop",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Forwarded-For": {"1.2.3.4, 198.51.100.7"}},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "x-forwarded-for multi-hop chain past trusted proxies",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Forwarded-For": {"1.2.3.4, 198.51.100.7", "10.1.1.1, fd00::2"}},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "x-forwarded-for chain of trusted proxies only",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Forwarded-For": {"10.2.2.2, 10.1.1.1"}},
+			want:    "10.2.2.2",
+		},
+		{
+			name:    "x-forwarded-for malformed hop stops the walk",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Forwarded-For": {"198.51.100.7, garbage, 10.1.1.1"}},
+			want:    "10.1.1.1",
+		},
+		{
+			name:    "forwarded ignores a spoofed X-Forwarded-For",
+			header:  config.ProxyHeaderForwarded,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"Forwarded": {"for=198.51.100.7;proto=https"}, "X-Forwarded-For": {"1.2.3.4"}},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "forwarded multi-hop chain with ports and IPv6",
+			header:  config.ProxyHeaderForwarded,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"Forwarded": {`for=1.2.3.4, for="[2001:db8::17]:4711"`, `for=10.1.1.1;by=10.0.0.1`}},
+			want:    "2001:db8::17",
+		},
+		{
+			name:    "forwarded obfuscated hop stops the walk",
+			header:  config.ProxyHeaderForwarded,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"Forwarded": {"for=198.51.100.7, for=_hidden, for=10.1.1.1"}},
+			want:    "10.1.1.1",
+		},
+		{
+			name:    "x-real-ip ignores spoofed chain headers",
+			header:  config.ProxyHeaderXRealIP,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Real-Ip": {"198.51.100.7"}, "X-Forwarded-For": {"1.2.3.4"}, "Forwarded": {"for=1.2.3.4"}},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "x-real-ip set by the proxy after the client wins",
+			header:  config.ProxyHeaderXRealIP,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Real-Ip": {"1.2.3.4", "198.51.100.7"}},
+			want:    "198.51.100.7",
+		},
+		{
+			name:    "x-real-ip missing",
+			header:  config.ProxyHeaderXRealIP,
+			remote:  "10.0.0.1:4000",
+			headers: http.Header{"X-Forwarded-For": {"1.2.3.4"}},
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "ipv4-mapped peer is trusted",
+			header:  config.ProxyHeaderXForwardedFor,
+			remote:  "[::ffff:10.0.0.1]:4000",
+			headers: http.Header{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "198.51.100.7",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &proxyTrust{prefixes: trusted, header: tc.header}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remote
+			r.Header = tc.headers
+			if r.Header == nil {
+				r.Header = http.Header{}
+			}
+			if got := p.resolve(r); got != tc.want {
+				t.Fatalf("resolve = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProxyTrustResolveUnix(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "@"
+	r.Header.Set("X-Forwarded-For", "198.51.100.7")
+
+	untrusted := &proxyTrust{header: config.ProxyHeaderXForwardedFor}
+	if got := untrusted.resolve(r); got != "@" {
+		t.Fatalf("untrusted unix peer: resolve = %q, want %q", got, "@")
+	}
+	trusted := &proxyTrust{unix: true, header: config.ProxyHeaderXForwardedFor}
+	if got := trusted.resolve(r); got != "198.51.100.7" {
+		t.Fatalf("trusted unix peer: resolve = %q, want %q", got, "198.51.100.7")
+	}
+}
@@ -0,0 +1,159 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	consentdomain "backoffice/backend/internal/domain/consent"
+	consentusecase "backoffice/backend/internal/usecase/consent"
+)
+
+// SetConsentService enables policy versions and consent tracking: the
+// consent routes, acceptance at registration and login, and blocking users
+// until they accept the newest mandatory versions. Without it the consent
+// routes answer 404 and nothing is blocked.
+func (s *Server) SetConsentService(consents *consentusecase.Service) {
+	s.consentService = consents
+}
+
+// requireConsent answers 403 with the pending versions to users who have
+// not accepted the newest mandatory terms, except on routes they need to
+// accept them or to leave.
+func (s *Server) requireConsent(rt route, next http.Handler) http.Handler {
+	if rt.beforeConsent {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := currentUserFromContext(r.Context())
+		if s.consentService == nil || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		pending, err := s.consentService.Pending(r.Context(), user.ID)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if len(pending) > 0 {
+			writeServiceError(w, r, consentusecase.ErrConsentRequired.With("pending", pending))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// consentStatus is the body of /users/me/consents and
+// /admin/users/{id}/consents.
+type consentStatus struct {
+	Pending  []*consentdomain.Version `json:"pending"`
+	Consents []*consentdomain.Consent `json:"consents"`
+}
+
+func (s *Server) consentStatus(r *http.Request, userID string) (*consentStatus, error) {
+	pending, err := s.consentService.Pending(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	consents, err := s.consentService.Consents(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	return &consentStatus{Pending: pending, Consents: consents}, nil
+}
+
+// handleMyConsents serves GET /users/me/consents, the caller's pending
+// versions and consents, and POST with {"accept":[{"document","version"}]}
+// to accept versions.
+func (s *Server) handleMyConsents(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if s.consentService == nil {
+		writeError(w, http.StatusNotFound, "consent tracking is not configured")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var payload struct {
+			Accept []consentdomain.Ref `json:"accept"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		if err := s.consentService.Accept(r.Context(), user.ID, payload.Accept); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+		return
+	}
+	status, err := s.consentStatus(r, user.ID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleAdminUserConsents serves GET /admin/users/{id}/consents.
+func (s *Server) handleAdminUserConsents(w http.ResponseWriter, r *http.Request, userID string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.consentService == nil {
+		writeError(w, http.StatusNotFound, "consent tracking is not configured")
+		return
+	}
+	if _, err := s.userService.Get(r.Context(), userID); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	status, err := s.consentStatus(r, userID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handlePolicies serves GET /admin/policies, every published version
+// newest first, and POST to publish one.
+func (s *Server) handlePolicies(w http.ResponseWriter, r *http.Request) {
+	if s.consentService == nil {
+		writeError(w, http.StatusNotFound, "consent tracking is not configured")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		versions, err := s.consentService.Versions(r.Context())
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": versions})
+	case http.MethodPost:
+		var input consentusecase.PublishInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		var publishedBy string
+		if user, ok := currentUserFromContext(r.Context()); ok {
+			publishedBy = user.ID
+		}
+		version, err := s.consentService.Publish(r.Context(), publishedBy, input)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, version)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
@@ -0,0 +1,478 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"backoffice/backend/internal/config"
+	authdomain "backoffice/backend/internal/domain/auth"
+	notificationdomain "backoffice/backend/internal/domain/notification"
+	retentiondomain "backoffice/backend/internal/domain/retention"
+	searchdomain "backoffice/backend/internal/domain/search"
+	shipmentdomain "backoffice/backend/internal/domain/shipment"
+	trashdomain "backoffice/backend/internal/domain/trash"
+	webhookdomain "backoffice/backend/internal/domain/webhook"
+	"backoffice/backend/internal/infrastructure/carrier"
+	"backoffice/backend/internal/infrastructure/memory"
+	"backoffice/backend/internal/infrastructure/payment"
+	"backoffice/backend/internal/infrastructure/pdf"
+	"backoffice/backend/internal/infrastructure/token"
+	activityusecase "backoffice/backend/internal/usecase/activity"
+	approvalusecase "backoffice/backend/internal/usecase/approval"
+	authusecase "backoffice/backend/internal/usecase/auth"
+	backupusecase "backoffice/backend/internal/usecase/backup"
+	categoryusecase "backoffice/backend/internal/usecase/category"
+	consentusecase "backoffice/backend/internal/usecase/consent"
+	customerusecase "backoffice/backend/internal/usecase/customer"
+	integrationusecase "backoffice/backend/internal/usecase/integration"
+	invoiceusecase "backoffice/backend/internal/usecase/invoice"
+	notificationusecase "backoffice/backend/internal/usecase/notification"
+	orderusecase "backoffice/backend/internal/usecase/order"
+	paymentusecase "backoffice/backend/internal/usecase/payment"
+	privacyusecase "backoffice/backend/internal/usecase/privacy"
+	productusecase "backoffice/backend/internal/usecase/product"
+	promotionusecase "backoffice/backend/internal/usecase/promotion"
+	quotausecase "backoffice/backend/internal/usecase/quota"
+	retentionusecase "backoffice/backend/internal/usecase/retention"
+	returnsusecase "backoffice/backend/internal/usecase/returns"
+	searchusecase "backoffice/backend/internal/usecase/search"
+	shipmentusecase "backoffice/backend/internal/usecase/shipment"
+	stocksyncusecase "backoffice/backend/internal/usecase/stocksync"
+	trashusecase "backoffice/backend/internal/usecase/trash"
+	userusecase "backoffice/backend/internal/usecase/user"
+	viewusecase "backoffice/backend/internal/usecase/view"
+	watchusecase "backoffice/backend/internal/usecase/watch"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
+)
+
+// contractSkips lists the documented operations the contract walk cannot
+// exercise with a single request and response.
+var contractSkips = map[string]string{
+	"GET /events":           "server-sent event stream",
+	"POST /products/stream": "NDJSON stream",
+}
+
+// contractCollections are created first, in this order, so the records
+// other requests refer to exist.
+var contractCollections = []string{"/admin/users", "/categories", "/products", "/customers", "/customers/{id}/addresses", "/orders", "/promotions"}
+
+// contractReferences maps request properties that name another record to
+// the collection the walk created it in.
+var contractReferences = map[string]string{
+	"productId":  "/products",
+	"customerId": "/customers",
+	"categoryId": "/categories",
+	"orderId":    "/orders",
+	"addressId":  "/customers/{id}/addresses",
+	"userId":     "/admin/users",
+}
+
+// contractValues fills request properties whose values the handlers check
+// beyond what the document can express.
+var contractValues = map[string]any{
+	"role":       authdomain.RoleAdmin,
+	"resource":   "products",
+	"country":    "LA",
+	"postalCode": "01000",
+	"quantity":   1,
+	"barcode":    "4006381333931",
+	"event":      webhookdomain.AllEvents,
+	"filter":     map[string]any{"skuPrefix": "contract"},
+	"url":        "https://example.com/contract",
+	"webhookUrl": "https://example.com/contract",
+}
+
+// contractParameters fills path parameters that are not record IDs.
+var contractParameters = map[string]string{
+	"locale":   "lo",
+	"kind":     trashdomain.KindProduct,
+	"target":   retentiondomain.TargetActivity,
+	"provider": "mock",
+	"carrier":  "mock",
+}
+
+// missingID stands in for records the walk could not create.
+const missingID = "00000000-0000-4000-8000-000000000000"
+
+// TestContract walks every operation in the OpenAPI document and calls it as
+// an admin against a server over in-memory repositories, failing when the
+// status is not documented for the operation or the body does not match the
+// documented schema. Collections are created before their records are read
+// and everything is deleted last, so most calls reach the handler's happy
+// path; documented error responses are checked the same way.
+func TestContract(t *testing.T) {
+	srv, adminToken := newMemoryServer(t)
+	spec, err := loadAPISpec(srv.openAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	c := &contractWalk{spec: spec, baseURL: ts.URL, token: adminToken, fixtures: map[string]string{}}
+	calls := c.operations()
+	succeeded := 0
+	for _, call := range calls {
+		name := call.method + " " + call.route.template
+		if reason, ok := contractSkips[name]; ok {
+			t.Logf("%s: skipped, %s", name, reason)
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			if c.call(t, call) {
+				succeeded++
+			}
+		})
+	}
+	t.Logf("%d of %d operations answered 2xx", succeeded, len(calls)-len(contractSkips))
+}
+
+// The first account of a server built by newMemoryServer, an admin.
+const (
+	memoryAdminEmail    = "admin@example.com"
+	memoryAdminPassword = "contract-password"
+)
+
+// newMemoryServer wires every service that has an in-memory repository and
+// returns the server with a token for its first admin.
+func newMemoryServer(t testing.TB) (*Server, string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "contract-test-secret-0123456789abcdef")
+	t.Setenv("DATABASE_URL", "postgres://contract@localhost/contract")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := memory.NewUserRepository()
+	products := memory.NewProductRepository()
+	categories := memory.NewCategoryRepository(products)
+	webhooks := memory.NewWebhookRepository()
+	activity := memory.NewActivityRepository()
+
+	authService := authusecase.NewService(users, token.NewJWTManager(cfg.JWTSecret, time.Hour, "", ""))
+	userService := userusecase.NewService(users)
+	productService := productusecase.NewService(products)
+	productService.SetReservations(products, cfg.Reservations.TTL, cfg.Reservations.MaxTTL)
+	productService.SetTranslations(products, cfg.ProductLocale)
+	categoryService := categoryusecase.NewService(categories)
+	trashService := trashusecase.NewService(map[string]trashdomain.Bin{
+		trashdomain.KindUser:    users,
+		trashdomain.KindProduct: products,
+	})
+	customerService := customerusecase.NewService(memory.NewCustomerRepository())
+	orderService := orderusecase.NewService(memory.NewOrderRepository(products), productService)
+	orderService.SetCustomers(customerService)
+	invoiceService, err := invoiceusecase.NewService(orderService, customerService, memory.NewAttachmentStore(), pdf.Text, invoiceusecase.Company{Name: "Contract"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := NewServer(cfg, authService, userService, productService, categoryService, webhookusecase.NewService(webhooks), trashService)
+	srv.SetSearchService(searchusecase.NewService(map[string]searchdomain.Source{
+		searchdomain.GroupProducts:   products,
+		searchdomain.GroupUsers:      users,
+		searchdomain.GroupCategories: categories,
+	}))
+	activityService := activityusecase.NewService(activity, users)
+	srv.SetActivityService(activityService)
+	srv.SetBackupService(backupusecase.NewService(users, categories, products, webhooks))
+	srv.SetRetentionService(retentionusecase.NewService(memory.NewRetentionRepository(), map[string]retentiondomain.Purger{
+		retentiondomain.TargetActivity: activity,
+		retentiondomain.TargetTrash:    trashService,
+	}, map[string]int{}))
+	srv.SetApprovalService(approvalusecase.NewService(memory.NewApprovalRepository(), nil, time.Hour))
+	srv.SetCustomerService(customerService)
+	srv.SetOrderService(orderService)
+	srv.SetInvoiceService(invoiceService)
+	srv.SetReturnService(returnsusecase.NewService(memory.NewReturnRepository(), orderService, productService))
+	srv.SetPaymentService(paymentusecase.NewService(memory.NewPaymentRepository(), orderService, "USD", payment.NewMock("contract")))
+	srv.SetShipmentService(shipmentusecase.NewService(memory.NewShipmentRepository(), orderService, customerService, shipmentdomain.Address{Name: "Contract", Country: "LA"}, 500, carrier.NewMock("contract", time.Minute)))
+	srv.SetPromotionService(promotionusecase.NewService(memory.NewPromotionRepository(), orderService, productService, categoryService))
+	viewService := viewusecase.NewService(memory.NewViewRepository())
+	srv.SetViewService(viewService)
+	watchService := watchusecase.NewService(memory.NewWatchRepository(), products)
+	srv.SetWatchService(watchService)
+	srv.SetSyncService(stocksyncusecase.NewService(memory.NewSyncRunRepository(), productService, nil, time.Minute))
+	srv.SetNotificationService(notificationusecase.NewService(memory.NewNotificationChannelRepository(), discardSender{}))
+	srv.SetIntegrationService(integrationusecase.NewService(memory.NewIntegrationRepository()))
+	srv.SetQuotaService(quotausecase.NewService(memory.NewQuotaRepository()))
+	consentService := consentusecase.NewService(memory.NewConsentRepository())
+	srv.SetConsentService(consentService)
+	srv.SetPrivacyService(privacyusecase.NewService(userService, authService, activityService, viewService, watchService, consentService))
+
+	ctx := context.Background()
+	if _, err := authService.Register(ctx, memoryAdminEmail, memoryAdminPassword, "Admin"); err != nil {
+		t.Fatal(err)
+	}
+	adminToken, _, err := authService.Login(ctx, authdomain.Credentials{Email: memoryAdminEmail, Password: memoryAdminPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, adminToken
+}
+
+// discardSender accepts channel messages without posting them anywhere.
+type discardSender struct{}
+
+func (discardSender) Send(context.Context, *notificationdomain.Channel, notificationdomain.Message) error {
+	return nil
+}
+
+// contractWalk calls documented operations, remembering the IDs of created
+// records by the template of the collection they were created in.
+type contractWalk struct {
+	spec     *apiSpec
+	baseURL  string
+	token    string
+	fixtures map[string]string
+	serial   int
+}
+
+type contractCall struct {
+	method string
+	route  *apiRoute
+	op     *apiOperation
+}
+
+// operations orders the documented operations so collections are filled
+// before their records and sub-resources are used, and deletes run last,
+// innermost first.
+func (c *contractWalk) operations() []contractCall {
+	rank := map[string]int{http.MethodPost: 0, http.MethodGet: 1, http.MethodPut: 2, http.MethodPatch: 3}
+	first := map[string]int{}
+	for i, template := range contractCollections {
+		first[template] = len(contractCollections) - i
+	}
+	var calls, deletes []contractCall
+	for _, route := range c.spec.routes {
+		for method, op := range route.operations {
+			call := contractCall{method: method, route: route, op: op}
+			if method == http.MethodDelete {
+				deletes = append(deletes, call)
+			} else {
+				calls = append(calls, call)
+			}
+		}
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		a, b := calls[i], calls[j]
+		if first[a.route.template] != first[b.route.template] {
+			return first[a.route.template] > first[b.route.template]
+		}
+		if len(a.route.segments) != len(b.route.segments) {
+			return len(a.route.segments) < len(b.route.segments)
+		}
+		if a.route.template != b.route.template {
+			return a.route.template < b.route.template
+		}
+		return rank[a.method] < rank[b.method]
+	})
+	sort.Slice(deletes, func(i, j int) bool {
+		a, b := deletes[i], deletes[j]
+		if len(a.route.segments) != len(b.route.segments) {
+			return len(a.route.segments) > len(b.route.segments)
+		}
+		return a.route.template < b.route.template
+	})
+	return append(calls, deletes...)
+}
+
+// call makes one request and checks the response against the document. It
+// reports whether the operation succeeded.
+func (c *contractWalk) call(t *testing.T, call contractCall) bool {
+	path := c.path(call.route)
+	var body []byte
+	if call.op.RequestBody != nil {
+		if content, ok := call.op.RequestBody.Content["application/json"]; ok {
+			var err error
+			if body, err = json.Marshal(c.example(content.Schema, "")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	query := url.Values{}
+	for _, param := range call.op.Parameters {
+		if param.In == "query" && param.Required {
+			query.Set(param.Name, fmt.Sprint(c.example(param.Schema, param.Name)))
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(call.method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if errs := c.spec.validateRequest(req, call.op); len(errs) > 0 {
+		t.Fatalf("generated request does not match the document: %v", errs)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode == http.StatusInternalServerError {
+		t.Fatalf("%s %s: %d %s", call.method, path, res.StatusCode, data)
+	}
+	if errs := c.spec.validateResponse(call.op, res.StatusCode, res.Header, data); len(errs) > 0 {
+		t.Fatalf("%s %s: %d response does not match the document: %v\n%s", call.method, path, res.StatusCode, errs, data)
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		t.Logf("%s %s: %d %s", call.method, path, res.StatusCode, bytes.TrimSpace(data))
+		return false
+	}
+	if call.method == http.MethodPost {
+		if id := responseID(data); id != "" {
+			c.fixtures[call.route.template] = id
+		}
+	}
+	return true
+}
+
+// path fills the route's parameters with created records, or with the
+// value the parameter's name calls for.
+func (c *contractWalk) path(route *apiRoute) string {
+	segments := make([]string, len(route.segments))
+	for i, segment := range route.segments {
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			segments[i] = segment
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+		if value, ok := contractParameters[name]; ok {
+			segments[i] = value
+		} else if id, ok := c.fixtures["/"+strings.Join(route.segments[:i], "/")]; ok {
+			segments[i] = id
+		} else {
+			segments[i] = missingID
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// example builds a small value that matches s: required properties and
+// those contractValues fills, one item per array and the lowest allowed
+// number from 1 up. Strings are unique so creates do not conflict with each
+// other.
+func (c *contractWalk) example(s *schema, name string) any {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		return c.example(c.spec.resolve(s.Ref), name)
+	}
+	if len(s.OneOf) > 0 {
+		return c.example(s.OneOf[0], name)
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+	if value, ok := contractValues[name]; ok {
+		return value
+	}
+	switch s.Type {
+	case "string":
+		return c.exampleString(s, name)
+	case "integer", "number":
+		value := 1.0
+		if s.Minimum != nil {
+			value = max(*s.Minimum, value)
+		}
+		if s.Maximum != nil {
+			value = min(value, *s.Maximum)
+		}
+		return value
+	case "boolean":
+		return true
+	case "array":
+		items := make([]any, max(1, derefInt(s.MinItems)))
+		for i := range items {
+			items[i] = c.example(s.Items, strings.TrimSuffix(name, "s"))
+		}
+		return items
+	}
+	object := map[string]any{}
+	for _, property := range s.Required {
+		object[property] = c.example(s.Properties[property], property)
+	}
+	for property := range s.Properties {
+		if value, ok := contractValues[property]; ok {
+			object[property] = value
+		}
+	}
+	return object
+}
+
+func (c *contractWalk) exampleString(s *schema, name string) string {
+	c.serial++
+	switch s.Format {
+	case "email":
+		return fmt.Sprintf("contract%d@example.com", c.serial)
+	case "date-time":
+		return time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	case "date":
+		return time.Now().Add(24 * time.Hour).UTC().Format(time.DateOnly)
+	}
+	if collection, ok := contractReferences[name]; ok {
+		if id, ok := c.fixtures[collection]; ok {
+			return id
+		}
+		return missingID
+	}
+	value := fmt.Sprintf("contract-%d", c.serial)
+	if s.MinLength != nil && len(value) < *s.MinLength {
+		value += strings.Repeat("x", *s.MinLength-len(value))
+	}
+	if s.MaxLength != nil && len(value) > *s.MaxLength {
+		value = value[len(value)-*s.MaxLength:]
+	}
+	return value
+}
+
+func derefInt(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+// responseID returns the id of the record a create answered with, found at
+// the top level or one object down (e.g. {"webhook": {"id": ...}}).
+func responseID(body []byte) string {
+	var object map[string]any
+	if json.Unmarshal(body, &object) != nil {
+		return ""
+	}
+	if id, ok := object["id"].(string); ok {
+		return id
+	}
+	for _, value := range object {
+		if nested, ok := value.(map[string]any); ok {
+			if id, ok := nested["id"].(string); ok {
+				return id
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	customerdomain "backoffice/backend/internal/domain/customer"
+	customerusecase "backoffice/backend/internal/usecase/customer"
+)
+
+// SetCustomerService enables /customers; without it the endpoints answer
+// 404.
+func (s *Server) SetCustomerService(customers *customerusecase.Service) {
+	s.customerService = customers
+}
+
+func (s *Server) handleCustomers(w http.ResponseWriter, r *http.Request) {
+	if s.customerService == nil {
+		writeError(w, http.StatusNotFound, "customers are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		customers, err := s.customerService.List(ctx)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if customers == nil {
+			customers = []*customerdomain.Customer{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": masked(r.Context(), customers)})
+	case http.MethodPost:
+		var payload customerusecase.CreateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		customer, err := s.customerService.Create(ctx, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, masked(r.Context(), customer))
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleCustomerByID serves /customers/{id}, /customers/{id}/addresses and
+// /customers/{id}/addresses/{addressId}.
+func (s *Server) handleCustomerByID(w http.ResponseWriter, r *http.Request) {
+	if s.customerService == nil {
+		writeError(w, http.StatusNotFound, "customers are not configured")
+		return
+	}
+	remainder := strings.Trim(strings.TrimPrefix(r.URL.Path, "/customers/"), "/")
+	segments := strings.Split(remainder, "/")
+	id := segments[0]
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "customer id required")
+		return
+	}
+
+	switch {
+	case len(segments) == 1:
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		customer, err := s.customerService.Get(r.Context(), id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, masked(r.Context(), customer))
+	case len(segments) == 2 && segments[1] == "addresses":
+		s.handleCustomerAddresses(w, r, id)
+	case len(segments) == 3 && segments[1] == "addresses" && segments[2] != "":
+		s.handleCustomerAddress(w, r, id, segments[2])
+	default:
+		writeError(w, http.StatusNotFound, "resource not found")
+	}
+}
+
+func (s *Server) handleCustomerAddresses(w http.ResponseWriter, r *http.Request, customerID string) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		addresses, err := s.customerService.Addresses(ctx, customerID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if addresses == nil {
+			addresses = []*customerdomain.Address{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": addresses})
+	case http.MethodPost:
+		var payload customerusecase.AddressInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		address, err := s.customerService.AddAddress(ctx, customerID, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, address)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) handleCustomerAddress(w http.ResponseWriter, r *http.Request, customerID, id string) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		address, err := s.customerService.GetAddress(ctx, customerID, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, address)
+	case http.MethodPut:
+		var payload customerusecase.AddressInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		address, err := s.customerService.UpdateAddress(ctx, customerID, id, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, address)
+	case http.MethodDelete:
+		if err := s.customerService.DeleteAddress(ctx, customerID, id); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
@@ -0,0 +1,165 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	eventBufferSize   = 16
+	eventHeartbeat    = 25 * time.Second
+	changeOpResync    = "resync"
+	changeTableConfig = "config"
+)
+
+// ChangeEvent describes a data or settings change, whether made by this
+// instance or received from another replica.
+type ChangeEvent struct {
+	Table string `json:"table"`
+	Op    string `json:"op"`
+	ID    string `json:"id,omitempty"`
+}
+
+// ChangePublisher broadcasts a change to the other instances.
+type ChangePublisher func(ctx context.Context, event ChangeEvent) error
+
+// changeCacheGroups maps a changed table to the response cache groups whose
+// entries may embed its rows.
+var changeCacheGroups = map[string][]string{
+	"products":   {"/products"},
+	"categories": {"/categories", "/products"},
+}
+
+// eventHub fans change events out to connected server-sent-event clients.
+// Slow clients that fall behind by more than eventBufferSize events miss
+// them rather than blocking the publisher.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan []byte]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[chan []byte]struct{})}
+}
+
+func (h *eventHub) subscribe() chan []byte {
+	ch := make(chan []byte, eventBufferSize)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan []byte) {
+	h.mu.Lock()
+	delete(h.subscribers, ch)
+	h.mu.Unlock()
+}
+
+func (h *eventHub) broadcast(name string, data []byte) {
+	msg := []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", name, data))
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// SetChangePublisher installs the function used to tell other instances about
+// changes that are not captured by database triggers, such as config reloads.
+func (s *Server) SetChangePublisher(publish ChangePublisher) {
+	s.publishChange = publish
+}
+
+// HandleChange reacts to a change made elsewhere: it drops affected cache
+// entries, reloads configuration when asked to and forwards the event to
+// event-stream clients.
+func (s *Server) HandleChange(event ChangeEvent) {
+	switch {
+	case event.Op == changeOpResync:
+		// Notifications may have been missed while disconnected.
+		for _, groups := range changeCacheGroups {
+			for _, group := range groups {
+				s.cache.invalidate(group)
+			}
+		}
+	case event.Table == changeTableConfig:
+		if _, err := s.ReloadConfig(); err != nil {
+			log.Printf("configuration reload requested by another instance rejected: %v", err)
+		}
+	default:
+		for _, group := range changeCacheGroups[event.Table] {
+			s.cache.invalidate(group)
+		}
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	name := event.Table
+	if name == "" {
+		name = event.Op
+	}
+	s.events.broadcast(name, data)
+}
+
+func (s *Server) announceChange(ctx context.Context, event ChangeEvent) {
+	if s.publishChange == nil {
+		return
+	}
+	if err := s.publishChange(ctx, event); err != nil {
+		log.Printf("failed to publish %s %s change: %v", event.Table, event.Op, err)
+	}
+}
+
+// handleEvents streams change events to the client as server-sent events.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	ch := s.events.subscribe()
+	defer s.events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	_ = rc.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-ch:
+			if _, err := w.Write(msg); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func FuzzExtractBearerToken(f *testing.F) {
+	for _, seed := range []string{"", "Bearer abc", "bearer  abc ", "BEARER", "Bearer ", "Basic abc", "Bearerabc", "Bearer\tabc"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, header string) {
+		token := extractBearerToken(header)
+		if token == "" {
+			return
+		}
+		if !strings.EqualFold(header[:7], "bearer ") {
+			t.Fatalf("extractBearerToken(%q) = %q without a bearer scheme", header, token)
+		}
+		if token != strings.TrimSpace(token) {
+			t.Fatalf("extractBearerToken(%q) = %q, not trimmed", header, token)
+		}
+	})
+}
+
+// fuzzTargets are the routes FuzzRequestDecoding posts bodies to; {id} is
+// replaced with an existing product.
+var fuzzTargets = []struct{ method, path string }{
+	{http.MethodPost, "/auth/login"},
+	{http.MethodPost, "/auth/renew"},
+	{http.MethodPost, "/products"},
+	{http.MethodPatch, "/products/{id}"},
+	{http.MethodPut, "/products/{id}"},
+	{http.MethodPost, "/products/{id}/stock"},
+	{http.MethodPost, "/products/{id}/reservations"},
+	{http.MethodPut, "/products/{id}/translations/lo"},
+	{http.MethodPost, "/products/bulk-price-update"},
+	{http.MethodPost, "/categories"},
+	{http.MethodPost, "/customers"},
+	{http.MethodPost, "/orders"},
+	{http.MethodPost, "/promotions"},
+	{http.MethodPost, "/promotions/validate"},
+	{http.MethodPost, "/users/change-password"},
+	{http.MethodPatch, "/users/me/preferences"},
+	{http.MethodPost, "/users/me/views"},
+	{http.MethodPost, "/admin/users"},
+	{http.MethodPost, "/admin/webhooks"},
+	{http.MethodPost, "/admin/integrations"},
+	{http.MethodPost, "/admin/notification-channels"},
+	{http.MethodPut, "/admin/retention/activity"},
+	{http.MethodPost, "/admin/restore"},
+}
+
+// FuzzRequestDecoding posts arbitrary bodies to the routes that decode JSON
+// and fails when one answers 500 or a client error without an error code
+// clients can act on.
+func FuzzRequestDecoding(f *testing.F) {
+	srv, token := newMemoryServer(f)
+	handler := srv.httpServer.Handler
+	productID := fuzzCreateProduct(f, handler, token)
+
+	for i := range fuzzTargets {
+		for _, body := range []string{
+			"",
+			"{}",
+			"null",
+			"[]",
+			`{"name":"x","sku":"y","price":1,"quantity":1}`,
+			`{"name":1}`,
+			`{"price":"1"}`,
+			`{"quantity":1e309}`,
+			`{"delta":-9223372036854775808}`,
+			`{"lines":[{"productId":"x","quantity":0}]}`,
+			`{"filter":{},"operations":[{"type":"percent","value":-100}]}`,
+			`{"email":"a@b.c","password":"` + strings.Repeat("p", 100) + `"}`,
+			`{"`,
+		} {
+			f.Add(uint8(i), []byte(body))
+		}
+	}
+	f.Fuzz(func(t *testing.T, target uint8, body []byte) {
+		route := fuzzTargets[int(target)%len(fuzzTargets)]
+		path := strings.ReplaceAll(route.path, "{id}", productID)
+		req := httptest.NewRequest(route.method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code >= http.StatusInternalServerError && rec.Code != http.StatusNotImplemented {
+			t.Fatalf("%s %s with %q: %d %s", route.method, path, body, rec.Code, rec.Body)
+		}
+		if rec.Code < http.StatusBadRequest || rec.Code == http.StatusTooManyRequests {
+			return
+		}
+		var problem struct {
+			Code string `json:"code"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || problem.Code == "" {
+			t.Fatalf("%s %s with %q: %d without an error code: %s", route.method, path, body, rec.Code, rec.Body)
+		}
+	})
+}
+
+func fuzzCreateProduct(f *testing.F, handler http.Handler, token string) string {
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Fuzz","sku":"FUZZ-1","price":1,"quantity":100}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var product struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &product); err != nil || product.ID == "" {
+		f.Fatalf("creating the product: %d %s", rec.Code, rec.Body)
+	}
+	return product.ID
+}
@@ -4,33 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
+	approvaldomain "backoffice/backend/internal/domain/approval"
 	authdomain "backoffice/backend/internal/domain/auth"
-	productdomain "backoffice/backend/internal/domain/product"
+	consentdomain "backoffice/backend/internal/domain/consent"
+	viewdomain "backoffice/backend/internal/domain/view"
+	"backoffice/backend/internal/errreport"
+	approvalusecase "backoffice/backend/internal/usecase/approval"
 	productusecase "backoffice/backend/internal/usecase/product"
 	userusecase "backoffice/backend/internal/usecase/user"
 )
 
 func (s *Server) registerRoutes() {
-	s.router.Handle("/health", http.HandlerFunc(s.handleHealth))
-	s.router.Handle("/auth/register", http.HandlerFunc(s.handleRegister))
-	s.router.Handle("/auth/login", http.HandlerFunc(s.handleLogin))
-	s.router.Handle("/auth/renew", http.HandlerFunc(s.handleRenewToken))
-
-	authenticated := s.authMiddleware
-	s.router.Handle("/products", authenticated(http.HandlerFunc(s.handleProducts)))
-	s.router.Handle("/products/", authenticated(http.HandlerFunc(s.handleProductByID)))
-	s.router.Handle("/users/change-password", authenticated(http.HandlerFunc(s.handleChangePassword)))
-	s.router.Handle("/users/me/role", authenticated(http.HandlerFunc(s.handleUserRole)))
-	s.router.Handle("/admin/users", authenticated(http.HandlerFunc(s.handleAdminUsers)))
-	s.router.Handle("/admin/users/", authenticated(http.HandlerFunc(s.handleAdminUserByID)))
+	for _, rt := range s.routes() {
+		s.mount(rt)
+	}
 }
 
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+func (s *Server) registerAdminRoutes() {
+	s.adminRouter.Handle("/health", http.HandlerFunc(s.handleHealth))
+	s.adminRouter.Handle("/readyz", http.HandlerFunc(s.handleReady))
+	s.adminRouter.Handle("/metrics", http.HandlerFunc(s.handleMetrics))
 }
 
 func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
@@ -43,24 +42,34 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 		Email    string `json:"email"`
 		Password string `json:"password"`
 		Name     string `json:"name"`
+		// Accept lists the policy versions the user agreed to.
+		Accept []consentdomain.Ref `json:"accept"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
+	if s.consentService != nil {
+		if err := s.consentService.CheckRegistration(r.Context(), payload.Accept); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+	}
 
 	user, err := s.authService.Register(r.Context(), payload.Email, payload.Password, payload.Name)
 	if err != nil {
-		switch {
-		case errors.Is(err, authdomain.ErrEmailExists):
-			writeError(w, http.StatusConflict, err.Error())
-		default:
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeServiceError(w, r, err)
 		return
 	}
+	if s.consentService != nil {
+		// The account exists either way; consents that failed to save are
+		// asked for again on the next request.
+		if err := s.consentService.Accept(r.Context(), user.ID, payload.Accept); err != nil {
+			errreport.Error(r.Context(), fmt.Errorf("recording consents at registration: %w", err), nil)
+		}
+	}
 
-	writeJSON(w, http.StatusCreated, map[string]any{"user": user})
+	writeJSON(w, http.StatusCreated, map[string]any{"user": newUserResponse(user)})
 }
 
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
@@ -72,31 +81,54 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		ClientID string `json:"clientId"`
+		// Scope is space-separated, as in OAuth 2.0.
+		Scope string `json:"scope"`
+		// Accept lists policy versions the user agreed to while signing in.
+		Accept []consentdomain.Ref `json:"accept"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
+	if s.consentService != nil {
+		if err := s.consentService.Check(r.Context(), payload.Accept); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+	}
 
 	token, user, err := s.authService.Login(r.Context(), authdomain.Credentials{
 		Email:    payload.Email,
 		Password: payload.Password,
+		ClientID: payload.ClientID,
+		Scopes:   strings.Fields(payload.Scope),
 	})
 	if err != nil {
-		switch {
-		case errors.Is(err, authdomain.ErrInvalidCredentials):
-			writeError(w, http.StatusUnauthorized, "invalid email or password")
-		default:
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeServiceError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	response := map[string]any{
 		"token": token,
-		"user":  user,
-	})
+		"user":  newUserResponse(user),
+	}
+	if s.consentService != nil {
+		if err := s.consentService.Accept(r.Context(), user.ID, payload.Accept); err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		// Clients prompt for these before anything else: every other
+		// request is refused until they are accepted.
+		pending, err := s.consentService.Pending(r.Context(), user.ID)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		response["pendingConsents"] = pending
+	}
+	writeJSON(w, http.StatusOK, response)
 }
 
 func (s *Server) handleRenewToken(w http.ResponseWriter, r *http.Request) {
@@ -128,11 +160,7 @@ func (s *Server) handleRenewToken(w http.ResponseWriter, r *http.Request) {
 
 	newToken, err := s.authService.RenewToken(r.Context(), token)
 	if err != nil {
-		if errors.Is(err, authdomain.ErrTokenInvalid) {
-			writeError(w, http.StatusUnauthorized, err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -145,29 +173,45 @@ func (s *Server) handleProducts(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	switch r.Method {
 	case http.MethodGet:
+		shape, err := parseShape(r, s.productExpanders())
+		if err != nil {
+			writeShapeError(w, r, err)
+			return
+		}
 		items, err := s.productService.List(ctx)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeInternalError(w, r, err)
+			return
+		}
+		if items, err = s.translateProducts(r, items); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		items, ok := applyView(s, w, r, viewdomain.ResourceProducts, masked(ctx, items))
+		if !ok {
+			return
+		}
+		shaped, err := shapeEach(ctx, shape, items)
+		if err != nil {
+			writeShapeError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"items": items})
+		writeJSON(w, http.StatusOK, map[string]any{"items": shaped})
 	case http.MethodPost:
 		var payload productusecase.CreateInput
 		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 			writeError(w, http.StatusBadRequest, "invalid JSON payload")
 			return
 		}
+		if !allowCostPrice(w, r, payload.CostPrice) {
+			return
+		}
 		item, err := s.productService.Create(ctx, payload)
 		if err != nil {
-			switch {
-			case errors.Is(err, productdomain.ErrDuplicateSKU):
-				writeError(w, http.StatusConflict, err.Error())
-			default:
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusCreated, item)
+		writeJSON(w, http.StatusCreated, masked(ctx, item))
 	default:
 		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
 	}
@@ -179,47 +223,64 @@ func (s *Server) handleProductByID(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "product id required")
 		return
 	}
+	if id, rest, ok := strings.Cut(id, "/"); ok {
+		switch sub, rest, _ := strings.Cut(rest, "/"); {
+		case sub == "reservations":
+			s.handleProductReservations(w, r, id, rest)
+		case sub == "stock" && rest == "":
+			s.handleProductStock(w, r, id)
+		case sub == "translations":
+			s.handleProductTranslations(w, r, id, rest)
+		case sub == "watch" && rest == "":
+			s.handleProductWatch(w, r, id)
+		default:
+			writeError(w, http.StatusNotFound, "resource not found")
+		}
+		return
+	}
 
 	ctx := r.Context()
 
 	switch r.Method {
 	case http.MethodGet:
+		shape, err := parseShape(r, s.productExpanders())
+		if err != nil {
+			writeShapeError(w, r, err)
+			return
+		}
 		item, err := s.productService.Get(ctx, id)
 		if err != nil {
-			if errors.Is(err, productdomain.ErrNotFound) {
-				writeError(w, http.StatusNotFound, err.Error())
-			} else {
-				writeError(w, http.StatusInternalServerError, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, item)
+		if item, err = s.translateProduct(r, item); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		shaped, err := shape.object(ctx, masked(ctx, item))
+		if err != nil {
+			writeShapeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, shaped)
 	case http.MethodPut, http.MethodPatch:
 		var payload productusecase.UpdateInput
 		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 			writeError(w, http.StatusBadRequest, "invalid JSON payload")
 			return
 		}
+		if !allowCostPrice(w, r, payload.CostPrice) {
+			return
+		}
 		item, err := s.productService.Update(ctx, id, payload)
 		if err != nil {
-			switch {
-			case errors.Is(err, productdomain.ErrNotFound):
-				writeError(w, http.StatusNotFound, err.Error())
-			case errors.Is(err, productdomain.ErrDuplicateSKU):
-				writeError(w, http.StatusConflict, err.Error())
-			default:
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, item)
+		writeJSON(w, http.StatusOK, masked(ctx, item))
 	case http.MethodDelete:
 		if err := s.productService.Delete(ctx, id); err != nil {
-			if errors.Is(err, productdomain.ErrNotFound) {
-				writeError(w, http.StatusNotFound, err.Error())
-			} else {
-				writeError(w, http.StatusInternalServerError, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
@@ -254,16 +315,7 @@ func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.authService.ChangePassword(r.Context(), user.ID, payload.CurrentPassword, payload.NewPassword); err != nil {
-		switch {
-		case errors.Is(err, authdomain.ErrPasswordMismatch):
-			writeError(w, http.StatusBadRequest, "current password is incorrect")
-		case errors.Is(err, authdomain.ErrPasswordUnchanged):
-			writeError(w, http.StatusBadRequest, err.Error())
-		case errors.Is(err, authdomain.ErrUserNotFound):
-			writeError(w, http.StatusNotFound, err.Error())
-		default:
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -279,8 +331,18 @@ func (s *Server) handleUserRole(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
+		shape, err := parseShape(r, s.userExpanders())
+		if err != nil {
+			writeShapeError(w, r, err)
+			return
+		}
+		shaped, err := shape.object(r.Context(), newUserResponse(user))
+		if err != nil {
+			writeShapeError(w, r, err)
+			return
+		}
 		writeJSON(w, http.StatusOK, map[string]any{
-			"user": user,
+			"user": shaped,
 		})
 	case http.MethodPut, http.MethodPatch:
 		var payload struct {
@@ -311,19 +373,12 @@ func (s *Server) handleUserRole(w http.ResponseWriter, r *http.Request) {
 			Role: &role,
 		})
 		if err != nil {
-			switch {
-			case errors.Is(err, authdomain.ErrInvalidRole):
-				writeError(w, http.StatusBadRequest, err.Error())
-			case errors.Is(err, authdomain.ErrUserNotFound):
-				writeError(w, http.StatusNotFound, err.Error())
-			default:
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
 
 		writeJSON(w, http.StatusOK, map[string]any{
-			"user": user,
+			"user": newUserResponse(user),
 		})
 	default:
 		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch)
@@ -333,7 +388,9 @@ func (s *Server) handleUserRole(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		if !s.requireAdmin(w, r) {
+		shape, err := parseShape(r, s.userExpanders())
+		if err != nil {
+			writeShapeError(w, r, err)
 			return
 		}
 		filter := userusecase.Filter{
@@ -341,18 +398,20 @@ func (s *Server) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
 		}
 		users, err := s.userService.List(r.Context(), filter)
 		if err != nil {
-			if errors.Is(err, authdomain.ErrInvalidRole) {
-				writeError(w, http.StatusBadRequest, err.Error())
-			} else {
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"users": users})
-	case http.MethodPost:
-		if !s.requireAdmin(w, r) {
+		responses, ok := applyView(s, w, r, viewdomain.ResourceUsers, newUserResponses(users))
+		if !ok {
 			return
 		}
+		shaped, err := shapeEach(r.Context(), shape, responses)
+		if err != nil {
+			writeShapeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"users": shaped})
+	case http.MethodPost:
 		var payload struct {
 			Email    string `json:"email"`
 			Name     string `json:"name"`
@@ -375,17 +434,10 @@ func (s *Server) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
 			Role:     payload.Role,
 		})
 		if err != nil {
-			switch {
-			case errors.Is(err, authdomain.ErrEmailExists):
-				writeError(w, http.StatusConflict, err.Error())
-			case errors.Is(err, authdomain.ErrInvalidRole):
-				writeError(w, http.StatusBadRequest, err.Error())
-			default:
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusCreated, map[string]any{"user": user})
+		writeJSON(w, http.StatusCreated, map[string]any{"user": newUserResponse(user)})
 	default:
 		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
 	}
@@ -400,6 +452,15 @@ func (s *Server) handleAdminUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch remainder {
+	case "admin-count":
+		s.handleAdminCount(w, r)
+		return
+	case "stats":
+		s.handleUserStats(w, r)
+		return
+	}
+
 	segments := strings.Split(remainder, "/")
 	id := strings.TrimSpace(segments[0])
 	if id == "" {
@@ -411,6 +472,12 @@ func (s *Server) handleAdminUserByID(w http.ResponseWriter, r *http.Request) {
 		switch strings.TrimSpace(segments[1]) {
 		case "role":
 			s.handleAdminUserRole(w, r, id)
+		case "revoke-tokens":
+			s.handleRevokeTokens(w, r, id)
+		case "data-export":
+			s.handleDataExport(w, r, id)
+		case "consents":
+			s.handleAdminUserConsents(w, r, id)
 		default:
 			writeError(w, http.StatusNotFound, "resource not found")
 		}
@@ -419,27 +486,28 @@ func (s *Server) handleAdminUserByID(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		if !s.requireAdmin(w, r) {
+		shape, err := parseShape(r, s.userExpanders())
+		if err != nil {
+			writeShapeError(w, r, err)
 			return
 		}
 		user, err := s.userService.Get(r.Context(), id)
 		if err != nil {
-			if errors.Is(err, authdomain.ErrUserNotFound) {
-				writeError(w, http.StatusNotFound, err.Error())
-			} else {
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, user)
-	case http.MethodPut, http.MethodPatch:
-		if !s.requireAdmin(w, r) {
+		shaped, err := shape.object(r.Context(), newUserResponse(user))
+		if err != nil {
+			writeShapeError(w, r, err)
 			return
 		}
+		writeJSON(w, http.StatusOK, shaped)
+	case http.MethodPut, http.MethodPatch:
 		var payload struct {
-			Email *string `json:"email"`
-			Name  *string `json:"name"`
-			Role  *string `json:"role"`
+			Email  *string `json:"email"`
+			Name   *string `json:"name"`
+			Role   *string `json:"role"`
+			Locale *string `json:"locale"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 			if errors.Is(err, io.EOF) {
@@ -451,34 +519,42 @@ func (s *Server) handleAdminUserByID(w http.ResponseWriter, r *http.Request) {
 		}
 
 		user, err := s.userService.Update(r.Context(), id, userusecase.UpdateInput{
-			Email: payload.Email,
-			Name:  payload.Name,
-			Role:  payload.Role,
+			Email:  payload.Email,
+			Name:   payload.Name,
+			Role:   payload.Role,
+			Locale: payload.Locale,
 		})
 		if err != nil {
-			switch {
-			case errors.Is(err, authdomain.ErrUserNotFound):
-				writeError(w, http.StatusNotFound, err.Error())
-			case errors.Is(err, authdomain.ErrEmailExists):
-				writeError(w, http.StatusConflict, err.Error())
-			case errors.Is(err, authdomain.ErrInvalidRole):
-				writeError(w, http.StatusBadRequest, err.Error())
-			default:
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, user)
+		writeJSON(w, http.StatusOK, newUserResponse(user))
 	case http.MethodDelete:
-		if !s.requireAdmin(w, r) {
+		mode, err := userusecase.ParseDeleteMode(r.URL.Query().Get("mode"))
+		if err != nil {
+			writeServiceError(w, r, err)
 			return
 		}
-		if err := s.userService.Delete(r.Context(), id); err != nil {
-			if errors.Is(err, authdomain.ErrUserNotFound) {
-				writeError(w, http.StatusNotFound, err.Error())
-			} else {
-				writeError(w, http.StatusBadRequest, err.Error())
+		if s.requiresApproval(approvaldomain.ActionDeleteUser) {
+			user, err := s.userService.Get(r.Context(), id)
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			summary := "Delete user " + user.Email
+			if mode == userusecase.DeleteModeAnonymize {
+				summary = "Anonymize user " + user.Email
 			}
+			s.requestApproval(w, r, approvalusecase.RequestInput{
+				Action:  approvaldomain.ActionDeleteUser,
+				Subject: user.ID,
+				Summary: summary,
+				Payload: map[string]string{"id": user.ID, "mode": string(mode)},
+			})
+			return
+		}
+		if err := s.userService.Delete(r.Context(), id, mode); err != nil {
+			writeServiceError(w, r, err)
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
@@ -487,26 +563,75 @@ func (s *Server) handleAdminUserByID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleAdminCount reports how many admins exist, so clients can warn before
+// an action that the last-admin safeguard would reject.
+func (s *Server) handleAdminCount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	count, err := s.userService.AdminCount(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"admins":          count,
+		"canRemoveAdmins": count > 1,
+	})
+}
+
+// handleUserStats serves GET /admin/users/stats?days=30&activeDays=30.
+func (s *Server) handleUserStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	var opts userusecase.StatsOptions
+	for name, target := range map[string]*int{"days": &opts.Days, "activeDays": &opts.ActiveDays} {
+		raw := r.URL.Query().Get(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			writeError(w, http.StatusBadRequest, name+" must be a positive integer")
+			return
+		}
+		*target = value
+	}
+	stats, err := s.userService.Stats(r.Context(), opts)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleRevokeTokens serves POST /admin/users/{id}/revoke-tokens.
+func (s *Server) handleRevokeTokens(w http.ResponseWriter, r *http.Request, userID string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if err := s.userService.RevokeTokens(r.Context(), userID); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	s.authService.ForgetUser(userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (s *Server) handleAdminUserRole(w http.ResponseWriter, r *http.Request, userID string) {
 	switch r.Method {
 	case http.MethodGet:
-		if !s.requireAdmin(w, r) {
-			return
-		}
 		user, err := s.userService.Get(r.Context(), userID)
 		if err != nil {
-			if errors.Is(err, authdomain.ErrUserNotFound) {
-				writeError(w, http.StatusNotFound, err.Error())
-			} else {
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"user": user})
+		writeJSON(w, http.StatusOK, map[string]any{"user": newUserResponse(user)})
 	case http.MethodPut, http.MethodPatch:
-		if !s.requireAdmin(w, r) {
-			return
-		}
 
 		var payload struct {
 			Role string `json:"role"`
@@ -530,44 +655,32 @@ func (s *Server) handleAdminUserRole(w http.ResponseWriter, r *http.Request, use
 			Role: &role,
 		})
 		if err != nil {
-			switch {
-			case errors.Is(err, authdomain.ErrUserNotFound):
-				writeError(w, http.StatusNotFound, err.Error())
-			case errors.Is(err, authdomain.ErrInvalidRole):
-				writeError(w, http.StatusBadRequest, err.Error())
-			default:
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, user)
+		writeJSON(w, http.StatusOK, newUserResponse(user))
 	case http.MethodDelete:
-		if !s.requireAdmin(w, r) {
-			return
-		}
 
 		defaultRole := string(authdomain.RoleUser)
 		user, err := s.userService.Update(r.Context(), userID, userusecase.UpdateInput{
 			Role: &defaultRole,
 		})
 		if err != nil {
-			switch {
-			case errors.Is(err, authdomain.ErrUserNotFound):
-				writeError(w, http.StatusNotFound, err.Error())
-			default:
-				writeError(w, http.StatusBadRequest, err.Error())
-			}
+			writeServiceError(w, r, err)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, user)
+		writeJSON(w, http.StatusOK, newUserResponse(user))
 	default:
 		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
 	}
 }
 
-func (s *Server) authMiddleware(next http.Handler) http.Handler {
+// authMiddleware authenticates the bearer token and, for restricted tokens,
+// requires the "<group>:read" scope for safe methods and "<group>:write" for
+// the rest.
+func (s *Server) authMiddleware(group string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token := extractBearerToken(r.Header.Get("Authorization"))
 		if token == "" {
@@ -575,17 +688,40 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		user, err := s.authService.VerifyToken(r.Context(), token)
+		user, grant, err := s.authService.VerifyToken(r.Context(), token)
 		if err != nil {
 			writeError(w, http.StatusUnauthorized, "invalid or expired token")
 			return
 		}
+		if scope := requiredScope(group, r.Method); !grant.Allows(scope) {
+			writeError(w, http.StatusForbidden, "token lacks the "+scope+" scope")
+			return
+		}
+		if grant.ClientID != "" && !s.applyQuota(w, r, grant.ClientID) {
+			return
+		}
+
+		if info := requestInfoFromContext(r.Context()); info != nil {
+			info.userID = user.ID
+			info.locale = user.Locale
+			info.timezone = user.Timezone
+		}
+		setErrorScopeUser(r.Context(), user.ID)
 
 		ctx := context.WithValue(r.Context(), ctxKeyUser{}, user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+func requiredScope(group, method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return group + ":" + authdomain.ScopeRead
+	default:
+		return group + ":" + authdomain.ScopeWrite
+	}
+}
+
 func currentUserFromContext(ctx context.Context) (*authdomain.User, bool) {
 	user, ok := ctx.Value(ctxKeyUser{}).(*authdomain.User)
 	if !ok || user == nil {
@@ -594,19 +730,6 @@ func currentUserFromContext(ctx context.Context) (*authdomain.User, bool) {
 	return user, true
 }
 
-func (s *Server) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
-	user, ok := currentUserFromContext(r.Context())
-	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
-		return false
-	}
-	if user.Role != authdomain.RoleAdmin {
-		writeError(w, http.StatusForbidden, "admin privileges required")
-		return false
-	}
-	return true
-}
-
 type ctxKeyUser struct{}
 
 func extractBearerToken(header string) string {
@@ -0,0 +1,159 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"backoffice/backend/internal/buildinfo"
+)
+
+// readinessTimeout bounds all readiness checks together so a hung dependency
+// fails the probe instead of stalling it.
+const readinessTimeout = 2 * time.Second
+
+// degradedLatency is the dependency round trip above which health reports
+// "degraded" instead of "ok".
+const degradedLatency = 500 * time.Millisecond
+
+// ReadinessCheck reports whether a dependency the server needs is usable.
+type ReadinessCheck func(ctx context.Context) error
+
+// AddReadinessCheck registers a check consulted by /readyz. Register checks
+// before Start.
+func (s *Server) AddReadinessCheck(name string, check ReadinessCheck) {
+	s.readinessChecks = append(s.readinessChecks, namedCheck{name: name, check: check})
+}
+
+type namedCheck struct {
+	name  string
+	check ReadinessCheck
+}
+
+// MigrationStatus summarizes the database schema version.
+type MigrationStatus struct {
+	Current int64   `json:"current"`
+	Latest  int64   `json:"latest"`
+	Pending int     `json:"pending"`
+	Dirty   []int64 `json:"dirty,omitempty"`
+}
+
+// MigrationCheck reports the schema version for the detailed health report.
+type MigrationCheck func(ctx context.Context) (MigrationStatus, error)
+
+// SetMigrationCheck adds the schema version to GET /admin/health; pending or
+// dirty migrations mark the server degraded there.
+func (s *Server) SetMigrationCheck(check MigrationCheck) {
+	s.migrationCheck = check
+}
+
+type dependencyHealth struct {
+	// Status is "ok", "slow" (slower than degradedLatency) or "down".
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// checkDependencies runs the readiness checks, timing each one, and reports
+// whether any is down or slow.
+func (s *Server) checkDependencies(ctx context.Context) (map[string]dependencyHealth, bool) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	results := make(map[string]dependencyHealth, len(s.readinessChecks))
+	degraded := false
+	for _, c := range s.readinessChecks {
+		start := time.Now()
+		err := c.check(ctx)
+		elapsed := time.Since(start)
+		result := dependencyHealth{Status: "ok", LatencyMS: float64(elapsed.Microseconds()) / 1000}
+		switch {
+		case err != nil:
+			result.Status, result.Error = "down", err.Error()
+		case elapsed > degradedLatency:
+			result.Status = "slow"
+		}
+		if result.Status != "ok" {
+			degraded = true
+		}
+		results[c.name] = result
+	}
+	return results, degraded
+}
+
+func healthStatus(degraded bool) string {
+	if degraded {
+		return "degraded"
+	}
+	return "ok"
+}
+
+// handleHealth is the public liveness probe. It answers 200 whenever the
+// process is serving; "degraded" means a dependency is down or slow, which a
+// restart would not fix.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodHead)
+		return
+	}
+	_, degraded := s.checkDependencies(r.Context())
+	writeJSON(w, http.StatusOK, map[string]string{"status": healthStatus(degraded)})
+}
+
+// handleHealthDetails serves GET /admin/health: the build, uptime, each
+// dependency's latency and the migration state.
+func (s *Server) handleHealthDetails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodHead)
+		return
+	}
+	dependencies, degraded := s.checkDependencies(r.Context())
+	report := map[string]any{
+		"build":         buildinfo.Get(),
+		"startedAt":     s.startedAt.UTC(),
+		"uptimeSeconds": int64(time.Since(s.startedAt).Seconds()),
+		"draining":      s.draining.Load(),
+		"dependencies":  dependencies,
+	}
+	if s.migrationCheck != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		migrations, err := s.migrationCheck(ctx)
+		cancel()
+		if err != nil {
+			report["migrationsError"] = err.Error()
+			degraded = true
+		} else {
+			report["migrations"] = migrations
+			degraded = degraded || migrations.Pending > 0 || len(migrations.Dirty) > 0
+		}
+	}
+	report["status"] = healthStatus(degraded)
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleReady answers 200 while the server can take traffic and 503 once it
+// is draining or a dependency check fails, so load balancers and container
+// health checks stop routing to it.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodHead)
+		return
+	}
+	if s.draining.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	failures := map[string]string{}
+	for _, c := range s.readinessChecks {
+		if err := c.check(ctx); err != nil {
+			failures[c.name] = err.Error()
+		}
+	}
+	if len(failures) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "unavailable", "checks": failures})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
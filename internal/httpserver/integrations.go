@@ -0,0 +1,180 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	integrationdomain "backoffice/backend/internal/domain/integration"
+	integrationusecase "backoffice/backend/internal/usecase/integration"
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// maxIntegrationBody caps inbound integration payloads, which are read in
+// full to check their signature.
+const maxIntegrationBody = 4 << 20
+
+// integrationHandler processes a payload from an integration whose
+// signature has been verified.
+type integrationHandler func(w http.ResponseWriter, r *http.Request, integration *integrationdomain.Integration)
+
+// SetIntegrationService enables /admin/integrations and the signed inbound
+// endpoint /integrations/{id}; without it both answer 404.
+func (s *Server) SetIntegrationService(integrations *integrationusecase.Service) {
+	s.integrationService = integrations
+}
+
+func (s *Server) handleIntegrations(w http.ResponseWriter, r *http.Request) {
+	if s.integrationService == nil {
+		writeError(w, http.StatusNotFound, "integrations are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		items, err := s.integrationService.List(ctx)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if items == nil {
+			items = []*integrationdomain.Integration{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": items})
+	case http.MethodPost:
+		var payload integrationusecase.CreateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		integration, err := s.integrationService.Create(ctx, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		// The secret is only ever shown in this response.
+		writeJSON(w, http.StatusCreated, map[string]any{"integration": integration, "secret": integration.Secret})
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleIntegrationByID serves /admin/integrations/{id}.
+func (s *Server) handleIntegrationByID(w http.ResponseWriter, r *http.Request) {
+	if s.integrationService == nil {
+		writeError(w, http.StatusNotFound, "integrations are not configured")
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/integrations/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		integration, err := s.integrationService.Get(ctx, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, integration)
+	case http.MethodPut, http.MethodPatch:
+		var payload integrationusecase.UpdateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		integration, err := s.integrationService.Update(ctx, id, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if payload.RotateSecret {
+			writeJSON(w, http.StatusOK, map[string]any{"integration": integration, "secret": integration.Secret})
+			return
+		}
+		writeJSON(w, http.StatusOK, integration)
+	case http.MethodDelete:
+		if err := s.integrationService.Delete(ctx, id); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// verifyIntegration authenticates POST /integrations/{id} by the request
+// signature, then hands the payload to next. Integrations carry no bearer
+// token, so this is their only check.
+func (s *Server) verifyIntegration(next integrationHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.integrationService == nil {
+			writeError(w, http.StatusNotFound, "integrations are not configured")
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/integrations/"), "/")
+		if id == "" || strings.Contains(id, "/") {
+			writeError(w, http.StatusNotFound, "resource not found")
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIntegrationBody+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "could not read request body")
+			return
+		}
+		if len(body) > maxIntegrationBody {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+
+		integration, err := s.integrationService.Verify(r.Context(), id, r.Header.Get(integrationusecase.HeaderTimestamp), r.Header.Get(integrationusecase.HeaderSignature), body)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r, integration)
+	}
+}
+
+// handleIntegrationPayload routes a verified payload by the integration's
+// kind.
+func (s *Server) handleIntegrationPayload(w http.ResponseWriter, r *http.Request, integration *integrationdomain.Integration) {
+	switch integration.Kind {
+	case integrationusecase.KindStockFeed:
+		s.handleStockFeed(w, r, integration)
+	default:
+		writeError(w, http.StatusNotFound, "resource not found")
+	}
+}
+
+// handleStockFeed applies a supplier's {"items":[{"sku","quantity"}]} stock
+// levels.
+func (s *Server) handleStockFeed(w http.ResponseWriter, r *http.Request, _ *integrationdomain.Integration) {
+	var payload struct {
+		Items []productusecase.StockLevel `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	report, err := s.productService.ApplyStockFeed(r.Context(), payload.Items)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if report.Updated > 0 {
+		s.cache.invalidate("/products")
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,47 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+const unixPrefix = "unix:"
+
+// listen opens a listener for addr, which is either a TCP address
+// ("host:port", ":port") or a Unix domain socket ("unix:/path/to.sock").
+func listen(addr string, socketMode os.FileMode) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		return net.Listen("tcp", addr)
+	}
+
+	path := strings.TrimPrefix(addr, unixPrefix)
+	if path == "" {
+		return nil, errors.New("unix socket path is empty")
+	}
+	// A socket left behind by a crashed process would make Listen fail with
+	// "address already in use"; only remove it if it really is a socket.
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if socketMode != 0 {
+		if err := os.Chmod(path, socketMode); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+	return ln, nil
+}
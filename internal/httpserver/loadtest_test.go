@@ -0,0 +1,72 @@
+package httpserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backoffice/backend/internal/app/loadtest"
+)
+
+// TestLoadBudget runs the load test against a server over the in-memory
+// repositories, so only the handler stack is measured. By default it is a
+// short smoke run; in CI, LOADTEST_SAVE writes the report of a run on the
+// base branch and LOADTEST_BASELINE fails a later run whose p95 or p99
+// regressed by more than LOADTEST_MAX_REGRESSION (a fraction, 0.2 by
+// default). LOADTEST_ITERATIONS lengthens the run for steadier numbers.
+func TestLoadBudget(t *testing.T) {
+	iterations := 10
+	if raw := os.Getenv("LOADTEST_ITERATIONS"); raw != "" {
+		var err error
+		if iterations, err = strconv.Atoi(raw); err != nil {
+			t.Fatalf("LOADTEST_ITERATIONS: %v", err)
+		}
+	}
+	srv, _ := newMemoryServer(t)
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+
+	report, err := loadtest.Run(context.Background(), loadtest.Options{
+		BaseURL:    ts.URL,
+		Email:      memoryAdminEmail,
+		Password:   memoryAdminPassword,
+		Workers:    4,
+		Iterations: iterations,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	report.Print(&out)
+	t.Log("\n" + out.String())
+	if n := report.Errors(); n > 0 {
+		t.Fatalf("%d requests failed; first: %s", n, report.FirstError)
+	}
+
+	if path := os.Getenv("LOADTEST_SAVE"); path != "" {
+		if err := loadtest.WriteReport(path, report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := os.Getenv("LOADTEST_BASELINE")
+	if path == "" {
+		return
+	}
+	baseline, err := loadtest.ReadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget := loadtest.Budget{MaxRegression: 0.2, Slack: time.Millisecond}
+	if raw := os.Getenv("LOADTEST_MAX_REGRESSION"); raw != "" {
+		if budget.MaxRegression, err = strconv.ParseFloat(raw, 64); err != nil {
+			t.Fatalf("LOADTEST_MAX_REGRESSION: %v", err)
+		}
+	}
+	for _, r := range loadtest.Compare(report, baseline, budget) {
+		t.Errorf("over budget: %s", r)
+	}
+}
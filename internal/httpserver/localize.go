@@ -0,0 +1,117 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"backoffice/backend/internal/i18n"
+)
+
+// withLocalization translates error responses into the caller's language:
+// the authenticated user's saved locale, else the best Accept-Language
+// match, else English. Handlers keep writing English messages; this adds
+// the message's "code" so clients can also key off it. It must run inside
+// withLogging, whose requestInfo carries the user's locale.
+func withLocalization(next http.Handler, catalog *i18n.Catalog) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lw := &localizedWriter{ResponseWriter: w}
+		next.ServeHTTP(lw, r)
+		if !lw.buffering {
+			return
+		}
+		var preferred string
+		if info := requestInfoFromContext(r.Context()); info != nil {
+			preferred = info.locale
+		}
+		language := catalog.Negotiate(preferred, r.Header.Get("Accept-Language"))
+		body := localizeError(lw.body.Bytes(), catalog, language)
+
+		header := w.Header()
+		header.Del("Content-Length")
+		header.Set("Content-Language", language)
+		header.Add("Vary", "Accept-Language")
+		w.WriteHeader(lw.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// localizedWriter holds back JSON error responses so their message can be
+// translated once the handler is done; everything else passes through.
+type localizedWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (lw *localizedWriter) WriteHeader(code int) {
+	if lw.wroteHeader {
+		return
+	}
+	lw.wroteHeader = true
+	if code >= http.StatusBadRequest && isJSONError(lw.Header().Get("Content-Type")) {
+		lw.status = code
+		lw.buffering = true
+		return
+	}
+	lw.ResponseWriter.WriteHeader(code)
+}
+
+// isJSONError reports whether contentType is one error responses are
+// written with: plain JSON or a problem document.
+func isJSONError(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "application/problem+json")
+}
+
+func (lw *localizedWriter) Write(b []byte) (int, error) {
+	if !lw.wroteHeader {
+		lw.WriteHeader(http.StatusOK)
+	}
+	if lw.buffering {
+		return lw.body.Write(b)
+	}
+	return lw.ResponseWriter.Write(b)
+}
+
+func (lw *localizedWriter) Flush() {
+	if lw.buffering {
+		return
+	}
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (lw *localizedWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
+
+// localizeError rewrites the "error" message of a JSON error body, and the
+// "detail" of a problem document, and adds its "code". Bodies it does not
+// recognise are returned as they are.
+func localizeError(body []byte, catalog *i18n.Catalog, language string) []byte {
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return body
+	}
+	message, ok := payload["error"].(string)
+	if !ok {
+		return body
+	}
+	code, text := catalog.Localize(language, message)
+	payload["error"] = text
+	if _, ok := payload["detail"]; ok {
+		payload["detail"] = text
+	}
+	if _, ok := payload["code"]; !ok && code != "" {
+		payload["code"] = code
+	}
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(payload); err != nil {
+		return body
+	}
+	return out.Bytes()
+}
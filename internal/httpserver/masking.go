@@ -0,0 +1,177 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Response fields are masked for callers who are not admins by tagging
+// them on the type that is encoded:
+//
+//	CostPrice *float64 `json:"costPrice,omitempty" mask:"hide"`
+//	Email     string   `json:"email,omitempty" mask:"email"`
+//
+// hide leaves the field at its zero value, so omitempty fields disappear.
+// email keeps the first character and the domain ("n***@example.com"), and
+// phone every digit but the last four ("+*** ** **** 1234"); both apply to
+// strings only. Tags are found in nested structs, slices, maps and
+// interface values, so handlers can mask whole responses.
+var maskRules = map[string]func(string) string{
+	"hide":  nil,
+	"email": maskEmail,
+	"phone": maskPhone,
+}
+
+// masked returns v as the caller may see it: v itself for admins and for
+// types without masked fields, otherwise a copy with every tagged field
+// masked. v is never modified.
+func masked[T any](ctx context.Context, v T) T {
+	value := reflect.ValueOf(&v).Elem()
+	if isAdmin(ctx) || !needsMask(value.Type()) {
+		return v
+	}
+	return maskValue(value).Interface().(T)
+}
+
+// maskPlans caches, per type, whether values of it can hold masked fields.
+var maskPlans sync.Map
+
+func needsMask(t reflect.Type) bool {
+	if cached, ok := maskPlans.Load(t); ok {
+		return cached.(bool)
+	}
+	// Recursive types are taken as masked while they are inspected.
+	maskPlans.Store(t, true)
+	result := false
+	switch t.Kind() {
+	case reflect.Interface:
+		result = true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		result = needsMask(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if rule, ok := field.Tag.Lookup("mask"); ok {
+				checkMaskTag(t, field, rule)
+				result = true
+			} else if needsMask(field.Type) {
+				result = true
+			}
+		}
+	}
+	maskPlans.Store(t, result)
+	return result
+}
+
+// checkMaskTag panics on tags that cannot be applied, so a mistyped tag
+// fails the first request that encodes it rather than leaking the field.
+func checkMaskTag(t reflect.Type, field reflect.StructField, rule string) {
+	fn, ok := maskRules[rule]
+	switch {
+	case !ok:
+		panic(fmt.Sprintf("httpserver: %s.%s has unknown mask rule %q", t, field.Name, rule))
+	case fn != nil && field.Type.Kind() != reflect.String:
+		panic(fmt.Sprintf("httpserver: %s.%s: mask rule %q applies to strings only", t, field.Name, rule))
+	}
+}
+
+// maskValue returns a masked copy of v, sharing whatever holds no masked
+// fields.
+func maskValue(v reflect.Value) reflect.Value {
+	if !needsMask(v.Type()) {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(maskValue(v.Elem()))
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(maskValue(v.Elem()))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(maskValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(maskValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), maskValue(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			rule, tagged := field.Tag.Lookup("mask")
+			switch {
+			case !tagged:
+				out.Field(i).Set(maskValue(v.Field(i)))
+			case maskRules[rule] == nil:
+				out.Field(i).SetZero()
+			case v.Field(i).String() != "":
+				out.Field(i).SetString(maskRules[rule](v.Field(i).String()))
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// maskEmail keeps the first character of the local part and the domain.
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain
+}
+
+// maskPhone replaces every digit but the last four, keeping the number's
+// punctuation so it still reads as a phone number.
+func maskPhone(phone string) string {
+	keep := 4
+	out := []byte(phone)
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i] < '0' || out[i] > '9' {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		out[i] = '*'
+	}
+	return string(out)
+}
@@ -0,0 +1,71 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/config"
+	"backoffice/backend/internal/metrics"
+)
+
+var (
+	requestDuration = metrics.Default.NewHistogramVec("http_request_duration_seconds",
+		"Duration of HTTP requests, by route pattern, method and status class.", nil, "route", "method", "code")
+	// SLORequests counts requests under each SLO_TARGETS prefix.
+	SLORequests = metrics.Default.NewCounterVec("slo_requests_total",
+		"Requests covered by a service level objective, by objective prefix.", "slo")
+	// SLOBadRequests counts the requests in SLORequests that failed with a
+	// server error or took longer than the objective's latency.
+	SLOBadRequests = metrics.Default.NewCounterVec("slo_bad_requests_total",
+		"Requests that spent error budget, by objective prefix.", "slo")
+)
+
+// handleMetrics serves the process metrics in the Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	metrics.Default.WritePrometheus(w)
+}
+
+// withRouteMetrics records the latency of every request to pattern and
+// counts it against the longest matching SLO target, if any.
+func withRouteMetrics(pattern string, slos []config.SLOTarget, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		elapsed := time.Since(start)
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		requestDuration.Observe(elapsed.Seconds(), pattern, r.Method, strconv.Itoa(status/100)+"xx")
+
+		slo, ok := matchSLO(slos, r.URL.Path)
+		if !ok {
+			return
+		}
+		SLORequests.Inc(slo.Prefix)
+		if status >= http.StatusInternalServerError || (slo.Latency > 0 && elapsed > slo.Latency) {
+			SLOBadRequests.Inc(slo.Prefix)
+		}
+	})
+}
+
+// matchSLO returns the target with the longest prefix of path.
+func matchSLO(slos []config.SLOTarget, path string) (config.SLOTarget, bool) {
+	var best config.SLOTarget
+	found := false
+	for _, slo := range slos {
+		if strings.HasPrefix(path, slo.Prefix) && (!found || len(slo.Prefix) > len(best.Prefix)) {
+			best, found = slo, true
+		}
+	}
+	return best, found
+}
@@ -1,10 +1,19 @@
 package httpserver
 
 import (
-	"log"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"backoffice/backend/internal/config"
 )
 
 type responseRecorder struct {
@@ -27,32 +36,222 @@ func (r *responseRecorder) Write(b []byte) (int, error) {
 	return n, err
 }
 
-func withLogging(next http.Handler) http.Handler {
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// accessLogger writes one structured entry per request.
+type accessLogger struct {
+	logger      *slog.Logger
+	headers     bool
+	bodies      bool
+	sampleRates map[string]float64
+	exclude     []string
+	preflights  bool
+}
+
+func newAccessLogger(cfg config.AccessLogConfig, level slog.Leveler) *accessLogger {
+	var handler slog.Handler
+	opts := &slog.HandlerOptions{Level: level}
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	}
+	return &accessLogger{
+		logger:      slog.New(handler),
+		headers:     cfg.Headers,
+		bodies:      cfg.Bodies,
+		sampleRates: cfg.SampleRates,
+		exclude:     cfg.Exclude,
+		preflights:  cfg.Preflights,
+	}
+}
+
+// level picks the level of a request's entry. Server errors are logged at
+// error and client errors at warn. Successful requests are logged at info,
+// except those on excluded paths, preflights and requests left out by
+// sampling, which drop to debug: LOG_LEVEL=debug shows every request.
+func (l *accessLogger) level(r *http.Request, status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	case r.Method == http.MethodOptions && !l.preflights:
+		return slog.LevelDebug
+	case l.excluded(r.URL.Path), !l.sampled(r.URL.Path):
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// excluded reports whether path falls under an excluded prefix.
+func (l *accessLogger) excluded(path string) bool {
+	for _, prefix := range l.exclude {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampled reports whether a successful request on path should be logged.
+func (l *accessLogger) sampled(path string) bool {
+	best := ""
+	rate := 1.0
+	for prefix, r := range l.sampleRates {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(best) {
+			best = prefix
+			rate = r
+		}
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// requestInfo is filled in by inner handlers (e.g. the authenticated user) so
+// the access log, which wraps everything, can report it.
+type requestInfo struct {
+	userID string
+	// locale and timezone are the authenticated user's preferences.
+	locale   string
+	timezone string
+	// err is the unexpected error a 500 was answered for.
+	err error
+}
+
+type ctxKeyRequestInfo struct{}
+
+func requestInfoFromContext(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(ctxKeyRequestInfo{}).(*requestInfo)
+	return info
+}
+
+const maxLoggedBody = 64 << 10
+
+func withLogging(next http.Handler, logger *accessLogger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		info := &requestInfo{}
+		r = r.WithContext(context.WithValue(r.Context(), ctxKeyRequestInfo{}, info))
+
+		var body []byte
+		if logger.bodies && r.Body != nil && strings.Contains(r.Header.Get("Content-Type"), "json") {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, maxLoggedBody))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+
 		recorder := &responseRecorder{ResponseWriter: w}
 		next.ServeHTTP(recorder, r)
 		status := recorder.status
 		if status == 0 {
 			status = http.StatusOK
 		}
-		duration := time.Since(start)
-		log.Printf("%s %s %d %dB %s", r.Method, r.URL.Path, status, recorder.size, duration)
+		level := logger.level(r, status)
+		if !logger.logger.Enabled(r.Context(), level) {
+			return
+		}
+
+		attrs := []slog.Attr{
+			slog.String("request_id", requestIDFromContext(r.Context())),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", recorder.size),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("client_ip", clientIP(r)),
+			slog.String("user_agent", r.UserAgent()),
+		}
+		if query := redactQuery(r.URL.Query()); query != "" {
+			attrs = append(attrs, slog.String("query", query))
+		}
+		if info.userID != "" {
+			attrs = append(attrs, slog.String("user_id", info.userID))
+		}
+		if info.err != nil {
+			attrs = append(attrs, slog.String("error", info.err.Error()))
+		}
+		if logger.headers {
+			attrs = append(attrs, slog.Any("headers", redactHeaders(r.Header)))
+		}
+		if len(body) > 0 {
+			attrs = append(attrs, slog.Any("body", redactJSON(body)))
+		}
+
+		logger.logger.LogAttrs(r.Context(), level, "http request", attrs...)
 	})
 }
 
-func withCORS(next http.Handler, allowedOrigins []string) http.Handler {
+// corsPolicy describes how cross-origin requests are answered. Origins may be
+// exact values, "*" or patterns with a wildcard host label such as
+// "https://*.example.com".
+type corsPolicy struct {
+	allowedOrigins   []string
+	allowCredentials bool
+	maxAge           time.Duration
+	exposedHeaders   []string
+	// routes overrides allowedOrigins for path prefixes; the longest prefix wins.
+	routes map[string][]string
+}
+
+func newCORSPolicy(allowedOrigins []string, cfg config.CORSConfig) *corsPolicy {
+	return &corsPolicy{
+		allowedOrigins:   allowedOrigins,
+		allowCredentials: cfg.AllowCredentials,
+		maxAge:           cfg.MaxAge,
+		exposedHeaders:   cfg.ExposedHeaders,
+		routes:           cfg.RouteOrigins,
+	}
+}
+
+func (p *corsPolicy) originsFor(path string) []string {
+	best := ""
+	origins := p.allowedOrigins
+	for prefix, routeOrigins := range p.routes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(best) {
+			best = prefix
+			origins = routeOrigins
+		}
+	}
+	return origins
+}
+
+func withCORS(next http.Handler, current *atomic.Pointer[corsPolicy]) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := current.Load()
+		allowedOrigins := policy.originsFor(r.URL.Path)
 		origin := r.Header.Get("Origin")
-		if origin != "" && isOriginAllowed(origin, allowedOrigins) {
+		wildcard := len(allowedOrigins) == 1 && allowedOrigins[0] == "*"
+		// An origin allowed only through "*" is never reflected alongside
+		// credentials, or any site could make authenticated requests.
+		if origin != "" && isOriginAllowed(origin, allowedOrigins, !policy.allowCredentials) {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-		} else if len(allowedOrigins) == 1 && allowedOrigins[0] == "*" {
+			w.Header().Add("Vary", "Origin")
+			if policy.allowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		} else if wildcard && !policy.allowCredentials {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if len(policy.exposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.exposedHeaders, ", "))
+		}
 		if r.Method == http.MethodOptions {
+			if policy.maxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.maxAge.Seconds())))
+			}
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
@@ -60,14 +259,42 @@ func withCORS(next http.Handler, allowedOrigins []string) http.Handler {
 	})
 }
 
-func isOriginAllowed(origin string, allowed []string) bool {
+// isOriginAllowed reports whether origin is listed in allowed, exactly or
+// by pattern; a bare "*" entry only counts when anyOrigin is set.
+func isOriginAllowed(origin string, allowed []string, anyOrigin bool) bool {
 	for _, candidate := range allowed {
 		if candidate == "*" {
-			return true
+			if anyOrigin {
+				return true
+			}
+			continue
 		}
 		if strings.EqualFold(candidate, origin) {
 			return true
 		}
+		if strings.Contains(candidate, "*") && matchOriginPattern(candidate, origin) {
+			return true
+		}
 	}
 	return false
 }
+
+// matchOriginPattern reports whether origin matches a pattern containing a
+// single "*", which stands for one or more host labels. The scheme and any
+// port in the pattern must match exactly.
+func matchOriginPattern(pattern, origin string) bool {
+	pattern = strings.ToLower(pattern)
+	origin = strings.ToLower(origin)
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok || strings.Contains(suffix, "*") {
+		return false
+	}
+	if len(origin) <= len(prefix)+len(suffix) {
+		return false
+	}
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	middle := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(middle, "/:@?#") && !strings.HasPrefix(middle, ".") && !strings.HasSuffix(middle, ".")
+}
@@ -0,0 +1,47 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"backoffice/backend/internal/config"
+)
+
+func TestWithCORSCredentials(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		origins     []string
+		credentials bool
+		origin      string
+		wantOrigin  string
+		wantCreds   string
+	}{
+		{name: "any origin without credentials", origins: []string{"*"}, origin: "https://evil.example", wantOrigin: "https://evil.example"},
+		{name: "any origin, no Origin header", origins: []string{"*"}, wantOrigin: "*"},
+		{name: "any origin with credentials is not reflected", origins: []string{"*"}, credentials: true, origin: "https://evil.example"},
+		{name: "listed origin with credentials", origins: []string{"https://app.example.com", "*"}, credentials: true, origin: "https://app.example.com", wantOrigin: "https://app.example.com", wantCreds: "true"},
+		{name: "unlisted origin next to * with credentials", origins: []string{"https://app.example.com", "*"}, credentials: true, origin: "https://evil.example"},
+		{name: "pattern origin with credentials", origins: []string{"https://*.example.com"}, credentials: true, origin: "https://shop.example.com", wantOrigin: "https://shop.example.com", wantCreds: "true"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var policy atomic.Pointer[corsPolicy]
+			policy.Store(newCORSPolicy(tc.origins, config.CORSConfig{AllowCredentials: tc.credentials}))
+			handler := withCORS(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), &policy)
+
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tc.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tc.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tc.wantCreds {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tc.wantCreds)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	notificationdomain "backoffice/backend/internal/domain/notification"
+	notificationusecase "backoffice/backend/internal/usecase/notification"
+)
+
+// SetNotificationService enables /admin/notification-channels; without it
+// the endpoints answer 404.
+func (s *Server) SetNotificationService(notifications *notificationusecase.Service) {
+	s.notificationService = notifications
+}
+
+func (s *Server) handleNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	if s.notificationService == nil {
+		writeError(w, http.StatusNotFound, "notification channels are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		items, err := s.notificationService.List(ctx)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if items == nil {
+			items = []*notificationdomain.Channel{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": items, "events": notificationdomain.Events})
+	case http.MethodPost:
+		var payload notificationusecase.CreateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		channel, err := s.notificationService.Create(ctx, payload)
+		if err != nil {
+			writeNotificationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, channel)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleNotificationChannelByID serves /admin/notification-channels/{id}
+// and POST /admin/notification-channels/{id}/test.
+func (s *Server) handleNotificationChannelByID(w http.ResponseWriter, r *http.Request) {
+	if s.notificationService == nil {
+		writeError(w, http.StatusNotFound, "notification channels are not configured")
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/notification-channels/"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+	if id == "" || (action != "" && action != "test") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+
+	ctx := r.Context()
+	if action == "test" {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		if err := s.notificationService.Test(ctx, id); err != nil {
+			writeNotificationError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		channel, err := s.notificationService.Get(ctx, id)
+		if err != nil {
+			writeNotificationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, channel)
+	case http.MethodPut, http.MethodPatch:
+		var payload notificationusecase.UpdateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		channel, err := s.notificationService.Update(ctx, id, payload)
+		if err != nil {
+			writeNotificationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, channel)
+	case http.MethodDelete:
+		if err := s.notificationService.Delete(ctx, id); err != nil {
+			writeNotificationError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func writeNotificationError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, notificationusecase.ErrDeliveryFailed) {
+		// The detail is the channel's own answer, which the admin testing
+		// it needs to see.
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeServiceError(w, r, err)
+}
@@ -0,0 +1,303 @@
+package httpserver
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backoffice/backend/internal/errreport"
+)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// maxValidatedBody caps how much of a request body is buffered for schema
+// validation; larger bodies are passed through unchecked.
+const maxValidatedBody = 1 << 20
+
+type apiSpec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+
+	routes []*apiRoute
+}
+
+type apiRoute struct {
+	template   string
+	segments   []string
+	literals   int
+	operations map[string]*apiOperation
+}
+
+type apiOperation struct {
+	OperationID string         `json:"operationId"`
+	Parameters  []apiParameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool                  `json:"required"`
+		Content  map[string]apiContent `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]apiContent `json:"content"`
+	} `json:"responses"`
+}
+
+type apiParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type apiContent struct {
+	Schema *schema `json:"schema"`
+}
+
+// mustLoadAPISpec parses a document derived from the embedded one, which is
+// fixed at build time.
+func mustLoadAPISpec(doc []byte) *apiSpec {
+	spec, err := loadAPISpec(doc)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+// loadAPISpec parses an OpenAPI document.
+func loadAPISpec(doc []byte) (*apiSpec, error) {
+	var spec apiSpec
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, fmt.Errorf("parsing openapi.json: %w", err)
+	}
+	for template, item := range spec.Paths {
+		route := &apiRoute{template: template, segments: strings.Split(strings.Trim(template, "/"), "/"), operations: map[string]*apiOperation{}}
+		var shared []apiParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("parsing parameters of %s: %w", template, err)
+			}
+		}
+		for method, raw := range item {
+			if method == "parameters" || method == "summary" || method == "description" {
+				continue
+			}
+			var op apiOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("parsing %s %s: %w", strings.ToUpper(method), template, err)
+			}
+			op.Parameters = append(append([]apiParameter{}, shared...), op.Parameters...)
+			route.operations[strings.ToUpper(method)] = &op
+		}
+		for _, segment := range route.segments {
+			if !strings.HasPrefix(segment, "{") {
+				route.literals++
+			}
+		}
+		spec.routes = append(spec.routes, route)
+	}
+	// Prefer literal matches, so /users/me/role wins over a templated sibling.
+	sort.Slice(spec.routes, func(i, j int) bool {
+		if spec.routes[i].literals != spec.routes[j].literals {
+			return spec.routes[i].literals > spec.routes[j].literals
+		}
+		return spec.routes[i].template < spec.routes[j].template
+	})
+	return &spec, nil
+}
+
+func (s *apiSpec) resolve(ref string) *schema {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok {
+		return nil
+	}
+	return s.Components.Schemas[name]
+}
+
+// operation finds the documented operation for a request.
+func (s *apiSpec) operation(method, path string) *apiOperation {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range s.routes {
+		if !route.matches(segments) {
+			continue
+		}
+		if op, ok := route.operations[method]; ok {
+			return op
+		}
+		if method == http.MethodHead {
+			return route.operations[http.MethodGet]
+		}
+		return nil
+	}
+	return nil
+}
+
+func (r *apiRoute) matches(segments []string) bool {
+	if len(r.segments) != len(segments) {
+		return false
+	}
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") {
+			if segments[i] == "" {
+				return false
+			}
+		} else if segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRequest checks query parameters and the JSON body of r. The body is
+// restored so the handler can read it again.
+func (s *apiSpec) validateRequest(r *http.Request, op *apiOperation) []fieldError {
+	var errs []fieldError
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		if param.In != "query" {
+			continue
+		}
+		raw, present := query[param.Name]
+		if !present || raw[0] == "" {
+			if param.Required {
+				errs = append(errs, fieldError{Field: "query." + param.Name, Message: "is required"})
+			}
+			continue
+		}
+		errs = append(errs, param.Schema.validate(queryValue(param.Schema, raw[0]), "query."+param.Name, s.resolve)...)
+	}
+
+	if op.RequestBody == nil || r.Body == nil {
+		return errs
+	}
+	content, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		// Other media types (e.g. streamed NDJSON) are left to the handler
+		// and must not be buffered here.
+		return errs
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > maxValidatedBody {
+		return errs
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			errs = append(errs, fieldError{Field: "body", Message: "is required"})
+		}
+		return errs
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return append(errs, fieldError{Field: "body", Message: "must be valid JSON"})
+	}
+	return append(errs, content.Schema.validate(value, "body", s.resolve)...)
+}
+
+// validateResponse checks a JSON response against the schema documented for
+// its status code.
+func (s *apiSpec) validateResponse(op *apiOperation, status int, header http.Header, body []byte) []fieldError {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		response, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []fieldError{{Field: "status", Message: fmt.Sprintf("%d is not documented", status)}}
+	}
+	content, ok := response.Content["application/json"]
+	if !ok || content.Schema == nil || !strings.Contains(header.Get("Content-Type"), "json") {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []fieldError{{Field: "body", Message: "must be valid JSON"}}
+	}
+	return content.Schema.validate(value, "body", s.resolve)
+}
+
+// queryValue converts a query string value to the JSON type its schema
+// expects, leaving it a string when it does not parse so validation reports
+// the mismatch.
+func queryValue(sch *schema, raw string) any {
+	if sch == nil {
+		return raw
+	}
+	switch sch.Type {
+	case "integer", "number":
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(raw); err == nil {
+			return v
+		}
+	}
+	return raw
+}
+
+// withSchemaValidation rejects requests that do not match the OpenAPI
+// document with a 400 listing every mismatch. When checkResponses is set
+// (development), responses are checked too and a mismatch is reported and
+// turned into a 500, so drift between the document and the handlers fails
+// tests instead of reaching clients. Undocumented routes pass through.
+func withSchemaValidation(next http.Handler, spec *apiSpec, checkResponses bool, streams *timeoutPolicy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := spec.operation(r.Method, r.URL.Path)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if errs := spec.validateRequest(r, op); len(errs) > 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error":   "request does not match the API schema",
+				"details": errs,
+			})
+			return
+		}
+		// Sparse fieldsets leave out required properties by design.
+		if !checkResponses || streams.isStream(r) || r.URL.Query().Has("fields") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedResponse{header: w.Header()}
+		next.ServeHTTP(buffered, r)
+		status := buffered.statusCode()
+		// Revalidations carry no body and server errors are reported anyway.
+		if status == http.StatusNotModified || status >= http.StatusInternalServerError {
+			w.WriteHeader(status)
+			_, _ = w.Write(buffered.body.Bytes())
+			return
+		}
+		if errs := spec.validateResponse(op, status, buffered.header, buffered.body.Bytes()); len(errs) > 0 {
+			errreport.Error(r.Context(), fmt.Errorf("response to %s %s (%d) does not match the API schema: %v", r.Method, r.URL.Path, status, errs), map[string]string{"operation": op.OperationID})
+			w.Header().Del("Content-Length")
+			writeJSON(w, http.StatusInternalServerError, map[string]any{
+				"error":   "response does not match the API schema",
+				"details": errs,
+			})
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(buffered.body.Bytes())
+	})
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodHead)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(s.openAPI)
+}
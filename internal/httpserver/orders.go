@@ -0,0 +1,182 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	orderdomain "backoffice/backend/internal/domain/order"
+	invoiceusecase "backoffice/backend/internal/usecase/invoice"
+	orderusecase "backoffice/backend/internal/usecase/order"
+)
+
+// SetOrderService enables /orders; without it the endpoints answer 404.
+func (s *Server) SetOrderService(orders *orderusecase.Service) {
+	s.orderService = orders
+}
+
+// SetInvoiceService enables /orders/{id}/invoice.pdf and emailing invoices;
+// without it those endpoints answer 404.
+func (s *Server) SetInvoiceService(invoices *invoiceusecase.Service) {
+	s.invoiceService = invoices
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	if s.orderService == nil {
+		writeError(w, http.StatusNotFound, "orders are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		orders, err := s.orderService.List(ctx, r.URL.Query().Get("status"))
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if orders == nil {
+			orders = []*orderdomain.Order{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": orders})
+	case http.MethodPost:
+		var payload orderusecase.CreateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		if user, ok := currentUserFromContext(ctx); ok {
+			payload.CreatedBy = user.ID
+		}
+		order, err := s.orderService.Create(ctx, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		// Placing an order takes stock.
+		s.cache.invalidate("/products")
+		writeJSON(w, http.StatusCreated, order)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleOrderByID serves GET /orders/{id}, POST /orders/{id}/transition,
+// the order's invoice and its payments.
+func (s *Server) handleOrderByID(w http.ResponseWriter, r *http.Request) {
+	if s.orderService == nil {
+		writeError(w, http.StatusNotFound, "orders are not configured")
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/orders/"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+	switch action {
+	case "invoice.pdf":
+		s.handleOrderInvoice(w, r, id)
+		return
+	case "invoice/email":
+		s.handleOrderInvoiceEmail(w, r, id)
+		return
+	case "payments":
+		s.handleOrderPayments(w, r, id)
+		return
+	case "shipments":
+		s.handleOrderShipments(w, r, id)
+		return
+	case "promotion":
+		s.handleOrderPromotion(w, r, id)
+		return
+	}
+	if id == "" || (action != "" && action != "transition") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+
+	ctx := r.Context()
+	if action == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		order, err := s.orderService.Get(ctx, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, order)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var payload orderusecase.TransitionInput
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if user, ok := currentUserFromContext(ctx); ok {
+		payload.By = user.ID
+	}
+	order, err := s.orderService.Transition(ctx, id, payload)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if order.Status == orderdomain.StatusCancelled {
+		// Cancelling an order returns its stock.
+		s.cache.invalidate("/products")
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// handleOrderInvoice serves GET /orders/{id}/invoice.pdf.
+func (s *Server) handleOrderInvoice(w http.ResponseWriter, r *http.Request, id string) {
+	if s.invoiceService == nil {
+		writeError(w, http.StatusNotFound, "invoices are not configured")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	doc, err := s.invoiceService.PDF(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(doc.Data)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", doc.Name))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(doc.Data)
+}
+
+// handleOrderInvoiceEmail serves POST /orders/{id}/invoice/email, sending
+// the invoice to the order's customer or to the address in the body.
+func (s *Server) handleOrderInvoiceEmail(w http.ResponseWriter, r *http.Request, id string) {
+	if s.invoiceService == nil {
+		writeError(w, http.StatusNotFound, "invoices are not configured")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var payload invoiceusecase.EmailInput
+	// The body is optional: without one the invoice goes to the customer.
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	to, err := s.invoiceService.Email(r.Context(), id, payload)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"invoice": invoiceusecase.Number(id), "sentTo": to})
+}
@@ -0,0 +1,142 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	paymentdomain "backoffice/backend/internal/domain/payment"
+	paymentusecase "backoffice/backend/internal/usecase/payment"
+)
+
+// maxPaymentWebhookBody caps provider webhooks, which are read in full to
+// check their signature.
+const maxPaymentWebhookBody = 1 << 20
+
+// SetPaymentService enables /orders/{id}/payments, /payments and the signed
+// provider webhooks; without it those endpoints answer 404.
+func (s *Server) SetPaymentService(payments *paymentusecase.Service) {
+	s.paymentService = payments
+}
+
+// handleOrderPayments serves GET and POST /orders/{id}/payments.
+func (s *Server) handleOrderPayments(w http.ResponseWriter, r *http.Request, orderID string) {
+	if s.paymentService == nil {
+		writeError(w, http.StatusNotFound, "payments are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		payments, err := s.paymentService.List(ctx, orderID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if payments == nil {
+			payments = []*paymentdomain.Payment{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": payments})
+	case http.MethodPost:
+		var payload paymentusecase.StartInput
+		// The body is optional: without one the default provider is used.
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		payment, err := s.paymentService.Start(ctx, orderID, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, payment)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handlePaymentByID serves GET /payments/{id} and POST
+// /payments/{id}/refund.
+func (s *Server) handlePaymentByID(w http.ResponseWriter, r *http.Request) {
+	if s.paymentService == nil {
+		writeError(w, http.StatusNotFound, "payments are not configured")
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/payments/"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+	if id == "" || (action != "" && action != "refund") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+
+	ctx := r.Context()
+	if action == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		payment, err := s.paymentService.Get(ctx, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, payment)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var payload paymentusecase.RefundInput
+	// Without a body the whole remaining amount is refunded.
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	payment, err := s.paymentService.Refund(ctx, id, payload)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, payment)
+}
+
+// handlePaymentWebhook serves POST /payments/webhooks/{provider}. Providers
+// carry no bearer token; the provider checks the payload's signature.
+func (s *Server) handlePaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.paymentService == nil {
+		writeError(w, http.StatusNotFound, "payments are not configured")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	provider := strings.Trim(strings.TrimPrefix(r.URL.Path, "/payments/webhooks/"), "/")
+	if provider == "" || strings.Contains(provider, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPaymentWebhookBody+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "could not read request body")
+		return
+	}
+	if len(body) > maxPaymentWebhookBody {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	payment, err := s.paymentService.Webhook(r.Context(), provider, body, r.Header)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if payment == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"received": true})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"received": true, "payment": payment})
+}
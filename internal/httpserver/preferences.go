@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	userusecase "backoffice/backend/internal/usecase/user"
+)
+
+// preferences is the body of /users/me/preferences.
+type preferences struct {
+	Locale   *string `json:"locale"`
+	Timezone *string `json:"timezone"`
+}
+
+func preferencesOf(user *authdomain.User) map[string]any {
+	return map[string]any{"locale": user.Locale, "timezone": user.Timezone}
+}
+
+// handleMyPreferences serves GET and PATCH /users/me/preferences, the
+// caller's own settings. Omitted fields are left unchanged.
+func (s *Server) handleMyPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, preferencesOf(user))
+	case http.MethodPatch:
+		var payload preferences
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		updated, err := s.userService.Update(r.Context(), user.ID, userusecase.UpdateInput{
+			Locale:   payload.Locale,
+			Timezone: payload.Timezone,
+		})
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if info := requestInfoFromContext(r.Context()); info != nil {
+			info.locale = updated.Locale
+			info.timezone = updated.Timezone
+		}
+		writeJSON(w, http.StatusOK, preferencesOf(updated))
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch)
+	}
+}
@@ -0,0 +1,62 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	approvaldomain "backoffice/backend/internal/domain/approval"
+	approvalusecase "backoffice/backend/internal/usecase/approval"
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// handleBulkPriceUpdate serves POST /products/bulk-price-update?preview=true,
+// repricing every product a filter selects. A preview returns the old and
+// new prices without writing them.
+func (s *Server) handleBulkPriceUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var preview bool
+	if raw := r.URL.Query().Get("preview"); raw != "" {
+		var err error
+		if preview, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "preview must be true or false")
+			return
+		}
+	}
+	var input productusecase.BulkPriceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if !preview && s.requiresApproval(approvaldomain.ActionBulkPriceUpdate) {
+		// The prices are computed again when the update is approved; the
+		// count only tells the approver how far it reaches today.
+		report, err := s.productService.BulkUpdatePrices(r.Context(), input, true)
+		if err != nil {
+			writeBulkPriceError(w, r, err)
+			return
+		}
+		s.requestApproval(w, r, approvalusecase.RequestInput{
+			Action:  approvaldomain.ActionBulkPriceUpdate,
+			Summary: fmt.Sprintf("Bulk price update of %d of %d matching products", report.Changed, report.Matched),
+			Payload: input,
+		})
+		return
+	}
+
+	report, err := s.productService.BulkUpdatePrices(r.Context(), input, preview)
+	if err != nil {
+		writeBulkPriceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func writeBulkPriceError(w http.ResponseWriter, r *http.Request, err error) {
+	writeServiceError(w, r, err)
+}
@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"net/http"
+
+	privacyusecase "backoffice/backend/internal/usecase/privacy"
+)
+
+// SetPrivacyService enables the data export routes; without it they answer
+// 404.
+func (s *Server) SetPrivacyService(privacy *privacyusecase.Service) {
+	s.privacyService = privacy
+}
+
+// handleMyDataExport serves GET /users/me/data-export, the caller's own
+// subject access archive.
+func (s *Server) handleMyDataExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	s.handleDataExport(w, r, user.ID)
+}
+
+// handleDataExport serves a JSON download of everything held about the
+// user, for GET /users/me/data-export and GET /admin/users/{id}/data-export.
+func (s *Server) handleDataExport(w http.ResponseWriter, r *http.Request, userID string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.privacyService == nil {
+		writeError(w, http.StatusNotFound, "data exports are not configured")
+		return
+	}
+	archive, err := s.privacyService.Export(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	filename := "user-data-" + archive.Profile.ID + "-" + archive.CreatedAt.Format("20060102-150405") + ".json"
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, archive)
+}
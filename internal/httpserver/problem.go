@@ -0,0 +1,74 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/errreport"
+)
+
+// problemResponse is an RFC 9457 problem document. Error repeats Detail so
+// clients reading errorResponse keep working.
+type problemResponse struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail"`
+	Code   string         `json:"code"`
+	Error  string         `json:"error"`
+	Meta   map[string]any `json:"meta,omitempty"`
+}
+
+// kindStatus maps each errcode.Kind to the status it is answered with.
+var kindStatus = map[errcode.Kind]int{
+	errcode.Invalid:         http.StatusBadRequest,
+	errcode.Unauthenticated: http.StatusUnauthorized,
+	errcode.Forbidden:       http.StatusForbidden,
+	errcode.NotFound:        http.StatusNotFound,
+	errcode.Conflict:        http.StatusConflict,
+	errcode.Unprocessable:   http.StatusUnprocessableEntity,
+	errcode.Unavailable:     http.StatusNotImplemented,
+}
+
+// writeServiceError answers a use case error. Errors with a code become a
+// problem document with the status of their kind; anything else is
+// unexpected and goes to writeInternalError.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	e, ok := errcode.As(err)
+	if !ok {
+		writeInternalError(w, r, err)
+		return
+	}
+	status, ok := kindStatus[e.Kind]
+	if !ok {
+		status = http.StatusBadRequest
+	}
+	// The whole chain is shown, as wrapping adds detail such as which field
+	// was rejected; only coded errors get here, and those are written for
+	// clients.
+	message := err.Error()
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problemResponse{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: message,
+		Code:   e.Code,
+		Error:  message,
+		Meta:   e.Meta,
+	})
+}
+
+// clientMessage is the text of err that may be shown to a client where a
+// response reports several outcomes: the message of a coded error, else a
+// generic one once err has been reported.
+func clientMessage(ctx context.Context, err error) string {
+	if _, ok := errcode.As(err); ok {
+		return err.Error()
+	}
+	errreport.Error(ctx, err, nil)
+	return "internal server error"
+}
@@ -0,0 +1,27 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+)
+
+// costPriceAdminOnly is the error when a non-admin sends costPrice.
+const costPriceAdminOnly = "admin privileges required to set costPrice"
+
+// isAdmin reports whether the request was made by an admin.
+func isAdmin(ctx context.Context) bool {
+	user, ok := currentUserFromContext(ctx)
+	return ok && user.Role == authdomain.RoleAdmin
+}
+
+// allowCostPrice answers 403 and reports false when a non-admin tries to
+// set a cost price.
+func allowCostPrice(w http.ResponseWriter, r *http.Request, cost *float64) bool {
+	if cost == nil || isAdmin(r.Context()) {
+		return true
+	}
+	writeError(w, http.StatusForbidden, costPriceAdminOnly)
+	return false
+}
@@ -0,0 +1,140 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// maxIngestLine caps one NDJSON record; longer lines end the ingestion.
+const maxIngestLine = 1 << 20
+
+// ingestResult reports the outcome of one input line.
+type ingestResult struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	SKU    string `json:"sku,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ingestSummary is the last line of the report.
+type ingestSummary struct {
+	Received int `json:"received"`
+	Created  int `json:"created"`
+	Updated  int `json:"updated"`
+	Failed   int `json:"failed"`
+	// Aborted explains why ingestion stopped before the end of the input.
+	Aborted string `json:"aborted,omitempty"`
+}
+
+// handleProductStream serves POST /products/stream: newline-delimited
+// product records, each created or, when its SKU exists, updated. Records are
+// processed one at a time as they arrive and a result line is streamed back
+// for each, so the client's upload is throttled by how fast products are
+// stored and memory use does not grow with the input. ?maxErrors=N stops
+// after N failed records.
+func (s *Server) handleProductStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ := mime.ParseMediaType(ct)
+		switch mediaType {
+		case "application/x-ndjson", "application/jsonl", "application/json":
+		default:
+			writeError(w, http.StatusUnsupportedMediaType, "send newline-delimited JSON (application/x-ndjson)")
+			return
+		}
+	}
+	maxErrors := 0
+	if raw := r.URL.Query().Get("maxErrors"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "maxErrors must be a non-negative integer")
+			return
+		}
+		maxErrors = n
+	}
+
+	// The upload may take far longer than the server's read timeout, and
+	// results are written while the body is still being read.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+	_ = rc.EnableFullDuplex()
+
+	ctx := r.Context()
+	out := newNDJSONStream(w, http.StatusOK)
+	var summary ingestSummary
+	defer func() {
+		_ = out.Encode(map[string]ingestSummary{"summary": summary})
+		_ = out.Close()
+	}()
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxIngestLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if ctx.Err() != nil {
+			summary.Aborted = "request cancelled"
+			return
+		}
+		summary.Received++
+
+		result := ingestResult{Line: line}
+		var input productusecase.CreateInput
+		if err := json.Unmarshal(raw, &input); err != nil {
+			result.Status, result.Error = "failed", "invalid JSON"
+		} else if input.CostPrice != nil && !isAdmin(ctx) {
+			result.Status, result.SKU, result.Error = "failed", input.SKU, costPriceAdminOnly
+		} else {
+			result.SKU = input.SKU
+			product, created, err := s.productService.Upsert(ctx, input)
+			switch {
+			case err != nil:
+				result.Status, result.Error = "failed", clientMessage(ctx, err)
+			case created:
+				result.Status, result.ID = "created", product.ID
+			default:
+				result.Status, result.ID = "updated", product.ID
+			}
+		}
+		switch result.Status {
+		case "created":
+			summary.Created++
+		case "updated":
+			summary.Updated++
+		default:
+			summary.Failed++
+		}
+		if err := out.Encode(result); err != nil {
+			// The client stopped reading; nothing more can be reported.
+			return
+		}
+		if maxErrors > 0 && summary.Failed >= maxErrors {
+			summary.Aborted = "maxErrors reached"
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			summary.Aborted = "line " + strconv.Itoa(line+1) + " exceeds " + strconv.Itoa(maxIngestLine) + " bytes"
+		} else {
+			summary.Aborted = "reading request body: " + err.Error()
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	promotiondomain "backoffice/backend/internal/domain/promotion"
+	promotionusecase "backoffice/backend/internal/usecase/promotion"
+)
+
+// SetPromotionService enables /promotions and /orders/{id}/promotion;
+// without it those endpoints answer 404.
+func (s *Server) SetPromotionService(promotions *promotionusecase.Service) {
+	s.promotionService = promotions
+}
+
+// handlePromotions serves GET and POST /promotions.
+func (s *Server) handlePromotions(w http.ResponseWriter, r *http.Request) {
+	if s.promotionService == nil {
+		writeError(w, http.StatusNotFound, "promotions are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		promotions, err := s.promotionService.List(ctx)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if promotions == nil {
+			promotions = []*promotiondomain.Promotion{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": promotions})
+	case http.MethodPost:
+		var payload promotionusecase.Input
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		if user, ok := currentUserFromContext(ctx); ok {
+			payload.CreatedBy = user.ID
+		}
+		promotion, err := s.promotionService.Create(ctx, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, promotion)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handlePromotionByID serves GET, PUT and DELETE /promotions/{id}.
+func (s *Server) handlePromotionByID(w http.ResponseWriter, r *http.Request) {
+	if s.promotionService == nil {
+		writeError(w, http.StatusNotFound, "promotions are not configured")
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/promotions/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		promotion, err := s.promotionService.Get(ctx, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, promotion)
+	case http.MethodPut:
+		var payload promotionusecase.Input
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		promotion, err := s.promotionService.Update(ctx, id, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, promotion)
+	case http.MethodDelete:
+		if err := s.promotionService.Delete(ctx, id); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// handlePromotionValidate serves POST /promotions/validate, which quotes a
+// code against an order or a basket of lines without redeeming it.
+func (s *Server) handlePromotionValidate(w http.ResponseWriter, r *http.Request) {
+	if s.promotionService == nil {
+		writeError(w, http.StatusNotFound, "promotions are not configured")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var payload promotionusecase.CheckInput
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	quote, err := s.promotionService.Check(r.Context(), payload)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, quote)
+}
+
+// handleOrderPromotion serves POST and DELETE /orders/{id}/promotion.
+func (s *Server) handleOrderPromotion(w http.ResponseWriter, r *http.Request, orderID string) {
+	if s.promotionService == nil {
+		writeError(w, http.StatusNotFound, "promotions are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodPost:
+		var payload promotionusecase.RedeemInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		order, err := s.promotionService.Redeem(ctx, orderID, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, order)
+	case http.MethodDelete:
+		order, err := s.promotionService.Remove(ctx, orderID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, order)
+	default:
+		writeMethodNotAllowed(w, http.MethodPost, http.MethodDelete)
+	}
+}
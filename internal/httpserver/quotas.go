@@ -0,0 +1,67 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	quotausecase "backoffice/backend/internal/usecase/quota"
+)
+
+// SetQuotaService counts API client requests against their monthly quotas
+// and enables GET /admin/api-keys/{id}/usage; without it clients are not
+// counted and the endpoint answers 404.
+func (s *Server) SetQuotaService(quotas *quotausecase.Service) {
+	s.quotaService = quotas
+}
+
+// applyQuota counts a request by an API client and reports its standing in
+// the X-Quota-* headers. It answers 429 and returns false when the client
+// is over quota and quotas are enforced.
+func (s *Server) applyQuota(w http.ResponseWriter, r *http.Request, clientID string) bool {
+	if s.quotaService == nil {
+		return true
+	}
+	status := s.quotaService.Use(r.Context(), clientID)
+	if status.Limit > 0 {
+		w.Header().Set("X-Quota-Limit", strconv.FormatInt(status.Limit, 10))
+		w.Header().Set("X-Quota-Remaining", strconv.FormatInt(status.Remaining, 10))
+		w.Header().Set("X-Quota-Reset", status.ResetsAt.Format(time.RFC3339))
+	}
+	if !status.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(status.ResetsAt).Seconds())+1))
+		writeError(w, http.StatusTooManyRequests, "monthly request quota exceeded")
+		return false
+	}
+	return true
+}
+
+// handleClientUsage serves GET /admin/api-keys/{id}/usage, the requests of
+// the API key's client this month against its quota. The id is a client id
+// from TOKEN_CLIENTS, so /admin/clients/{id}/usage serves the same.
+func (s *Server) handleClientUsage(w http.ResponseWriter, r *http.Request) {
+	rest, found := strings.CutPrefix(r.URL.Path, "/admin/api-keys/")
+	if !found {
+		rest = strings.TrimPrefix(r.URL.Path, "/admin/clients/")
+	}
+	id, ok := strings.CutSuffix(rest, "/usage")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.quotaService == nil {
+		writeError(w, http.StatusNotFound, "client quotas are not configured")
+		return
+	}
+	usage, err := s.quotaService.Usage(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
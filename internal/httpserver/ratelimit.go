@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"backoffice/backend/internal/config"
+)
+
+const rateLimiterIdleTTL = 10 * time.Minute
+
+// rateLimiter is a per-client token bucket whose limits can be changed while
+// the server is running.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	nowFunc   func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	l := &rateLimiter{buckets: make(map[string]*bucket), nowFunc: time.Now}
+	l.configure(cfg)
+	return l
+}
+
+// configure swaps the limits; existing buckets keep their current balance.
+func (l *rateLimiter) configure(cfg config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = cfg.RequestsPerSecond
+	l.burst = float64(cfg.Burst)
+}
+
+// allow consumes a token for key and, when none is left, reports how long the
+// caller should wait.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	now := l.nowFunc()
+	if now.Sub(l.lastSweep) > rateLimiterIdleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > rateLimiterIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+func withRateLimit(next http.Handler, limiter *rateLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := limiter.allow(clientIP(r))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
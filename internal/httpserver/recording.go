@@ -0,0 +1,255 @@
+package httpserver
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backoffice/backend/internal/config"
+)
+
+// maxRecordedBody caps each recorded request and response body; larger
+// bodies are replaced by a note rather than recorded in part.
+const maxRecordedBody = 64 << 10
+
+// recordingPathPrefix is never recorded, so reading the recordings does not
+// record them again.
+const recordingPathPrefix = "/admin/debug/"
+
+// recordedExchange is one request and its response, with credentials
+// redacted the way the access log redacts them.
+type recordedExchange struct {
+	RequestID  string          `json:"requestId"`
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Query      string          `json:"query,omitempty"`
+	UserID     string          `json:"userId,omitempty"`
+	Status     int             `json:"status"`
+	DurationMs float64         `json:"durationMs"`
+	Request    recordedMessage `json:"request"`
+	Response   recordedMessage `json:"response"`
+}
+
+type recordedMessage struct {
+	Headers map[string]string `json:"headers"`
+	Body    any               `json:"body,omitempty"`
+}
+
+// requestRecorder keeps the newest exchanges of the selected users and
+// routes in a ring buffer. The selection is reloadable.
+type requestRecorder struct {
+	mu      sync.Mutex
+	users   map[string]bool
+	routes  []string
+	size    int
+	entries []recordedExchange
+	// next is where the next entry goes once the buffer is full.
+	next int
+}
+
+func newRequestRecorder(cfg config.RecordingConfig) *requestRecorder {
+	rec := &requestRecorder{}
+	rec.configure(cfg)
+	return rec
+}
+
+// configure applies a new selection and size, keeping the newest entries
+// that still fit.
+func (r *requestRecorder) configure(cfg config.RecordingConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users = make(map[string]bool, len(cfg.Users))
+	for _, id := range cfg.Users {
+		r.users[id] = true
+	}
+	r.routes = cfg.Routes
+	size := max(cfg.Size, 1)
+	if size != r.size {
+		entries := r.ordered()
+		if len(entries) > size {
+			entries = entries[len(entries)-size:]
+		}
+		r.entries, r.next, r.size = entries, 0, size
+	}
+}
+
+// enabled reports whether any users or routes are selected.
+func (r *requestRecorder) enabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.users) > 0 || len(r.routes) > 0
+}
+
+// selects reports whether a request to path by userID, which is empty for
+// anonymous requests, is recorded.
+func (r *requestRecorder) selects(path, userID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if userID != "" && r.users[userID] {
+		return true
+	}
+	for _, prefix := range r.routes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *requestRecorder) add(e recordedExchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) < r.size {
+		r.entries = append(r.entries, e)
+		return
+	}
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % r.size
+}
+
+// ordered returns the entries oldest first. The caller holds mu.
+func (r *requestRecorder) ordered() []recordedExchange {
+	out := make([]recordedExchange, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// list returns up to limit entries matching keep, newest first.
+func (r *requestRecorder) list(keep func(*recordedExchange) bool, limit int) []recordedExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := r.ordered()
+	out := []recordedExchange{}
+	for i := len(entries) - 1; i >= 0 && len(out) < limit; i-- {
+		if keep(&entries[i]) {
+			out = append(out, entries[i])
+		}
+	}
+	return out
+}
+
+func (r *requestRecorder) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries, r.next = nil, 0
+}
+
+// bodyRecorder copies up to maxRecordedBody of a response while writing it.
+type bodyRecorder struct {
+	*responseRecorder
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (b *bodyRecorder) Write(p []byte) (int, error) {
+	if room := maxRecordedBody - b.body.Len(); room < len(p) {
+		b.truncated = true
+		b.body.Write(p[:max(room, 0)])
+	} else {
+		b.body.Write(p)
+	}
+	return b.responseRecorder.Write(p)
+}
+
+// withRecording records the exchanges requestRecorder selects. It runs
+// inside withLogging, whose request info names the user once
+// authMiddleware has run.
+func withRecording(next http.Handler, rec *requestRecorder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !rec.enabled() || strings.HasPrefix(r.URL.Path, recordingPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		var body []byte
+		bodyTruncated := false
+		if r.Body != nil && r.Body != http.NoBody {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, maxRecordedBody+1))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+			if len(body) > maxRecordedBody {
+				body, bodyTruncated = nil, true
+			}
+		}
+
+		recorder := &bodyRecorder{responseRecorder: &responseRecorder{ResponseWriter: w}}
+		next.ServeHTTP(recorder, r)
+
+		userID := ""
+		if info := requestInfoFromContext(r.Context()); info != nil {
+			userID = info.userID
+		}
+		if !rec.selects(r.URL.Path, userID) {
+			return
+		}
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		rec.add(recordedExchange{
+			RequestID:  requestIDFromContext(r.Context()),
+			Time:       start.UTC(),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      redactQuery(r.URL.Query()),
+			UserID:     userID,
+			Status:     status,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			Request:    recordedMessage{Headers: redactHeaders(r.Header), Body: recordedBody(body, bodyTruncated)},
+			Response:   recordedMessage{Headers: redactHeaders(recorder.Header()), Body: recordedBody(recorder.body.Bytes(), recorder.truncated)},
+		})
+	})
+}
+
+func recordedBody(body []byte, truncated bool) any {
+	if truncated {
+		return "[body over " + strconv.Itoa(maxRecordedBody>>10) + " KiB omitted]"
+	}
+	return redactJSON(body)
+}
+
+// handleDebugRequests serves GET /admin/debug/requests?user=&path=&status=&limit=,
+// the recorded exchanges newest first, and DELETE, which clears them.
+func (s *Server) handleDebugRequests(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		limit := 50
+		if raw := query.Get("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > 200 {
+				writeError(w, http.StatusBadRequest, "limit must be between 1 and 200")
+				return
+			}
+			limit = n
+		}
+		status := 0
+		if raw := query.Get("status"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 100 || n > 599 {
+				writeError(w, http.StatusBadRequest, "status must be an HTTP status code")
+				return
+			}
+			status = n
+		}
+		user, path := query.Get("user"), query.Get("path")
+		items := s.recordings.list(func(e *recordedExchange) bool {
+			return (user == "" || e.UserID == user) &&
+				(path == "" || strings.HasPrefix(e.Path, path)) &&
+				(status == 0 || e.Status == status)
+		}, limit)
+		writeJSON(w, http.StatusOK, map[string]any{"enabled": s.recordings.enabled(), "items": items})
+	case http.MethodDelete:
+		s.recordings.clear()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodDelete)
+	}
+}
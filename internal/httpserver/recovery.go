@@ -0,0 +1,77 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+
+	"backoffice/backend/internal/errreport"
+)
+
+// withErrorScope attaches an error-report scope describing the request so
+// reports made anywhere below (handlers, use cases) carry the request ID,
+// route, client IP and, once authenticated, the user.
+func withErrorScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := &errreport.Scope{
+			RequestID: requestIDFromContext(r.Context()),
+			Method:    r.Method,
+			Route:     r.URL.Path,
+			ClientIP:  clientIP(r),
+		}
+		next.ServeHTTP(w, r.WithContext(errreport.WithScope(r.Context(), scope)))
+	})
+}
+
+// withRecovery turns a panicking handler into a 500 response and reports the
+// panic instead of letting net/http drop the connection.
+func withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			errreport.Panic(r.Context(), recovered, nil)
+			// If the handler already started the response this cannot
+			// change the status, but the client still sees a truncated body.
+			writeInternalErrorResponse(w, r)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeInternalError reports an unexpected use case error and answers 500.
+// The error often comes from the database driver and can name tables,
+// constraints or hosts, so the client only gets the request ID to quote; the
+// error itself goes to the error report and the access log entry.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	errreport.Error(r.Context(), err, nil)
+	if info := requestInfoFromContext(r.Context()); info != nil {
+		info.err = err
+	}
+	writeInternalErrorResponse(w, r)
+}
+
+// internalErrorResponse is the body of every 500. RequestID matches the
+// X-Request-ID header and the logged entries for the request.
+type internalErrorResponse struct {
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func writeInternalErrorResponse(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusInternalServerError, internalErrorResponse{
+		Error:     "internal server error",
+		RequestID: requestIDFromContext(r.Context()),
+	})
+}
+
+// setErrorScopeUser records the authenticated user on the request's scope.
+func setErrorScopeUser(ctx context.Context, userID string) {
+	if scope := errreport.ScopeFromContext(ctx); scope != nil {
+		scope.UserID = userID
+	}
+}
@@ -0,0 +1,92 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+}
+
+// isSensitiveKey reports whether a JSON field or query parameter name is
+// likely to carry a credential.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range []string{"password", "secret", "token", "authorization", "api_key", "apikey"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key, values := range h {
+		canonical := http.CanonicalHeaderKey(key)
+		if sensitiveHeaders[canonical] {
+			out[canonical] = redacted
+			continue
+		}
+		out[canonical] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+func redactQuery(values url.Values) string {
+	if len(values) == 0 {
+		return ""
+	}
+	clean := url.Values{}
+	for key, vals := range values {
+		if isSensitiveKey(key) {
+			clean[key] = []string{redacted}
+			continue
+		}
+		clean[key] = vals
+	}
+	return clean.Encode()
+}
+
+// redactJSON returns body with sensitive fields replaced. Bodies that are not
+// valid JSON are dropped entirely rather than risk leaking secrets.
+func redactJSON(body []byte) any {
+	if len(body) == 0 {
+		return nil
+	}
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return "[non-JSON body omitted]"
+	}
+	return redactValue(decoded)
+}
+
+func redactValue(v any) any {
+	switch typed := v.(type) {
+	case map[string]any:
+		for key, inner := range typed {
+			if isSensitiveKey(key) {
+				typed[key] = redacted
+				continue
+			}
+			typed[key] = redactValue(inner)
+		}
+		return typed
+	case []any:
+		for i, inner := range typed {
+			typed[i] = redactValue(inner)
+		}
+		return typed
+	default:
+		return v
+	}
+}
@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"backoffice/backend/internal/config"
+)
+
+// applyDynamicConfig installs the settings that are safe to change while
+// requests are in flight: CORS origins, security headers, trusted proxies,
+// rate limits, load shedding, log level and feature flags.
+func (s *Server) applyDynamicConfig(cfg config.Config) {
+	s.cors.Store(newCORSPolicy(cfg.AllowedOrigins, cfg.CORS))
+	s.securityHeaders.Store(newSecurityHeaders(cfg.SecurityHeaders))
+	prefixes, unix := cfg.ProxyPrefixes()
+	s.proxies.Store(&proxyTrust{prefixes: prefixes, unix: unix, header: cfg.TrustedProxyHeader})
+	s.limiter.configure(cfg.RateLimit)
+	s.shedder.configure(cfg.DatabasePool.ShedThreshold)
+	s.recordings.configure(cfg.Recording)
+	s.logLevel.Set(parseLogLevel(cfg.LogLevel))
+	flags := make(map[string]bool, len(cfg.FeatureFlags))
+	for name, enabled := range cfg.FeatureFlags {
+		flags[name] = enabled
+	}
+	s.flags.Store(&flags)
+	for _, hook := range s.reloadHooks {
+		hook(cfg)
+	}
+}
+
+// OnReload registers fn to receive the configuration after every successful
+// reload, for settings owned by other components (e.g. query logging).
+func (s *Server) OnReload(fn func(config.Config)) {
+	s.reloadHooks = append(s.reloadHooks, fn)
+}
+
+// ReloadConfig re-reads the environment (including .env) and applies the
+// dynamically-safe settings. Invalid configuration is rejected and the
+// running settings are kept.
+func (s *Server) ReloadConfig() (config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.Config{}, err
+	}
+	s.applyDynamicConfig(cfg)
+	log.Printf("configuration reloaded: log level %s, rate limit %g req/s, %d CORS origin(s)", cfg.LogLevel, cfg.RateLimit.RequestsPerSecond, len(cfg.AllowedOrigins))
+	return cfg, nil
+}
+
+// FeatureEnabled reports whether the named feature flag is switched on.
+func (s *Server) FeatureEnabled(name string) bool {
+	flags := s.flags.Load()
+	if flags == nil {
+		return false
+	}
+	return (*flags)[strings.ToLower(name)]
+}
+
+func (s *Server) handleConfigReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	cfg, err := s.ReloadConfig()
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	s.announceChange(r.Context(), ChangeEvent{Table: changeTableConfig, Op: "reload"})
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"log_level":      cfg.LogLevel,
+		"cors_origins":   cfg.AllowedOrigins,
+		"rate_limit":     map[string]any{"rps": cfg.RateLimit.RequestsPerSecond, "burst": cfg.RateLimit.Burst},
+		"feature_flags":  cfg.FeatureFlags,
+		"config_summary": cfg.Summary(),
+	})
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
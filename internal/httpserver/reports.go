@@ -0,0 +1,152 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	productdomain "backoffice/backend/internal/domain/product"
+)
+
+// handleStockValuation serves GET /reports/stock-valuation. asOf (a date or
+// RFC 3339 timestamp) values stock from the movement ledger at that time; a
+// bare date means the end of that UTC day. format=csv, or an Accept header
+// preferring text/csv, returns a spreadsheet-friendly export.
+func (s *Server) handleStockValuation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	var asOf *time.Time
+	if raw := strings.TrimSpace(r.URL.Query().Get("asOf")); raw != "" {
+		parsed, err := parseAsOf(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "asOf must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+			return
+		}
+		asOf = &parsed
+	}
+
+	report, err := s.productService.StockValuation(r.Context(), asOf)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		writeValuationCSV(w, report)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleMarginReport serves GET /reports/margins: the margin of every
+// product with a cost price and the totals per category. format=csv, or an
+// Accept header preferring text/csv, exports the product lines.
+func (s *Server) handleMarginReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	ctx := r.Context()
+	report, err := s.productService.MarginReport(ctx)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if wantsCSV(r) {
+		writeMarginCSV(w, report)
+		return
+	}
+
+	var ids []string
+	for _, line := range report.Categories {
+		if line.CategoryID != "" {
+			ids = append(ids, line.CategoryID)
+		}
+	}
+	categories, err := s.categoryService.GetByIDs(ctx, ids)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	names := make(map[string]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	for i := range report.Categories {
+		report.Categories[i].CategoryName = names[report.Categories[i].CategoryID]
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func writeMarginCSV(w http.ResponseWriter, report *productdomain.MarginReport) {
+	out, err := newCSVStream(w, "margins.csv", []string{"product_id", "sku", "name", "category_id", "price", "cost_price", "margin", "margin_percent", "quantity", "stock_margin"})
+	if err != nil {
+		return
+	}
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, line := range report.Products {
+		if err := out.Write([]string{
+			line.ProductID,
+			line.SKU,
+			line.Name,
+			line.CategoryID,
+			money(line.Price),
+			money(line.CostPrice),
+			money(line.Margin),
+			money(line.MarginPercent),
+			strconv.Itoa(line.Quantity),
+			money(line.StockMargin),
+		}); err != nil {
+			return
+		}
+	}
+	// The total's margin percent is of the stock's sale value.
+	_ = out.Write([]string{"", "", "TOTAL", "", "", "", "", money(report.Total.MarginPercent), strconv.Itoa(report.Total.Quantity), money(report.Total.Margin)})
+	_ = out.Close()
+}
+
+func parseAsOf(raw string) (time.Time, error) {
+	if day, err := time.Parse(time.DateOnly, raw); err == nil {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+func wantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return strings.HasPrefix(r.Header.Get("Accept"), "text/csv")
+}
+
+func writeValuationCSV(w http.ResponseWriter, report *productdomain.StockValuation) {
+	name := "stock-valuation.csv"
+	if report.AsOf != nil {
+		name = "stock-valuation-" + report.AsOf.UTC().Format(time.DateOnly) + ".csv"
+	}
+	out, err := newCSVStream(w, name, []string{"category_id", "category", "products", "quantity", "value"})
+	if err != nil {
+		return
+	}
+	for _, line := range report.Lines {
+		category := line.CategoryName
+		if line.CategoryID == "" {
+			category = "(uncategorised)"
+		}
+		if err := out.Write([]string{
+			line.CategoryID,
+			category,
+			strconv.Itoa(line.Products),
+			strconv.Itoa(line.Quantity),
+			strconv.FormatFloat(line.Value, 'f', 2, 64),
+		}); err != nil {
+			return
+		}
+	}
+	_ = out.Write([]string{"", "TOTAL", strconv.Itoa(report.TotalProducts), strconv.Itoa(report.TotalQuantity), strconv.FormatFloat(report.TotalValue, 'f', 2, 64)})
+	_ = out.Close()
+}
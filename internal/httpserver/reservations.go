@@ -0,0 +1,83 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// handleProductReservations serves /products/{id}/reservations (list and
+// create), /products/{id}/reservations/{reservationId} (release) and
+// /products/{id}/reservations/{reservationId}/confirm. rest is the path after
+// "reservations/".
+func (s *Server) handleProductReservations(w http.ResponseWriter, r *http.Request, productID, rest string) {
+	rest = strings.Trim(rest, "/")
+	if rest == "" {
+		s.handleReservationCollection(w, r, productID)
+		return
+	}
+	reservationID, action, _ := strings.Cut(rest, "/")
+	switch action {
+	case "":
+		if r.Method != http.MethodDelete {
+			writeMethodNotAllowed(w, http.MethodDelete)
+			return
+		}
+		if err := s.productService.ReleaseReservation(r.Context(), productID, reservationID); err != nil {
+			writeReservationError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "confirm":
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		item, err := s.productService.ConfirmReservation(r.Context(), productID, reservationID)
+		if err != nil {
+			writeReservationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, masked(r.Context(), item))
+	default:
+		writeError(w, http.StatusNotFound, "resource not found")
+	}
+}
+
+func (s *Server) handleReservationCollection(w http.ResponseWriter, r *http.Request, productID string) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		items, err := s.productService.ListReservations(ctx, productID)
+		if err != nil {
+			writeReservationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": items})
+	case http.MethodPost:
+		var payload productusecase.ReserveInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		var userID string
+		if user, ok := currentUserFromContext(ctx); ok {
+			userID = user.ID
+		}
+		item, err := s.productService.Reserve(ctx, productID, userID, payload)
+		if err != nil {
+			writeReservationError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, item)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// writeReservationError maps reservation failures to responses.
+func writeReservationError(w http.ResponseWriter, r *http.Request, err error) {
+	writeServiceError(w, r, err)
+}
@@ -1,11 +1,19 @@
 package httpserver
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// streamFlushInterval is how often streaming writers push buffered rows to
+// the client: often enough that exports show progress, without a write per
+// row.
+const streamFlushInterval = 250 * time.Millisecond
+
 type errorResponse struct {
 	Error string `json:"error"`
 }
@@ -24,3 +32,106 @@ func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
 	w.Header().Set("Allow", strings.Join(allowed, ", "))
 	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 }
+
+// intervalFlusher flushes a response at most once per interval. Writers that
+// cannot flush (e.g. when a middleware buffers the response) are tolerated;
+// the body then goes out when the handler returns.
+type intervalFlusher struct {
+	rc       *http.ResponseController
+	interval time.Duration
+	last     time.Time
+}
+
+func newIntervalFlusher(w http.ResponseWriter, interval time.Duration) intervalFlusher {
+	return intervalFlusher{rc: http.NewResponseController(w), interval: interval, last: time.Now()}
+}
+
+// due reports whether the interval has passed since the last flush.
+func (f *intervalFlusher) due() bool {
+	return time.Since(f.last) >= f.interval
+}
+
+func (f *intervalFlusher) flush() error {
+	f.last = time.Now()
+	if err := f.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// csvStream writes a CSV download row by row. Once it is created the status
+// is sent, so errors can only end the response early; a Write error usually
+// means the client went away and the handler should stop.
+type csvStream struct {
+	out     *csv.Writer
+	flusher intervalFlusher
+}
+
+// newCSVStream starts a 200 text/csv attachment named filename and writes the
+// header row.
+func newCSVStream(w http.ResponseWriter, filename string, header []string) (*csvStream, error) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	s := &csvStream{out: csv.NewWriter(w), flusher: newIntervalFlusher(w, streamFlushInterval)}
+	return s, s.Write(header)
+}
+
+// Write appends a row, flushing when the flush interval has passed.
+func (s *csvStream) Write(record []string) error {
+	if err := s.out.Write(record); err != nil {
+		return err
+	}
+	if !s.flusher.due() {
+		return nil
+	}
+	s.out.Flush()
+	if err := s.out.Error(); err != nil {
+		return err
+	}
+	return s.flusher.flush()
+}
+
+// Close sends any buffered rows.
+func (s *csvStream) Close() error {
+	s.out.Flush()
+	if err := s.out.Error(); err != nil {
+		return err
+	}
+	return s.flusher.flush()
+}
+
+// ndjsonStream writes newline-delimited JSON, one value per line, with the
+// same flushing and error semantics as csvStream.
+type ndjsonStream struct {
+	enc     *json.Encoder
+	flusher intervalFlusher
+}
+
+// newNDJSONStream starts an application/x-ndjson response with status.
+func newNDJSONStream(w http.ResponseWriter, status int) *ndjsonStream {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(status)
+	return &ndjsonStream{enc: json.NewEncoder(w), flusher: newIntervalFlusher(w, streamFlushInterval)}
+}
+
+// Encode writes v as one line, flushing when the flush interval has passed.
+func (s *ndjsonStream) Encode(v any) error {
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+	if !s.flusher.due() {
+		return nil
+	}
+	return s.flusher.flush()
+}
+
+// Flush sends buffered lines now, e.g. before a slow step.
+func (s *ndjsonStream) Flush() error {
+	return s.flusher.flush()
+}
+
+// Close sends any buffered lines.
+func (s *ndjsonStream) Close() error {
+	return s.flusher.flush()
+}
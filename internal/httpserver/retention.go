@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	retentionusecase "backoffice/backend/internal/usecase/retention"
+)
+
+// SetRetentionService enables /admin/retention; without it the endpoints
+// answer 404.
+func (s *Server) SetRetentionService(retention *retentionusecase.Service) {
+	s.retentionService = retention
+}
+
+func (s *Server) handleRetention(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.retentionService == nil {
+		writeError(w, http.StatusNotFound, "retention policies are not configured")
+		return
+	}
+	policies, err := s.retentionService.Policies(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": policies})
+}
+
+// handleRetentionTarget serves PUT /admin/retention/{target} and
+// POST /admin/retention/purge?dryRun=true|false.
+func (s *Server) handleRetentionTarget(w http.ResponseWriter, r *http.Request) {
+	if s.retentionService == nil {
+		writeError(w, http.StatusNotFound, "retention policies are not configured")
+		return
+	}
+	target := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/retention/"), "/")
+	if target == "" || strings.Contains(target, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	if target == "purge" {
+		s.handleRetentionPurge(w, r)
+		return
+	}
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		writeMethodNotAllowed(w, http.MethodPut, http.MethodPatch)
+		return
+	}
+	var payload struct {
+		Days *int `json:"days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if payload.Days == nil {
+		writeError(w, http.StatusBadRequest, "days is required")
+		return
+	}
+	var actorID string
+	if user, ok := currentUserFromContext(r.Context()); ok {
+		actorID = user.ID
+	}
+	policy, err := s.retentionService.SetPolicy(r.Context(), target, *payload.Days, actorID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// handleRetentionPurge enforces every policy now. A dry run reports what
+// would be deleted without deleting it.
+func (s *Server) handleRetentionPurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var dryRun bool
+	if raw := r.URL.Query().Get("dryRun"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "dryRun must be true or false")
+			return
+		}
+	}
+	results, err := s.retentionService.Enforce(r.Context(), dryRun)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": results})
+}
@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	returnsdomain "backoffice/backend/internal/domain/returns"
+	returnsusecase "backoffice/backend/internal/usecase/returns"
+)
+
+// SetReturnService enables /returns; without it the endpoints answer 404.
+func (s *Server) SetReturnService(returns *returnsusecase.Service) {
+	s.returnService = returns
+}
+
+func (s *Server) handleReturns(w http.ResponseWriter, r *http.Request) {
+	if s.returnService == nil {
+		writeError(w, http.StatusNotFound, "returns are not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		returns, err := s.returnService.List(ctx, query.Get("status"), query.Get("orderId"))
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if returns == nil {
+			returns = []*returnsdomain.Return{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": returns})
+	case http.MethodPost:
+		var payload returnsusecase.CreateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		if user, ok := currentUserFromContext(ctx); ok {
+			payload.RequestedBy = user.ID
+		}
+		ret, err := s.returnService.Create(ctx, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, ret)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleReturnByID serves GET /returns/{id} and
+// POST /returns/{id}/approve|reject|receive.
+func (s *Server) handleReturnByID(w http.ResponseWriter, r *http.Request) {
+	if s.returnService == nil {
+		writeError(w, http.StatusNotFound, "returns are not configured")
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/returns/"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+	if id == "" || (action != "" && action != "approve" && action != "reject" && action != "receive") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+
+	ctx := r.Context()
+	if action == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		ret, err := s.returnService.Get(ctx, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, ret)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	user, ok := currentUserFromContext(ctx)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	var ret *returnsdomain.Return
+	var err error
+	switch action {
+	case "approve":
+		ret, err = s.returnService.Approve(ctx, id, user.ID)
+	case "reject":
+		var payload struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		ret, err = s.returnService.Reject(ctx, id, user.ID, payload.Reason)
+	case "receive":
+		ret, err = s.returnService.Receive(ctx, id, user.ID)
+		if err == nil {
+			// Received goods are back in stock.
+			s.cache.invalidate("/products")
+		}
+	}
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
@@ -0,0 +1,286 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"backoffice/backend/internal/config"
+	authdomain "backoffice/backend/internal/domain/auth"
+)
+
+// routeKind selects the request timeout class a route is mounted with.
+type routeKind int
+
+const (
+	routeStandard routeKind = iota
+	routeLongRunning
+	routeStreaming
+)
+
+// route declares one mounted pattern and everything needed to guard it. The
+// same declarations drive the mux registration and the security section of
+// the served OpenAPI document, so the two cannot drift apart.
+type route struct {
+	pattern string
+	handler http.HandlerFunc
+	kind    routeKind
+	// group is the token scope group (see authMiddleware). Routes without a
+	// group are public.
+	group string
+	// role is required for every method; writeRole only for methods that
+	// change state, for routes that anyone may read.
+	role      authdomain.UserRole
+	writeRole authdomain.UserRole
+	// rateLimit adds a per-client limit on top of the global one.
+	rateLimit *config.RateLimitConfig
+	// cache names the response cache group; empty disables caching.
+	cache   string
+	noStore bool
+	// beforeConsent lets users who have yet to accept the newest mandatory
+	// terms through (see requireConsent).
+	beforeConsent bool
+}
+
+// authRateLimit slows down credential guessing on the public auth routes.
+var authRateLimit = &config.RateLimitConfig{RequestsPerSecond: 0.5, Burst: 10}
+
+// routes is the registry of public API routes.
+func (s *Server) routes() []route {
+	routes := []route{
+		{pattern: "/health", handler: s.handleHealth},
+		{pattern: "/readyz", handler: s.handleReady},
+		{pattern: "/openapi.json", handler: s.handleOpenAPI},
+		{pattern: "/auth/register", handler: s.handleRegister, noStore: true, rateLimit: authRateLimit},
+		{pattern: "/auth/login", handler: s.handleLogin, noStore: true, rateLimit: authRateLimit},
+		{pattern: "/auth/renew", handler: s.handleRenewToken, noStore: true},
+		// Logout reads the bearer token itself so it can revoke sessions
+		// whose user is gone.
+		{pattern: "/auth/logout", handler: s.handleLogout, noStore: true},
+		// Integrations sign their requests instead of sending a token.
+		{pattern: "/integrations/", handler: s.verifyIntegration(s.handleIntegrationPayload), noStore: true},
+		// So do payment providers, checked by the provider.
+		{pattern: "/payments/webhooks/", handler: s.handlePaymentWebhook, noStore: true},
+		// And shipping carriers.
+		{pattern: "/shipments/webhooks/", handler: s.handleShipmentWebhook, noStore: true},
+
+		{pattern: "/products", handler: s.handleProducts, group: "products", cache: "/products"},
+		{pattern: "/products/", handler: s.handleProductByID, group: "products", cache: "/products"},
+		{pattern: "/products/lookup", handler: s.handleProductLookup, group: "products", cache: "/products"},
+		{pattern: "/products/stream", handler: s.handleProductStream, kind: routeStreaming, group: "products", cache: "/products"},
+		{pattern: "/products/bulk-price-update", handler: s.handleBulkPriceUpdate, group: "products", role: authdomain.RoleAdmin, cache: "/products"},
+		{pattern: "/products/bulk-delete", handler: s.handleBulkDeleteProducts, group: "products", role: authdomain.RoleAdmin, cache: "/products"},
+		{pattern: "/categories", handler: s.handleCategories, group: "categories", writeRole: authdomain.RoleAdmin, cache: "/categories"},
+		{pattern: "/categories/", handler: s.handleCategoryByID, group: "categories", writeRole: authdomain.RoleAdmin, cache: "/categories"},
+		{pattern: "/users/change-password", handler: s.handleChangePassword, group: "account"},
+		{pattern: "/users/me/role", handler: s.handleUserRole, group: "account"},
+		{pattern: "/users/me/preferences", handler: s.handleMyPreferences, group: "account"},
+		{pattern: "/users/me/sessions", handler: s.handleMySessions, group: "account"},
+		{pattern: "/users/me/sessions/", handler: s.handleMySessions, group: "account"},
+		{pattern: "/users/me/views", handler: s.handleMyViews, group: "account"},
+		{pattern: "/users/me/views/", handler: s.handleMyViews, group: "account"},
+		{pattern: "/users/me/watches", handler: s.handleMyWatches, group: "account"},
+		{pattern: "/users/me/notifications", handler: s.handleMyNotifications, group: "account"},
+		{pattern: "/users/me/data-export", handler: s.handleMyDataExport, kind: routeLongRunning, group: "account", beforeConsent: true},
+		{pattern: "/users/me/consents", handler: s.handleMyConsents, group: "account", beforeConsent: true},
+		{pattern: "/orders", handler: s.handleOrders, group: "orders"},
+		{pattern: "/orders/", handler: s.handleOrderByID, group: "orders"},
+		{pattern: "/returns", handler: s.handleReturns, group: "orders"},
+		{pattern: "/returns/", handler: s.handleReturnByID, group: "orders", writeRole: authdomain.RoleAdmin},
+		{pattern: "/payments/", handler: s.handlePaymentByID, group: "orders", writeRole: authdomain.RoleAdmin},
+		{pattern: "/shipments/", handler: s.handleShipmentByID, group: "orders"},
+		{pattern: "/promotions", handler: s.handlePromotions, group: "promotions", writeRole: authdomain.RoleAdmin},
+		{pattern: "/promotions/", handler: s.handlePromotionByID, group: "promotions", writeRole: authdomain.RoleAdmin},
+		// Checking a code changes nothing, so any user may do it.
+		{pattern: "/promotions/validate", handler: s.handlePromotionValidate, group: "promotions"},
+		{pattern: "/customers", handler: s.handleCustomers, group: "customers"},
+		{pattern: "/customers/", handler: s.handleCustomerByID, group: "customers"},
+		{pattern: "/reports/margins", handler: s.handleMarginReport, kind: routeLongRunning, group: "reports", role: authdomain.RoleAdmin},
+		{pattern: "/reports/stock-valuation", handler: s.handleStockValuation, kind: routeLongRunning, group: "reports", role: authdomain.RoleAdmin},
+		{pattern: "/events", handler: s.handleEvents, kind: routeStreaming, group: "events"},
+
+		{pattern: "/admin/users", handler: s.handleAdminUsers, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/users/", handler: s.handleAdminUserByID, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/users/bulk-delete", handler: s.handleBulkDeleteUsers, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/webhooks", handler: s.handleWebhooks, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/webhooks/", handler: s.handleWebhookByID, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/trash", handler: s.handleTrash, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/trash/", handler: s.handleTrashItem, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/retention", handler: s.handleRetention, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/retention/", handler: s.handleRetentionTarget, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/approvals", handler: s.handleApprovals, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/approvals/", handler: s.handleApprovalByID, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/integrations", handler: s.handleIntegrations, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/integrations/", handler: s.handleIntegrationByID, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/api-keys/", handler: s.handleClientUsage, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/clients/", handler: s.handleClientUsage, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/notification-channels", handler: s.handleNotificationChannels, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/notification-channels/", handler: s.handleNotificationChannelByID, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/sync-runs", handler: s.handleSyncRuns, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/sync-runs/", handler: s.handleSyncRun, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/activity", handler: s.handleActivity, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/audit-events/export", handler: s.handleAuditExport, kind: routeLongRunning, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/policies", handler: s.handlePolicies, group: "admin", role: authdomain.RoleAdmin, beforeConsent: true},
+		{pattern: "/admin/backup", handler: s.handleBackup, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/restore", handler: s.handleRestore, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/config/reload", handler: s.handleConfigReload, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/admin/debug/requests", handler: s.handleDebugRequests, group: "admin", role: authdomain.RoleAdmin, noStore: true},
+		{pattern: "/admin/health", handler: s.handleHealthDetails, group: "admin", role: authdomain.RoleAdmin},
+		{pattern: "/search", handler: s.handleSearch, group: "admin", role: authdomain.RoleAdmin},
+	}
+	if len(s.adminAddrs) == 0 {
+		// Without an internal listener, metrics are only exposed to admins.
+		routes = append(routes, route{pattern: "/metrics", handler: s.handleMetrics, group: "admin", role: authdomain.RoleAdmin})
+	}
+	return routes
+}
+
+// roleFor returns the role a request with the given method needs, if any.
+func (rt *route) roleFor(method string) authdomain.UserRole {
+	if rt.role != "" {
+		return rt.role
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ""
+	default:
+		return rt.writeRole
+	}
+}
+
+// mount wraps the route's handler in the middleware it declares and
+// registers it on the public router.
+func (s *Server) mount(rt route) {
+	var handler http.Handler = rt.handler
+	if rt.cache != "" {
+		handler = s.cache.middleware(rt.cache, handler)
+	}
+	if rt.group != "" {
+		handler = s.authMiddleware(rt.group, requireRole(rt, s.requireConsent(rt, handler)))
+	}
+	if rt.rateLimit != nil {
+		handler = withRateLimit(handler, newRateLimiter(*rt.rateLimit))
+	}
+	if rt.noStore {
+		handler = withNoStore(handler)
+	}
+	handler = withRouteMetrics(rt.pattern, s.slos, handler)
+
+	switch rt.kind {
+	case routeLongRunning:
+		s.handleLongRunning(rt.pattern, handler)
+	case routeStreaming:
+		s.handleStreaming(rt.pattern, handler)
+	default:
+		s.router.Handle(rt.pattern, handler)
+	}
+}
+
+// requireRole rejects callers without the role the route declares for the
+// request method. It runs after authMiddleware has stored the user.
+func requireRole(rt route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := rt.roleFor(r.Method)
+		if role == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, ok := currentUserFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		if user.Role != role {
+			writeError(w, http.StatusForbidden, string(role)+" privileges required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lookupRoute finds the route the mux would pick for path: an exact pattern,
+// or else the longest subtree pattern (ending in "/") that prefixes it.
+func lookupRoute(routes []route, path string) *route {
+	var best *route
+	for i := range routes {
+		rt := &routes[i]
+		if rt.pattern == path {
+			return rt
+		}
+		if strings.HasSuffix(rt.pattern, "/") && strings.HasPrefix(path, rt.pattern) {
+			if best == nil || len(rt.pattern) > len(best.pattern) {
+				best = rt
+			}
+		}
+	}
+	return best
+}
+
+// documentRoutes fills in the security requirements, the 401/403/429
+// responses and the x-required-scope, x-required-role and x-rate-limit
+// extensions of every operation in doc from the route registry. Operations
+// on public routes are left as written.
+func documentRoutes(doc []byte, routes []route) ([]byte, error) {
+	var spec map[string]any
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, fmt.Errorf("parsing openapi.json: %w", err)
+	}
+	paths, _ := spec["paths"].(map[string]any)
+	for template, item := range paths {
+		operations, _ := item.(map[string]any)
+		// Templated segments are matched like any other path segment.
+		rt := lookupRoute(routes, template)
+		if rt == nil {
+			return nil, fmt.Errorf("openapi.json documents %s, which no route serves", template)
+		}
+		for method, raw := range operations {
+			op, ok := raw.(map[string]any)
+			if !ok || method == "parameters" {
+				continue
+			}
+			responses, _ := op["responses"].(map[string]any)
+			if responses == nil {
+				responses = map[string]any{}
+				op["responses"] = responses
+			}
+			method = strings.ToUpper(method)
+			if rt.rateLimit != nil {
+				op["x-rate-limit"] = map[string]any{
+					"requestsPerSecond": rt.rateLimit.RequestsPerSecond,
+					"burst":             rt.rateLimit.Burst,
+				}
+				setDefaultResponse(responses, "429", "Rate limit exceeded")
+			}
+			if rt.group == "" {
+				continue
+			}
+			op["security"] = []any{map[string]any{"bearerAuth": []any{}}}
+			op["x-required-scope"] = requiredScope(rt.group, method)
+			setDefaultResponse(responses, "401", "Missing or invalid token")
+			if role := rt.roleFor(method); role != "" {
+				op["x-required-role"] = string(role)
+				setDefaultResponse(responses, "403", "Admin only, or token lacks the required scope")
+			} else {
+				setDefaultResponse(responses, "403", "Token lacks the required scope")
+			}
+		}
+	}
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// setDefaultResponse documents an error response unless the operation already
+// describes that status itself.
+func setDefaultResponse(responses map[string]any, status, description string) {
+	if _, ok := responses[status]; ok {
+		return
+	}
+	responses[status] = map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+			},
+		},
+	}
+}
@@ -0,0 +1,198 @@
+package httpserver
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+// schema is the subset of OpenAPI 3.0 schema objects the validator
+// understands; keywords outside it are ignored.
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Enum       []any              `json:"enum"`
+	Nullable   bool               `json:"nullable"`
+	Properties map[string]*schema `json:"properties"`
+	Required   []string           `json:"required"`
+	Items      *schema            `json:"items"`
+	OneOf      []*schema          `json:"oneOf"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	MinItems   *int               `json:"minItems"`
+	// AdditionalProperties is only honoured in its boolean form.
+	AdditionalProperties *bool `json:"additionalProperties"`
+}
+
+// fieldError describes one place where a document does not match its schema.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// validate checks value, as decoded by encoding/json, against s. Field paths
+// are rooted at path ("body", "query.limit", ...).
+func (s *schema) validate(value any, path string, resolve func(string) *schema) []fieldError {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		target := resolve(s.Ref)
+		if target == nil {
+			return []fieldError{{Field: path, Message: "unresolvable schema " + s.Ref}}
+		}
+		return target.validate(value, path, resolve)
+	}
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []fieldError{{Field: path, Message: "must not be null"}}
+	}
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, option := range s.OneOf {
+			if len(option.validate(value, path, resolve)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return []fieldError{{Field: path, Message: fmt.Sprintf("must match exactly one of %d schemas", len(s.OneOf))}}
+		}
+		return nil
+	}
+
+	var errs []fieldError
+	fail := func(format string, args ...any) {
+		errs = append(errs, fieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return errs
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, fieldError{Field: joinField(path, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					errs = append(errs, fieldError{Field: joinField(path, name), Message: "is not allowed"})
+				}
+				continue
+			}
+			errs = append(errs, prop.validate(obj[name], joinField(path, name), resolve)...)
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return errs
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			fail("must contain at least %d item(s)", *s.MinItems)
+		}
+		for i, item := range arr {
+			errs = append(errs, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), resolve)...)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return errs
+		}
+		length := len([]rune(str))
+		if s.MinLength != nil && length < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if msg := checkFormat(s.Format, str); msg != "" {
+			fail("%s", msg)
+		}
+	case "number", "integer":
+		num, ok := value.(float64)
+		if !ok {
+			if s.Type == "integer" {
+				fail("must be an integer")
+			} else {
+				fail("must be a number")
+			}
+			return errs
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			fail("must be an integer")
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			fail("must be at least %g", *s.Minimum)
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			fail("must be at most %g", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		options := make([]string, len(s.Enum))
+		for i, option := range s.Enum {
+			options[i] = fmt.Sprint(option)
+		}
+		fail("must be one of %s", strings.Join(options, ", "))
+	}
+	return errs
+}
+
+func checkFormat(format, value string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return "must be an RFC 3339 timestamp"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return "must be an email address"
+		}
+	}
+	return ""
+}
+
+func inEnum(options []any, value any) bool {
+	for _, option := range options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	searchusecase "backoffice/backend/internal/usecase/search"
+)
+
+// SetSearchService enables GET /search; without it the route answers 404.
+func (s *Server) SetSearchService(search *searchusecase.Service) {
+	s.searchService = search
+}
+
+// handleSearch serves GET /search?q=&limit=&groups=, the admin UI's global
+// search bar: the best matches per group, for typeahead.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	if s.searchService == nil {
+		writeError(w, http.StatusNotFound, "search is not configured")
+		return
+	}
+	query := r.URL.Query()
+	q := searchusecase.Query{
+		Text:   query.Get("q"),
+		Groups: splitList(query.Get("groups")),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, searchusecase.ErrInvalidLimit.Error())
+			return
+		}
+		q.Limit = limit
+	}
+
+	results, err := s.searchService.Search(r.Context(), q)
+	if err != nil {
+		switch {
+		case errors.Is(err, searchusecase.ErrQueryTooShort),
+			errors.Is(err, searchusecase.ErrInvalidLimit),
+			errors.Is(err, searchusecase.ErrUnknownGroup):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"query": q.Text, "results": results})
+}
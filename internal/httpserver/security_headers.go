@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"backoffice/backend/internal/config"
+)
+
+// securityHeaders are the headers withSecurityHeaders adds, rendered once
+// per configuration.
+type securityHeaders struct {
+	names  []string
+	values []string
+}
+
+func newSecurityHeaders(cfg config.SecurityHeadersConfig) *securityHeaders {
+	h := &securityHeaders{}
+	if !cfg.Enabled {
+		return h
+	}
+	add := func(name, value string) {
+		h.names = append(h.names, name)
+		h.values = append(h.values, value)
+	}
+	if cfg.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge.Seconds()), 10)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		add("Strict-Transport-Security", hsts)
+	}
+	add("X-Content-Type-Options", "nosniff")
+	add("X-Frame-Options", "DENY")
+	add("Referrer-Policy", cfg.ReferrerPolicy)
+	if cfg.ContentSecurityPolicy != "" {
+		add("Content-Security-Policy", cfg.ContentSecurityPolicy)
+	}
+	return h
+}
+
+// withSecurityHeaders adds the configured security headers to every
+// response before the handler runs, so error responses from any middleware
+// carry them and a handler can still replace one.
+func withSecurityHeaders(next http.Handler, current *atomic.Pointer[securityHeaders]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := current.Load()
+		header := w.Header()
+		for i, name := range h.names {
+			header.Set(name, h.values[i])
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,65 +2,267 @@ package httpserver
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"backoffice/backend/internal/config"
+	"backoffice/backend/internal/i18n"
+	activityusecase "backoffice/backend/internal/usecase/activity"
+	approvalusecase "backoffice/backend/internal/usecase/approval"
 	authusecase "backoffice/backend/internal/usecase/auth"
+	backupusecase "backoffice/backend/internal/usecase/backup"
+	categoryusecase "backoffice/backend/internal/usecase/category"
+	consentusecase "backoffice/backend/internal/usecase/consent"
+	customerusecase "backoffice/backend/internal/usecase/customer"
+	integrationusecase "backoffice/backend/internal/usecase/integration"
+	invoiceusecase "backoffice/backend/internal/usecase/invoice"
+	notificationusecase "backoffice/backend/internal/usecase/notification"
+	orderusecase "backoffice/backend/internal/usecase/order"
+	paymentusecase "backoffice/backend/internal/usecase/payment"
+	privacyusecase "backoffice/backend/internal/usecase/privacy"
 	productusecase "backoffice/backend/internal/usecase/product"
+	promotionusecase "backoffice/backend/internal/usecase/promotion"
+	quotausecase "backoffice/backend/internal/usecase/quota"
+	retentionusecase "backoffice/backend/internal/usecase/retention"
+	returnsusecase "backoffice/backend/internal/usecase/returns"
+	searchusecase "backoffice/backend/internal/usecase/search"
+	shipmentusecase "backoffice/backend/internal/usecase/shipment"
+	stocksyncusecase "backoffice/backend/internal/usecase/stocksync"
+	trashusecase "backoffice/backend/internal/usecase/trash"
 	userusecase "backoffice/backend/internal/usecase/user"
+	viewusecase "backoffice/backend/internal/usecase/view"
+	watchusecase "backoffice/backend/internal/usecase/watch"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
 )
 
-// Server wraps the HTTP server lifecycle.
+// Server wraps the HTTP server lifecycle. The public API can listen on several
+// addresses at once and an optional internal listener serves operational
+// endpoints (health, metrics) that should not be exposed publicly.
 type Server struct {
-	httpServer     *http.Server
-	router         *http.ServeMux
-	authService    *authusecase.Service
-	productService *productusecase.Service
-	userService    *userusecase.Service
-	allowedOrigins []string
-	addr           string
+	httpServer          *http.Server
+	adminServer         *http.Server
+	router              *http.ServeMux
+	adminRouter         *http.ServeMux
+	authService         *authusecase.Service
+	productService      *productusecase.Service
+	categoryService     *categoryusecase.Service
+	userService         *userusecase.Service
+	webhookService      *webhookusecase.Service
+	trashService        *trashusecase.Service
+	searchService       *searchusecase.Service
+	activityService     *activityusecase.Service
+	backupService       *backupusecase.Service
+	integrationService  *integrationusecase.Service
+	syncService         *stocksyncusecase.Service
+	notificationService *notificationusecase.Service
+	retentionService    *retentionusecase.Service
+	approvalService     *approvalusecase.Service
+	orderService        *orderusecase.Service
+	invoiceService      *invoiceusecase.Service
+	paymentService      *paymentusecase.Service
+	shipmentService     *shipmentusecase.Service
+	promotionService    *promotionusecase.Service
+	returnService       *returnsusecase.Service
+	customerService     *customerusecase.Service
+	viewService         *viewusecase.Service
+	watchService        *watchusecase.Service
+	quotaService        *quotausecase.Service
+	privacyService      *privacyusecase.Service
+	consentService      *consentusecase.Service
+	timeouts            *timeoutPolicy
+	cache               *responseCache
+	recordings          *requestRecorder
+	cors                atomic.Pointer[corsPolicy]
+	proxies             atomic.Pointer[proxyTrust]
+	securityHeaders     atomic.Pointer[securityHeaders]
+	logLevel            *slog.LevelVar
+	limiter             *rateLimiter
+	shedder             *loadShedder
+	flags               atomic.Pointer[map[string]bool]
+	openAPI             []byte
+	events              *eventHub
+	publishChange       ChangePublisher
+	reloadHooks         []func(config.Config)
+	readinessChecks     []namedCheck
+	migrationCheck      MigrationCheck
+	startedAt           time.Time
+	draining            atomic.Bool
+	listenAddrs         []string
+	adminAddrs          []string
+	socketMode          os.FileMode
+	slos                []config.SLOTarget
 }
 
 // NewServer constructs a new Server with configured dependencies.
-func NewServer(cfg config.Config, authService *authusecase.Service, userService *userusecase.Service, productService *productusecase.Service) *Server {
+func NewServer(cfg config.Config, authService *authusecase.Service, userService *userusecase.Service, productService *productusecase.Service, categoryService *categoryusecase.Service, webhookService *webhookusecase.Service, trashService *trashusecase.Service) *Server {
 	mux := http.NewServeMux()
-	addr := cfg.HTTPPort
-	if !strings.Contains(addr, ":") {
-		addr = ":" + addr
-	}
-
-	handler := withLogging(withCORS(mux, cfg.AllowedOrigins))
+	adminMux := http.NewServeMux()
 
+	timeouts := &timeoutPolicy{
+		read:         cfg.ReadRequestTimeout,
+		write:        cfg.WriteRequestTimeout,
+		long:         cfg.LongRequestTimeout,
+		serverWrite:  time.Duration(cfg.WriteTimeoutSec) * time.Second,
+		longPrefixes: cfg.LongRequestPaths,
+	}
 	srv := &Server{
 		httpServer: &http.Server{
-			Handler:      handler,
 			ReadTimeout:  time.Duration(cfg.ReadTimeoutSec) * time.Second,
 			WriteTimeout: time.Duration(cfg.WriteTimeoutSec) * time.Second,
 			IdleTimeout:  time.Duration(cfg.IdleTimeoutSec) * time.Second,
 		},
-		router:         mux,
-		authService:    authService,
-		userService:    userService,
-		productService: productService,
-		allowedOrigins: cfg.AllowedOrigins,
-		addr:           addr,
+		adminServer: &http.Server{
+			Handler:      withRequestID(withErrorScope(withRecovery(adminMux))),
+			ReadTimeout:  time.Duration(cfg.ReadTimeoutSec) * time.Second,
+			WriteTimeout: time.Duration(cfg.WriteTimeoutSec) * time.Second,
+			IdleTimeout:  time.Duration(cfg.IdleTimeoutSec) * time.Second,
+		},
+		router:          mux,
+		adminRouter:     adminMux,
+		authService:     authService,
+		userService:     userService,
+		productService:  productService,
+		categoryService: categoryService,
+		webhookService:  webhookService,
+		trashService:    trashService,
+		timeouts:        timeouts,
+		cache:           newResponseCache(cfg.ResponseCacheTTLs),
+		recordings:      newRequestRecorder(cfg.Recording),
+		logLevel:        new(slog.LevelVar),
+		limiter:         newRateLimiter(cfg.RateLimit),
+		shedder:         &loadShedder{},
+		events:          newEventHub(),
+		listenAddrs:     cfg.ListenAddrs,
+		adminAddrs:      cfg.AdminAddrs,
+		socketMode:      cfg.UnixSocketMode,
+		slos:            cfg.Alerts.SLOs,
+		startedAt:       time.Now(),
 	}
-	srv.httpServer.Addr = addr
+	srv.applyDynamicConfig(cfg)
 	srv.registerRoutes()
+	srv.registerAdminRoutes()
+
+	// The security section of the served document is generated from the
+	// route registry; a path the registry does not serve is a build bug.
+	openAPI, err := documentRoutes(openAPIDocument, srv.routes())
+	if err != nil {
+		panic(err)
+	}
+	srv.openAPI = openAPI
+
+	var handler http.Handler = mux
+	if cfg.SchemaValidation {
+		handler = withSchemaValidation(handler, mustLoadAPISpec(srv.openAPI), !cfg.IsProduction(), timeouts)
+	}
+	handler = withTimeout(handler, timeouts)
+	handler = withLoadShedding(handler, srv.shedder)
+	handler = withRateLimit(handler, srv.limiter)
+	handler = withCORS(handler, &srv.cors)
+	handler = withRecovery(handler)
+	handler = withErrorScope(handler)
+	handler = withTimezones(handler)
+	handler = withLocalization(handler, i18n.Default())
+	handler = withRecording(handler, srv.recordings)
+	handler = withLogging(handler, newAccessLogger(cfg.AccessLog, srv.logLevel))
+	handler = withClientIP(handler, &srv.proxies)
+	handler = withSecurityHeaders(handler, &srv.securityHeaders)
+	handler = withRequestID(handler)
+	srv.httpServer.Handler = handler
 	return srv
 }
 
-// Start bootstraps the HTTP server on the provided address.
+// Start opens every configured listener and serves until all of them are
+// closed. It returns http.ErrServerClosed after a clean Shutdown, or the first
+// error encountered by any listener.
 func (s *Server) Start() error {
-	s.httpServer.Addr = s.addr
-	return s.httpServer.ListenAndServe()
+	type binding struct {
+		server   *http.Server
+		listener net.Listener
+	}
+
+	var bindings []binding
+	closeAll := func() {
+		for _, b := range bindings {
+			b.listener.Close()
+		}
+	}
+	for _, addr := range s.listenAddrs {
+		ln, err := listen(addr, s.socketMode)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		bindings = append(bindings, binding{server: s.httpServer, listener: ln})
+	}
+	for _, addr := range s.adminAddrs {
+		ln, err := listen(addr, s.socketMode)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		bindings = append(bindings, binding{server: s.adminServer, listener: ln})
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, b := range bindings {
+		wg.Add(1)
+		go func(b binding) {
+			defer wg.Done()
+			err := b.server.Serve(b.listener)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				once.Do(func() {
+					firstErr = err
+					// One listener failing takes the others down with it so
+					// the process exits instead of running half-bound.
+					go s.Shutdown(context.Background())
+				})
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return http.ErrServerClosed
+}
+
+// Drain makes /readyz fail from now on while requests keep being served, so
+// load balancers stop routing here before Shutdown closes the listeners.
+func (s *Server) Drain() {
+	s.draining.Store(true)
 }
 
-// Shutdown gracefully stops the HTTP server.
+// Shutdown gracefully stops the public and internal listeners. Readiness
+// turns unhealthy first so probes stop routing traffic here.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	s.draining.Store(true)
+	return errors.Join(s.httpServer.Shutdown(ctx), s.adminServer.Shutdown(ctx))
+}
+
+// handleLongRunning registers a route that is allowed to run for the long
+// request timeout, e.g. imports and exports.
+func (s *Server) handleLongRunning(pattern string, handler http.Handler) {
+	s.timeouts.longPrefixes = append(s.timeouts.longPrefixes, pattern)
+	s.router.Handle(pattern, handler)
+}
+
+// handleStreaming registers a long-lived streaming route (server-sent events)
+// that is exempt from request timeouts.
+func (s *Server) handleStreaming(pattern string, handler http.Handler) {
+	s.timeouts.streamPrefixes = append(s.timeouts.streamPrefixes, pattern)
+	s.router.Handle(pattern, handler)
 }
 
 // Router exposes the underlying ServeMux so routes can be registered.
@@ -68,7 +270,16 @@ func (s *Server) Router() *http.ServeMux {
 	return s.router
 }
 
-// Addr returns the configured network address for the HTTP server.
+// Addr returns the configured network addresses for the HTTP server.
 func (s *Server) Addr() string {
-	return s.addr
+	addr := strings.Join(s.listenAddrs, ", ")
+	if len(s.adminAddrs) > 0 {
+		addr += " (internal: " + strings.Join(s.adminAddrs, ", ") + ")"
+	}
+	return addr
+}
+
+// AdminRouter exposes the ServeMux backing the internal listener.
+func (s *Server) AdminRouter() *http.ServeMux {
+	return s.adminRouter
 }
@@ -0,0 +1,74 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/session"
+)
+
+// handleLogout serves POST /auth/logout, revoking the bearer token's session.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	token := extractBearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "authorization token required")
+		return
+	}
+	if err := s.authService.Logout(r.Context(), token); err != nil {
+		writeSessionError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMySessions serves GET /users/me/sessions and
+// DELETE /users/me/sessions/{id}.
+func (s *Server) handleMySessions(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/users/me/sessions"), "/")
+
+	if id == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		sessions, err := s.authService.Sessions(r.Context(), user.ID)
+		if err != nil {
+			writeSessionError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"sessions": sessions})
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		writeMethodNotAllowed(w, http.MethodDelete)
+		return
+	}
+	if err := s.authService.RevokeSession(r.Context(), user.ID, id); err != nil {
+		writeSessionError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeSessionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, authdomain.ErrSessionsUnsupported):
+		writeError(w, http.StatusNotImplemented, err.Error())
+	case errors.Is(err, session.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
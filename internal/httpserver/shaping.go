@@ -0,0 +1,302 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	categorydomain "backoffice/backend/internal/domain/category"
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// errShape marks fields or expand parameters that cannot be served.
+var errShape = errors.New("invalid response shape")
+
+// responseShape is the projection a client asked for with the fields and
+// expand query parameters. The zero value leaves responses untouched, so
+// handlers pay nothing unless a client opts in.
+type responseShape struct {
+	// fields is nil when every field is wanted.
+	fields    fieldSet
+	expand    []string
+	expanders map[string]expander
+}
+
+// fieldSet is a tree of selected JSON properties, keyed in lower case so
+// clients need not match each resource's naming; "category.name" selects
+// name inside category. A nil subtree selects the whole value.
+type fieldSet map[string]fieldSet
+
+// expander loads one related resource for each of objects, given their
+// encoded fields. It is called once per response with every object in it,
+// so a list resolves a relation with one lookup rather than one per item.
+// The result is aligned with objects; a nil entry means there is nothing to
+// embed, and the property is then left out.
+type expander func(ctx context.Context, objects []map[string]any) ([]any, error)
+
+// parseShape reads ?fields=a,b.c and ?expand=x,y. Expanded resources are
+// always part of the response, even when fields does not name them.
+func parseShape(r *http.Request, expanders map[string]expander) (responseShape, error) {
+	query := r.URL.Query()
+	shape := responseShape{expanders: expanders}
+	for _, name := range splitList(query.Get("expand")) {
+		if _, ok := expanders[name]; !ok {
+			return responseShape{}, fmt.Errorf("%w: cannot expand %q (supported: %s)", errShape, name, expansionNames(expanders))
+		}
+		shape.expand = append(shape.expand, name)
+	}
+	if raw := query.Get("fields"); raw != "" {
+		shape.fields = fieldSet{}
+		for _, path := range splitList(raw) {
+			shape.fields.add(strings.ToLower(path))
+		}
+		for _, name := range shape.expand {
+			if _, ok := shape.fields[strings.ToLower(name)]; !ok {
+				shape.fields[strings.ToLower(name)] = nil
+			}
+		}
+	}
+	return shape, nil
+}
+
+func (s responseShape) empty() bool {
+	return s.fields == nil && len(s.expand) == 0
+}
+
+// object projects a single resource.
+func (s responseShape) object(ctx context.Context, v any) (any, error) {
+	if s.empty() {
+		return v, nil
+	}
+	shaped, err := s.shape(ctx, []any{v})
+	if err != nil {
+		return nil, err
+	}
+	return shaped[0], nil
+}
+
+// shapeEach projects every item of a list response.
+func shapeEach[T any](ctx context.Context, s responseShape, items []T) (any, error) {
+	if s.empty() {
+		return items, nil
+	}
+	values := make([]any, len(items))
+	for i, item := range items {
+		values[i] = item
+	}
+	return s.shape(ctx, values)
+}
+
+// shape decodes values and runs each expander once over all of them before
+// projecting the fields.
+func (s responseShape) shape(ctx context.Context, values []any) ([]any, error) {
+	shaped := make([]any, len(values))
+	var objects []map[string]any
+	for i, v := range values {
+		encoded, err := decoded(v)
+		if err != nil {
+			return nil, err
+		}
+		shaped[i] = encoded
+		if object, ok := encoded.(map[string]any); ok {
+			objects = append(objects, object)
+		}
+	}
+	if len(objects) == 0 {
+		return shaped, nil
+	}
+	for _, name := range s.expand {
+		related, err := s.expanders[name](ctx, objects)
+		if err != nil {
+			return nil, err
+		}
+		for i, object := range objects {
+			if related[i] == nil {
+				continue
+			}
+			// Decoded like the object itself so fields can reach inside.
+			if object[name], err = decoded(related[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.fields != nil {
+		for i, v := range shaped {
+			if object, ok := v.(map[string]any); ok {
+				shaped[i] = s.fields.project(object)
+			}
+		}
+	}
+	return shaped, nil
+}
+
+// decoded returns v as encoding/json would decode its JSON encoding: maps,
+// slices and scalars.
+func decoded(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+func (f fieldSet) add(path string) {
+	head, rest, nested := strings.Cut(path, ".")
+	if head == "" {
+		return
+	}
+	sub, seen := f[head]
+	if !nested || rest == "" {
+		f[head] = nil
+		return
+	}
+	if seen && sub == nil {
+		// The whole value is already selected.
+		return
+	}
+	if sub == nil {
+		sub = fieldSet{}
+		f[head] = sub
+	}
+	sub.add(rest)
+}
+
+func (f fieldSet) project(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(f))
+		for key, value := range v {
+			sub, ok := f[strings.ToLower(key)]
+			if !ok {
+				continue
+			}
+			if sub == nil {
+				out[key] = value
+			} else {
+				out[key] = sub.project(value)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = f.project(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// productExpanders embeds a product's category and active reservations,
+// each with one lookup per response.
+func (s *Server) productExpanders() map[string]expander {
+	return map[string]expander{
+		"category": func(ctx context.Context, products []map[string]any) ([]any, error) {
+			categories, err := s.categoryService.GetByIDs(ctx, stringFields(products, "categoryId"))
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]*categorydomain.Category, len(categories))
+			for _, category := range categories {
+				byID[category.ID] = category
+			}
+			related := make([]any, len(products))
+			for i, product := range products {
+				id, _ := product["categoryId"].(string)
+				if category, ok := byID[id]; ok {
+					related[i] = category
+				}
+			}
+			return related, nil
+		},
+		"reservations": func(ctx context.Context, products []map[string]any) ([]any, error) {
+			reservations, err := s.productService.ReservationsFor(ctx, stringFields(products, "id"))
+			if errors.Is(err, productusecase.ErrReservationsUnavailable) {
+				return nil, fmt.Errorf("%w: %v", errShape, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			related := make([]any, len(products))
+			for i, product := range products {
+				id, _ := product["id"].(string)
+				related[i] = reservations[id]
+			}
+			return related, nil
+		},
+	}
+}
+
+// userExpanders embeds a user's active sessions.
+func (s *Server) userExpanders() map[string]expander {
+	return map[string]expander{
+		"sessions": func(ctx context.Context, users []map[string]any) ([]any, error) {
+			sessions, err := s.authService.SessionsFor(ctx, stringFields(users, "id"))
+			if errors.Is(err, authdomain.ErrSessionsUnsupported) {
+				return nil, fmt.Errorf("%w: %v", errShape, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			related := make([]any, len(users))
+			for i, user := range users {
+				id, _ := user["id"].(string)
+				related[i] = sessions[id]
+			}
+			return related, nil
+		},
+	}
+}
+
+// stringFields collects the distinct non-empty string values of key across
+// objects, in order of first appearance.
+func stringFields(objects []map[string]any, key string) []string {
+	var values []string
+	seen := map[string]bool{}
+	for _, object := range objects {
+		if v, _ := object[key].(string); v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// writeShapeError reports a failure to shape a response.
+func writeShapeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errShape) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeInternalError(w, r, err)
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func expansionNames(expanders map[string]expander) string {
+	if len(expanders) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(expanders))
+	for name := range expanders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
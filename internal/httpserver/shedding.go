@@ -0,0 +1,71 @@
+package httpserver
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"backoffice/backend/internal/metrics"
+)
+
+var shedRequests = metrics.Default.NewCounterVec("http_shed_requests_total",
+	"Requests answered 503 because the database pool was saturated, by method.", "method")
+
+// shedExemptPaths are served whatever the pool's state, so probes and
+// scrapes keep working while requests are shed.
+var shedExemptPaths = []string{"/health", "/readyz", "/metrics"}
+
+// loadShedder turns requests away while the database pool is saturated.
+// The threshold is reloadable; zero disables shedding.
+type loadShedder struct {
+	mu         sync.RWMutex
+	threshold  float64
+	saturation func() float64
+}
+
+func (l *loadShedder) configure(threshold float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.threshold = threshold
+}
+
+func (l *loadShedder) setSaturation(fn func() float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.saturation = fn
+}
+
+// shed reports whether a request should be turned away.
+func (l *loadShedder) shed() bool {
+	l.mu.RLock()
+	threshold, saturation := l.threshold, l.saturation
+	l.mu.RUnlock()
+	return threshold > 0 && saturation != nil && saturation() >= threshold
+}
+
+// SetPoolSaturation supplies the database pool's saturation (0 to 1) that
+// DB_POOL_SHED_THRESHOLD is compared against.
+func (s *Server) SetPoolSaturation(fn func() float64) {
+	s.shedder.setSaturation(fn)
+}
+
+func withLoadShedding(next http.Handler, shedder *loadShedder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if shedder.shed() && !shedExempt(r.URL.Path) {
+			shedRequests.Inc(r.Method)
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusServiceUnavailable, "server is busy, retry shortly")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func shedExempt(path string) bool {
+	for _, exempt := range shedExemptPaths {
+		if path == exempt || strings.HasPrefix(path, exempt+"/") {
+			return true
+		}
+	}
+	return false
+}
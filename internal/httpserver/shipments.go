@@ -0,0 +1,122 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	shipmentdomain "backoffice/backend/internal/domain/shipment"
+	shipmentusecase "backoffice/backend/internal/usecase/shipment"
+)
+
+// maxShipmentWebhookBody caps carrier webhooks, which are read in full to
+// check their signature.
+const maxShipmentWebhookBody = 1 << 20
+
+// SetShipmentService enables /orders/{id}/shipments, /shipments and the
+// signed carrier webhooks; without it those endpoints answer 404.
+func (s *Server) SetShipmentService(shipments *shipmentusecase.Service) {
+	s.shipmentService = shipments
+}
+
+// handleOrderShipments serves GET and POST /orders/{id}/shipments.
+func (s *Server) handleOrderShipments(w http.ResponseWriter, r *http.Request, orderID string) {
+	if s.shipmentService == nil {
+		writeError(w, http.StatusNotFound, "shipping is not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		shipments, err := s.shipmentService.List(ctx, orderID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if shipments == nil {
+			shipments = []*shipmentdomain.Shipment{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": shipments})
+	case http.MethodPost:
+		var payload shipmentusecase.CreateInput
+		// The body is optional: without one the default carrier ships a
+		// parcel of the default weight to the default shipping address.
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		if user, ok := currentUserFromContext(ctx); ok {
+			payload.CreatedBy = user.ID
+		}
+		shipment, err := s.shipmentService.Create(ctx, orderID, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, shipment)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleShipmentByID serves GET /shipments/{id}.
+func (s *Server) handleShipmentByID(w http.ResponseWriter, r *http.Request) {
+	if s.shipmentService == nil {
+		writeError(w, http.StatusNotFound, "shipping is not configured")
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/shipments/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	shipment, err := s.shipmentService.Get(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, shipment)
+}
+
+// handleShipmentWebhook serves POST /shipments/webhooks/{carrier}. Carriers
+// carry no bearer token; the carrier checks the payload's signature.
+func (s *Server) handleShipmentWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.shipmentService == nil {
+		writeError(w, http.StatusNotFound, "shipping is not configured")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	carrier := strings.Trim(strings.TrimPrefix(r.URL.Path, "/shipments/webhooks/"), "/")
+	if carrier == "" || strings.Contains(carrier, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxShipmentWebhookBody+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "could not read request body")
+		return
+	}
+	if len(body) > maxShipmentWebhookBody {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	shipment, err := s.shipmentService.Webhook(r.Context(), carrier, body, r.Header)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if shipment == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"received": true})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"received": true, "shipment": shipment})
+}
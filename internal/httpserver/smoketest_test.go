@@ -0,0 +1,65 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backoffice/backend/internal/app/smoketest"
+	consentdomain "backoffice/backend/internal/domain/consent"
+	consentusecase "backoffice/backend/internal/usecase/consent"
+	userusecase "backoffice/backend/internal/usecase/user"
+)
+
+// TestSmokeTest runs the deployment smoke test against a server over the
+// in-memory repositories that requires a consent to register, and checks
+// that it leaves nothing behind.
+func TestSmokeTest(t *testing.T) {
+	srv, _ := newMemoryServer(t)
+	ts := httptest.NewServer(srv.httpServer.Handler)
+	defer ts.Close()
+	ctx := context.Background()
+
+	for _, input := range []consentusecase.PublishInput{
+		{Document: consentdomain.DocumentTerms, Version: "2025-01", Mandatory: true},
+		{Document: consentdomain.DocumentPrivacy, Version: "2025-01"},
+		{Document: consentdomain.DocumentTerms, Version: "2026-01", Mandatory: true},
+	} {
+		if _, err := srv.consentService.Publish(ctx, "", input); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	users, err := srv.userService.List(ctx, userusecase.Filter{})
+	if err != nil || len(users) != 1 {
+		t.Fatalf("List = %d users, %v; want the admin", len(users), err)
+	}
+	current := []consentdomain.Ref{{Document: consentdomain.DocumentTerms, Version: "2026-01"}}
+	if err := srv.consentService.Accept(ctx, users[0].ID, current); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+
+	var log strings.Builder
+	opts := smoketest.Options{
+		BaseURL:       ts.URL,
+		EmailDomain:   "example.com",
+		AdminEmail:    memoryAdminEmail,
+		AdminPassword: memoryAdminPassword,
+		Log:           &log,
+	}
+	if _, err := smoketest.Run(ctx, opts); err != nil {
+		t.Fatalf("Run: %v\n%s", err, log.String())
+	}
+	if users, err := srv.userService.List(ctx, userusecase.Filter{}); err != nil || len(users) != 1 {
+		t.Fatalf("List = %d users, %v; want only the admin left", len(users), err)
+	}
+	if items, err := srv.trashService.List(ctx); err != nil || len(items) != 0 {
+		t.Fatalf("trash = %+v, %v; want the user and product purged", items, err)
+	}
+
+	opts.AdminPassword = ""
+	if steps, err := smoketest.Run(ctx, opts); !errors.Is(err, smoketest.ErrAdminRequired) || len(steps) != 0 {
+		t.Fatalf("Run without an admin = %d steps, %v; want none and %v", len(steps), err, smoketest.ErrAdminRequired)
+	}
+}
@@ -0,0 +1,30 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// handleProductStock serves POST /products/{id}/stock, which adds
+// {"delta": n} to the product's quantity; a negative delta deducts stock.
+// {"unit": "pack"} counts delta in packs rather than base units.
+func (s *Server) handleProductStock(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var payload struct {
+		Delta int    `json:"delta"`
+		Unit  string `json:"unit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	item, err := s.productService.AdjustStock(r.Context(), id, payload.Delta, payload.Unit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, masked(r.Context(), item))
+}
@@ -0,0 +1,100 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	stocksyncdomain "backoffice/backend/internal/domain/stocksync"
+	stocksyncusecase "backoffice/backend/internal/usecase/stocksync"
+)
+
+// SetSyncService enables /admin/sync-runs; without it the routes answer 404.
+func (s *Server) SetSyncService(sync *stocksyncusecase.Service) {
+	s.syncService = sync
+}
+
+// handleSyncRuns serves GET /admin/sync-runs?connector=&limit=, the run
+// history with the configured connectors, and POST /admin/sync-runs
+// {"connector"}, which starts a run now.
+func (s *Server) handleSyncRuns(w http.ResponseWriter, r *http.Request) {
+	if s.syncService == nil {
+		writeError(w, http.StatusNotFound, "product sync is not configured")
+		return
+	}
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		limit := 0
+		if raw := query.Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, stocksyncusecase.ErrInvalidLimit.Error())
+				return
+			}
+			limit = parsed
+		}
+		runs, err := s.syncService.ListRuns(ctx, strings.TrimSpace(query.Get("connector")), limit)
+		if err != nil {
+			if errors.Is(err, stocksyncusecase.ErrInvalidLimit) {
+				writeError(w, http.StatusBadRequest, err.Error())
+			} else {
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": runs, "connectors": s.syncService.Connectors()})
+	case http.MethodPost:
+		var payload struct {
+			Connector string `json:"connector"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		run, err := s.syncService.Trigger(ctx, strings.TrimSpace(payload.Connector))
+		switch {
+		case errors.Is(err, stocksyncusecase.ErrUnknownConnector):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, stocksyncusecase.ErrRunInProgress):
+			writeError(w, http.StatusConflict, err.Error())
+		case err != nil:
+			writeInternalError(w, r, err)
+		default:
+			w.Header().Set("Location", "/admin/sync-runs/"+run.ID)
+			writeJSON(w, http.StatusAccepted, run)
+		}
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleSyncRun serves GET /admin/sync-runs/{id}.
+func (s *Server) handleSyncRun(w http.ResponseWriter, r *http.Request) {
+	if s.syncService == nil {
+		writeError(w, http.StatusNotFound, "product sync is not configured")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/sync-runs/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	run, err := s.syncService.GetRun(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, stocksyncdomain.ErrRunNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+		} else {
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
@@ -0,0 +1,135 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeoutPolicy decides how long a request may run before its context is cancelled.
+type timeoutPolicy struct {
+	read         time.Duration
+	write        time.Duration
+	long         time.Duration
+	serverWrite  time.Duration
+	longPrefixes []string
+	// streamPrefixes are long-lived streaming routes that run without a
+	// deadline and manage their own lifetime.
+	streamPrefixes []string
+}
+
+func (p *timeoutPolicy) isStream(r *http.Request) bool {
+	for _, prefix := range p.streamPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *timeoutPolicy) timeoutFor(r *http.Request) time.Duration {
+	for _, prefix := range p.longPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return p.long
+		}
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return p.read
+	default:
+		return p.write
+	}
+}
+
+// withTimeout bounds every request with a context deadline. Handlers run on the
+// calling goroutine so cancellation propagates to pgx, which aborts in-flight
+// queries server-side; if the deadline fires before a response is committed the
+// client receives 504 instead of whatever error the handler produced.
+func withTimeout(next http.Handler, policy *timeoutPolicy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := policy.timeoutFor(r)
+		if timeout <= 0 || policy.isStream(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if policy.serverWrite > 0 && timeout >= policy.serverWrite {
+			rc := http.NewResponseController(w)
+			_ = rc.SetWriteDeadline(time.Now().Add(timeout + time.Second))
+			_ = rc.SetReadDeadline(time.Now().Add(timeout))
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{ResponseWriter: w, ctx: ctx}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		tw.finish()
+	})
+}
+
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+
+	mu       sync.Mutex
+	wrote    bool
+	timedOut bool
+}
+
+func (tw *timeoutWriter) expired() bool {
+	return errors.Is(tw.ctx.Err(), context.DeadlineExceeded)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.wrote {
+		return
+	}
+	tw.wrote = true
+	if tw.expired() {
+		tw.timedOut = true
+		writeError(tw.ResponseWriter, http.StatusGatewayTimeout, "request timed out")
+		return
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	wrote := tw.wrote
+	tw.mu.Unlock()
+	if !wrote {
+		tw.WriteHeader(http.StatusOK)
+	}
+	tw.mu.Lock()
+	timedOut := tw.timedOut
+	tw.mu.Unlock()
+	if timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+func (tw *timeoutWriter) Flush() {
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+func (tw *timeoutWriter) finish() {
+	tw.mu.Lock()
+	wrote := tw.wrote
+	tw.mu.Unlock()
+	if !wrote && tw.expired() {
+		tw.WriteHeader(http.StatusGatewayTimeout)
+	}
+}
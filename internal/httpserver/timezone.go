@@ -0,0 +1,133 @@
+package httpserver
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	userusecase "backoffice/backend/internal/usecase/user"
+)
+
+// timezoneHeader names the zone a request wants timestamps in; responses
+// echo the zone applied.
+const timezoneHeader = "X-Timezone"
+
+// timestampField matches a JSON property ending in "At" or "_at" whose value
+// is an RFC 3339 timestamp, as encoding/json writes them: createdAt,
+// expiresAt, and the created_at of user responses…
+var timestampField = regexp.MustCompile(`"([A-Za-z0-9_]*(?:At|_at))":"(\d{4}-\d{2}-\d{2}T[^"]+)"`)
+
+// withTimezones renders the timestamps of JSON responses in the zone given
+// by the tz query parameter or the X-Timezone header, falling back to the
+// authenticated user's saved timezone; without either they stay in UTC.
+// Only the rendering changes: handlers, caches and storage see UTC. The tz
+// parameter is removed before routing so it does not split cache entries or
+// trip schema validation.
+func withTimezones(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(timezoneHeader)
+		if query := r.URL.Query(); query.Has("tz") {
+			name = query.Get("tz")
+			query.Del("tz")
+			r.URL.RawQuery = query.Encode()
+		}
+		var location *time.Location
+		if name = strings.TrimSpace(name); name != "" {
+			loaded, err := userusecase.LoadTimezone(name)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			location = loaded
+		}
+
+		tw := &timezoneWriter{ResponseWriter: w, location: location}
+		if location == nil {
+			// Fall back to the saved preference, known once the request
+			// is authenticated.
+			tw.saved = func() string {
+				if info := requestInfoFromContext(r.Context()); info != nil {
+					return info.timezone
+				}
+				return ""
+			}
+		}
+		next.ServeHTTP(tw, r)
+		tw.finish()
+	})
+}
+
+// timezoneWriter buffers successful JSON responses that need their
+// timestamps converted; everything else passes through.
+type timezoneWriter struct {
+	http.ResponseWriter
+	location    *time.Location
+	saved       func() string
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (tw *timezoneWriter) WriteHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	if tw.location == nil && tw.saved != nil {
+		if loaded, err := userusecase.LoadTimezone(tw.saved()); err == nil {
+			tw.location = loaded
+		}
+	}
+	if tw.location != nil && tw.location != time.UTC {
+		tw.Header().Set(timezoneHeader, tw.location.String())
+		if code < http.StatusBadRequest && strings.HasPrefix(tw.Header().Get("Content-Type"), "application/json") {
+			tw.status = code
+			tw.buffering = true
+			return
+		}
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timezoneWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.buffering {
+		return tw.body.Write(b)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+func (tw *timezoneWriter) Flush() {
+	if tw.buffering {
+		return
+	}
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (tw *timezoneWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+func (tw *timezoneWriter) finish() {
+	if !tw.buffering {
+		return
+	}
+	body := timestampField.ReplaceAllFunc(tw.body.Bytes(), func(match []byte) []byte {
+		parts := timestampField.FindSubmatch(match)
+		t, err := time.Parse(time.RFC3339Nano, string(parts[2]))
+		if err != nil {
+			return match
+		}
+		return []byte(`"` + string(parts[1]) + `":"` + t.In(tw.location).Format(time.RFC3339Nano) + `"`)
+	})
+	tw.Header().Del("Content-Length")
+	tw.ResponseWriter.WriteHeader(tw.status)
+	_, _ = tw.ResponseWriter.Write(body)
+}
@@ -0,0 +1,52 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestUserTimestampsInTimezone checks that the snake_case timestamps of user
+// responses are rendered in the requested zone, like the camelCase ones of
+// every other resource.
+func TestUserTimestampsInTimezone(t *testing.T) {
+	srv, token := newMemoryServer(t)
+	handler := srv.httpServer.Handler
+
+	for name, set := range map[string]func(*http.Request){
+		"query":  func(r *http.Request) { r.URL.RawQuery = "tz=Asia/Vientiane" },
+		"header": func(r *http.Request) { r.Header.Set(timezoneHeader, "Asia/Vientiane") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			set(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get(timezoneHeader); got != "Asia/Vientiane" {
+				t.Fatalf("%s = %q, want Asia/Vientiane", timezoneHeader, got)
+			}
+
+			var body struct {
+				Users []map[string]any `json:"users"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding %s: %v", rec.Body, err)
+			}
+			if len(body.Users) == 0 {
+				t.Fatalf("no users in %s", rec.Body)
+			}
+			for _, field := range []string{"created_at", "updated_at"} {
+				value, _ := body.Users[0][field].(string)
+				if !strings.HasSuffix(value, "+07:00") {
+					t.Errorf("%s = %q, want a +07:00 offset", field, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	productdomain "backoffice/backend/internal/domain/product"
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// translateProducts returns items in the locales asked for with ?lang=, or
+// items unchanged when the request asks for none.
+func (s *Server) translateProducts(r *http.Request, items []*productdomain.Product) ([]*productdomain.Product, error) {
+	lang := r.URL.Query().Get("lang")
+	if strings.TrimSpace(lang) == "" {
+		return items, nil
+	}
+	return s.productService.Translate(r.Context(), lang, items)
+}
+
+// translateProduct is translateProducts for a single product.
+func (s *Server) translateProduct(r *http.Request, item *productdomain.Product) (*productdomain.Product, error) {
+	items, err := s.translateProducts(r, []*productdomain.Product{item})
+	if err != nil {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// handleProductTranslations serves GET /products/{id}/translations and
+// PUT and DELETE /products/{id}/translations/{locale}.
+func (s *Server) handleProductTranslations(w http.ResponseWriter, r *http.Request, productID, locale string) {
+	ctx := r.Context()
+	locale = strings.Trim(locale, "/")
+	if locale == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		translations, err := s.productService.ListTranslations(ctx, productID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": translations})
+		return
+	}
+	if strings.Contains(locale, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var payload productusecase.TranslationInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		var userID string
+		if user, ok := currentUserFromContext(ctx); ok {
+			userID = user.ID
+		}
+		translation, err := s.productService.PutTranslation(ctx, productID, locale, userID, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, translation)
+	case http.MethodDelete:
+		if err := s.productService.DeleteTranslation(ctx, productID, locale); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodPut, http.MethodDelete)
+	}
+}
@@ -0,0 +1,77 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	productdomain "backoffice/backend/internal/domain/product"
+	trashdomain "backoffice/backend/internal/domain/trash"
+)
+
+// handleTrash serves GET /admin/trash.
+func (s *Server) handleTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	items, err := s.trashService.List(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// handleTrashItem serves POST /admin/trash/{kind}/{id}/restore and
+// DELETE /admin/trash/{kind}/{id}, which purges the record for good.
+func (s *Server) handleTrashItem(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/trash/"), "/"), "/")
+	if len(segments) < 2 || segments[1] == "" {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	kind, id := segments[0], segments[1]
+
+	switch {
+	case len(segments) == 2:
+		if r.Method != http.MethodDelete {
+			writeMethodNotAllowed(w, http.MethodDelete)
+			return
+		}
+		if err := s.trashService.Purge(r.Context(), kind, id); err != nil {
+			writeTrashError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 3 && segments[2] == "restore":
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		if err := s.trashService.Restore(r.Context(), kind, id); err != nil {
+			writeTrashError(w, r, err)
+			return
+		}
+		if strings.EqualFold(kind, trashdomain.KindProduct) {
+			s.cache.invalidate("/products")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, "resource not found")
+	}
+}
+
+func writeTrashError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, trashdomain.ErrUnknownKind):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, authdomain.ErrUserNotFound), errors.Is(err, productdomain.ErrNotFound):
+		writeError(w, http.StatusNotFound, "no such item in the trash")
+	case errors.Is(err, authdomain.ErrEmailExists), errors.Is(err, productdomain.ErrDuplicateSKU):
+		writeError(w, http.StatusConflict, err.Error()+"; rename the live record before restoring")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
@@ -0,0 +1,43 @@
+package httpserver
+
+import (
+	"time"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+)
+
+// userResponse is the JSON form of a user in auth, user and admin
+// responses. Keeping it apart from the domain struct means the password hash
+// and token version never leave the server, and renaming a Go field cannot
+// change the API.
+type userResponse struct {
+	ID        string              `json:"id"`
+	Email     string              `json:"email"`
+	Name      string              `json:"name"`
+	Role      authdomain.UserRole `json:"role"`
+	Locale    string              `json:"locale,omitempty"`
+	Timezone  string              `json:"timezone,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+func newUserResponse(u *authdomain.User) userResponse {
+	return userResponse{
+		ID:        u.ID,
+		Email:     u.Email,
+		Name:      u.Name,
+		Role:      u.Role,
+		Locale:    u.Locale,
+		Timezone:  u.Timezone,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+func newUserResponses(users []*authdomain.User) []userResponse {
+	out := make([]userResponse, len(users))
+	for i, u := range users {
+		out[i] = newUserResponse(u)
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	viewusecase "backoffice/backend/internal/usecase/view"
+)
+
+// SetViewService enables /users/me/views and ?view= on the product and user
+// lists; without it those answer 404.
+func (s *Server) SetViewService(views *viewusecase.Service) {
+	s.viewService = views
+}
+
+// handleMyViews serves GET and POST /users/me/views and GET, PUT and
+// DELETE /users/me/views/{id}, the caller's own saved list views.
+func (s *Server) handleMyViews(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if s.viewService == nil {
+		writeError(w, http.StatusNotFound, "views are not configured")
+		return
+	}
+	ctx := r.Context()
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/users/me/views"), "/")
+	if id == "" {
+		switch r.Method {
+		case http.MethodGet:
+			views, err := s.viewService.List(ctx, user.ID, r.URL.Query().Get("resource"))
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"items": views})
+		case http.MethodPost:
+			var payload viewusecase.Input
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid JSON payload")
+				return
+			}
+			view, err := s.viewService.Create(ctx, user.ID, payload)
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			writeJSON(w, http.StatusCreated, view)
+		default:
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+	if strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		view, err := s.viewService.Get(ctx, user.ID, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, view)
+	case http.MethodPut:
+		var payload viewusecase.Input
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		view, err := s.viewService.Update(ctx, user.ID, id, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, view)
+	case http.MethodDelete:
+		if err := s.viewService.Delete(ctx, user.ID, id); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// applyView filters and sorts items with the caller's view named by
+// ?view=, comparing them in their JSON form, or returns them unchanged when
+// the request names none. It reports false once it has written an error.
+func applyView[T any](s *Server, w http.ResponseWriter, r *http.Request, resource string, items []T) ([]T, bool) {
+	id := strings.TrimSpace(r.URL.Query().Get("view"))
+	if id == "" {
+		return items, true
+	}
+	if s.viewService == nil {
+		writeError(w, http.StatusNotFound, "views are not configured")
+		return nil, false
+	}
+	var userID string
+	if user, ok := currentUserFromContext(r.Context()); ok {
+		userID = user.ID
+	}
+	view, err := s.viewService.For(r.Context(), userID, id, resource)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return nil, false
+	}
+	type record struct {
+		item   T
+		fields map[string]any
+	}
+	records := make([]record, 0, len(items))
+	for _, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return nil, false
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			writeInternalError(w, r, err)
+			return nil, false
+		}
+		if view.Matches(fields) {
+			records = append(records, record{item: item, fields: fields})
+		}
+	}
+	slices.SortStableFunc(records, func(a, b record) int { return view.Compare(a.fields, b.fields) })
+	matched := make([]T, len(records))
+	for i, rec := range records {
+		matched[i] = rec.item
+	}
+	return matched, true
+}
@@ -0,0 +1,111 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	watchusecase "backoffice/backend/internal/usecase/watch"
+)
+
+// SetWatchService enables /products/{id}/watch, /users/me/watches and
+// /users/me/notifications; without it those answer 404.
+func (s *Server) SetWatchService(watches *watchusecase.Service) {
+	s.watchService = watches
+}
+
+// handleProductWatch serves POST and DELETE /products/{id}/watch, the
+// caller watching and unwatching a product. The body of POST is optional.
+func (s *Server) handleProductWatch(w http.ResponseWriter, r *http.Request, productID string) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if s.watchService == nil {
+		writeError(w, http.StatusNotFound, "product watches are not configured")
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		var payload watchusecase.Input
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		watch, created, err := s.watchService.Watch(r.Context(), user.ID, productID, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, watch)
+	case http.MethodDelete:
+		if err := s.watchService.Unwatch(r.Context(), user.ID, productID); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodPost, http.MethodDelete)
+	}
+}
+
+// handleMyWatches serves GET /users/me/watches, the products the caller
+// watches.
+func (s *Server) handleMyWatches(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if s.watchService == nil {
+		writeError(w, http.StatusNotFound, "product watches are not configured")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	watches, err := s.watchService.List(r.Context(), user.ID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": watches})
+}
+
+// handleMyNotifications serves GET and DELETE /users/me/notifications, the
+// caller's latest watch notifications and clearing them.
+func (s *Server) handleMyNotifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	if s.watchService == nil {
+		writeError(w, http.StatusNotFound, "product watches are not configured")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		notifications, err := s.watchService.Notifications(r.Context(), user.ID)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": notifications})
+	case http.MethodDelete:
+		if err := s.watchService.ClearNotifications(r.Context(), user.ID); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodDelete)
+	}
+}
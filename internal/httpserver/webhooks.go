@@ -0,0 +1,132 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	webhookdomain "backoffice/backend/internal/domain/webhook"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
+)
+
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		items, err := s.webhookService.List(ctx)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		if items == nil {
+			items = []*webhookdomain.Subscription{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"items": items})
+	case http.MethodPost:
+		var payload webhookusecase.CreateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		sub, err := s.webhookService.Create(ctx, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		// The secret is only ever shown in this response.
+		writeJSON(w, http.StatusCreated, map[string]any{"webhook": sub, "secret": sub.Secret})
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleWebhookByID serves /admin/webhooks/{id}, .../deliveries and
+// .../deliveries/{deliveryId}/redeliver.
+func (s *Server) handleWebhookByID(w http.ResponseWriter, r *http.Request) {
+	remainder := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/webhooks/"), "/")
+	segments := strings.Split(remainder, "/")
+	id := segments[0]
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "webhook id required")
+		return
+	}
+
+	switch {
+	case len(segments) == 1:
+		s.handleWebhook(w, r, id)
+	case len(segments) == 2 && segments[1] == "deliveries":
+		s.handleWebhookDeliveries(w, r, id)
+	case len(segments) == 4 && segments[1] == "deliveries" && segments[3] == "redeliver":
+		s.handleWebhookRedeliver(w, r, id, segments[2])
+	default:
+		writeError(w, http.StatusNotFound, "resource not found")
+	}
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		sub, err := s.webhookService.Get(ctx, id)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, sub)
+	case http.MethodPut, http.MethodPatch:
+		var payload webhookusecase.UpdateInput
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON payload")
+			return
+		}
+		sub, err := s.webhookService.Update(ctx, id, payload)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		if payload.RotateSecret {
+			writeJSON(w, http.StatusOK, map[string]any{"webhook": sub, "secret": sub.Secret})
+			return
+		}
+		writeJSON(w, http.StatusOK, sub)
+	case http.MethodDelete:
+		if err := s.webhookService.Delete(ctx, id); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func (s *Server) handleWebhookDeliveries(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	items, err := s.webhookService.Deliveries(r.Context(), id, limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if items == nil {
+		items = []*webhookdomain.Delivery{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) handleWebhookRedeliver(w http.ResponseWriter, r *http.Request, id, deliveryID string) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	delivery, err := s.webhookService.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, delivery)
+}
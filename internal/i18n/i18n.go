@@ -0,0 +1,135 @@
+// Package i18n translates API error messages. Each locales/<language>.json
+// maps error codes to messages; the English catalog also identifies which
+// code a message has, since handlers report errors by their English text.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// DefaultLanguage is used when the caller prefers no supported language,
+// and for codes a catalog does not translate.
+const DefaultLanguage = "en"
+
+// Catalog holds the translations of every supported language.
+type Catalog struct {
+	messages  map[string]map[string]string
+	codes     map[string]string
+	languages []string
+}
+
+// Load reads the embedded catalogs.
+func Load() (*Catalog, error) {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalog{messages: map[string]map[string]string{}, codes: map[string]string{}}
+	for _, entry := range entries {
+		raw, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", entry.Name(), err)
+		}
+		language := strings.TrimSuffix(entry.Name(), ".json")
+		c.messages[language] = messages
+		c.languages = append(c.languages, language)
+	}
+	if _, ok := c.messages[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("no %s catalog", DefaultLanguage)
+	}
+	for code, message := range c.messages[DefaultLanguage] {
+		c.codes[message] = code
+	}
+	sort.Strings(c.languages)
+	return c, nil
+}
+
+// Default returns the embedded catalogs, loaded once. They ship with the
+// binary, so failing to load them is a programming error.
+var Default = sync.OnceValue(func() *Catalog {
+	c, err := Load()
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	return c
+})
+
+// Languages lists the supported languages.
+func (c *Catalog) Languages() []string {
+	return c.languages
+}
+
+// Supports reports whether language has a catalog.
+func (c *Catalog) Supports(language string) bool {
+	_, ok := c.messages[language]
+	return ok
+}
+
+// Negotiate picks the response language: preferred (a user's saved locale)
+// when supported, otherwise the best supported match in an Accept-Language
+// header, otherwise DefaultLanguage. Regional variants match their base
+// language, so "lo-LA" selects "lo".
+func (c *Catalog) Negotiate(preferred, acceptLanguage string) string {
+	if language := baseLanguage(preferred); c.Supports(language) {
+		return language
+	}
+	best, bestQ := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if language := baseLanguage(tag); q > bestQ && c.Supports(language) {
+			best, bestQ = language, q
+		}
+	}
+	return best
+}
+
+// Localize returns the code of an English error message and its text in
+// language. A message that extends a known one with ": detail" takes that
+// message's code and keeps the detail untranslated. Unknown messages have
+// no code and are returned unchanged.
+func (c *Catalog) Localize(language, message string) (code, text string) {
+	known, detail := message, ""
+	code, ok := c.codes[known]
+	for i := len(message); !ok; {
+		i = strings.LastIndex(message[:i], ": ")
+		if i < 0 {
+			return "", message
+		}
+		known, detail = message[:i], message[i:]
+		code, ok = c.codes[known]
+	}
+	translated, ok := c.messages[language][code]
+	if !ok {
+		translated = known
+	}
+	return code, translated + detail
+}
+
+func baseLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	base, _, _ := strings.Cut(tag, "-")
+	base, _, _ = strings.Cut(base, "_")
+	return base
+}
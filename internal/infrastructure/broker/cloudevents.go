@@ -0,0 +1,117 @@
+// Package broker publishes domain events to external message brokers (NATS
+// or Kafka) as CloudEvents so other services and the data warehouse can
+// consume them.
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/errreport"
+)
+
+// TypePrefix namespaces CloudEvents types, e.g. com.backoffice.product.created.
+const TypePrefix = "com.backoffice."
+
+// CloudEvent is the structured-mode CloudEvents 1.0 JSON envelope.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            any       `json:"data"`
+}
+
+// ToCloudEvent wraps a domain event in the CloudEvents envelope.
+func ToCloudEvent(e event.Event, source string) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              e.ID,
+		Source:          source,
+		Type:            TypePrefix + e.Type,
+		Subject:         e.Subject,
+		Time:            e.OccurredAt,
+		DataContentType: "application/json",
+		Data:            e.Data,
+	}
+}
+
+// Sender delivers one encoded CloudEvent to a broker.
+type Sender interface {
+	Send(ctx context.Context, eventType, key string, payload []byte) error
+	Close() error
+}
+
+// Publisher is an event.Publisher that encodes events as CloudEvents and
+// hands them to a Sender on a background goroutine, so request handlers
+// never wait on the broker. When the queue is full events are dropped and
+// logged.
+type Publisher struct {
+	sender  Sender
+	source  string
+	queue   chan event.Event
+	done    chan struct{}
+	closeMu sync.Once
+}
+
+// NewPublisher starts a publisher with room for queueSize pending events.
+func NewPublisher(sender Sender, source string, queueSize int) *Publisher {
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+	p := &Publisher{
+		sender: sender,
+		source: source,
+		queue:  make(chan event.Event, queueSize),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+var _ event.Publisher = (*Publisher)(nil)
+
+// Publish queues e without blocking.
+func (p *Publisher) Publish(_ context.Context, e event.Event) {
+	select {
+	case p.queue <- e:
+	default:
+		log.Printf("event broker: queue full, dropping %s %s", e.Type, e.ID)
+	}
+}
+
+// Close stops accepting events, sends those still queued (until ctx is
+// done) and closes the sender.
+func (p *Publisher) Close(ctx context.Context) error {
+	p.closeMu.Do(func() { close(p.queue) })
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+	}
+	return p.sender.Close()
+}
+
+func (p *Publisher) run() {
+	defer close(p.done)
+	for e := range p.queue {
+		payload, err := json.Marshal(ToCloudEvent(e, p.source))
+		if err != nil {
+			log.Printf("event broker: encoding %s: %v", e.ID, err)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = p.sender.Send(ctx, e.Type, e.Subject, payload)
+		cancel()
+		if err != nil {
+			errreport.Error(context.Background(), fmt.Errorf("event broker: publishing %s %s: %w", e.Type, e.ID, err), map[string]string{"job": "event-broker"})
+		}
+	}
+}
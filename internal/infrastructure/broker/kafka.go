@@ -0,0 +1,68 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// KafkaRESTSender produces records through a Kafka REST Proxy (Confluent
+// REST Proxy v2 API), keyed by the event subject so changes to one aggregate
+// stay ordered within a partition.
+type KafkaRESTSender struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaRESTSender targets topic on the proxy at baseURL.
+func NewKafkaRESTSender(baseURL, topic string) (*KafkaRESTSender, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid Kafka REST proxy URL %q", baseURL)
+	}
+	if strings.TrimSpace(topic) == "" {
+		return nil, fmt.Errorf("kafka topic is required")
+	}
+	return &KafkaRESTSender{
+		endpoint: strings.TrimRight(baseURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send produces a single record.
+func (k *KafkaRESTSender) Send(ctx context.Context, _, key string, payload []byte) error {
+	body, err := json.Marshal(map[string]any{
+		"records": []map[string]any{{"key": key, "value": json.RawMessage(payload)}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("kafka rest proxy responded %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close is a no-op; the HTTP client holds no dedicated resources.
+func (k *KafkaRESTSender) Close() error {
+	return nil
+}
@@ -0,0 +1,171 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSSender publishes to a NATS server using the core text protocol.
+// Subjects are "<prefix>.<event type>", e.g. backoffice.product.created.
+// Publishing is fire-and-forget (core NATS, not JetStream); use a JetStream
+// stream bound to the subjects for durability.
+type NATSSender struct {
+	addr    string
+	connect []byte
+	prefix  string
+
+	mu   sync.Mutex
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+// NewNATSSender parses rawURL (nats://[user:pass@|token@]host:port) and
+// connects lazily on the first send.
+func NewNATSSender(rawURL, subjectPrefix string) (*NATSSender, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "nats" && u.Scheme != "tcp") {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	opts := map[string]any{"verbose": false, "pedantic": false, "name": "backoffice-api", "lang": "go", "version": "1"}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			opts["user"] = u.User.Username()
+			opts["pass"] = pass
+		} else {
+			opts["auth_token"] = u.User.Username()
+		}
+	}
+	connect, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSSender{
+		addr:    host,
+		connect: connect,
+		prefix:  strings.Trim(subjectPrefix, "."),
+	}, nil
+}
+
+// Send publishes payload, reconnecting once if the connection was lost.
+func (n *NATSSender) Send(ctx context.Context, eventType, _ string, payload []byte) error {
+	subject := eventType
+	if n.prefix != "" {
+		subject = n.prefix + "." + eventType
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	err := n.publish(ctx, subject, payload)
+	if err != nil {
+		n.resetLocked()
+		err = n.publish(ctx, subject, payload)
+	}
+	return err
+}
+
+func (n *NATSSender) publish(ctx context.Context, subject string, payload []byte) error {
+	if n.conn == nil {
+		if err := n.dialLocked(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = n.conn.SetWriteDeadline(deadline)
+	}
+	fmt.Fprintf(n.w, "PUB %s %d\r\n", subject, len(payload))
+	n.w.Write(payload)
+	n.w.WriteString("\r\n")
+	return n.w.Flush()
+}
+
+func (n *NATSSender) dialLocked(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected NATS greeting %q", strings.TrimSpace(line))
+	}
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "CONNECT %s\r\nPING\r\n", n.connect)
+	if err := w.Flush(); err != nil {
+		conn.Close()
+		return err
+	}
+	// The server answers PONG once CONNECT is accepted, or -ERR.
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		switch {
+		case strings.HasPrefix(line, "PONG"):
+			_ = conn.SetReadDeadline(time.Time{})
+			n.conn, n.w = conn, w
+			go n.readLoop(conn, r)
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			conn.Close()
+			return errors.New("NATS: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// readLoop answers server PINGs so the connection is not considered stale.
+func (n *NATSSender) readLoop(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			n.mu.Lock()
+			if n.conn == conn {
+				n.resetLocked()
+			}
+			n.mu.Unlock()
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			n.mu.Lock()
+			if n.conn == conn {
+				n.w.WriteString("PONG\r\n")
+				_ = n.w.Flush()
+			}
+			n.mu.Unlock()
+		}
+	}
+}
+
+func (n *NATSSender) resetLocked() {
+	if n.conn != nil {
+		n.conn.Close()
+	}
+	n.conn, n.w = nil, nil
+}
+
+// Close drops the connection.
+func (n *NATSSender) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.resetLocked()
+	return nil
+}
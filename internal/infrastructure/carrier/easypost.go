@@ -0,0 +1,299 @@
+package carrier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/shipment"
+)
+
+// easyPostAPI is EasyPost's REST API.
+const easyPostAPI = "https://api.easypost.com"
+
+// EasyPostSignatureHeader carries EasyPost's webhook signature,
+// "hmac-sha256-hex=<hex HMAC-SHA256 of the body>".
+const EasyPostSignatureHeader = "X-Hmac-Signature"
+
+// gramsPerOunce converts parcel weights to the ounces EasyPost expects.
+const gramsPerOunce = 28.349523125
+
+// easyPostStatuses maps EasyPost tracker statuses to shipment statuses.
+var easyPostStatuses = map[string]string{
+	"unknown":              domain.StatusLabelCreated,
+	"pre_transit":          domain.StatusLabelCreated,
+	"in_transit":           domain.StatusInTransit,
+	"out_for_delivery":     domain.StatusOutForDelivery,
+	"available_for_pickup": domain.StatusOutForDelivery,
+	"delivered":            domain.StatusDelivered,
+	"return_to_sender":     domain.StatusReturned,
+	"failure":              domain.StatusException,
+	"error":                domain.StatusException,
+	"cancelled":            domain.StatusException,
+}
+
+// EasyPost buys labels from the carriers connected to an EasyPost account.
+// A shipment's reference is its EasyPost tracker id.
+type EasyPost struct {
+	apiKey        string
+	webhookSecret string
+	apiURL        string
+	client        *http.Client
+}
+
+var _ domain.WebhookCarrier = (*EasyPost)(nil)
+
+// NewEasyPost constructs a carrier using the account's API key, checking
+// webhooks against the webhook's secret.
+func NewEasyPost(apiKey, webhookSecret string) *EasyPost {
+	return &EasyPost{
+		apiKey:        apiKey,
+		webhookSecret: webhookSecret,
+		apiURL:        easyPostAPI,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name is "easypost".
+func (e *EasyPost) Name() string {
+	return "easypost"
+}
+
+type easyPostAddress struct {
+	Name    string `json:"name,omitempty"`
+	Street1 string `json:"street1"`
+	Street2 string `json:"street2,omitempty"`
+	City    string `json:"city"`
+	State   string `json:"state,omitempty"`
+	Zip     string `json:"zip,omitempty"`
+	Country string `json:"country"`
+	Phone   string `json:"phone,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+func toEasyPostAddress(a domain.Address) easyPostAddress {
+	return easyPostAddress{
+		Name:    a.Name,
+		Street1: a.Line1,
+		Street2: a.Line2,
+		City:    a.City,
+		State:   a.Region,
+		Zip:     a.PostalCode,
+		Country: a.Country,
+		Phone:   a.Phone,
+		Email:   a.Email,
+	}
+}
+
+type easyPostRate struct {
+	ID      string `json:"id"`
+	Carrier string `json:"carrier"`
+	Service string `json:"service"`
+	Rate    string `json:"rate"`
+}
+
+// CreateLabel rates the parcel and buys the rate for the requested
+// service, or the cheapest one.
+func (e *EasyPost) CreateLabel(ctx context.Context, req domain.LabelRequest) (*domain.Label, error) {
+	payload := map[string]any{
+		"shipment": map[string]any{
+			"reference":    req.Reference,
+			"from_address": toEasyPostAddress(req.From),
+			"to_address":   toEasyPostAddress(req.To),
+			"parcel":       map[string]any{"weight": math.Ceil(float64(req.WeightGrams)/gramsPerOunce*10) / 10},
+		},
+	}
+	var shipment struct {
+		ID    string         `json:"id"`
+		Rates []easyPostRate `json:"rates"`
+	}
+	if err := e.call(ctx, http.MethodPost, "/v2/shipments", payload, &shipment); err != nil {
+		return nil, err
+	}
+	rate, err := pickRate(shipment.Rates, req.Service)
+	if err != nil {
+		return nil, err
+	}
+	var bought struct {
+		TrackingCode string       `json:"tracking_code"`
+		SelectedRate easyPostRate `json:"selected_rate"`
+		PostageLabel struct {
+			LabelURL string `json:"label_url"`
+		} `json:"postage_label"`
+		Tracker struct {
+			ID string `json:"id"`
+		} `json:"tracker"`
+	}
+	buy := map[string]any{"rate": map[string]string{"id": rate.ID}}
+	if err := e.call(ctx, http.MethodPost, "/v2/shipments/"+url.PathEscape(shipment.ID)+"/buy", buy, &bought); err != nil {
+		return nil, err
+	}
+	return &domain.Label{
+		TrackingNumber: bought.TrackingCode,
+		Service:        strings.TrimSpace(bought.SelectedRate.Carrier + " " + bought.SelectedRate.Service),
+		LabelURL:       bought.PostageLabel.LabelURL,
+		Reference:      bought.Tracker.ID,
+	}, nil
+}
+
+// pickRate returns the cheapest rate, of the service when one is named.
+func pickRate(rates []easyPostRate, service string) (*easyPostRate, error) {
+	var best *easyPostRate
+	bestPrice := math.Inf(1)
+	for i, r := range rates {
+		if service != "" && !strings.EqualFold(r.Service, service) && !strings.EqualFold(r.Carrier+" "+r.Service, service) {
+			continue
+		}
+		price, err := strconv.ParseFloat(r.Rate, 64)
+		if err != nil {
+			continue
+		}
+		if price < bestPrice {
+			best, bestPrice = &rates[i], price
+		}
+	}
+	if best == nil {
+		if service != "" {
+			return nil, fmt.Errorf("easypost: no %s rate for this parcel", service)
+		}
+		return nil, errors.New("easypost: no rates for this parcel")
+	}
+	return best, nil
+}
+
+type easyPostTracker struct {
+	TrackingCode    string `json:"tracking_code"`
+	Status          string `json:"status"`
+	TrackingDetails []struct {
+		Message          string    `json:"message"`
+		Status           string    `json:"status"`
+		Datetime         time.Time `json:"datetime"`
+		TrackingLocation struct {
+			City    string `json:"city"`
+			State   string `json:"state"`
+			Country string `json:"country"`
+		} `json:"tracking_location"`
+	} `json:"tracking_details"`
+}
+
+func (t *easyPostTracker) tracking() *domain.Tracking {
+	status, ok := easyPostStatuses[t.Status]
+	if !ok {
+		return nil
+	}
+	tracking := &domain.Tracking{TrackingNumber: t.TrackingCode, Status: status}
+	for _, d := range t.TrackingDetails {
+		detailStatus, ok := easyPostStatuses[d.Status]
+		if !ok {
+			detailStatus = status
+		}
+		var place []string
+		for _, part := range []string{d.TrackingLocation.City, d.TrackingLocation.State, d.TrackingLocation.Country} {
+			if part != "" {
+				place = append(place, part)
+			}
+		}
+		tracking.Events = append(tracking.Events, domain.TrackingEvent{
+			Status:      detailStatus,
+			Description: d.Message,
+			Location:    strings.Join(place, ", "),
+			At:          d.Datetime,
+		})
+	}
+	return tracking
+}
+
+// Track fetches the parcel's tracker.
+func (e *EasyPost) Track(ctx context.Context, s *domain.Shipment) (*domain.Tracking, error) {
+	if s.Reference == "" {
+		return nil, nil
+	}
+	var tracker easyPostTracker
+	if err := e.call(ctx, http.MethodGet, "/v2/trackers/"+url.PathEscape(s.Reference), nil, &tracker); err != nil {
+		return nil, err
+	}
+	return tracker.tracking(), nil
+}
+
+// Webhook verifies the X-Hmac-Signature header and reads tracker.updated
+// events.
+func (e *EasyPost) Webhook(payload []byte, header http.Header) (*domain.Tracking, error) {
+	given, ok := strings.CutPrefix(strings.TrimSpace(header.Get(EasyPostSignatureHeader)), "hmac-sha256-hex=")
+	mac := hmac.New(sha256.New, []byte(e.webhookSecret))
+	mac.Write(payload)
+	if !ok || !hmac.Equal([]byte(strings.ToLower(given)), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return nil, domain.ErrInvalidSignature
+	}
+	var ev struct {
+		Description string          `json:"description"`
+		Result      easyPostTracker `json:"result"`
+	}
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, domain.ErrInvalidWebhook
+	}
+	if ev.Description != "tracker.updated" && ev.Description != "tracker.created" {
+		return nil, nil
+	}
+	if ev.Result.TrackingCode == "" {
+		return nil, domain.ErrInvalidWebhook
+	}
+	return ev.Result.tracking(), nil
+}
+
+// call sends a JSON request to the API and decodes the JSON response into
+// out. Errors carry EasyPost's message, never the API key.
+func (e *EasyPost) call(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, e.apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(e.apiKey, "")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("easypost: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("easypost: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &failure) == nil && failure.Error.Message != "" {
+			return fmt.Errorf("easypost: %s", failure.Error.Message)
+		}
+		return fmt.Errorf("easypost: unexpected status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(data, out)
+}
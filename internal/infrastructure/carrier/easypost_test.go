@@ -0,0 +1,211 @@
+package carrier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/shipment"
+)
+
+const testEasyPostSecret = "ep_secret"
+
+// easyPostSignature signs payload the way EasyPost does.
+func easyPostSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "hmac-sha256-hex=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestEasyPostWebhookSignature(t *testing.T) {
+	const payload = `{"description":"batch.created"}`
+	// HMAC-SHA256("ep_secret", payload) computed independently.
+	const pinned = "hmac-sha256-hex=4d3856fc3d7b9464e1b08768cf3f8b4441fda819d500bec28be3454f744b532a"
+	if got := easyPostSignature(testEasyPostSecret, payload); got != pinned {
+		t.Fatalf("test signer = %s, want %s", got, pinned)
+	}
+	hexPart := strings.TrimPrefix(pinned, "hmac-sha256-hex=")
+
+	for _, tc := range []struct {
+		name      string
+		signature string
+		body      string
+		ok        bool
+	}{
+		{name: "valid", signature: pinned, ok: true},
+		{name: "upper-case hex", signature: "hmac-sha256-hex=" + strings.ToUpper(hexPart), ok: true},
+		{name: "surrounding spaces", signature: "  " + pinned + " ", ok: true},
+		{name: "wrong secret", signature: easyPostSignature("other", payload)},
+		{name: "tampered body", signature: pinned, body: `{"description":"batch.updated"}`},
+		{name: "missing header"},
+		{name: "no scheme", signature: hexPart},
+		{name: "other scheme", signature: "hmac-sha1-hex=" + hexPart},
+		{name: "empty signature", signature: "hmac-sha256-hex="},
+		{name: "truncated", signature: pinned[:len(pinned)-2]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			body := payload
+			if tc.body != "" {
+				body = tc.body
+			}
+			header := http.Header{}
+			if tc.signature != "" {
+				header.Set(EasyPostSignatureHeader, tc.signature)
+			}
+			// A verified batch event is ignored with no error.
+			_, err := NewEasyPost("key", testEasyPostSecret).Webhook([]byte(body), header)
+			switch {
+			case tc.ok && err != nil:
+				t.Fatalf("Webhook: %v", err)
+			case !tc.ok && !errors.Is(err, domain.ErrInvalidSignature):
+				t.Fatalf("Webhook: err = %v, want %v", err, domain.ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestEasyPostWebhook(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 15, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		payload string
+		want    *domain.Tracking
+		err     error
+	}{
+		{
+			name: "tracker updated with history",
+			payload: `{"description":"tracker.updated","result":{"tracking_code":"EZ100","status":"out_for_delivery","tracking_details":[
+				{"message":"Picked up","status":"in_transit","datetime":"2026-03-01T17:00:00Z","tracking_location":{"city":"Vientiane","country":"LA"}},
+				{"message":"Arrived","status":"mystery","datetime":"2026-03-02T09:15:00Z","tracking_location":{}}]}}`,
+			want: &domain.Tracking{TrackingNumber: "EZ100", Status: domain.StatusOutForDelivery, Events: []domain.TrackingEvent{
+				{Status: domain.StatusInTransit, Description: "Picked up", Location: "Vientiane, LA", At: time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)},
+				// An unknown detail status takes the tracker's.
+				{Status: domain.StatusOutForDelivery, Description: "Arrived", At: at},
+			}},
+		},
+		{
+			name:    "tracker created",
+			payload: `{"description":"tracker.created","result":{"tracking_code":"EZ100","status":"pre_transit"}}`,
+			want:    &domain.Tracking{TrackingNumber: "EZ100", Status: domain.StatusLabelCreated},
+		},
+		{
+			name:    "unknown tracker status",
+			payload: `{"description":"tracker.updated","result":{"tracking_code":"EZ100","status":"teleported"}}`,
+		},
+		{
+			name:    "other event",
+			payload: `{"description":"batch.updated","result":{"id":"batch_1"}}`,
+		},
+		{
+			name:    "tracker without a code",
+			payload: `{"description":"tracker.updated","result":{"status":"delivered"}}`,
+			err:     domain.ErrInvalidWebhook,
+		},
+		{
+			name:    "not JSON",
+			payload: `tracker.updated`,
+			err:     domain.ErrInvalidWebhook,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{EasyPostSignatureHeader: {easyPostSignature(testEasyPostSecret, tc.payload)}}
+			got, err := NewEasyPost("key", testEasyPostSecret).Webhook([]byte(tc.payload), header)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err = %v, want %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("tracking = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEasyPostStatuses(t *testing.T) {
+	for status, want := range map[string]string{
+		"unknown":              domain.StatusLabelCreated,
+		"pre_transit":          domain.StatusLabelCreated,
+		"in_transit":           domain.StatusInTransit,
+		"out_for_delivery":     domain.StatusOutForDelivery,
+		"available_for_pickup": domain.StatusOutForDelivery,
+		"delivered":            domain.StatusDelivered,
+		"return_to_sender":     domain.StatusReturned,
+		"failure":              domain.StatusException,
+		"error":                domain.StatusException,
+		"cancelled":            domain.StatusException,
+	} {
+		tracker := easyPostTracker{TrackingCode: "EZ100", Status: status}
+		got := tracker.tracking()
+		if got == nil || got.Status != want {
+			t.Errorf("%s: tracking = %+v, want status %s", status, got, want)
+		}
+	}
+	for _, status := range []string{"", "Delivered", "lost"} {
+		tracker := easyPostTracker{TrackingCode: "EZ100", Status: status}
+		if got := tracker.tracking(); got != nil {
+			t.Errorf("%q: tracking = %+v, want nil for an unmapped status", status, got)
+		}
+	}
+}
+
+func TestEasyPostTrack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "key" || r.URL.Path != "/v2/trackers/trk_1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"tracking_code":"EZ100","status":"delivered"}`))
+	}))
+	defer server.Close()
+	e := NewEasyPost("key", testEasyPostSecret)
+	e.apiURL = server.URL
+	ctx := context.Background()
+
+	got, err := e.Track(ctx, &domain.Shipment{Reference: "trk_1"})
+	if err != nil {
+		t.Fatalf("Track: %v", err)
+	}
+	if got == nil || got.TrackingNumber != "EZ100" || got.Status != domain.StatusDelivered {
+		t.Fatalf("Track = %+v", got)
+	}
+	if _, err := e.Track(ctx, &domain.Shipment{Reference: "trk_2"}); err == nil || err.Error() != "easypost: not found" {
+		t.Fatalf("Track of a missing tracker: err = %v, want EasyPost's message", err)
+	}
+	if got, err := e.Track(ctx, &domain.Shipment{}); got != nil || err != nil {
+		t.Fatalf("Track without a reference = %+v, %v; want nothing", got, err)
+	}
+}
+
+func TestPickRate(t *testing.T) {
+	rates := []easyPostRate{
+		{ID: "rate_1", Carrier: "USPS", Service: "Priority", Rate: "9.10"},
+		{ID: "rate_2", Carrier: "USPS", Service: "Ground", Rate: "5.25"},
+		{ID: "rate_3", Carrier: "UPS", Service: "Ground", Rate: "6.00"},
+		{ID: "rate_4", Carrier: "DHL", Service: "Express", Rate: "n/a"},
+	}
+	for service, want := range map[string]string{
+		"":           "rate_2",
+		"priority":   "rate_1",
+		"UPS Ground": "rate_3",
+		"ground":     "rate_2",
+	} {
+		got, err := pickRate(rates, service)
+		if err != nil || got.ID != want {
+			t.Errorf("pickRate(%q) = %+v, %v; want %s", service, got, err, want)
+		}
+	}
+	if _, err := pickRate(rates, "Express"); err == nil {
+		t.Error("pickRate of an unpriced service: want an error")
+	}
+	if _, err := pickRate(nil, ""); err == nil {
+		t.Error("pickRate without rates: want an error")
+	}
+}
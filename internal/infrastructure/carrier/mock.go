@@ -0,0 +1,103 @@
+// Package carrier implements shipping carriers: EasyPost, and a mock for
+// development whose parcels move on their own as time passes.
+package carrier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/shipment"
+)
+
+// MockSignatureHeader carries the mock carrier's webhook signature,
+// "sha256=<hex HMAC-SHA256 of the body>".
+const MockSignatureHeader = "X-Mock-Signature"
+
+// mockRoute is the path a mock parcel takes, one status per step.
+var mockRoute = []domain.TrackingEvent{
+	{Status: domain.StatusLabelCreated},
+	{Status: domain.StatusInTransit, Description: "Picked up by carrier", Location: "Origin facility"},
+	{Status: domain.StatusOutForDelivery, Description: "Out for delivery", Location: "Destination facility"},
+	{Status: domain.StatusDelivered, Description: "Delivered"},
+}
+
+// Mock is a carrier that ships nothing. Its parcels move one status along
+// every step after their label is created until they are delivered.
+// Any status can also be set by posting {"trackingNumber": "...",
+// "status": "..."} to its webhook, signed with the shared secret; a parcel
+// set to exception stays there until a webhook moves it on.
+type Mock struct {
+	secret  string
+	step    time.Duration
+	nowFunc func() time.Time
+}
+
+var _ domain.WebhookCarrier = (*Mock)(nil)
+
+// NewMock constructs a mock carrier whose parcels move every step and
+// whose webhooks are signed with secret.
+func NewMock(secret string, step time.Duration) *Mock {
+	return &Mock{secret: secret, step: step, nowFunc: time.Now}
+}
+
+// Name is "mock".
+func (m *Mock) Name() string {
+	return "mock"
+}
+
+// CreateLabel returns a tracking number derived from the shipment id and a
+// label URL nobody can visit.
+func (m *Mock) CreateLabel(_ context.Context, req domain.LabelRequest) (*domain.Label, error) {
+	number := "MOCK" + strings.ToUpper(strings.ReplaceAll(req.ShipmentID, "-", "")[:12])
+	service := req.Service
+	if service == "" {
+		service = "standard"
+	}
+	return &domain.Label{TrackingNumber: number, Service: service, LabelURL: "mock://labels/" + number, Reference: number}, nil
+}
+
+// Track reports how far along its route the parcel is by now.
+func (m *Mock) Track(_ context.Context, s *domain.Shipment) (*domain.Tracking, error) {
+	if s.Status == domain.StatusException || m.step <= 0 {
+		return nil, nil
+	}
+	reached := min(int(m.nowFunc().Sub(s.CreatedAt)/m.step), len(mockRoute)-1)
+	if reached <= 0 {
+		return &domain.Tracking{TrackingNumber: s.TrackingNumber, Status: domain.StatusLabelCreated}, nil
+	}
+	events := slices.Clone(mockRoute[1 : reached+1])
+	for i := range events {
+		events[i].At = s.CreatedAt.Add(time.Duration(i+1) * m.step)
+	}
+	return &domain.Tracking{TrackingNumber: s.TrackingNumber, Status: events[len(events)-1].Status, Events: events}, nil
+}
+
+// Webhook verifies the signature and reads the parcel's new status.
+func (m *Mock) Webhook(payload []byte, header http.Header) (*domain.Tracking, error) {
+	given, ok := strings.CutPrefix(strings.TrimSpace(header.Get(MockSignatureHeader)), "sha256=")
+	if !ok || !hmac.Equal([]byte(strings.ToLower(given)), []byte(MockSign(m.secret, payload))) {
+		return nil, domain.ErrInvalidSignature
+	}
+	var body struct {
+		TrackingNumber string `json:"trackingNumber"`
+		Status         string `json:"status"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil || body.TrackingNumber == "" || !slices.Contains(domain.Statuses, body.Status) {
+		return nil, domain.ErrInvalidWebhook
+	}
+	return &domain.Tracking{TrackingNumber: body.TrackingNumber, Status: body.Status}, nil
+}
+
+// MockSign returns the hex signature of a mock webhook payload.
+func MockSign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
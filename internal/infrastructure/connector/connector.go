@@ -0,0 +1,100 @@
+// Package connector implements stocksync.Connector for the external systems
+// products are pulled from: Shopify stores, supplier CSV drops and ERP REST
+// APIs.
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/stocksync"
+)
+
+// maxResponse caps what a source may return, per request.
+const maxResponse = 64 << 20
+
+// New builds a connector of kind ("shopify", "csv" or "rest") from its
+// configured options.
+func New(kind string, options map[string]string) (domain.Connector, error) {
+	client := &http.Client{Timeout: time.Minute}
+	switch kind {
+	case "shopify":
+		return newShopify(options, client)
+	case "csv":
+		return newCSV(options, client)
+	case "rest":
+		return newREST(options, client)
+	default:
+		return nil, fmt.Errorf("unknown connector type %q", kind)
+	}
+}
+
+// checkURL accepts absolute http and https URLs.
+func checkURL(option, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("option %s must be an absolute http or https URL", option)
+	}
+	return nil
+}
+
+// get fetches target and returns the response for a 2xx status.
+func get(ctx context.Context, client *http.Client, target string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", "backoffice-sync/1")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s responded %d: %s", req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(resp.Body, maxResponse), resp.Body}
+	return resp, nil
+}
+
+// flatten adds the scalar fields of a decoded JSON value to record, naming
+// nested fields by their path: {"stock":{"qty":3}} becomes "stock.qty" and
+// array elements are numbered ("variants.0.sku").
+func flatten(record domain.Record, prefix string, value any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			flatten(record, join(key), child)
+		}
+	case []any:
+		for i, child := range v {
+			flatten(record, join(strconv.Itoa(i)), child)
+		}
+	case string:
+		record[prefix] = v
+	case json.Number:
+		record[prefix] = v.String()
+	case bool:
+		record[prefix] = strconv.FormatBool(v)
+	}
+}
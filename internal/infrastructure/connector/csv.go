@@ -0,0 +1,117 @@
+package connector
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	domain "backoffice/backend/internal/domain/stocksync"
+)
+
+// csvSource reads a supplier's CSV drop: a file the supplier uploads (e.g.
+// over FTP to a mounted directory) or a URL it publishes. The header row
+// names the fields.
+type csvSource struct {
+	path      string
+	url       string
+	token     string
+	delimiter rune
+	client    *http.Client
+}
+
+// newCSV reads the options path or url, and optionally token (sent as a
+// bearer token with url) and delimiter (default ",").
+func newCSV(options map[string]string, client *http.Client) (*csvSource, error) {
+	c := &csvSource{
+		path:      strings.TrimSpace(options["path"]),
+		url:       strings.TrimSpace(options["url"]),
+		token:     options["token"],
+		delimiter: ',',
+		client:    client,
+	}
+	if (c.path == "") == (c.url == "") {
+		return nil, errors.New("csv connector needs exactly one of the options path and url")
+	}
+	if c.url != "" {
+		if err := checkURL("url", c.url); err != nil {
+			return nil, err
+		}
+	}
+	if d := options["delimiter"]; d != "" {
+		r, size := utf8.DecodeRuneInString(d)
+		if size != len(d) || r == '"' || r == '\n' {
+			return nil, fmt.Errorf("option delimiter must be a single character, got %q", d)
+		}
+		c.delimiter = r
+	}
+	return c, nil
+}
+
+// Fetch reads every row of the drop.
+func (c *csvSource) Fetch(ctx context.Context) ([]domain.Record, error) {
+	var body io.ReadCloser
+	if c.path != "" {
+		file, err := os.Open(c.path)
+		if err != nil {
+			return nil, err
+		}
+		body = file
+	} else {
+		header := http.Header{}
+		if c.token != "" {
+			header.Set("Authorization", "Bearer "+c.token)
+		}
+		resp, err := get(ctx, c.client, c.url, header)
+		if err != nil {
+			return nil, err
+		}
+		body = resp.Body
+	}
+	defer body.Close()
+
+	reader := csv.NewReader(body)
+	reader.Comma = c.delimiter
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if len(header) > 0 {
+		// Spreadsheet exports often start with a byte order mark.
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var records []domain.Record
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record := domain.Record{}
+		for i, value := range row {
+			if i < len(header) && header[i] != "" {
+				record[header[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+}
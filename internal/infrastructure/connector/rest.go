@@ -0,0 +1,77 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	domain "backoffice/backend/internal/domain/stocksync"
+)
+
+// restSource reads products from a JSON API, such as an ERP's item
+// endpoint.
+type restSource struct {
+	url    string
+	token  string
+	items  []string
+	client *http.Client
+}
+
+// newREST reads the options url, token (sent as a bearer token) and items,
+// the dot-separated path to the product array in the response ("data.items";
+// empty when the response is the array itself).
+func newREST(options map[string]string, client *http.Client) (*restSource, error) {
+	r := &restSource{url: strings.TrimSpace(options["url"]), token: options["token"], client: client}
+	if r.url == "" {
+		return nil, errors.New("rest connector needs the option url")
+	}
+	if err := checkURL("url", r.url); err != nil {
+		return nil, err
+	}
+	if items := strings.Trim(strings.TrimSpace(options["items"]), "."); items != "" {
+		r.items = strings.Split(items, ".")
+	}
+	return r, nil
+}
+
+// Fetch requests the endpoint once and flattens each item into a record.
+func (r *restSource) Fetch(ctx context.Context) ([]domain.Record, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if r.token != "" {
+		header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := get(ctx, r.client, r.url, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	var body any
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	for _, key := range r.items {
+		object, ok := body.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("response has no object at %q", key)
+		}
+		body = object[key]
+	}
+	items, ok := body.([]any)
+	if !ok {
+		return nil, fmt.Errorf("response has no array at %q", strings.Join(r.items, "."))
+	}
+	records := make([]domain.Record, 0, len(items))
+	for _, item := range items {
+		record := domain.Record{}
+		flatten(record, "", item)
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,124 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	domain "backoffice/backend/internal/domain/stocksync"
+)
+
+// shopifyAPIVersion is used unless the connector sets apiVersion.
+const shopifyAPIVersion = "2024-07"
+
+// shopifySource reads the product variants of a Shopify store through the
+// Admin REST API. Each variant is one record with the fields sku, name,
+// description, price and quantity, so the default mapping needs no
+// configuration, plus vendor, barcode and the product and variant ids.
+type shopifySource struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// newShopify reads the options shop (the store's myshopify.com domain),
+// token (an Admin API access token) and optionally apiVersion.
+func newShopify(options map[string]string, client *http.Client) (*shopifySource, error) {
+	shop := strings.TrimRight(strings.TrimSpace(options["shop"]), "/")
+	token := options["token"]
+	if shop == "" || token == "" {
+		return nil, errors.New("shopify connector needs the options shop and token")
+	}
+	if !strings.Contains(shop, "://") {
+		shop = "https://" + shop
+	}
+	if err := checkURL("shop", shop); err != nil {
+		return nil, err
+	}
+	version := strings.TrimSpace(options["apiVersion"])
+	if version == "" {
+		version = shopifyAPIVersion
+	}
+	return &shopifySource{
+		endpoint: shop + "/admin/api/" + url.PathEscape(version) + "/products.json?limit=250",
+		token:    token,
+		client:   client,
+	}, nil
+}
+
+type shopifyProduct struct {
+	ID       json.Number `json:"id"`
+	Title    string      `json:"title"`
+	BodyHTML string      `json:"body_html"`
+	Vendor   string      `json:"vendor"`
+	Variants []struct {
+		ID                json.Number `json:"id"`
+		Title             string      `json:"title"`
+		SKU               string      `json:"sku"`
+		Barcode           string      `json:"barcode"`
+		Price             string      `json:"price"`
+		InventoryQuantity json.Number `json:"inventory_quantity"`
+	} `json:"variants"`
+}
+
+// Fetch pages through every product of the store.
+func (s *shopifySource) Fetch(ctx context.Context) ([]domain.Record, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("X-Shopify-Access-Token", s.token)
+
+	var records []domain.Record
+	for next := s.endpoint; next != ""; {
+		resp, err := get(ctx, s.client, next, header)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Products []shopifyProduct `json:"products"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding products: %w", err)
+		}
+		for _, p := range page.Products {
+			for _, v := range p.Variants {
+				name := p.Title
+				// Products without options have a single "Default Title"
+				// variant.
+				if v.Title != "" && v.Title != "Default Title" {
+					name += " - " + v.Title
+				}
+				records = append(records, domain.Record{
+					"sku":         v.SKU,
+					"name":        name,
+					"description": p.BodyHTML,
+					"price":       v.Price,
+					"quantity":    v.InventoryQuantity.String(),
+					"vendor":      p.Vendor,
+					"barcode":     v.Barcode,
+					"product_id":  p.ID.String(),
+					"variant_id":  v.ID.String(),
+				})
+			}
+		}
+		next = nextLink(resp.Header.Get("Link"))
+	}
+	return records, nil
+}
+
+// nextLink returns the rel="next" URL of a Link header, as Shopify uses for
+// cursor pagination.
+func nextLink(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if ok && strings.Contains(params, `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+// Package fieldcrypt encrypts individual database fields with AES-256-GCM
+// so personal data is unreadable at rest to anyone holding only the
+// database or its backups.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the length in bytes of every key.
+const KeySize = 32
+
+// prefix marks encrypted values: prefix, the key id, a colon, then the
+// base64 of the nonce and sealed value.
+const prefix = "pii:v1:"
+
+var (
+	// ErrUnknownKey means a value was encrypted with a key the keyring no
+	// longer holds.
+	ErrUnknownKey = errors.New("fieldcrypt: value is encrypted with an unknown key")
+	// ErrCorrupt means a value is not one this package produced for the
+	// same field, or has been altered.
+	ErrCorrupt = errors.New("fieldcrypt: value cannot be decrypted")
+)
+
+// Key is one named key. IDs are stored with every value, so keep them short
+// and never reuse one for different key material.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring encrypts with its primary key and decrypts with any of its keys,
+// so keys can be rotated without rewriting every value at once. A nil
+// Keyring stores values in plain text.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring builds a keyring whose primary key is the first. Key IDs may
+// use letters, digits, '-' and '.'.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("fieldcrypt: no keys")
+	}
+	k := &Keyring{primary: keys[0].ID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for _, key := range keys {
+		if !validID(key.ID) {
+			return nil, fmt.Errorf("fieldcrypt: invalid key id %q", key.ID)
+		}
+		if _, dup := k.aeads[key.ID]; dup {
+			return nil, fmt.Errorf("fieldcrypt: key id %q is used twice", key.ID)
+		}
+		if len(key.Secret) != KeySize {
+			return nil, fmt.Errorf("fieldcrypt: key %q is %d bytes, want %d", key.ID, len(key.Secret), KeySize)
+		}
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[key.ID] = aead
+	}
+	return k, nil
+}
+
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// Primary returns the id of the key new values are encrypted with, empty
+// for a nil keyring.
+func (k *Keyring) Primary() string {
+	if k == nil {
+		return ""
+	}
+	return k.primary
+}
+
+// PrimaryPrefix is how every value encrypted with the primary key starts,
+// for finding the values a rotation still has to rewrite.
+func (k *Keyring) PrimaryPrefix() string {
+	return prefix + k.Primary() + ":"
+}
+
+// Encrypt seals plaintext with the primary key. field binds the value to
+// where it is stored, such as "customers.phone:<id>", so a value copied to
+// another row or column does not decrypt. Empty values stay empty.
+func (k *Keyring) Encrypt(plaintext, field string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(field))
+	return k.PrimaryPrefix() + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt for the same field. Values
+// without the encrypted prefix are returned as they are: they were stored
+// before encryption was enabled and are rewritten by the re-encryption job.
+func (k *Keyring) Decrypt(value, field string) (string, error) {
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", ErrCorrupt
+	}
+	var aead cipher.AEAD
+	if k != nil {
+		aead = k.aeads[id]
+	}
+	if aead == nil {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrCorrupt
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(field))
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether value should be rewritten: it is stored in
+// plain text or under a key other than the primary.
+func (k *Keyring) NeedsRotation(value string) bool {
+	if k == nil || value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, k.PrimaryPrefix())
+}
+
+// Reencrypt decrypts value and encrypts it again with the primary key.
+func (k *Keyring) Reencrypt(value, field string) (string, error) {
+	plaintext, err := k.Decrypt(value, field)
+	if err != nil {
+		return "", err
+	}
+	return k.Encrypt(plaintext, field)
+}
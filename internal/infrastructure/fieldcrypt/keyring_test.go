@@ -0,0 +1,62 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func FuzzKeyringDecrypt(f *testing.F) {
+	old, err := NewKeyring(Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, KeySize)})
+	if err != nil {
+		f.Fatal(err)
+	}
+	// k2 is primary and k1 is kept for values not yet rotated.
+	keys, err := NewKeyring(Key{ID: "k2", Secret: bytes.Repeat([]byte{2}, KeySize)}, Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, KeySize)})
+	if err != nil {
+		f.Fatal(err)
+	}
+	const field = "customers.phone:c1"
+	sealed, err := old.Encrypt("+856 20 5555 1234", field)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, seed := range []string{
+		sealed,
+		sealed[:len(sealed)-2],
+		"pii:v1:k3:" + strings.TrimPrefix(sealed, "pii:v1:k1:"),
+		"pii:v1:k1",
+		"pii:v1:",
+		"+856 20 5555 1234",
+		"",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		plain, err := keys.Decrypt(value, field)
+		if err != nil {
+			return
+		}
+		// Values that open under the field are either stored in plain text
+		// or were sealed by one of the keys for exactly that field.
+		if strings.HasPrefix(value, prefix) && plain != "+856 20 5555 1234" {
+			t.Fatalf("Decrypt(%q) = %q", value, plain)
+		}
+		if !strings.HasPrefix(value, prefix) && plain != value {
+			t.Fatalf("Decrypt(%q) changed plain text to %q", value, plain)
+		}
+		rotated, err := keys.Reencrypt(value, field)
+		if err != nil {
+			t.Fatalf("Reencrypt(%q): %v", value, err)
+		}
+		if keys.NeedsRotation(rotated) {
+			t.Fatalf("Reencrypt(%q) = %q, still needs rotation", value, rotated)
+		}
+		if again, err := keys.Decrypt(rotated, field); err != nil || again != plain {
+			t.Fatalf("Decrypt(Reencrypt(%q)) = %q, %v; want %q", value, again, err, plain)
+		}
+		if _, err := keys.Decrypt(rotated, "customers.phone:c2"); value != "" && err == nil {
+			t.Fatalf("value sealed for %s opened for another row", field)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+// Package mail sends email through an SMTP server.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/mail"
+
+	"github.com/google/uuid"
+)
+
+// SMTPSender delivers messages through one SMTP server, upgrading the
+// connection with STARTTLS when the server offers it.
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+	host string
+}
+
+var _ domain.Sender = (*SMTPSender)(nil)
+
+// NewSMTPSender constructs a sender for host:port that sends as from. The
+// username and password are only used when a username is given.
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	s := &SMTPSender{addr: net.JoinHostPort(host, strconv.Itoa(port)), from: from, host: host}
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s
+}
+
+// Send delivers msg. net/smtp has no context support, so a cancelled
+// context only stops a message that has not started sending.
+func (s *SMTPSender) Send(ctx context.Context, msg domain.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	body, err := s.compose(msg)
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(s.addr, s.auth, s.from, msg.To, body); err != nil {
+		return fmt.Errorf("smtp %s: %w", s.addr, err)
+	}
+	return nil
+}
+
+// compose renders msg as a MIME message: the text alone, or a multipart
+// message carrying the attachments after it.
+func (s *SMTPSender) compose(msg domain.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", s.from)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid.NewString(), s.host))
+	header("MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(msg.Text))
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", `multipart/mixed; boundary="`+parts.Boundary()+`"`)
+	buf.WriteString("\r\n")
+	text, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/plain; charset="utf-8"`},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(text, []byte(msg.Text))
+	for _, a := range msg.Attachments {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, a.Data)
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines, as MIME
+// requires.
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		_, _ = w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	_, _ = w.Write([]byte(encoded + "\r\n"))
+}
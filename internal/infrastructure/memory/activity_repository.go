@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/activity"
+)
+
+// ActivityRepository is a thread-safe, in-memory domain.Repository that
+// mirrors the PostgreSQL implementation's ordering.
+type ActivityRepository struct {
+	mu sync.RWMutex
+	// entries are kept in chain order.
+	entries []domain.Entry
+	seq     int64
+}
+
+// NewActivityRepository constructs an empty repository.
+func NewActivityRepository() *ActivityRepository {
+	return &ActivityRepository{}
+}
+
+var _ domain.Repository = (*ActivityRepository)(nil)
+
+// Record appends an entry to the hash chain.
+func (r *ActivityRepository) Record(_ context.Context, entry *domain.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := ""
+	if len(r.entries) > 0 {
+		prev = r.entries[len(r.entries)-1].Hash
+	}
+	r.seq++
+	// Kept at the precision PostgreSQL stores, so exported entries hash the
+	// same way in both.
+	entry.OccurredAt = entry.OccurredAt.Truncate(time.Microsecond)
+	entry.Seq, entry.PrevHash, entry.Hash = r.seq, prev, entry.ChainHash(prev)
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+// List returns matching entries, newest first.
+func (r *ActivityRepository) List(_ context.Context, filter domain.Filter) ([]*domain.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []*domain.Entry
+	for _, e := range r.entries {
+		if filter.ActorID != "" && e.ActorID != filter.ActorID ||
+			filter.EntityType != "" && e.EntityType != filter.EntityType ||
+			filter.EntityID != "" && e.EntityID != filter.EntityID ||
+			filter.Action != "" && e.Action != filter.Action ||
+			filter.After != nil && !newer(*filter.After, e) {
+			continue
+		}
+		e := e
+		entries = append(entries, &e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		return newer(domain.Position{OccurredAt: a.OccurredAt, ID: a.ID}, *b)
+	})
+	if len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// ChainRange returns the Seq of the first and last entries that occurred in
+// [from, to).
+func (r *ActivityRepository) ChainRange(_ context.Context, from, to time.Time) (int64, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var first, last int64
+	for _, e := range r.entries {
+		if !from.IsZero() && e.OccurredAt.Before(from) || !to.IsZero() && !e.OccurredAt.Before(to) {
+			continue
+		}
+		if first == 0 || e.Seq < first {
+			first = e.Seq
+		}
+		last = max(last, e.Seq)
+	}
+	return first, last, nil
+}
+
+// Chain returns up to limit entries with first <= Seq <= last, in chain
+// order.
+func (r *ActivityRepository) Chain(_ context.Context, first, last int64, limit int) ([]*domain.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []*domain.Entry
+	for _, e := range r.entries {
+		if e.Seq < first || e.Seq > last {
+			continue
+		}
+		if len(entries) == limit {
+			break
+		}
+		e := e
+		entries = append(entries, &e)
+	}
+	return entries, nil
+}
+
+// newer reports whether p comes before e in newest-first order.
+func newer(p domain.Position, e domain.Entry) bool {
+	if !p.OccurredAt.Equal(e.OccurredAt) {
+		return p.OccurredAt.After(e.OccurredAt)
+	}
+	return p.ID > e.ID
+}
+
+// CountBefore counts entries recorded before cutoff.
+func (r *ActivityRepository) CountBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.prefixBefore(cutoff), nil
+}
+
+// PurgeBefore deletes entries recorded before cutoff.
+func (r *ActivityRepository) PurgeBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := r.prefixBefore(cutoff)
+	r.entries = append([]domain.Entry(nil), r.entries[purged:]...)
+	return purged, nil
+}
+
+// prefixBefore returns how many entries precede, in chain order, the first
+// that occurred at or after cutoff. Like the PostgreSQL implementation, only
+// that prefix is purged so the rest of the chain stays verifiable.
+func (r *ActivityRepository) prefixBefore(cutoff time.Time) int {
+	for i, e := range r.entries {
+		if !e.OccurredAt.Before(cutoff) {
+			return i
+		}
+	}
+	return len(r.entries)
+}
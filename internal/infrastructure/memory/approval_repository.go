@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/approval"
+)
+
+// ApprovalRepository is a thread-safe, in-memory domain.Repository.
+type ApprovalRepository struct {
+	mu        sync.RWMutex
+	approvals map[string]domain.Approval
+}
+
+// NewApprovalRepository constructs an empty repository.
+func NewApprovalRepository() *ApprovalRepository {
+	return &ApprovalRepository{approvals: make(map[string]domain.Approval)}
+}
+
+var _ domain.Repository = (*ApprovalRepository)(nil)
+
+// Create inserts a new approval.
+func (r *ApprovalRepository) Create(_ context.Context, a *domain.Approval) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.approvals[a.ID] = copyApproval(*a)
+	return nil
+}
+
+// GetByID fetches an approval by id.
+func (r *ApprovalRepository) GetByID(_ context.Context, id string) (*domain.Approval, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.approvals[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyApproval(a)
+	return &found, nil
+}
+
+// List returns approvals with the status, or all when it is empty, newest
+// first.
+func (r *ApprovalRepository) List(_ context.Context, status string) ([]*domain.Approval, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var approvals []*domain.Approval
+	for _, a := range r.approvals {
+		if status != "" && a.Status != status {
+			continue
+		}
+		found := copyApproval(a)
+		approvals = append(approvals, &found)
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		if !approvals[i].CreatedAt.Equal(approvals[j].CreatedAt) {
+			return approvals[i].CreatedAt.After(approvals[j].CreatedAt)
+		}
+		return approvals[i].ID > approvals[j].ID
+	})
+	return approvals, nil
+}
+
+// Decide records the outcome of a pending approval.
+func (r *ApprovalRepository) Decide(_ context.Context, a *domain.Approval) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.approvals[a.ID]
+	if !ok || existing.Status != domain.StatusPending {
+		return domain.ErrNotPending
+	}
+	existing.Status = a.Status
+	existing.Reason = a.Reason
+	existing.DecidedBy = a.DecidedBy
+	existing.DecidedAt = a.DecidedAt
+	r.approvals[a.ID] = copyApproval(existing)
+	return nil
+}
+
+// Finish records the result of executing an approved action.
+func (r *ApprovalRepository) Finish(_ context.Context, a *domain.Approval) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.approvals[a.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.Status = a.Status
+	existing.Error = a.Error
+	r.approvals[a.ID] = existing
+	return nil
+}
+
+// Expire marks pending approvals whose expiry has passed by now as expired.
+func (r *ApprovalRepository) Expire(_ context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expired := 0
+	for id, a := range r.approvals {
+		if a.Status == domain.StatusPending && !a.ExpiresAt.After(now) {
+			a.Status = domain.StatusExpired
+			r.approvals[id] = a
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func copyApproval(a domain.Approval) domain.Approval {
+	a.Payload = slices.Clone(a.Payload)
+	if a.DecidedAt != nil {
+		at := *a.DecidedAt
+		a.DecidedAt = &at
+	}
+	return a
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/attachment"
+)
+
+// AttachmentStore is a thread-safe, in-memory domain.Store.
+type AttachmentStore struct {
+	mu          sync.RWMutex
+	attachments map[string]domain.Attachment
+}
+
+// NewAttachmentStore constructs an empty store.
+func NewAttachmentStore() *AttachmentStore {
+	return &AttachmentStore{attachments: make(map[string]domain.Attachment)}
+}
+
+var _ domain.Store = (*AttachmentStore)(nil)
+
+// Get fetches the attachment stored under key.
+func (s *AttachmentStore) Get(_ context.Context, key string) (*domain.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.attachments[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	a.Data = slices.Clone(a.Data)
+	return &a, nil
+}
+
+// Put stores an attachment, replacing any under the same key.
+func (s *AttachmentStore) Put(_ context.Context, a *domain.Attachment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *a
+	stored.Data = slices.Clone(a.Data)
+	s.attachments[a.Key] = stored
+	return nil
+}
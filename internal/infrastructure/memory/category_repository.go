@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/category"
+)
+
+// CategoryRepository is a thread-safe, in-memory domain.Repository. When
+// given a ProductRepository it refuses to delete categories that products
+// still reference, mirroring the PostgreSQL foreign key.
+type CategoryRepository struct {
+	mu         sync.RWMutex
+	categories map[string]domain.Category
+	products   *ProductRepository
+}
+
+// NewCategoryRepository constructs an empty repository; products may be nil.
+func NewCategoryRepository(products *ProductRepository) *CategoryRepository {
+	return &CategoryRepository{categories: make(map[string]domain.Category), products: products}
+}
+
+var _ domain.Repository = (*CategoryRepository)(nil)
+
+// Create inserts a new category.
+func (r *CategoryRepository) Create(_ context.Context, category *domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.categories[category.ID]; ok {
+		return domain.ErrDuplicateSlug
+	}
+	for _, existing := range r.categories {
+		if existing.Slug == category.Slug {
+			return domain.ErrDuplicateSlug
+		}
+	}
+	r.categories[category.ID] = *category
+	return nil
+}
+
+// GetByID fetches a category by id.
+func (r *CategoryRepository) GetByID(_ context.Context, id string) (*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.categories[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &c, nil
+}
+
+// GetByIDs fetches the categories among ids.
+func (r *CategoryRepository) GetByIDs(_ context.Context, ids []string) ([]*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var categories []*domain.Category
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if c, ok := r.categories[id]; ok && !seen[id] {
+			seen[id] = true
+			categories = append(categories, &c)
+		}
+	}
+	return categories, nil
+}
+
+// GetBySlug fetches a category by slug.
+func (r *CategoryRepository) GetBySlug(_ context.Context, slug string) (*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.categories {
+		if c.Slug == slug {
+			found := c
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List returns all categories sorted by name.
+func (r *CategoryRepository) List(_ context.Context) ([]*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var categories []*domain.Category
+	for _, c := range r.categories {
+		found := c
+		categories = append(categories, &found)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return categories, nil
+}
+
+// Update writes category updates.
+func (r *CategoryRepository) Update(_ context.Context, category *domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.categories[category.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	for id, other := range r.categories {
+		if id != category.ID && other.Slug == category.Slug {
+			return domain.ErrDuplicateSlug
+		}
+	}
+	r.categories[category.ID] = *category
+	return nil
+}
+
+// Delete removes a category by id.
+func (r *CategoryRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.categories[id]; !ok {
+		return domain.ErrNotFound
+	}
+	if r.products != nil && r.products.referencesCategory(id) {
+		return domain.ErrInUse
+	}
+	delete(r.categories, id)
+	return nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/consent"
+)
+
+// ConsentRepository is a thread-safe, in-memory domain.Repository.
+type ConsentRepository struct {
+	mu       sync.RWMutex
+	versions []domain.Version
+	consents []domain.Consent
+}
+
+// NewConsentRepository constructs an empty repository.
+func NewConsentRepository() *ConsentRepository {
+	return &ConsentRepository{}
+}
+
+var _ domain.Repository = (*ConsentRepository)(nil)
+
+// Publish stores a new version.
+func (r *ConsentRepository) Publish(_ context.Context, v *domain.Version) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.ContainsFunc(r.versions, func(existing domain.Version) bool { return existing.Ref() == v.Ref() }) {
+		return domain.ErrVersionExists
+	}
+	r.versions = append(r.versions, *v)
+	return nil
+}
+
+// Versions returns every published version, newest first.
+func (r *ConsentRepository) Versions(_ context.Context) ([]*domain.Version, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := make([]*domain.Version, 0, len(r.versions))
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		v := r.versions[i]
+		versions = append(versions, &v)
+	}
+	return versions, nil
+}
+
+// Accept records a consent unless the user already accepted the version.
+func (r *ConsentRepository) Accept(_ context.Context, c *domain.Consent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.ContainsFunc(r.consents, func(existing domain.Consent) bool {
+		return existing.UserID == c.UserID && existing.Document == c.Document && existing.Version == c.Version
+	}) {
+		return nil
+	}
+	r.consents = append(r.consents, *c)
+	return nil
+}
+
+// Consents returns the user's consents, newest first.
+func (r *ConsentRepository) Consents(_ context.Context, userID string) ([]*domain.Consent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var consents []*domain.Consent
+	for i := len(r.consents) - 1; i >= 0; i-- {
+		if c := r.consents[i]; c.UserID == userID {
+			consents = append(consents, &c)
+		}
+	}
+	return consents, nil
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/customer"
+)
+
+// CustomerRepository is a thread-safe, in-memory domain.Repository.
+type CustomerRepository struct {
+	mu        sync.RWMutex
+	customers map[string]domain.Customer
+	addresses map[string]domain.Address
+}
+
+// NewCustomerRepository constructs an empty repository.
+func NewCustomerRepository() *CustomerRepository {
+	return &CustomerRepository{
+		customers: make(map[string]domain.Customer),
+		addresses: make(map[string]domain.Address),
+	}
+}
+
+var _ domain.Repository = (*CustomerRepository)(nil)
+
+// Create inserts a new customer.
+func (r *CustomerRepository) Create(_ context.Context, c *domain.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.customers[c.ID] = *c
+	return nil
+}
+
+// GetByID fetches a customer by id.
+func (r *CustomerRepository) GetByID(_ context.Context, id string) (*domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.customers[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &c, nil
+}
+
+// List returns customers ordered by name.
+func (r *CustomerRepository) List(_ context.Context) ([]*domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var customers []*domain.Customer
+	for _, c := range r.customers {
+		found := c
+		customers = append(customers, &found)
+	}
+	sort.Slice(customers, func(i, j int) bool {
+		if customers[i].Name != customers[j].Name {
+			return customers[i].Name < customers[j].Name
+		}
+		return customers[i].ID < customers[j].ID
+	})
+	return customers, nil
+}
+
+// Addresses returns a customer's addresses, oldest first.
+func (r *CustomerRepository) Addresses(_ context.Context, customerID string) ([]*domain.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var addresses []*domain.Address
+	for _, a := range r.addresses {
+		if a.CustomerID == customerID {
+			found := a
+			addresses = append(addresses, &found)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if !addresses[i].CreatedAt.Equal(addresses[j].CreatedAt) {
+			return addresses[i].CreatedAt.Before(addresses[j].CreatedAt)
+		}
+		return addresses[i].ID < addresses[j].ID
+	})
+	return addresses, nil
+}
+
+// GetAddress fetches one of a customer's addresses.
+func (r *CustomerRepository) GetAddress(_ context.Context, customerID, id string) (*domain.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.addresses[id]
+	if !ok || a.CustomerID != customerID {
+		return nil, domain.ErrAddressNotFound
+	}
+	return &a, nil
+}
+
+// SaveAddress inserts or replaces an address, clearing the defaults it
+// takes over from the customer's other addresses.
+func (r *CustomerRepository) SaveAddress(_ context.Context, a *domain.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, other := range r.addresses {
+		if other.CustomerID != a.CustomerID || id == a.ID {
+			continue
+		}
+		other.DefaultBilling = other.DefaultBilling && !a.DefaultBilling
+		other.DefaultShipping = other.DefaultShipping && !a.DefaultShipping
+		r.addresses[id] = other
+	}
+	r.addresses[a.ID] = *a
+	return nil
+}
+
+// DeleteAddress removes one of a customer's addresses.
+func (r *CustomerRepository) DeleteAddress(_ context.Context, customerID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.addresses[id]
+	if !ok || a.CustomerID != customerID {
+		return domain.ErrAddressNotFound
+	}
+	delete(r.addresses, id)
+	return nil
+}
@@ -0,0 +1,5 @@
+// Package memory provides thread-safe in-memory implementations of the domain
+// repositories. They behave like the PostgreSQL repositories (same sentinel
+// errors, uniqueness rules and ordering) so use cases and HTTP handlers can be
+// exercised without a database.
+package memory
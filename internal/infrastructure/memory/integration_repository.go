@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/integration"
+)
+
+// IntegrationRepository is a thread-safe, in-memory domain.Repository.
+type IntegrationRepository struct {
+	mu           sync.RWMutex
+	integrations map[string]domain.Integration
+}
+
+// NewIntegrationRepository constructs an empty repository.
+func NewIntegrationRepository() *IntegrationRepository {
+	return &IntegrationRepository{integrations: make(map[string]domain.Integration)}
+}
+
+var _ domain.Repository = (*IntegrationRepository)(nil)
+
+// Create inserts a new integration.
+func (r *IntegrationRepository) Create(_ context.Context, integration *domain.Integration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.integrations {
+		if existing.Name == integration.Name {
+			return domain.ErrDuplicateName
+		}
+	}
+	r.integrations[integration.ID] = *integration
+	return nil
+}
+
+// GetByID fetches an integration by id.
+func (r *IntegrationRepository) GetByID(_ context.Context, id string) (*domain.Integration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.integrations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &i, nil
+}
+
+// List returns all integrations sorted by name.
+func (r *IntegrationRepository) List(_ context.Context) ([]*domain.Integration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var integrations []*domain.Integration
+	for _, i := range r.integrations {
+		found := i
+		integrations = append(integrations, &found)
+	}
+	sort.Slice(integrations, func(i, j int) bool { return integrations[i].Name < integrations[j].Name })
+	return integrations, nil
+}
+
+// Update writes integration updates. Like the PostgreSQL repository it never
+// changes the kind.
+func (r *IntegrationRepository) Update(_ context.Context, integration *domain.Integration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.integrations[integration.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	for id, other := range r.integrations {
+		if id != integration.ID && other.Name == integration.Name {
+			return domain.ErrDuplicateName
+		}
+	}
+	updated := *integration
+	updated.Kind = existing.Kind
+	r.integrations[integration.ID] = updated
+	return nil
+}
+
+// Delete removes an integration by id.
+func (r *IntegrationRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.integrations[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.integrations, id)
+	return nil
+}
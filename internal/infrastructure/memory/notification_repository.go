@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/notification"
+)
+
+// NotificationChannelRepository is a thread-safe, in-memory
+// domain.Repository.
+type NotificationChannelRepository struct {
+	mu       sync.RWMutex
+	channels map[string]domain.Channel
+}
+
+// NewNotificationChannelRepository constructs an empty repository.
+func NewNotificationChannelRepository() *NotificationChannelRepository {
+	return &NotificationChannelRepository{channels: make(map[string]domain.Channel)}
+}
+
+var _ domain.Repository = (*NotificationChannelRepository)(nil)
+
+// Create inserts a new channel.
+func (r *NotificationChannelRepository) Create(_ context.Context, channel *domain.Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.channels {
+		if existing.Name == channel.Name {
+			return domain.ErrDuplicateName
+		}
+	}
+	r.channels[channel.ID] = copyChannel(*channel)
+	return nil
+}
+
+// GetByID fetches a channel by id.
+func (r *NotificationChannelRepository) GetByID(_ context.Context, id string) (*domain.Channel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.channels[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyChannel(c)
+	return &found, nil
+}
+
+// List returns all channels sorted by name.
+func (r *NotificationChannelRepository) List(_ context.Context) ([]*domain.Channel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var channels []*domain.Channel
+	for _, c := range r.channels {
+		found := copyChannel(c)
+		channels = append(channels, &found)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels, nil
+}
+
+// Update writes channel updates. Like the PostgreSQL repository it never
+// changes the kind.
+func (r *NotificationChannelRepository) Update(_ context.Context, channel *domain.Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.channels[channel.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	for id, other := range r.channels {
+		if id != channel.ID && other.Name == channel.Name {
+			return domain.ErrDuplicateName
+		}
+	}
+	updated := copyChannel(*channel)
+	updated.Kind = existing.Kind
+	r.channels[channel.ID] = updated
+	return nil
+}
+
+// Delete removes a channel by id.
+func (r *NotificationChannelRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.channels[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.channels, id)
+	return nil
+}
+
+func copyChannel(c domain.Channel) domain.Channel {
+	c.Events = slices.Clone(c.Events)
+	return c
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/order"
+)
+
+// OrderRepository is a thread-safe, in-memory domain.Repository. Given a
+// ProductRepository it takes and returns the stock of orders' lines like
+// PostgreSQL does.
+type OrderRepository struct {
+	mu       sync.RWMutex
+	orders   map[string]domain.Order
+	products *ProductRepository
+}
+
+// NewOrderRepository constructs an empty repository; products may be nil.
+func NewOrderRepository(products *ProductRepository) *OrderRepository {
+	return &OrderRepository{orders: make(map[string]domain.Order), products: products}
+}
+
+var _ domain.Repository = (*OrderRepository)(nil)
+
+// Create inserts an order with its lines and history once their stock is
+// taken.
+func (r *OrderRepository) Create(_ context.Context, o *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.products != nil {
+		deltas := o.Quantities()
+		for id := range deltas {
+			deltas[id] = -deltas[id]
+		}
+		if err := r.products.moveStock(deltas, o.CreatedAt, false); err != nil {
+			return err
+		}
+	}
+	r.orders[o.ID] = copyOrder(*o)
+	return nil
+}
+
+// GetByID fetches an order by id.
+func (r *OrderRepository) GetByID(_ context.Context, id string) (*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyOrder(o)
+	return &found, nil
+}
+
+// List returns orders with the status, or all when it is empty, newest
+// first.
+func (r *OrderRepository) List(_ context.Context, status string) ([]*domain.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var orders []*domain.Order
+	for _, o := range r.orders {
+		if status != "" && o.Status != status {
+			continue
+		}
+		found := copyOrder(o)
+		orders = append(orders, &found)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.After(orders[j].CreatedAt)
+		}
+		return orders[i].ID > orders[j].ID
+	})
+	return orders, nil
+}
+
+// Transition stores the order's status and the latest entry of its history,
+// returning the stock of a cancelled order's lines.
+func (r *OrderRepository) Transition(_ context.Context, o *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.orders[o.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	last := o.Last()
+	if existing.Status != last.From {
+		return domain.ErrStatusChanged
+	}
+	if o.Status == domain.StatusCancelled && r.products != nil {
+		if err := r.products.moveStock(existing.Quantities(), o.UpdatedAt, true); err != nil {
+			return err
+		}
+	}
+	existing.Status = o.Status
+	existing.UpdatedAt = o.UpdatedAt
+	existing.History = append(slices.Clone(existing.History), last)
+	r.orders[o.ID] = existing
+	return nil
+}
+
+// SetPaymentStatus records the status of the order's latest payment.
+func (r *OrderRepository) SetPaymentStatus(_ context.Context, id, status string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.orders[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.PaymentStatus = status
+	existing.UpdatedAt = at
+	r.orders[id] = existing
+	return nil
+}
+
+// AddTrackingNumber records the tracking number of a parcel sent for the
+// order.
+func (r *OrderRepository) AddTrackingNumber(_ context.Context, id, number string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.orders[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if !slices.Contains(existing.TrackingNumbers, number) {
+		existing.TrackingNumbers = append(slices.Clone(existing.TrackingNumbers), number)
+	}
+	existing.UpdatedAt = at
+	r.orders[id] = existing
+	return nil
+}
+
+// SetPromotion records the discount code redeemed on the order.
+func (r *OrderRepository) SetPromotion(_ context.Context, id, code string, discount, total float64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.orders[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.PromotionCode = code
+	existing.Discount = discount
+	existing.Total = total
+	existing.UpdatedAt = at
+	r.orders[id] = existing
+	return nil
+}
+
+func copyOrder(o domain.Order) domain.Order {
+	o.TrackingNumbers = slices.Clone(o.TrackingNumbers)
+	o.Lines = slices.Clone(o.Lines)
+	o.History = slices.Clone(o.History)
+	return o
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/payment"
+)
+
+// PaymentRepository is a thread-safe, in-memory domain.Repository.
+type PaymentRepository struct {
+	mu       sync.RWMutex
+	payments map[string]domain.Payment
+}
+
+// NewPaymentRepository constructs an empty repository.
+func NewPaymentRepository() *PaymentRepository {
+	return &PaymentRepository{payments: make(map[string]domain.Payment)}
+}
+
+var _ domain.Repository = (*PaymentRepository)(nil)
+
+// Create inserts a payment.
+func (r *PaymentRepository) Create(_ context.Context, p *domain.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payments[p.ID] = *p
+	return nil
+}
+
+// GetByID fetches a payment by id.
+func (r *PaymentRepository) GetByID(_ context.Context, id string) (*domain.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &p, nil
+}
+
+// GetByReference finds a payment by the provider's reference for it.
+func (r *PaymentRepository) GetByReference(_ context.Context, provider, reference string) (*domain.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.payments {
+		if p.Provider == provider && p.Reference == reference && reference != "" {
+			return &p, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// ListByOrder returns an order's payments, newest first.
+func (r *PaymentRepository) ListByOrder(_ context.Context, orderID string) ([]*domain.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var payments []*domain.Payment
+	for _, p := range r.payments {
+		if p.OrderID == orderID {
+			found := p
+			payments = append(payments, &found)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		if !payments[i].CreatedAt.Equal(payments[j].CreatedAt) {
+			return payments[i].CreatedAt.After(payments[j].CreatedAt)
+		}
+		return payments[i].ID > payments[j].ID
+	})
+	return payments, nil
+}
+
+// Update stores a payment's provider reference, status and refunds.
+func (r *PaymentRepository) Update(_ context.Context, p *domain.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.payments[p.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.Reference = p.Reference
+	existing.Status = p.Status
+	existing.Refunded = p.Refunded
+	existing.CheckoutURL = p.CheckoutURL
+	existing.FailureReason = p.FailureReason
+	existing.UpdatedAt = p.UpdatedAt
+	r.payments[p.ID] = existing
+	return nil
+}
@@ -0,0 +1,479 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/product"
+	"backoffice/backend/internal/domain/trash"
+)
+
+// ProductRepository is a thread-safe, in-memory domain.Repository that
+// mirrors the PostgreSQL implementation's errors and ordering.
+type ProductRepository struct {
+	mu       sync.RWMutex
+	products map[string]domain.Product
+	// movements mirrors the stock_movements ledger for StockValuation.
+	movements []stockMovement
+	trashed   map[string]trashedProduct
+	// reservations are kept until released, confirmed or purged; expired
+	// ones are ignored like in PostgreSQL.
+	reservations map[string]domain.Reservation
+	// translations are keyed by product and then locale, and go when their
+	// product is purged.
+	translations map[string]map[string]domain.Translation
+	nowFunc      func() time.Time
+}
+
+type trashedProduct struct {
+	product   domain.Product
+	deletedAt time.Time
+}
+
+type stockMovement struct {
+	productID  string
+	categoryID string
+	quantity   int
+	price      float64
+	at         time.Time
+}
+
+// NewProductRepository constructs an empty repository.
+func NewProductRepository() *ProductRepository {
+	return &ProductRepository{
+		products:     make(map[string]domain.Product),
+		trashed:      make(map[string]trashedProduct),
+		reservations: make(map[string]domain.Reservation),
+		translations: make(map[string]map[string]domain.Translation),
+		nowFunc:      time.Now,
+	}
+}
+
+var (
+	_ domain.Repository            = (*ProductRepository)(nil)
+	_ domain.ReservationRepository = (*ProductRepository)(nil)
+	_ domain.TranslationRepository = (*ProductRepository)(nil)
+	_ trash.Bin                    = (*ProductRepository)(nil)
+)
+
+// Create inserts a new product.
+func (r *ProductRepository) Create(_ context.Context, product *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.products[product.ID]; ok {
+		return domain.ErrDuplicateSKU
+	}
+	if err := r.conflict(*product); err != nil {
+		return err
+	}
+	r.products[product.ID] = *product
+	r.record(*product, product.Quantity)
+	return nil
+}
+
+// GetByID fetches a product by id.
+func (r *ProductRepository) GetByID(_ context.Context, id string) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.products[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	p.SetReserved(r.reserved(id, r.nowFunc()))
+	return &p, nil
+}
+
+// GetByIDs fetches the products among ids, in the order of ids.
+func (r *ProductRepository) GetByIDs(_ context.Context, ids []string) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var products []*domain.Product
+	seen := map[string]bool{}
+	for _, id := range ids {
+		p, ok := r.products[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		p.SetReserved(r.reserved(id, r.nowFunc()))
+		products = append(products, &p)
+	}
+	return products, nil
+}
+
+// GetBySKU fetches a product using its SKU.
+func (r *ProductRepository) GetBySKU(_ context.Context, sku string) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.products {
+		if p.SKU == sku {
+			found := p
+			found.SetReserved(r.reserved(p.ID, r.nowFunc()))
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// GetByBarcode fetches a product using its barcode.
+func (r *ProductRepository) GetByBarcode(_ context.Context, barcode string) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.products {
+		if p.Barcode != "" && p.Barcode == barcode {
+			found := p
+			found.SetReserved(r.reserved(p.ID, r.nowFunc()))
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List returns all products sorted by name.
+func (r *ProductRepository) List(_ context.Context) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var products []*domain.Product
+	now := r.nowFunc()
+	for _, p := range r.products {
+		found := p
+		found.SetReserved(r.reserved(p.ID, now))
+		products = append(products, &found)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].Name < products[j].Name })
+	return products, nil
+}
+
+// Update writes product updates and moves the stored quantity by delta,
+// checking both before either is applied.
+func (r *ProductRepository) Update(_ context.Context, product *domain.Product, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.products[product.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if err := r.conflict(*product); err != nil {
+		return err
+	}
+	if !domain.KeepsReserved(existing.Quantity, r.reserved(product.ID, r.nowFunc()), delta) {
+		return domain.ErrInsufficientStock
+	}
+	// As in PostgreSQL, the quantity moves relative to what is stored.
+	updated := *product
+	updated.Quantity = existing.Quantity + delta
+	r.products[product.ID] = updated
+	if delta != 0 || existing.Price != updated.Price || existing.CategoryID != updated.CategoryID {
+		r.record(updated, updated.Quantity)
+	}
+	product.Quantity = updated.Quantity
+	product.SetReserved(r.reserved(product.ID, r.nowFunc()))
+	return nil
+}
+
+// AdjustQuantity adds delta to the product's quantity under the write lock,
+// refusing with ErrInsufficientStock when the result would not cover the
+// reserved stock.
+func (r *ProductRepository) AdjustQuantity(_ context.Context, id string, delta int, at time.Time) (*domain.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.products[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if !domain.KeepsReserved(p.Quantity, r.reserved(id, r.nowFunc()), delta) {
+		return nil, domain.ErrInsufficientStock
+	}
+	p.Quantity += delta
+	p.UpdatedAt = at
+	r.products[id] = p
+	r.record(p, p.Quantity)
+	p.SetReserved(r.reserved(id, r.nowFunc()))
+	return &p, nil
+}
+
+// SetQuantity sets the product's quantity under the write lock.
+func (r *ProductRepository) SetQuantity(_ context.Context, id string, quantity int, at time.Time) (*domain.Product, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.products[id]
+	if !ok {
+		return nil, 0, domain.ErrNotFound
+	}
+	previous := p.Quantity
+	if quantity != previous && !domain.KeepsReserved(previous, r.reserved(id, r.nowFunc()), quantity-previous) {
+		return nil, 0, domain.ErrInsufficientStock
+	}
+	if quantity != previous {
+		p.Quantity = quantity
+		p.UpdatedAt = at
+		r.products[id] = p
+		r.record(p, p.Quantity)
+	}
+	p.SetReserved(r.reserved(id, r.nowFunc()))
+	return &p, previous, nil
+}
+
+// UpdatePrices applies every change or none.
+func (r *ProductRepository) UpdatePrices(_ context.Context, changes []domain.PriceChange, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, change := range changes {
+		if existing, ok := r.products[change.ProductID]; !ok || existing.Price != change.OldPrice {
+			return domain.ErrPriceChanged
+		}
+	}
+	for _, change := range changes {
+		product := r.products[change.ProductID]
+		product.Price = change.NewPrice
+		product.UpdatedAt = at
+		r.products[change.ProductID] = product
+		r.record(product, product.Quantity)
+	}
+	return nil
+}
+
+// Delete moves a product to the trash.
+func (r *ProductRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.products[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.products, id)
+	r.trashed[id] = trashedProduct{product: existing, deletedAt: r.nowFunc()}
+	r.record(existing, 0)
+	return nil
+}
+
+// DeleteMany moves all of ids to the trash, or none if any is missing.
+func (r *ProductRepository) DeleteMany(_ context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := r.products[id]; !ok {
+			return domain.ErrNotFound
+		}
+	}
+	now := r.nowFunc()
+	for _, id := range ids {
+		existing := r.products[id]
+		delete(r.products, id)
+		r.trashed[id] = trashedProduct{product: existing, deletedAt: now}
+		r.record(existing, 0)
+	}
+	return nil
+}
+
+// ListDeleted returns trashed products, most recently deleted first.
+func (r *ProductRepository) ListDeleted(_ context.Context) ([]trash.Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var items []trash.Item
+	for id, t := range r.trashed {
+		items = append(items, trash.Item{Kind: trash.KindProduct, ID: id, Name: t.product.Name, DeletedAt: t.deletedAt})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// Restore takes a product out of the trash unless its SKU or barcode has
+// been reused.
+func (r *ProductRepository) Restore(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.trashed[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if err := r.conflict(t.product); err != nil {
+		return err
+	}
+	delete(r.trashed, id)
+	r.products[id] = t.product
+	r.record(t.product, t.product.Quantity)
+	return nil
+}
+
+// Purge permanently deletes a trashed product.
+func (r *ProductRepository) Purge(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.trashed[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.trashed, id)
+	delete(r.translations, id)
+	return nil
+}
+
+// CountDeletedBefore counts products trashed before cutoff.
+func (r *ProductRepository) CountDeletedBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, t := range r.trashed {
+		if t.deletedAt.Before(cutoff) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// PurgeDeletedBefore permanently deletes products trashed before cutoff.
+func (r *ProductRepository) PurgeDeletedBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := 0
+	for id, t := range r.trashed {
+		if t.deletedAt.Before(cutoff) {
+			delete(r.trashed, id)
+			delete(r.translations, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// CountLowStock counts products with at most quantity in stock.
+func (r *ProductRepository) CountLowStock(_ context.Context, quantity int) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, p := range r.products {
+		if p.Quantity <= quantity {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// StockValuation values stock per category, now or from the ledger at asOf.
+// Category names are not known to this repository and are left empty.
+func (r *ProductRepository) StockValuation(_ context.Context, asOf *time.Time) ([]domain.ValuationLine, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var holdings []stockMovement
+	if asOf == nil {
+		for _, p := range r.products {
+			holdings = append(holdings, stockMovement{productID: p.ID, categoryID: p.CategoryID, quantity: p.Quantity, price: p.Price})
+		}
+	} else {
+		latest := map[string]stockMovement{}
+		for _, m := range r.movements {
+			if !m.at.After(*asOf) {
+				latest[m.productID] = m
+			}
+		}
+		for _, m := range latest {
+			if m.quantity != 0 {
+				holdings = append(holdings, m)
+			}
+		}
+	}
+
+	byCategory := map[string]*domain.ValuationLine{}
+	for _, h := range holdings {
+		line, ok := byCategory[h.categoryID]
+		if !ok {
+			line = &domain.ValuationLine{CategoryID: h.categoryID}
+			byCategory[h.categoryID] = line
+		}
+		line.Products++
+		line.Quantity += h.quantity
+		line.Value += float64(h.quantity) * h.price
+	}
+	lines := make([]domain.ValuationLine, 0, len(byCategory))
+	for _, line := range byCategory {
+		lines = append(lines, *line)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Value != lines[j].Value {
+			return lines[i].Value > lines[j].Value
+		}
+		return lines[i].CategoryID < lines[j].CategoryID
+	})
+	return lines, nil
+}
+
+// record appends a ledger entry; callers hold the write lock.
+func (r *ProductRepository) record(p domain.Product, quantityAfter int) {
+	r.movements = append(r.movements, stockMovement{
+		productID:  p.ID,
+		categoryID: p.CategoryID,
+		quantity:   quantityAfter,
+		price:      p.Price,
+		at:         r.nowFunc(),
+	})
+}
+
+// moveStock adds each of deltas, keyed by product id, to its product's
+// quantity, all or none, for orders that take stock or give it back. A
+// reduction must leave the product's reserved stock on hand. A missing
+// product fails with ErrNotFound unless skipMissing is set.
+func (r *ProductRepository) moveStock(deltas map[string]int, at time.Time, skipMissing bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range slices.Sorted(maps.Keys(deltas)) {
+		p, ok := r.products[id]
+		switch {
+		case !ok && skipMissing:
+			continue
+		case !ok:
+			return domain.ErrNotFound
+		case !domain.KeepsReserved(p.Quantity, r.reserved(id, at), deltas[id]):
+			return domain.ErrInsufficientStock.With("productId", id)
+		}
+	}
+	for id, delta := range deltas {
+		p, ok := r.products[id]
+		if !ok {
+			continue
+		}
+		p.Quantity += delta
+		p.UpdatedAt = at
+		r.products[id] = p
+		r.record(p, p.Quantity)
+	}
+	return nil
+}
+
+func (r *ProductRepository) referencesCategory(categoryID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.products {
+		if p.CategoryID == categoryID {
+			return true
+		}
+	}
+	// Trashed products keep their category, as with the foreign key in
+	// PostgreSQL.
+	for _, t := range r.trashed {
+		if t.product.CategoryID == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// conflict reports the unique field of product that another live product
+// already uses. Callers hold the lock.
+func (r *ProductRepository) conflict(product domain.Product) error {
+	for id, other := range r.products {
+		if id == product.ID {
+			continue
+		}
+		if other.SKU == product.SKU {
+			return domain.ErrDuplicateSKU
+		}
+		if product.Barcode != "" && other.Barcode == product.Barcode {
+			return domain.ErrDuplicateBarcode
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/promotion"
+)
+
+// PromotionRepository is a thread-safe, in-memory domain.Repository.
+type PromotionRepository struct {
+	mu         sync.RWMutex
+	promotions map[string]domain.Promotion
+}
+
+// NewPromotionRepository constructs an empty repository.
+func NewPromotionRepository() *PromotionRepository {
+	return &PromotionRepository{promotions: make(map[string]domain.Promotion)}
+}
+
+var _ domain.Repository = (*PromotionRepository)(nil)
+
+// Create inserts a promotion.
+func (r *PromotionRepository) Create(_ context.Context, p *domain.Promotion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.codeTaken(p.Code, p.ID) {
+		return domain.ErrDuplicateCode
+	}
+	r.promotions[p.ID] = copyPromotion(*p)
+	return nil
+}
+
+func (r *PromotionRepository) codeTaken(code, id string) bool {
+	for _, existing := range r.promotions {
+		if existing.Code == code && existing.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetByID fetches a promotion by id.
+func (r *PromotionRepository) GetByID(_ context.Context, id string) (*domain.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.promotions[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyPromotion(p)
+	return &found, nil
+}
+
+// GetByCode fetches a promotion by its code.
+func (r *PromotionRepository) GetByCode(_ context.Context, code string) (*domain.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.promotions {
+		if p.Code == code {
+			found := copyPromotion(p)
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// List returns every promotion ordered by code.
+func (r *PromotionRepository) List(context.Context) ([]*domain.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var promotions []*domain.Promotion
+	for _, p := range r.promotions {
+		found := copyPromotion(p)
+		promotions = append(promotions, &found)
+	}
+	sort.Slice(promotions, func(i, j int) bool { return promotions[i].Code < promotions[j].Code })
+	return promotions, nil
+}
+
+// Update writes every field but the usage count.
+func (r *PromotionRepository) Update(_ context.Context, p *domain.Promotion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.promotions[p.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if r.codeTaken(p.Code, p.ID) {
+		return domain.ErrDuplicateCode
+	}
+	updated := copyPromotion(*p)
+	updated.Used = existing.Used
+	updated.CreatedBy = existing.CreatedBy
+	updated.CreatedAt = existing.CreatedAt
+	r.promotions[p.ID] = updated
+	return nil
+}
+
+// Delete removes a promotion.
+func (r *PromotionRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.promotions[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.promotions, id)
+	return nil
+}
+
+// Redeem takes one use of the promotion.
+func (r *PromotionRepository) Redeem(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.promotions[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if p.UsageLimit > 0 && p.Used >= p.UsageLimit {
+		return domain.ErrExhausted
+	}
+	p.Used++
+	p.UpdatedAt = at
+	r.promotions[id] = p
+	return nil
+}
+
+// Release gives back a use taken by Redeem.
+func (r *PromotionRepository) Release(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.promotions[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	p.Used = max(p.Used-1, 0)
+	p.UpdatedAt = at
+	r.promotions[id] = p
+	return nil
+}
+
+func copyPromotion(p domain.Promotion) domain.Promotion {
+	p.ProductIDs = slices.Clone(p.ProductIDs)
+	p.CategoryIDs = slices.Clone(p.CategoryIDs)
+	return p
+}
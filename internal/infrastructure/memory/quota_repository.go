@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/quota"
+)
+
+// QuotaRepository is a thread-safe, in-memory domain.Repository.
+type QuotaRepository struct {
+	mu     sync.Mutex
+	counts map[quotaKey]int64
+}
+
+type quotaKey struct {
+	clientID string
+	month    time.Time
+}
+
+// NewQuotaRepository constructs an empty repository.
+func NewQuotaRepository() *QuotaRepository {
+	return &QuotaRepository{counts: make(map[quotaKey]int64)}
+}
+
+var _ domain.Repository = (*QuotaRepository)(nil)
+
+// Add increments the client's count for month.
+func (r *QuotaRepository) Add(_ context.Context, clientID string, month time.Time, requests int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := quotaKey{clientID: clientID, month: month.UTC()}
+	r.counts[key] += requests
+	return r.counts[key], nil
+}
+
+// Get returns the client's count for month.
+func (r *QuotaRepository) Get(_ context.Context, clientID string, month time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[quotaKey{clientID: clientID, month: month.UTC()}], nil
+}
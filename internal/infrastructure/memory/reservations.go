@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	domain "backoffice/backend/internal/domain/product"
+)
+
+// Reserve stores res when the product has enough unreserved stock. The
+// write lock makes the check and the insert atomic.
+func (r *ProductRepository) Reserve(_ context.Context, res *domain.Reservation, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.products[res.ProductID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if p.Quantity-r.reserved(p.ID, now) < res.Quantity {
+		return domain.ErrInsufficientStock
+	}
+	r.reservations[res.ID] = *res
+	return nil
+}
+
+// ListReservations returns the product's active reservations, oldest first.
+func (r *ProductRepository) ListReservations(_ context.Context, productID string, now time.Time) ([]*domain.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var reservations []*domain.Reservation
+	for _, res := range r.reservations {
+		if res.ProductID == productID && res.ExpiresAt.After(now) {
+			found := res
+			reservations = append(reservations, &found)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		if !reservations[i].CreatedAt.Equal(reservations[j].CreatedAt) {
+			return reservations[i].CreatedAt.Before(reservations[j].CreatedAt)
+		}
+		return reservations[i].ID < reservations[j].ID
+	})
+	return reservations, nil
+}
+
+// ListReservationsFor returns the active reservations of productIDs, by
+// product and then oldest first.
+func (r *ProductRepository) ListReservationsFor(_ context.Context, productIDs []string, now time.Time) ([]*domain.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var reservations []*domain.Reservation
+	for _, res := range r.reservations {
+		if slices.Contains(productIDs, res.ProductID) && res.ExpiresAt.After(now) {
+			found := res
+			reservations = append(reservations, &found)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		a, b := reservations[i], reservations[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return reservations, nil
+}
+
+// Release deletes an active reservation.
+func (r *ProductRepository) Release(_ context.Context, productID, id string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.activeReservation(productID, id, now); !ok {
+		return domain.ErrReservationNotFound
+	}
+	delete(r.reservations, id)
+	return nil
+}
+
+// Confirm turns an active reservation into a stock deduction.
+func (r *ProductRepository) Confirm(_ context.Context, productID, id string, now time.Time) (*domain.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.products[productID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	res, ok := r.activeReservation(productID, id, now)
+	if !ok {
+		return nil, domain.ErrReservationNotFound
+	}
+	if p.Quantity < res.Quantity {
+		return nil, domain.ErrInsufficientStock
+	}
+	delete(r.reservations, id)
+	p.Quantity -= res.Quantity
+	p.UpdatedAt = now
+	r.products[productID] = p
+	r.record(p, p.Quantity)
+	p.SetReserved(r.reserved(productID, now))
+	return &p, nil
+}
+
+// PurgeExpiredReservations deletes reservations that expired before cutoff.
+func (r *ProductRepository) PurgeExpiredReservations(_ context.Context, cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := 0
+	for id, res := range r.reservations {
+		if res.ExpiresAt.Before(cutoff) {
+			delete(r.reservations, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// reserved sums the product's active reservations; callers hold the lock.
+func (r *ProductRepository) reserved(productID string, now time.Time) int {
+	total := 0
+	for _, res := range r.reservations {
+		if res.ProductID == productID && res.ExpiresAt.After(now) {
+			total += res.Quantity
+		}
+	}
+	return total
+}
+
+// activeReservation looks up an unexpired reservation of the product;
+// callers hold the lock.
+func (r *ProductRepository) activeReservation(productID, id string, now time.Time) (domain.Reservation, bool) {
+	res, ok := r.reservations[id]
+	if !ok || res.ProductID != productID || !res.ExpiresAt.After(now) {
+		return domain.Reservation{}, false
+	}
+	return res, true
+}
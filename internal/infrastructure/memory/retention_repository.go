@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/retention"
+)
+
+// RetentionRepository is a thread-safe, in-memory domain.Repository.
+type RetentionRepository struct {
+	mu       sync.RWMutex
+	policies map[string]domain.Policy
+}
+
+// NewRetentionRepository constructs an empty repository.
+func NewRetentionRepository() *RetentionRepository {
+	return &RetentionRepository{policies: make(map[string]domain.Policy)}
+}
+
+var _ domain.Repository = (*RetentionRepository)(nil)
+
+// ListPolicies returns every saved policy sorted by target.
+func (r *RetentionRepository) ListPolicies(_ context.Context) ([]domain.Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	policies := make([]domain.Policy, 0, len(r.policies))
+	for _, p := range r.policies {
+		policies = append(policies, p)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Target < policies[j].Target })
+	return policies, nil
+}
+
+// SavePolicy inserts or replaces the policy for its target.
+func (r *RetentionRepository) SavePolicy(_ context.Context, policy domain.Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if policy.UpdatedAt != nil {
+		at := *policy.UpdatedAt
+		policy.UpdatedAt = &at
+	}
+	r.policies[policy.Target] = policy
+	return nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/returns"
+)
+
+// ReturnRepository is a thread-safe, in-memory domain.Repository.
+type ReturnRepository struct {
+	mu      sync.RWMutex
+	returns map[string]domain.Return
+}
+
+// NewReturnRepository constructs an empty repository.
+func NewReturnRepository() *ReturnRepository {
+	return &ReturnRepository{returns: make(map[string]domain.Return)}
+}
+
+var _ domain.Repository = (*ReturnRepository)(nil)
+
+// Create inserts a return with its lines.
+func (r *ReturnRepository) Create(_ context.Context, ret *domain.Return) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.returns[ret.ID] = copyReturn(*ret)
+	return nil
+}
+
+// GetByID fetches a return by id.
+func (r *ReturnRepository) GetByID(_ context.Context, id string) (*domain.Return, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ret, ok := r.returns[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyReturn(ret)
+	return &found, nil
+}
+
+// List returns the returns the filter selects, newest first.
+func (r *ReturnRepository) List(_ context.Context, filter domain.Filter) ([]*domain.Return, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var returns []*domain.Return
+	for _, ret := range r.returns {
+		if (filter.Status != "" && ret.Status != filter.Status) || (filter.OrderID != "" && ret.OrderID != filter.OrderID) {
+			continue
+		}
+		found := copyReturn(ret)
+		returns = append(returns, &found)
+	}
+	sort.Slice(returns, func(i, j int) bool {
+		if !returns[i].CreatedAt.Equal(returns[j].CreatedAt) {
+			return returns[i].CreatedAt.After(returns[j].CreatedAt)
+		}
+		return returns[i].ID > returns[j].ID
+	})
+	return returns, nil
+}
+
+// Update stores a decision on the return while it is still in status from.
+func (r *ReturnRepository) Update(_ context.Context, ret *domain.Return, from string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.returns[ret.ID]
+	if !ok || existing.Status != from {
+		return domain.ErrStatusChanged
+	}
+	r.returns[ret.ID] = copyReturn(*ret)
+	return nil
+}
+
+// Returned sums, per product, the quantity of an order covered by returns
+// that were not rejected.
+func (r *ReturnRepository) Returned(_ context.Context, orderID string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	returned := map[string]int{}
+	for _, ret := range r.returns {
+		if ret.OrderID != orderID || ret.Status == domain.StatusRejected {
+			continue
+		}
+		for _, line := range ret.Lines {
+			returned[line.ProductID] += line.Quantity
+		}
+	}
+	return returned, nil
+}
+
+func copyReturn(ret domain.Return) domain.Return {
+	ret.Lines = slices.Clone(ret.Lines)
+	if ret.DecidedAt != nil {
+		at := *ret.DecidedAt
+		ret.DecidedAt = &at
+	}
+	if ret.ReceivedAt != nil {
+		at := *ret.ReceivedAt
+		ret.ReceivedAt = &at
+	}
+	return ret
+}
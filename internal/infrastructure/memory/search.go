@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"backoffice/backend/internal/domain/search"
+)
+
+var (
+	_ search.Source = (*ProductRepository)(nil)
+	_ search.Source = (*UserRepository)(nil)
+	_ search.Source = (*CategoryRepository)(nil)
+)
+
+// Search matches products by name or SKU substring.
+func (r *ProductRepository) Search(_ context.Context, query string, limit int) ([]search.Hit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var m matcher
+	for _, p := range r.products {
+		m.match(query, search.Hit{ID: p.ID, Title: p.Name, Subtitle: p.SKU}, p.Name, p.SKU)
+	}
+	return m.best(limit), nil
+}
+
+// Search matches users by name or email substring.
+func (r *UserRepository) Search(_ context.Context, query string, limit int) ([]search.Hit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var m matcher
+	for _, u := range r.users {
+		title := u.Name
+		if title == "" {
+			title = u.Email
+		}
+		m.match(query, search.Hit{ID: u.ID, Title: title, Subtitle: u.Email}, u.Name, u.Email)
+	}
+	return m.best(limit), nil
+}
+
+// Search matches categories by name substring.
+func (r *CategoryRepository) Search(_ context.Context, query string, limit int) ([]search.Hit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var m matcher
+	for _, c := range r.categories {
+		m.match(query, search.Hit{ID: c.ID, Title: c.Name, Subtitle: c.Slug}, c.Name)
+	}
+	return m.best(limit), nil
+}
+
+// matcher approximates the PostgreSQL ranking without trigrams: prefix
+// matches first, then by the share of the label the query covers.
+type matcher struct {
+	hits   []search.Hit
+	prefix map[string]bool
+}
+
+func (m *matcher) match(query string, hit search.Hit, labels ...string) {
+	query = strings.ToLower(query)
+	matched := false
+	for _, label := range labels {
+		lower := strings.ToLower(label)
+		if !strings.Contains(lower, query) {
+			continue
+		}
+		matched = true
+		hit.Score = math.Max(hit.Score, math.Round(float64(len(query))/float64(len(lower))*1000)/1000)
+		if strings.HasPrefix(lower, query) {
+			if m.prefix == nil {
+				m.prefix = map[string]bool{}
+			}
+			m.prefix[hit.ID] = true
+		}
+	}
+	if matched {
+		m.hits = append(m.hits, hit)
+	}
+}
+
+func (m *matcher) best(limit int) []search.Hit {
+	sort.Slice(m.hits, func(i, j int) bool {
+		a, b := m.hits[i], m.hits[j]
+		if m.prefix[a.ID] != m.prefix[b.ID] {
+			return m.prefix[a.ID]
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Title < b.Title
+	})
+	if len(m.hits) > limit {
+		m.hits = m.hits[:limit]
+	}
+	return m.hits
+}
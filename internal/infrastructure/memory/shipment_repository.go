@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/shipment"
+)
+
+// ShipmentRepository is a thread-safe, in-memory domain.Repository.
+type ShipmentRepository struct {
+	mu        sync.RWMutex
+	shipments map[string]domain.Shipment
+}
+
+// NewShipmentRepository constructs an empty repository.
+func NewShipmentRepository() *ShipmentRepository {
+	return &ShipmentRepository{shipments: make(map[string]domain.Shipment)}
+}
+
+var _ domain.Repository = (*ShipmentRepository)(nil)
+
+// Create inserts a shipment.
+func (r *ShipmentRepository) Create(_ context.Context, s *domain.Shipment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shipments[s.ID] = copyShipment(*s)
+	return nil
+}
+
+// GetByID fetches a shipment by id.
+func (r *ShipmentRepository) GetByID(_ context.Context, id string) (*domain.Shipment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.shipments[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyShipment(s)
+	return &found, nil
+}
+
+// GetByTracking finds a shipment by its carrier and tracking number.
+func (r *ShipmentRepository) GetByTracking(_ context.Context, carrier, trackingNumber string) (*domain.Shipment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, s := range r.shipments {
+		if s.Carrier == carrier && s.TrackingNumber == trackingNumber {
+			found := copyShipment(s)
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// ListByOrder returns an order's shipments, newest first.
+func (r *ShipmentRepository) ListByOrder(_ context.Context, orderID string) ([]*domain.Shipment, error) {
+	shipments := r.filter(func(s domain.Shipment) bool { return s.OrderID == orderID })
+	sort.Slice(shipments, func(i, j int) bool {
+		if !shipments[i].CreatedAt.Equal(shipments[j].CreatedAt) {
+			return shipments[i].CreatedAt.After(shipments[j].CreatedAt)
+		}
+		return shipments[i].ID > shipments[j].ID
+	})
+	return shipments, nil
+}
+
+// ListOpen returns the shipments that are not delivered or returned,
+// oldest first.
+func (r *ShipmentRepository) ListOpen(context.Context) ([]*domain.Shipment, error) {
+	shipments := r.filter(func(s domain.Shipment) bool { return !domain.Final(s.Status) })
+	sort.Slice(shipments, func(i, j int) bool {
+		if !shipments[i].CreatedAt.Equal(shipments[j].CreatedAt) {
+			return shipments[i].CreatedAt.Before(shipments[j].CreatedAt)
+		}
+		return shipments[i].ID < shipments[j].ID
+	})
+	return shipments, nil
+}
+
+func (r *ShipmentRepository) filter(keep func(domain.Shipment) bool) []*domain.Shipment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var shipments []*domain.Shipment
+	for _, s := range r.shipments {
+		if keep(s) {
+			found := copyShipment(s)
+			shipments = append(shipments, &found)
+		}
+	}
+	return shipments
+}
+
+// Update stores a shipment's status and tracking events.
+func (r *ShipmentRepository) Update(_ context.Context, s *domain.Shipment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.shipments[s.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existing.Status = s.Status
+	existing.Events = slices.Clone(s.Events)
+	existing.UpdatedAt = s.UpdatedAt
+	existing.DeliveredAt = s.DeliveredAt
+	r.shipments[s.ID] = existing
+	return nil
+}
+
+func copyShipment(s domain.Shipment) domain.Shipment {
+	s.Events = slices.Clone(s.Events)
+	return s
+}
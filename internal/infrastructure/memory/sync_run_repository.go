@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/stocksync"
+)
+
+// SyncRunRepository is a thread-safe, in-memory domain.RunRepository.
+type SyncRunRepository struct {
+	mu   sync.RWMutex
+	runs map[string]domain.Run
+}
+
+// NewSyncRunRepository constructs an empty repository.
+func NewSyncRunRepository() *SyncRunRepository {
+	return &SyncRunRepository{runs: make(map[string]domain.Run)}
+}
+
+var _ domain.RunRepository = (*SyncRunRepository)(nil)
+
+// CreateRun inserts a new run.
+func (r *SyncRunRepository) CreateRun(_ context.Context, run *domain.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs[run.ID] = copyRun(*run)
+	return nil
+}
+
+// UpdateRun writes a run's progress or outcome.
+func (r *SyncRunRepository) UpdateRun(_ context.Context, run *domain.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.runs[run.ID]; !ok {
+		return domain.ErrRunNotFound
+	}
+	r.runs[run.ID] = copyRun(*run)
+	return nil
+}
+
+// GetRun fetches a run by id.
+func (r *SyncRunRepository) GetRun(_ context.Context, id string) (*domain.Run, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	run, ok := r.runs[id]
+	if !ok {
+		return nil, domain.ErrRunNotFound
+	}
+	found := copyRun(run)
+	return &found, nil
+}
+
+// ListRuns returns matching runs, newest first.
+func (r *SyncRunRepository) ListRuns(_ context.Context, filter domain.RunFilter) ([]*domain.Run, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var runs []*domain.Run
+	for _, run := range r.runs {
+		if filter.Connector != "" && run.Connector != filter.Connector {
+			continue
+		}
+		found := copyRun(run)
+		runs = append(runs, &found)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+	if len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+	return runs, nil
+}
+
+// copyRun detaches the stored run from the caller's slices and pointers.
+func copyRun(run domain.Run) domain.Run {
+	run.Problems = append([]string{}, run.Problems...)
+	if run.FinishedAt != nil {
+		finishedAt := *run.FinishedAt
+		run.FinishedAt = &finishedAt
+	}
+	return run
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	domain "backoffice/backend/internal/domain/product"
+)
+
+// PutTranslation creates or replaces the product's translation in
+// t.Locale. Trashed products keep theirs, as in PostgreSQL.
+func (r *ProductRepository) PutTranslation(_ context.Context, t *domain.Translation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, live := r.products[t.ProductID]
+	_, trashed := r.trashed[t.ProductID]
+	if !live && !trashed {
+		return domain.ErrNotFound
+	}
+	if r.translations[t.ProductID] == nil {
+		r.translations[t.ProductID] = make(map[string]domain.Translation)
+	}
+	r.translations[t.ProductID][t.Locale] = *t
+	return nil
+}
+
+// ListTranslations returns the product's translations ordered by locale.
+func (r *ProductRepository) ListTranslations(_ context.Context, productID string) ([]*domain.Translation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var translations []*domain.Translation
+	for _, t := range r.translations[productID] {
+		found := t
+		translations = append(translations, &found)
+	}
+	sort.Slice(translations, func(i, j int) bool { return translations[i].Locale < translations[j].Locale })
+	return translations, nil
+}
+
+// ListTranslationsFor returns the translations of productIDs in locales, by
+// product and then locale.
+func (r *ProductRepository) ListTranslationsFor(_ context.Context, productIDs, locales []string) ([]*domain.Translation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var translations []*domain.Translation
+	for _, id := range productIDs {
+		for _, t := range r.translations[id] {
+			if slices.Contains(locales, t.Locale) {
+				found := t
+				translations = append(translations, &found)
+			}
+		}
+	}
+	sort.Slice(translations, func(i, j int) bool {
+		if translations[i].ProductID != translations[j].ProductID {
+			return translations[i].ProductID < translations[j].ProductID
+		}
+		return translations[i].Locale < translations[j].Locale
+	})
+	return translations, nil
+}
+
+// DeleteTranslation removes the product's translation in locale.
+func (r *ProductRepository) DeleteTranslation(_ context.Context, productID, locale string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.translations[productID][locale]; !ok {
+		return domain.ErrTranslationNotFound
+	}
+	delete(r.translations[productID], locale)
+	return nil
+}
@@ -0,0 +1,350 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/trash"
+)
+
+// UserRepository is a thread-safe, in-memory domain.UserRepository that
+// mirrors the PostgreSQL implementation's errors and ordering.
+type UserRepository struct {
+	mu      sync.RWMutex
+	users   map[string]domain.User
+	logins  map[string]time.Time
+	trashed map[string]trashedUser
+	// anonymized holds scrubbed users, which are neither live nor trashed.
+	anonymized map[string]domain.User
+}
+
+type trashedUser struct {
+	user      domain.User
+	deletedAt time.Time
+}
+
+// NewUserRepository constructs an empty repository.
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:      make(map[string]domain.User),
+		logins:     make(map[string]time.Time),
+		trashed:    make(map[string]trashedUser),
+		anonymized: make(map[string]domain.User),
+	}
+}
+
+var (
+	_ domain.UserRepository = (*UserRepository)(nil)
+	_ trash.Bin             = (*UserRepository)(nil)
+)
+
+// Create inserts a new user record.
+func (r *UserRepository) Create(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(user)
+}
+
+func (r *UserRepository) create(user *domain.User) error {
+	if _, ok := r.users[user.ID]; ok {
+		return domain.ErrEmailExists
+	}
+	for _, existing := range r.users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return domain.ErrEmailExists
+		}
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+// CreatePromotingFirst inserts a new user record, as an admin if the
+// repository holds no users at all.
+func (r *UserRepository) CreatePromotingFirst(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.users)+len(r.trashed)+len(r.anonymized) == 0 {
+		user.Role = domain.RoleAdmin
+	}
+	return r.create(user)
+}
+
+// GetByEmail fetches a user by email, ignoring case.
+func (r *UserRepository) GetByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			found := u
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// GetByID retrieves a user by id.
+func (r *UserRepository) GetByID(_ context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+// GetByIDs fetches the users among ids.
+func (r *UserRepository) GetByIDs(_ context.Context, ids []string) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var users []*domain.User
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if u, ok := r.users[id]; ok && !seen[id] {
+			seen[id] = true
+			users = append(users, &u)
+		}
+	}
+	return users, nil
+}
+
+// List returns users filtered by the provided criteria, newest first.
+func (r *UserRepository) List(_ context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var users []*domain.User
+	for _, u := range r.users {
+		if filter.Role != "" && u.Role != filter.Role {
+			continue
+		}
+		found := u
+		users = append(users, &found)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.After(users[j].CreatedAt) })
+	return users, nil
+}
+
+// Update modifies an existing user record.
+func (r *UserRepository) Update(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.users[user.ID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	for id, other := range r.users {
+		if id != user.ID && strings.EqualFold(other.Email, user.Email) {
+			return domain.ErrEmailExists
+		}
+	}
+	existing.Email = user.Email
+	existing.Name = user.Name
+	existing.Role = user.Role
+	existing.Locale = user.Locale
+	existing.Timezone = user.Timezone
+	existing.UpdatedAt = user.UpdatedAt
+	r.users[user.ID] = existing
+	return nil
+}
+
+// Delete moves a user to the trash.
+func (r *UserRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(r.users, id)
+	r.trashed[id] = trashedUser{user: user, deletedAt: time.Now()}
+	return nil
+}
+
+// DeleteMany moves all of ids to the trash, or none if any is missing.
+func (r *UserRepository) DeleteMany(_ context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := r.users[id]; !ok {
+			return domain.ErrUserNotFound
+		}
+	}
+	now := time.Now()
+	for _, id := range ids {
+		r.trashed[id] = trashedUser{user: r.users[id], deletedAt: now}
+		delete(r.users, id)
+	}
+	return nil
+}
+
+// Anonymize replaces a live user with its scrubbed fields and takes it out
+// of use for good.
+func (r *UserRepository) Anonymize(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.users[user.ID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	anonymized := *user
+	anonymized.TokenVersion = existing.TokenVersion + 1
+	delete(r.users, user.ID)
+	delete(r.logins, user.ID)
+	r.anonymized[user.ID] = anonymized
+	return nil
+}
+
+// ListDeleted returns trashed users, most recently deleted first.
+func (r *UserRepository) ListDeleted(_ context.Context) ([]trash.Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var items []trash.Item
+	for id, t := range r.trashed {
+		name := t.user.Name
+		if name == "" {
+			name = t.user.Email
+		}
+		items = append(items, trash.Item{Kind: trash.KindUser, ID: id, Name: name, DeletedAt: t.deletedAt})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// Restore takes a user out of the trash unless its email has been reused.
+func (r *UserRepository) Restore(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.trashed[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	for _, other := range r.users {
+		if strings.EqualFold(other.Email, t.user.Email) {
+			return domain.ErrEmailExists
+		}
+	}
+	delete(r.trashed, id)
+	r.users[id] = t.user
+	return nil
+}
+
+// Purge permanently deletes a trashed user.
+func (r *UserRepository) Purge(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.trashed[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(r.trashed, id)
+	delete(r.logins, id)
+	return nil
+}
+
+// CountDeletedBefore counts users trashed before cutoff.
+func (r *UserRepository) CountDeletedBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, t := range r.trashed {
+		if t.deletedAt.Before(cutoff) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// PurgeDeletedBefore permanently deletes users trashed before cutoff.
+func (r *UserRepository) PurgeDeletedBefore(_ context.Context, cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := 0
+	for id, t := range r.trashed {
+		if t.deletedAt.Before(cutoff) {
+			delete(r.trashed, id)
+			delete(r.logins, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// CountByRole returns how many users hold the role.
+func (r *UserRepository) CountByRole(_ context.Context, role domain.UserRole) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, u := range r.users {
+		if u.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// RecordLogin stamps the user's last successful login.
+func (r *UserRepository) RecordLogin(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	r.logins[id] = at
+	return nil
+}
+
+// Stats aggregates users by role and activity, plus signups per UTC day
+// since signupsSince. Days without signups are omitted.
+func (r *UserRepository) Stats(_ context.Context, activeSince, signupsSince time.Time) (*domain.UserStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := &domain.UserStats{ByRole: map[domain.UserRole]int{}}
+	signups := map[string]int{}
+	for id, u := range r.users {
+		stats.Total++
+		stats.ByRole[u.Role]++
+		if login, ok := r.logins[id]; ok && !login.Before(activeSince) {
+			stats.Active++
+		}
+		if !u.CreatedAt.Before(signupsSince) {
+			signups[u.CreatedAt.UTC().Format(time.DateOnly)]++
+		}
+	}
+	stats.Dormant = stats.Total - stats.Active
+	for day, count := range signups {
+		stats.Signups = append(stats.Signups, domain.DailyCount{Date: day, Count: count})
+	}
+	sort.Slice(stats.Signups, func(i, j int) bool { return stats.Signups[i].Date < stats.Signups[j].Date })
+	return stats, nil
+}
+
+// UpdatePassword updates the stored password hash for a user.
+func (r *UserRepository) UpdatePassword(_ context.Context, id, passwordHash string, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	u.PasswordHash = passwordHash
+	u.UpdatedAt = updatedAt
+	u.TokenVersion++
+	r.users[id] = u
+	return nil
+}
+
+// BumpTokenVersion invalidates every token issued to the user so far.
+func (r *UserRepository) BumpTokenVersion(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	u.TokenVersion++
+	r.users[id] = u
+	return nil
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/view"
+)
+
+// ViewRepository is a thread-safe, in-memory domain.Repository.
+type ViewRepository struct {
+	mu    sync.RWMutex
+	views map[string]domain.View
+}
+
+// NewViewRepository constructs an empty repository.
+func NewViewRepository() *ViewRepository {
+	return &ViewRepository{views: make(map[string]domain.View)}
+}
+
+var _ domain.Repository = (*ViewRepository)(nil)
+
+// Create inserts a view.
+func (r *ViewRepository) Create(_ context.Context, v *domain.View) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.nameTaken(v) {
+		return domain.ErrDuplicateName
+	}
+	r.views[v.ID] = copyView(*v)
+	return nil
+}
+
+func (r *ViewRepository) nameTaken(v *domain.View) bool {
+	for _, existing := range r.views {
+		if existing.UserID == v.UserID && existing.Resource == v.Resource && existing.Name == v.Name && existing.ID != v.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetByID fetches a view by id.
+func (r *ViewRepository) GetByID(_ context.Context, id string) (*domain.View, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.views[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyView(v)
+	return &found, nil
+}
+
+// List returns the user's views of resource, or of every list when resource
+// is empty, ordered by list and then name.
+func (r *ViewRepository) List(_ context.Context, userID, resource string) ([]*domain.View, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var views []*domain.View
+	for _, v := range r.views {
+		if v.UserID == userID && (resource == "" || v.Resource == resource) {
+			found := copyView(v)
+			views = append(views, &found)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Resource != views[j].Resource {
+			return views[i].Resource < views[j].Resource
+		}
+		return views[i].Name < views[j].Name
+	})
+	return views, nil
+}
+
+// Update stores a view's name, filters and sort.
+func (r *ViewRepository) Update(_ context.Context, v *domain.View) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.views[v.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if r.nameTaken(v) {
+		return domain.ErrDuplicateName
+	}
+	existing.Name = v.Name
+	existing.Filters = slices.Clone(v.Filters)
+	existing.Sort = v.Sort
+	existing.UpdatedAt = v.UpdatedAt
+	r.views[v.ID] = existing
+	return nil
+}
+
+// Delete removes a view.
+func (r *ViewRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.views[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.views, id)
+	return nil
+}
+
+func copyView(v domain.View) domain.View {
+	v.Filters = slices.Clone(v.Filters)
+	return v
+}
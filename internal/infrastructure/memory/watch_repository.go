@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	domain "backoffice/backend/internal/domain/watch"
+)
+
+// WatchRepository is a thread-safe, in-memory domain.Repository. Unlike the
+// PostgreSQL one it does not check that the product exists.
+type WatchRepository struct {
+	mu            sync.RWMutex
+	watches       map[watchKey]domain.Watch
+	notifications []domain.Notification
+}
+
+type watchKey struct {
+	userID, productID string
+}
+
+// NewWatchRepository constructs an empty repository.
+func NewWatchRepository() *WatchRepository {
+	return &WatchRepository{watches: make(map[watchKey]domain.Watch)}
+}
+
+var _ domain.Repository = (*WatchRepository)(nil)
+
+// Put creates or replaces the user's watch of w.ProductID, keeping the
+// creation time of a watch it replaces.
+func (r *WatchRepository) Put(_ context.Context, w *domain.Watch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := watchKey{w.UserID, w.ProductID}
+	stored := copyWatch(*w)
+	if existing, ok := r.watches[key]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.watches[key] = stored
+	return nil
+}
+
+// Get fetches the user's watch of a product.
+func (r *WatchRepository) Get(_ context.Context, userID, productID string) (*domain.Watch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.watches[watchKey{userID, productID}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copyWatch(w)
+	return &found, nil
+}
+
+// ListByUser returns the user's watches, newest first.
+func (r *WatchRepository) ListByUser(_ context.Context, userID string) ([]*domain.Watch, error) {
+	watches := r.list(func(w domain.Watch) bool { return w.UserID == userID })
+	sort.SliceStable(watches, func(i, j int) bool { return watches[i].CreatedAt.After(watches[j].CreatedAt) })
+	return watches, nil
+}
+
+// ListByProduct returns every watch of the product.
+func (r *WatchRepository) ListByProduct(_ context.Context, productID string) ([]*domain.Watch, error) {
+	watches := r.list(func(w domain.Watch) bool { return w.ProductID == productID })
+	sort.SliceStable(watches, func(i, j int) bool { return watches[i].CreatedAt.Before(watches[j].CreatedAt) })
+	return watches, nil
+}
+
+func (r *WatchRepository) list(keep func(domain.Watch) bool) []*domain.Watch {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var watches []*domain.Watch
+	for _, w := range r.watches {
+		if keep(w) {
+			found := copyWatch(w)
+			watches = append(watches, &found)
+		}
+	}
+	return watches
+}
+
+// SetLast records the name and snapshot every watcher of the product was
+// told about.
+func (r *WatchRepository) SetLast(_ context.Context, productID, name string, last domain.Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, w := range r.watches {
+		if w.ProductID == productID {
+			w.ProductName = name
+			w.Last = last
+			r.watches[key] = w
+		}
+	}
+	return nil
+}
+
+// Delete removes the user's watch of a product.
+func (r *WatchRepository) Delete(_ context.Context, userID, productID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := watchKey{userID, productID}
+	if _, ok := r.watches[key]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.watches, key)
+	return nil
+}
+
+// AddNotification stores a notification.
+func (r *WatchRepository) AddNotification(_ context.Context, n *domain.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *n
+	stored.Changes = slices.Clone(n.Changes)
+	r.notifications = append(r.notifications, stored)
+	return nil
+}
+
+// ListNotifications returns the user's latest notifications, newest first.
+func (r *WatchRepository) ListNotifications(_ context.Context, userID string, limit int) ([]*domain.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var notifications []*domain.Notification
+	for i := len(r.notifications) - 1; i >= 0 && len(notifications) < limit; i-- {
+		if n := r.notifications[i]; n.UserID == userID {
+			n.Changes = slices.Clone(n.Changes)
+			notifications = append(notifications, &n)
+		}
+	}
+	return notifications, nil
+}
+
+// ClearNotifications removes every notification of the user.
+func (r *WatchRepository) ClearNotifications(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = slices.DeleteFunc(r.notifications, func(n domain.Notification) bool { return n.UserID == userID })
+	return nil
+}
+
+func copyWatch(w domain.Watch) domain.Watch {
+	w.Fields = slices.Clone(w.Fields)
+	return w
+}
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/webhook"
+)
+
+// WebhookRepository is a thread-safe, in-memory domain.Repository.
+type WebhookRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]domain.Subscription
+	deliveries    map[string]domain.Delivery
+}
+
+// NewWebhookRepository constructs an empty repository.
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		subscriptions: make(map[string]domain.Subscription),
+		deliveries:    make(map[string]domain.Delivery),
+	}
+}
+
+var _ domain.Repository = (*WebhookRepository)(nil)
+
+// CreateSubscription inserts a new subscription.
+func (r *WebhookRepository) CreateSubscription(_ context.Context, sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscriptions[sub.ID] = copySubscription(*sub)
+	return nil
+}
+
+// GetSubscription fetches a subscription by id.
+func (r *WebhookRepository) GetSubscription(_ context.Context, id string) (*domain.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sub, ok := r.subscriptions[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := copySubscription(sub)
+	return &found, nil
+}
+
+// ListSubscriptions returns all subscriptions, oldest first.
+func (r *WebhookRepository) ListSubscriptions(_ context.Context) ([]*domain.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var subs []*domain.Subscription
+	for _, sub := range r.subscriptions {
+		found := copySubscription(sub)
+		subs = append(subs, &found)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs, nil
+}
+
+// UpdateSubscription writes subscription updates.
+func (r *WebhookRepository) UpdateSubscription(_ context.Context, sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.subscriptions[sub.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	updated := copySubscription(*sub)
+	updated.CreatedAt = existing.CreatedAt
+	r.subscriptions[sub.ID] = updated
+	return nil
+}
+
+// DeleteSubscription removes a subscription and its deliveries.
+func (r *WebhookRepository) DeleteSubscription(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subscriptions[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.subscriptions, id)
+	for deliveryID, d := range r.deliveries {
+		if d.SubscriptionID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// CreateDelivery queues a delivery.
+func (r *WebhookRepository) CreateDelivery(_ context.Context, d *domain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[d.ID] = copyDelivery(*d)
+	return nil
+}
+
+// GetDelivery fetches a delivery by id.
+func (r *WebhookRepository) GetDelivery(_ context.Context, id string) (*domain.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	found := copyDelivery(d)
+	return &found, nil
+}
+
+// ListDeliveries returns the newest deliveries for a subscription.
+func (r *WebhookRepository) ListDeliveries(_ context.Context, subscriptionID string, limit int) ([]*domain.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var deliveries []*domain.Delivery
+	for _, d := range r.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			found := copyDelivery(d)
+			deliveries = append(deliveries, &found)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery records the outcome of an attempt.
+func (r *WebhookRepository) UpdateDelivery(_ context.Context, d *domain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.deliveries[d.ID]
+	if !ok {
+		return domain.ErrDeliveryNotFound
+	}
+	existing.Status = d.Status
+	existing.Attempts = d.Attempts
+	existing.LastStatusCode = d.LastStatusCode
+	existing.LastError = d.LastError
+	existing.NextAttemptAt = d.NextAttemptAt
+	existing.UpdatedAt = d.UpdatedAt
+	r.deliveries[d.ID] = existing
+	return nil
+}
+
+// ClaimDue leases due pending deliveries, earliest first.
+func (r *WebhookRepository) ClaimDue(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []domain.Delivery
+	for _, d := range r.deliveries {
+		if d.Status == domain.StatusPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*domain.Delivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		r.deliveries[d.ID] = d
+		found := copyDelivery(d)
+		claimed = append(claimed, &found)
+	}
+	return claimed, nil
+}
+
+func copySubscription(sub domain.Subscription) domain.Subscription {
+	sub.Events = slices.Clone(sub.Events)
+	return sub
+}
+
+func copyDelivery(d domain.Delivery) domain.Delivery {
+	d.Payload = slices.Clone(d.Payload)
+	return d
+}
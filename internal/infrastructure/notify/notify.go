@@ -0,0 +1,71 @@
+// Package notify posts messages to chat tools through their incoming
+// webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/notification"
+)
+
+// Sender posts notification messages to Slack and Microsoft Teams channels.
+type Sender struct {
+	client *http.Client
+}
+
+var _ notification.Sender = (*Sender)(nil)
+
+// NewSender constructs a sender whose requests time out after timeout.
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{client: &http.Client{Timeout: timeout}}
+}
+
+// Send formats msg for the channel's kind and posts it.
+func (s *Sender) Send(ctx context.Context, channel *notification.Channel, msg notification.Message) error {
+	switch channel.Kind {
+	case notification.KindSlack:
+		return post(ctx, s.client, "slack", channel.WebhookURL, slackMessage(msg.Title, msg.Text))
+	case notification.KindTeams:
+		return post(ctx, s.client, "teams", channel.WebhookURL, teamsMessage(msg.Title, msg.Text))
+	default:
+		return fmt.Errorf("unsupported channel kind %q", channel.Kind)
+	}
+}
+
+// post sends payload as JSON. Webhook URLs carry their credential, so they
+// are kept out of the returned errors.
+func post(ctx context.Context, client *http.Client, tool, webhookURL string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s webhook: invalid URL", tool)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s webhook: %w", tool, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s webhook responded %d: %s", tool, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/alert"
+)
+
+// Slack posts threshold alerts to a Slack incoming webhook.
+type Slack struct {
+	webhookURL string
+	client     *http.Client
+}
+
+var _ alert.Notifier = (*Slack)(nil)
+
+// NewSlack targets the incoming webhook at webhookURL.
+func NewSlack(webhookURL string) (*Slack, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid Slack webhook URL")
+	}
+	return &Slack{webhookURL: webhookURL, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Notify posts the alert as a single message.
+func (s *Slack) Notify(ctx context.Context, a alert.Alert) error {
+	icon := ":rotating_light:"
+	if !a.Firing {
+		icon = ":white_check_mark:"
+	}
+	return post(ctx, s.client, "slack", s.webhookURL, map[string]string{"text": icon + " " + slackEscaper.Replace(a.Message)})
+}
+
+// slackEscaper escapes the characters Slack reads as markup, so text such as
+// "Name <email>" is not taken for a link.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMessage renders a titled message in Slack's mrkdwn.
+func slackMessage(title, text string) map[string]string {
+	return map[string]string{"text": "*" + slackEscaper.Replace(title) + "*\n" + slackEscaper.Replace(text)}
+}
@@ -0,0 +1,21 @@
+package notify
+
+// teamsMessage wraps a titled message in the Adaptive Card envelope that
+// Teams workflow webhooks accept.
+func teamsMessage(title, text string) map[string]any {
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body": []map[string]any{
+					{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "wrap": true},
+					{"type": "TextBlock", "text": text, "wrap": true},
+				},
+			},
+		}},
+	}
+}
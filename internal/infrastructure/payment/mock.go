@@ -0,0 +1,79 @@
+// Package payment implements payment providers: Stripe, and a mock for
+// development whose webhooks are sent by hand.
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	domain "backoffice/backend/internal/domain/payment"
+)
+
+// MockSignatureHeader carries the mock provider's webhook signature,
+// "sha256=<hex HMAC-SHA256 of the body>".
+const MockSignatureHeader = "X-Mock-Signature"
+
+// Mock is a provider that takes no money. Payments are settled by posting
+// {"reference": "...", "status": "succeeded"|"failed", "reason": "..."} to
+// its webhook, signed with the shared secret.
+type Mock struct {
+	secret string
+}
+
+var _ domain.Provider = (*Mock)(nil)
+
+// NewMock constructs a mock provider whose webhooks are signed with secret.
+func NewMock(secret string) *Mock {
+	return &Mock{secret: secret}
+}
+
+// Name is "mock".
+func (m *Mock) Name() string {
+	return "mock"
+}
+
+// Start returns a reference derived from the payment id and a checkout URL
+// nobody can visit.
+func (m *Mock) Start(_ context.Context, checkout domain.Checkout) (*domain.Session, error) {
+	reference := "mock_" + checkout.PaymentID
+	return &domain.Session{Reference: reference, CheckoutURL: "mock://checkout/" + reference}, nil
+}
+
+// Webhook verifies the signature and reads the outcome.
+func (m *Mock) Webhook(payload []byte, header http.Header) (*domain.Outcome, error) {
+	given, ok := strings.CutPrefix(strings.TrimSpace(header.Get(MockSignatureHeader)), "sha256=")
+	if !ok || !hmac.Equal([]byte(strings.ToLower(given)), []byte(MockSign(m.secret, payload))) {
+		return nil, domain.ErrInvalidSignature
+	}
+	var body struct {
+		Reference string `json:"reference"`
+		Status    string `json:"status"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil || body.Reference == "" {
+		return nil, domain.ErrInvalidWebhook
+	}
+	switch body.Status {
+	case domain.StatusSucceeded, domain.StatusFailed:
+		return &domain.Outcome{Reference: body.Reference, Status: body.Status, Reason: body.Reason}, nil
+	default:
+		return nil, domain.ErrInvalidWebhook
+	}
+}
+
+// Refund always succeeds.
+func (m *Mock) Refund(context.Context, *domain.Payment, float64) error {
+	return nil
+}
+
+// MockSign returns the hex signature of a mock webhook payload.
+func MockSign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,243 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/payment"
+)
+
+// stripeAPI is Stripe's REST API.
+const stripeAPI = "https://api.stripe.com"
+
+// StripeTolerance is how far a webhook's signed timestamp may be from now,
+// which limits replaying a captured webhook.
+const StripeTolerance = 5 * time.Minute
+
+// zeroDecimal lists the currencies Stripe counts in whole units rather than
+// cents.
+var zeroDecimal = []string{"BIF", "CLP", "DJF", "GNF", "JPY", "KMF", "KRW", "MGA", "PYG", "RWF", "UGX", "VND", "VUV", "XAF", "XOF", "XPF"}
+
+// Stripe takes payments with Stripe Checkout. A payment's reference is its
+// Checkout Session id.
+type Stripe struct {
+	secretKey     string
+	webhookSecret string
+	successURL    string
+	cancelURL     string
+	apiURL        string
+	client        *http.Client
+	nowFunc       func() time.Time
+}
+
+var _ domain.Provider = (*Stripe)(nil)
+
+// NewStripe constructs a provider using the account's secret key, checking
+// webhooks against the endpoint's signing secret. Customers are sent to
+// successURL after paying and to cancelURL, or successURL when it is
+// empty, if they give up.
+func NewStripe(secretKey, webhookSecret, successURL, cancelURL string) *Stripe {
+	if cancelURL == "" {
+		cancelURL = successURL
+	}
+	return &Stripe{
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+		successURL:    successURL,
+		cancelURL:     cancelURL,
+		apiURL:        stripeAPI,
+		client:        &http.Client{Timeout: 15 * time.Second},
+		nowFunc:       time.Now,
+	}
+}
+
+// Name is "stripe".
+func (s *Stripe) Name() string {
+	return "stripe"
+}
+
+// Start creates a Checkout Session for the amount.
+func (s *Stripe) Start(ctx context.Context, checkout domain.Checkout) (*domain.Session, error) {
+	form := url.Values{
+		"mode":                                   {"payment"},
+		"success_url":                            {s.successURL},
+		"cancel_url":                             {s.cancelURL},
+		"client_reference_id":                    {checkout.OrderID},
+		"metadata[payment_id]":                   {checkout.PaymentID},
+		"metadata[order_id]":                     {checkout.OrderID},
+		"line_items[0][quantity]":                {"1"},
+		"line_items[0][price_data][currency]":    {strings.ToLower(checkout.Currency)},
+		"line_items[0][price_data][unit_amount]": {strconv.FormatInt(minorUnits(checkout.Amount, checkout.Currency), 10)},
+		"line_items[0][price_data][product_data][name]": {checkout.Description},
+	}
+	var session struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	// Retrying with the payment id as the key cannot open a second session.
+	if err := s.call(ctx, http.MethodPost, "/v1/checkout/sessions", form, checkout.PaymentID, &session); err != nil {
+		return nil, err
+	}
+	return &domain.Session{Reference: session.ID, CheckoutURL: session.URL}, nil
+}
+
+// Webhook verifies the Stripe-Signature header and reads Checkout Session
+// events: completed and paid, or asynchronously succeeded, settles the
+// payment; asynchronously failed or expired fails it.
+func (s *Stripe) Webhook(payload []byte, header http.Header) (*domain.Outcome, error) {
+	if err := s.verify(payload, header.Get("Stripe-Signature")); err != nil {
+		return nil, err
+	}
+	var e struct {
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				ID            string `json:"id"`
+				PaymentStatus string `json:"payment_status"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, domain.ErrInvalidWebhook
+	}
+	session := e.Data.Object
+	switch e.Type {
+	case "checkout.session.completed":
+		// Delayed methods complete the session unpaid and settle later.
+		if session.PaymentStatus != "paid" {
+			return nil, nil
+		}
+		return &domain.Outcome{Reference: session.ID, Status: domain.StatusSucceeded}, nil
+	case "checkout.session.async_payment_succeeded":
+		return &domain.Outcome{Reference: session.ID, Status: domain.StatusSucceeded}, nil
+	case "checkout.session.async_payment_failed":
+		return &domain.Outcome{Reference: session.ID, Status: domain.StatusFailed, Reason: "payment failed"}, nil
+	case "checkout.session.expired":
+		return &domain.Outcome{Reference: session.ID, Status: domain.StatusFailed, Reason: "checkout expired"}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// verify checks a "t=<unix>,v1=<hex>" signature over "<t>.<payload>". The
+// header may carry several v1 signatures while the secret is rolled.
+func (s *Stripe) verify(payload []byte, header string) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return domain.ErrInvalidSignature
+	}
+	if age := s.nowFunc().Sub(time.Unix(seconds, 0)); age > StripeTolerance || age < -StripeTolerance {
+		return domain.ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(s.webhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !slices.ContainsFunc(signatures, func(sig string) bool { return hmac.Equal([]byte(sig), []byte(expected)) }) {
+		return domain.ErrInvalidSignature
+	}
+	return nil
+}
+
+// Refund refunds amount of the payment intent behind the payment's
+// Checkout Session.
+func (s *Stripe) Refund(ctx context.Context, p *domain.Payment, amount float64) error {
+	var session struct {
+		PaymentIntent string `json:"payment_intent"`
+	}
+	if err := s.call(ctx, http.MethodGet, "/v1/checkout/sessions/"+url.PathEscape(p.Reference), nil, "", &session); err != nil {
+		return err
+	}
+	if session.PaymentIntent == "" {
+		return errors.New("stripe: checkout session has no payment intent")
+	}
+	form := url.Values{
+		"payment_intent":       {session.PaymentIntent},
+		"amount":               {strconv.FormatInt(minorUnits(amount, p.Currency), 10)},
+		"metadata[payment_id]": {p.ID},
+	}
+	// The key covers what was refunded before, so retrying this refund
+	// cannot repeat it but a later one is still made.
+	key := fmt.Sprintf("%s-refund-%d", p.ID, minorUnits(p.Refunded, p.Currency))
+	return s.call(ctx, http.MethodPost, "/v1/refunds", form, key, nil)
+}
+
+// call sends a form-encoded request to the API and decodes the JSON
+// response into out. Errors carry Stripe's message, never the secret key.
+func (s *Stripe) call(ctx context.Context, method, path string, form url.Values, idempotencyKey string, out any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.secretKey)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("stripe: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &failure) == nil && failure.Error.Message != "" {
+			return fmt.Errorf("stripe: %s", failure.Error.Message)
+		}
+		return fmt.Errorf("stripe: unexpected status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// minorUnits converts amount to the currency's smallest unit.
+func minorUnits(amount float64, currency string) int64 {
+	if slices.Contains(zeroDecimal, strings.ToUpper(currency)) {
+		return int64(math.Round(amount))
+	}
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,173 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/payment"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// newTestStripe returns a provider whose clock stands at now.
+func newTestStripe(now time.Time) *Stripe {
+	s := NewStripe("sk_test", testWebhookSecret, "https://shop.example/paid", "")
+	s.nowFunc = func() time.Time { return now }
+	return s
+}
+
+// stripeSignature signs payload at t the way Stripe does.
+func stripeSignature(secret string, t time.Time, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t.Unix(), 10) + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestStripeVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	const payload = `{"type":"ping"}`
+	// HMAC-SHA256("whsec_test", "1700000000.{\"type\":\"ping\"}") computed
+	// independently.
+	const pinned = "bc08c591847b765241711bcbe7067e3869a219e424d3fdd9d00b3b6f915baf97"
+	valid := stripeSignature(testWebhookSecret, now, payload)
+	if valid != pinned {
+		t.Fatalf("test signer = %s, want %s", valid, pinned)
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	at := func(d time.Duration) string {
+		signed := now.Add(d)
+		return "t=" + strconv.FormatInt(signed.Unix(), 10) + ",v1=" + stripeSignature(testWebhookSecret, signed, payload)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		body   string
+		ok     bool
+	}{
+		{name: "valid", header: "t=" + ts + ",v1=" + valid, ok: true},
+		{name: "spaces and unknown schemes", header: " t=" + ts + " , v0=deadbeef , v1=" + valid, ok: true},
+		{name: "rolled secret: one of two v1 matches", header: "t=" + ts + ",v1=" + stripeSignature("whsec_old", now, payload) + ",v1=" + valid, ok: true},
+		{name: "duplicate v1, none matching", header: "t=" + ts + ",v1=" + stripeSignature("whsec_old", now, payload) + ",v1=" + stripeSignature("whsec_older", now, payload)},
+		{name: "wrong secret", header: "t=" + ts + ",v1=" + stripeSignature("whsec_other", now, payload)},
+		{name: "tampered body", header: "t=" + ts + ",v1=" + valid, body: `{"type":"pong"}`},
+		{name: "timestamp changed after signing", header: "t=" + strconv.FormatInt(now.Unix()+1, 10) + ",v1=" + valid},
+		{name: "within tolerance, old", header: at(-StripeTolerance), ok: true},
+		{name: "within tolerance, ahead", header: at(StripeTolerance), ok: true},
+		{name: "stale", header: at(-StripeTolerance - time.Second)},
+		{name: "too far ahead", header: at(StripeTolerance + time.Second)},
+		{name: "missing header"},
+		{name: "missing v1", header: "t=" + ts},
+		{name: "only a v0", header: "t=" + ts + ",v0=" + valid},
+		{name: "missing timestamp", header: "v1=" + valid},
+		{name: "empty fields", header: "t=,v1="},
+		{name: "empty signature", header: "t=" + ts + ",v1="},
+		{name: "timestamp not a number", header: "t=yesterday,v1=" + valid},
+		{name: "no separators", header: "t" + ts + "v1" + valid},
+		{name: "semicolons", header: "t=" + ts + ";v1=" + valid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			body := payload
+			if tc.body != "" {
+				body = tc.body
+			}
+			err := newTestStripe(now).verify([]byte(body), tc.header)
+			switch {
+			case tc.ok && err != nil:
+				t.Fatalf("verify: %v", err)
+			case !tc.ok && !errors.Is(err, domain.ErrInvalidSignature):
+				t.Fatalf("verify: err = %v, want %v", err, domain.ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestStripeWebhook(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := newTestStripe(now)
+	for _, tc := range []struct {
+		payload string
+		want    *domain.Outcome
+	}{
+		{`{"type":"checkout.session.completed","data":{"object":{"id":"cs_1","payment_status":"paid"}}}`, &domain.Outcome{Reference: "cs_1", Status: domain.StatusSucceeded}},
+		// Delayed methods complete unpaid and settle with a later event.
+		{`{"type":"checkout.session.completed","data":{"object":{"id":"cs_1","payment_status":"unpaid"}}}`, nil},
+		{`{"type":"checkout.session.async_payment_succeeded","data":{"object":{"id":"cs_1"}}}`, &domain.Outcome{Reference: "cs_1", Status: domain.StatusSucceeded}},
+		{`{"type":"checkout.session.async_payment_failed","data":{"object":{"id":"cs_1"}}}`, &domain.Outcome{Reference: "cs_1", Status: domain.StatusFailed, Reason: "payment failed"}},
+		{`{"type":"checkout.session.expired","data":{"object":{"id":"cs_1"}}}`, &domain.Outcome{Reference: "cs_1", Status: domain.StatusFailed, Reason: "checkout expired"}},
+		{`{"type":"customer.created","data":{"object":{"id":"cus_1"}}}`, nil},
+	} {
+		header := http.Header{"Stripe-Signature": {"t=1700000000,v1=" + stripeSignature(testWebhookSecret, now, tc.payload)}}
+		got, err := s.Webhook([]byte(tc.payload), header)
+		if err != nil {
+			t.Errorf("%s: %v", tc.payload, err)
+			continue
+		}
+		if (got == nil) != (tc.want == nil) || got != nil && *got != *tc.want {
+			t.Errorf("%s: outcome = %+v, want %+v", tc.payload, got, tc.want)
+		}
+	}
+
+	const garbled = `{"type":`
+	header := http.Header{"Stripe-Signature": {"t=1700000000,v1=" + stripeSignature(testWebhookSecret, now, garbled)}}
+	if _, err := s.Webhook([]byte(garbled), header); !errors.Is(err, domain.ErrInvalidWebhook) {
+		t.Fatalf("signed but malformed: err = %v, want %v", err, domain.ErrInvalidWebhook)
+	}
+	if _, err := s.Webhook([]byte(`{}`), http.Header{}); !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Fatalf("unsigned: err = %v, want %v", err, domain.ErrInvalidSignature)
+	}
+}
+
+func TestStripeStart(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		if r.URL.Path != "/v1/checkout/sessions" || r.Header.Get("Authorization") != "Bearer sk_test" || r.Header.Get("Idempotency-Key") != "payment-1" {
+			t.Errorf("%s %s with %v", r.Method, r.URL.Path, r.Header)
+		}
+		w.Write([]byte(`{"id":"cs_1","url":"https://checkout.stripe.com/c/cs_1"}`))
+	}))
+	defer server.Close()
+	s := newTestStripe(time.Now())
+	s.apiURL = server.URL
+
+	session, err := s.Start(context.Background(), domain.Checkout{PaymentID: "payment-1", OrderID: "order-1", Amount: 12.35, Currency: "USD", Description: "Order SHOP-1"})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if session.Reference != "cs_1" || session.CheckoutURL != "https://checkout.stripe.com/c/cs_1" {
+		t.Fatalf("session = %+v", session)
+	}
+	if form.Get("line_items[0][price_data][unit_amount]") != "1235" || form.Get("line_items[0][price_data][currency]") != "usd" ||
+		form.Get("cancel_url") != "https://shop.example/paid" || form.Get("metadata[payment_id]") != "payment-1" {
+		t.Fatalf("form = %v", form)
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	for _, tc := range []struct {
+		amount   float64
+		currency string
+		want     int64
+	}{
+		{12.34, "USD", 1234},
+		{0.1 + 0.2, "eur", 30},
+		{1500, "JPY", 1500},
+		{1500.6, "krw", 1501},
+	} {
+		if got := minorUnits(tc.amount, tc.currency); got != tc.want {
+			t.Errorf("minorUnits(%v, %s) = %d, want %d", tc.amount, tc.currency, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package pdf writes plain-text documents as PDF files. Text is set in the
+// standard Courier font, which every viewer has, so the files need no
+// embedded fonts; characters outside Windows-1252 print as "?".
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// A4 portrait in points, with the text area inset by margin on every side.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+	fontSize   = 10
+	leading    = 12
+	// Courier glyphs are 0.6 em wide.
+	lineWidth    = (pageWidth - 2*margin) * 10 / (6 * fontSize)
+	linesPerPage = (pageHeight - 2*margin) / leading
+)
+
+// Text lays out lines top to bottom over as many pages as they need. Lines
+// longer than the page is wide are wrapped, and a line holding only a form
+// feed ("\f") starts a new page.
+func Text(title string, lines []string) []byte {
+	var pages [][]string
+	var page []string
+	for _, line := range lines {
+		if line == "\f" {
+			pages = append(pages, page)
+			page = nil
+			continue
+		}
+		for _, part := range wrap(line) {
+			if len(page) == linesPerPage {
+				pages = append(pages, page)
+				page = nil
+			}
+			page = append(page, part)
+		}
+	}
+	pages = append(pages, page)
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objects 1-4 are fixed; each page then takes two: the page and its
+	// content stream.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	w.object("<< /Type /Catalog /Pages 2 0 R >>")
+	w.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	w.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	w.object(fmt.Sprintf("<< /Title %s /Producer (backoffice) >>", literal(title)))
+	for i, page := range pages {
+		w.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, leading, margin, pageHeight-margin-fontSize)
+		for _, line := range page {
+			fmt.Fprintf(&content, "%s '\n", literal(line))
+		}
+		content.WriteString("ET\n")
+		w.object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, xref)
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// object appends the next numbered object and records where it starts for
+// the cross-reference table.
+func (w *writer) object(body string) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", len(w.offsets), body)
+}
+
+// wrap splits line into pieces that fit the page, breaking at the last
+// space that keeps a piece within the width where there is one.
+func wrap(line string) []string {
+	line = strings.TrimRight(strings.ReplaceAll(line, "\t", "    "), " \r")
+	var parts []string
+	for utf8.RuneCountInString(line) > lineWidth {
+		runes := []rune(line)
+		cut := lineWidth
+		if i := strings.LastIndex(string(runes[:lineWidth]), " "); i > 0 {
+			cut = utf8.RuneCountInString(string(runes[:lineWidth])[:i])
+		}
+		parts = append(parts, strings.TrimRight(string(runes[:cut]), " "))
+		line = strings.TrimLeft(string(runes[cut:]), " ")
+	}
+	return append(parts, line)
+}
+
+// winAnsi maps the characters Windows-1252 places in 0x80-0x9F.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// literal encodes s as a PDF string in Windows-1252.
+func literal(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7F:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
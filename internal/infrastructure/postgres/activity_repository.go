@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "backoffice/backend/internal/domain/activity"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// activityChainLockID is the transaction-scoped advisory lock key held while
+// an entry is chained, so concurrent writers link to each other in turn
+// rather than to the same predecessor.
+const activityChainLockID = 7_246_913_002
+
+const activityColumns = `id, occurred_at, actor_id, actor_ip, entity_type, action, entity_id, entity_name, seq, prev_hash, hash`
+
+// ActivityRepository persists the audit trail in PostgreSQL.
+type ActivityRepository struct {
+	pool Querier
+}
+
+// NewActivityRepository constructs a repository.
+func NewActivityRepository(pool Querier) *ActivityRepository {
+	return &ActivityRepository{pool: pool}
+}
+
+var _ domain.Repository = (*ActivityRepository)(nil)
+
+// Record appends an entry to the hash chain.
+func (r *ActivityRepository) Record(ctx context.Context, entry *domain.Entry) error {
+	const query = `
+INSERT INTO activity_log (id, occurred_at, actor_id, actor_ip, entity_type, action, entity_id, entity_name, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING seq
+`
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, activityChainLockID); err != nil {
+			return err
+		}
+		var prev string
+		err := tx.QueryRow(ctx, `SELECT hash FROM activity_log ORDER BY seq DESC LIMIT 1`).Scan(&prev)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		hash := entry.ChainHash(prev)
+		var seq int64
+		err = tx.QueryRow(ctx, query,
+			entry.ID,
+			entry.OccurredAt,
+			entry.ActorID,
+			entry.ActorIP,
+			entry.EntityType,
+			entry.Action,
+			entry.EntityID,
+			entry.EntityName,
+			prev,
+			hash,
+		).Scan(&seq)
+		if err != nil {
+			return err
+		}
+		entry.Seq, entry.PrevHash, entry.Hash = seq, prev, hash
+		return nil
+	})
+}
+
+// List returns matching entries, newest first.
+func (r *ActivityRepository) List(ctx context.Context, filter domain.Filter) ([]*domain.Entry, error) {
+	q := newSelect(`SELECT ` + activityColumns + ` FROM activity_log`)
+	if filter.ActorID != "" {
+		q.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.EntityType != "" {
+		q.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		q.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		q.Where("action = ?", filter.Action)
+	}
+	if filter.After != nil {
+		q.Where("(occurred_at, id) < (?, ?)", filter.After.OccurredAt, filter.After.ID)
+	}
+	query, args := q.OrderBy("occurred_at DESC, id DESC").Limit(filter.Limit).Build()
+
+	return r.query(ctx, query, args...)
+}
+
+// ChainRange returns the Seq of the first and last entries that occurred in
+// [from, to).
+func (r *ActivityRepository) ChainRange(ctx context.Context, from, to time.Time) (int64, int64, error) {
+	q := newSelect(`SELECT COALESCE(min(seq), 0), COALESCE(max(seq), 0) FROM activity_log`)
+	if !from.IsZero() {
+		q.Where("occurred_at >= ?", from)
+	}
+	if !to.IsZero() {
+		q.Where("occurred_at < ?", to)
+	}
+	query, args := q.Build()
+	var first, last int64
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&first, &last); err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
+
+// Chain returns up to limit entries with first <= seq <= last, in chain
+// order.
+func (r *ActivityRepository) Chain(ctx context.Context, first, last int64, limit int) ([]*domain.Entry, error) {
+	const query = `
+SELECT ` + activityColumns + `
+FROM activity_log
+WHERE seq BETWEEN $1 AND $2
+ORDER BY seq
+LIMIT $3
+`
+	return r.query(ctx, query, first, last, limit)
+}
+
+func (r *ActivityRepository) query(ctx context.Context, query string, args ...any) ([]*domain.Entry, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.Entry
+	for rows.Next() {
+		var e domain.Entry
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.ActorID, &e.ActorIP, &e.EntityType, &e.Action, &e.EntityID, &e.EntityName, &e.Seq, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// chainPrefixBefore matches the entries purged for cutoff $1: every entry
+// recorded before the first one that occurred at or after it. Purging only a
+// prefix of the chain keeps the rest verifiable even when entries were
+// recorded out of occurred_at order.
+const chainPrefixBefore = `seq < COALESCE(
+	(SELECT min(seq) FROM activity_log WHERE occurred_at >= $1),
+	(SELECT max(seq) + 1 FROM activity_log)
+)`
+
+// CountBefore counts entries recorded before cutoff.
+func (r *ActivityRepository) CountBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `SELECT COUNT(*) FROM activity_log WHERE ` + chainPrefixBefore
+	var count int
+	if err := r.pool.QueryRow(ctx, query, cutoff).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// PurgeBefore deletes entries recorded before cutoff.
+func (r *ActivityRepository) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `DELETE FROM activity_log WHERE ` + chainPrefixBefore
+	tag, err := r.pool.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/activity"
+
+	"github.com/google/uuid"
+)
+
+// TestActivityChainHashSQL checks that the activity_chain_hash function of
+// migration 0037 agrees with activity.Entry.ChainHash on the fixtures the
+// domain test pins.
+func TestActivityChainHashSQL(t *testing.T) {
+	raw, err := os.ReadFile("../../domain/activity/testdata/chain_hash.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []struct {
+		Name  string       `json:"name"`
+		Prev  string       `json:"prev"`
+		Entry domain.Entry `json:"entry"`
+		Hash  string       `json:"hash"`
+	}
+	if err := json.Unmarshal(raw, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	db := testDB(t)
+	ctx := context.Background()
+
+	const query = `SELECT activity_chain_hash($1, $2, $3, $4, $5, $6, $7)`
+	for _, f := range fixtures {
+		var got string
+		err := db.Pool.QueryRow(ctx, query,
+			f.Prev, f.Entry.ID, f.Entry.OccurredAt, f.Entry.ActorID, f.Entry.EntityType, f.Entry.Action, f.Entry.EntityID,
+		).Scan(&got)
+		if err != nil {
+			t.Fatalf("%s: %v", f.Name, err)
+		}
+		if got != f.Hash {
+			t.Errorf("%s: activity_chain_hash = %s, want %s", f.Name, got, f.Hash)
+		}
+	}
+}
+
+// TestActivityChainHashSQLNulls checks that NULL inputs hash like Go's zero
+// values instead of turning the whole hash NULL, so a row with a NULL field
+// still verifies.
+func TestActivityChainHashSQLNulls(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	var got *string
+	err := db.Pool.QueryRow(ctx, `SELECT activity_chain_hash(NULL, NULL, NULL, NULL, NULL, NULL, NULL)`).Scan(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&domain.Entry{}).ChainHash(""); got == nil || *got != want {
+		t.Fatalf("activity_chain_hash of NULLs = %v, want %s", got, want)
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	// A background job's entry has no actor, and a deletion no entity id.
+	entry := &domain.Entry{
+		ID:         uuid.NewString(),
+		OccurredAt: time.Now().UTC().Truncate(time.Microsecond),
+		EntityType: "category",
+		Action:     "deleted",
+	}
+	if err := NewActivityRepository(tx).Record(ctx, entry); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	const verifyQuery = `
+SELECT hash = activity_chain_hash(prev_hash, id, occurred_at, NULLIF(actor_id, ''), entity_type, action, NULLIF(entity_id, ''))
+FROM activity_log
+WHERE id = $1
+`
+	var verified *bool
+	if err := tx.QueryRow(ctx, verifyQuery, entry.ID).Scan(&verified); err != nil {
+		t.Fatal(err)
+	}
+	if verified == nil || !*verified {
+		t.Fatalf("row with NULL actor and entity ids verified = %v, want true", verified)
+	}
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "backoffice/backend/internal/domain/approval"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ApprovalRepository persists approvals in PostgreSQL.
+type ApprovalRepository struct {
+	pool Querier
+}
+
+// NewApprovalRepository constructs a repository.
+func NewApprovalRepository(pool Querier) *ApprovalRepository {
+	return &ApprovalRepository{pool: pool}
+}
+
+var _ domain.Repository = (*ApprovalRepository)(nil)
+
+const approvalColumns = `id, action, status, subject, summary, payload, error, reason, requested_by, decided_by, created_at, expires_at, decided_at`
+
+// Create inserts a new approval.
+func (r *ApprovalRepository) Create(ctx context.Context, a *domain.Approval) error {
+	const query = `
+INSERT INTO approvals (` + approvalColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+`
+	_, err := r.pool.Exec(ctx, query,
+		a.ID,
+		a.Action,
+		a.Status,
+		a.Subject,
+		a.Summary,
+		a.Payload,
+		a.Error,
+		a.Reason,
+		a.RequestedBy,
+		a.DecidedBy,
+		a.CreatedAt,
+		a.ExpiresAt,
+		a.DecidedAt,
+	)
+	return err
+}
+
+// GetByID fetches an approval by id.
+func (r *ApprovalRepository) GetByID(ctx context.Context, id string) (*domain.Approval, error) {
+	const query = `SELECT ` + approvalColumns + ` FROM approvals WHERE id = $1`
+	a, err := scanApproval(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// List returns approvals with the status, or all when it is empty, newest
+// first.
+func (r *ApprovalRepository) List(ctx context.Context, status string) ([]*domain.Approval, error) {
+	const query = `
+SELECT ` + approvalColumns + `
+FROM approvals
+WHERE $1 = '' OR status = $1
+ORDER BY created_at DESC, id DESC
+`
+	rows, err := r.pool.Query(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []*domain.Approval
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, a)
+	}
+	return approvals, rows.Err()
+}
+
+// Decide records the outcome of a pending approval.
+func (r *ApprovalRepository) Decide(ctx context.Context, a *domain.Approval) error {
+	const query = `
+UPDATE approvals
+SET status = $2, reason = $3, decided_by = $4, decided_at = $5
+WHERE id = $1 AND status = 'pending'
+`
+	tag, err := r.pool.Exec(ctx, query, a.ID, a.Status, a.Reason, a.DecidedBy, a.DecidedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotPending
+	}
+	return nil
+}
+
+// Finish records the result of executing an approved action.
+func (r *ApprovalRepository) Finish(ctx context.Context, a *domain.Approval) error {
+	const query = `UPDATE approvals SET status = $2, error = $3 WHERE id = $1`
+	tag, err := r.pool.Exec(ctx, query, a.ID, a.Status, a.Error)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Expire marks pending approvals whose expiry has passed by now as expired.
+func (r *ApprovalRepository) Expire(ctx context.Context, now time.Time) (int, error) {
+	const query = `UPDATE approvals SET status = 'expired' WHERE status = 'pending' AND expires_at <= $1`
+	tag, err := r.pool.Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func scanApproval(row pgx.Row) (*domain.Approval, error) {
+	var a domain.Approval
+	err := row.Scan(
+		&a.ID,
+		&a.Action,
+		&a.Status,
+		&a.Subject,
+		&a.Summary,
+		&a.Payload,
+		&a.Error,
+		&a.Reason,
+		&a.RequestedBy,
+		&a.DecidedBy,
+		&a.CreatedAt,
+		&a.ExpiresAt,
+		&a.DecidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/attachment"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AttachmentStore keeps attachments in PostgreSQL.
+type AttachmentStore struct {
+	pool Querier
+}
+
+// NewAttachmentStore constructs a store.
+func NewAttachmentStore(pool Querier) *AttachmentStore {
+	return &AttachmentStore{pool: pool}
+}
+
+var _ domain.Store = (*AttachmentStore)(nil)
+
+// Get fetches the attachment stored under key.
+func (s *AttachmentStore) Get(ctx context.Context, key string) (*domain.Attachment, error) {
+	const query = `SELECT key, name, content_type, data, created_at FROM attachments WHERE key = $1`
+	var a domain.Attachment
+	err := s.pool.QueryRow(ctx, query, key).Scan(&a.Key, &a.Name, &a.ContentType, &a.Data, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Put stores an attachment, replacing any under the same key.
+func (s *AttachmentStore) Put(ctx context.Context, a *domain.Attachment) error {
+	const query = `
+INSERT INTO attachments (key, name, content_type, data, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (key) DO UPDATE SET
+    name = EXCLUDED.name,
+    content_type = EXCLUDED.content_type,
+    data = EXCLUDED.data,
+    created_at = EXCLUDED.created_at
+`
+	_, err := s.pool.Exec(ctx, query, a.Key, a.Name, a.ContentType, a.Data, a.CreatedAt)
+	return err
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/category"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CategoryRepository persists categories in PostgreSQL.
+type CategoryRepository struct {
+	pool Querier
+}
+
+// NewCategoryRepository constructs a repository on top of a pool, transaction or
+// Database.Retrying.
+func NewCategoryRepository(pool Querier) *CategoryRepository {
+	return &CategoryRepository{pool: pool}
+}
+
+// Create inserts a new category.
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	const query = `
+INSERT INTO categories (id, name, slug, description, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+	_, err := r.pool.Exec(ctx, query,
+		category.ID,
+		category.Name,
+		category.Slug,
+		category.Description,
+		category.CreatedAt,
+		category.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateSlug
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByID fetches a category by id.
+func (r *CategoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	const query = `
+SELECT id, name, slug, description, created_at, updated_at
+FROM categories WHERE id = $1
+`
+	category, err := scanCategory(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// GetByIDs fetches the categories among ids.
+func (r *CategoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Category, error) {
+	const query = `
+SELECT id, name, slug, description, created_at, updated_at
+FROM categories WHERE id = ANY($1)
+`
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*domain.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// GetBySlug fetches a category by slug.
+func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	const query = `
+SELECT id, name, slug, description, created_at, updated_at
+FROM categories WHERE slug = $1
+`
+	category, err := scanCategory(r.pool.QueryRow(ctx, query, slug))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// List returns all categories sorted by name.
+func (r *CategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	const query = `
+SELECT id, name, slug, description, created_at, updated_at
+FROM categories
+ORDER BY name ASC
+`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*domain.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// Update writes category updates to the database.
+func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	const query = `
+UPDATE categories
+SET name = $2,
+    slug = $3,
+    description = $4,
+    updated_at = $5
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query,
+		category.ID,
+		category.Name,
+		category.Slug,
+		category.Description,
+		category.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateSlug
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a category by id.
+func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
+	const query = `DELETE FROM categories WHERE id = $1`
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrInUse
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanCategory(row pgx.Row) (*domain.Category, error) {
+	var c domain.Category
+	err := row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Slug,
+		&c.Description,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
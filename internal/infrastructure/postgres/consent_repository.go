@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+
+	domain "backoffice/backend/internal/domain/consent"
+)
+
+// ConsentRepository persists policy versions and users' consents in
+// PostgreSQL.
+type ConsentRepository struct {
+	pool Querier
+}
+
+// NewConsentRepository constructs a repository.
+func NewConsentRepository(pool Querier) *ConsentRepository {
+	return &ConsentRepository{pool: pool}
+}
+
+var _ domain.Repository = (*ConsentRepository)(nil)
+
+// Publish stores a new version.
+func (r *ConsentRepository) Publish(ctx context.Context, v *domain.Version) error {
+	const query = `
+INSERT INTO policy_versions (document, version, mandatory, url, published_by, published_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+	_, err := r.pool.Exec(ctx, query, v.Document, v.Version, v.Mandatory, v.URL, v.PublishedBy, v.PublishedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrVersionExists
+	}
+	return err
+}
+
+// Versions returns every published version, newest first.
+func (r *ConsentRepository) Versions(ctx context.Context) ([]*domain.Version, error) {
+	const query = `
+SELECT document, version, mandatory, url, published_by, published_at
+FROM policy_versions
+ORDER BY published_at DESC, document, version DESC
+`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*domain.Version
+	for rows.Next() {
+		var v domain.Version
+		if err := rows.Scan(&v.Document, &v.Version, &v.Mandatory, &v.URL, &v.PublishedBy, &v.PublishedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+// Accept records a consent unless the user already accepted the version.
+func (r *ConsentRepository) Accept(ctx context.Context, c *domain.Consent) error {
+	const query = `
+INSERT INTO user_consents (user_id, document, version, client_ip, accepted_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, document, version) DO NOTHING
+`
+	_, err := r.pool.Exec(ctx, query, c.UserID, c.Document, c.Version, c.ClientIP, c.AcceptedAt)
+	if isForeignKeyViolation(err) {
+		return domain.ErrVersionNotFound
+	}
+	return err
+}
+
+// Consents returns the user's consents, newest first.
+func (r *ConsentRepository) Consents(ctx context.Context, userID string) ([]*domain.Consent, error) {
+	const query = `
+SELECT user_id, document, version, client_ip, accepted_at
+FROM user_consents
+WHERE user_id = $1
+ORDER BY accepted_at DESC, document
+`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var consents []*domain.Consent
+	for rows.Next() {
+		var c domain.Consent
+		if err := rows.Scan(&c.UserID, &c.Document, &c.Version, &c.ClientIP, &c.AcceptedAt); err != nil {
+			return nil, err
+		}
+		consents = append(consents, &c)
+	}
+	return consents, rows.Err()
+}
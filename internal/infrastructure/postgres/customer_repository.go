@@ -0,0 +1,352 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	domain "backoffice/backend/internal/domain/customer"
+	"backoffice/backend/internal/infrastructure/fieldcrypt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CustomerRepository persists customers and their addresses in PostgreSQL.
+// Phone numbers and address lines are encrypted with its keyring.
+type CustomerRepository struct {
+	pool Querier
+	keys *fieldcrypt.Keyring
+}
+
+// NewCustomerRepository constructs a repository. With a nil keyring new
+// values are stored in plain text, though encrypted ones still need their
+// key to be read.
+func NewCustomerRepository(pool Querier, keys *fieldcrypt.Keyring) *CustomerRepository {
+	return &CustomerRepository{pool: pool, keys: keys}
+}
+
+var _ domain.Repository = (*CustomerRepository)(nil)
+
+const (
+	customerColumns = `id, name, email, phone, created_at, updated_at`
+	addressColumns  = `id, customer_id, label, recipient, line1, line2, city, region, postal_code, country, default_billing, default_shipping, created_at, updated_at`
+)
+
+// piiAddressColumns are the address columns stored encrypted, in the order
+// addressPII returns them. The country stays readable: it identifies no one
+// and its CHAR(2) column cannot hold a ciphertext.
+var piiAddressColumns = []string{"recipient", "line1", "line2", "city", "region", "postal_code"}
+
+func addressPII(a *domain.Address) []*string {
+	return []*string{&a.Recipient, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode}
+}
+
+// piiField names where an encrypted value is stored, binding it to its row
+// and column.
+func piiField(table, column, id string) string {
+	return table + "." + column + ":" + id
+}
+
+// Create inserts a new customer.
+func (r *CustomerRepository) Create(ctx context.Context, c *domain.Customer) error {
+	const query = `
+INSERT INTO customers (` + customerColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+	phone, err := r.keys.Encrypt(c.Phone, piiField("customers", "phone", c.ID))
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, query, c.ID, c.Name, c.Email, phone, c.CreatedAt, c.UpdatedAt)
+	return err
+}
+
+// GetByID fetches a customer by id.
+func (r *CustomerRepository) GetByID(ctx context.Context, id string) (*domain.Customer, error) {
+	const query = `SELECT ` + customerColumns + ` FROM customers WHERE id = $1`
+	var c domain.Customer
+	err := r.pool.QueryRow(ctx, query, id).Scan(&c.ID, &c.Name, &c.Email, &c.Phone, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.openCustomer(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// List returns customers ordered by name.
+func (r *CustomerRepository) List(ctx context.Context) ([]*domain.Customer, error) {
+	const query = `SELECT ` + customerColumns + ` FROM customers ORDER BY name, id`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customers []*domain.Customer
+	for rows.Next() {
+		var c domain.Customer
+		if err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Phone, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := r.openCustomer(&c); err != nil {
+			return nil, err
+		}
+		customers = append(customers, &c)
+	}
+	return customers, rows.Err()
+}
+
+// Addresses returns a customer's addresses, oldest first.
+func (r *CustomerRepository) Addresses(ctx context.Context, customerID string) ([]*domain.Address, error) {
+	const query = `
+SELECT ` + addressColumns + `
+FROM customer_addresses
+WHERE customer_id = $1
+ORDER BY created_at, id
+`
+	rows, err := r.pool.Query(ctx, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addresses []*domain.Address
+	for rows.Next() {
+		a, err := r.scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, a)
+	}
+	return addresses, rows.Err()
+}
+
+// GetAddress fetches one of a customer's addresses.
+func (r *CustomerRepository) GetAddress(ctx context.Context, customerID, id string) (*domain.Address, error) {
+	const query = `SELECT ` + addressColumns + ` FROM customer_addresses WHERE customer_id = $1 AND id = $2`
+	a, err := r.scanAddress(r.pool.QueryRow(ctx, query, customerID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// SaveAddress inserts or replaces an address. Defaults are cleared from the
+// customer's other addresses first, so the partial unique indexes never see
+// two defaults of a kind.
+func (r *CustomerRepository) SaveAddress(ctx context.Context, a *domain.Address) error {
+	const clearQuery = `
+UPDATE customer_addresses
+SET default_billing = default_billing AND NOT $3,
+    default_shipping = default_shipping AND NOT $4
+WHERE customer_id = $1 AND id <> $2 AND ((default_billing AND $3) OR (default_shipping AND $4))
+`
+	const upsertQuery = `
+INSERT INTO customer_addresses (` + addressColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (id) DO UPDATE SET
+    label = EXCLUDED.label,
+    recipient = EXCLUDED.recipient,
+    line1 = EXCLUDED.line1,
+    line2 = EXCLUDED.line2,
+    city = EXCLUDED.city,
+    region = EXCLUDED.region,
+    postal_code = EXCLUDED.postal_code,
+    country = EXCLUDED.country,
+    default_billing = EXCLUDED.default_billing,
+    default_shipping = EXCLUDED.default_shipping,
+    updated_at = EXCLUDED.updated_at
+`
+	stored := *a
+	for i, value := range addressPII(&stored) {
+		sealed, err := r.keys.Encrypt(*value, piiField("customer_addresses", piiAddressColumns[i], a.ID))
+		if err != nil {
+			return err
+		}
+		*value = sealed
+	}
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		if a.DefaultBilling || a.DefaultShipping {
+			if _, err := tx.Exec(ctx, clearQuery, a.CustomerID, a.ID, a.DefaultBilling, a.DefaultShipping); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, upsertQuery,
+			a.ID,
+			a.CustomerID,
+			a.Label,
+			stored.Recipient,
+			stored.Line1,
+			stored.Line2,
+			stored.City,
+			stored.Region,
+			stored.PostalCode,
+			a.Country,
+			a.DefaultBilling,
+			a.DefaultShipping,
+			a.CreatedAt,
+			a.UpdatedAt,
+		)
+		return err
+	})
+}
+
+// DeleteAddress removes one of a customer's addresses.
+func (r *CustomerRepository) DeleteAddress(ctx context.Context, customerID, id string) error {
+	const query = `DELETE FROM customer_addresses WHERE customer_id = $1 AND id = $2`
+	tag, err := r.pool.Exec(ctx, query, customerID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAddressNotFound
+	}
+	return nil
+}
+
+// openCustomer decrypts a scanned customer's phone number.
+func (r *CustomerRepository) openCustomer(c *domain.Customer) error {
+	phone, err := r.keys.Decrypt(c.Phone, piiField("customers", "phone", c.ID))
+	if err != nil {
+		return fmt.Errorf("customer %s phone: %w", c.ID, err)
+	}
+	c.Phone = phone
+	return nil
+}
+
+func (r *CustomerRepository) scanAddress(row pgx.Row) (*domain.Address, error) {
+	var a domain.Address
+	err := row.Scan(
+		&a.ID,
+		&a.CustomerID,
+		&a.Label,
+		&a.Recipient,
+		&a.Line1,
+		&a.Line2,
+		&a.City,
+		&a.Region,
+		&a.PostalCode,
+		&a.Country,
+		&a.DefaultBilling,
+		&a.DefaultShipping,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range addressPII(&a) {
+		plain, err := r.keys.Decrypt(*value, piiField("customer_addresses", piiAddressColumns[i], a.ID))
+		if err != nil {
+			return nil, fmt.Errorf("address %s %s: %w", a.ID, piiAddressColumns[i], err)
+		}
+		*value = plain
+	}
+	return &a, nil
+}
+
+// ReencryptResult counts the rows Reencrypt rewrote.
+type ReencryptResult struct {
+	Customers int
+	Addresses int
+}
+
+// Reencrypt rewrites, batchSize rows at a time, every phone number and
+// address stored in plain text or under a key other than the primary, so
+// plain values left from before encryption are sealed and retired keys can
+// be removed from the keyring. A row changed while it is being rewritten
+// keeps its new value, which is already under the primary key.
+func (r *CustomerRepository) Reencrypt(ctx context.Context, batchSize int) (ReencryptResult, error) {
+	var result ReencryptResult
+	if r.keys == nil {
+		return result, errors.New("no PII encryption keys are configured")
+	}
+	var err error
+	if result.Customers, err = r.reencryptTable(ctx, "customers", []string{"phone"}, batchSize); err != nil {
+		return result, err
+	}
+	result.Addresses, err = r.reencryptTable(ctx, "customer_addresses", piiAddressColumns, batchSize)
+	return result, err
+}
+
+// reencryptTable rewrites the rows of table with a column needing rotation,
+// walking them in id order.
+func (r *CustomerRepository) reencryptTable(ctx context.Context, table string, columns []string, batchSize int) (int, error) {
+	var pending, set, unchanged []string
+	for i, column := range columns {
+		pending = append(pending, fmt.Sprintf("(%s <> '' AND %s NOT LIKE $1)", column, column))
+		set = append(set, fmt.Sprintf("%s = $%d", column, i+2))
+		unchanged = append(unchanged, fmt.Sprintf("%s = $%d", column, len(columns)+i+2))
+	}
+	selectQuery := `SELECT id, ` + strings.Join(columns, ", ") + ` FROM ` + table + `
+WHERE id > $2 AND (` + strings.Join(pending, " OR ") + `)
+ORDER BY id
+LIMIT $3`
+	updateQuery := `UPDATE ` + table + ` SET ` + strings.Join(set, ", ") + `
+WHERE id = $1 AND ` + strings.Join(unchanged, " AND ")
+
+	type row struct {
+		id     string
+		values []string
+	}
+	rewritten, after := 0, ""
+	for {
+		rows, err := r.pool.Query(ctx, selectQuery, r.keys.PrimaryPrefix()+"%", after, batchSize)
+		if err != nil {
+			return rewritten, err
+		}
+		var batch []row
+		for rows.Next() {
+			current := row{values: make([]string, len(columns))}
+			dest := []any{&current.id}
+			for i := range current.values {
+				dest = append(dest, &current.values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return rewritten, err
+			}
+			batch = append(batch, current)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rewritten, err
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+
+		for _, current := range batch {
+			args := []any{current.id}
+			for i, value := range current.values {
+				if !r.keys.NeedsRotation(value) {
+					args = append(args, value)
+					continue
+				}
+				sealed, err := r.keys.Reencrypt(value, piiField(table, columns[i], current.id))
+				if err != nil {
+					return rewritten, fmt.Errorf("%s %s %s: %w", table, current.id, columns[i], err)
+				}
+				args = append(args, sealed)
+			}
+			for _, value := range current.values {
+				args = append(args, value)
+			}
+			tag, err := r.pool.Exec(ctx, updateQuery, args...)
+			if err != nil {
+				return rewritten, err
+			}
+			rewritten += int(tag.RowsAffected())
+		}
+		after = batch[len(batch)-1].id
+	}
+}
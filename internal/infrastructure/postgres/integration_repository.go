@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/integration"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IntegrationRepository persists inbound integrations in PostgreSQL.
+type IntegrationRepository struct {
+	pool Querier
+}
+
+// NewIntegrationRepository constructs a repository.
+func NewIntegrationRepository(pool Querier) *IntegrationRepository {
+	return &IntegrationRepository{pool: pool}
+}
+
+var _ domain.Repository = (*IntegrationRepository)(nil)
+
+const integrationColumns = `id, name, kind, description, secret, active, created_at, updated_at`
+
+// Create inserts a new integration.
+func (r *IntegrationRepository) Create(ctx context.Context, integration *domain.Integration) error {
+	const query = `
+INSERT INTO integrations (` + integrationColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+	_, err := r.pool.Exec(ctx, query,
+		integration.ID,
+		integration.Name,
+		integration.Kind,
+		integration.Description,
+		integration.Secret,
+		integration.Active,
+		integration.CreatedAt,
+		integration.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateName
+	}
+	return err
+}
+
+// GetByID fetches an integration by id.
+func (r *IntegrationRepository) GetByID(ctx context.Context, id string) (*domain.Integration, error) {
+	const query = `SELECT ` + integrationColumns + ` FROM integrations WHERE id = $1`
+	integration, err := scanIntegration(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return integration, nil
+}
+
+// List returns all integrations sorted by name.
+func (r *IntegrationRepository) List(ctx context.Context) ([]*domain.Integration, error) {
+	const query = `SELECT ` + integrationColumns + ` FROM integrations ORDER BY name ASC`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var integrations []*domain.Integration
+	for rows.Next() {
+		integration, err := scanIntegration(rows)
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, integration)
+	}
+	return integrations, rows.Err()
+}
+
+// Update writes integration updates.
+func (r *IntegrationRepository) Update(ctx context.Context, integration *domain.Integration) error {
+	const query = `
+UPDATE integrations
+SET name = $2,
+    description = $3,
+    secret = $4,
+    active = $5,
+    updated_at = $6
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query,
+		integration.ID,
+		integration.Name,
+		integration.Description,
+		integration.Secret,
+		integration.Active,
+		integration.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateName
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes an integration.
+func (r *IntegrationRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM integrations WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanIntegration(row pgx.Row) (*domain.Integration, error) {
+	var i domain.Integration
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.Description,
+		&i.Secret,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
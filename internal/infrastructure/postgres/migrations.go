@@ -2,30 +2,371 @@ package postgres
 
 import (
 	"context"
-	_ "embed"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-//go:embed migrations/schema.sql
-var schemaSQL string
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the advisory lock key held while migrating so replicas
+// starting at the same time apply migrations one after another.
+const migrationLockID = 7_246_913_001
+
+// noTransactionDirective at the top of an up/down file runs it outside a
+// transaction (needed for e.g. CREATE INDEX CONCURRENTLY).
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// ErrDirtyMigration means a previous migration failed part-way outside a
+// transaction and the schema must be repaired by hand before continuing.
+var ErrDirtyMigration = errors.New("database schema is dirty")
+
+// Migration is a numbered schema change with its rollback.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState reports whether a migration has been applied.
+type MigrationState struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations ordered by version.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		version, name, direction, err := parseMigrationFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		body, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseMigrationFilename splits "0002_add_role.up.sql" into its parts.
+func parseMigrationFilename(filename string) (int64, string, string, error) {
+	base, ok := strings.CutSuffix(filename, ".sql")
+	if !ok {
+		return 0, "", "", fmt.Errorf("migration %q: expected .sql extension", filename)
+	}
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", fmt.Errorf("migration %q: expected .up.sql or .down.sql", filename)
+	}
+	base = strings.TrimSuffix(base, direction)
+	rawVersion, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", fmt.Errorf("migration %q: expected <version>_<name>", filename)
+	}
+	version, err := strconv.ParseInt(rawVersion, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %q: invalid version", filename)
+	}
+	return version, name, strings.TrimPrefix(direction, "."), nil
+}
 
-// Migrate ensures the required tables exist.
+// Migrate applies all pending migrations.
 func (db *Database) Migrate(ctx context.Context) error {
+	_, err := db.MigrateUp(ctx, 0)
+	return err
+}
+
+// MigrateUp applies up to steps pending migrations (all when steps <= 0) and
+// returns the versions applied.
+func (db *Database) MigrateUp(ctx context.Context, steps int) ([]int64, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int64
+	err = withMigrationLock(ctx, db.Pool, func(conn *pgxpool.Conn) error {
+		current, err := loadMigrationRecords(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, done := current[m.Version]; done {
+				continue
+			}
+			if steps > 0 && len(applied) >= steps {
+				break
+			}
+			markDirty := func(q execer) error {
+				_, err := q.Exec(ctx, `INSERT INTO schema_migrations (version, name, dirty) VALUES ($1, $2, true)`, m.Version, m.Name)
+				return err
+			}
+			record := func(q execer) error {
+				_, err := q.Exec(ctx, `
+INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES ($1, $2, false, now())
+ON CONFLICT (version) DO UPDATE SET dirty = false, applied_at = now()`, m.Version, m.Name)
+				return err
+			}
+			if err := runMigration(ctx, conn, m.Version, m.Up, markDirty, record); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", m.Version, m.Name, err)
+			}
+			applied = append(applied, m.Version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown rolls back the most recent steps migrations (one when
+// steps <= 0) and returns the versions reverted.
+func (db *Database) MigrateDown(ctx context.Context, steps int) ([]int64, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var reverted []int64
+	err = withMigrationLock(ctx, db.Pool, func(conn *pgxpool.Conn) error {
+		current, err := loadMigrationRecords(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(current))
+		for v := range current {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, version := range versions {
+			if len(reverted) >= steps {
+				break
+			}
+			m, ok := byVersion[version]
+			if !ok {
+				return fmt.Errorf("migration %d is applied but not embedded in this build", version)
+			}
+			if strings.TrimSpace(m.Down) == "" {
+				return fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+			}
+			markDirty := func(q execer) error {
+				_, err := q.Exec(ctx, `UPDATE schema_migrations SET dirty = true WHERE version = $1`, m.Version)
+				return err
+			}
+			record := func(q execer) error {
+				_, err := q.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+				return err
+			}
+			if err := runMigration(ctx, conn, m.Version, m.Down, markDirty, record); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", m.Version, m.Name, err)
+			}
+			reverted = append(reverted, version)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatus lists every embedded migration with its applied state.
+func (db *Database) MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
 	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	records, err := queryMigrationRecords(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Version: m.Version, Name: m.Name}
+		if rec, ok := records[m.Version]; ok {
+			appliedAt := rec.appliedAt
+			state.Applied = !rec.dirty
+			state.Dirty = rec.dirty
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// ForceMigrationVersion records version as cleanly applied without running
+// it. Operators use it after repairing a dirty migration by hand.
+func (db *Database) ForceMigrationVersion(ctx context.Context, version int64) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	name := ""
+	for _, m := range migrations {
+		if m.Version == version {
+			name = m.Name
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("migration %d is not embedded in this build", version)
+	}
+	return withMigrationLock(ctx, db.Pool, func(conn *pgxpool.Conn) error {
+		_, err := conn.Exec(ctx, `
+INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES ($1, $2, false, now())
+ON CONFLICT (version) DO UPDATE SET dirty = false`, version, name)
+		return err
+	})
+}
+
+type migrationRecord struct {
+	dirty     bool
+	appliedAt time.Time
+}
+
+func withMigrationLock(ctx context.Context, pool *pgxpool.Pool, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := pool.Acquire(ctx)
 	if err != nil {
 		return err
 	}
 	defer conn.Release()
 
-	statements := strings.Split(schemaSQL, ";")
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled.
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+	}()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureMigrationsTable(ctx context.Context, conn *pgxpool.Conn) error {
+	_, err := conn.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    dirty BOOLEAN NOT NULL DEFAULT false,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`)
+	return err
+}
+
+// loadMigrationRecords returns applied migrations, refusing to continue when
+// any of them is dirty.
+func loadMigrationRecords(ctx context.Context, conn *pgxpool.Conn) (map[int64]migrationRecord, error) {
+	records, err := queryMigrationRecords(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for version, rec := range records {
+		if rec.dirty {
+			return nil, fmt.Errorf("%w: migration %d did not complete; fix the schema and clear the dirty flag", ErrDirtyMigration, version)
 		}
-		if _, err := conn.Exec(ctx, stmt); err != nil {
+	}
+	return records, nil
+}
+
+func queryMigrationRecords(ctx context.Context, conn *pgxpool.Conn) (map[int64]migrationRecord, error) {
+	rows, err := conn.Query(ctx, `SELECT version, dirty, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := map[int64]migrationRecord{}
+	for rows.Next() {
+		var version int64
+		var rec migrationRecord
+		if err := rows.Scan(&version, &rec.dirty, &rec.appliedAt); err != nil {
+			return nil, err
+		}
+		records[version] = rec
+	}
+	return records, rows.Err()
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// runMigration executes sql and then record inside one transaction. Files
+// marked with the no-transaction directive instead run between markDirty and
+// record, so a failure leaves the version flagged dirty for manual repair.
+func runMigration(ctx context.Context, conn *pgxpool.Conn, version int64, sql string, markDirty, record func(execer) error) error {
+	if strings.HasPrefix(strings.TrimSpace(sql), noTransactionDirective) {
+		if err := markDirty(conn); err != nil {
 			return err
 		}
+		if _, err := conn.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("%w (schema_migrations version %d left dirty)", err, version)
+		}
+		return record(conn)
 	}
-	return nil
+
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+		return record(tx)
+	})
 }
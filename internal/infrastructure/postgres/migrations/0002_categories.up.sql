@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS category_id TEXT REFERENCES categories (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS products_category_id_idx ON products (category_id);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS categories_notify_change ON categories;
+DROP TRIGGER IF EXISTS products_notify_change ON products;
+DROP FUNCTION IF EXISTS notify_change();
@@ -0,0 +1,46 @@
+-- Ledger of stock and price changes, written by trigger so every code path
+-- (API, seeding, manual SQL) is captured. Rows keep the category at the time
+-- of the change and survive product deletion, which is what point-in-time
+-- valuation needs.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id TEXT NOT NULL,
+    category_id TEXT,
+    quantity_delta INTEGER NOT NULL,
+    quantity_after INTEGER NOT NULL,
+    unit_price NUMERIC(12, 2) NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_time_idx
+    ON stock_movements (product_id, occurred_at DESC, id DESC);
+
+CREATE OR REPLACE FUNCTION record_stock_movement() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+        VALUES (NEW.id, NEW.category_id, NEW.quantity, NEW.quantity, NEW.price);
+    ELSIF TG_OP = 'UPDATE' THEN
+        IF NEW.quantity IS DISTINCT FROM OLD.quantity
+            OR NEW.price IS DISTINCT FROM OLD.price
+            OR NEW.category_id IS DISTINCT FROM OLD.category_id THEN
+            INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+            VALUES (NEW.id, NEW.category_id, NEW.quantity - OLD.quantity, NEW.quantity, NEW.price);
+        END IF;
+    ELSE
+        INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+        VALUES (OLD.id, OLD.category_id, -OLD.quantity, 0, OLD.price);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_record_stock_movement
+    AFTER INSERT OR UPDATE OR DELETE ON products
+    FOR EACH ROW EXECUTE FUNCTION record_stock_movement();
+
+-- Opening balances: history before this migration is unknown, so each
+-- product's current stock is recorded as of its last update.
+INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price, occurred_at)
+SELECT id, category_id, quantity, quantity, price, updated_at
+FROM products;
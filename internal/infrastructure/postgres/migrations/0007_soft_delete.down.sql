@@ -0,0 +1,34 @@
+-- Trashed rows cannot be represented without deleted_at; drop them first.
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION record_stock_movement() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+        VALUES (NEW.id, NEW.category_id, NEW.quantity, NEW.quantity, NEW.price);
+    ELSIF TG_OP = 'UPDATE' THEN
+        IF NEW.quantity IS DISTINCT FROM OLD.quantity
+            OR NEW.price IS DISTINCT FROM OLD.price
+            OR NEW.category_id IS DISTINCT FROM OLD.category_id THEN
+            INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+            VALUES (NEW.id, NEW.category_id, NEW.quantity - OLD.quantity, NEW.quantity, NEW.price);
+        END IF;
+    ELSE
+        INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+        VALUES (OLD.id, OLD.category_id, -OLD.quantity, 0, OLD.price);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS products_deleted_at_idx;
+DROP INDEX IF EXISTS users_deleted_at_idx;
+
+DROP INDEX IF EXISTS products_sku_live_idx;
+ALTER TABLE products ADD CONSTRAINT products_sku_key UNIQUE (sku);
+DROP INDEX IF EXISTS users_email_live_idx;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
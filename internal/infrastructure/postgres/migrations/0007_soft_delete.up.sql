@@ -0,0 +1,43 @@
+-- Deletes move rows to the trash (deleted_at set) until they are restored or
+-- purged. Uniqueness only applies to live rows so a trashed email or SKU can
+-- be reused.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_live_idx ON users (email) WHERE deleted_at IS NULL;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_live_idx ON products (sku) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS products_deleted_at_idx ON products (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Trashing takes stock out of the valuation and restoring puts it back;
+-- purging an already-trashed product changes nothing.
+CREATE OR REPLACE FUNCTION record_stock_movement() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+        VALUES (NEW.id, NEW.category_id, NEW.quantity, NEW.quantity, NEW.price);
+    ELSIF TG_OP = 'UPDATE' THEN
+        IF NEW.deleted_at IS DISTINCT FROM OLD.deleted_at THEN
+            IF NEW.deleted_at IS NULL THEN
+                INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+                VALUES (NEW.id, NEW.category_id, NEW.quantity, NEW.quantity, NEW.price);
+            ELSE
+                INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+                VALUES (OLD.id, OLD.category_id, -OLD.quantity, 0, OLD.price);
+            END IF;
+        ELSIF NEW.quantity IS DISTINCT FROM OLD.quantity
+            OR NEW.price IS DISTINCT FROM OLD.price
+            OR NEW.category_id IS DISTINCT FROM OLD.category_id THEN
+            INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+            VALUES (NEW.id, NEW.category_id, NEW.quantity - OLD.quantity, NEW.quantity, NEW.price);
+        END IF;
+    ELSIF OLD.deleted_at IS NULL THEN
+        INSERT INTO stock_movements (product_id, category_id, quantity_delta, quantity_after, unit_price)
+        VALUES (OLD.id, OLD.category_id, -OLD.quantity, 0, OLD.price);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,15 @@
+-- Stock held for pending orders. A reservation counts against its product's
+-- available quantity until it is confirmed, released or reaches expires_at;
+-- expired rows are ignored by every query and deleted by the worker.
+CREATE TABLE IF NOT EXISTS product_reservations (
+    id TEXT PRIMARY KEY,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reference TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_reservations_product ON product_reservations (product_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_product_reservations_expiry ON product_reservations (expires_at);
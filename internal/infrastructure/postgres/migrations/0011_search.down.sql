@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS categories_name_trgm_idx;
+DROP INDEX IF EXISTS users_email_trgm_idx;
+DROP INDEX IF EXISTS users_name_trgm_idx;
+DROP INDEX IF EXISTS products_sku_trgm_idx;
+DROP INDEX IF EXISTS products_name_trgm_idx;
@@ -0,0 +1,17 @@
+-- Audit trail behind the admin activity feed: one row per domain event with
+-- the user who caused it. actor_id is empty for changes made by background
+-- jobs; entity_name keeps the label the record had at the time, so entries
+-- stay readable after it is renamed or purged.
+CREATE TABLE IF NOT EXISTS activity_log (
+    id TEXT PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    actor_id TEXT NOT NULL DEFAULT '',
+    entity_type TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    entity_name TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_log_recent ON activity_log (occurred_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_activity_log_actor ON activity_log (actor_id, occurred_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_activity_log_entity ON activity_log (entity_type, occurred_at DESC, id DESC);
@@ -0,0 +1,4 @@
+-- Users' preferred language for API messages; empty defers to the request's
+-- Accept-Language header.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT '';
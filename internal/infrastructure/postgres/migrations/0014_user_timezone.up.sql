@@ -0,0 +1,4 @@
+-- Users' preferred IANA timezone for rendering API timestamps; empty means
+-- UTC. Timestamps themselves are always stored in UTC.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT '';
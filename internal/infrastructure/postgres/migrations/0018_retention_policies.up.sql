@@ -0,0 +1,8 @@
+-- Retention admins set through the API; targets without a row keep the
+-- configured default.
+CREATE TABLE IF NOT EXISTS retention_policies (
+    target TEXT PRIMARY KEY,
+    days INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    updated_by TEXT NOT NULL
+);
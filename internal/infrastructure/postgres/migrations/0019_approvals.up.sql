@@ -0,0 +1,18 @@
+-- Destructive admin actions waiting for, or decided by, a second admin.
+CREATE TABLE IF NOT EXISTS approvals (
+    id TEXT PRIMARY KEY,
+    action TEXT NOT NULL,
+    status TEXT NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    requested_by TEXT NOT NULL,
+    decided_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    decided_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS approvals_status_created_at_idx ON approvals (status, created_at DESC);
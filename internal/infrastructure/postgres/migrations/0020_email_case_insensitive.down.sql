@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_live_idx ON users (email) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS users_email_lower_live_idx;
@@ -0,0 +1,6 @@
+-- Emails are unique regardless of case, so Admin@x.com and admin@x.com cannot
+-- both be live accounts, and lookups compare lower(email), which this index
+-- serves. Building it fails if live users already differ only by case; trash
+-- or rename one of each pair first.
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_live_idx ON users (lower(email)) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS users_email_live_idx;
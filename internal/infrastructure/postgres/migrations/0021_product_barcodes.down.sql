@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS products_barcode_live_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS barcode;
@@ -0,0 +1,4 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS barcode TEXT;
+-- Products without a barcode store NULL, which the index leaves out, so any
+-- number of them can coexist.
+CREATE UNIQUE INDEX IF NOT EXISTS products_barcode_live_idx ON products (barcode) WHERE deleted_at IS NULL;
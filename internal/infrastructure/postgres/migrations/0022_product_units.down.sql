@@ -0,0 +1 @@
+ALTER TABLE products DROP COLUMN IF EXISTS pack_size, DROP COLUMN IF EXISTS unit;
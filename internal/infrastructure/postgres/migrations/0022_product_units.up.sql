@@ -0,0 +1,5 @@
+-- Quantities are counted in the product's unit; existing products are
+-- counted in pieces, one to a pack.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS unit TEXT NOT NULL DEFAULT 'piece' CHECK (unit IN ('piece', 'kg', 'liter')),
+    ADD COLUMN IF NOT EXISTS pack_size INTEGER NOT NULL DEFAULT 1 CHECK (pack_size >= 1);
@@ -0,0 +1,3 @@
+-- NULL until a cost price is recorded; such products are left out of the
+-- margin report.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_price NUMERIC(12, 2) CHECK (cost_price >= 0);
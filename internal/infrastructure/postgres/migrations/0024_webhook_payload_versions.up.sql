@@ -0,0 +1,3 @@
+-- Existing subscriptions were built against the first payload shape, so they
+-- stay on it; new ones are pinned to the latest version by the application.
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS payload_version INTEGER NOT NULL DEFAULT 1 CHECK (payload_version >= 1);
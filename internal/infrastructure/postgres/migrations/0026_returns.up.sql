@@ -0,0 +1,30 @@
+-- Returns of shipped orders and the lines being sent back. Lines keep the
+-- SKU and name from the order line they return.
+CREATE TABLE IF NOT EXISTS returns (
+    id TEXT PRIMARY KEY,
+    order_id TEXT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('requested', 'approved', 'received', 'rejected')),
+    note TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    requested_by TEXT NOT NULL DEFAULT '',
+    decided_by TEXT NOT NULL DEFAULT '',
+    received_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    decided_at TIMESTAMPTZ,
+    received_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS returns_status_created_at_idx ON returns (status, created_at DESC);
+CREATE INDEX IF NOT EXISTS returns_order_id_idx ON returns (order_id);
+
+CREATE TABLE IF NOT EXISTS return_lines (
+    return_id TEXT NOT NULL REFERENCES returns (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    product_id TEXT NOT NULL,
+    sku TEXT NOT NULL,
+    name TEXT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason TEXT NOT NULL,
+    PRIMARY KEY (return_id, position)
+);
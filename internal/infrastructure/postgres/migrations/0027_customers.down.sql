@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS customer_addresses;
+DROP TABLE IF EXISTS customers;
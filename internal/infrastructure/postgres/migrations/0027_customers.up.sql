@@ -0,0 +1,31 @@
+-- Customers and their address books. Each customer has at most one default
+-- billing and one default shipping address.
+CREATE TABLE IF NOT EXISTS customers (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS customer_addresses (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    label TEXT NOT NULL DEFAULT '',
+    recipient TEXT NOT NULL DEFAULT '',
+    line1 TEXT NOT NULL,
+    line2 TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL,
+    region TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    country CHAR(2) NOT NULL,
+    default_billing BOOLEAN NOT NULL DEFAULT FALSE,
+    default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_addresses_customer_idx ON customer_addresses (customer_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS customer_addresses_default_billing_idx ON customer_addresses (customer_id) WHERE default_billing;
+CREATE UNIQUE INDEX IF NOT EXISTS customer_addresses_default_shipping_idx ON customer_addresses (customer_id) WHERE default_shipping;
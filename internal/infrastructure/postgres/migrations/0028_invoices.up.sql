@@ -0,0 +1,13 @@
+-- Orders can name the customer they are for, who is billed on the invoice.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS orders_customer_id_idx ON orders (customer_id) WHERE customer_id <> '';
+
+-- Generated files, such as invoice PDFs, kept so they are only produced once.
+CREATE TABLE IF NOT EXISTS attachments (
+    key TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,22 @@
+-- Orders carry the status of their latest payment.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_status TEXT NOT NULL DEFAULT 'unpaid';
+
+-- Payments taken for orders through a payment provider. The provider's
+-- reference identifies the payment in its webhooks.
+CREATE TABLE IF NOT EXISTS payments (
+    id TEXT PRIMARY KEY,
+    order_id TEXT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed', 'partially_refunded', 'refunded')),
+    amount NUMERIC(12,2) NOT NULL,
+    currency TEXT NOT NULL,
+    refunded NUMERIC(12,2) NOT NULL DEFAULT 0,
+    checkout_url TEXT NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS payments_order_id_idx ON payments (order_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS payments_provider_reference_idx ON payments (provider, reference) WHERE reference <> '';
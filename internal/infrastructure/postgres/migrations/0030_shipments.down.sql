@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS shipments;
+ALTER TABLE orders DROP COLUMN IF EXISTS tracking_numbers;
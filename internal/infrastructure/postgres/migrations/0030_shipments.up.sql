@@ -0,0 +1,24 @@
+-- Orders carry the tracking numbers of the parcels sent for them.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tracking_numbers TEXT[] NOT NULL DEFAULT '{}';
+
+-- Parcels sent for orders through a shipping carrier. Tracking events are
+-- kept as the carrier last reported them.
+CREATE TABLE IF NOT EXISTS shipments (
+    id TEXT PRIMARY KEY,
+    order_id TEXT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    carrier TEXT NOT NULL,
+    service TEXT NOT NULL DEFAULT '',
+    tracking_number TEXT NOT NULL,
+    label_url TEXT NOT NULL DEFAULT '',
+    reference TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK (status IN ('label_created', 'in_transit', 'out_for_delivery', 'delivered', 'exception', 'returned')),
+    events JSONB NOT NULL DEFAULT '[]',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS shipments_order_id_idx ON shipments (order_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS shipments_carrier_tracking_idx ON shipments (carrier, tracking_number);
+CREATE INDEX IF NOT EXISTS shipments_open_idx ON shipments (created_at) WHERE status NOT IN ('delivered', 'returned');
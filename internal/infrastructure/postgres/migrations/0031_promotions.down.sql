@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS discount;
+ALTER TABLE orders DROP COLUMN IF EXISTS promotion_code;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,24 @@
+-- Discount codes redeemed on orders. Codes are stored upper-case.
+CREATE TABLE IF NOT EXISTS promotions (
+    id TEXT PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL CHECK (kind IN ('percentage', 'fixed')),
+    value NUMERIC(12,2) NOT NULL CHECK (value > 0),
+    min_subtotal NUMERIC(12,2) NOT NULL DEFAULT 0,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    usage_limit INTEGER NOT NULL DEFAULT 0,
+    used INTEGER NOT NULL DEFAULT 0,
+    product_ids TEXT[] NOT NULL DEFAULT '{}',
+    category_ids TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Orders carry the code redeemed on them and the discount it gave; total
+-- is what is left to pay.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promotion_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount NUMERIC(12,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+-- Product names and descriptions in locales other than PRODUCT_LOCALE, the
+-- one products themselves are written in.
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    locale TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (product_id, locale)
+);
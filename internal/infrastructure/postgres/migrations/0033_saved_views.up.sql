@@ -0,0 +1,12 @@
+-- Filter and sort presets users save for the product and user lists.
+CREATE TABLE IF NOT EXISTS saved_views (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource TEXT NOT NULL,
+    name TEXT NOT NULL,
+    filters JSONB NOT NULL DEFAULT '[]',
+    sort TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (user_id, resource, name)
+);
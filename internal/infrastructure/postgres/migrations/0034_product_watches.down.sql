@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS watch_notifications;
+DROP TABLE IF EXISTS product_watches;
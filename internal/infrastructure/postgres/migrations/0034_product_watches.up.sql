@@ -0,0 +1,29 @@
+-- Users' subscriptions to changes of individual products. product_name and
+-- last_* hold the product as the watcher was last told about it.
+CREATE TABLE IF NOT EXISTS product_watches (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    fields TEXT[] NOT NULL,
+    product_name TEXT NOT NULL,
+    last_price NUMERIC(12,2) NOT NULL,
+    last_stock INTEGER NOT NULL,
+    last_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_watches_product_id_idx ON product_watches (product_id);
+
+-- Notifications outlive the product they describe, so a purged product's
+-- history stays readable.
+CREATE TABLE IF NOT EXISTS watch_notifications (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id TEXT NOT NULL,
+    product_name TEXT NOT NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS watch_notifications_user_id_created_at_idx ON watch_notifications (user_id, created_at DESC);
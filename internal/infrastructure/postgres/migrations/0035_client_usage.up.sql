@@ -0,0 +1,8 @@
+-- Requests per API client and UTC month, for monthly quotas.
+CREATE TABLE IF NOT EXISTS client_usage (
+    client_id TEXT NOT NULL,
+    month DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (client_id, month)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE sessions DROP COLUMN IF EXISTS client_ip;
+ALTER TABLE activity_log DROP COLUMN IF EXISTS actor_ip;
@@ -0,0 +1,8 @@
+-- The client address, resolved through trusted proxies, that caused each
+-- audit entry and opened each session. Empty for rows recorded before it
+-- was kept and for changes made by background jobs.
+ALTER TABLE activity_log
+    ADD COLUMN IF NOT EXISTS actor_ip TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS client_ip TEXT NOT NULL DEFAULT '';
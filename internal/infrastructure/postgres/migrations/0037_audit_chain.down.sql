@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_activity_log_seq;
+ALTER TABLE activity_log
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS seq;
+DROP SEQUENCE IF EXISTS activity_log_seq;
+DROP FUNCTION IF EXISTS activity_chain_hash(TEXT, TEXT, TIMESTAMPTZ, TEXT, TEXT, TEXT, TEXT);
@@ -0,0 +1,76 @@
+-- Chains the audit trail: each entry stores the hash of the one recorded
+-- before it, so editing, removing or reordering entries breaks the chain.
+-- seq is the chain order; existing entries are numbered by occurred_at.
+ALTER TABLE activity_log
+    ADD COLUMN IF NOT EXISTS seq BIGINT,
+    ADD COLUMN IF NOT EXISTS prev_hash TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS hash TEXT NOT NULL DEFAULT '';
+
+UPDATE activity_log AS a
+SET seq = numbered.seq
+FROM (SELECT id, row_number() OVER (ORDER BY occurred_at, id) AS seq FROM activity_log) AS numbered
+WHERE a.id = numbered.id AND a.seq IS NULL;
+
+CREATE SEQUENCE IF NOT EXISTS activity_log_seq OWNED BY activity_log.seq;
+SELECT setval('activity_log_seq', COALESCE((SELECT max(seq) FROM activity_log), 0) + 1, false);
+
+ALTER TABLE activity_log
+    ALTER COLUMN seq SET DEFAULT nextval('activity_log_seq'),
+    ALTER COLUMN seq SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_log_seq ON activity_log (seq);
+
+-- activity_chain_hash computes exactly what activity.Entry.ChainHash does:
+-- each chained field as its byte length, a colon, the value and a newline.
+-- actor_ip and entity_name are personal data that anonymizing a user
+-- erases, so they are not chained. NULLs hash like Go's zero values: the
+-- empty string, and 0001-01-01 for occurred_at. The Go and SQL versions are
+-- pinned to the same fixtures in tests; keep them in step.
+CREATE OR REPLACE FUNCTION activity_chain_hash(
+    prev TEXT,
+    id TEXT,
+    occurred_at TIMESTAMPTZ,
+    actor_id TEXT,
+    entity_type TEXT,
+    action TEXT,
+    entity_id TEXT
+) RETURNS TEXT AS $$
+DECLARE
+    chain_input TEXT := '';
+    part TEXT;
+BEGIN
+    FOREACH part IN ARRAY ARRAY[
+        COALESCE(prev, ''),
+        COALESCE(id, ''),
+        to_char(COALESCE(occurred_at, '0001-01-01T00:00:00Z') AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+        COALESCE(actor_id, ''),
+        COALESCE(entity_type, ''),
+        COALESCE(action, ''),
+        COALESCE(entity_id, '')
+    ] LOOP
+        chain_input := chain_input || octet_length(part) || ':' || part || E'\n';
+    END LOOP;
+    RETURN encode(sha256(convert_to(chain_input, 'UTF8')), 'hex');
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Chain the entries recorded before this migration, oldest first.
+DO $$
+DECLARE
+    entry RECORD;
+    prev TEXT := '';
+BEGIN
+    FOR entry IN
+        SELECT id, occurred_at, actor_id, entity_type, action, entity_id
+        FROM activity_log
+        WHERE hash = ''
+        ORDER BY seq
+    LOOP
+        UPDATE activity_log
+        SET prev_hash = prev,
+            hash = activity_chain_hash(prev, entry.id, entry.occurred_at, entry.actor_id, entry.entity_type, entry.action, entry.entity_id)
+        WHERE id = entry.id
+        RETURNING hash INTO prev;
+    END LOOP;
+END
+$$;
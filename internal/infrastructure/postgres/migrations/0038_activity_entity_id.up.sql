@@ -0,0 +1,2 @@
+-- Finds every change made to one record, such as for a user's data export.
+CREATE INDEX IF NOT EXISTS idx_activity_log_entity_id ON activity_log (entity_id, occurred_at DESC, id DESC);
@@ -0,0 +1,21 @@
+-- Published versions of the terms of service and privacy policy.
+CREATE TABLE IF NOT EXISTS policy_versions (
+    document TEXT NOT NULL,
+    version TEXT NOT NULL,
+    mandatory BOOLEAN NOT NULL DEFAULT FALSE,
+    url TEXT NOT NULL DEFAULT '',
+    published_by TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (document, version)
+);
+
+-- Each user's acceptance of a version, kept as evidence of consent.
+CREATE TABLE IF NOT EXISTS user_consents (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document TEXT NOT NULL,
+    version TEXT NOT NULL,
+    client_ip TEXT NOT NULL DEFAULT '',
+    accepted_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, document, version),
+    FOREIGN KEY (document, version) REFERENCES policy_versions (document, version)
+);
@@ -0,0 +1,3 @@
+-- Anonymized users are scrubbed and out of use like trashed ones, but are
+-- never restored or purged so that rows naming them stay valid.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
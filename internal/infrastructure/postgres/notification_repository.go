@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/notification"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// NotificationChannelRepository persists notification channels in
+// PostgreSQL.
+type NotificationChannelRepository struct {
+	pool Querier
+}
+
+// NewNotificationChannelRepository constructs a repository.
+func NewNotificationChannelRepository(pool Querier) *NotificationChannelRepository {
+	return &NotificationChannelRepository{pool: pool}
+}
+
+var _ domain.Repository = (*NotificationChannelRepository)(nil)
+
+const notificationChannelColumns = `id, name, kind, webhook_url, events, active, created_at, updated_at`
+
+// Create inserts a new channel.
+func (r *NotificationChannelRepository) Create(ctx context.Context, channel *domain.Channel) error {
+	const query = `
+INSERT INTO notification_channels (` + notificationChannelColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+	_, err := r.pool.Exec(ctx, query,
+		channel.ID,
+		channel.Name,
+		channel.Kind,
+		channel.WebhookURL,
+		channel.Events,
+		channel.Active,
+		channel.CreatedAt,
+		channel.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateName
+	}
+	return err
+}
+
+// GetByID fetches a channel by id.
+func (r *NotificationChannelRepository) GetByID(ctx context.Context, id string) (*domain.Channel, error) {
+	const query = `SELECT ` + notificationChannelColumns + ` FROM notification_channels WHERE id = $1`
+	channel, err := scanNotificationChannel(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return channel, nil
+}
+
+// List returns all channels sorted by name.
+func (r *NotificationChannelRepository) List(ctx context.Context) ([]*domain.Channel, error) {
+	const query = `SELECT ` + notificationChannelColumns + ` FROM notification_channels ORDER BY name ASC`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []*domain.Channel
+	for rows.Next() {
+		channel, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, rows.Err()
+}
+
+// Update writes channel updates. The kind never changes.
+func (r *NotificationChannelRepository) Update(ctx context.Context, channel *domain.Channel) error {
+	const query = `
+UPDATE notification_channels
+SET name = $2,
+    webhook_url = $3,
+    events = $4,
+    active = $5,
+    updated_at = $6
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query,
+		channel.ID,
+		channel.Name,
+		channel.WebhookURL,
+		channel.Events,
+		channel.Active,
+		channel.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateName
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a channel.
+func (r *NotificationChannelRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM notification_channels WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanNotificationChannel(row pgx.Row) (*domain.Channel, error) {
+	var c domain.Channel
+	err := row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Kind,
+		&c.WebhookURL,
+		&c.Events,
+		&c.Active,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// ChangeChannel is the LISTEN/NOTIFY channel shared by all API instances.
+const ChangeChannel = "backoffice_changes"
+
+const (
+	listenMinBackoff = time.Second
+	listenMaxBackoff = 30 * time.Second
+)
+
+// ChangeEvent is the payload published on ChangeChannel. Row changes come
+// from table triggers; other events (such as a config reload) are sent with
+// NotifyChange and carry the Origin instance so it can ignore its own.
+type ChangeEvent struct {
+	Table  string `json:"table"`
+	Op     string `json:"op"`
+	ID     string `json:"id,omitempty"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// NotifyChange publishes event to every listening instance.
+func (db *Database) NotifyChange(ctx context.Context, event ChangeEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = db.Pool.Exec(ctx, `SELECT pg_notify($1, $2)`, ChangeChannel, string(payload))
+	return err
+}
+
+// ListenChanges holds a dedicated connection LISTENing on ChangeChannel and
+// calls handle for each event until ctx is done. Events published by origin
+// itself are skipped. Lost connections are re-established with backoff; any
+// notifications sent while disconnected are lost, so handle is also called
+// with a synthetic {Op: "resync"} event after every reconnect.
+func (db *Database) ListenChanges(ctx context.Context, origin string, handle func(ChangeEvent)) {
+	backoff := listenMinBackoff
+	connected := false
+	for ctx.Err() == nil {
+		err := db.listenOnce(ctx, origin, func(event ChangeEvent) {
+			backoff = listenMinBackoff
+			handle(event)
+		}, func() {
+			if connected {
+				handle(ChangeEvent{Op: "resync"})
+			}
+			connected = true
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("change listener disconnected: %v (retrying in %s)", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, listenMaxBackoff)
+	}
+}
+
+func (db *Database) listenOnce(ctx context.Context, origin string, handle func(ChangeEvent), onListen func()) error {
+	pooled, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection has session state (LISTEN), so never return it to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+ChangeChannel); err != nil {
+		return err
+	}
+	onListen()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event ChangeEvent
+		if err := json.Unmarshal([]byte(n.Payload), &event); err != nil {
+			log.Printf("change listener: ignoring malformed payload %q", n.Payload)
+			continue
+		}
+		if origin != "" && event.Origin == origin {
+			continue
+		}
+		handle(event)
+	}
+}
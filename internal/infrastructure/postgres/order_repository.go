@@ -0,0 +1,297 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	domain "backoffice/backend/internal/domain/order"
+	productdomain "backoffice/backend/internal/domain/product"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OrderRepository persists orders in PostgreSQL.
+type OrderRepository struct {
+	pool Querier
+}
+
+// NewOrderRepository constructs a repository.
+func NewOrderRepository(pool Querier) *OrderRepository {
+	return &OrderRepository{pool: pool}
+}
+
+var _ domain.Repository = (*OrderRepository)(nil)
+
+const orderColumns = `id, reference, customer_id, status, payment_status, tracking_numbers, promotion_code, discount, total, created_by, created_at, updated_at`
+
+// Create takes the stock of the order's lines and inserts the order with its
+// lines and history, all in one transaction.
+func (r *OrderRepository) Create(ctx context.Context, o *domain.Order) error {
+	const orderQuery = `
+INSERT INTO orders (` + orderColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+`
+	const lineQuery = `
+INSERT INTO order_lines (order_id, position, product_id, sku, name, quantity, unit_price, total)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+	deltas := o.Quantities()
+	for id := range deltas {
+		deltas[id] = -deltas[id]
+	}
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := moveStock(ctx, tx, deltas, o.CreatedAt, false); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, orderQuery, o.ID, o.Reference, o.CustomerID, o.Status, o.PaymentStatus, o.TrackingNumbers, o.PromotionCode, o.Discount, o.Total, o.CreatedBy, o.CreatedAt, o.UpdatedAt); err != nil {
+			return err
+		}
+		for i, l := range o.Lines {
+			if _, err := tx.Exec(ctx, lineQuery, o.ID, i, l.ProductID, l.SKU, l.Name, l.Quantity, l.UnitPrice, l.Total); err != nil {
+				return err
+			}
+		}
+		for i, t := range o.History {
+			if err := insertTransition(ctx, tx, o.ID, i, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID fetches an order with its lines and history.
+func (r *OrderRepository) GetByID(ctx context.Context, id string) (*domain.Order, error) {
+	const query = `SELECT ` + orderColumns + ` FROM orders WHERE id = $1`
+	o, err := scanOrder(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadDetails(ctx, []*domain.Order{o}); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// List returns orders with the status, or all when it is empty, newest
+// first.
+func (r *OrderRepository) List(ctx context.Context, status string) ([]*domain.Order, error) {
+	const query = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE $1 = '' OR status = $1
+ORDER BY created_at DESC, id DESC
+`
+	rows, err := r.pool.Query(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadDetails(ctx, orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// Transition stores the order's status and appends the latest entry of its
+// history. The status is only changed while it still matches the entry's
+// From, so of two concurrent transitions from one status only the first
+// is recorded, and a cancellation returns the lines' stock at most once.
+func (r *OrderRepository) Transition(ctx context.Context, o *domain.Order) error {
+	const updateQuery = `UPDATE orders SET status = $2, updated_at = $3 WHERE id = $1 AND status = $4`
+	last := o.Last()
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, updateQuery, o.ID, o.Status, o.UpdatedAt, last.From)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrStatusChanged
+		}
+		if o.Status == domain.StatusCancelled {
+			if err := moveStock(ctx, tx, o.Quantities(), o.UpdatedAt, true); err != nil {
+				return err
+			}
+		}
+		return insertTransition(ctx, tx, o.ID, len(o.History)-1, last)
+	})
+}
+
+// SetPaymentStatus records the status of the order's latest payment.
+func (r *OrderRepository) SetPaymentStatus(ctx context.Context, id, status string, at time.Time) error {
+	const query = `UPDATE orders SET payment_status = $2, updated_at = $3 WHERE id = $1`
+	tag, err := r.pool.Exec(ctx, query, id, status, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// AddTrackingNumber appends a parcel's tracking number to the order unless
+// it is already listed.
+func (r *OrderRepository) AddTrackingNumber(ctx context.Context, id, number string, at time.Time) error {
+	const query = `
+UPDATE orders
+SET tracking_numbers = CASE WHEN $2 = ANY(tracking_numbers) THEN tracking_numbers ELSE array_append(tracking_numbers, $2) END,
+    updated_at = $3
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query, id, number, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// SetPromotion records the discount code redeemed on the order.
+func (r *OrderRepository) SetPromotion(ctx context.Context, id, code string, discount, total float64, at time.Time) error {
+	const query = `UPDATE orders SET promotion_code = $2, discount = $3, total = $4, updated_at = $5 WHERE id = $1`
+	tag, err := r.pool.Exec(ctx, query, id, code, discount, total, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func insertTransition(ctx context.Context, tx pgx.Tx, orderID string, position int, t domain.Transition) error {
+	const query = `
+INSERT INTO order_transitions (order_id, position, from_status, to_status, note, changed_by, changed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+	_, err := tx.Exec(ctx, query, orderID, position, t.From, t.To, t.Note, t.By, t.At)
+	return err
+}
+
+// loadDetails fills in the lines and history of orders.
+func (r *OrderRepository) loadDetails(ctx context.Context, orders []*domain.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	const linesQuery = `
+SELECT order_id, product_id, sku, name, quantity, unit_price, total
+FROM order_lines
+WHERE order_id = ANY($1)
+ORDER BY order_id, position
+`
+	const historyQuery = `
+SELECT order_id, from_status, to_status, note, changed_by, changed_at
+FROM order_transitions
+WHERE order_id = ANY($1)
+ORDER BY order_id, position
+`
+	byID := make(map[string]*domain.Order, len(orders))
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		o.Lines = []domain.Line{}
+		o.History = []domain.Transition{}
+		byID[o.ID] = o
+		ids = append(ids, o.ID)
+	}
+
+	rows, err := r.pool.Query(ctx, linesQuery, ids)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var orderID string
+		var l domain.Line
+		if err := rows.Scan(&orderID, &l.ProductID, &l.SKU, &l.Name, &l.Quantity, &l.UnitPrice, &l.Total); err != nil {
+			rows.Close()
+			return err
+		}
+		byID[orderID].Lines = append(byID[orderID].Lines, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.pool.Query(ctx, historyQuery, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var orderID string
+		var t domain.Transition
+		if err := rows.Scan(&orderID, &t.From, &t.To, &t.Note, &t.By, &t.At); err != nil {
+			return err
+		}
+		byID[orderID].History = append(byID[orderID].History, t)
+	}
+	return rows.Err()
+}
+
+func scanOrder(row pgx.Row) (*domain.Order, error) {
+	var o domain.Order
+	err := row.Scan(
+		&o.ID,
+		&o.Reference,
+		&o.CustomerID,
+		&o.Status,
+		&o.PaymentStatus,
+		&o.TrackingNumbers,
+		&o.PromotionCode,
+		&o.Discount,
+		&o.Total,
+		&o.CreatedBy,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// moveStock adds each of deltas, keyed by product id, to its product's
+// quantity within tx. The product rows are locked in id order, so
+// concurrent orders sharing products cannot deadlock, and a reduction must
+// leave the reserved stock on hand or fails with ErrInsufficientStock. A
+// missing product fails with ErrNotFound unless skipMissing is set.
+func moveStock(ctx context.Context, tx pgx.Tx, deltas map[string]int, at time.Time, skipMissing bool) error {
+	const query = `UPDATE products SET quantity = quantity + $2, updated_at = $3 WHERE id = $1`
+	for _, id := range slices.Sorted(maps.Keys(deltas)) {
+		quantity, reserved, err := lockStock(ctx, tx, id, at)
+		if errors.Is(err, productdomain.ErrNotFound) && skipMissing {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !productdomain.KeepsReserved(quantity, reserved, deltas[id]) {
+			return productdomain.ErrInsufficientStock.With("productId", id)
+		}
+		if _, err := tx.Exec(ctx, query, id, deltas[id], at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
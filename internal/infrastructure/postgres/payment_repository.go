@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/payment"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PaymentRepository persists payments in PostgreSQL.
+type PaymentRepository struct {
+	pool Querier
+}
+
+// NewPaymentRepository constructs a repository.
+func NewPaymentRepository(pool Querier) *PaymentRepository {
+	return &PaymentRepository{pool: pool}
+}
+
+var _ domain.Repository = (*PaymentRepository)(nil)
+
+const paymentColumns = `id, order_id, provider, reference, status, amount, currency, refunded, checkout_url, failure_reason, created_at, updated_at`
+
+// Create inserts a payment.
+func (r *PaymentRepository) Create(ctx context.Context, p *domain.Payment) error {
+	const query = `
+INSERT INTO payments (` + paymentColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+`
+	_, err := r.pool.Exec(ctx, query,
+		p.ID,
+		p.OrderID,
+		p.Provider,
+		p.Reference,
+		p.Status,
+		p.Amount,
+		p.Currency,
+		p.Refunded,
+		p.CheckoutURL,
+		p.FailureReason,
+		p.CreatedAt,
+		p.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID fetches a payment by id.
+func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
+	const query = `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+	return r.get(ctx, query, id)
+}
+
+// GetByReference finds a payment by the provider's reference for it.
+func (r *PaymentRepository) GetByReference(ctx context.Context, provider, reference string) (*domain.Payment, error) {
+	const query = `SELECT ` + paymentColumns + ` FROM payments WHERE provider = $1 AND reference = $2 AND reference <> ''`
+	return r.get(ctx, query, provider, reference)
+}
+
+func (r *PaymentRepository) get(ctx context.Context, query string, args ...any) (*domain.Payment, error) {
+	p, err := scanPayment(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListByOrder returns an order's payments, newest first.
+func (r *PaymentRepository) ListByOrder(ctx context.Context, orderID string) ([]*domain.Payment, error) {
+	const query = `
+SELECT ` + paymentColumns + `
+FROM payments
+WHERE order_id = $1
+ORDER BY created_at DESC, id DESC
+`
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*domain.Payment
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// Update stores a payment's provider reference, status and refunds.
+func (r *PaymentRepository) Update(ctx context.Context, p *domain.Payment) error {
+	const query = `
+UPDATE payments
+SET reference = $2, status = $3, refunded = $4, checkout_url = $5, failure_reason = $6, updated_at = $7
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query, p.ID, p.Reference, p.Status, p.Refunded, p.CheckoutURL, p.FailureReason, p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanPayment(row pgx.Row) (*domain.Payment, error) {
+	var p domain.Payment
+	err := row.Scan(
+		&p.ID,
+		&p.OrderID,
+		&p.Provider,
+		&p.Reference,
+		&p.Status,
+		&p.Amount,
+		&p.Currency,
+		&p.Refunded,
+		&p.CheckoutURL,
+		&p.FailureReason,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
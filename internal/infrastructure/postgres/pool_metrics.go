@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backoffice/backend/internal/metrics"
+)
+
+// poolSampleInterval is how often MonitorPool refreshes the pool gauges.
+const poolSampleInterval = time.Second
+
+var (
+	poolConnections = metrics.Default.NewGaugeVec("db_pool_connections",
+		"Pooled connections by state (idle, acquired, constructing).", "state")
+	poolMaxConnections = metrics.Default.NewGauge("db_pool_max_connections",
+		"Size limit of the connection pool.")
+	poolSaturation = metrics.Default.NewGauge("db_pool_saturation",
+		"Share of the pool's connections in use, from 0 to 1.")
+)
+
+// Saturation returns the share of the pool's connections in use, from 0
+// to 1, and refreshes the pool gauges.
+func (db *Database) Saturation() float64 {
+	st := db.Pool.Stat()
+	poolConnections.Set(float64(st.IdleConns()), "idle")
+	poolConnections.Set(float64(st.AcquiredConns()), "acquired")
+	poolConnections.Set(float64(st.ConstructingConns()), "constructing")
+	poolMaxConnections.Set(float64(st.MaxConns()))
+	saturation := 0.0
+	if st.MaxConns() > 0 {
+		saturation = float64(st.AcquiredConns()) / float64(st.MaxConns())
+	}
+	poolSaturation.Set(saturation)
+	return saturation
+}
+
+// MonitorPool refreshes the pool gauges every second until ctx is done and
+// logs a warning when saturation reaches warnAt, and again once it has
+// fallen back below. A zero warnAt disables the warning.
+func (db *Database) MonitorPool(ctx context.Context, warnAt float64) {
+	ticker := time.NewTicker(poolSampleInterval)
+	defer ticker.Stop()
+	saturated := false
+	var since time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		saturation := db.Saturation()
+		switch {
+		case warnAt > 0 && saturation >= warnAt && !saturated:
+			saturated, since = true, time.Now()
+			st := db.Pool.Stat()
+			log.Printf("db pool saturated: %d of %d connections in use (threshold %.0f%%), %d acquires waited so far",
+				st.AcquiredConns(), st.MaxConns(), warnAt*100, st.EmptyAcquireCount())
+		case saturated && saturation < warnAt:
+			saturated = false
+			log.Printf("db pool recovered after %s: %.0f%% of connections in use", time.Since(since).Round(time.Second), saturation*100)
+		}
+	}
+}
@@ -2,24 +2,122 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Database wraps the pgx connection pool.
 type Database struct {
-	Pool *pgxpool.Pool
+	Pool   *pgxpool.Pool
+	tracer *tracer
+	retry  RetryPolicy
+}
+
+// PoolOptions tunes the connection pool. Zero values keep the pgx defaults
+// (or the values given as DSN parameters).
+type PoolOptions struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// AcquireTimeout bounds how long a query waits for a free connection
+	// before failing, independently of the caller's deadline.
+	AcquireTimeout time.Duration
+	// StatementCacheMode is one of cache_statement, cache_describe,
+	// describe_exec, exec or simple_protocol.
+	StatementCacheMode string
+	// SlowQueryThreshold logs statements that take at least this long;
+	// zero disables slow-query logging.
+	SlowQueryThreshold time.Duration
+	// LogQueries logs every statement, for debugging.
+	LogQueries bool
+	// Retry applies to repositories built on Database.Retrying.
+	Retry RetryPolicy
+	// ApplicationName identifies the pool's connections in pg_stat_activity
+	// unless the DSN sets application_name itself.
+	ApplicationName string
+	// TagRequests appends the request and user ID of the acquiring context
+	// to application_name.
+	TagRequests bool
+}
+
+var execModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
 }
 
 // New establishes a new connection pool against the provided DSN.
-func New(ctx context.Context, dsn string) (*Database, error) {
+func New(ctx context.Context, dsn string, opts PoolOptions) (*Database, error) {
 	cfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, err
 	}
 	cfg.MaxConnLifetime = time.Hour
 	cfg.MaxConnIdleTime = 30 * time.Minute
+	if opts.MaxConns > 0 {
+		cfg.MaxConns = opts.MaxConns
+	}
+	if opts.MinConns > 0 {
+		cfg.MinConns = opts.MinConns
+	}
+	if opts.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+	if opts.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = opts.HealthCheckPeriod
+	}
+	if opts.StatementCacheMode != "" {
+		mode, ok := execModes[opts.StatementCacheMode]
+		if !ok {
+			return nil, fmt.Errorf("unknown statement cache mode %q", opts.StatementCacheMode)
+		}
+		cfg.ConnConfig.DefaultQueryExecMode = mode
+	}
+	if opts.ApplicationName != "" && cfg.ConnConfig.RuntimeParams["application_name"] == "" {
+		cfg.ConnConfig.RuntimeParams["application_name"] = opts.ApplicationName
+	}
+	// Sessions run in UTC so date functions agree with the API's UTC days,
+	// and timestamptz values scan as UTC whatever the host's zone.
+	cfg.ConnConfig.RuntimeParams["timezone"] = "UTC"
+	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		conn.TypeMap().RegisterType(&pgtype.Type{
+			Name:  "timestamptz",
+			OID:   pgtype.TimestamptzOID,
+			Codec: &pgtype.TimestamptzCodec{ScanLocation: time.UTC},
+		})
+		return prepareHotStatements(ctx, conn)
+	}
+	if opts.TagRequests {
+		cfg.PrepareConn = tagSession(cfg.ConnConfig.RuntimeParams["application_name"])
+	}
+	t := &tracer{acquireTimeout: opts.AcquireTimeout}
+	t.slowThreshold.Store(int64(opts.SlowQueryThreshold))
+	t.logAll.Store(opts.LogQueries)
+	cfg.ConnConfig.Tracer = t
+	// Ask the server to cancel the running statement when a request context is
+	// cancelled instead of only dropping the socket, which would leave the query
+	// executing until it next tries to write.
+	cfg.ConnConfig.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{
+			Conn:               conn,
+			CancelRequestDelay: 0,
+			DeadlineDelay:      2 * time.Second,
+		}
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
@@ -30,7 +128,7 @@ func New(ctx context.Context, dsn string) (*Database, error) {
 		return nil, err
 	}
 
-	return &Database{Pool: pool}, nil
+	return &Database{Pool: pool, tracer: t, retry: opts.Retry}, nil
 }
 
 // Close drains the connection pool.
@@ -39,3 +137,30 @@ func (db *Database) Close() {
 		db.Pool.Close()
 	}
 }
+
+// SetQueryLogging changes the slow-query threshold and whether every
+// statement is logged, without reconnecting.
+func (db *Database) SetQueryLogging(slowThreshold time.Duration, logAll bool) {
+	db.tracer.slowThreshold.Store(int64(slowThreshold))
+	db.tracer.logAll.Store(logAll)
+}
+
+// LogPoolStats writes pool statistics every interval until ctx is done.
+func (db *Database) LogPoolStats(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			st := db.Pool.Stat()
+			log.Printf("db pool: total=%d idle=%d acquired=%d constructing=%d max=%d acquires=%d empty_acquires=%d canceled_acquires=%d acquire_wait=%s",
+				st.TotalConns(), st.IdleConns(), st.AcquiredConns(), st.ConstructingConns(), st.MaxConns(),
+				st.AcquireCount(), st.EmptyAcquireCount(), st.CanceledAcquireCount(), st.AcquireDuration())
+		}
+	}
+}
@@ -3,42 +3,62 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	domain "backoffice/backend/internal/domain/product"
+	"backoffice/backend/internal/domain/trash"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // ProductRepository persists products in PostgreSQL.
 type ProductRepository struct {
-	pool *pgxpool.Pool
+	pool Querier
 }
 
-// NewProductRepository constructs a repository.
-func NewProductRepository(pool *pgxpool.Pool) *ProductRepository {
+// NewProductRepository constructs a repository on top of a pool, transaction or
+// Database.Retrying.
+func NewProductRepository(pool Querier) *ProductRepository {
 	return &ProductRepository{pool: pool}
 }
 
+var (
+	_ domain.Repository            = (*ProductRepository)(nil)
+	_ domain.ReservationRepository = (*ProductRepository)(nil)
+)
+
+// productColumns selects a product followed by the stock held by its active
+// reservations.
+const productColumns = `id, name, description, sku, barcode, price, cost_price, unit, pack_size, quantity, category_id, created_at, updated_at,
+    coalesce((SELECT sum(r.quantity) FROM product_reservations r WHERE r.product_id = products.id AND r.expires_at > now()), 0)`
+
 // Create inserts a new product.
 func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
 	const query = `
-INSERT INTO products (id, name, description, sku, price, quantity, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO products (id, name, description, sku, barcode, price, cost_price, unit, pack_size, quantity, category_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 `
 	_, err := r.pool.Exec(ctx, query,
 		product.ID,
 		product.Name,
 		product.Description,
 		product.SKU,
+		nullableString(product.Barcode),
 		product.Price,
+		product.CostPrice,
+		product.Unit,
+		product.PackSize,
 		product.Quantity,
+		nullableString(product.CategoryID),
 		product.CreatedAt,
 		product.UpdatedAt,
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
-			return domain.ErrDuplicateSKU
+			return duplicateProduct(err)
+		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrUnknownCategory
 		}
 		return err
 	}
@@ -48,8 +68,8 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 // GetByID fetches a product by id.
 func (r *ProductRepository) GetByID(ctx context.Context, id string) (*domain.Product, error) {
 	const query = `
-SELECT id, name, description, sku, price, quantity, created_at, updated_at
-FROM products WHERE id = $1
+SELECT ` + productColumns + `
+FROM products WHERE id = $1 AND deleted_at IS NULL
 `
 	row := r.pool.QueryRow(ctx, query, id)
 	product, err := scanProduct(row)
@@ -62,11 +82,38 @@ FROM products WHERE id = $1
 	return product, nil
 }
 
+// GetByIDs fetches the live products among ids, in the order of ids.
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Product, error) {
+	const query = `
+SELECT ` + productColumns + `
+FROM products WHERE id = ANY($1) AND deleted_at IS NULL
+ORDER BY array_position($1, id)
+`
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*domain.Product
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
 // GetBySKU fetches a product using its SKU.
 func (r *ProductRepository) GetBySKU(ctx context.Context, sku string) (*domain.Product, error) {
 	const query = `
-SELECT id, name, description, sku, price, quantity, created_at, updated_at
-FROM products WHERE sku = $1
+SELECT ` + productColumns + `
+FROM products WHERE sku = $1 AND deleted_at IS NULL
 `
 	row := r.pool.QueryRow(ctx, query, sku)
 	product, err := scanProduct(row)
@@ -79,14 +126,26 @@ FROM products WHERE sku = $1
 	return product, nil
 }
 
-// List returns all products sorted by name.
-func (r *ProductRepository) List(ctx context.Context) ([]*domain.Product, error) {
+// GetByBarcode fetches a product using its barcode.
+func (r *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (*domain.Product, error) {
 	const query = `
-SELECT id, name, description, sku, price, quantity, created_at, updated_at
-FROM products
-ORDER BY name ASC
+SELECT ` + productColumns + `
+FROM products WHERE barcode = $1 AND deleted_at IS NULL
 `
-	rows, err := r.pool.Query(ctx, query)
+	row := r.pool.QueryRow(ctx, query, barcode)
+	product, err := scanProduct(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+// List returns all products sorted by name.
+func (r *ProductRepository) List(ctx context.Context) ([]*domain.Product, error) {
+	rows, err := r.pool.Query(ctx, productListQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -103,30 +162,265 @@ ORDER BY name ASC
 	return products, rows.Err()
 }
 
-// Update writes product updates to the database.
-func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+// Update writes product updates to the database and then, in the same
+// transaction, moves the quantity by delta relative to the stored value, so
+// a stock change is never committed without the rest of the update. A
+// reduction is checked against the reserved stock as AdjustQuantity does.
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product, delta int) error {
 	const query = `
 UPDATE products
 SET name = $2,
     description = $3,
     sku = $4,
-    price = $5,
-    quantity = $6,
-    updated_at = $7
+    barcode = $5,
+    price = $6,
+    cost_price = $7,
+    unit = $8,
+    pack_size = $9,
+    category_id = $10,
+    updated_at = $11
+WHERE id = $1 AND deleted_at IS NULL
+`
+	const adjustQuery = `
+UPDATE products
+SET quantity = quantity + $2
+WHERE id = $1
+RETURNING ` + productColumns
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query,
+			product.ID,
+			product.Name,
+			product.Description,
+			product.SKU,
+			nullableString(product.Barcode),
+			product.Price,
+			product.CostPrice,
+			product.Unit,
+			product.PackSize,
+			nullableString(product.CategoryID),
+			product.UpdatedAt,
+		)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return duplicateProduct(err)
+			}
+			if isForeignKeyViolation(err) {
+				return domain.ErrUnknownCategory
+			}
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrNotFound
+		}
+		if delta == 0 {
+			return nil
+		}
+		quantity, reserved, err := lockStock(ctx, tx, product.ID, product.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if !domain.KeepsReserved(quantity, reserved, delta) {
+			return domain.ErrInsufficientStock
+		}
+		adjusted, err := scanProduct(tx.QueryRow(ctx, adjustQuery, product.ID, delta))
+		if err != nil {
+			return err
+		}
+		product.Quantity = adjusted.Quantity
+		product.SetReserved(adjusted.Reserved)
+		return nil
+	})
+}
+
+// AdjustQuantity adds delta to the product's quantity with the row locked,
+// so concurrent adjustments and reservations cannot interleave with it, and
+// refuses with ErrInsufficientStock when a reduction would leave less than
+// the reserved stock.
+func (r *ProductRepository) AdjustQuantity(ctx context.Context, id string, delta int, at time.Time) (*domain.Product, error) {
+	const query = `
+UPDATE products
+SET quantity = quantity + $2, updated_at = $3
 WHERE id = $1
+RETURNING ` + productColumns
+	var product *domain.Product
+	err := inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		quantity, reserved, err := lockStock(ctx, tx, id, at)
+		if err != nil {
+			return err
+		}
+		if !domain.KeepsReserved(quantity, reserved, delta) {
+			return domain.ErrInsufficientStock
+		}
+		product, err = scanProduct(tx.QueryRow(ctx, query, id, delta, at))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// SetQuantity sets the product's quantity to an absolute level, locking the
+// row while it reads the quantity being replaced and the reserved stock, so
+// a concurrent adjustment or reservation lands either wholly before or
+// wholly after it.
+func (r *ProductRepository) SetQuantity(ctx context.Context, id string, quantity int, at time.Time) (*domain.Product, int, error) {
+	const selectQuery = `SELECT ` + productColumns + ` FROM products WHERE id = $1`
+	const setQuery = `
+UPDATE products
+SET quantity = $2, updated_at = $3
+WHERE id = $1
+RETURNING ` + productColumns
+	var product *domain.Product
+	var previous int
+	err := inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		var reserved int
+		var err error
+		previous, reserved, err = lockStock(ctx, tx, id, at)
+		if err != nil {
+			return err
+		}
+		if previous == quantity {
+			product, err = scanProduct(tx.QueryRow(ctx, selectQuery, id))
+			return err
+		}
+		if !domain.KeepsReserved(previous, reserved, quantity-previous) {
+			return domain.ErrInsufficientStock
+		}
+		product, err = scanProduct(tx.QueryRow(ctx, setQuery, id, quantity, at))
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return product, previous, nil
+}
+
+// Delete moves a product to the trash.
+func (r *ProductRepository) Delete(ctx context.Context, id string) error {
+	const query = `UPDATE products SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteMany moves all of ids to the trash in one statement, rolled back
+// unless every one of them was live.
+func (r *ProductRepository) DeleteMany(ctx context.Context, ids []string) error {
+	const query = `UPDATE products SET deleted_at = now() WHERE id = ANY($1) AND deleted_at IS NULL`
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, ids)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() != int64(len(ids)) {
+			return domain.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// CountLowStock counts live products with at most quantity in stock.
+func (r *ProductRepository) CountLowStock(ctx context.Context, quantity int) (int, error) {
+	const query = `
+SELECT COUNT(*)
+FROM products
+WHERE deleted_at IS NULL AND quantity <= $1
 `
-	tag, err := r.pool.Exec(ctx, query,
-		product.ID,
-		product.Name,
-		product.Description,
-		product.SKU,
-		product.Price,
-		product.Quantity,
-		product.UpdatedAt,
+	var count int
+	err := r.pool.QueryRow(ctx, query, quantity).Scan(&count)
+	return count, err
+}
+
+// StockValuation values stock per category, from the products table or, for a
+// past instant, from the latest ledger entry per product at that time.
+func (r *ProductRepository) StockValuation(ctx context.Context, asOf *time.Time) ([]domain.ValuationLine, error) {
+	const currentQuery = `
+SELECT coalesce(c.id, ''), coalesce(c.name, ''), count(*), coalesce(sum(p.quantity), 0), coalesce(sum(p.quantity * p.price), 0)
+FROM products p
+LEFT JOIN categories c ON c.id = p.category_id
+WHERE p.deleted_at IS NULL
+GROUP BY c.id, c.name
+ORDER BY 5 DESC, 2
+`
+	const asOfQuery = `
+WITH latest AS (
+    SELECT DISTINCT ON (product_id) product_id, category_id, quantity_after, unit_price
+    FROM stock_movements
+    WHERE occurred_at <= $1
+    ORDER BY product_id, occurred_at DESC, id DESC
+)
+SELECT coalesce(l.category_id, ''), coalesce(c.name, ''), count(*), coalesce(sum(l.quantity_after), 0), coalesce(sum(l.quantity_after * l.unit_price), 0)
+FROM latest l
+LEFT JOIN categories c ON c.id = l.category_id
+WHERE l.quantity_after <> 0
+GROUP BY l.category_id, c.name
+ORDER BY 5 DESC, 2
+`
+	var (
+		rows pgx.Rows
+		err  error
 	)
+	if asOf == nil {
+		rows, err = r.pool.Query(ctx, currentQuery)
+	} else {
+		rows, err = r.pool.Query(ctx, asOfQuery, *asOf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []domain.ValuationLine
+	for rows.Next() {
+		var line domain.ValuationLine
+		if err := rows.Scan(&line.CategoryID, &line.CategoryName, &line.Products, &line.Quantity, &line.Value); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// ListDeleted returns trashed products, most recently deleted first.
+func (r *ProductRepository) ListDeleted(ctx context.Context) ([]trash.Item, error) {
+	const query = `
+SELECT id, name, deleted_at
+FROM products
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []trash.Item
+	for rows.Next() {
+		item := trash.Item{Kind: trash.KindProduct}
+		if err := rows.Scan(&item.ID, &item.Name, &item.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Restore takes a product out of the trash. It fails with ErrDuplicateSKU or
+// ErrDuplicateBarcode when the SKU or barcode has been reused in the
+// meantime.
+func (r *ProductRepository) Restore(ctx context.Context, id string) error {
+	const query = `UPDATE products SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+	tag, err := r.pool.Exec(ctx, query, id)
 	if err != nil {
 		if isUniqueViolation(err) {
-			return domain.ErrDuplicateSKU
+			return duplicateProduct(err)
 		}
 		return err
 	}
@@ -136,9 +430,9 @@ WHERE id = $1
 	return nil
 }
 
-// Delete removes a product by id.
-func (r *ProductRepository) Delete(ctx context.Context, id string) error {
-	const query = `DELETE FROM products WHERE id = $1`
+// Purge permanently deletes a trashed product.
+func (r *ProductRepository) Purge(ctx context.Context, id string) error {
+	const query = `DELETE FROM products WHERE id = $1 AND deleted_at IS NOT NULL`
 	tag, err := r.pool.Exec(ctx, query, id)
 	if err != nil {
 		return err
@@ -149,20 +443,95 @@ func (r *ProductRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpdatePrices applies every change in one transaction. A product that was
+// deleted or repriced since its change was computed rolls the whole update
+// back.
+func (r *ProductRepository) UpdatePrices(ctx context.Context, changes []domain.PriceChange, at time.Time) error {
+	const query = `
+UPDATE products
+SET price = $2, updated_at = $4
+WHERE id = $1 AND price = $3 AND deleted_at IS NULL
+`
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, change := range changes {
+			tag, err := tx.Exec(ctx, query, change.ProductID, change.NewPrice, change.OldPrice, at)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return domain.ErrPriceChanged
+			}
+		}
+		return nil
+	})
+}
+
+// CountDeletedBefore counts products trashed before cutoff.
+func (r *ProductRepository) CountDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `SELECT COUNT(*) FROM products WHERE deleted_at < $1`
+	var count int
+	if err := r.pool.QueryRow(ctx, query, cutoff).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// PurgeDeletedBefore permanently deletes products trashed before cutoff.
+func (r *ProductRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `DELETE FROM products WHERE deleted_at < $1`
+	tag, err := r.pool.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
 func scanProduct(row pgx.Row) (*domain.Product, error) {
 	var p domain.Product
+	var categoryID, barcode *string
+	var reserved int
 	err := row.Scan(
 		&p.ID,
 		&p.Name,
 		&p.Description,
 		&p.SKU,
+		&barcode,
 		&p.Price,
+		&p.CostPrice,
+		&p.Unit,
+		&p.PackSize,
 		&p.Quantity,
+		&categoryID,
 		&p.CreatedAt,
 		&p.UpdatedAt,
+		&reserved,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if categoryID != nil {
+		p.CategoryID = *categoryID
+	}
+	if barcode != nil {
+		p.Barcode = *barcode
+	}
+	p.SetReserved(reserved)
 	return &p, nil
 }
+
+// duplicateProduct maps a unique violation on products to the field that
+// clashed.
+func duplicateProduct(err error) error {
+	if violatedConstraint(err) == "products_barcode_live_idx" {
+		return domain.ErrDuplicateBarcode
+	}
+	return domain.ErrDuplicateSKU
+}
+
+// nullableString stores empty optional references as NULL.
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/product"
+
+	"github.com/google/uuid"
+)
+
+// TestProductQuantityKeepsReserved checks that no quantity write takes stock
+// below what active reservations hold. It runs in a transaction that is
+// rolled back.
+func TestProductQuantityKeepsReserved(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	repo := NewProductRepository(tx)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	product := &domain.Product{
+		ID:        uuid.NewString(),
+		Name:      "Rice",
+		SKU:       "RICE-" + uuid.NewString(),
+		Price:     2.5,
+		Unit:      domain.UnitPiece,
+		PackSize:  1,
+		Quantity:  10,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(ctx, product); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	reservation := &domain.Reservation{
+		ID:        uuid.NewString(),
+		ProductID: product.ID,
+		Quantity:  6,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+	if err := repo.Reserve(ctx, reservation, now); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	if _, _, err := repo.SetQuantity(ctx, product.ID, 5, now); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Errorf("SetQuantity below reserved: err = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+	if _, err := repo.AdjustQuantity(ctx, product.ID, -5, now); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Errorf("AdjustQuantity below reserved: err = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+	edited := *product
+	edited.Price = 3
+	if err := repo.Update(ctx, &edited, -5); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Errorf("Update below reserved: err = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+	stored, err := repo.GetByID(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Quantity != 10 || stored.Price != 2.5 || stored.Reserved != 6 {
+		t.Fatalf("stored = quantity %d price %v reserved %d, want the refused writes to change nothing", stored.Quantity, stored.Price, stored.Reserved)
+	}
+
+	// Down to exactly the reserved stock is allowed.
+	if _, previous, err := repo.SetQuantity(ctx, product.ID, 6, now); err != nil || previous != 10 {
+		t.Fatalf("SetQuantity to reserved = %d, %v; want 10, nil", previous, err)
+	}
+	if _, err := repo.AdjustQuantity(ctx, product.ID, 2, now); err != nil {
+		t.Fatalf("AdjustQuantity up: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "backoffice/backend/internal/domain/promotion"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PromotionRepository persists promotions in PostgreSQL.
+type PromotionRepository struct {
+	pool Querier
+}
+
+// NewPromotionRepository constructs a repository.
+func NewPromotionRepository(pool Querier) *PromotionRepository {
+	return &PromotionRepository{pool: pool}
+}
+
+var _ domain.Repository = (*PromotionRepository)(nil)
+
+const promotionColumns = `id, code, description, kind, value, min_subtotal, starts_at, ends_at, usage_limit, used, product_ids, category_ids, active, created_by, created_at, updated_at`
+
+// Create inserts a promotion.
+func (r *PromotionRepository) Create(ctx context.Context, p *domain.Promotion) error {
+	const query = `
+INSERT INTO promotions (` + promotionColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+`
+	_, err := r.pool.Exec(ctx, query,
+		p.ID,
+		p.Code,
+		p.Description,
+		p.Kind,
+		p.Value,
+		p.MinSubtotal,
+		p.StartsAt,
+		p.EndsAt,
+		p.UsageLimit,
+		p.Used,
+		p.ProductIDs,
+		p.CategoryIDs,
+		p.Active,
+		p.CreatedBy,
+		p.CreatedAt,
+		p.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateCode
+	}
+	return err
+}
+
+// GetByID fetches a promotion by id.
+func (r *PromotionRepository) GetByID(ctx context.Context, id string) (*domain.Promotion, error) {
+	const query = `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1`
+	return r.get(ctx, query, id)
+}
+
+// GetByCode fetches a promotion by its code.
+func (r *PromotionRepository) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	const query = `SELECT ` + promotionColumns + ` FROM promotions WHERE code = $1`
+	return r.get(ctx, query, code)
+}
+
+func (r *PromotionRepository) get(ctx context.Context, query string, args ...any) (*domain.Promotion, error) {
+	p, err := scanPromotion(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// List returns every promotion ordered by code.
+func (r *PromotionRepository) List(ctx context.Context) ([]*domain.Promotion, error) {
+	const query = `SELECT ` + promotionColumns + ` FROM promotions ORDER BY code ASC`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []*domain.Promotion
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+	return promotions, rows.Err()
+}
+
+// Update writes every field but the usage count.
+func (r *PromotionRepository) Update(ctx context.Context, p *domain.Promotion) error {
+	const query = `
+UPDATE promotions
+SET code = $2,
+    description = $3,
+    kind = $4,
+    value = $5,
+    min_subtotal = $6,
+    starts_at = $7,
+    ends_at = $8,
+    usage_limit = $9,
+    product_ids = $10,
+    category_ids = $11,
+    active = $12,
+    updated_at = $13
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query,
+		p.ID,
+		p.Code,
+		p.Description,
+		p.Kind,
+		p.Value,
+		p.MinSubtotal,
+		p.StartsAt,
+		p.EndsAt,
+		p.UsageLimit,
+		p.ProductIDs,
+		p.CategoryIDs,
+		p.Active,
+		p.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateCode
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a promotion.
+func (r *PromotionRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM promotions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Redeem takes one use of the promotion in a single statement, so
+// concurrent orders cannot exceed its limit.
+func (r *PromotionRepository) Redeem(ctx context.Context, id string, at time.Time) error {
+	const query = `
+UPDATE promotions
+SET used = used + 1, updated_at = $2
+WHERE id = $1 AND (usage_limit = 0 OR used < usage_limit)
+`
+	tag, err := r.pool.Exec(ctx, query, id, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrExhausted
+	}
+	return nil
+}
+
+// Release gives back a use taken by Redeem.
+func (r *PromotionRepository) Release(ctx context.Context, id string, at time.Time) error {
+	const query = `UPDATE promotions SET used = GREATEST(used - 1, 0), updated_at = $2 WHERE id = $1`
+	tag, err := r.pool.Exec(ctx, query, id, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanPromotion(row pgx.Row) (*domain.Promotion, error) {
+	var p domain.Promotion
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.Description,
+		&p.Kind,
+		&p.Value,
+		&p.MinSubtotal,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.UsageLimit,
+		&p.Used,
+		&p.ProductIDs,
+		&p.CategoryIDs,
+		&p.Active,
+		&p.CreatedBy,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
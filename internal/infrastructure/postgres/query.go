@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"strconv"
+	"strings"
+)
+
+// selectBuilder assembles a SELECT whose WHERE clause depends on the caller's
+// filters. Conditions are written with ? for their values; every ? becomes
+// the next $n placeholder as the condition is added, so the arguments line up
+// with the placeholders however many conditions apply. Conditions are joined
+// with AND, so one that contains OR must bring its own parentheses.
+type selectBuilder struct {
+	base       string
+	conditions []string
+	orderBy    string
+	limit      *int
+	args       []any
+}
+
+// newSelect starts a query from base, the SELECT ... FROM part.
+func newSelect(base string) *selectBuilder {
+	return &selectBuilder{base: strings.TrimSpace(base)}
+}
+
+// Where adds cond, binding args to its ? placeholders in order. A mismatch
+// between the two is a programming error and panics.
+func (b *selectBuilder) Where(cond string, args ...any) *selectBuilder {
+	if n := strings.Count(cond, "?"); n != len(args) {
+		panic("postgres: condition " + strconv.Quote(cond) + " has " + strconv.Itoa(n) + " placeholders for " + strconv.Itoa(len(args)) + " arguments")
+	}
+	var sb strings.Builder
+	for _, r := range cond {
+		if r != '?' {
+			sb.WriteRune(r)
+			continue
+		}
+		b.args = append(b.args, args[0])
+		args = args[1:]
+		sb.WriteString("$" + strconv.Itoa(len(b.args)))
+	}
+	b.conditions = append(b.conditions, sb.String())
+	return b
+}
+
+// OrderBy sets the ORDER BY expression.
+func (b *selectBuilder) OrderBy(expr string) *selectBuilder {
+	b.orderBy = expr
+	return b
+}
+
+// Limit caps the number of rows. The limit is bound after every condition,
+// whenever it is set.
+func (b *selectBuilder) Limit(n int) *selectBuilder {
+	b.limit = &n
+	return b
+}
+
+// Build returns the statement and its arguments.
+func (b *selectBuilder) Build() (string, []any) {
+	args := append([]any(nil), b.args...)
+	query := b.base
+	if len(b.conditions) > 0 {
+		query += "\nWHERE " + strings.Join(b.conditions, " AND ")
+	}
+	if b.orderBy != "" {
+		query += "\nORDER BY " + b.orderBy
+	}
+	if b.limit != nil {
+		args = append(args, *b.limit)
+		query += "\nLIMIT $" + strconv.Itoa(len(args))
+	}
+	return query, args
+}
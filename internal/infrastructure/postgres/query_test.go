@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectBuilder(t *testing.T) {
+	tests := []struct {
+		name      string
+		build     func() *selectBuilder
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "no conditions",
+			build:     func() *selectBuilder { return newSelect("SELECT id FROM t") },
+			wantQuery: "SELECT id FROM t",
+		},
+		{
+			name: "conditions without values",
+			build: func() *selectBuilder {
+				return newSelect("SELECT id FROM t").Where("deleted_at IS NULL").OrderBy("id")
+			},
+			wantQuery: "SELECT id FROM t\nWHERE deleted_at IS NULL\nORDER BY id",
+		},
+		{
+			name: "placeholders numbered in order added",
+			build: func() *selectBuilder {
+				return newSelect("\nSELECT id FROM t\n").
+					Where("a = ?", "x").
+					Where("deleted_at IS NULL").
+					Where("(b, c) < (?, ?)", 2, "z")
+			},
+			wantQuery: "SELECT id FROM t\nWHERE a = $1 AND deleted_at IS NULL AND (b, c) < ($2, $3)",
+			wantArgs:  []any{"x", 2, "z"},
+		},
+		{
+			name: "limit bound last even when set first",
+			build: func() *selectBuilder {
+				return newSelect("SELECT id FROM t").Limit(10).Where("a = ?", "x").OrderBy("id DESC")
+			},
+			wantQuery: "SELECT id FROM t\nWHERE a = $1\nORDER BY id DESC\nLIMIT $2",
+			wantArgs:  []any{"x", 10},
+		},
+		{
+			name:      "limit alone",
+			build:     func() *selectBuilder { return newSelect("SELECT id FROM t").Limit(5) },
+			wantQuery: "SELECT id FROM t\nLIMIT $1",
+			wantArgs:  []any{5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.build().Build()
+			if query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSelectBuilderBuildIsRepeatable(t *testing.T) {
+	b := newSelect("SELECT id FROM t").Where("a = ?", 1).Limit(3)
+	q1, a1 := b.Build()
+	q2, a2 := b.Build()
+	if q1 != q2 || !reflect.DeepEqual(a1, a2) {
+		t.Fatalf("second Build = %q %v, first = %q %v", q2, a2, q1, a1)
+	}
+}
+
+func TestSelectBuilderPanicsOnArgumentMismatch(t *testing.T) {
+	for _, tc := range []struct {
+		cond string
+		args []any
+	}{
+		{"a = ?", nil},
+		{"a = ? AND b = ?", []any{1}},
+		{"a = 1", []any{1}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Where(%q, %v) did not panic", tc.cond, tc.args)
+				}
+			}()
+			newSelect("SELECT id FROM t").Where(tc.cond, tc.args...)
+		}()
+	}
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "backoffice/backend/internal/domain/quota"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QuotaRepository counts API client requests per month in PostgreSQL.
+type QuotaRepository struct {
+	pool Querier
+}
+
+// NewQuotaRepository constructs a repository.
+func NewQuotaRepository(pool Querier) *QuotaRepository {
+	return &QuotaRepository{pool: pool}
+}
+
+var _ domain.Repository = (*QuotaRepository)(nil)
+
+// Add increments the client's count for month in one statement, so
+// instances flushing at once do not lose each other's requests.
+func (r *QuotaRepository) Add(ctx context.Context, clientID string, month time.Time, requests int64) (int64, error) {
+	const query = `
+INSERT INTO client_usage (client_id, month, requests, updated_at)
+VALUES ($1, $2, $3, now())
+ON CONFLICT (client_id, month) DO UPDATE SET
+    requests = client_usage.requests + EXCLUDED.requests,
+    updated_at = EXCLUDED.updated_at
+RETURNING requests
+`
+	var total int64
+	err := r.pool.QueryRow(ctx, query, clientID, month, requests).Scan(&total)
+	return total, err
+}
+
+// Get returns the client's count for month.
+func (r *QuotaRepository) Get(ctx context.Context, clientID string, month time.Time) (int64, error) {
+	const query = `SELECT requests FROM client_usage WHERE client_id = $1 AND month = $2`
+	var total int64
+	err := r.pool.QueryRow(ctx, query, clientID, month).Scan(&total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return total, err
+}
@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "backoffice/backend/internal/domain/product"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const reservationColumns = `id, product_id, quantity, reference, created_by, created_at, expires_at`
+
+// Reserve stores res when the product has enough unreserved stock. The
+// product row is locked first, so concurrent reservations of one product are
+// serialised, and the reserved total is summed by a separate statement,
+// which under READ COMMITTED sees every reservation committed while this
+// transaction waited for the lock.
+func (r *ProductRepository) Reserve(ctx context.Context, res *domain.Reservation, now time.Time) error {
+	const insertQuery = `
+INSERT INTO product_reservations (` + reservationColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		quantity, reserved, err := lockStock(ctx, tx, res.ProductID, now)
+		if err != nil {
+			return err
+		}
+		if quantity-reserved < res.Quantity {
+			return domain.ErrInsufficientStock
+		}
+		_, err = tx.Exec(ctx, insertQuery,
+			res.ID,
+			res.ProductID,
+			res.Quantity,
+			res.Reference,
+			res.CreatedBy,
+			res.CreatedAt,
+			res.ExpiresAt,
+		)
+		return err
+	})
+}
+
+// lockStock locks a live product's row and returns its quantity along with
+// the stock held by its active reservations at now. The sum runs after the
+// lock is granted, so it sees every reservation committed while waiting.
+func lockStock(ctx context.Context, tx pgx.Tx, productID string, now time.Time) (quantity, reserved int, err error) {
+	const lockQuery = `SELECT quantity FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	const reservedQuery = `
+SELECT coalesce(sum(quantity), 0)
+FROM product_reservations
+WHERE product_id = $1 AND expires_at > $2
+`
+	if err := tx.QueryRow(ctx, lockQuery, productID).Scan(&quantity); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, domain.ErrNotFound
+		}
+		return 0, 0, err
+	}
+	if err := tx.QueryRow(ctx, reservedQuery, productID, now).Scan(&reserved); err != nil {
+		return 0, 0, err
+	}
+	return quantity, reserved, nil
+}
+
+// ListReservations returns the product's active reservations, oldest first.
+func (r *ProductRepository) ListReservations(ctx context.Context, productID string, now time.Time) ([]*domain.Reservation, error) {
+	const query = `
+SELECT ` + reservationColumns + `
+FROM product_reservations
+WHERE product_id = $1 AND expires_at > $2
+ORDER BY created_at, id
+`
+	rows, err := r.pool.Query(ctx, query, productID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []*domain.Reservation
+	for rows.Next() {
+		var res domain.Reservation
+		if err := rows.Scan(&res.ID, &res.ProductID, &res.Quantity, &res.Reference, &res.CreatedBy, &res.CreatedAt, &res.ExpiresAt); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, &res)
+	}
+	return reservations, rows.Err()
+}
+
+// ListReservationsFor returns the active reservations of productIDs, by
+// product and then oldest first.
+func (r *ProductRepository) ListReservationsFor(ctx context.Context, productIDs []string, now time.Time) ([]*domain.Reservation, error) {
+	const query = `
+SELECT ` + reservationColumns + `
+FROM product_reservations
+WHERE product_id = ANY($1) AND expires_at > $2
+ORDER BY product_id, created_at, id
+`
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, query, productIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []*domain.Reservation
+	for rows.Next() {
+		var res domain.Reservation
+		if err := rows.Scan(&res.ID, &res.ProductID, &res.Quantity, &res.Reference, &res.CreatedBy, &res.CreatedAt, &res.ExpiresAt); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, &res)
+	}
+	return reservations, rows.Err()
+}
+
+// Release deletes an active reservation.
+func (r *ProductRepository) Release(ctx context.Context, productID, id string, now time.Time) error {
+	const query = `DELETE FROM product_reservations WHERE id = $1 AND product_id = $2 AND expires_at > $3`
+	tag, err := r.pool.Exec(ctx, query, id, productID, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReservationNotFound
+	}
+	return nil
+}
+
+// Confirm turns an active reservation into a stock deduction. It fails with
+// ErrInsufficientStock, keeping the reservation, when the stock on hand was
+// lowered below the reserved quantity in the meantime.
+func (r *ProductRepository) Confirm(ctx context.Context, productID, id string, now time.Time) (*domain.Product, error) {
+	const lockQuery = `SELECT quantity FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	const deleteQuery = `
+DELETE FROM product_reservations
+WHERE id = $1 AND product_id = $2 AND expires_at > $3
+RETURNING quantity
+`
+	const updateQuery = `UPDATE products SET quantity = quantity - $2, updated_at = $3 WHERE id = $1`
+	const selectQuery = `SELECT ` + productColumns + ` FROM products WHERE id = $1`
+
+	var product *domain.Product
+	err := inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		var onHand int
+		if err := tx.QueryRow(ctx, lockQuery, productID).Scan(&onHand); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		var quantity int
+		if err := tx.QueryRow(ctx, deleteQuery, id, productID, now).Scan(&quantity); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrReservationNotFound
+			}
+			return err
+		}
+		if onHand < quantity {
+			return domain.ErrInsufficientStock
+		}
+		if _, err := tx.Exec(ctx, updateQuery, productID, quantity, now); err != nil {
+			return err
+		}
+		var err error
+		product, err = scanProduct(tx.QueryRow(ctx, selectQuery, productID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// PurgeExpiredReservations deletes reservations that expired before cutoff.
+func (r *ProductRepository) PurgeExpiredReservations(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `DELETE FROM product_reservations WHERE expires_at < $1`
+	tag, err := r.pool.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+
+	domain "backoffice/backend/internal/domain/retention"
+)
+
+// RetentionRepository persists retention policies in PostgreSQL.
+type RetentionRepository struct {
+	pool Querier
+}
+
+// NewRetentionRepository constructs a repository.
+func NewRetentionRepository(pool Querier) *RetentionRepository {
+	return &RetentionRepository{pool: pool}
+}
+
+var _ domain.Repository = (*RetentionRepository)(nil)
+
+// ListPolicies returns every saved policy.
+func (r *RetentionRepository) ListPolicies(ctx context.Context) ([]domain.Policy, error) {
+	const query = `SELECT target, days, updated_at, updated_by FROM retention_policies ORDER BY target ASC`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []domain.Policy
+	for rows.Next() {
+		var p domain.Policy
+		if err := rows.Scan(&p.Target, &p.Days, &p.UpdatedAt, &p.UpdatedBy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// SavePolicy inserts or replaces the policy for its target.
+func (r *RetentionRepository) SavePolicy(ctx context.Context, policy domain.Policy) error {
+	const query = `
+INSERT INTO retention_policies (target, days, updated_at, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (target) DO UPDATE
+SET days = EXCLUDED.days, updated_at = EXCLUDED.updated_at, updated_by = EXCLUDED.updated_by
+`
+	_, err := r.pool.Exec(ctx, query, policy.Target, policy.Days, policy.UpdatedAt, policy.UpdatedBy)
+	return err
+}
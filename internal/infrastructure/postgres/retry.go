@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"backoffice/backend/internal/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var dbRetries = metrics.Default.NewCounterVec("db_retries_total",
+	"SQL statements retried after a transient error.", "statement")
+
+// Querier is the subset of pgx used by the repositories. *pgxpool.Pool,
+// pgx.Tx and the retrying wrapper returned by Database.Retrying satisfy it.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// txBeginner is implemented by the Queriers that can open a transaction:
+// the pool, the retrying wrapper and pgx.Tx itself (as a savepoint).
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// inTx runs fn in a transaction on q, committing when it returns nil. The
+// statements inside are not retried; a failed transaction is rolled back and
+// its error returned.
+func inTx(ctx context.Context, q Querier, fn func(pgx.Tx) error) error {
+	b, ok := q.(txBeginner)
+	if !ok {
+		return errors.New("postgres: querier cannot open a transaction")
+	}
+	return pgx.BeginFunc(ctx, b, fn)
+}
+
+// RetryPolicy controls how transient failures are retried. Attempts counts
+// the first try, so 1 (or 0) disables retries.
+type RetryPolicy struct {
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type ctxKeyIdempotent struct{}
+
+// WithIdempotent marks writes issued with ctx as safe to repeat, so they are
+// retried like reads even when the failure left their outcome unknown.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyIdempotent{}, true)
+}
+
+// Retrying returns a Querier that retries transient failures according to
+// the pool's retry policy.
+func (db *Database) Retrying() Querier {
+	return &retryingQuerier{pool: db.Pool, policy: db.retry}
+}
+
+type retryingQuerier struct {
+	pool   *pgxpool.Pool
+	policy RetryPolicy
+}
+
+func (q *retryingQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := q.do(ctx, sql, func() error {
+		var err error
+		tag, err = q.pool.Exec(ctx, sql, args...)
+		return err
+	})
+	return tag, err
+}
+
+// Begin retries opening the transaction, which is always safe; statements
+// issued on the transaction are not retried.
+func (q *retryingQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	var tx pgx.Tx
+	err := q.do(WithIdempotent(ctx), "BEGIN", func() error {
+		var err error
+		tx, err = q.pool.Begin(ctx)
+		return err
+	})
+	return tx, err
+}
+
+// Query retries only the initial round trip; errors raised while iterating
+// the rows are returned to the caller.
+func (q *retryingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := q.do(ctx, sql, func() error {
+		var err error
+		rows, err = q.pool.Query(ctx, sql, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (q *retryingQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &retryingRow{q: q, ctx: ctx, sql: sql, args: args}
+}
+
+// retryingRow defers the query to Scan because pgx reports QueryRow errors
+// there.
+type retryingRow struct {
+	q    *retryingQuerier
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (r *retryingRow) Scan(dest ...any) error {
+	return r.q.do(r.ctx, r.sql, func() error {
+		return r.q.pool.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	})
+}
+
+func (q *retryingQuerier) do(ctx context.Context, sql string, fn func() error) error {
+	idempotent := isReadOnly(sql)
+	if v, ok := ctx.Value(ctxKeyIdempotent{}).(bool); ok && v {
+		idempotent = true
+	}
+
+	backoff := q.policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= q.policy.Attempts || !isRetryable(err, idempotent) {
+			return err
+		}
+		dbRetries.Inc(statementKind(normalizeSQL(sql)))
+
+		// Full jitter keeps replicas that failed together from retrying in
+		// lockstep against a recovering primary.
+		wait := time.Duration(rand.Int64N(int64(backoff) + 1))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+		if q.policy.MaxBackoff > 0 && backoff > q.policy.MaxBackoff {
+			backoff = q.policy.MaxBackoff
+		}
+	}
+}
+
+// isReadOnly reports whether sql is a plain SELECT that can be repeated.
+func isReadOnly(sql string) bool {
+	sql = strings.ToUpper(strings.TrimSpace(sql))
+	return strings.HasPrefix(sql, "SELECT") && !strings.Contains(sql, "FOR UPDATE")
+}
+
+// isRetryable decides whether err is worth another attempt. Failures where
+// the server never ran the statement (or rolled it back) are always
+// retryable; a dropped connection mid-statement leaves a write's outcome
+// unknown, so only idempotent statements are repeated.
+func isRetryable(err error, idempotent bool) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "40001", pgErr.Code == "40P01":
+			// serialization_failure, deadlock_detected: rolled back.
+			return true
+		case pgErr.Code == "57P01", pgErr.Code == "57P02", pgErr.Code == "57P03",
+			strings.HasPrefix(pgErr.Code, "08"), pgErr.Code == "25006":
+			// admin/crash shutdown, cannot connect now, connection
+			// exceptions, and read_only_sql_transaction after a failover.
+			return idempotent || pgErr.Code == "57P03" || pgErr.Code == "25006"
+		}
+		return false
+	}
+	if !idempotent {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.As(err, &netErr)
+}
@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/returns"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReturnRepository persists returns in PostgreSQL.
+type ReturnRepository struct {
+	pool Querier
+}
+
+// NewReturnRepository constructs a repository.
+func NewReturnRepository(pool Querier) *ReturnRepository {
+	return &ReturnRepository{pool: pool}
+}
+
+var _ domain.Repository = (*ReturnRepository)(nil)
+
+const returnColumns = `id, order_id, status, note, reason, requested_by, decided_by, received_by, created_at, updated_at, decided_at, received_at`
+
+// Create inserts a return with its lines in one transaction.
+func (r *ReturnRepository) Create(ctx context.Context, ret *domain.Return) error {
+	const returnQuery = `
+INSERT INTO returns (` + returnColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+`
+	const lineQuery = `
+INSERT INTO return_lines (return_id, position, product_id, sku, name, quantity, reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, returnQuery,
+			ret.ID,
+			ret.OrderID,
+			ret.Status,
+			ret.Note,
+			ret.Reason,
+			ret.RequestedBy,
+			ret.DecidedBy,
+			ret.ReceivedBy,
+			ret.CreatedAt,
+			ret.UpdatedAt,
+			ret.DecidedAt,
+			ret.ReceivedAt,
+		)
+		if err != nil {
+			return err
+		}
+		for i, l := range ret.Lines {
+			if _, err := tx.Exec(ctx, lineQuery, ret.ID, i, l.ProductID, l.SKU, l.Name, l.Quantity, l.Reason); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID fetches a return with its lines.
+func (r *ReturnRepository) GetByID(ctx context.Context, id string) (*domain.Return, error) {
+	const query = `SELECT ` + returnColumns + ` FROM returns WHERE id = $1`
+	ret, err := scanReturn(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadLines(ctx, []*domain.Return{ret}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// List returns the returns the filter selects, newest first.
+func (r *ReturnRepository) List(ctx context.Context, filter domain.Filter) ([]*domain.Return, error) {
+	const query = `
+SELECT ` + returnColumns + `
+FROM returns
+WHERE ($1 = '' OR status = $1) AND ($2 = '' OR order_id = $2)
+ORDER BY created_at DESC, id DESC
+`
+	rows, err := r.pool.Query(ctx, query, filter.Status, filter.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returns []*domain.Return
+	for rows.Next() {
+		ret, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, ret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadLines(ctx, returns); err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// Update stores a decision on the return while it is still in status from.
+func (r *ReturnRepository) Update(ctx context.Context, ret *domain.Return, from string) error {
+	const query = `
+UPDATE returns
+SET status = $2, reason = $3, decided_by = $4, received_by = $5, updated_at = $6, decided_at = $7, received_at = $8
+WHERE id = $1 AND status = $9
+`
+	tag, err := r.pool.Exec(ctx, query,
+		ret.ID,
+		ret.Status,
+		ret.Reason,
+		ret.DecidedBy,
+		ret.ReceivedBy,
+		ret.UpdatedAt,
+		ret.DecidedAt,
+		ret.ReceivedAt,
+		from,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrStatusChanged
+	}
+	return nil
+}
+
+// Returned sums, per product, the quantity of an order covered by returns
+// that were not rejected.
+func (r *ReturnRepository) Returned(ctx context.Context, orderID string) (map[string]int, error) {
+	const query = `
+SELECT l.product_id, sum(l.quantity)
+FROM return_lines l
+JOIN returns r ON r.id = l.return_id
+WHERE r.order_id = $1 AND r.status <> 'rejected'
+GROUP BY l.product_id
+`
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returned := map[string]int{}
+	for rows.Next() {
+		var productID string
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		returned[productID] = quantity
+	}
+	return returned, rows.Err()
+}
+
+// loadLines fills in the lines of returns.
+func (r *ReturnRepository) loadLines(ctx context.Context, returns []*domain.Return) error {
+	if len(returns) == 0 {
+		return nil
+	}
+	const query = `
+SELECT return_id, product_id, sku, name, quantity, reason
+FROM return_lines
+WHERE return_id = ANY($1)
+ORDER BY return_id, position
+`
+	byID := make(map[string]*domain.Return, len(returns))
+	ids := make([]string, 0, len(returns))
+	for _, ret := range returns {
+		ret.Lines = []domain.Line{}
+		byID[ret.ID] = ret
+		ids = append(ids, ret.ID)
+	}
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var returnID string
+		var l domain.Line
+		if err := rows.Scan(&returnID, &l.ProductID, &l.SKU, &l.Name, &l.Quantity, &l.Reason); err != nil {
+			return err
+		}
+		byID[returnID].Lines = append(byID[returnID].Lines, l)
+	}
+	return rows.Err()
+}
+
+func scanReturn(row pgx.Row) (*domain.Return, error) {
+	var ret domain.Return
+	err := row.Scan(
+		&ret.ID,
+		&ret.OrderID,
+		&ret.Status,
+		&ret.Note,
+		&ret.Reason,
+		&ret.RequestedBy,
+		&ret.DecidedBy,
+		&ret.ReceivedBy,
+		&ret.CreatedAt,
+		&ret.UpdatedAt,
+		&ret.DecidedAt,
+		&ret.ReceivedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"backoffice/backend/internal/domain/search"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	_ search.Source = (*ProductRepository)(nil)
+	_ search.Source = (*UserRepository)(nil)
+	_ search.Source = (*CategoryRepository)(nil)
+)
+
+// likeEscaper escapes the LIKE wildcards (and the default escape character)
+// in user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchArgs returns the arguments shared by the search queries: the raw
+// query for similarity, a substring pattern and a prefix pattern.
+func searchArgs(query string, limit int) []any {
+	escaped := likeEscaper.Replace(query)
+	return []any{query, "%" + escaped + "%", escaped + "%", limit}
+}
+
+// Search matches products by name or SKU, as a substring or by trigram
+// similarity.
+func (r *ProductRepository) Search(ctx context.Context, query string, limit int) ([]search.Hit, error) {
+	const sql = `
+SELECT id, name, sku, greatest(similarity(name, $1), similarity(sku, $1)) AS score
+FROM products
+WHERE deleted_at IS NULL
+  AND (name ILIKE $2 OR sku ILIKE $2 OR name % $1)
+ORDER BY (name ILIKE $3 OR sku ILIKE $3) DESC, score DESC, name
+LIMIT $4
+`
+	rows, err := r.pool.Query(ctx, sql, searchArgs(query, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanHits(rows)
+}
+
+// Search matches users by name or email, as a substring or by trigram
+// similarity.
+func (r *UserRepository) Search(ctx context.Context, query string, limit int) ([]search.Hit, error) {
+	const sql = `
+SELECT id, coalesce(nullif(name, ''), email), email,
+       greatest(similarity(coalesce(name, ''), $1), similarity(email, $1)) AS score
+FROM users
+WHERE deleted_at IS NULL
+  AND (name ILIKE $2 OR email ILIKE $2 OR name % $1)
+ORDER BY (name ILIKE $3 OR email ILIKE $3) DESC, score DESC, email
+LIMIT $4
+`
+	rows, err := r.pool.Query(ctx, sql, searchArgs(query, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanHits(rows)
+}
+
+// Search matches categories by name, as a substring or by trigram
+// similarity.
+func (r *CategoryRepository) Search(ctx context.Context, query string, limit int) ([]search.Hit, error) {
+	const sql = `
+SELECT id, name, slug, similarity(name, $1) AS score
+FROM categories
+WHERE name ILIKE $2 OR name % $1
+ORDER BY (name ILIKE $3) DESC, score DESC, name
+LIMIT $4
+`
+	rows, err := r.pool.Query(ctx, sql, searchArgs(query, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanHits(rows)
+}
+
+func scanHits(rows pgx.Rows) ([]search.Hit, error) {
+	defer rows.Close()
+	var hits []search.Hit
+	for rows.Next() {
+		var hit search.Hit
+		var score float32
+		if err := rows.Scan(&hit.ID, &hit.Title, &hit.Subtitle, &score); err != nil {
+			return nil, err
+		}
+		hit.Score = math.Round(float64(score)*1000) / 1000
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "backoffice/backend/internal/domain/session"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionRepository persists opaque-token sessions in PostgreSQL.
+type SessionRepository struct {
+	pool Querier
+}
+
+// NewSessionRepository constructs a repository.
+func NewSessionRepository(pool Querier) *SessionRepository {
+	return &SessionRepository{pool: pool}
+}
+
+var _ domain.Repository = (*SessionRepository)(nil)
+
+const sessionColumns = `id, user_id, token_hash, client_id, scopes, token_version, created_at, last_used_at, expires_at, client_ip`
+
+// Create inserts a new session.
+func (r *SessionRepository) Create(ctx context.Context, s *domain.Session) error {
+	const query = `
+INSERT INTO sessions (` + sessionColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+	scopes := s.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	_, err := r.pool.Exec(ctx, query,
+		s.ID,
+		s.UserID,
+		s.TokenHash,
+		s.ClientID,
+		scopes,
+		s.TokenVersion,
+		s.CreatedAt,
+		s.LastUsedAt,
+		s.ExpiresAt,
+		s.ClientIP,
+	)
+	return err
+}
+
+// GetByTokenHash fetches the session for a token, expired or not.
+func (r *SessionRepository) GetByTokenHash(ctx context.Context, hash string) (*domain.Session, error) {
+	const query = `SELECT ` + sessionColumns + ` FROM sessions WHERE token_hash = $1`
+	s, err := scanSession(r.pool.QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// Touch records that the session was used.
+func (r *SessionRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE sessions SET last_used_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+// ListActive returns the user's unexpired sessions, newest first.
+func (r *SessionRepository) ListActive(ctx context.Context, userID string, now time.Time) ([]*domain.Session, error) {
+	const query = `
+SELECT ` + sessionColumns + `
+FROM sessions
+WHERE user_id = $1 AND expires_at > $2
+ORDER BY created_at DESC
+`
+	rows, err := r.pool.Query(ctx, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// ListActiveFor returns the unexpired sessions of userIDs, newest first.
+func (r *SessionRepository) ListActiveFor(ctx context.Context, userIDs []string, now time.Time) ([]*domain.Session, error) {
+	const query = `
+SELECT ` + sessionColumns + `
+FROM sessions
+WHERE user_id = ANY($1) AND expires_at > $2
+ORDER BY created_at DESC
+`
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, query, userIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// Delete removes one of the user's sessions.
+func (r *SessionRepository) Delete(ctx context.Context, userID, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByTokenHash removes the session for a token, if any.
+func (r *SessionRepository) DeleteByTokenHash(ctx context.Context, hash string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE token_hash = $1`, hash)
+	return err
+}
+
+// DeleteExpired removes the user's sessions that expired before cutoff.
+func (r *SessionRepository) DeleteExpired(ctx context.Context, userID string, cutoff time.Time) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1 AND expires_at < $2`, userID, cutoff)
+	return err
+}
+
+// CountBefore counts sessions of any user that expired before cutoff.
+func (r *SessionRepository) CountBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM sessions WHERE expires_at < $1`, cutoff).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// PurgeBefore removes sessions of any user that expired before cutoff.
+func (r *SessionRepository) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func scanSession(row pgx.Row) (*domain.Session, error) {
+	var s domain.Session
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.TokenHash,
+		&s.ClientID,
+		&s.Scopes,
+		&s.TokenVersion,
+		&s.CreatedAt,
+		&s.LastUsedAt,
+		&s.ExpiresAt,
+		&s.ClientIP,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
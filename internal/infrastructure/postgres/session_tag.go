@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"backoffice/backend/internal/errreport"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxApplicationName is the longest application_name the server keeps
+// (NAMEDATALEN - 1); longer values are silently truncated.
+const maxApplicationName = 63
+
+// tagSession returns a PrepareConn hook that sets application_name to base
+// followed by the request and user ID of the acquiring context, so a
+// statement seen in pg_stat_activity can be matched to the API request (and
+// its access log line) that issued it. Connections acquired outside a request
+// are reset to base. The setting is only sent when it changes.
+func tagSession(base string) func(context.Context, *pgx.Conn) (bool, error) {
+	return func(ctx context.Context, conn *pgx.Conn) (bool, error) {
+		name := applicationName(base, errreport.ScopeFromContext(ctx))
+		if conn.PgConn().ParameterStatus("application_name") == name {
+			return true, nil
+		}
+		if _, err := conn.Exec(ctx, `SELECT set_config('application_name', $1, false)`, name); err != nil {
+			// A connection that cannot run this cannot run the query either.
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// applicationName renders "base req=<id> user=<id>", dropping whatever does
+// not fit so the request ID survives before the user ID.
+func applicationName(base string, scope *errreport.Scope) string {
+	parts := []string{base}
+	if scope != nil {
+		if scope.RequestID != "" {
+			parts = append(parts, "req="+scope.RequestID)
+		}
+		if scope.UserID != "" {
+			parts = append(parts, "user="+scope.UserID)
+		}
+	}
+	name := strings.Join(parts, " ")
+	if len(name) > maxApplicationName {
+		name = name[:maxApplicationName]
+	}
+	return name
+}
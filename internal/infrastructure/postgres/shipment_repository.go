@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/shipment"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ShipmentRepository persists shipments in PostgreSQL. Tracking events are
+// stored as a JSONB array on the shipment.
+type ShipmentRepository struct {
+	pool Querier
+}
+
+// NewShipmentRepository constructs a repository.
+func NewShipmentRepository(pool Querier) *ShipmentRepository {
+	return &ShipmentRepository{pool: pool}
+}
+
+var _ domain.Repository = (*ShipmentRepository)(nil)
+
+const shipmentColumns = `id, order_id, carrier, service, tracking_number, label_url, reference, status, events, created_by, created_at, updated_at, delivered_at`
+
+// Create inserts a shipment.
+func (r *ShipmentRepository) Create(ctx context.Context, s *domain.Shipment) error {
+	const query = `
+INSERT INTO shipments (` + shipmentColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+`
+	events, err := marshalTrackingEvents(s.Events)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, query,
+		s.ID,
+		s.OrderID,
+		s.Carrier,
+		s.Service,
+		s.TrackingNumber,
+		s.LabelURL,
+		s.Reference,
+		s.Status,
+		events,
+		s.CreatedBy,
+		s.CreatedAt,
+		s.UpdatedAt,
+		s.DeliveredAt,
+	)
+	return err
+}
+
+// GetByID fetches a shipment by id.
+func (r *ShipmentRepository) GetByID(ctx context.Context, id string) (*domain.Shipment, error) {
+	const query = `SELECT ` + shipmentColumns + ` FROM shipments WHERE id = $1`
+	return r.get(ctx, query, id)
+}
+
+// GetByTracking finds a shipment by its carrier and tracking number.
+func (r *ShipmentRepository) GetByTracking(ctx context.Context, carrier, trackingNumber string) (*domain.Shipment, error) {
+	const query = `SELECT ` + shipmentColumns + ` FROM shipments WHERE carrier = $1 AND tracking_number = $2`
+	return r.get(ctx, query, carrier, trackingNumber)
+}
+
+func (r *ShipmentRepository) get(ctx context.Context, query string, args ...any) (*domain.Shipment, error) {
+	s, err := scanShipment(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListByOrder returns an order's shipments, newest first.
+func (r *ShipmentRepository) ListByOrder(ctx context.Context, orderID string) ([]*domain.Shipment, error) {
+	const query = `
+SELECT ` + shipmentColumns + `
+FROM shipments
+WHERE order_id = $1
+ORDER BY created_at DESC, id DESC
+`
+	return r.list(ctx, query, orderID)
+}
+
+// ListOpen returns the shipments that are not delivered or returned,
+// oldest first.
+func (r *ShipmentRepository) ListOpen(ctx context.Context) ([]*domain.Shipment, error) {
+	const query = `
+SELECT ` + shipmentColumns + `
+FROM shipments
+WHERE status NOT IN ('delivered', 'returned')
+ORDER BY created_at, id
+`
+	return r.list(ctx, query)
+}
+
+func (r *ShipmentRepository) list(ctx context.Context, query string, args ...any) ([]*domain.Shipment, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shipments []*domain.Shipment
+	for rows.Next() {
+		s, err := scanShipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, s)
+	}
+	return shipments, rows.Err()
+}
+
+// Update stores a shipment's status and tracking events.
+func (r *ShipmentRepository) Update(ctx context.Context, s *domain.Shipment) error {
+	const query = `
+UPDATE shipments
+SET status = $2, events = $3, updated_at = $4, delivered_at = $5
+WHERE id = $1
+`
+	events, err := marshalTrackingEvents(s.Events)
+	if err != nil {
+		return err
+	}
+	tag, err := r.pool.Exec(ctx, query, s.ID, s.Status, events, s.UpdatedAt, s.DeliveredAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func marshalTrackingEvents(events []domain.TrackingEvent) ([]byte, error) {
+	if events == nil {
+		events = []domain.TrackingEvent{}
+	}
+	return json.Marshal(events)
+}
+
+func scanShipment(row pgx.Row) (*domain.Shipment, error) {
+	var s domain.Shipment
+	var events []byte
+	err := row.Scan(
+		&s.ID,
+		&s.OrderID,
+		&s.Carrier,
+		&s.Service,
+		&s.TrackingNumber,
+		&s.LabelURL,
+		&s.Reference,
+		&s.Status,
+		&events,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&s.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &s.Events); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
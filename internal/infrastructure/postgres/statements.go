@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// The hottest queries: every authenticated request loads its user, every
+// sign-in looks one up by email, and the product list backs the catalogue
+// pages. They list their columns so later migrations adding columns leave
+// the prepared result types valid.
+const (
+	userByIDQuery = `
+SELECT id, email, name, role, password_hash, token_version, created_at, updated_at, locale, timezone
+FROM users WHERE id = $1 AND deleted_at IS NULL
+`
+	userByEmailQuery = `
+SELECT id, email, name, role, password_hash, token_version, created_at, updated_at, locale, timezone
+FROM users WHERE lower(email) = lower($1) AND deleted_at IS NULL
+`
+	productListQuery = `
+SELECT ` + productColumns + `
+FROM products
+WHERE deleted_at IS NULL
+ORDER BY name ASC
+`
+)
+
+// hotStatements are prepared on every new connection. Each is prepared
+// under its own text, so pgx uses the prepared statement whenever a
+// repository runs that exact query, and it is never evicted from the
+// connection's statement cache by less frequent queries.
+var hotStatements = []string{userByIDQuery, userByEmailQuery, productListQuery}
+
+// prepareHotStatements prepares hotStatements on conn unless the connection
+// avoids prepared statements, as it must behind PgBouncer in transaction
+// mode. A statement the server rejects, such as one whose table a pending
+// migration creates, is left to the statement cache.
+func prepareHotStatements(ctx context.Context, conn *pgx.Conn) error {
+	switch conn.Config().DefaultQueryExecMode {
+	case pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol:
+		return nil
+	}
+	for _, sql := range hotStatements {
+		if _, err := conn.Prepare(ctx, sql, sql); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	domain "backoffice/backend/internal/domain/auth"
+)
+
+// BenchmarkHotStatements compares the hot queries run as prepared
+// statements with the same queries parsed on every execution. It needs a
+// migrated database named by TEST_DATABASE_URL:
+//
+//	TEST_DATABASE_URL=postgres://... go test -run '^$' -bench HotStatements ./internal/infrastructure/postgres
+func BenchmarkHotStatements(b *testing.B) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("TEST_DATABASE_URL is not set")
+	}
+	for _, mode := range []string{"cache_statement", "exec"} {
+		b.Run(mode, func(b *testing.B) {
+			ctx := context.Background()
+			db, err := New(ctx, dsn, PoolOptions{MaxConns: 4, StatementCacheMode: mode})
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer db.Close()
+			users := NewUserRepository(db.Retrying())
+			products := NewProductRepository(db.Retrying())
+
+			b.Run("user_by_id", func(b *testing.B) {
+				for b.Loop() {
+					if _, err := users.GetByID(ctx, "00000000-0000-0000-0000-000000000000"); err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("user_by_email", func(b *testing.B) {
+				for b.Loop() {
+					if _, err := users.GetByEmail(ctx, "nobody@example.com"); err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("product_list", func(b *testing.B) {
+				for b.Loop() {
+					if _, err := products.List(ctx); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
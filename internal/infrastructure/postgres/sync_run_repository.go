@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/stocksync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SyncRunRepository persists the product synchronization history in
+// PostgreSQL.
+type SyncRunRepository struct {
+	pool Querier
+}
+
+// NewSyncRunRepository constructs a repository.
+func NewSyncRunRepository(pool Querier) *SyncRunRepository {
+	return &SyncRunRepository{pool: pool}
+}
+
+var _ domain.RunRepository = (*SyncRunRepository)(nil)
+
+const syncRunColumns = `id, connector, status, started_at, finished_at, fetched, created, updated, unchanged, duplicates, rejected, problems, error`
+
+// CreateRun inserts a new run.
+func (r *SyncRunRepository) CreateRun(ctx context.Context, run *domain.Run) error {
+	const query = `
+INSERT INTO sync_runs (` + syncRunColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+`
+	_, err := r.pool.Exec(ctx, query, syncRunArgs(run)...)
+	return err
+}
+
+// UpdateRun writes a run's progress or outcome.
+func (r *SyncRunRepository) UpdateRun(ctx context.Context, run *domain.Run) error {
+	const query = `
+UPDATE sync_runs
+SET status = $2,
+    finished_at = $3,
+    fetched = $4,
+    created = $5,
+    updated = $6,
+    unchanged = $7,
+    duplicates = $8,
+    rejected = $9,
+    problems = $10,
+    error = $11
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query,
+		run.ID,
+		run.Status,
+		run.FinishedAt,
+		run.Fetched,
+		run.Created,
+		run.Updated,
+		run.Unchanged,
+		run.Duplicates,
+		run.Rejected,
+		nonNil(run.Problems),
+		run.Error,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRunNotFound
+	}
+	return nil
+}
+
+// GetRun fetches a run by id.
+func (r *SyncRunRepository) GetRun(ctx context.Context, id string) (*domain.Run, error) {
+	const query = `SELECT ` + syncRunColumns + ` FROM sync_runs WHERE id = $1`
+	run, err := scanSyncRun(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRunNotFound
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListRuns returns matching runs, newest first.
+func (r *SyncRunRepository) ListRuns(ctx context.Context, filter domain.RunFilter) ([]*domain.Run, error) {
+	q := newSelect(`SELECT ` + syncRunColumns + ` FROM sync_runs`)
+	if filter.Connector != "" {
+		q.Where("connector = ?", filter.Connector)
+	}
+	query, args := q.OrderBy("started_at DESC, id DESC").Limit(filter.Limit).Build()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*domain.Run
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// nonNil keeps NULL out of the NOT NULL problems column.
+func nonNil(problems []string) []string {
+	if problems == nil {
+		return []string{}
+	}
+	return problems
+}
+
+func syncRunArgs(run *domain.Run) []any {
+	return []any{
+		run.ID,
+		run.Connector,
+		run.Status,
+		run.StartedAt,
+		run.FinishedAt,
+		run.Fetched,
+		run.Created,
+		run.Updated,
+		run.Unchanged,
+		run.Duplicates,
+		run.Rejected,
+		nonNil(run.Problems),
+		run.Error,
+	}
+}
+
+func scanSyncRun(row pgx.Row) (*domain.Run, error) {
+	var run domain.Run
+	err := row.Scan(
+		&run.ID,
+		&run.Connector,
+		&run.Status,
+		&run.StartedAt,
+		&run.FinishedAt,
+		&run.Fetched,
+		&run.Created,
+		&run.Updated,
+		&run.Unchanged,
+		&run.Duplicates,
+		&run.Rejected,
+		&run.Problems,
+		&run.Error,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
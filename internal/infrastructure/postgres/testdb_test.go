@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// testDB connects to the migrated database named by TEST_DATABASE_URL and
+// skips the test when it is not set:
+//
+//	TEST_DATABASE_URL=postgres://... go test ./internal/infrastructure/postgres
+func testDB(t *testing.T) *Database {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := New(context.Background(), dsn, PoolOptions{MaxConns: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
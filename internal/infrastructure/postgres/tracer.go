@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"backoffice/backend/internal/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	queryDuration = metrics.Default.NewHistogramVec("db_query_duration_seconds",
+		"Duration of SQL statements.", nil, "status")
+	slowQueries = metrics.Default.NewCounterVec("db_slow_queries_total",
+		"SQL statements slower than the configured threshold.", "statement")
+	acquireWait = metrics.Default.NewHistogramVec("db_pool_acquire_wait_seconds",
+		"Time spent waiting for a pooled connection.",
+		[]float64{0.0005, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}, "status")
+	acquireFailures = metrics.Default.NewCounterVec("db_pool_acquire_failures_total",
+		"Connection acquires that failed, by reason (timeout, canceled, error).", "reason")
+)
+
+// tracer hooks into pgx query and pool acquire events.
+type tracer struct {
+	acquireTimeout time.Duration
+	// slowThreshold (nanoseconds) and logAll can be changed at runtime via
+	// Database.SetQueryLogging.
+	slowThreshold atomic.Int64
+	logAll        atomic.Bool
+}
+
+var (
+	_ pgx.QueryTracer       = (*tracer)(nil)
+	_ pgxpool.AcquireTracer = (*tracer)(nil)
+)
+
+type (
+	ctxKeyAcquireCancel struct{}
+	ctxKeyAcquireStart  struct{}
+	ctxKeyQueryStart    struct{}
+)
+
+type queryStart struct {
+	at   time.Time
+	sql  string
+	args []any
+}
+
+func (t *tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, ctxKeyQueryStart{}, queryStart{at: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+func (t *tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(ctxKeyQueryStart{}).(queryStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.at)
+	status := "ok"
+	if data.Err != nil {
+		status = "error"
+	}
+	queryDuration.Observe(elapsed.Seconds(), status)
+
+	threshold := time.Duration(t.slowThreshold.Load())
+	slow := threshold > 0 && elapsed >= threshold
+	if !slow && !t.logAll.Load() {
+		return
+	}
+	sql := normalizeSQL(start.sql)
+	if slow {
+		slowQueries.Inc(statementKind(sql))
+		log.Printf("slow query: %s (%s, args %s, status %s)", sql, elapsed.Round(time.Microsecond), redactArgs(start.args), status)
+		return
+	}
+	log.Printf("query: %s (%s, args %s, status %s)", sql, elapsed.Round(time.Microsecond), redactArgs(start.args), status)
+}
+
+// TraceAcquireStart times and bounds the wait for a pooled connection. The
+// returned context is only used by the acquire itself, so cancelling it
+// afterwards does not affect the query that runs on the connection.
+func (t *tracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	ctx = context.WithValue(ctx, ctxKeyAcquireStart{}, time.Now())
+	if t.acquireTimeout <= 0 {
+		return ctx
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.acquireTimeout)
+	return context.WithValue(ctx, ctxKeyAcquireCancel{}, cancel)
+}
+
+func (t *tracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	if cancel, ok := ctx.Value(ctxKeyAcquireCancel{}).(context.CancelFunc); ok {
+		cancel()
+	}
+	status := "ok"
+	if data.Err != nil {
+		status = "error"
+		acquireFailures.Inc(acquireFailureReason(data.Err))
+	}
+	if start, ok := ctx.Value(ctxKeyAcquireStart{}).(time.Time); ok {
+		acquireWait.Observe(time.Since(start).Seconds(), status)
+	}
+}
+
+func acquireFailureReason(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+var (
+	sqlWhitespace = regexp.MustCompile(`\s+`)
+	// sqlLiterals matches quoted strings and bare numbers so values inlined
+	// into SQL text are not logged; $N placeholders are matched to be kept.
+	sqlLiterals = regexp.MustCompile(`\$\d+|'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+)
+
+// normalizeSQL collapses whitespace and replaces inline literals with "?".
+func normalizeSQL(sql string) string {
+	sql = sqlLiterals.ReplaceAllStringFunc(sql, func(lit string) string {
+		if strings.HasPrefix(lit, "$") {
+			return lit
+		}
+		return "?"
+	})
+	return strings.TrimSpace(sqlWhitespace.ReplaceAllString(sql, " "))
+}
+
+// statementKind returns the leading keyword (SELECT, INSERT, ...) for use as
+// a low-cardinality metric label.
+func statementKind(sql string) string {
+	kind, _, _ := strings.Cut(sql, " ")
+	return strings.ToUpper(kind)
+}
+
+// redactArgs describes bind parameters by type only; values may hold
+// credentials or personal data.
+func redactArgs(args []any) string {
+	if len(args) == 0 {
+		return "[]"
+	}
+	types := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			types[i] = "null"
+			continue
+		}
+		types[i] = fmt.Sprintf("%T", arg)
+	}
+	return "[" + strings.Join(types, " ") + "]"
+}
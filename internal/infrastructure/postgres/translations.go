@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+
+	domain "backoffice/backend/internal/domain/product"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var _ domain.TranslationRepository = (*ProductRepository)(nil)
+
+const translationColumns = `product_id, locale, name, description, updated_by, updated_at`
+
+// PutTranslation creates or replaces the product's translation in
+// t.Locale.
+func (r *ProductRepository) PutTranslation(ctx context.Context, t *domain.Translation) error {
+	const query = `
+INSERT INTO product_translations (` + translationColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (product_id, locale) DO UPDATE
+SET name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+`
+	_, err := r.pool.Exec(ctx, query,
+		t.ProductID,
+		t.Locale,
+		t.Name,
+		t.Description,
+		t.UpdatedBy,
+		t.UpdatedAt,
+	)
+	if isForeignKeyViolation(err) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+// ListTranslations returns the product's translations ordered by locale.
+func (r *ProductRepository) ListTranslations(ctx context.Context, productID string) ([]*domain.Translation, error) {
+	const query = `SELECT ` + translationColumns + ` FROM product_translations WHERE product_id = $1 ORDER BY locale`
+	rows, err := r.pool.Query(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	return scanTranslations(rows)
+}
+
+// ListTranslationsFor returns the translations of productIDs in locales, by
+// product and then locale.
+func (r *ProductRepository) ListTranslationsFor(ctx context.Context, productIDs, locales []string) ([]*domain.Translation, error) {
+	const query = `
+SELECT ` + translationColumns + `
+FROM product_translations
+WHERE product_id = ANY($1) AND locale = ANY($2)
+ORDER BY product_id, locale
+`
+	if len(productIDs) == 0 || len(locales) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, query, productIDs, locales)
+	if err != nil {
+		return nil, err
+	}
+	return scanTranslations(rows)
+}
+
+// DeleteTranslation removes the product's translation in locale.
+func (r *ProductRepository) DeleteTranslation(ctx context.Context, productID, locale string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM product_translations WHERE product_id = $1 AND locale = $2`, productID, locale)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTranslationNotFound
+	}
+	return nil
+}
+
+func scanTranslations(rows pgx.Rows) ([]*domain.Translation, error) {
+	defer rows.Close()
+	var translations []*domain.Translation
+	for rows.Next() {
+		var t domain.Translation
+		if err := rows.Scan(&t.ProductID, &t.Locale, &t.Name, &t.Description, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		translations = append(translations, &t)
+	}
+	return translations, rows.Err()
+}
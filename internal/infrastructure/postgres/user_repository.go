@@ -6,26 +6,27 @@ import (
 	"time"
 
 	domain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/trash"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // UserRepository persists users in PostgreSQL.
 type UserRepository struct {
-	pool *pgxpool.Pool
+	pool Querier
 }
 
-// NewUserRepository constructs a repository.
-func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
+// NewUserRepository constructs a repository on top of a pool, transaction or
+// Database.Retrying.
+func NewUserRepository(pool Querier) *UserRepository {
 	return &UserRepository{pool: pool}
 }
 
 // Create inserts a new user record.
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	const query = `
-INSERT INTO users (id, email, name, role, password_hash, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO users (id, email, name, role, password_hash, created_at, updated_at, locale, timezone)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 `
 	_, err := r.pool.Exec(ctx, query,
 		user.ID,
@@ -35,6 +36,8 @@ VALUES ($1, $2, $3, $4, $5, $6, $7)
 		user.PasswordHash,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.Locale,
+		user.Timezone,
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -45,13 +48,50 @@ VALUES ($1, $2, $3, $4, $5, $6, $7)
 	return nil
 }
 
-// GetByEmail fetches a user by email.
-func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+// firstUserLockID is the transaction-scoped advisory lock key held while a
+// registration checks whether it is the first, so two first registrations
+// cannot both see an empty table.
+const firstUserLockID = 7_246_913_003
+
+// CreatePromotingFirst inserts a new user record, as an admin if the users
+// table is empty.
+func (r *UserRepository) CreatePromotingFirst(ctx context.Context, user *domain.User) error {
 	const query = `
-SELECT id, email, name, role, password_hash, created_at, updated_at
-FROM users WHERE email = $1
+INSERT INTO users (id, email, name, role, password_hash, created_at, updated_at, locale, timezone)
+SELECT $1, $2, $3, CASE WHEN EXISTS (SELECT 1 FROM users) THEN $4 ELSE $10 END, $5, $6, $7, $8, $9
+RETURNING role
 `
-	row := r.pool.QueryRow(ctx, query, email)
+	var role domain.UserRole
+	err := inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, firstUserLockID); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, query,
+			user.ID,
+			user.Email,
+			user.Name,
+			user.Role,
+			user.PasswordHash,
+			user.CreatedAt,
+			user.UpdatedAt,
+			user.Locale,
+			user.Timezone,
+			domain.RoleAdmin,
+		).Scan(&role)
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrEmailExists
+		}
+		return err
+	}
+	user.Role = role
+	return nil
+}
+
+// GetByEmail fetches a user by email, ignoring case.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	row := r.pool.QueryRow(ctx, userByEmailQuery, email)
 	user, err := scanUser(row)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -64,11 +104,7 @@ FROM users WHERE email = $1
 
 // GetByID retrieves a user by id.
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	const query = `
-SELECT id, email, name, role, password_hash, created_at, updated_at
-FROM users WHERE id = $1
-`
-	row := r.pool.QueryRow(ctx, query, id)
+	row := r.pool.QueryRow(ctx, userByIDQuery, id)
 	user, err := scanUser(row)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -79,18 +115,42 @@ FROM users WHERE id = $1
 	return user, nil
 }
 
+// GetByIDs fetches the live users among ids.
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	const query = `
+SELECT id, email, name, role, password_hash, token_version, created_at, updated_at, locale, timezone
+FROM users WHERE id = ANY($1) AND deleted_at IS NULL
+`
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
 // List returns users filtered by the provided criteria.
 func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
-	query := `
-SELECT id, email, name, role, password_hash, created_at, updated_at
+	q := newSelect(`
+SELECT id, email, name, role, password_hash, token_version, created_at, updated_at, locale, timezone
 FROM users
-`
-	var args []any
+`).Where("deleted_at IS NULL")
 	if filter.Role != "" {
-		query += "WHERE role = $1 "
-		args = append(args, filter.Role)
+		q.Where("role = ?", filter.Role)
 	}
-	query += "ORDER BY created_at DESC"
+	query, args := q.OrderBy("created_at DESC").Build()
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -116,8 +176,8 @@ FROM users
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	const query = `
 UPDATE users
-SET email = $2, name = $3, role = $4, updated_at = $5
-WHERE id = $1
+SET email = $2, name = $3, role = $4, updated_at = $5, locale = $6, timezone = $7
+WHERE id = $1 AND deleted_at IS NULL
 `
 	ct, err := r.pool.Exec(ctx, query,
 		user.ID,
@@ -125,6 +185,8 @@ WHERE id = $1
 		user.Name,
 		user.Role,
 		user.UpdatedAt,
+		user.Locale,
+		user.Timezone,
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -138,9 +200,9 @@ WHERE id = $1
 	return nil
 }
 
-// Delete removes a user by id.
+// Delete moves a user to the trash.
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	const query = `DELETE FROM users WHERE id = $1`
+	const query = `UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`
 	ct, err := r.pool.Exec(ctx, query, id)
 	if err != nil {
 		return err
@@ -151,12 +213,255 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// DeleteMany moves all of ids to the trash in one statement, rolled back
+// unless every one of them was live.
+func (r *UserRepository) DeleteMany(ctx context.Context, ids []string) error {
+	const query = `UPDATE users SET deleted_at = now() WHERE id = ANY($1) AND deleted_at IS NULL`
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		ct, err := tx.Exec(ctx, query, ids)
+		if err != nil {
+			return err
+		}
+		if ct.RowsAffected() != int64(len(ids)) {
+			return domain.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// Anonymize overwrites a live user with its scrubbed fields and takes it out
+// of use, in one transaction. It removes the data only the user owns and
+// erases what identifies the user elsewhere: the client addresses of its
+// consents and audit entries, its label in the audit trail, its email in
+// approvals, and its personal data in webhook deliveries. Audit entries
+// stay verifiable since the chain does not cover addresses or labels.
+func (r *UserRepository) Anonymize(ctx context.Context, user *domain.User) error {
+	const query = `
+UPDATE users
+SET email = $2, name = $3, role = $4, password_hash = $5, locale = $6, timezone = $7,
+    updated_at = $8, token_version = token_version + 1, deleted_at = $8, anonymized_at = $8
+WHERE id = $1 AND deleted_at IS NULL
+`
+	owned := []string{
+		`DELETE FROM sessions WHERE user_id = $1`,
+		`DELETE FROM saved_views WHERE user_id = $1`,
+		`DELETE FROM product_watches WHERE user_id = $1`,
+		`DELETE FROM watch_notifications WHERE user_id = $1`,
+		`UPDATE user_consents SET client_ip = '' WHERE user_id = $1`,
+		`UPDATE activity_log SET actor_ip = '' WHERE actor_id = $1 AND actor_ip <> ''`,
+		`UPDATE activity_log SET entity_name = '' WHERE entity_type = 'user' AND entity_id = $1 AND entity_name <> ''`,
+		// user.* payloads carry the user's email and name; keep only the id.
+		`UPDATE webhook_deliveries SET payload = jsonb_set(payload, '{data}', jsonb_build_object('id', $1::text))
+WHERE event_type LIKE 'user.%' AND payload->>'subject' = $1`,
+	}
+	// Approval summaries name the user by email ("Delete user …"), and so
+	// do the audit labels and webhook payloads of approval events.
+	mentions := []string{
+		`UPDATE activity_log SET entity_name = replace(entity_name, $2, $3)
+WHERE entity_type = 'approval' AND entity_id IN (SELECT id FROM approvals WHERE subject = $1) AND strpos(entity_name, $2) > 0`,
+		`UPDATE webhook_deliveries SET payload = replace(payload::text, $2, $3)::jsonb
+WHERE event_type LIKE 'approval.%' AND payload->'data'->>'subject' = $1 AND strpos(payload::text, $2) > 0`,
+		`UPDATE approvals SET summary = replace(summary, $2, $3) WHERE subject = $1 AND strpos(summary, $2) > 0`,
+	}
+	return inTx(ctx, r.pool, func(tx pgx.Tx) error {
+		var email string
+		err := tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, user.ID).Scan(&email)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		ct, err := tx.Exec(ctx, query,
+			user.ID,
+			user.Email,
+			user.Name,
+			user.Role,
+			user.PasswordHash,
+			user.Locale,
+			user.Timezone,
+			user.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if ct.RowsAffected() == 0 {
+			return domain.ErrUserNotFound
+		}
+		for _, statement := range owned {
+			if _, err := tx.Exec(ctx, statement, user.ID); err != nil {
+				return err
+			}
+		}
+		for _, statement := range mentions {
+			if _, err := tx.Exec(ctx, statement, user.ID, email, user.Email); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CountByRole returns how many users hold the role.
+func (r *UserRepository) CountByRole(ctx context.Context, role domain.UserRole) (int, error) {
+	const query = `SELECT count(*) FROM users WHERE role = $1 AND deleted_at IS NULL`
+	var count int
+	if err := r.pool.QueryRow(ctx, query, role).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// RecordLogin stamps the user's last successful login.
+func (r *UserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	const query = `UPDATE users SET last_login_at = $2 WHERE id = $1`
+	ct, err := r.pool.Exec(ctx, query, id, at)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Stats aggregates users by role and activity, plus signups per UTC day
+// since signupsSince. Days without signups are omitted.
+func (r *UserRepository) Stats(ctx context.Context, activeSince, signupsSince time.Time) (*domain.UserStats, error) {
+	const byRoleQuery = `
+SELECT role, count(*), count(*) FILTER (WHERE last_login_at >= $1)
+FROM users
+WHERE deleted_at IS NULL
+GROUP BY role
+`
+	rows, err := r.pool.Query(ctx, byRoleQuery, activeSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &domain.UserStats{ByRole: map[domain.UserRole]int{}}
+	for rows.Next() {
+		var role domain.UserRole
+		var total, active int
+		if err := rows.Scan(&role, &total, &active); err != nil {
+			return nil, err
+		}
+		stats.ByRole[role] = total
+		stats.Total += total
+		stats.Active += active
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	stats.Dormant = stats.Total - stats.Active
+
+	const signupsQuery = `
+SELECT to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, count(*)
+FROM users
+WHERE created_at >= $1
+GROUP BY day
+ORDER BY day
+`
+	rows, err = r.pool.Query(ctx, signupsQuery, signupsSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day domain.DailyCount
+		if err := rows.Scan(&day.Date, &day.Count); err != nil {
+			return nil, err
+		}
+		stats.Signups = append(stats.Signups, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListDeleted returns trashed users, most recently deleted first.
+func (r *UserRepository) ListDeleted(ctx context.Context) ([]trash.Item, error) {
+	const query = `
+SELECT id, coalesce(nullif(name, ''), email), deleted_at
+FROM users
+WHERE deleted_at IS NOT NULL AND anonymized_at IS NULL
+ORDER BY deleted_at DESC
+`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []trash.Item
+	for rows.Next() {
+		item := trash.Item{Kind: trash.KindUser}
+		if err := rows.Scan(&item.ID, &item.Name, &item.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Restore takes a user out of the trash. It fails with ErrEmailExists when
+// the email has been reused in the meantime.
+func (r *UserRepository) Restore(ctx context.Context, id string) error {
+	const query = `UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL AND anonymized_at IS NULL`
+	ct, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrEmailExists
+		}
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes a trashed user.
+func (r *UserRepository) Purge(ctx context.Context, id string) error {
+	const query = `DELETE FROM users WHERE id = $1 AND deleted_at IS NOT NULL AND anonymized_at IS NULL`
+	ct, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// CountDeletedBefore counts users trashed before cutoff.
+func (r *UserRepository) CountDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `SELECT COUNT(*) FROM users WHERE deleted_at < $1 AND anonymized_at IS NULL`
+	var count int
+	if err := r.pool.QueryRow(ctx, query, cutoff).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// PurgeDeletedBefore permanently deletes users trashed before cutoff.
+func (r *UserRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `DELETE FROM users WHERE deleted_at < $1 AND anonymized_at IS NULL`
+	ct, err := r.pool.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(ct.RowsAffected()), nil
+}
+
 // UpdatePassword updates the stored password hash for a user.
 func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string, updatedAt time.Time) error {
 	const query = `
 UPDATE users
-SET password_hash = $2, updated_at = $3
-WHERE id = $1
+SET password_hash = $2, updated_at = $3, token_version = token_version + 1
+WHERE id = $1 AND deleted_at IS NULL
 `
 	ct, err := r.pool.Exec(ctx, query, id, passwordHash, updatedAt)
 	if err != nil {
@@ -168,6 +473,19 @@ WHERE id = $1
 	return nil
 }
 
+// BumpTokenVersion invalidates every token issued to the user so far.
+func (r *UserRepository) BumpTokenVersion(ctx context.Context, id string) error {
+	const query = `UPDATE users SET token_version = token_version + 1 WHERE id = $1 AND deleted_at IS NULL`
+	ct, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
 func scanUser(row pgx.Row) (*domain.User, error) {
 	var u domain.User
 	err := row.Scan(
@@ -176,8 +494,11 @@ func scanUser(row pgx.Row) (*domain.User, error) {
 		&u.Name,
 		&u.Role,
 		&u.PasswordHash,
+		&u.TokenVersion,
 		&u.CreatedAt,
 		&u.UpdatedAt,
+		&u.Locale,
+		&u.Timezone,
 	)
 	if err != nil {
 		return nil, err
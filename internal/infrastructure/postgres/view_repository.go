@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	domain "backoffice/backend/internal/domain/view"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ViewRepository persists saved list views in PostgreSQL. Filters are
+// stored as JSON.
+type ViewRepository struct {
+	pool Querier
+}
+
+// NewViewRepository constructs a repository.
+func NewViewRepository(pool Querier) *ViewRepository {
+	return &ViewRepository{pool: pool}
+}
+
+var _ domain.Repository = (*ViewRepository)(nil)
+
+const viewColumns = `id, user_id, resource, name, filters, sort, created_at, updated_at`
+
+// Create inserts a view.
+func (r *ViewRepository) Create(ctx context.Context, v *domain.View) error {
+	const query = `
+INSERT INTO saved_views (` + viewColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+	filters, err := marshalViewFilters(v.Filters)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, query,
+		v.ID,
+		v.UserID,
+		v.Resource,
+		v.Name,
+		filters,
+		v.Sort,
+		v.CreatedAt,
+		v.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateName
+	}
+	return err
+}
+
+// GetByID fetches a view by id.
+func (r *ViewRepository) GetByID(ctx context.Context, id string) (*domain.View, error) {
+	const query = `SELECT ` + viewColumns + ` FROM saved_views WHERE id = $1`
+	v, err := scanView(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// List returns the user's views of resource, or of every list when resource
+// is empty, ordered by list and then name.
+func (r *ViewRepository) List(ctx context.Context, userID, resource string) ([]*domain.View, error) {
+	const query = `
+SELECT ` + viewColumns + `
+FROM saved_views
+WHERE user_id = $1 AND ($2 = '' OR resource = $2)
+ORDER BY resource, name
+`
+	rows, err := r.pool.Query(ctx, query, userID, resource)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []*domain.View
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+// Update stores a view's name, filters and sort.
+func (r *ViewRepository) Update(ctx context.Context, v *domain.View) error {
+	const query = `
+UPDATE saved_views
+SET name = $2, filters = $3, sort = $4, updated_at = $5
+WHERE id = $1
+`
+	filters, err := marshalViewFilters(v.Filters)
+	if err != nil {
+		return err
+	}
+	tag, err := r.pool.Exec(ctx, query, v.ID, v.Name, filters, v.Sort, v.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateName
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a view.
+func (r *ViewRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM saved_views WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func marshalViewFilters(filters []domain.Filter) ([]byte, error) {
+	if filters == nil {
+		filters = []domain.Filter{}
+	}
+	return json.Marshal(filters)
+}
+
+func scanView(row pgx.Row) (*domain.View, error) {
+	var (
+		v       domain.View
+		filters []byte
+	)
+	err := row.Scan(
+		&v.ID,
+		&v.UserID,
+		&v.Resource,
+		&v.Name,
+		&filters,
+		&v.Sort,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filters, &v.Filters); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	productdomain "backoffice/backend/internal/domain/product"
+	domain "backoffice/backend/internal/domain/watch"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WatchRepository persists product watches and watch notifications in
+// PostgreSQL. Notification changes are stored as JSON.
+type WatchRepository struct {
+	pool Querier
+}
+
+// NewWatchRepository constructs a repository.
+func NewWatchRepository(pool Querier) *WatchRepository {
+	return &WatchRepository{pool: pool}
+}
+
+var _ domain.Repository = (*WatchRepository)(nil)
+
+const (
+	watchColumns             = `user_id, product_id, fields, product_name, last_price, last_stock, last_status, created_at, updated_at`
+	watchNotificationColumns = `id, user_id, product_id, product_name, changes, created_at`
+)
+
+// Put creates or replaces the user's watch of w.ProductID.
+func (r *WatchRepository) Put(ctx context.Context, w *domain.Watch) error {
+	const query = `
+INSERT INTO product_watches (` + watchColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (user_id, product_id) DO UPDATE
+SET fields = EXCLUDED.fields,
+    product_name = EXCLUDED.product_name,
+    last_price = EXCLUDED.last_price,
+    last_stock = EXCLUDED.last_stock,
+    last_status = EXCLUDED.last_status,
+    updated_at = EXCLUDED.updated_at
+`
+	_, err := r.pool.Exec(ctx, query,
+		w.UserID,
+		w.ProductID,
+		w.Fields,
+		w.ProductName,
+		w.Last.Price,
+		w.Last.Stock,
+		w.Last.Status,
+		w.CreatedAt,
+		w.UpdatedAt,
+	)
+	if isForeignKeyViolation(err) {
+		return productdomain.ErrNotFound
+	}
+	return err
+}
+
+// Get fetches the user's watch of a product.
+func (r *WatchRepository) Get(ctx context.Context, userID, productID string) (*domain.Watch, error) {
+	const query = `SELECT ` + watchColumns + ` FROM product_watches WHERE user_id = $1 AND product_id = $2`
+	w, err := scanWatch(r.pool.QueryRow(ctx, query, userID, productID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+// ListByUser returns the user's watches, newest first.
+func (r *WatchRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Watch, error) {
+	const query = `SELECT ` + watchColumns + ` FROM product_watches WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWatches(rows)
+}
+
+// ListByProduct returns every watch of the product.
+func (r *WatchRepository) ListByProduct(ctx context.Context, productID string) ([]*domain.Watch, error) {
+	const query = `SELECT ` + watchColumns + ` FROM product_watches WHERE product_id = $1 ORDER BY created_at`
+	rows, err := r.pool.Query(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWatches(rows)
+}
+
+// SetLast records the name and snapshot every watcher of the product was
+// told about.
+func (r *WatchRepository) SetLast(ctx context.Context, productID, name string, last domain.Snapshot) error {
+	const query = `
+UPDATE product_watches
+SET product_name = $2, last_price = $3, last_stock = $4, last_status = $5
+WHERE product_id = $1
+`
+	_, err := r.pool.Exec(ctx, query, productID, name, last.Price, last.Stock, last.Status)
+	return err
+}
+
+// Delete removes the user's watch of a product.
+func (r *WatchRepository) Delete(ctx context.Context, userID, productID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM product_watches WHERE user_id = $1 AND product_id = $2`, userID, productID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// AddNotification stores a notification.
+func (r *WatchRepository) AddNotification(ctx context.Context, n *domain.Notification) error {
+	const query = `
+INSERT INTO watch_notifications (` + watchNotificationColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+	changes, err := json.Marshal(n.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, query, n.ID, n.UserID, n.ProductID, n.ProductName, changes, n.CreatedAt)
+	return err
+}
+
+// ListNotifications returns the user's latest notifications, newest first.
+func (r *WatchRepository) ListNotifications(ctx context.Context, userID string, limit int) ([]*domain.Notification, error) {
+	const query = `
+SELECT ` + watchNotificationColumns + `
+FROM watch_notifications
+WHERE user_id = $1
+ORDER BY created_at DESC, id
+LIMIT $2
+`
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		var (
+			n       domain.Notification
+			changes []byte
+		)
+		if err := rows.Scan(&n.ID, &n.UserID, &n.ProductID, &n.ProductName, &changes, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &n.Changes); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &n)
+	}
+	return notifications, rows.Err()
+}
+
+// ClearNotifications removes every notification of the user.
+func (r *WatchRepository) ClearNotifications(ctx context.Context, userID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM watch_notifications WHERE user_id = $1`, userID)
+	return err
+}
+
+func scanWatches(rows pgx.Rows) ([]*domain.Watch, error) {
+	defer rows.Close()
+	var watches []*domain.Watch
+	for rows.Next() {
+		w, err := scanWatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
+
+func scanWatch(row pgx.Row) (*domain.Watch, error) {
+	var w domain.Watch
+	err := row.Scan(
+		&w.UserID,
+		&w.ProductID,
+		&w.Fields,
+		&w.ProductName,
+		&w.Last.Price,
+		&w.Last.Stock,
+		&w.Last.Status,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
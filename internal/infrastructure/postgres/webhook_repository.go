@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "backoffice/backend/internal/domain/webhook"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WebhookRepository persists webhook subscriptions and deliveries in
+// PostgreSQL.
+type WebhookRepository struct {
+	pool Querier
+}
+
+// NewWebhookRepository constructs a repository.
+func NewWebhookRepository(pool Querier) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+var _ domain.Repository = (*WebhookRepository)(nil)
+
+const subscriptionColumns = `id, url, events, description, secret, active, payload_version, created_at, updated_at`
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, last_status_code, last_error, next_attempt_at, created_at, updated_at`
+
+// CreateSubscription inserts a new subscription.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	const query = `
+INSERT INTO webhook_subscriptions (` + subscriptionColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+	_, err := r.pool.Exec(ctx, query,
+		sub.ID,
+		sub.URL,
+		sub.Events,
+		sub.Description,
+		sub.Secret,
+		sub.Active,
+		sub.Version,
+		sub.CreatedAt,
+		sub.UpdatedAt,
+	)
+	return err
+}
+
+// GetSubscription fetches a subscription by id.
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	const query = `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	sub, err := scanSubscription(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns all subscriptions, oldest first.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.Subscription, error) {
+	const query = `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at ASC`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// UpdateSubscription writes subscription updates.
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	const query = `
+UPDATE webhook_subscriptions
+SET url = $2,
+    events = $3,
+    description = $4,
+    secret = $5,
+    active = $6,
+    payload_version = $7,
+    updated_at = $8
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query,
+		sub.ID,
+		sub.URL,
+		sub.Events,
+		sub.Description,
+		sub.Secret,
+		sub.Active,
+		sub.Version,
+		sub.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription; its deliveries cascade.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CreateDelivery queues a delivery.
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, d *domain.Delivery) error {
+	const query = `
+INSERT INTO webhook_deliveries (` + deliveryColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+`
+	_, err := r.pool.Exec(ctx, query,
+		d.ID,
+		d.SubscriptionID,
+		d.EventID,
+		d.EventType,
+		d.Payload,
+		d.Status,
+		d.Attempts,
+		d.LastStatusCode,
+		d.LastError,
+		d.NextAttemptAt,
+		d.CreatedAt,
+		d.UpdatedAt,
+	)
+	return err
+}
+
+// GetDelivery fetches a delivery by id.
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*domain.Delivery, error) {
+	const query = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	d, err := scanDelivery(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// ListDeliveries returns the newest deliveries for a subscription.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*domain.Delivery, error) {
+	const query = `
+SELECT ` + deliveryColumns + `
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+	return r.queryDeliveries(ctx, query, subscriptionID, limit)
+}
+
+// UpdateDelivery records the outcome of an attempt.
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d *domain.Delivery) error {
+	const query = `
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = $3,
+    last_status_code = $4,
+    last_error = $5,
+    next_attempt_at = $6,
+    updated_at = $7
+WHERE id = $1
+`
+	tag, err := r.pool.Exec(ctx, query,
+		d.ID,
+		d.Status,
+		d.Attempts,
+		d.LastStatusCode,
+		d.LastError,
+		d.NextAttemptAt,
+		d.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+// ClaimDue leases due deliveries with SKIP LOCKED so replicas share the queue.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.Delivery, error) {
+	const query = `
+UPDATE webhook_deliveries
+SET next_attempt_at = $2
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= $1
+    ORDER BY next_attempt_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + deliveryColumns
+	return r.queryDeliveries(ctx, query, now, now.Add(lease), limit)
+}
+
+func (r *WebhookRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]*domain.Delivery, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanSubscription(row pgx.Row) (*domain.Subscription, error) {
+	var s domain.Subscription
+	err := row.Scan(
+		&s.ID,
+		&s.URL,
+		&s.Events,
+		&s.Description,
+		&s.Secret,
+		&s.Active,
+		&s.Version,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanDelivery(row pgx.Row) (*domain.Delivery, error) {
+	var d domain.Delivery
+	err := row.Scan(
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.NextAttemptAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,301 @@
+// Package sentry implements errreport.Reporter on top of Sentry's envelope
+// HTTP API, without the Sentry SDK.
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backoffice/backend/internal/errreport"
+)
+
+// Options configure the client.
+type Options struct {
+	DSN         string
+	Environment string
+	Release     string
+	// QueueSize bounds reports waiting to be sent; extra ones are dropped.
+	QueueSize int
+}
+
+// Client sends reports to Sentry from a background goroutine.
+type Client struct {
+	endpoint   string
+	auth       string
+	dsn        string
+	env        string
+	release    string
+	serverName string
+	http       *http.Client
+	queue      chan []byte
+	pending    sync.WaitGroup
+}
+
+var _ errreport.Reporter = (*Client)(nil)
+
+// New parses the DSN (https://<key>@<host>/<project id>) and starts the
+// sender.
+func New(opts Options) (*Client, error) {
+	u, err := url.Parse(opts.DSN)
+	if err != nil || u.User == nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN")
+	}
+	projectID := strings.Trim(u.Path, "/")
+	prefix := ""
+	if i := strings.LastIndex(projectID, "/"); i >= 0 {
+		prefix, projectID = "/"+projectID[:i], projectID[i+1:]
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: missing project id")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	host, _ := os.Hostname()
+	c := &Client{
+		endpoint:   fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, projectID),
+		auth:       "Sentry sentry_version=7, sentry_client=backoffice-api/1, sentry_key=" + u.User.Username(),
+		dsn:        opts.DSN,
+		env:        opts.Environment,
+		release:    opts.Release,
+		serverName: host,
+		http:       &http.Client{Timeout: 10 * time.Second},
+		queue:      make(chan []byte, opts.QueueSize),
+	}
+	go c.run()
+	return c, nil
+}
+
+// Report converts r to a Sentry event and queues it.
+func (c *Client) Report(_ context.Context, r errreport.Report) {
+	envelope, err := c.envelope(r)
+	if err != nil {
+		log.Printf("sentry: encoding report: %v", err)
+		return
+	}
+	c.pending.Add(1)
+	select {
+	case c.queue <- envelope:
+	default:
+		c.pending.Done()
+		log.Printf("sentry: queue full, dropping report: %v", r.Err)
+	}
+}
+
+// Flush waits up to timeout for queued reports to be sent.
+func (c *Client) Flush(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		c.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+func (c *Client) run() {
+	for envelope := range c.queue {
+		if err := c.send(envelope); err != nil {
+			log.Printf("sentry: %v", err)
+		}
+		c.pending.Done()
+	}
+}
+
+func (c *Client) send(envelope []byte) error {
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", c.auth)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ingest responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	User        *user             `json:"user,omitempty"`
+	Request     *request          `json:"request,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Exception   exceptions        `json:"exception"`
+}
+
+type user struct {
+	ID        string `json:"id,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+type request struct {
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+type exceptions struct {
+	Values []exception `json:"values"`
+}
+
+type exception struct {
+	Type       string     `json:"type"`
+	Value      string     `json:"value"`
+	Stacktrace stacktrace `json:"stacktrace"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+func (c *Client) envelope(r errreport.Report) ([]byte, error) {
+	id := newEventID()
+	tags := map[string]string{}
+	for k, v := range r.Tags {
+		tags[k] = v
+	}
+	if r.Scope.RequestID != "" {
+		tags["request_id"] = r.Scope.RequestID
+	}
+	if r.Scope.Route != "" {
+		tags["route"] = r.Scope.Route
+	}
+
+	ev := event{
+		EventID:     id,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       r.Level,
+		Platform:    "go",
+		Environment: c.env,
+		Release:     c.release,
+		ServerName:  c.serverName,
+		Transaction: strings.TrimSpace(r.Scope.Method + " " + r.Scope.Route),
+		Tags:        tags,
+		Exception: exceptions{Values: []exception{{
+			Type:       fmt.Sprintf("%T", r.Err),
+			Value:      r.Err.Error(),
+			Stacktrace: stacktrace{Frames: frames(r.Stack)},
+		}}},
+	}
+	if r.Scope.UserID != "" || r.Scope.ClientIP != "" {
+		ev.User = &user{ID: r.Scope.UserID, IPAddress: r.Scope.ClientIP}
+	}
+	if r.Scope.Method != "" {
+		ev.Request = &request{Method: r.Scope.Method, URL: r.Scope.Route}
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := json.Marshal(map[string]string{"event_id": id, "dsn": c.dsn, "sent_at": ev.Timestamp})
+	item, _ := json.Marshal(map[string]any{"type": "event", "length": len(body)})
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteByte('\n')
+	buf.Write(item)
+	buf.WriteByte('\n')
+	buf.Write(body)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// debugStackLine matches the "\t/path/file.go:123 +0x45" lines of
+// runtime/debug.Stack output.
+var debugStackLine = regexp.MustCompile(`^\t(.+):(\d+)`)
+
+// frames builds Sentry frames (oldest call first) from a panic stack, or
+// from the current goroutine when stack is empty.
+func frames(stack []byte) []frame {
+	var out []frame
+	if len(stack) > 0 {
+		lines := strings.Split(string(stack), "\n")
+		for i := 1; i+1 < len(lines); i += 2 {
+			m := debugStackLine.FindStringSubmatch(lines[i+1])
+			if m == nil {
+				continue
+			}
+			fn := lines[i]
+			if j := strings.LastIndex(fn, "("); j > 0 {
+				fn = fn[:j]
+			}
+			line, _ := strconv.Atoi(m[2])
+			out = append(out, newFrame(fn, m[1], line))
+		}
+	} else {
+		pcs := make([]uintptr, 32)
+		n := runtime.Callers(5, pcs)
+		iter := runtime.CallersFrames(pcs[:n])
+		for {
+			f, more := iter.Next()
+			out = append(out, newFrame(f.Function, f.File, f.Line))
+			if !more {
+				break
+			}
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+func newFrame(function, file string, line int) frame {
+	module := ""
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		if j := strings.Index(function[i:], "."); j >= 0 {
+			module, function = function[:i+j], function[i+j+1:]
+		}
+	} else if j := strings.Index(function, "."); j >= 0 {
+		module, function = function[:j], function[j+1:]
+	}
+	return frame{
+		Function: function,
+		Module:   module,
+		Filename: file,
+		Lineno:   line,
+		InApp:    strings.HasPrefix(module, "backoffice/backend"),
+	}
+}
+
+func newEventID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
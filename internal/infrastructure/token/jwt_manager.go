@@ -1,9 +1,13 @@
 package token
 
 import (
+	"context"
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
+	domain "backoffice/backend/internal/domain/auth"
 	usecase "backoffice/backend/internal/usecase/auth"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,86 +18,120 @@ type JWTManager struct {
 	secret     []byte
 	expiration time.Duration
 	issuer     string
+	audience   string
 }
 
 // NewJWTManager constructs a manager with the provided secret and expiration.
-func NewJWTManager(secret string, expiration time.Duration, issuer string) *JWTManager {
+// When audience is set it is embedded in every token and required on
+// validation.
+func NewJWTManager(secret string, expiration time.Duration, issuer, audience string) *JWTManager {
 	return &JWTManager{
 		secret:     []byte(secret),
 		expiration: expiration,
 		issuer:     issuer,
+		audience:   audience,
 	}
 }
 
 // Ensure JWTManager implements the TokenManager interface.
 var _ usecase.TokenManager = (*JWTManager)(nil)
 
-// Claims represents token claims.
+// Claims represents token claims. Scope is space-separated, as in OAuth 2.0.
 type Claims struct {
-	UserID string `json:"uid"`
+	UserID   string `json:"uid"`
+	Version  int    `json:"ver,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// Generate creates a signed JWT containing the user id.
-func (m *JWTManager) Generate(userID string) (string, error) {
+func (c *Claims) identity() usecase.Identity {
+	identity := usecase.Identity{
+		UserID:  c.UserID,
+		Grant:   domain.Grant{ClientID: c.ClientID, Scopes: strings.Fields(c.Scope)},
+		Version: c.Version,
+	}
+	if c.ExpiresAt != nil {
+		identity.ExpiresAt = c.ExpiresAt.Time
+	}
+	return identity
+}
+
+// Generate creates a signed JWT containing the user id, token version and
+// grant.
+func (m *JWTManager) Generate(_ context.Context, user *domain.User, grant domain.Grant) (string, error) {
 	now := time.Now().UTC()
 	claims := Claims{
-		UserID: userID,
+		UserID:   user.ID,
+		Version:  user.TokenVersion,
+		ClientID: grant.ClientID,
+		Scope:    strings.Join(grant.Scopes, " "),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.expiration)),
 		},
 	}
+	if m.audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.audience}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(m.secret)
 }
 
-// Validate parses and validates the token returning the user id when valid.
-func (m *JWTManager) Validate(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return m.secret, nil
-	})
+// Validate parses and validates the token returning the identity when valid.
+func (m *JWTManager) Validate(_ context.Context, tokenString string) (usecase.Identity, error) {
+	var opts []jwt.ParserOption
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	if m.audience != "" {
+		opts = append(opts, jwt.WithAudience(m.audience))
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.key, opts...)
 	if err != nil {
-		return "", err
+		return usecase.Identity{}, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return "", errors.New("invalid token claims")
+		return usecase.Identity{}, errors.New("invalid token claims")
 	}
-	return claims.UserID, nil
+	return claims.identity(), nil
 }
 
-// ExtractUserID returns the user identifier embedded in the token without enforcing expiry.
-func (m *JWTManager) ExtractUserID(tokenString string) (string, error) {
+// Extract returns the identity embedded in the token without enforcing expiry.
+func (m *JWTManager) Extract(_ context.Context, tokenString string) (usecase.Identity, error) {
 	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
-	token, err := parser.ParseWithClaims(tokenString, &Claims{}, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return m.secret, nil
-	})
+	token, err := parser.ParseWithClaims(tokenString, &Claims{}, m.key)
 	if err != nil {
-		return "", err
+		return usecase.Identity{}, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		return "", errors.New("invalid token claims")
+		return usecase.Identity{}, errors.New("invalid token claims")
 	}
 
 	if claims.UserID == "" {
-		return "", errors.New("user id missing in token")
+		return usecase.Identity{}, errors.New("user id missing in token")
 	}
 
 	if m.issuer != "" && claims.Issuer != m.issuer {
-		return "", errors.New("invalid token issuer")
+		return usecase.Identity{}, errors.New("invalid token issuer")
+	}
+
+	if m.audience != "" && !slices.Contains(claims.Audience, m.audience) {
+		return usecase.Identity{}, errors.New("invalid token audience")
 	}
 
-	return claims.UserID, nil
+	return claims.identity(), nil
+}
+
+func (m *JWTManager) key(t *jwt.Token) (any, error) {
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, errors.New("unexpected signing method")
+	}
+	return m.secret, nil
 }
@@ -0,0 +1,57 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/auth"
+)
+
+func FuzzJWTManagerValidate(f *testing.F) {
+	ctx := context.Background()
+	manager := NewJWTManager("fuzz-secret-0123456789abcdef", time.Hour, "backoffice", "api")
+	valid, err := manager.Generate(ctx, &domain.User{ID: "user-1", TokenVersion: 2}, domain.Grant{ClientID: "cli", Scopes: []string{"products:read"}})
+	if err != nil {
+		f.Fatal(err)
+	}
+	expired, err := NewJWTManager("fuzz-secret-0123456789abcdef", -time.Hour, "backoffice", "api").Generate(ctx, &domain.User{ID: "user-1"}, domain.Grant{})
+	if err != nil {
+		f.Fatal(err)
+	}
+	foreign, err := NewJWTManager("another-secret", time.Hour, "backoffice", "api").Generate(ctx, &domain.User{ID: "user-1"}, domain.Grant{})
+	if err != nil {
+		f.Fatal(err)
+	}
+	otherIssuer, err := NewJWTManager("fuzz-secret-0123456789abcdef", time.Hour, "elsewhere", "api").Generate(ctx, &domain.User{ID: "user-1"}, domain.Grant{})
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, seed := range []string{
+		valid,
+		expired,
+		foreign,
+		otherIssuer,
+		"",
+		"a.b.c",
+		// alg "none" with a user id.
+		"eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJ1aWQiOiJ1c2VyLTEifQ.",
+		valid + "x",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, tokenString string) {
+		identity, err := manager.Validate(ctx, tokenString)
+		if err != nil {
+			return
+		}
+		// Only tokens the manager signed can validate, and those name a
+		// user and expire.
+		if identity.UserID == "" || identity.ExpiresAt.IsZero() {
+			t.Fatalf("Validate(%q) accepted %+v", tokenString, identity)
+		}
+		if _, err := manager.Extract(ctx, tokenString); err != nil {
+			t.Fatalf("Validate accepted %q but Extract rejects it: %v", tokenString, err)
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/auth"
+	usecase "backoffice/backend/internal/usecase/auth"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// pasetoHeader prefixes every token: PASETO version 4, local (symmetric
+// authenticated encryption) purpose.
+const pasetoHeader = "v4.local."
+
+// PASETOKeySize is the length of a v4.local key.
+const PASETOKeySize = 32
+
+// PASETOManager issues and validates PASETO v4.local tokens. Unlike JWTs
+// they have no algorithm header to get wrong and their claims are
+// encrypted, not just signed.
+type PASETOManager struct {
+	key        []byte
+	expiration time.Duration
+	issuer     string
+	audience   string
+}
+
+// NewPASETOManager constructs a manager with a 32-byte key. When audience is
+// set it is embedded in every token and required on validation.
+func NewPASETOManager(key []byte, expiration time.Duration, issuer, audience string) (*PASETOManager, error) {
+	if len(key) != PASETOKeySize {
+		return nil, fmt.Errorf("paseto key must be %d bytes, got %d", PASETOKeySize, len(key))
+	}
+	return &PASETOManager{
+		key:        slices.Clone(key),
+		expiration: expiration,
+		issuer:     issuer,
+		audience:   audience,
+	}, nil
+}
+
+// Ensure PASETOManager implements the TokenManager interface.
+var _ usecase.TokenManager = (*PASETOManager)(nil)
+
+// pasetoClaims is the token payload, using the registered claim names from
+// the PASETO specification.
+type pasetoClaims struct {
+	Subject   string    `json:"sub"`
+	Version   int       `json:"ver,omitempty"`
+	Issuer    string    `json:"iss,omitempty"`
+	Audience  string    `json:"aud,omitempty"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
+}
+
+// Generate creates an encrypted token containing the user id, token version
+// and grant.
+func (m *PASETOManager) Generate(_ context.Context, user *domain.User, grant domain.Grant) (string, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	payload, err := json.Marshal(pasetoClaims{
+		Subject:   user.ID,
+		Version:   user.TokenVersion,
+		Issuer:    m.issuer,
+		Audience:  m.audience,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(m.expiration),
+		ClientID:  grant.ClientID,
+		Scope:     strings.Join(grant.Scopes, " "),
+	})
+	if err != nil {
+		return "", err
+	}
+	return m.encrypt(payload)
+}
+
+// Validate decrypts the token and checks its claims, returning the identity
+// when valid.
+func (m *PASETOManager) Validate(_ context.Context, token string) (usecase.Identity, error) {
+	claims, err := m.open(token)
+	if err != nil {
+		return usecase.Identity{}, err
+	}
+	if !time.Now().Before(claims.ExpiresAt) {
+		return usecase.Identity{}, errors.New("token expired")
+	}
+	return claims.identity(), nil
+}
+
+// Extract returns the identity in the token without enforcing expiry.
+func (m *PASETOManager) Extract(_ context.Context, token string) (usecase.Identity, error) {
+	claims, err := m.open(token)
+	if err != nil {
+		return usecase.Identity{}, err
+	}
+	return claims.identity(), nil
+}
+
+func (c *pasetoClaims) identity() usecase.Identity {
+	return usecase.Identity{
+		UserID:    c.Subject,
+		Grant:     domain.Grant{ClientID: c.ClientID, Scopes: strings.Fields(c.Scope)},
+		Version:   c.Version,
+		ExpiresAt: c.ExpiresAt,
+	}
+}
+
+// open decrypts the token and checks every claim except expiry.
+func (m *PASETOManager) open(token string) (*pasetoClaims, error) {
+	payload, err := m.decrypt(token)
+	if err != nil {
+		return nil, err
+	}
+	var claims pasetoClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("invalid token claims")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("user id missing in token")
+	}
+	if m.issuer != "" && claims.Issuer != m.issuer {
+		return nil, errors.New("invalid token issuer")
+	}
+	if m.audience != "" && claims.Audience != m.audience {
+		return nil, errors.New("invalid token audience")
+	}
+	return &claims, nil
+}
+
+// encrypt seals message for the manager's key under a random nonce, without
+// footer or implicit assertion.
+func (m *PASETOManager) encrypt(message []byte) (string, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return sealLocal(m.key, nonce, message, nil, nil)
+}
+
+// decrypt authenticates and decrypts a token. Tokens with a footer are
+// rejected since this manager never issues them.
+func (m *PASETOManager) decrypt(token string) ([]byte, error) {
+	message, footer, err := openLocal(m.key, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(footer) != 0 {
+		return nil, errors.New("unexpected token footer")
+	}
+	return message, nil
+}
+
+// sealLocal implements v4.local encryption: message is encrypted with
+// XChaCha20 under keys derived from key and nonce, and the header, nonce,
+// ciphertext, footer and implicit assertion are authenticated with keyed
+// BLAKE2b.
+func sealLocal(key, nonce, message, footer, implicit []byte) (string, error) {
+	encKey, counterNonce, authKey, err := splitKeys(key, nonce)
+	if err != nil {
+		return "", err
+	}
+	stream, err := chacha20.NewUnauthenticatedCipher(encKey, counterNonce)
+	if err != nil {
+		return "", err
+	}
+	ciphertext := make([]byte, len(message))
+	stream.XORKeyStream(ciphertext, message)
+
+	tag, err := mac(authKey, preAuthEncode([]byte(pasetoHeader), nonce, ciphertext, footer, implicit))
+	if err != nil {
+		return "", err
+	}
+	token := pasetoHeader + base64.RawURLEncoding.EncodeToString(slices.Concat(nonce, ciphertext, tag))
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token, nil
+}
+
+// openLocal authenticates and decrypts a v4.local token, returning the
+// message and the footer it was sealed with.
+func openLocal(key []byte, token string, implicit []byte) (message, footer []byte, err error) {
+	encoded, ok := strings.CutPrefix(token, pasetoHeader)
+	if !ok {
+		return nil, nil, errors.New("not a v4.local token")
+	}
+	encoded, encodedFooter, hasFooter := strings.Cut(encoded, ".")
+	if hasFooter {
+		if footer, err = base64.RawURLEncoding.DecodeString(encodedFooter); err != nil || len(footer) == 0 {
+			return nil, nil, errors.New("malformed token footer")
+		}
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(body) < 32+32 {
+		return nil, nil, errors.New("malformed token")
+	}
+	nonce, ciphertext, tag := body[:32], body[32:len(body)-32], body[len(body)-32:]
+
+	encKey, counterNonce, authKey, err := splitKeys(key, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	expected, err := mac(authKey, preAuthEncode([]byte(pasetoHeader), nonce, ciphertext, footer, implicit))
+	if err != nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare(tag, expected) != 1 {
+		return nil, nil, errors.New("invalid token signature")
+	}
+
+	stream, err := chacha20.NewUnauthenticatedCipher(encKey, counterNonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	message = make([]byte, len(ciphertext))
+	stream.XORKeyStream(message, ciphertext)
+	return message, footer, nil
+}
+
+// splitKeys derives the per-token encryption key, XChaCha20 nonce and
+// authentication key from the shared key and the token nonce.
+func splitKeys(key, nonce []byte) (encKey, counterNonce, authKey []byte, err error) {
+	h, err := blake2b.New(56, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	h.Write([]byte("paseto-encryption-key"))
+	h.Write(nonce)
+	tmp := h.Sum(nil)
+
+	authKey, err = mac(key, slices.Concat([]byte("paseto-auth-key-for-aead"), nonce))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tmp[:32], tmp[32:], authKey, nil
+}
+
+func mac(key, message []byte) ([]byte, error) {
+	h, err := blake2b.New256(key)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(message)
+	return h.Sum(nil), nil
+}
+
+// preAuthEncode is PASETO's PAE: the piece count and each piece's length as
+// little-endian 64-bit integers with the top bit cleared, followed by the
+// piece.
+func preAuthEncode(pieces ...[]byte) []byte {
+	le64 := func(n int) []byte {
+		return binary.LittleEndian.AppendUint64(nil, uint64(n)&(1<<63-1))
+	}
+	out := le64(len(pieces))
+	for _, piece := range pieces {
+		out = append(out, le64(len(piece))...)
+		out = append(out, piece...)
+	}
+	return out
+}
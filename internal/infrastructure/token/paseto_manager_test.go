@@ -0,0 +1,245 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/auth"
+)
+
+// pasetoVectorKey is the key shared by the v4.local vectors of the PASETO
+// specification's test suite (paseto-standard/test-vectors, v4.json).
+const pasetoVectorKey = "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"
+
+func TestPASETOLocalVectors(t *testing.T) {
+	const (
+		zeroNonce   = "0000000000000000000000000000000000000000000000000000000000000000"
+		vectorNonce = "df654812bac492663825520ba2f6e67cf5ca5bdc13d4e7507a98cc4c2fcc3ad8"
+		secret      = `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`
+		hidden      = `{"data":"this is a hidden message","exp":"2022-01-01T00:00:00+00:00"}`
+		kid         = `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+	)
+	key, err := hex.DecodeString(pasetoVectorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		nonce    string
+		payload  string
+		footer   string
+		implicit string
+		token    string
+	}{
+		{
+			name:    "4-E-1",
+			nonce:   zeroNonce,
+			payload: secret,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg",
+		},
+		{
+			name:    "4-E-2",
+			nonce:   zeroNonce,
+			payload: hidden,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvS2csCgglvpk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XIemu9chy3WVKvRBfg6t8wwYHK0ArLxxfZP73W_vfwt5A",
+		},
+		{
+			name:    "4-E-3",
+			nonce:   vectorNonce,
+			payload: secret,
+			token:   "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t6-tyebyWG6Ov7kKvBdkrrAJ837lKP3iDag2hzUPHuMKA",
+		},
+		{
+			name:    "4-E-4",
+			nonce:   vectorNonce,
+			payload: hidden,
+			token:   "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t4gt6TiLm55vIH8c_lGxxZpE3AWlH4WTR0v45nsWoU3gQ",
+		},
+		{
+			name:    "4-E-5",
+			nonce:   vectorNonce,
+			payload: secret,
+			footer:  kid,
+			token:   "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t4x-RMNXtQNbz7FvFZ_G-lFpk5RG3EOrwDL6CgDqcerSQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+		{
+			name:    "4-E-6",
+			nonce:   vectorNonce,
+			payload: hidden,
+			footer:  kid,
+			token:   "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t6pWSA5HX2wjb3P-xLQg5K5feUCX4P2fpVK3ZLWFbMSxQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+		{
+			name:     "4-E-7",
+			nonce:    vectorNonce,
+			payload:  secret,
+			footer:   kid,
+			implicit: `{"test-vector":"4-E-7"}`,
+			token:    "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t40KCCWLA7GYL9KFHzKlwY9_RnIfRrMQpueydLEAZGGcA.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+		{
+			name:     "4-E-8",
+			nonce:    vectorNonce,
+			payload:  hidden,
+			footer:   kid,
+			implicit: `{"test-vector":"4-E-8"}`,
+			token:    "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t5uvqQbMGlLLNYBc7A6_x7oqnpUK5WLvj24eE4DVPDZjw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+		{
+			name:     "4-E-9",
+			nonce:    vectorNonce,
+			payload:  hidden,
+			footer:   "arbitrary-string-that-isn't-json",
+			implicit: `{"test-vector":"4-E-9"}`,
+			token:    "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t6tybdlmnMwcDMw0YxA_gFSE_IUWl78aMtOepFYSWYfQA.YXJiaXRyYXJ5LXN0cmluZy10aGF0LWlzbid0LWpzb24",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			nonce, err := hex.DecodeString(tc.nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sealed, err := sealLocal(key, nonce, []byte(tc.payload), []byte(tc.footer), []byte(tc.implicit))
+			if err != nil {
+				t.Fatalf("sealLocal: %v", err)
+			}
+			if sealed != tc.token {
+				t.Fatalf("sealLocal = %s\nwant       %s", sealed, tc.token)
+			}
+
+			message, footer, err := openLocal(key, tc.token, []byte(tc.implicit))
+			if err != nil {
+				t.Fatalf("openLocal: %v", err)
+			}
+			if string(message) != tc.payload || string(footer) != tc.footer {
+				t.Fatalf("openLocal = %q, footer %q", message, footer)
+			}
+			if tc.implicit != "" {
+				if _, _, err := openLocal(key, tc.token, nil); err == nil {
+					t.Fatal("openLocal without the implicit assertion: want an error")
+				}
+			}
+		})
+	}
+}
+
+func TestPASETOManager(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{7}, PASETOKeySize)
+	manager, err := NewPASETOManager(key, time.Hour, "backoffice", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{ID: "user-1", TokenVersion: 3}
+	grant := domain.Grant{ClientID: "cli", Scopes: []string{"products:read", "orders:write"}}
+	token, err := manager.Generate(ctx, user, grant)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	identity, err := manager.Validate(ctx, token)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if identity.UserID != "user-1" || identity.Version != 3 || identity.Grant.ClientID != "cli" ||
+		strings.Join(identity.Grant.Scopes, " ") != "products:read orders:write" {
+		t.Fatalf("identity = %+v", identity)
+	}
+	if !identity.ExpiresAt.After(time.Now()) {
+		t.Fatalf("ExpiresAt = %v, want in the future", identity.ExpiresAt)
+	}
+
+	// retoken re-encodes token after changing its raw body.
+	retoken := func(change func(body []byte)) string {
+		body, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, pasetoHeader))
+		if err != nil {
+			t.Fatal(err)
+		}
+		change(body)
+		return pasetoHeader + base64.RawURLEncoding.EncodeToString(body)
+	}
+	withFooter, err := sealLocal(key, bytes.Repeat([]byte{1}, 32), []byte(`{"sub":"user-1"}`), []byte(`{"kid":"k1"}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := NewPASETOManager(bytes.Repeat([]byte{8}, PASETOKeySize), time.Hour, "backoffice", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherIssuer, err := NewPASETOManager(key, time.Hour, "elsewhere", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := otherKey.Generate(ctx, user, grant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	misissued, err := otherIssuer.Generate(ctx, user, grant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, bad := range map[string]string{
+		"wrong key":           foreign,
+		"wrong issuer":        misissued,
+		"tampered nonce":      retoken(func(body []byte) { body[0] ^= 1 }),
+		"tampered ciphertext": retoken(func(body []byte) { body[32] ^= 1 }),
+		"tampered tag":        retoken(func(body []byte) { body[len(body)-1] ^= 1 }),
+		"truncated":           token[:len(token)-4],
+		"added footer":        token + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"k1"}`)),
+		"empty footer":        token + ".",
+		"authentic footer":    withFooter,
+		"tampered footer":     withFooter[:strings.LastIndex(withFooter, ".")+1] + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"k2"}`)),
+		"wrong purpose":       "v4.public." + strings.TrimPrefix(token, pasetoHeader),
+		"wrong version":       "v3.local." + strings.TrimPrefix(token, pasetoHeader),
+		"jwt":                 "eyJhbGciOiJIUzI1NiJ9.eyJ1aWQiOiJ1c2VyLTEifQ.c2ln",
+		"not base64":          pasetoHeader + "!!!",
+		"empty":               "",
+		"header only":         pasetoHeader,
+		"nonce and tag only":  pasetoHeader + base64.RawURLEncoding.EncodeToString(make([]byte, 63)),
+		"uppercase header":    strings.ToUpper(pasetoHeader) + strings.TrimPrefix(token, pasetoHeader),
+		"leading whitespace":  " " + token,
+	} {
+		if _, err := manager.Validate(ctx, bad); err == nil {
+			t.Errorf("%s: Validate accepted %q", name, bad)
+		}
+		if _, err := manager.Extract(ctx, bad); err == nil {
+			t.Errorf("%s: Extract accepted %q", name, bad)
+		}
+	}
+}
+
+func TestPASETOManagerExpired(t *testing.T) {
+	ctx := context.Background()
+	manager, err := NewPASETOManager(bytes.Repeat([]byte{7}, PASETOKeySize), -time.Minute, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := manager.Generate(ctx, &domain.User{ID: "user-1"}, domain.Grant{})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if _, err := manager.Validate(ctx, token); err == nil {
+		t.Fatal("Validate accepted an expired token")
+	}
+	identity, err := manager.Extract(ctx, token)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if identity.UserID != "user-1" || identity.ExpiresAt.After(time.Now()) {
+		t.Fatalf("Extract = %+v, want the expired identity", identity)
+	}
+}
+
+func TestNewPASETOManagerKeySize(t *testing.T) {
+	for _, size := range []int{0, 16, 31, 33, 64} {
+		if _, err := NewPASETOManager(make([]byte, size), time.Hour, "", ""); err == nil {
+			t.Errorf("%d-byte key accepted", size)
+		}
+	}
+}
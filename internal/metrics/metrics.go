@@ -0,0 +1,295 @@
+// Package metrics is a small, dependency-free metrics registry that renders
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default is the process-wide registry served at /metrics.
+var Default = NewRegistry()
+
+// Registry holds named metric families.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+type family interface {
+	write(w io.Writer, name string)
+}
+
+// NewRegistry constructs an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+func (r *Registry) register(name string, f family) family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.families[name]; ok {
+		return existing
+	}
+	r.families[name] = f
+	return f
+}
+
+// WritePrometheus renders every metric in the text exposition format.
+func (r *Registry) WritePrometheus(w io.Writer) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make(map[string]family, len(r.families))
+	for name, f := range r.families {
+		families[name] = f
+	}
+	r.mu.Unlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		families[name].write(w, name)
+	}
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels.
+type CounterVec struct {
+	help   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec registers (or returns the existing) counter called name.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return r.register(name, &CounterVec{help: help, labels: labels, values: make(map[string]float64)}).(*CounterVec)
+}
+
+// Inc adds one to the series identified by labelValues.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta (which must not be negative) to the series.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	key := seriesKey(c.labels, labelValues)
+	c.mu.Lock()
+	c.values[key] += delta
+	c.mu.Unlock()
+}
+
+// Total sums every series of the counter.
+func (c *CounterVec) Total() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total float64
+	for _, v := range c.values {
+		total += v
+	}
+	return total
+}
+
+// Value returns the series identified by labelValues, zero if it was never
+// incremented.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := seriesKey(c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+func (c *CounterVec) write(w io.Writer, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, c.help, name)
+	writeSeries(w, name, c.values)
+}
+
+// Gauge holds a value set by the application, such as a figure refreshed by
+// a periodic job.
+type Gauge struct {
+	help  string
+	mu    sync.Mutex
+	value float64
+}
+
+// NewGauge registers (or returns the existing) gauge called name.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	return r.register(name, &Gauge{help: help}).(*Gauge)
+}
+
+// Set replaces the gauge's value.
+func (g *Gauge) Set(value float64) {
+	g.mu.Lock()
+	g.value = value
+	g.mu.Unlock()
+}
+
+func (g *Gauge) write(w io.Writer, name string) {
+	g.mu.Lock()
+	value := g.value
+	g.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, g.help, name, name, formatValue(value))
+}
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct {
+	help   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewGaugeVec registers (or returns the existing) gauge vector called name.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return r.register(name, &GaugeVec{help: help, labels: labels, values: make(map[string]float64)}).(*GaugeVec)
+}
+
+// Set replaces the value of the series identified by labelValues.
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	key := seriesKey(g.labels, labelValues)
+	g.mu.Lock()
+	g.values[key] = value
+	g.mu.Unlock()
+}
+
+func (g *GaugeVec) write(w io.Writer, name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, g.help, name)
+	writeSeries(w, name, g.values)
+}
+
+// GaugeFunc reports a value computed at scrape time.
+type GaugeFunc struct {
+	help string
+	fn   func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on every scrape.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	return r.register(name, &GaugeFunc{help: help, fn: fn}).(*GaugeFunc)
+}
+
+func (g *GaugeFunc) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, g.help, name, name, formatValue(g.fn()))
+}
+
+// HistogramVec counts observations into cumulative buckets, partitioned by
+// labels.
+type HistogramVec struct {
+	help    string
+	labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// DefaultBuckets suits request and query latencies measured in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// NewHistogramVec registers (or returns the existing) histogram called name.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	return r.register(name, &HistogramVec{help: help, labels: labels, buckets: buckets, series: make(map[string]*histogram)}).(*HistogramVec)
+}
+
+// Observe records value in the series identified by labelValues.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := seriesKey(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, upper := range h.buckets {
+		if value <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *HistogramVec) write(w io.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, h.help, name)
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(key, "le", formatValue(upper)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, braces(key), formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, braces(key), s.count)
+	}
+}
+
+// seriesKey renders label pairs as `a="x",b="y"`; missing values are empty.
+func seriesKey(labels, values []string) string {
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		parts[i] = label + "=" + strconv.Quote(value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func braces(key string) string {
+	if key == "" {
+		return ""
+	}
+	return "{" + key + "}"
+}
+
+func withLabel(key, label, value string) string {
+	pair := label + "=" + strconv.Quote(value)
+	if key == "" {
+		return "{" + pair + "}"
+	}
+	return "{" + key + "," + pair + "}"
+}
+
+func writeSeries(w io.Writer, name string, values map[string]float64) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", name, braces(key), formatValue(values[key]))
+	}
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Calls counts the method calls a double received. It is safe for
+// concurrent use.
+type Calls struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Count returns how often method was called.
+func (c *Calls) Count(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[method]
+}
+
+func (c *Calls) record(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[method]++
+}
+
+func notStubbed(double, method string) string {
+	return fmt.Sprintf("mocks: %s.%s called without a %sFunc or Fallback", double, method, method)
+}
@@ -0,0 +1,7 @@
+// Package mocks provides hand-written test doubles for the domain
+// repositories and the token manager. Each double has a XFunc field per
+// method: a set field answers the call, an unset one defers to Fallback,
+// typically an in-memory repository, and with neither the call panics
+// naming the method. Calls are counted so tests can assert what a use case
+// did, not just what it returned.
+package mocks
@@ -0,0 +1,200 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	productdomain "backoffice/backend/internal/domain/product"
+)
+
+// ProductRepository is a stub of productdomain.Repository.
+type ProductRepository struct {
+	Calls
+	// Fallback answers the methods whose func is unset.
+	Fallback productdomain.Repository
+
+	CreateFunc         func(context.Context, *productdomain.Product) error
+	GetByIDFunc        func(context.Context, string) (*productdomain.Product, error)
+	GetByIDsFunc       func(context.Context, []string) ([]*productdomain.Product, error)
+	GetBySKUFunc       func(context.Context, string) (*productdomain.Product, error)
+	GetByBarcodeFunc   func(context.Context, string) (*productdomain.Product, error)
+	ListFunc           func(context.Context) ([]*productdomain.Product, error)
+	UpdateFunc         func(context.Context, *productdomain.Product, int) error
+	AdjustQuantityFunc func(context.Context, string, int, time.Time) (*productdomain.Product, error)
+	SetQuantityFunc    func(context.Context, string, int, time.Time) (*productdomain.Product, int, error)
+	DeleteFunc         func(context.Context, string) error
+	DeleteManyFunc     func(context.Context, []string) error
+	StockValuationFunc func(context.Context, *time.Time) ([]productdomain.ValuationLine, error)
+	CountLowStockFunc  func(context.Context, int) (int, error)
+	UpdatePricesFunc   func(context.Context, []productdomain.PriceChange, time.Time) error
+}
+
+var _ productdomain.Repository = (*ProductRepository)(nil)
+
+// Create implements productdomain.Repository.
+func (m *ProductRepository) Create(ctx context.Context, product *productdomain.Product) error {
+	m.record("Create")
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, product)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "Create"))
+	}
+	return m.Fallback.Create(ctx, product)
+}
+
+// GetByID implements productdomain.Repository.
+func (m *ProductRepository) GetByID(ctx context.Context, id string) (*productdomain.Product, error) {
+	m.record("GetByID")
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "GetByID"))
+	}
+	return m.Fallback.GetByID(ctx, id)
+}
+
+// GetByIDs implements productdomain.Repository.
+func (m *ProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*productdomain.Product, error) {
+	m.record("GetByIDs")
+	if m.GetByIDsFunc != nil {
+		return m.GetByIDsFunc(ctx, ids)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "GetByIDs"))
+	}
+	return m.Fallback.GetByIDs(ctx, ids)
+}
+
+// GetBySKU implements productdomain.Repository.
+func (m *ProductRepository) GetBySKU(ctx context.Context, sku string) (*productdomain.Product, error) {
+	m.record("GetBySKU")
+	if m.GetBySKUFunc != nil {
+		return m.GetBySKUFunc(ctx, sku)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "GetBySKU"))
+	}
+	return m.Fallback.GetBySKU(ctx, sku)
+}
+
+// GetByBarcode implements productdomain.Repository.
+func (m *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (*productdomain.Product, error) {
+	m.record("GetByBarcode")
+	if m.GetByBarcodeFunc != nil {
+		return m.GetByBarcodeFunc(ctx, barcode)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "GetByBarcode"))
+	}
+	return m.Fallback.GetByBarcode(ctx, barcode)
+}
+
+// List implements productdomain.Repository.
+func (m *ProductRepository) List(ctx context.Context) ([]*productdomain.Product, error) {
+	m.record("List")
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "List"))
+	}
+	return m.Fallback.List(ctx)
+}
+
+// Update implements productdomain.Repository.
+func (m *ProductRepository) Update(ctx context.Context, product *productdomain.Product, delta int) error {
+	m.record("Update")
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, product, delta)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "Update"))
+	}
+	return m.Fallback.Update(ctx, product, delta)
+}
+
+// AdjustQuantity implements productdomain.Repository.
+func (m *ProductRepository) AdjustQuantity(ctx context.Context, id string, delta int, at time.Time) (*productdomain.Product, error) {
+	m.record("AdjustQuantity")
+	if m.AdjustQuantityFunc != nil {
+		return m.AdjustQuantityFunc(ctx, id, delta, at)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "AdjustQuantity"))
+	}
+	return m.Fallback.AdjustQuantity(ctx, id, delta, at)
+}
+
+// SetQuantity implements productdomain.Repository.
+func (m *ProductRepository) SetQuantity(ctx context.Context, id string, quantity int, at time.Time) (*productdomain.Product, int, error) {
+	m.record("SetQuantity")
+	if m.SetQuantityFunc != nil {
+		return m.SetQuantityFunc(ctx, id, quantity, at)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "SetQuantity"))
+	}
+	return m.Fallback.SetQuantity(ctx, id, quantity, at)
+}
+
+// Delete implements productdomain.Repository.
+func (m *ProductRepository) Delete(ctx context.Context, id string) error {
+	m.record("Delete")
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "Delete"))
+	}
+	return m.Fallback.Delete(ctx, id)
+}
+
+// DeleteMany implements productdomain.Repository.
+func (m *ProductRepository) DeleteMany(ctx context.Context, ids []string) error {
+	m.record("DeleteMany")
+	if m.DeleteManyFunc != nil {
+		return m.DeleteManyFunc(ctx, ids)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "DeleteMany"))
+	}
+	return m.Fallback.DeleteMany(ctx, ids)
+}
+
+// StockValuation implements productdomain.Repository.
+func (m *ProductRepository) StockValuation(ctx context.Context, asOf *time.Time) ([]productdomain.ValuationLine, error) {
+	m.record("StockValuation")
+	if m.StockValuationFunc != nil {
+		return m.StockValuationFunc(ctx, asOf)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "StockValuation"))
+	}
+	return m.Fallback.StockValuation(ctx, asOf)
+}
+
+// CountLowStock implements productdomain.Repository.
+func (m *ProductRepository) CountLowStock(ctx context.Context, quantity int) (int, error) {
+	m.record("CountLowStock")
+	if m.CountLowStockFunc != nil {
+		return m.CountLowStockFunc(ctx, quantity)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "CountLowStock"))
+	}
+	return m.Fallback.CountLowStock(ctx, quantity)
+}
+
+// UpdatePrices implements productdomain.Repository.
+func (m *ProductRepository) UpdatePrices(ctx context.Context, changes []productdomain.PriceChange, at time.Time) error {
+	m.record("UpdatePrices")
+	if m.UpdatePricesFunc != nil {
+		return m.UpdatePricesFunc(ctx, changes, at)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("ProductRepository", "UpdatePrices"))
+	}
+	return m.Fallback.UpdatePrices(ctx, changes, at)
+}
@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"context"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	authusecase "backoffice/backend/internal/usecase/auth"
+)
+
+// TokenManager is a stub of authusecase.TokenManager.
+type TokenManager struct {
+	Calls
+	// Fallback answers the methods whose func is unset.
+	Fallback authusecase.TokenManager
+
+	GenerateFunc func(context.Context, *authdomain.User, authdomain.Grant) (string, error)
+	ValidateFunc func(context.Context, string) (authusecase.Identity, error)
+	ExtractFunc  func(context.Context, string) (authusecase.Identity, error)
+}
+
+var _ authusecase.TokenManager = (*TokenManager)(nil)
+
+// Generate implements authusecase.TokenManager.
+func (m *TokenManager) Generate(ctx context.Context, user *authdomain.User, grant authdomain.Grant) (string, error) {
+	m.record("Generate")
+	if m.GenerateFunc != nil {
+		return m.GenerateFunc(ctx, user, grant)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("TokenManager", "Generate"))
+	}
+	return m.Fallback.Generate(ctx, user, grant)
+}
+
+// Validate implements authusecase.TokenManager.
+func (m *TokenManager) Validate(ctx context.Context, token string) (authusecase.Identity, error) {
+	m.record("Validate")
+	if m.ValidateFunc != nil {
+		return m.ValidateFunc(ctx, token)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("TokenManager", "Validate"))
+	}
+	return m.Fallback.Validate(ctx, token)
+}
+
+// Extract implements authusecase.TokenManager.
+func (m *TokenManager) Extract(ctx context.Context, token string) (authusecase.Identity, error) {
+	m.record("Extract")
+	if m.ExtractFunc != nil {
+		return m.ExtractFunc(ctx, token)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("TokenManager", "Extract"))
+	}
+	return m.Fallback.Extract(ctx, token)
+}
@@ -0,0 +1,213 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+)
+
+// UserRepository is a stub of authdomain.UserRepository.
+type UserRepository struct {
+	Calls
+	// Fallback answers the methods whose func is unset.
+	Fallback authdomain.UserRepository
+
+	CreateFunc               func(context.Context, *authdomain.User) error
+	CreatePromotingFirstFunc func(context.Context, *authdomain.User) error
+	GetByEmailFunc           func(context.Context, string) (*authdomain.User, error)
+	GetByIDFunc              func(context.Context, string) (*authdomain.User, error)
+	GetByIDsFunc             func(context.Context, []string) ([]*authdomain.User, error)
+	ListFunc                 func(context.Context, authdomain.UserFilter) ([]*authdomain.User, error)
+	UpdateFunc               func(context.Context, *authdomain.User) error
+	DeleteFunc               func(context.Context, string) error
+	DeleteManyFunc           func(context.Context, []string) error
+	AnonymizeFunc            func(context.Context, *authdomain.User) error
+	UpdatePasswordFunc       func(context.Context, string, string, time.Time) error
+	BumpTokenVersionFunc     func(context.Context, string) error
+	CountByRoleFunc          func(context.Context, authdomain.UserRole) (int, error)
+	RecordLoginFunc          func(context.Context, string, time.Time) error
+	StatsFunc                func(context.Context, time.Time, time.Time) (*authdomain.UserStats, error)
+}
+
+var _ authdomain.UserRepository = (*UserRepository)(nil)
+
+// Create implements authdomain.UserRepository.
+func (m *UserRepository) Create(ctx context.Context, user *authdomain.User) error {
+	m.record("Create")
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, user)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "Create"))
+	}
+	return m.Fallback.Create(ctx, user)
+}
+
+// CreatePromotingFirst implements authdomain.UserRepository.
+func (m *UserRepository) CreatePromotingFirst(ctx context.Context, user *authdomain.User) error {
+	m.record("CreatePromotingFirst")
+	if m.CreatePromotingFirstFunc != nil {
+		return m.CreatePromotingFirstFunc(ctx, user)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "CreatePromotingFirst"))
+	}
+	return m.Fallback.CreatePromotingFirst(ctx, user)
+}
+
+// GetByEmail implements authdomain.UserRepository.
+func (m *UserRepository) GetByEmail(ctx context.Context, email string) (*authdomain.User, error) {
+	m.record("GetByEmail")
+	if m.GetByEmailFunc != nil {
+		return m.GetByEmailFunc(ctx, email)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "GetByEmail"))
+	}
+	return m.Fallback.GetByEmail(ctx, email)
+}
+
+// GetByID implements authdomain.UserRepository.
+func (m *UserRepository) GetByID(ctx context.Context, id string) (*authdomain.User, error) {
+	m.record("GetByID")
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "GetByID"))
+	}
+	return m.Fallback.GetByID(ctx, id)
+}
+
+// GetByIDs implements authdomain.UserRepository.
+func (m *UserRepository) GetByIDs(ctx context.Context, ids []string) ([]*authdomain.User, error) {
+	m.record("GetByIDs")
+	if m.GetByIDsFunc != nil {
+		return m.GetByIDsFunc(ctx, ids)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "GetByIDs"))
+	}
+	return m.Fallback.GetByIDs(ctx, ids)
+}
+
+// List implements authdomain.UserRepository.
+func (m *UserRepository) List(ctx context.Context, filter authdomain.UserFilter) ([]*authdomain.User, error) {
+	m.record("List")
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, filter)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "List"))
+	}
+	return m.Fallback.List(ctx, filter)
+}
+
+// Update implements authdomain.UserRepository.
+func (m *UserRepository) Update(ctx context.Context, user *authdomain.User) error {
+	m.record("Update")
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, user)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "Update"))
+	}
+	return m.Fallback.Update(ctx, user)
+}
+
+// Delete implements authdomain.UserRepository.
+func (m *UserRepository) Delete(ctx context.Context, id string) error {
+	m.record("Delete")
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "Delete"))
+	}
+	return m.Fallback.Delete(ctx, id)
+}
+
+// DeleteMany implements authdomain.UserRepository.
+func (m *UserRepository) DeleteMany(ctx context.Context, ids []string) error {
+	m.record("DeleteMany")
+	if m.DeleteManyFunc != nil {
+		return m.DeleteManyFunc(ctx, ids)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "DeleteMany"))
+	}
+	return m.Fallback.DeleteMany(ctx, ids)
+}
+
+// Anonymize implements authdomain.UserRepository.
+func (m *UserRepository) Anonymize(ctx context.Context, user *authdomain.User) error {
+	m.record("Anonymize")
+	if m.AnonymizeFunc != nil {
+		return m.AnonymizeFunc(ctx, user)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "Anonymize"))
+	}
+	return m.Fallback.Anonymize(ctx, user)
+}
+
+// UpdatePassword implements authdomain.UserRepository.
+func (m *UserRepository) UpdatePassword(ctx context.Context, id string, passwordHash string, updatedAt time.Time) error {
+	m.record("UpdatePassword")
+	if m.UpdatePasswordFunc != nil {
+		return m.UpdatePasswordFunc(ctx, id, passwordHash, updatedAt)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "UpdatePassword"))
+	}
+	return m.Fallback.UpdatePassword(ctx, id, passwordHash, updatedAt)
+}
+
+// BumpTokenVersion implements authdomain.UserRepository.
+func (m *UserRepository) BumpTokenVersion(ctx context.Context, id string) error {
+	m.record("BumpTokenVersion")
+	if m.BumpTokenVersionFunc != nil {
+		return m.BumpTokenVersionFunc(ctx, id)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "BumpTokenVersion"))
+	}
+	return m.Fallback.BumpTokenVersion(ctx, id)
+}
+
+// CountByRole implements authdomain.UserRepository.
+func (m *UserRepository) CountByRole(ctx context.Context, role authdomain.UserRole) (int, error) {
+	m.record("CountByRole")
+	if m.CountByRoleFunc != nil {
+		return m.CountByRoleFunc(ctx, role)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "CountByRole"))
+	}
+	return m.Fallback.CountByRole(ctx, role)
+}
+
+// RecordLogin implements authdomain.UserRepository.
+func (m *UserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	m.record("RecordLogin")
+	if m.RecordLoginFunc != nil {
+		return m.RecordLoginFunc(ctx, id, at)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "RecordLogin"))
+	}
+	return m.Fallback.RecordLogin(ctx, id, at)
+}
+
+// Stats implements authdomain.UserRepository.
+func (m *UserRepository) Stats(ctx context.Context, activeSince time.Time, signupsSince time.Time) (*authdomain.UserStats, error) {
+	m.record("Stats")
+	if m.StatsFunc != nil {
+		return m.StatsFunc(ctx, activeSince, signupsSince)
+	}
+	if m.Fallback == nil {
+		panic(notStubbed("UserRepository", "Stats"))
+	}
+	return m.Fallback.Stats(ctx, activeSince, signupsSince)
+}
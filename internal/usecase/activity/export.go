@@ -0,0 +1,89 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "backoffice/backend/internal/domain/activity"
+	"backoffice/backend/internal/errreport"
+)
+
+// exportBatch is how many entries Export reads per query.
+const exportBatch = 500
+
+// ErrInvalidRange rejects exports whose To is not after From.
+var ErrInvalidRange = errors.New("to must be after from")
+
+// ExportQuery selects the entries that occurred in [From, To); a zero time
+// leaves that end open. Entries recorded between the first and last match
+// are exported too, so the export is an unbroken run of the chain.
+type ExportQuery struct {
+	From time.Time
+	To   time.Time
+}
+
+// ExportSummary closes an export with what a verifier needs to check it.
+type ExportSummary struct {
+	Algorithm   string    `json:"algorithm"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Count       int       `json:"count"`
+	FirstSeq    int64     `json:"firstSeq,omitempty"`
+	LastSeq     int64     `json:"lastSeq,omitempty"`
+	// Anchor is the prevHash of the first entry: the hash of the entry
+	// recorded before the export's range, which may since have been purged.
+	Anchor string `json:"anchor"`
+	// Head is the hash of the last entry. A head kept outside the database
+	// lets a later export show that nothing up to it was rewritten.
+	Head string `json:"head"`
+	// Verified reports whether every entry's hash and link checked out.
+	// BrokenAt is then the seq of the first entry that did not.
+	Verified bool  `json:"verified"`
+	BrokenAt int64 `json:"brokenAt,omitempty"`
+}
+
+// Export passes the selected entries to emit in chain order, with actor
+// names resolved, checking each entry's hash and its link to the one before
+// as it goes. A broken chain is reported in the summary and to error
+// reporting; it does not stop the export, so the evidence is complete.
+func (s *Service) Export(ctx context.Context, q ExportQuery, emit func(*domain.Entry) error) (*ExportSummary, error) {
+	if !q.From.IsZero() && !q.To.IsZero() && !q.To.After(q.From) {
+		return nil, ErrInvalidRange
+	}
+	first, last, err := s.repo.ChainRange(ctx, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ExportSummary{Algorithm: domain.ChainAlgorithm, GeneratedAt: time.Now().UTC(), Verified: true}
+	for first != 0 && first <= last {
+		batch, err := s.repo.Chain(ctx, first, last, exportBatch)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if err := s.resolveActors(ctx, batch); err != nil {
+			return nil, err
+		}
+		for _, e := range batch {
+			if summary.Count == 0 {
+				summary.Anchor, summary.FirstSeq = e.PrevHash, e.Seq
+			}
+			linked := summary.Count == 0 || e.PrevHash == summary.Head
+			if summary.Verified && (!linked || e.ChainHash(e.PrevHash) != e.Hash) {
+				summary.Verified, summary.BrokenAt = false, e.Seq
+				errreport.Error(ctx, fmt.Errorf("activity: audit chain broken at seq %d", e.Seq), nil)
+			}
+			summary.Head, summary.LastSeq = e.Hash, e.Seq
+			summary.Count++
+			if err := emit(e); err != nil {
+				return nil, err
+			}
+		}
+		first = batch[len(batch)-1].Seq + 1
+	}
+	return summary, nil
+}
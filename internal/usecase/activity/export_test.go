@@ -0,0 +1,116 @@
+package activity_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/activity"
+	"backoffice/backend/internal/infrastructure/memory"
+	"backoffice/backend/internal/usecase/activity"
+)
+
+// tampered serves the chain of a real repository with change applied to it,
+// as someone editing the table behind the application's back would.
+type tampered struct {
+	domain.Repository
+	change func([]*domain.Entry) []*domain.Entry
+}
+
+func (r *tampered) Chain(ctx context.Context, first, last int64, limit int) ([]*domain.Entry, error) {
+	entries, err := r.Repository.Chain(ctx, first, last, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.change(entries), nil
+}
+
+func TestExportDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewActivityRepository()
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		entry := &domain.Entry{
+			ID:         fmt.Sprintf("entry-%d", i+1),
+			OccurredAt: start.Add(time.Duration(i) * time.Minute),
+			EntityType: "product",
+			Action:     "updated",
+			EntityID:   "product-1",
+			EntityName: "Rice",
+		}
+		if err := repo.Record(ctx, entry); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		change   func([]*domain.Entry) []*domain.Entry
+		count    int
+		brokenAt int64
+	}{
+		{
+			name:   "untouched",
+			change: func(entries []*domain.Entry) []*domain.Entry { return entries },
+			count:  5,
+		},
+		{
+			name:     "middle entry deleted",
+			change:   func(entries []*domain.Entry) []*domain.Entry { return slices.Delete(entries, 2, 3) },
+			count:    4,
+			brokenAt: 4,
+		},
+		{
+			name: "middle entry edited",
+			change: func(entries []*domain.Entry) []*domain.Entry {
+				entries[2].Action = "deleted"
+				return entries
+			},
+			count:    5,
+			brokenAt: 3,
+		},
+		{
+			name: "middle entry edited and rehashed",
+			change: func(entries []*domain.Entry) []*domain.Entry {
+				entries[2].ActorID = "someone-else"
+				entries[2].Hash = entries[2].ChainHash(entries[2].PrevHash)
+				return entries
+			},
+			count:    5,
+			brokenAt: 4,
+		},
+		{
+			// Entity names are not chained, so erasing one when a user is
+			// anonymized leaves the chain intact.
+			name: "middle entity name erased",
+			change: func(entries []*domain.Entry) []*domain.Entry {
+				entries[2].EntityName = ""
+				return entries
+			},
+			count: 5,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := activity.NewService(&tampered{Repository: repo, change: tc.change}, memory.NewUserRepository())
+			var seqs []int64
+			summary, err := svc.Export(ctx, activity.ExportQuery{}, func(e *domain.Entry) error {
+				seqs = append(seqs, e.Seq)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if summary.Count != tc.count || len(seqs) != tc.count {
+				t.Fatalf("exported %d entries (%v), summary count %d, want %d", len(seqs), seqs, summary.Count, tc.count)
+			}
+			if summary.Verified != (tc.brokenAt == 0) || summary.BrokenAt != tc.brokenAt {
+				t.Fatalf("verified %v, broken at %d; want broken at %d", summary.Verified, summary.BrokenAt, tc.brokenAt)
+			}
+			if summary.Algorithm != domain.ChainAlgorithm || summary.FirstSeq != 1 || summary.LastSeq != 5 {
+				t.Fatalf("summary = %+v", summary)
+			}
+		})
+	}
+}
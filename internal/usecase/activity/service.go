@@ -0,0 +1,226 @@
+package activity
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/activity"
+	authdomain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/errreport"
+)
+
+// Limits on the number of entries returned per page.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+var (
+	// ErrInvalidCursor rejects cursors this service did not issue.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidLimit rejects limits outside 1..MaxLimit.
+	ErrInvalidLimit = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	// ErrInvalidFilter rejects unknown entity types and actions.
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// UserLookup resolves actors to users.
+type UserLookup interface {
+	GetByIDs(ctx context.Context, ids []string) ([]*authdomain.User, error)
+}
+
+// Service records domain events as an audit trail and pages through it.
+type Service struct {
+	repo  domain.Repository
+	users UserLookup
+}
+
+// NewService constructs an activity service; users resolves actor names
+// when entries are listed.
+func NewService(repo domain.Repository, users UserLookup) *Service {
+	return &Service{repo: repo, users: users}
+}
+
+// Publish records e, attributed to the authenticated user and client IP of
+// the request that caused it. It implements event.Publisher so the service can sit on
+// the event bus; failures are reported and never fail the change itself.
+func (s *Service) Publish(ctx context.Context, e event.Event) {
+	entityType, action, _ := strings.Cut(e.Type, ".")
+	entry := &domain.Entry{
+		ID:         e.ID,
+		OccurredAt: e.OccurredAt,
+		EntityType: entityType,
+		Action:     action,
+		EntityID:   e.Subject,
+		EntityName: label(e.Data),
+	}
+	if scope := errreport.ScopeFromContext(ctx); scope != nil {
+		entry.ActorID = scope.UserID
+		entry.ActorIP = scope.ClientIP
+	}
+	if err := s.repo.Record(ctx, entry); err != nil {
+		errreport.Error(ctx, fmt.Errorf("activity: recording %s: %w", e.Type, err), nil)
+	}
+}
+
+// Query selects a page of the feed. Cursor is the NextCursor of the
+// previous page, empty for the first.
+type Query struct {
+	ActorID    string
+	EntityType string
+	EntityID   string
+	Action     string
+	Cursor     string
+	Limit      int
+}
+
+// Page is one page of the feed. NextCursor is empty on the last page.
+type Page struct {
+	Items      []*domain.Entry `json:"items"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+// List returns the most recent matching entries, newest first, with actor
+// names resolved.
+func (s *Service) List(ctx context.Context, q Query) (*Page, error) {
+	limit := q.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, ErrInvalidLimit
+	}
+	filter := domain.Filter{
+		ActorID:    strings.TrimSpace(q.ActorID),
+		EntityType: strings.ToLower(strings.TrimSpace(q.EntityType)),
+		EntityID:   strings.TrimSpace(q.EntityID),
+		Action:     strings.ToLower(strings.TrimSpace(q.Action)),
+		// One extra entry tells whether another page follows.
+		Limit: limit + 1,
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{Items: entries}
+	if len(entries) > limit {
+		page.Items = entries[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(domain.Position{OccurredAt: last.OccurredAt, ID: last.ID})
+	}
+	if page.Items == nil {
+		page.Items = []*domain.Entry{}
+	}
+	if err := s.resolveActors(ctx, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// resolveActors fills in actor names, looking every actor of the page up in
+// one query. Actors that no longer exist keep an empty name.
+func (s *Service) resolveActors(ctx context.Context, entries []*domain.Entry) error {
+	var ids []string
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.ActorID != "" && !seen[e.ActorID] {
+			seen[e.ActorID] = true
+			ids = append(ids, e.ActorID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	users, err := s.users.GetByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("resolving actors: %w", err)
+	}
+	names := make(map[string]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+		if user.Name == "" {
+			names[user.ID] = user.Email
+		}
+	}
+	for _, e := range entries {
+		e.ActorName = names[e.ActorID]
+	}
+	return nil
+}
+
+// validateFilter checks the entity type and action against the published
+// event types.
+func validateFilter(f domain.Filter) error {
+	if f.EntityType == "" && f.Action == "" {
+		return nil
+	}
+	var entityKnown, actionKnown bool
+	for _, t := range event.Types {
+		entityType, action, _ := strings.Cut(t, ".")
+		entityKnown = entityKnown || entityType == f.EntityType
+		actionKnown = actionKnown || action == f.Action
+	}
+	if f.EntityType != "" && !entityKnown {
+		return fmt.Errorf("%w: unknown entityType %q", ErrInvalidFilter, f.EntityType)
+	}
+	if f.Action != "" && !actionKnown {
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidFilter, f.Action)
+	}
+	return nil
+}
+
+// label picks a readable name out of an event payload.
+func label(data any) string {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	var fields map[string]any
+	if json.Unmarshal(raw, &fields) != nil {
+		return ""
+	}
+	for _, key := range []string{"name", "email", "summary"} {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Cursors are opaque to clients: the position of the last entry served.
+func encodeCursor(p domain.Position) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(p.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + p.ID))
+}
+
+func decodeCursor(cursor string) (*domain.Position, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &domain.Position{OccurredAt: occurredAt, ID: id}, nil
+}
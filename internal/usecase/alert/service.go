@@ -0,0 +1,265 @@
+// Package alert refreshes the business gauges exported at /metrics and
+// notifies when they cross configured thresholds.
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/alert"
+	"backoffice/backend/internal/errreport"
+	"backoffice/backend/internal/metrics"
+	authusecase "backoffice/backend/internal/usecase/auth"
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// Window is the span the hourly gauges cover.
+const Window = time.Hour
+
+var jobTags = map[string]string{"job": "business-metrics"}
+
+var (
+	lowStockProducts = metrics.Default.NewGauge("products_low_stock",
+		"Products with stock at or below the low-stock quantity.")
+	registrationsLastHour = metrics.Default.NewGauge("auth_registrations_last_hour",
+		"Self-registrations over the last hour, as seen by this process.")
+	failedLoginsLastHour = metrics.Default.NewGauge("auth_failed_logins_last_hour",
+		"Sign-ins rejected for bad credentials over the last hour, as seen by this process.")
+	ordersLastHour = metrics.Default.NewGauge("orders_confirmed_last_hour",
+		"Reservations confirmed into orders over the last hour, as seen by this process.")
+	sloBurnRate = metrics.Default.NewGaugeVec("slo_error_budget_burn_rate",
+		"How many times faster than allowed each objective's error budget was spent over the SLO window.", "slo")
+)
+
+// Thresholds configure the alerts. A zero value disables an alert.
+type Thresholds struct {
+	// FailedLoginsPerHour alerts when at least this many sign-ins were
+	// rejected in the last hour.
+	FailedLoginsPerHour int
+	// LowStockProducts alerts when at least this many products are low on
+	// stock.
+	LowStockProducts int
+	// MinOrdersPerHour and MinRegistrationsPerHour alert when fewer orders
+	// or sign-ups than this happened in the last hour. They are only
+	// evaluated once the process has been up for a full hour.
+	MinOrdersPerHour        int
+	MinRegistrationsPerHour int
+	// SLOBurnRate alerts when an objective in SLOs spends its error budget
+	// at least this many times faster than it allows, measured over
+	// SLOWindow. Windows with fewer than SLOMinRequests requests report a
+	// burn rate of zero, so a handful of failures on a quiet route does not
+	// page anyone.
+	SLOs           []SLO
+	SLOWindow      time.Duration
+	SLOBurnRate    float64
+	SLOMinRequests int
+}
+
+// SLO is a service level objective over the counters of one route prefix.
+type SLO struct {
+	Name string
+	// Objective is the percentage of requests that must be good.
+	Objective float64
+	// Requests and BadRequests return the cumulative request counts.
+	Requests    func() float64
+	BadRequests func() float64
+}
+
+// StockCounter counts products that are running low on stock.
+type StockCounter interface {
+	LowStockCount(ctx context.Context, quantity int) (int, error)
+}
+
+// check is one measurement with its gauge and thresholds.
+type check struct {
+	name  string
+	label string
+	gauge func(float64)
+	// measure returns the current value and whether it covers enough
+	// history for the minimum threshold to be meaningful.
+	measure func(ctx context.Context, now time.Time) (float64, bool, error)
+	atLeast float64
+	below   float64
+	firing  bool
+}
+
+// Service samples the business metrics and raises alerts on breaches.
+type Service struct {
+	notifier domain.Notifier
+	checks   []*check
+	nowFunc  func() time.Time
+	mu       sync.Mutex
+}
+
+// NewService builds the checks. Products with at most lowStockQuantity in
+// stock count as low on stock. Without a notifier alerts are only logged.
+func NewService(products StockCounter, lowStockQuantity int, thresholds Thresholds, notifier domain.Notifier) *Service {
+	now := time.Now()
+	registrations := newWindow(authusecase.Registrations.Total, Window, now)
+	failedLogins := newWindow(authusecase.FailedLogins.Total, Window, now)
+	orders := newWindow(productusecase.OrdersConfirmed.Total, Window, now)
+	s := &Service{
+		notifier: notifier,
+		nowFunc:  time.Now,
+		checks: []*check{
+			{
+				name:  "low_stock_products",
+				label: "Products low on stock",
+				gauge: lowStockProducts.Set,
+				measure: func(ctx context.Context, _ time.Time) (float64, bool, error) {
+					count, err := products.LowStockCount(ctx, lowStockQuantity)
+					return float64(count), true, err
+				},
+				atLeast: float64(thresholds.LowStockProducts),
+			},
+			{
+				name:    "failed_logins_last_hour",
+				label:   "Failed logins in the last hour",
+				gauge:   failedLoginsLastHour.Set,
+				measure: failedLogins.measure,
+				atLeast: float64(thresholds.FailedLoginsPerHour),
+			},
+			{
+				name:    "orders_last_hour",
+				label:   "Orders confirmed in the last hour",
+				gauge:   ordersLastHour.Set,
+				measure: orders.measure,
+				below:   float64(thresholds.MinOrdersPerHour),
+			},
+			{
+				name:    "registrations_last_hour",
+				label:   "Registrations in the last hour",
+				gauge:   registrationsLastHour.Set,
+				measure: registrations.measure,
+				below:   float64(thresholds.MinRegistrationsPerHour),
+			},
+		},
+	}
+	for _, slo := range thresholds.SLOs {
+		s.checks = append(s.checks, sloCheck(slo, thresholds, now))
+	}
+	return s
+}
+
+// sloCheck watches the error budget burn rate of slo: the share of bad
+// requests over the window divided by the share the objective allows.
+func sloCheck(slo SLO, thresholds Thresholds, now time.Time) *check {
+	requests := newWindow(slo.Requests, thresholds.SLOWindow, now)
+	bad := newWindow(slo.BadRequests, thresholds.SLOWindow, now)
+	budget := 1 - slo.Objective/100
+	return &check{
+		name:  "slo_burn_rate:" + slo.Name,
+		label: "Error budget burn rate for " + slo.Name,
+		gauge: func(value float64) { sloBurnRate.Set(value, slo.Name) },
+		measure: func(ctx context.Context, now time.Time) (float64, bool, error) {
+			total, settled, _ := requests.measure(ctx, now)
+			failed, _, _ := bad.measure(ctx, now)
+			if total == 0 || total < float64(thresholds.SLOMinRequests) || budget <= 0 {
+				return 0, settled, nil
+			}
+			return math.Round(failed/total/budget*100) / 100, settled, nil
+		},
+		atLeast: thresholds.SLOBurnRate,
+	}
+}
+
+// Evaluate refreshes every gauge and notifies about checks that started or
+// stopped breaching their threshold. A failed notification is retried on the
+// next evaluation.
+func (s *Service) Evaluate(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.nowFunc().UTC()
+	var errs []error
+	for _, c := range s.checks {
+		value, settled, err := c.measure(ctx, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			continue
+		}
+		c.gauge(value)
+		breached := (c.atLeast > 0 && value >= c.atLeast) || (c.below > 0 && settled && value < c.below)
+		if breached == c.firing {
+			continue
+		}
+		if err := s.notify(ctx, c.alert(value, breached, now)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: notifying: %w", c.name, err))
+			continue
+		}
+		c.firing = breached
+	}
+	return errors.Join(errs...)
+}
+
+// Run calls Evaluate every interval until ctx is done.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Evaluate(ctx); err != nil && ctx.Err() == nil {
+			errreport.Error(ctx, fmt.Errorf("business metrics: %w", err), jobTags)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) notify(ctx context.Context, a domain.Alert) error {
+	if s.notifier == nil {
+		log.Printf("alert: %s", a.Message)
+		return nil
+	}
+	return s.notifier.Notify(ctx, a)
+}
+
+func (c *check) alert(value float64, firing bool, now time.Time) domain.Alert {
+	threshold, condition := c.atLeast, "at least "
+	if c.below > 0 {
+		threshold, condition = c.below, "below "
+	}
+	condition += strconv.FormatFloat(threshold, 'f', -1, 64)
+	message := fmt.Sprintf("%s: %s (threshold: %s)", c.label, strconv.FormatFloat(value, 'f', -1, 64), condition)
+	if !firing {
+		message = "Resolved. " + message
+	}
+	return domain.Alert{Check: c.name, Firing: firing, Value: value, Threshold: threshold, Message: message, At: now}
+}
+
+// window turns a cumulative counter into its increase over a trailing span.
+type window struct {
+	total   func() float64
+	span    time.Duration
+	started time.Time
+	samples []sample
+}
+
+type sample struct {
+	at    time.Time
+	total float64
+}
+
+func newWindow(total func() float64, span time.Duration, now time.Time) *window {
+	return &window{total: total, span: span, started: now, samples: []sample{{at: now, total: total()}}}
+}
+
+// measure records the counter and returns its increase since the newest
+// sample at least span old. The value is settled once the window has been
+// observed for the whole span.
+func (w *window) measure(_ context.Context, now time.Time) (float64, bool, error) {
+	current := w.total()
+	w.samples = append(w.samples, sample{at: now, total: current})
+	cutoff := now.Add(-w.span)
+	for len(w.samples) > 1 && !w.samples[1].at.After(cutoff) {
+		w.samples = w.samples[1:]
+	}
+	return current - w.samples[0].total, !w.started.After(cutoff), nil
+}
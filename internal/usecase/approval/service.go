@@ -0,0 +1,196 @@
+// Package approval holds destructive admin actions until a second admin
+// confirms them, then runs them.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/approval"
+	"backoffice/backend/internal/domain/event"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidStatus rejects a list filter that is not an approval status.
+var ErrInvalidStatus = fmt.Errorf("status must be one of: %s", strings.Join(domain.Statuses, ", "))
+
+// Executor runs an approved action with the payload it was requested with.
+type Executor func(ctx context.Context, payload json.RawMessage) error
+
+// Service creates, decides and executes approvals.
+type Service struct {
+	repo      domain.Repository
+	required  map[string]bool
+	executors map[string]Executor
+	ttl       time.Duration
+	events    event.Publisher
+	nowFunc   func() time.Time
+}
+
+// NewService constructs an approval service. Actions listed in required wait
+// for a second admin, who has ttl to decide before the request expires.
+func NewService(repo domain.Repository, required []string, ttl time.Duration) *Service {
+	s := &Service{
+		repo:      repo,
+		required:  map[string]bool{},
+		executors: map[string]Executor{},
+		ttl:       ttl,
+		events:    event.Discard,
+		nowFunc:   time.Now,
+	}
+	for _, action := range required {
+		s.required[action] = true
+	}
+	return s
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// Register sets how an approved action is carried out.
+func (s *Service) Register(action string, execute Executor) {
+	s.executors[action] = execute
+}
+
+// Required reports whether action must be approved before it runs. Actions
+// nothing can execute are never held.
+func (s *Service) Required(action string) bool {
+	return s.required[action] && s.executors[action] != nil
+}
+
+// RequestInput describes an action to hold for approval.
+type RequestInput struct {
+	Action      string
+	Subject     string
+	Summary     string
+	Payload     any
+	RequestedBy string
+}
+
+// Request records a pending approval for an action.
+func (s *Service) Request(ctx context.Context, input RequestInput) (*domain.Approval, error) {
+	if s.executors[input.Action] == nil {
+		return nil, fmt.Errorf("action %q cannot be approved", input.Action)
+	}
+	payload, err := json.Marshal(input.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s payload: %w", input.Action, err)
+	}
+	now := s.nowFunc().UTC()
+	approval := &domain.Approval{
+		ID:          uuid.NewString(),
+		Action:      input.Action,
+		Status:      domain.StatusPending,
+		Subject:     input.Subject,
+		Summary:     input.Summary,
+		Payload:     payload,
+		RequestedBy: input.RequestedBy,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+	if err := s.repo.Create(ctx, approval); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, event.ApprovalRequested, approval)
+	return approval, nil
+}
+
+// List returns approvals with the status, or all when it is empty, newest
+// first.
+func (s *Service) List(ctx context.Context, status string) ([]*domain.Approval, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && !slices.Contains(domain.Statuses, status) {
+		return nil, ErrInvalidStatus
+	}
+	if err := s.expire(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, status)
+}
+
+// Get fetches an approval by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Approval, error) {
+	if err := s.expire(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, strings.TrimSpace(id))
+}
+
+// Approve confirms a pending approval on behalf of an admin other than the
+// requester and runs the action. The returned approval is executed, or
+// failed with the action's error.
+func (s *Service) Approve(ctx context.Context, id, adminID string) (*domain.Approval, error) {
+	approval, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != domain.StatusPending {
+		return nil, domain.ErrNotPending
+	}
+	if approval.RequestedBy == adminID {
+		return nil, domain.ErrSelfApproval
+	}
+	execute := s.executors[approval.Action]
+	if execute == nil {
+		return nil, fmt.Errorf("action %q cannot be executed", approval.Action)
+	}
+	if err := s.decide(ctx, approval, domain.StatusApproved, adminID, ""); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, event.ApprovalApproved, approval)
+
+	approval.Status = domain.StatusExecuted
+	if err := execute(ctx, approval.Payload); err != nil {
+		approval.Status = domain.StatusFailed
+		approval.Error = err.Error()
+	}
+	if err := s.repo.Finish(ctx, approval); err != nil {
+		return nil, fmt.Errorf("recording the result of approval %s: %w", approval.ID, err)
+	}
+	return approval, nil
+}
+
+// Reject declines a pending approval. The requester may reject their own
+// request to withdraw it.
+func (s *Service) Reject(ctx context.Context, id, adminID, reason string) (*domain.Approval, error) {
+	approval, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != domain.StatusPending {
+		return nil, domain.ErrNotPending
+	}
+	if err := s.decide(ctx, approval, domain.StatusRejected, adminID, strings.TrimSpace(reason)); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, event.ApprovalRejected, approval)
+	return approval, nil
+}
+
+func (s *Service) decide(ctx context.Context, approval *domain.Approval, status, adminID, reason string) error {
+	now := s.nowFunc().UTC()
+	approval.Status = status
+	approval.DecidedBy = adminID
+	approval.DecidedAt = &now
+	approval.Reason = reason
+	return s.repo.Decide(ctx, approval)
+}
+
+// expire settles approvals nobody decided in time before they are read.
+func (s *Service) expire(ctx context.Context) error {
+	if _, err := s.repo.Expire(ctx, s.nowFunc().UTC()); err != nil {
+		return fmt.Errorf("expiring approvals: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) publish(ctx context.Context, eventType string, approval *domain.Approval) {
+	s.events.Publish(ctx, event.New(eventType, approval.ID, approval))
+}
@@ -0,0 +1,13 @@
+package auth
+
+import "backoffice/backend/internal/metrics"
+
+var (
+	// Registrations counts accounts created through self-registration.
+	Registrations = metrics.Default.NewCounterVec("auth_registrations_total",
+		"Accounts created through self-registration.")
+	// FailedLogins counts rejected sign-in attempts by reason:
+	// missing_credentials, unknown_user or wrong_password.
+	FailedLogins = metrics.Default.NewCounterVec("auth_failed_logins_total",
+		"Sign-in attempts rejected because of bad credentials, by reason.", "reason")
+)
@@ -3,10 +3,18 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	domain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/domain/session"
+	"backoffice/backend/internal/errreport"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -16,28 +24,117 @@ import (
 type Service struct {
 	users   domain.UserRepository
 	tokens  TokenManager
-	nowFunc func() time.Time
+	clients map[string][]string
+	// renewGrace is how long after expiry a token can still be renewed.
+	renewGrace time.Duration
+	// failureDelay caps the random delay added to failed sign-ins.
+	failureDelay time.Duration
+	// registrationClosed turns public registration off; users are then only
+	// created by admins.
+	registrationClosed bool
+	// registrationDomains, when set, are the only email domains that may
+	// register.
+	registrationDomains []string
+	// adminEmails register as admins; when empty, the first user to
+	// register while there is no admin becomes one.
+	adminEmails []string
+	// userCache, when set, serves VerifyToken's user lookups.
+	userCache *UserCache
+	events    event.Publisher
+	nowFunc   func() time.Time
 }
 
 // NewService constructs an auth service.
 func NewService(users domain.UserRepository, tokens TokenManager) *Service {
+	// Hashed ahead of the first sign-in with an unknown email, which would
+	// otherwise take twice as long.
+	go dummyHash()
 	return &Service{
 		users:   users,
 		tokens:  tokens,
+		events:  event.Discard,
 		nowFunc: time.Now,
 	}
 }
 
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// SetClients configures the API clients that may request restricted tokens,
+// mapping each client id to the scopes it may be granted.
+func (s *Service) SetClients(clients map[string][]string) {
+	s.clients = clients
+}
+
+// SetRenewGrace lets RenewToken accept tokens up to grace past their
+// expiry, so clients that were idle briefly need not log in again.
+func (s *Service) SetRenewGrace(grace time.Duration) {
+	s.renewGrace = grace
+}
+
+// SetFailureDelay makes failed sign-ins wait a random time of up to max
+// before answering, so their timing reveals nothing and guessing is slowed.
+func (s *Service) SetFailureDelay(max time.Duration) {
+	s.failureDelay = max
+}
+
+// SetUserCache lets VerifyToken serve users from cache. The cache should
+// also be subscribed to the event bus the user service publishes to.
+func (s *Service) SetUserCache(cache *UserCache) {
+	s.userCache = cache
+}
+
+// ForgetUser drops the user with id from the user cache, for changes that
+// publish no event, such as revoking the user's tokens.
+func (s *Service) ForgetUser(id string) {
+	if s.userCache != nil {
+		s.userCache.Forget(id)
+	}
+}
+
+// SetRegistration configures self-registration: when enabled is false
+// Register always fails with ErrRegistrationClosed, and a non-empty domains
+// list limits it to emails at those domains.
+func (s *Service) SetRegistration(enabled bool, domains []string) {
+	s.registrationClosed = !enabled
+	s.registrationDomains = nil
+	for _, d := range domains {
+		s.registrationDomains = append(s.registrationDomains, strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@")))
+	}
+}
+
+// SetAdminEmails names the emails that are made admins when they register,
+// even while registration is closed or limited to other domains. Setting
+// any turns off promoting the first user to register.
+func (s *Service) SetAdminEmails(emails []string) {
+	s.adminEmails = nil
+	for _, e := range emails {
+		s.adminEmails = append(s.adminEmails, strings.ToLower(strings.TrimSpace(e)))
+	}
+}
+
 // Register creates a new user and returns the persisted entity without a password hash.
 func (s *Service) Register(ctx context.Context, email, password, name string) (*domain.User, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
 	password = strings.TrimSpace(password)
 	name = strings.TrimSpace(name)
+	bootstrapAdmin := slices.Contains(s.adminEmails, email)
+	if s.registrationClosed && !bootstrapAdmin {
+		return nil, domain.ErrRegistrationClosed
+	}
 	if email == "" {
-		return nil, errors.New("email is required")
+		return nil, errcode.New(errcode.Invalid, "email_required", "email is required")
 	}
 	if password == "" {
-		return nil, errors.New("password is required")
+		return nil, errcode.New(errcode.Invalid, "password_required", "password is required")
+	}
+	if len(password) > domain.MaxPasswordBytes {
+		return nil, domain.ErrPasswordTooLong
+	}
+	if !bootstrapAdmin && !s.registrationAllowed(email) {
+		return nil, domain.ErrEmailDomainNotAllowed
 	}
 
 	if _, err := s.users.GetByEmail(ctx, email); err == nil {
@@ -62,97 +159,251 @@ func (s *Service) Register(ctx context.Context, email, password, name string) (*
 		UpdatedAt:    now,
 	}
 
-	if err := s.users.Create(ctx, user); err != nil {
+	// Everyone registers as a plain user, except bootstrap admins and, when
+	// no admin emails are configured, the very first user, so a fresh
+	// deployment is never left without an admin.
+	create := s.users.Create
+	switch {
+	case bootstrapAdmin:
+		user.Role = domain.RoleAdmin
+	case len(s.adminEmails) == 0:
+		create = s.users.CreatePromotingFirst
+	}
+	if err := create(ctx, user); err != nil {
 		return nil, err
 	}
+	Registrations.Inc()
+	s.events.Publish(ctx, event.New(event.UserCreated, user.ID, user.Summary()))
 
 	return sanitizeUser(user), nil
 }
 
+// registrationAllowed reports whether email is at one of the registration
+// domains, or whether registration is open to every domain.
+func (s *Service) registrationAllowed(email string) bool {
+	if len(s.registrationDomains) == 0 {
+		return true
+	}
+	_, domainName, ok := strings.Cut(email, "@")
+	return ok && slices.Contains(s.registrationDomains, domainName)
+}
+
 // Login validates credentials and returns a token plus user.
 func (s *Service) Login(ctx context.Context, creds domain.Credentials) (string, *domain.User, error) {
 	email := strings.TrimSpace(strings.ToLower(creds.Email))
 	password := strings.TrimSpace(creds.Password)
 	if email == "" || password == "" {
-		return "", nil, domain.ErrInvalidCredentials
+		return "", nil, s.loginFailed(ctx, "missing_credentials")
+	}
+	grant, err := s.grant(creds.ClientID, creds.Scopes)
+	if err != nil {
+		return "", nil, err
 	}
 
 	user, err := s.users.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
-			return "", nil, domain.ErrInvalidCredentials
+			// Compare anyway so an unknown email takes as long as a wrong
+			// password.
+			_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+			return "", nil, s.loginFailed(ctx, "unknown_user")
 		}
 		return "", nil, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", nil, domain.ErrInvalidCredentials
+		return "", nil, s.loginFailed(ctx, "wrong_password")
 	}
 
-	token, err := s.tokens.Generate(user.ID)
+	token, err := s.tokens.Generate(ctx, user, grant)
 	if err != nil {
 		return "", nil, err
 	}
+	// Activity tracking must not block sign-in.
+	if err := s.users.RecordLogin(ctx, user.ID, s.nowFunc().UTC()); err != nil {
+		errreport.Error(ctx, fmt.Errorf("recording login: %w", err), nil)
+	}
 
 	return token, sanitizeUser(user), nil
 }
 
-// VerifyToken validates a bearer token and returns the associated user.
-func (s *Service) VerifyToken(ctx context.Context, token string) (*domain.User, error) {
-	userID, err := s.tokens.Validate(token)
+// loginFailed counts a failed sign-in and waits out a random part of the
+// failure delay, or until ctx ends, before reporting invalid credentials.
+func (s *Service) loginFailed(ctx context.Context, reason string) error {
+	FailedLogins.Inc(reason)
+	if s.failureDelay > 0 {
+		timer := time.NewTimer(rand.N(s.failureDelay))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	return domain.ErrInvalidCredentials
+}
+
+// dummyHash is compared against when the email is unknown. It is computed
+// once, at the cost real passwords are hashed with.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, domain.ErrTokenInvalid
+		panic(err)
 	}
+	return hash
+})
 
-	user, err := s.users.GetByID(ctx, userID)
+// VerifyToken validates a bearer token and returns the associated user and
+// what the token grants.
+func (s *Service) VerifyToken(ctx context.Context, token string) (*domain.User, domain.Grant, error) {
+	identity, err := s.tokens.Validate(ctx, token)
+	if err != nil {
+		return nil, domain.Grant{}, domain.ErrTokenInvalid
+	}
+
+	// A cached user whose token version differs may be stale, e.g. when
+	// the token was issued by another instance after a password change, so
+	// it is looked up again before the token is rejected.
+	if s.userCache != nil {
+		if user, ok := s.userCache.get(identity.UserID); ok && user.TokenVersion == identity.Version {
+			return user, identity.Grant, nil
+		}
+	}
+
+	user, err := s.users.GetByID(ctx, identity.UserID)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
-			return nil, domain.ErrTokenInvalid
+			return nil, domain.Grant{}, domain.ErrTokenInvalid
 		}
-		return nil, err
+		return nil, domain.Grant{}, err
+	}
+	user = sanitizeUser(user)
+	if s.userCache != nil {
+		s.userCache.put(user)
+	}
+	if identity.Version != user.TokenVersion {
+		return nil, domain.Grant{}, domain.ErrTokenInvalid
 	}
 
-	return sanitizeUser(user), nil
+	return user, identity.Grant, nil
 }
 
-// RenewToken issues a new access token for the user encoded in the provided token.
+// RenewToken issues a new access token for the user encoded in the provided
+// token, which may have expired within the renewal grace window.
 func (s *Service) RenewToken(ctx context.Context, token string) (string, error) {
 	token = strings.TrimSpace(token)
 	if token == "" {
 		return "", domain.ErrTokenInvalid
 	}
 
-	userID, err := s.tokens.ExtractUserID(token)
+	identity, err := s.tokens.Extract(ctx, token)
+	if err != nil {
+		return "", domain.ErrTokenInvalid
+	}
+	if !identity.ExpiresAt.IsZero() && s.nowFunc().After(identity.ExpiresAt.Add(s.renewGrace)) {
+		return "", domain.ErrTokenInvalid
+	}
+	// Re-check the grant so a client whose allowance shrank cannot keep
+	// renewing its old scopes.
+	grant, err := s.grant(identity.Grant.ClientID, identity.Grant.Scopes)
 	if err != nil {
 		return "", domain.ErrTokenInvalid
 	}
 
-	user, err := s.users.GetByID(ctx, userID)
+	user, err := s.users.GetByID(ctx, identity.UserID)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
 			return "", domain.ErrTokenInvalid
 		}
 		return "", err
 	}
+	if identity.Version != user.TokenVersion {
+		return "", domain.ErrTokenInvalid
+	}
 
-	newToken, err := s.tokens.Generate(user.ID)
+	newToken, err := s.tokens.Generate(ctx, user, grant)
 	if err != nil {
 		return "", err
 	}
+	// The renewed session replaces the old one.
+	if store, ok := s.tokens.(SessionStore); ok {
+		if err := store.Revoke(ctx, token); err != nil {
+			return "", err
+		}
+	}
 
 	return newToken, nil
 }
 
-// ChangePassword updates the password for the provided user after verifying the current one.
+// Sessions lists the user's active sessions.
+func (s *Service) Sessions(ctx context.Context, userID string) ([]*session.Session, error) {
+	store, ok := s.tokens.(SessionStore)
+	if !ok {
+		return nil, domain.ErrSessionsUnsupported
+	}
+	sessions, err := store.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sessions == nil {
+		sessions = []*session.Session{}
+	}
+	return sessions, nil
+}
+
+// SessionsFor lists the active sessions of each of userIDs in one lookup.
+// Every id has an entry, empty when the user has no sessions.
+func (s *Service) SessionsFor(ctx context.Context, userIDs []string) (map[string][]*session.Session, error) {
+	store, ok := s.tokens.(SessionStore)
+	if !ok {
+		return nil, domain.ErrSessionsUnsupported
+	}
+	sessions, err := store.ListSessionsFor(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[string][]*session.Session, len(userIDs))
+	for _, id := range userIDs {
+		byUser[id] = []*session.Session{}
+	}
+	for _, sess := range sessions {
+		byUser[sess.UserID] = append(byUser[sess.UserID], sess)
+	}
+	return byUser, nil
+}
+
+// RevokeSession ends one of the user's sessions.
+func (s *Service) RevokeSession(ctx context.Context, userID, id string) error {
+	store, ok := s.tokens.(SessionStore)
+	if !ok {
+		return domain.ErrSessionsUnsupported
+	}
+	return store.RevokeSession(ctx, userID, strings.TrimSpace(id))
+}
+
+// Logout ends the session of token. Stateless tokens cannot be revoked and
+// simply run until they expire.
+func (s *Service) Logout(ctx context.Context, token string) error {
+	store, ok := s.tokens.(SessionStore)
+	if !ok {
+		return domain.ErrSessionsUnsupported
+	}
+	return store.Revoke(ctx, strings.TrimSpace(token))
+}
+
+// ChangePassword updates the password for the provided user after verifying
+// the current one. Every token issued to the user stops working.
 func (s *Service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
 	currentPassword = strings.TrimSpace(currentPassword)
 	newPassword = strings.TrimSpace(newPassword)
 
 	if currentPassword == "" {
-		return errors.New("current password is required")
+		return errcode.New(errcode.Invalid, "password_current_required", "current password is required")
 	}
 	if newPassword == "" {
-		return errors.New("new password is required")
+		return errcode.New(errcode.Invalid, "password_new_required", "new password is required")
+	}
+	if len(newPassword) > domain.MaxPasswordBytes {
+		return domain.ErrPasswordTooLong
 	}
 	if newPassword == currentPassword {
 		return domain.ErrPasswordUnchanged
@@ -172,7 +423,47 @@ func (s *Service) ChangePassword(ctx context.Context, userID, currentPassword, n
 		return err
 	}
 
-	return s.users.UpdatePassword(ctx, userID, string(hashed), s.nowFunc().UTC())
+	if err := s.users.UpdatePassword(ctx, userID, string(hashed), s.nowFunc().UTC()); err != nil {
+		return err
+	}
+	s.ForgetUser(userID)
+	return nil
+}
+
+// grant resolves the scopes a token may carry. Without a client, callers may
+// restrict their own token to any scopes; a client is limited to its
+// configured allowance, all of which it receives when it asks for nothing.
+func (s *Service) grant(clientID string, requested []string) (domain.Grant, error) {
+	var scopes []string
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || slices.Contains(scopes, scope) {
+			continue
+		}
+		if !domain.ValidScope(scope) {
+			return domain.Grant{}, fmt.Errorf("%w: %q", errcode.New(errcode.Invalid, "scope_invalid", "invalid scope").With("scope", scope), scope)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	clientID = strings.TrimSpace(clientID)
+	if clientID == "" {
+		return domain.Grant{Scopes: scopes}, nil
+	}
+	allowed, ok := s.clients[clientID]
+	if !ok {
+		return domain.Grant{}, domain.ErrUnknownClient
+	}
+	if len(scopes) == 0 {
+		return domain.Grant{ClientID: clientID, Scopes: allowed}, nil
+	}
+	allowance := domain.Grant{Scopes: allowed}
+	for _, scope := range scopes {
+		if !allowance.Allows(scope) {
+			return domain.Grant{}, fmt.Errorf("%w: %s", domain.ErrScopeNotAllowed.With("scope", scope), scope)
+		}
+	}
+	return domain.Grant{ClientID: clientID, Scopes: scopes}, nil
 }
 
 func sanitizeUser(u *domain.User) *domain.User {
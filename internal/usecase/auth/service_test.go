@@ -0,0 +1,334 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/infrastructure/memory"
+	"backoffice/backend/internal/mocks"
+	"backoffice/backend/internal/usecase/auth"
+)
+
+// newService returns a service over an in-memory user store and a token
+// manager that issues "token-<user id>" and accepts nothing until a test
+// stubs Validate or Extract.
+func newService() (*auth.Service, *mocks.UserRepository, *mocks.TokenManager) {
+	users := &mocks.UserRepository{Fallback: memory.NewUserRepository()}
+	tokens := &mocks.TokenManager{
+		GenerateFunc: func(_ context.Context, user *domain.User, _ domain.Grant) (string, error) {
+			return "token-" + user.ID, nil
+		},
+		ValidateFunc: func(context.Context, string) (auth.Identity, error) {
+			return auth.Identity{}, errors.New("not stubbed")
+		},
+		ExtractFunc: func(context.Context, string) (auth.Identity, error) {
+			return auth.Identity{}, errors.New("not stubbed")
+		},
+	}
+	return auth.NewService(users, tokens), users, tokens
+}
+
+func TestRegister(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newService()
+
+	first, err := svc.Register(ctx, " Ada@Example.com ", "correct horse", "Ada")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if first.Email != "ada@example.com" || first.Role != domain.RoleAdmin || first.PasswordHash != "" {
+		t.Fatalf("first user = %+v, want a normalized admin without a password hash", first)
+	}
+	second, err := svc.Register(ctx, "bob@example.com", "battery staple", "Bob")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if second.Role != domain.RoleUser {
+		t.Fatalf("second user role = %q, want %q", second.Role, domain.RoleUser)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		email    string
+		password string
+		want     error
+	}{
+		{"duplicate email", "ADA@example.com", "another one", domain.ErrEmailExists},
+		{"password too long", "carol@example.com", strings.Repeat("x", domain.MaxPasswordBytes+1), domain.ErrPasswordTooLong},
+	} {
+		if _, err := svc.Register(ctx, tc.email, tc.password, ""); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	svc.SetRegistration(false, nil)
+	if _, err := svc.Register(ctx, "dave@example.com", "secret", ""); !errors.Is(err, domain.ErrRegistrationClosed) {
+		t.Fatalf("closed registration: err = %v, want %v", err, domain.ErrRegistrationClosed)
+	}
+}
+
+// TestRegisterWithoutAdmin covers deployments that have users but no admin,
+// such as ones upgraded from before roles were assigned at registration:
+// only the very first user is promoted, not the next one to register.
+func TestRegisterWithoutAdmin(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _ := newService()
+	existing := &domain.User{ID: "legacy", Email: "legacy@example.com", Role: domain.RoleUser}
+	if err := users.Create(ctx, existing); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	user, err := svc.Register(ctx, "mallory@example.com", "secret", "Mallory")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if user.Role != domain.RoleUser {
+		t.Fatalf("role = %q with existing users, want %q", user.Role, domain.RoleUser)
+	}
+}
+
+func TestRegisterConcurrentFirstUsers(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newService()
+
+	var wg sync.WaitGroup
+	roles := make(chan domain.UserRole, 8)
+	for i := range cap(roles) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := svc.Register(ctx, fmt.Sprintf("user%d@example.com", i), "secret", "")
+			if err != nil {
+				t.Errorf("Register: %v", err)
+				return
+			}
+			roles <- user.Role
+		}()
+	}
+	wg.Wait()
+	close(roles)
+	admins := 0
+	for role := range roles {
+		if role == domain.RoleAdmin {
+			admins++
+		}
+	}
+	if admins != 1 {
+		t.Fatalf("%d concurrent first registrations became admin, want 1", admins)
+	}
+}
+
+func TestRegisterStoreFailure(t *testing.T) {
+	svc, users, _ := newService()
+	boom := errors.New("database down")
+	users.CreatePromotingFirstFunc = func(context.Context, *domain.User) error { return boom }
+
+	if _, err := svc.Register(context.Background(), "ada@example.com", "secret", ""); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	svc, users, tokens := newService()
+	user, err := svc.Register(ctx, "ada@example.com", "secret", "Ada")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	token, got, err := svc.Login(ctx, domain.Credentials{Email: "ADA@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if token != "token-"+user.ID || got.ID != user.ID || got.PasswordHash != "" {
+		t.Fatalf("Login = %q, %+v", token, got)
+	}
+	if n := users.Count("RecordLogin"); n != 1 {
+		t.Fatalf("RecordLogin called %d times, want 1", n)
+	}
+
+	for _, creds := range []domain.Credentials{
+		{Email: "ada@example.com", Password: "wrong"},
+		{Email: "nobody@example.com", Password: "secret"},
+		{Email: "ada@example.com"},
+	} {
+		if _, _, err := svc.Login(ctx, creds); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("Login(%+v): err = %v, want %v", creds, err, domain.ErrInvalidCredentials)
+		}
+	}
+	if n := tokens.Count("Generate"); n != 1 {
+		t.Fatalf("Generate called %d times, want only for the successful sign-in", n)
+	}
+}
+
+func TestLoginIgnoresActivityFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _ := newService()
+	if _, err := svc.Register(ctx, "ada@example.com", "secret", ""); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	users.RecordLoginFunc = func(context.Context, string, time.Time) error { return errors.New("database down") }
+
+	if _, _, err := svc.Login(ctx, domain.Credentials{Email: "ada@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	ctx := context.Background()
+	svc, users, tokens := newService()
+	user, err := svc.Register(ctx, "ada@example.com", "secret", "")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	grant := domain.Grant{Scopes: []string{"products:read"}}
+	tokens.ValidateFunc = func(_ context.Context, token string) (auth.Identity, error) {
+		if token != "good" {
+			return auth.Identity{}, errors.New("bad signature")
+		}
+		return auth.Identity{UserID: user.ID, Grant: grant}, nil
+	}
+
+	got, gotGrant, err := svc.VerifyToken(ctx, "good")
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if got.ID != user.ID || !gotGrant.Restricted() {
+		t.Fatalf("VerifyToken = %+v, %+v", got, gotGrant)
+	}
+	if _, _, err := svc.VerifyToken(ctx, "forged"); !errors.Is(err, domain.ErrTokenInvalid) {
+		t.Fatalf("forged token: err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+
+	// Signing out everywhere bumps the version the token was issued at.
+	if err := users.BumpTokenVersion(ctx, user.ID); err != nil {
+		t.Fatalf("BumpTokenVersion: %v", err)
+	}
+	if _, _, err := svc.VerifyToken(ctx, "good"); !errors.Is(err, domain.ErrTokenInvalid) {
+		t.Fatalf("revoked token: err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+}
+
+func TestRenewToken(t *testing.T) {
+	ctx := context.Background()
+	svc, _, tokens := newService()
+	user, err := svc.Register(ctx, "ada@example.com", "secret", "")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	svc.SetRenewGrace(time.Hour)
+	expiresAt := time.Now().Add(-30 * time.Minute)
+	tokens.ExtractFunc = func(context.Context, string) (auth.Identity, error) {
+		return auth.Identity{UserID: user.ID, ExpiresAt: expiresAt}, nil
+	}
+
+	renewed, err := svc.RenewToken(ctx, "expired")
+	if err != nil {
+		t.Fatalf("RenewToken within grace: %v", err)
+	}
+	if renewed != "token-"+user.ID {
+		t.Fatalf("RenewToken = %q", renewed)
+	}
+
+	expiresAt = time.Now().Add(-2 * time.Hour)
+	if _, err := svc.RenewToken(ctx, "expired"); !errors.Is(err, domain.ErrTokenInvalid) {
+		t.Fatalf("past grace: err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+	if _, err := svc.RenewToken(ctx, " "); !errors.Is(err, domain.ErrTokenInvalid) {
+		t.Fatalf("blank token: err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _ := newService()
+	user, err := svc.Register(ctx, "ada@example.com", "secret", "")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name             string
+		current, newPass string
+		want             error
+	}{
+		{"wrong current password", "guess", "new secret", domain.ErrPasswordMismatch},
+		{"unchanged", "secret", "secret", domain.ErrPasswordUnchanged},
+		{"too long", "secret", strings.Repeat("x", domain.MaxPasswordBytes+1), domain.ErrPasswordTooLong},
+	} {
+		if err := svc.ChangePassword(ctx, user.ID, tc.current, tc.newPass); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if n := users.Count("UpdatePassword"); n != 0 {
+		t.Fatalf("UpdatePassword called %d times by rejected changes", n)
+	}
+
+	if err := svc.ChangePassword(ctx, user.ID, "secret", "new secret"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if _, _, err := svc.Login(ctx, domain.Credentials{Email: "ada@example.com", Password: "new secret"}); err != nil {
+		t.Fatalf("Login with the new password: %v", err)
+	}
+	if _, _, err := svc.Login(ctx, domain.Credentials{Email: "ada@example.com", Password: "secret"}); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("Login with the old password: err = %v, want %v", err, domain.ErrInvalidCredentials)
+	}
+}
+
+func TestVerifyTokenUserCache(t *testing.T) {
+	ctx := context.Background()
+	svc, users, tokens := newService()
+	cache := auth.NewUserCache(time.Minute)
+	svc.SetUserCache(cache)
+	user, err := svc.Register(ctx, "ada@example.com", "secret", "")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	version := 0
+	tokens.ValidateFunc = func(context.Context, string) (auth.Identity, error) {
+		return auth.Identity{UserID: user.ID, Version: version}, nil
+	}
+
+	for range 3 {
+		if _, _, err := svc.VerifyToken(ctx, "good"); err != nil {
+			t.Fatalf("VerifyToken: %v", err)
+		}
+	}
+	if n := users.Count("GetByID"); n != 1 {
+		t.Fatalf("GetByID called %d times for three requests, want 1", n)
+	}
+
+	// A token issued after the version moved on is checked against the
+	// stored user rather than rejected by the cached one.
+	if err := users.BumpTokenVersion(ctx, user.ID); err != nil {
+		t.Fatalf("BumpTokenVersion: %v", err)
+	}
+	version = 1
+	if _, _, err := svc.VerifyToken(ctx, "good"); err != nil {
+		t.Fatalf("VerifyToken with a newer token: %v", err)
+	}
+
+	cache.Publish(ctx, event.New(event.UserRoleChanged, user.ID, nil))
+	if _, _, err := svc.VerifyToken(ctx, "good"); err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if n := users.Count("GetByID"); n != 3 {
+		t.Fatalf("GetByID called %d times, want a lookup after the version change and after the role change", n)
+	}
+
+	// Revoking drops the cached user, so the old token fails at once.
+	if err := users.BumpTokenVersion(ctx, user.ID); err != nil {
+		t.Fatalf("BumpTokenVersion: %v", err)
+	}
+	svc.ForgetUser(user.ID)
+	if _, _, err := svc.VerifyToken(ctx, "good"); !errors.Is(err, domain.ErrTokenInvalid) {
+		t.Fatalf("revoked token: err = %v, want %v", err, domain.ErrTokenInvalid)
+	}
+}
@@ -1,8 +1,84 @@
 package auth
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	domain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/session"
+)
+
 // TokenManager abstracts token issuance and verification.
 type TokenManager interface {
-	Generate(userID string) (string, error)
-	Validate(token string) (string, error)
-	ExtractUserID(token string) (string, error)
+	// Generate issues a token for the user at their current token version.
+	Generate(ctx context.Context, user *domain.User, grant domain.Grant) (string, error)
+	Validate(ctx context.Context, token string) (Identity, error)
+	// Extract returns the identity in an authentic token without enforcing
+	// expiry; callers decide how long after ExpiresAt they accept it.
+	Extract(ctx context.Context, token string) (Identity, error)
+}
+
+// Identity is what a valid token asserts about its bearer.
+type Identity struct {
+	UserID string
+	Grant  domain.Grant
+	// Version must match the user's token version for the token to be
+	// accepted.
+	Version int
+	// ExpiresAt is zero for tokens that do not expire.
+	ExpiresAt time.Time
+}
+
+// SessionStore is implemented by token managers that keep server-side
+// sessions, which can be listed and revoked individually.
+type SessionStore interface {
+	ListSessions(ctx context.Context, userID string) ([]*session.Session, error)
+	// ListSessionsFor lists the active sessions of several users at once.
+	ListSessionsFor(ctx context.Context, userIDs []string) ([]*session.Session, error)
+	RevokeSession(ctx context.Context, userID, id string) error
+	// Revoke ends the session of token; unknown tokens are ignored.
+	Revoke(ctx context.Context, token string) error
+}
+
+// CheckTokens issues a token for a made-up user and checks that tokens
+// validates it to the same identity and rejects a tampered copy, so a
+// broken token configuration fails at startup rather than at the first
+// sign-in. It suits managers that need no storage; one that keeps sessions
+// would store one for a user that does not exist.
+func CheckTokens(ctx context.Context, tokens TokenManager) error {
+	probe := &domain.User{ID: "startup-self-test", Role: domain.RoleUser, TokenVersion: 7}
+	grant := domain.Grant{ClientID: "self-test", Scopes: []string{"products:read"}}
+	token, err := tokens.Generate(ctx, probe, grant)
+	if err != nil {
+		return fmt.Errorf("issuing a token: %w", err)
+	}
+	identity, err := tokens.Validate(ctx, token)
+	if err != nil {
+		return fmt.Errorf("validating a freshly issued token: %w", err)
+	}
+	switch {
+	case identity.UserID != probe.ID:
+		return fmt.Errorf("token names user %q, issued for %q", identity.UserID, probe.ID)
+	case identity.Version != probe.TokenVersion:
+		return fmt.Errorf("token carries version %d, issued at %d", identity.Version, probe.TokenVersion)
+	case identity.Grant.ClientID != grant.ClientID || !slices.Equal(identity.Grant.Scopes, grant.Scopes):
+		return fmt.Errorf("token grant %+v differs from the issued %+v", identity.Grant, grant)
+	case !identity.ExpiresAt.IsZero() && !identity.ExpiresAt.After(time.Now()):
+		return fmt.Errorf("token expired on issue (expires %s)", identity.ExpiresAt.Format(time.RFC3339))
+	}
+
+	tampered := []byte(token)
+	i := len(tampered) / 2
+	if tampered[i] == 'A' {
+		tampered[i] = 'B'
+	} else {
+		tampered[i] = 'A'
+	}
+	if _, err := tokens.Validate(ctx, string(tampered)); err == nil {
+		return errors.New("a tampered token was accepted")
+	}
+	return nil
 }
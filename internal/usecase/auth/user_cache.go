@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/metrics"
+)
+
+var userCacheLookups = metrics.Default.NewCounterVec("auth_user_cache_lookups_total",
+	"User lookups while verifying tokens, by result (hit, miss).", "result")
+
+// UserCache keeps the users VerifyToken loaded for a short time, so
+// authenticated requests need not read the user from the database every
+// time. It subscribes to the event bus and drops a user when the user is
+// updated, changes role, or is deleted or restored. Changes made by other
+// instances are only seen once an entry expires.
+type UserCache struct {
+	ttl     time.Duration
+	nowFunc func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]cachedUser
+	lastSweep time.Time
+}
+
+type cachedUser struct {
+	user    domain.User
+	expires time.Time
+}
+
+// NewUserCache constructs a cache whose entries live for ttl.
+func NewUserCache(ttl time.Duration) *UserCache {
+	return &UserCache{ttl: ttl, nowFunc: time.Now, entries: make(map[string]cachedUser)}
+}
+
+var _ event.Publisher = (*UserCache)(nil)
+
+// Publish drops the subject of user events.
+func (c *UserCache) Publish(_ context.Context, e event.Event) {
+	switch e.Type {
+	case event.UserUpdated, event.UserRoleChanged, event.UserDeleted, event.UserRestored:
+		c.Forget(e.Subject)
+	}
+}
+
+// Forget drops the user with id.
+func (c *UserCache) Forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
+
+func (c *UserCache) get(id string) (*domain.User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || !c.nowFunc().Before(entry.expires) {
+		userCacheLookups.Inc("miss")
+		return nil, false
+	}
+	userCacheLookups.Inc("hit")
+	user := entry.user
+	return &user, true
+}
+
+func (c *UserCache) put(user *domain.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFunc()
+	if now.Sub(c.lastSweep) > c.ttl {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[user.ID] = cachedUser{user: *user, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"time"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	categorydomain "backoffice/backend/internal/domain/category"
+	productdomain "backoffice/backend/internal/domain/product"
+)
+
+// Archive format identifiers. Version changes whenever a restore could no
+// longer read older archives.
+const (
+	Format  = "backoffice-backup"
+	Version = 1
+)
+
+// Archive is a portable copy of the backoffice data. Users carry no
+// password hashes and webhooks no signing secrets.
+type Archive struct {
+	Format     string                     `json:"format"`
+	Version    int                        `json:"version"`
+	CreatedAt  time.Time                  `json:"createdAt"`
+	Users      []authdomain.Summary       `json:"users"`
+	Categories []*categorydomain.Category `json:"categories"`
+	Products   []*productdomain.Product   `json:"products"`
+	Settings   Settings                   `json:"settings"`
+}
+
+// Settings is the configuration kept in the database rather than the
+// environment.
+type Settings struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// Webhook is a webhook subscription without its secret.
+type Webhook struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	// Version is the pinned payload version; archives from before versions
+	// existed omit it and restore as version 1.
+	Version   int       `json:"version,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Report describes what a restore did, or would do on a dry run.
+type Report struct {
+	DryRun  bool           `json:"dryRun"`
+	Created map[string]int `json:"created"`
+	Skipped []Skipped      `json:"skipped"`
+}
+
+// Skipped is an archived record that was not restored.
+type Skipped struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// Kinds of archived records, as reported in Report.
+const (
+	KindUser     = "user"
+	KindCategory = "category"
+	KindProduct  = "product"
+	KindWebhook  = "webhook"
+)
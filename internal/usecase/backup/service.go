@@ -0,0 +1,409 @@
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	categorydomain "backoffice/backend/internal/domain/category"
+	productdomain "backoffice/backend/internal/domain/product"
+	webhookdomain "backoffice/backend/internal/domain/webhook"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidArchive wraps archives that cannot be restored at all.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// Service exports the backoffice data to an Archive and restores archives
+// into another environment. Restores write to the repositories directly, so
+// they publish no domain events.
+type Service struct {
+	users      authdomain.UserRepository
+	categories categorydomain.Repository
+	products   productdomain.Repository
+	webhooks   webhookdomain.Repository
+	nowFunc    func() time.Time
+}
+
+// NewService constructs a backup service over the given repositories.
+func NewService(users authdomain.UserRepository, categories categorydomain.Repository, products productdomain.Repository, webhooks webhookdomain.Repository) *Service {
+	return &Service{
+		users:      users,
+		categories: categories,
+		products:   products,
+		webhooks:   webhooks,
+		nowFunc:    time.Now,
+	}
+}
+
+// Export copies every live record into an archive.
+func (s *Service) Export(ctx context.Context) (*Archive, error) {
+	archive := &Archive{
+		Format:     Format,
+		Version:    Version,
+		CreatedAt:  s.nowFunc().UTC(),
+		Users:      []authdomain.Summary{},
+		Categories: []*categorydomain.Category{},
+		Products:   []*productdomain.Product{},
+		Settings:   Settings{Webhooks: []Webhook{}},
+	}
+	users, err := s.users.List(ctx, authdomain.UserFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("exporting users: %w", err)
+	}
+	for _, u := range users {
+		archive.Users = append(archive.Users, u.Summary())
+	}
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("exporting categories: %w", err)
+	}
+	archive.Categories = append(archive.Categories, categories...)
+	products, err := s.products.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("exporting products: %w", err)
+	}
+	archive.Products = append(archive.Products, products...)
+	subs, err := s.webhooks.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("exporting webhooks: %w", err)
+	}
+	for _, sub := range subs {
+		archive.Settings.Webhooks = append(archive.Settings.Webhooks, Webhook{
+			ID:          sub.ID,
+			URL:         sub.URL,
+			Events:      sub.Events,
+			Description: sub.Description,
+			Active:      sub.Active,
+			Version:     sub.Version,
+			CreatedAt:   sub.CreatedAt,
+		})
+	}
+	return archive, nil
+}
+
+// RestoreInput is a restore request.
+type RestoreInput struct {
+	Archive Archive `json:"archive"`
+	// Password becomes every restored user's password. Without it restored
+	// users cannot sign in.
+	Password string `json:"password"`
+	DryRun   bool   `json:"-"`
+}
+
+// Restore loads an archive, keeping record ids so references stay valid.
+// Records that already exist, by id or by email, slug, SKU or barcode, are skipped
+// and reported; products of a skipped category are attached to the
+// existing category with that slug. Webhooks are restored inactive with new
+// secrets so a copy never delivers to the original's receivers.
+//
+// The archive is checked in full before anything is written, and a dry run
+// stops there. Restores are not atomic: if a write fails the records before
+// it stay.
+func (s *Service) Restore(ctx context.Context, input RestoreInput) (*Report, error) {
+	archive := input.Archive
+	if err := validate(archive); err != nil {
+		return nil, err
+	}
+	passwordHash := ""
+	if len(input.Password) > authdomain.MaxPasswordBytes {
+		return nil, authdomain.ErrPasswordTooLong
+	}
+	if input.Password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = string(hashed)
+	}
+
+	report := &Report{
+		DryRun:  input.DryRun,
+		Created: map[string]int{KindUser: 0, KindCategory: 0, KindProduct: 0, KindWebhook: 0},
+		Skipped: []Skipped{},
+	}
+	skip := func(kind, id, reason string) {
+		report.Skipped = append(report.Skipped, Skipped{Kind: kind, ID: id, Reason: reason})
+	}
+	// created counts a record, writing it unless this is a dry run. A
+	// conflict the checks below missed, such as a trashed record holding
+	// the same key, is reported as a skip.
+	created := func(kind, id string, write func() error) error {
+		if !input.DryRun {
+			err := write()
+			switch {
+			case errors.Is(err, authdomain.ErrEmailExists),
+				errors.Is(err, categorydomain.ErrDuplicateSlug),
+				errors.Is(err, productdomain.ErrDuplicateSKU),
+				errors.Is(err, productdomain.ErrDuplicateBarcode):
+				skip(kind, id, err.Error())
+				return nil
+			case err != nil:
+				return fmt.Errorf("restoring %s %s: %w", kind, id, err)
+			}
+		}
+		report.Created[kind]++
+		return nil
+	}
+
+	for _, summary := range archive.Users {
+		if reason, err := s.userConflict(ctx, summary); err != nil {
+			return nil, err
+		} else if reason != "" {
+			skip(KindUser, summary.ID, reason)
+			continue
+		}
+		user := &authdomain.User{
+			ID:           summary.ID,
+			Email:        summary.Email,
+			Name:         summary.Name,
+			Role:         summary.Role,
+			PasswordHash: passwordHash,
+			Locale:       summary.Locale,
+			Timezone:     summary.Timezone,
+			CreatedAt:    summary.CreatedAt,
+			UpdatedAt:    summary.UpdatedAt,
+		}
+		if err := created(KindUser, user.ID, func() error { return s.users.Create(ctx, user) }); err != nil {
+			return nil, err
+		}
+	}
+
+	// categoryIDs maps archived category ids to the ids products should use.
+	categoryIDs := map[string]string{}
+	for _, category := range archive.Categories {
+		existing, reason, err := s.categoryConflict(ctx, category)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			categoryIDs[category.ID] = existing
+			skip(KindCategory, category.ID, reason)
+			continue
+		}
+		categoryIDs[category.ID] = category.ID
+		category := *category
+		if err := created(KindCategory, category.ID, func() error { return s.categories.Create(ctx, &category) }); err != nil {
+			return nil, err
+		}
+	}
+
+	// Products already present are looked up together rather than one
+	// query per archived product.
+	productIDs := make([]string, 0, len(archive.Products))
+	for _, product := range archive.Products {
+		productIDs = append(productIDs, product.ID)
+	}
+	existing, err := s.products.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	existingProducts := make(map[string]bool, len(existing))
+	for _, product := range existing {
+		existingProducts[product.ID] = true
+	}
+	for _, product := range archive.Products {
+		if reason, err := s.productConflict(ctx, product, existingProducts); err != nil {
+			return nil, err
+		} else if reason != "" {
+			skip(KindProduct, product.ID, reason)
+			continue
+		}
+		product := *product
+		if product.CategoryID != "" {
+			product.CategoryID = categoryIDs[product.CategoryID]
+		}
+		// Archives from before units were recorded count in pieces; validate
+		// has rejected units that do not parse.
+		product.Unit, _ = productdomain.ParseUnit(string(product.Unit))
+		if product.PackSize == 0 {
+			product.PackSize = 1
+		}
+		product.SetReserved(0)
+		if err := created(KindProduct, product.ID, func() error { return s.products.Create(ctx, &product) }); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, webhook := range archive.Settings.Webhooks {
+		_, err := s.webhooks.GetSubscription(ctx, webhook.ID)
+		switch {
+		case err == nil:
+			skip(KindWebhook, webhook.ID, "a webhook with this id exists")
+			continue
+		case !errors.Is(err, webhookdomain.ErrNotFound):
+			return nil, err
+		}
+		now := s.nowFunc().UTC()
+		sub := &webhookdomain.Subscription{
+			ID:          webhook.ID,
+			URL:         webhook.URL,
+			Events:      webhook.Events,
+			Description: webhook.Description,
+			Secret:      newSecret(),
+			Active:      false,
+			Version:     max(webhook.Version, webhookdomain.PayloadV1),
+			CreatedAt:   webhook.CreatedAt,
+			UpdatedAt:   now,
+		}
+		if err := created(KindWebhook, sub.ID, func() error { return s.webhooks.CreateSubscription(ctx, sub) }); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// userConflict explains why an archived user cannot be restored, or
+// returns "" when it can.
+func (s *Service) userConflict(ctx context.Context, summary authdomain.Summary) (string, error) {
+	if _, err := s.users.GetByID(ctx, summary.ID); err == nil {
+		return "a user with this id exists", nil
+	} else if !errors.Is(err, authdomain.ErrUserNotFound) {
+		return "", err
+	}
+	if _, err := s.users.GetByEmail(ctx, summary.Email); err == nil {
+		return "a user with this email exists", nil
+	} else if !errors.Is(err, authdomain.ErrUserNotFound) {
+		return "", err
+	}
+	return "", nil
+}
+
+// categoryConflict is userConflict for categories; it also returns the id
+// of the existing category that takes the archived one's place.
+func (s *Service) categoryConflict(ctx context.Context, category *categorydomain.Category) (string, string, error) {
+	if _, err := s.categories.GetByID(ctx, category.ID); err == nil {
+		return category.ID, "a category with this id exists", nil
+	} else if !errors.Is(err, categorydomain.ErrNotFound) {
+		return "", "", err
+	}
+	if existing, err := s.categories.GetBySlug(ctx, category.Slug); err == nil {
+		return existing.ID, "a category with this slug exists; its products use the existing one", nil
+	} else if !errors.Is(err, categorydomain.ErrNotFound) {
+		return "", "", err
+	}
+	return "", "", nil
+}
+
+// productConflict is userConflict for products; existing holds the ids of
+// archived products that are already present.
+func (s *Service) productConflict(ctx context.Context, product *productdomain.Product, existing map[string]bool) (string, error) {
+	if existing[product.ID] {
+		return "a product with this id exists", nil
+	}
+	if _, err := s.products.GetBySKU(ctx, product.SKU); err == nil {
+		return "a product with this SKU exists", nil
+	} else if !errors.Is(err, productdomain.ErrNotFound) {
+		return "", err
+	}
+	if product.Barcode == "" {
+		return "", nil
+	}
+	if _, err := s.products.GetByBarcode(ctx, product.Barcode); err == nil {
+		return "a product with this barcode exists", nil
+	} else if !errors.Is(err, productdomain.ErrNotFound) {
+		return "", err
+	}
+	return "", nil
+}
+
+// validate rejects archives that are malformed or inconsistent in
+// themselves, listing every problem found.
+func validate(a Archive) error {
+	var problems []string
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	if a.Format != Format {
+		addProblem("format must be %q", Format)
+	}
+	if a.Version != Version {
+		addProblem("version %d is not supported (expected %d)", a.Version, Version)
+	}
+
+	ids := map[string]bool{}
+	unique := func(kind, id, key, value string) {
+		if id == "" {
+			addProblem("a %s has no id", kind)
+		} else if ids[kind+"/"+id] {
+			addProblem("%s id %s appears twice", kind, id)
+		}
+		ids[kind+"/"+id] = true
+		if key == "" {
+			return
+		}
+		if value == "" {
+			addProblem("%s %s has no %s", kind, id, key)
+		} else if ids[kind+"/"+key+"/"+value] {
+			addProblem("%s %s %q appears twice", kind, key, value)
+		}
+		ids[kind+"/"+key+"/"+value] = true
+	}
+	for _, u := range a.Users {
+		unique(KindUser, u.ID, "email", strings.ToLower(u.Email))
+		if u.Role != authdomain.RoleUser && u.Role != authdomain.RoleAdmin {
+			addProblem("user %s has invalid role %q", u.ID, u.Role)
+		}
+	}
+	for _, c := range a.Categories {
+		if c == nil {
+			addProblem("categories contains null")
+			continue
+		}
+		unique(KindCategory, c.ID, "slug", c.Slug)
+	}
+	for _, p := range a.Products {
+		if p == nil {
+			addProblem("products contains null")
+			continue
+		}
+		unique(KindProduct, p.ID, "sku", p.SKU)
+		if p.Barcode != "" {
+			if !productdomain.ValidBarcode(p.Barcode) {
+				addProblem("product %s has invalid barcode %q", p.ID, p.Barcode)
+			} else if ids[KindProduct+"/barcode/"+p.Barcode] {
+				addProblem("%s barcode %q appears twice", KindProduct, p.Barcode)
+			}
+			ids[KindProduct+"/barcode/"+p.Barcode] = true
+		}
+		if p.CategoryID != "" && !ids[KindCategory+"/"+p.CategoryID] {
+			addProblem("product %s refers to category %s, which is not in the archive", p.ID, p.CategoryID)
+		}
+		if p.Price < 0 || p.Quantity < 0 {
+			addProblem("product %s has a negative price or quantity", p.ID)
+		}
+		if _, err := productdomain.ParseUnit(string(p.Unit)); err != nil {
+			addProblem("product %s has unknown unit %q", p.ID, p.Unit)
+		}
+		if p.PackSize < 0 {
+			addProblem("product %s has a negative pack size", p.ID)
+		}
+	}
+	for _, w := range a.Settings.Webhooks {
+		unique(KindWebhook, w.ID, "", "")
+		if w.URL == "" {
+			addProblem("webhook %s has no url", w.ID)
+		}
+		if w.Version > webhookdomain.LatestPayloadVersion {
+			addProblem("webhook %s is pinned to payload version %d, newer than this server's %d", w.ID, w.Version, webhookdomain.LatestPayloadVersion)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// newSecret generates a webhook signing secret in the webhook service's
+// format.
+func newSecret() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return "whsec_" + hex.EncodeToString(buf)
+}
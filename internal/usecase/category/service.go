@@ -0,0 +1,188 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	domain "backoffice/backend/internal/domain/category"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+
+	"github.com/google/uuid"
+)
+
+// Service encapsulates category use cases.
+type Service struct {
+	repo    domain.Repository
+	events  event.Publisher
+	nowFunc func() time.Time
+}
+
+// NewService constructs a category service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{
+		repo:    repo,
+		events:  event.Discard,
+		nowFunc: time.Now,
+	}
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// CreateInput contains the payload required for category creation.
+type CreateInput struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+}
+
+// UpdateInput encapsulates partial category updates.
+type UpdateInput struct {
+	Name        *string `json:"name"`
+	Slug        *string `json:"slug"`
+	Description *string `json:"description"`
+}
+
+// Create stores a new category, deriving the slug from the name when omitted.
+func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Category, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, errcode.New(errcode.Invalid, "name_required", "name is required")
+	}
+	slug := Slugify(input.Slug)
+	if slug == "" {
+		slug = Slugify(input.Name)
+	}
+	if slug == "" {
+		return nil, errcode.New(errcode.Invalid, "slug_required", "slug is required")
+	}
+
+	if _, err := s.repo.GetBySlug(ctx, slug); err == nil {
+		return nil, domain.ErrDuplicateSlug
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	now := s.nowFunc().UTC()
+	category := &domain.Category{
+		ID:          uuid.NewString(),
+		Name:        input.Name,
+		Slug:        slug,
+		Description: input.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(event.CategoryCreated, category.ID, category))
+	return category, nil
+}
+
+// List retrieves all categories.
+func (s *Service) List(ctx context.Context) ([]*domain.Category, error) {
+	return s.repo.List(ctx)
+}
+
+// Get fetches a category by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Category, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetByIDs fetches the categories among ids in one lookup; unknown ids are
+// skipped.
+func (s *Service) GetByIDs(ctx context.Context, ids []string) ([]*domain.Category, error) {
+	return s.repo.GetByIDs(ctx, ids)
+}
+
+// GetBySlug fetches a category by slug.
+func (s *Service) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	return s.repo.GetBySlug(ctx, Slugify(slug))
+}
+
+// Update applies partial updates to a category.
+func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*domain.Category, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errcode.New(errcode.Invalid, "name_empty", "name cannot be empty")
+		}
+		category.Name = name
+	}
+	if input.Slug != nil {
+		slug := Slugify(*input.Slug)
+		if slug == "" {
+			return nil, errcode.New(errcode.Invalid, "slug_empty", "slug cannot be empty")
+		}
+		if slug != category.Slug {
+			if _, err := s.repo.GetBySlug(ctx, slug); err == nil {
+				return nil, domain.ErrDuplicateSlug
+			} else if !errors.Is(err, domain.ErrNotFound) {
+				return nil, err
+			}
+		}
+		category.Slug = slug
+	}
+	if input.Description != nil {
+		category.Description = *input.Description
+	}
+	category.UpdatedAt = s.nowFunc().UTC()
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(event.CategoryUpdated, category.ID, category))
+	return category, nil
+}
+
+// Delete removes a category that no product references.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, event.New(event.CategoryDeleted, id, map[string]string{"id": id}))
+	return nil
+}
+
+// Slugify lowercases s and collapses everything but letters and digits into
+// single hyphens.
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,258 @@
+// Package consent publishes versions of the terms of service and privacy
+// policy and tracks which of them each user has accepted.
+package consent
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	domain "backoffice/backend/internal/domain/consent"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/errreport"
+)
+
+const (
+	maxVersionLength = 64
+	// requiredTTL is how long the required versions are cached. A version
+	// published on another instance is required here after at most this
+	// long.
+	requiredTTL = time.Minute
+)
+
+var (
+	// ErrInvalidDocument rejects versions of unknown documents.
+	ErrInvalidDocument = errcode.New(errcode.Invalid, "policy_document_invalid", "document must be terms or privacy").With("supported", domain.Documents)
+	// ErrVersionRequired rejects a version without a name.
+	ErrVersionRequired = errcode.New(errcode.Invalid, "policy_version_required", "policy version is required")
+	// ErrVersionTooLong rejects a version name longer than maxVersionLength.
+	ErrVersionTooLong = errcode.New(errcode.Invalid, "policy_version_too_long", "policy version is too long").With("maxLength", maxVersionLength)
+	// ErrInvalidURL rejects a URL that is not absolute http or https.
+	ErrInvalidURL = errcode.New(errcode.Invalid, "policy_url_invalid", "policy url must be an absolute http or https URL")
+	// ErrConsentRequired blocks users who have not accepted the newest
+	// mandatory versions; its "pending" meta lists them.
+	ErrConsentRequired = errcode.New(errcode.Forbidden, "consent_required", "the latest terms must be accepted first")
+)
+
+// Service publishes versions and records consents. Which versions are
+// required is cached for requiredTTL, and users known to have accepted them
+// are remembered, so checking a request rarely reads the database.
+type Service struct {
+	repo    domain.Repository
+	nowFunc func() time.Time
+
+	mu       sync.Mutex
+	required []*domain.Version
+	loadedAt time.Time
+	// accepted maps user ids to the requiredKey of the versions the user
+	// was last seen to have accepted. Consents are never withdrawn, so
+	// only users with nothing pending are remembered.
+	accepted sync.Map
+}
+
+// NewService constructs a consent service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{repo: repo, nowFunc: time.Now}
+}
+
+// PublishInput describes a new version.
+type PublishInput struct {
+	Document  string `json:"document"`
+	Version   string `json:"version"`
+	Mandatory bool   `json:"mandatory"`
+	URL       string `json:"url"`
+}
+
+// Publish stores a new version of a document. A mandatory version is
+// required of every user from then on, blocking those who have not
+// accepted it.
+func (s *Service) Publish(ctx context.Context, publishedBy string, input PublishInput) (*domain.Version, error) {
+	v := &domain.Version{
+		Document:    strings.ToLower(strings.TrimSpace(input.Document)),
+		Version:     strings.TrimSpace(input.Version),
+		Mandatory:   input.Mandatory,
+		URL:         strings.TrimSpace(input.URL),
+		PublishedBy: publishedBy,
+		PublishedAt: s.nowFunc().UTC(),
+	}
+	switch {
+	case !slices.Contains(domain.Documents, v.Document):
+		return nil, ErrInvalidDocument
+	case v.Version == "":
+		return nil, ErrVersionRequired
+	case utf8.RuneCountInString(v.Version) > maxVersionLength:
+		return nil, ErrVersionTooLong
+	}
+	if v.URL != "" {
+		u, err := url.Parse(v.URL)
+		if err != nil || u.Host == "" || u.Scheme != "http" && u.Scheme != "https" {
+			return nil, ErrInvalidURL
+		}
+	}
+	if err := s.repo.Publish(ctx, v); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+	return v, nil
+}
+
+// Versions returns every published version, newest first.
+func (s *Service) Versions(ctx context.Context) ([]*domain.Version, error) {
+	versions, err := s.repo.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []*domain.Version{}
+	}
+	return versions, nil
+}
+
+// Required returns the newest mandatory version of each document that has
+// one.
+func (s *Service) Required(ctx context.Context) ([]*domain.Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && s.nowFunc().Sub(s.loadedAt) < requiredTTL {
+		return s.required, nil
+	}
+	versions, err := s.repo.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var required []*domain.Version
+	for _, document := range domain.Documents {
+		for _, v := range versions {
+			if v.Document == document && v.Mandatory {
+				required = append(required, v)
+				break
+			}
+		}
+	}
+	s.required, s.loadedAt = required, s.nowFunc()
+	return required, nil
+}
+
+// requiredKey identifies a set of required versions.
+func requiredKey(required []*domain.Version) string {
+	var b strings.Builder
+	for _, v := range required {
+		b.WriteString(v.Document + "\x00" + v.Version + "\x00")
+	}
+	return b.String()
+}
+
+// Pending returns the required versions the user has yet to accept, empty
+// when there are none.
+func (s *Service) Pending(ctx context.Context, userID string) ([]*domain.Version, error) {
+	required, err := s.Required(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := []*domain.Version{}
+	if len(required) == 0 {
+		return pending, nil
+	}
+	key := requiredKey(required)
+	if cached, ok := s.accepted.Load(userID); ok && cached.(string) == key {
+		return pending, nil
+	}
+	consents, err := s.repo.Consents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range required {
+		if !slices.ContainsFunc(consents, func(c *domain.Consent) bool {
+			return c.Document == v.Document && c.Version == v.Version
+		}) {
+			pending = append(pending, v)
+		}
+	}
+	if len(pending) == 0 {
+		s.accepted.Store(userID, key)
+	}
+	return pending, nil
+}
+
+// Consents returns the user's consents, newest first.
+func (s *Service) Consents(ctx context.Context, userID string) ([]*domain.Consent, error) {
+	consents, err := s.repo.Consents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if consents == nil {
+		consents = []*domain.Consent{}
+	}
+	return consents, nil
+}
+
+// Check verifies that every ref names a published version.
+func (s *Service) Check(ctx context.Context, refs []domain.Ref) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	versions, err := s.repo.Versions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if !slices.ContainsFunc(versions, func(v *domain.Version) bool { return v.Ref() == ref }) {
+			return domain.ErrVersionNotFound.With("document", ref.Document).With("version", ref.Version)
+		}
+	}
+	return nil
+}
+
+// CheckRegistration verifies, before an account is created, that refs
+// name published versions and cover every required one.
+func (s *Service) CheckRegistration(ctx context.Context, refs []domain.Ref) error {
+	if err := s.Check(ctx, refs); err != nil {
+		return err
+	}
+	required, err := s.Required(ctx)
+	if err != nil {
+		return err
+	}
+	var pending []*domain.Version
+	for _, v := range required {
+		if !slices.Contains(refs, v.Ref()) {
+			pending = append(pending, v)
+		}
+	}
+	if len(pending) > 0 {
+		return ErrConsentRequired.With("pending", pending)
+	}
+	return nil
+}
+
+// Accept records the user accepting every ref, attributed to the request's
+// client address.
+func (s *Service) Accept(ctx context.Context, userID string, refs []domain.Ref) error {
+	if err := s.Check(ctx, refs); err != nil {
+		return err
+	}
+	now := s.nowFunc().UTC()
+	var clientIP string
+	if scope := errreport.ScopeFromContext(ctx); scope != nil {
+		clientIP = scope.ClientIP
+	}
+	for _, ref := range refs {
+		err := s.repo.Accept(ctx, &domain.Consent{
+			UserID:     userID,
+			Document:   ref.Document,
+			Version:    ref.Version,
+			ClientIP:   clientIP,
+			AcceptedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
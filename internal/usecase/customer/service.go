@@ -0,0 +1,161 @@
+// Package customer manages customers and their address books.
+package customer
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	domain "backoffice/backend/internal/domain/customer"
+	"backoffice/backend/internal/domain/errcode"
+
+	"github.com/google/uuid"
+)
+
+// ErrNameRequired rejects a customer without a name.
+var ErrNameRequired = errcode.New(errcode.Invalid, "customer_name_required", "customer name is required")
+
+// Service encapsulates customer use cases.
+type Service struct {
+	repo    domain.Repository
+	nowFunc func() time.Time
+}
+
+// NewService constructs a customer service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{
+		repo:    repo,
+		nowFunc: time.Now,
+	}
+}
+
+// CreateInput describes a new customer.
+type CreateInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// Create stores a new customer.
+func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Customer, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrNameRequired
+	}
+	now := s.nowFunc().UTC()
+	customer := &domain.Customer{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Email:     strings.ToLower(strings.TrimSpace(input.Email)),
+		Phone:     strings.TrimSpace(input.Phone),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(ctx, customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// List returns every customer ordered by name.
+func (s *Service) List(ctx context.Context) ([]*domain.Customer, error) {
+	return s.repo.List(ctx)
+}
+
+// Get fetches a customer by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Customer, error) {
+	return s.repo.GetByID(ctx, strings.TrimSpace(id))
+}
+
+// AddressInput describes an address; saving it replaces every field.
+type AddressInput struct {
+	Label           string `json:"label"`
+	Recipient       string `json:"recipient"`
+	Line1           string `json:"line1"`
+	Line2           string `json:"line2"`
+	City            string `json:"city"`
+	Region          string `json:"region"`
+	PostalCode      string `json:"postalCode"`
+	Country         string `json:"country"`
+	DefaultBilling  bool   `json:"defaultBilling"`
+	DefaultShipping bool   `json:"defaultShipping"`
+}
+
+func (in AddressInput) apply(a *domain.Address) {
+	a.Label = in.Label
+	a.Recipient = in.Recipient
+	a.Line1 = in.Line1
+	a.Line2 = in.Line2
+	a.City = in.City
+	a.Region = in.Region
+	a.PostalCode = in.PostalCode
+	a.Country = in.Country
+	a.DefaultBilling = in.DefaultBilling
+	a.DefaultShipping = in.DefaultShipping
+	a.Normalize()
+}
+
+// Addresses returns a customer's address book, oldest first.
+func (s *Service) Addresses(ctx context.Context, customerID string) ([]*domain.Address, error) {
+	customer, err := s.Get(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Addresses(ctx, customer.ID)
+}
+
+// GetAddress fetches one of a customer's addresses.
+func (s *Service) GetAddress(ctx context.Context, customerID, id string) (*domain.Address, error) {
+	return s.repo.GetAddress(ctx, strings.TrimSpace(customerID), strings.TrimSpace(id))
+}
+
+// AddAddress validates an address and adds it to the customer's address
+// book. A customer's first address becomes the default for both billing and
+// shipping.
+func (s *Service) AddAddress(ctx context.Context, customerID string, input AddressInput) (*domain.Address, error) {
+	customer, err := s.Get(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.nowFunc().UTC()
+	address := &domain.Address{ID: uuid.NewString(), CustomerID: customer.ID, CreatedAt: now, UpdatedAt: now}
+	input.apply(address)
+	if err := address.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.Addresses(ctx, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		address.DefaultBilling, address.DefaultShipping = true, true
+	}
+	if err := s.repo.SaveAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// UpdateAddress validates and replaces one of a customer's addresses.
+func (s *Service) UpdateAddress(ctx context.Context, customerID, id string, input AddressInput) (*domain.Address, error) {
+	address, err := s.GetAddress(ctx, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+	input.apply(address)
+	if err := address.Validate(); err != nil {
+		return nil, err
+	}
+	address.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.SaveAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress removes one of a customer's addresses. Deleting a default
+// leaves the customer without a default of that kind until another address
+// is made one.
+func (s *Service) DeleteAddress(ctx context.Context, customerID, id string) error {
+	return s.repo.DeleteAddress(ctx, strings.TrimSpace(customerID), strings.TrimSpace(id))
+}
@@ -0,0 +1,197 @@
+// Package integration manages the external systems that push signed
+// payloads to the backoffice and verifies their requests.
+package integration
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	domain "backoffice/backend/internal/domain/integration"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
+
+	"github.com/google/uuid"
+)
+
+// Request headers every inbound payload must carry. The signature uses the
+// outgoing webhook scheme: "sha256=" and the hex HMAC-SHA256 of
+// "timestamp.body" keyed with the integration's secret.
+const (
+	HeaderTimestamp = "X-Integration-Timestamp"
+	HeaderSignature = "X-Integration-Signature"
+)
+
+// Tolerance is how far a request timestamp may be from the server clock.
+// Older requests are rejected so captured payloads cannot be replayed.
+const Tolerance = 5 * time.Minute
+
+// Kinds of payload an integration may push.
+const (
+	// KindStockFeed sets product quantities by SKU, for supplier stock-feed
+	// callbacks.
+	KindStockFeed = "stock-feed"
+)
+
+// Kinds lists every supported kind.
+var Kinds = []string{KindStockFeed}
+
+var (
+	// ErrInvalidSignature rejects requests whose signature is missing or
+	// does not match the body.
+	ErrInvalidSignature = errcode.New(errcode.Unauthenticated, "signature_invalid", "invalid signature")
+	// ErrStaleRequest rejects requests whose timestamp is missing or outside
+	// the Tolerance window.
+	ErrStaleRequest = errcode.New(errcode.Unauthenticated, "request_stale", "request timestamp is missing or too old")
+)
+
+// Service manages integrations.
+type Service struct {
+	repo    domain.Repository
+	nowFunc func() time.Time
+}
+
+// NewService constructs an integration service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{repo: repo, nowFunc: time.Now}
+}
+
+// CreateInput contains the payload required to register an integration.
+type CreateInput struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+}
+
+// UpdateInput encapsulates partial integration updates. The kind cannot be
+// changed; register a new integration instead.
+type UpdateInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Active      *bool   `json:"active"`
+	// RotateSecret replaces the signing secret with a newly generated one.
+	RotateSecret bool `json:"rotateSecret"`
+}
+
+// Create registers an integration with a generated secret. The returned
+// integration carries the secret, which is not exposed again afterwards.
+func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Integration, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errcode.New(errcode.Invalid, "name_required", "name is required")
+	}
+	kind := strings.TrimSpace(input.Kind)
+	if !slices.Contains(Kinds, kind) {
+		return nil, fmt.Errorf("%w: %s", errcode.New(errcode.Invalid, "kind_invalid", "kind must be one of"), strings.Join(Kinds, ", "))
+	}
+
+	now := s.nowFunc().UTC()
+	integration := &domain.Integration{
+		ID:          uuid.NewString(),
+		Name:        name,
+		Kind:        kind,
+		Description: strings.TrimSpace(input.Description),
+		Secret:      generateSecret(),
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, integration); err != nil {
+		return nil, err
+	}
+	return integration, nil
+}
+
+// List retrieves all integrations.
+func (s *Service) List(ctx context.Context) ([]*domain.Integration, error) {
+	return s.repo.List(ctx)
+}
+
+// Get fetches an integration by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Integration, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Update applies partial updates to an integration.
+func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*domain.Integration, error) {
+	integration, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errcode.New(errcode.Invalid, "name_empty", "name cannot be empty")
+		}
+		integration.Name = name
+	}
+	if input.Description != nil {
+		integration.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Active != nil {
+		integration.Active = *input.Active
+	}
+	if input.RotateSecret {
+		integration.Secret = generateSecret()
+	}
+	integration.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.Update(ctx, integration); err != nil {
+		return nil, err
+	}
+	return integration, nil
+}
+
+// Delete removes an integration; its requests are refused from then on.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Verify authenticates a request from integration id: its timestamp must be
+// within Tolerance and its signature must match body. Unknown and paused
+// integrations are reported as domain.ErrNotFound.
+func (s *Service) Verify(ctx context.Context, id, timestamp, signature string, body []byte) (*domain.Integration, error) {
+	integration, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !integration.Active {
+		return nil, domain.ErrNotFound
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return nil, ErrStaleRequest
+	}
+	if age := s.nowFunc().Sub(time.Unix(seconds, 0)); age > Tolerance || age < -Tolerance {
+		return nil, ErrStaleRequest
+	}
+	given, ok := strings.CutPrefix(strings.TrimSpace(signature), "sha256=")
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+	expected := webhookusecase.Sign(integration.Secret, strings.TrimSpace(timestamp), body)
+	if !hmac.Equal([]byte(strings.ToLower(given)), []byte(expected)) {
+		return nil, ErrInvalidSignature
+	}
+	return integration, nil
+}
+
+// generateSecret creates a random signing secret.
+func generateSecret() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return "whsec_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	domain "backoffice/backend/internal/domain/integration"
+	"backoffice/backend/internal/infrastructure/memory"
+	webhookusecase "backoffice/backend/internal/usecase/webhook"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	svc := NewService(memory.NewIntegrationRepository())
+	svc.nowFunc = func() time.Time { return now }
+	feed, err := svc.Create(ctx, CreateInput{Name: "Acme supplier", Kind: KindStockFeed})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	paused, err := svc.Create(ctx, CreateInput{Name: "Old supplier", Kind: KindStockFeed})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	inactive := false
+	if _, err := svc.Update(ctx, paused.ID, UpdateInput{Active: &inactive}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	body := []byte(`{"items":[{"sku":"RICE-1","quantity":42}]}`)
+	stamp := func(at time.Time) string { return strconv.FormatInt(at.Unix(), 10) }
+	sign := func(secret string, at time.Time, body []byte) string {
+		return "sha256=" + webhookusecase.Sign(secret, stamp(at), body)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		id        string
+		timestamp string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{name: "valid", id: feed.ID, timestamp: stamp(now), signature: sign(feed.Secret, now, body), body: body},
+		{name: "valid within the window", id: feed.ID, timestamp: stamp(now.Add(-Tolerance)), signature: sign(feed.Secret, now.Add(-Tolerance), body), body: body},
+		{name: "clock skew within the window", id: feed.ID, timestamp: stamp(now.Add(Tolerance)), signature: sign(feed.Secret, now.Add(Tolerance), body), body: body},
+		{name: "tampered body", id: feed.ID, timestamp: stamp(now), signature: sign(feed.Secret, now, body), body: []byte(`{"items":[{"sku":"RICE-1","quantity":0}]}`), want: ErrInvalidSignature},
+		{name: "wrong secret", id: feed.ID, timestamp: stamp(now), signature: sign(paused.Secret, now, body), body: body, want: ErrInvalidSignature},
+		{name: "timestamp changed after signing", id: feed.ID, timestamp: stamp(now.Add(time.Second)), signature: sign(feed.Secret, now, body), body: body, want: ErrInvalidSignature},
+		{name: "missing signature", id: feed.ID, timestamp: stamp(now), body: body, want: ErrInvalidSignature},
+		{name: "signature without scheme", id: feed.ID, timestamp: stamp(now), signature: webhookusecase.Sign(feed.Secret, stamp(now), body), body: body, want: ErrInvalidSignature},
+		{name: "missing timestamp", id: feed.ID, signature: sign(feed.Secret, now, body), body: body, want: ErrStaleRequest},
+		{name: "malformed timestamp", id: feed.ID, timestamp: "yesterday", signature: sign(feed.Secret, now, body), body: body, want: ErrStaleRequest},
+		{name: "replayed after the window", id: feed.ID, timestamp: stamp(now.Add(-Tolerance - time.Second)), signature: sign(feed.Secret, now.Add(-Tolerance-time.Second), body), body: body, want: ErrStaleRequest},
+		{name: "timestamp too far ahead", id: feed.ID, timestamp: stamp(now.Add(Tolerance + time.Second)), signature: sign(feed.Secret, now.Add(Tolerance+time.Second), body), body: body, want: ErrStaleRequest},
+		{name: "paused integration", id: paused.ID, timestamp: stamp(now), signature: sign(paused.Secret, now, body), body: body, want: domain.ErrNotFound},
+		{name: "unknown integration", id: "missing", timestamp: stamp(now), signature: sign(feed.Secret, now, body), body: body, want: domain.ErrNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := svc.Verify(ctx, tc.id, tc.timestamp, tc.signature, tc.body)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Verify: err = %v, want %v", err, tc.want)
+			}
+			if tc.want == nil && got.ID != tc.id {
+				t.Fatalf("Verify = %s, want %s", got.ID, tc.id)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Package invoice renders order invoices as PDF files and emails them to
+// customers.
+package invoice
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"backoffice/backend/internal/domain/attachment"
+	customerdomain "backoffice/backend/internal/domain/customer"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/mail"
+	orderdomain "backoffice/backend/internal/domain/order"
+)
+
+// ErrRecipientRequired rejects emailing an invoice when the order has no
+// customer with an email address and none was given.
+var ErrRecipientRequired = errcode.New(errcode.Invalid, "invoice_recipient_required", "invoice recipient is required")
+
+//go:embed invoice.tmpl
+var defaultTemplate string
+
+// Company is the seller printed at the top of every invoice.
+type Company struct {
+	Name    string
+	Address []string
+	TaxID   string
+	Email   string
+	Phone   string
+}
+
+// Orders fetches the order being invoiced. The order service implements it.
+type Orders interface {
+	Get(ctx context.Context, id string) (*orderdomain.Order, error)
+}
+
+// Customers fetches who an order is billed to. The customer service
+// implements it.
+type Customers interface {
+	Get(ctx context.Context, id string) (*customerdomain.Customer, error)
+	Addresses(ctx context.Context, customerID string) ([]*customerdomain.Address, error)
+}
+
+// Printer lays out lines of text as a PDF document.
+type Printer func(title string, lines []string) []byte
+
+// Document is a rendered invoice.
+type Document struct {
+	Number string
+	Name   string
+	Data   []byte
+}
+
+// Service renders invoices, keeping each one as an attachment so it is only
+// rendered again once its order changes.
+type Service struct {
+	orders    Orders
+	customers Customers
+	store     attachment.Store
+	company   Company
+	tmpl      *template.Template
+	print     Printer
+	sender    mail.Sender
+	nowFunc   func() time.Time
+}
+
+// NewService constructs an invoice service printing the built-in layout, or
+// the text/template file at templatePath when one is given.
+func NewService(orders Orders, customers Customers, store attachment.Store, print Printer, company Company, templatePath string) (*Service, error) {
+	text := defaultTemplate
+	if templatePath != "" {
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("read invoice template: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("invoice").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse invoice template: %w", err)
+	}
+	return &Service{
+		orders:    orders,
+		customers: customers,
+		store:     store,
+		company:   company,
+		tmpl:      tmpl,
+		print:     print,
+		nowFunc:   time.Now,
+	}, nil
+}
+
+// SetSender lets invoices be emailed; without one Email fails with
+// mail.ErrUnavailable.
+func (s *Service) SetSender(sender mail.Sender) {
+	s.sender = sender
+}
+
+// PDF returns the order's invoice, rendering it unless the order is
+// unchanged since it was last rendered.
+func (s *Service) PDF(ctx context.Context, orderID string) (*Document, error) {
+	order, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := s.render(ctx, order)
+	return doc, err
+}
+
+// EmailInput names who to email an invoice to; when To is empty it goes to
+// the order's customer.
+type EmailInput struct {
+	To string `json:"to"`
+}
+
+// Email sends the order's invoice as a PDF attachment and returns the
+// address it was sent to.
+func (s *Service) Email(ctx context.Context, orderID string, input EmailInput) (string, error) {
+	if s.sender == nil {
+		return "", mail.ErrUnavailable
+	}
+	order, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+	doc, customer, err := s.render(ctx, order)
+	if err != nil {
+		return "", err
+	}
+	to := strings.TrimSpace(input.To)
+	if to == "" && customer != nil {
+		to = customer.Email
+	}
+	if to == "" {
+		return "", ErrRecipientRequired
+	}
+	greeting := "Hello,"
+	if customer != nil {
+		greeting = "Dear " + customer.Name + ","
+	}
+	msg := mail.Message{
+		To:      []string{to},
+		Subject: fmt.Sprintf("Invoice %s from %s", doc.Number, s.company.Name),
+		Text: fmt.Sprintf("%s\n\nPlease find attached invoice %s for your order, totalling %.2f.\n\n%s\n",
+			greeting, doc.Number, order.Total, s.company.Name),
+		Attachments: []mail.Attachment{{Name: doc.Name, ContentType: "application/pdf", Data: doc.Data}},
+	}
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return "", fmt.Errorf("send invoice: %w", err)
+	}
+	return to, nil
+}
+
+// render returns the stored invoice for the order's current state, or
+// renders and stores it. The customer is returned when the order has one.
+func (s *Service) render(ctx context.Context, order *orderdomain.Order) (*Document, *customerdomain.Customer, error) {
+	number := Number(order.ID)
+	name := number + ".pdf"
+	key := fmt.Sprintf("invoices/%s/%d.pdf", order.ID, order.UpdatedAt.UnixNano())
+
+	var customer *customerdomain.Customer
+	var billTo *customerdomain.Address
+	if order.CustomerID != "" {
+		var err error
+		customer, err = s.customers.Get(ctx, order.CustomerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		addresses, err := s.customers.Addresses(ctx, order.CustomerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, a := range addresses {
+			if a.DefaultBilling {
+				billTo = a
+			}
+		}
+	}
+
+	stored, err := s.store.Get(ctx, key)
+	if err == nil {
+		return &Document{Number: number, Name: stored.Name, Data: stored.Data}, customer, nil
+	}
+	if !errors.Is(err, attachment.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{
+		"Company":  s.company,
+		"Number":   number,
+		"Date":     order.CreatedAt,
+		"Order":    order,
+		"Customer": customer,
+		"BillTo":   billTo,
+	}
+	if err := s.tmpl.Execute(&buf, data); err != nil {
+		return nil, nil, fmt.Errorf("render invoice: %w", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	doc := &Document{Number: number, Name: name, Data: s.print("Invoice "+number, lines)}
+	if err := s.store.Put(ctx, &attachment.Attachment{
+		Key:         key,
+		Name:        name,
+		ContentType: "application/pdf",
+		Data:        doc.Data,
+		CreatedAt:   s.nowFunc().UTC(),
+	}); err != nil {
+		return nil, nil, err
+	}
+	return doc, customer, nil
+}
+
+// Number is the invoice number printed for an order.
+func Number(orderID string) string {
+	id := strings.ReplaceAll(orderID, "-", "")
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return "INV-" + strings.ToUpper(id)
+}
+
+// funcs help templates line up columns in the fixed-width font.
+var funcs = template.FuncMap{
+	"left":  func(n int, s string) string { return pad(n, s, false) },
+	"right": func(n int, s string) string { return pad(n, s, true) },
+	"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"rule":  func(n int) string { return strings.Repeat("-", n) },
+}
+
+// pad fits s into n columns, truncating it when too long.
+func pad(n int, s string, right bool) string {
+	if utf8.RuneCountInString(s) > n {
+		return string([]rune(s)[:n])
+	}
+	fill := strings.Repeat(" ", n-utf8.RuneCountInString(s))
+	if right {
+		return fill + s
+	}
+	return s + fill
+}
@@ -0,0 +1,101 @@
+package notification
+
+import (
+	"fmt"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/notification"
+	productdomain "backoffice/backend/internal/domain/product"
+	shipmentdomain "backoffice/backend/internal/domain/shipment"
+	stocksyncdomain "backoffice/backend/internal/domain/stocksync"
+)
+
+// messageFor translates a domain event into the message for the
+// notification event it represents, if any.
+func messageFor(e event.Event) (domain.Message, bool) {
+	switch e.Type {
+	case event.SyncRunFailed:
+		run, ok := e.Data.(*stocksyncdomain.Run)
+		if !ok {
+			return domain.Message{}, false
+		}
+		return domain.Message{
+			Event: domain.EventImportFailed,
+			Title: "Import failed: " + run.Connector,
+			Text:  fmt.Sprintf("Sync run %s failed after fetching %d record(s): %s", run.ID, run.Fetched, run.Error),
+		}, true
+	case event.ProductOutOfStock:
+		product, ok := e.Data.(*productdomain.Product)
+		if !ok {
+			return domain.Message{}, false
+		}
+		return domain.Message{
+			Event: domain.EventStockOut,
+			Title: "Out of stock: " + product.Name,
+			Text:  fmt.Sprintf("Product %s (SKU %s) has no stock left.", product.Name, product.SKU),
+		}, true
+	case event.UserCreated:
+		user, ok := e.Data.(authdomain.Summary)
+		if !ok || user.Role != authdomain.RoleAdmin {
+			return domain.Message{}, false
+		}
+		return adminMessage(user, "A new admin account was created"), true
+	case event.UserRoleChanged:
+		data, ok := e.Data.(map[string]any)
+		if !ok {
+			return domain.Message{}, false
+		}
+		user, ok := data["user"].(authdomain.Summary)
+		if !ok || user.Role != authdomain.RoleAdmin {
+			return domain.Message{}, false
+		}
+		return adminMessage(user, "A user was promoted to admin"), true
+	case event.ShipmentStatusChanged:
+		shipment, ok := e.Data.(*shipmentdomain.Shipment)
+		if !ok {
+			return domain.Message{}, false
+		}
+		return shipmentMessage(shipment)
+	}
+	return domain.Message{}, false
+}
+
+func shipmentMessage(shipment *shipmentdomain.Shipment) (domain.Message, bool) {
+	parcel := fmt.Sprintf("Parcel %s (%s) for order %s", shipment.TrackingNumber, shipment.Carrier, shipment.OrderID)
+	switch shipment.Status {
+	case shipmentdomain.StatusDelivered:
+		return domain.Message{
+			Event: domain.EventShipmentDelivered,
+			Title: "Delivered: " + shipment.TrackingNumber,
+			Text:  parcel + " was delivered.",
+		}, true
+	case shipmentdomain.StatusException, shipmentdomain.StatusReturned:
+		what := "ran into a delivery exception"
+		if shipment.Status == shipmentdomain.StatusReturned {
+			what = "is being returned to sender"
+		}
+		text := parcel + " " + what + "."
+		if n := len(shipment.Events); n > 0 && shipment.Events[n-1].Description != "" {
+			text += " Carrier says: " + shipment.Events[n-1].Description
+		}
+		return domain.Message{
+			Event: domain.EventShipmentException,
+			Title: "Shipment problem: " + shipment.TrackingNumber,
+			Text:  text,
+		}, true
+	}
+	return domain.Message{}, false
+}
+
+func adminMessage(user authdomain.Summary, what string) domain.Message {
+	name := user.Email
+	if user.Name != "" {
+		name = user.Name + " <" + user.Email + ">"
+	}
+	return domain.Message{
+		Event: domain.EventAdminCreated,
+		Title: "New admin: " + user.Email,
+		Text:  fmt.Sprintf("%s: %s.", what, name),
+	}
+}
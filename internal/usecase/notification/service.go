@@ -0,0 +1,242 @@
+// Package notification manages the chat channels that are told about notable
+// events and delivers those messages in the background.
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/notification"
+	"backoffice/backend/internal/errreport"
+
+	"github.com/google/uuid"
+)
+
+// QueueSize bounds the messages waiting for delivery; further ones are
+// dropped.
+const QueueSize = 256
+
+var deliveryTags = map[string]string{"job": "notifications"}
+
+// ErrDeliveryFailed wraps errors from the chat tool when sending a test
+// message.
+var ErrDeliveryFailed = errors.New("notification delivery failed")
+
+// Service manages channels and notifies them. It is an event.Publisher:
+// events are translated to messages and queued for Run to deliver.
+type Service struct {
+	repo    domain.Repository
+	sender  domain.Sender
+	queue   chan domain.Message
+	nowFunc func() time.Time
+}
+
+// NewService constructs a notification service.
+func NewService(repo domain.Repository, sender domain.Sender) *Service {
+	return &Service{repo: repo, sender: sender, queue: make(chan domain.Message, QueueSize), nowFunc: time.Now}
+}
+
+// CreateInput contains the payload required to add a channel.
+type CreateInput struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	WebhookURL string   `json:"webhookUrl"`
+	Events     []string `json:"events"`
+}
+
+// UpdateInput encapsulates partial channel updates. The kind cannot be
+// changed; add a new channel instead.
+type UpdateInput struct {
+	Name       *string   `json:"name"`
+	WebhookURL *string   `json:"webhookUrl"`
+	Events     *[]string `json:"events"`
+	Active     *bool     `json:"active"`
+}
+
+// Create adds an active channel.
+func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Channel, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errcode.New(errcode.Invalid, "name_required", "name is required")
+	}
+	kind := strings.TrimSpace(input.Kind)
+	if !slices.Contains(domain.Kinds, kind) {
+		return nil, fmt.Errorf("%w: %s", errcode.New(errcode.Invalid, "kind_invalid", "kind must be one of"), strings.Join(domain.Kinds, ", "))
+	}
+	webhookURL, err := normalizeWebhookURL(input.WebhookURL)
+	if err != nil {
+		return nil, err
+	}
+	events, err := normalizeEvents(input.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.nowFunc().UTC()
+	channel := &domain.Channel{
+		ID:         uuid.NewString(),
+		Name:       name,
+		Kind:       kind,
+		WebhookURL: webhookURL,
+		Events:     events,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.repo.Create(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// List retrieves all channels.
+func (s *Service) List(ctx context.Context) ([]*domain.Channel, error) {
+	return s.repo.List(ctx)
+}
+
+// Get fetches a channel by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Channel, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Update applies partial updates to a channel.
+func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*domain.Channel, error) {
+	channel, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errcode.New(errcode.Invalid, "name_empty", "name cannot be empty")
+		}
+		channel.Name = name
+	}
+	if input.WebhookURL != nil {
+		if channel.WebhookURL, err = normalizeWebhookURL(*input.WebhookURL); err != nil {
+			return nil, err
+		}
+	}
+	if input.Events != nil {
+		if channel.Events, err = normalizeEvents(*input.Events); err != nil {
+			return nil, err
+		}
+	}
+	if input.Active != nil {
+		channel.Active = *input.Active
+	}
+	channel.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.Update(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// Delete removes a channel.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Test sends a test message to a channel right away, whether or not it is
+// active, so admins can check the webhook.
+func (s *Service) Test(ctx context.Context, id string) error {
+	channel, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	msg := domain.Message{
+		Title: "Test notification",
+		Text:  fmt.Sprintf("The backoffice can post to %q. It is told about: %s.", channel.Name, strings.Join(channel.Events, ", ")),
+	}
+	if err := s.sender.Send(ctx, channel, msg); err != nil {
+		return fmt.Errorf("%w: %v", ErrDeliveryFailed, err)
+	}
+	return nil
+}
+
+// Publish queues a message for the events channels can subscribe to and
+// ignores the rest. It never blocks: when the queue is full the message is
+// dropped.
+func (s *Service) Publish(_ context.Context, e event.Event) {
+	msg, ok := messageFor(e)
+	if !ok {
+		return
+	}
+	select {
+	case s.queue <- msg:
+	default:
+		log.Printf("notifications: queue full, dropping %s", msg.Event)
+	}
+}
+
+// Run delivers queued messages until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-s.queue:
+			s.deliver(ctx, msg)
+		}
+	}
+}
+
+// deliver sends msg to every active channel subscribed to its event.
+// Failures are reported and not retried.
+func (s *Service) deliver(ctx context.Context, msg domain.Message) {
+	channels, err := s.repo.List(ctx)
+	if err != nil {
+		errreport.Error(ctx, fmt.Errorf("notifications: listing channels for %s: %w", msg.Event, err), deliveryTags)
+		return
+	}
+	for _, channel := range channels {
+		if !channel.Wants(msg.Event) {
+			continue
+		}
+		if err := s.sender.Send(ctx, channel, msg); err != nil && ctx.Err() == nil {
+			errreport.Error(ctx, fmt.Errorf("notifications: sending %s to %s: %w", msg.Event, channel.Name, err), deliveryTags)
+		}
+	}
+}
+
+func normalizeWebhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", errcode.New(errcode.Invalid, "webhook_url_invalid", "webhookUrl must be an absolute http(s) URL")
+	}
+	return raw, nil
+}
+
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, errcode.New(errcode.Invalid, "notification_events_required", "events must list at least one event")
+	}
+	normalized := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if !slices.Contains(domain.Events, e) {
+			return nil, fmt.Errorf("%w: %q; valid events: %s", errcode.New(errcode.Invalid, "event_unknown", "unknown event").With("event", e), e, strings.Join(domain.Events, ", "))
+		}
+		if !slices.Contains(normalized, e) {
+			normalized = append(normalized, e)
+		}
+	}
+	return normalized, nil
+}
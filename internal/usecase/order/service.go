@@ -0,0 +1,244 @@
+// Package order places orders against product stock and moves them through
+// fulfilment.
+package order
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	customerdomain "backoffice/backend/internal/domain/customer"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/order"
+	productdomain "backoffice/backend/internal/domain/product"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrLinesRequired rejects an order without lines.
+	ErrLinesRequired = errcode.New(errcode.Invalid, "order_lines_required", "order must have at least one line")
+	// ErrInvalidQuantity rejects a line that orders nothing.
+	ErrInvalidQuantity = errcode.New(errcode.Invalid, "order_quantity_invalid", "line quantity must be greater than zero")
+)
+
+// Inventory is the stock orders are taken from. The product service
+// implements it.
+type Inventory interface {
+	Get(ctx context.Context, id string) (*productdomain.Product, error)
+	// StockMoved announces that the order repository added delta to the
+	// product's stock along with an order it stored.
+	StockMoved(ctx context.Context, id string, delta int)
+}
+
+// Customers looks up the customer an order is for. The customer service
+// implements it.
+type Customers interface {
+	Get(ctx context.Context, id string) (*customerdomain.Customer, error)
+}
+
+// Service places orders and records their transitions.
+type Service struct {
+	repo      domain.Repository
+	inventory Inventory
+	customers Customers
+	events    event.Publisher
+	nowFunc   func() time.Time
+}
+
+// NewService constructs an order service that deducts stock from inventory.
+func NewService(repo domain.Repository, inventory Inventory) *Service {
+	return &Service{
+		repo:      repo,
+		inventory: inventory,
+		events:    event.Discard,
+		nowFunc:   time.Now,
+	}
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// SetCustomers lets orders name a customer, which must exist.
+func (s *Service) SetCustomers(customers Customers) {
+	s.customers = customers
+}
+
+// LineInput orders Quantity of a product, counted in Unit: the product's
+// own unit when empty, or whole packs.
+type LineInput struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	Unit      string `json:"unit"`
+}
+
+// CreateInput describes an order to place.
+type CreateInput struct {
+	Reference  string      `json:"reference"`
+	CustomerID string      `json:"customerId"`
+	Lines      []LineInput `json:"lines"`
+	CreatedBy  string      `json:"-"`
+}
+
+// Create places a pending order, pricing each line at the product's current
+// price. The repository takes the lines' stock in the same transaction as
+// it stores the order, so if any line cannot be filled nothing changes.
+func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Order, error) {
+	if len(input.Lines) == 0 {
+		return nil, ErrLinesRequired
+	}
+	customerID := strings.TrimSpace(input.CustomerID)
+	if customerID != "" {
+		if s.customers == nil {
+			return nil, customerdomain.ErrNotFound
+		}
+		if _, err := s.customers.Get(ctx, customerID); err != nil {
+			return nil, err
+		}
+	}
+	now := s.nowFunc().UTC()
+	order := &domain.Order{
+		ID:              uuid.NewString(),
+		Reference:       strings.TrimSpace(input.Reference),
+		CustomerID:      customerID,
+		Status:          domain.StatusPending,
+		PaymentStatus:   domain.PaymentUnpaid,
+		TrackingNumbers: []string{},
+		CreatedBy:       input.CreatedBy,
+		History:         []domain.Transition{{To: domain.StatusPending, By: input.CreatedBy, At: now}},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	for i, in := range input.Lines {
+		line, err := s.line(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		order.Lines = append(order.Lines, line)
+		order.Total += line.Total
+	}
+	order.Total = roundCents(order.Total)
+	if err := s.repo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(event.OrderCreated, order.ID, order))
+	s.stockMoved(ctx, order, -1)
+	return order, nil
+}
+
+// line prices a line, converting its quantity to the product's unit.
+func (s *Service) line(ctx context.Context, in LineInput) (domain.Line, error) {
+	if in.Quantity <= 0 {
+		return domain.Line{}, ErrInvalidQuantity
+	}
+	product, err := s.inventory.Get(ctx, strings.TrimSpace(in.ProductID))
+	if err != nil {
+		return domain.Line{}, err
+	}
+	quantity := in.Quantity
+	if strings.TrimSpace(in.Unit) != "" {
+		if quantity, err = product.ToBase(quantity, in.Unit); err != nil {
+			return domain.Line{}, err
+		}
+	}
+	return domain.Line{
+		ProductID: product.ID,
+		SKU:       product.SKU,
+		Name:      product.Name,
+		Quantity:  quantity,
+		UnitPrice: product.Price,
+		Total:     roundCents(product.Price * float64(quantity)),
+	}, nil
+}
+
+// stockMoved announces the stock the order's lines took, with sign -1, or
+// returned, with sign 1.
+func (s *Service) stockMoved(ctx context.Context, order *domain.Order, sign int) {
+	for id, quantity := range order.Quantities() {
+		s.inventory.StockMoved(ctx, id, sign*quantity)
+	}
+}
+
+// List returns orders with the status, or all when it is empty, newest
+// first.
+func (s *Service) List(ctx context.Context, status string) ([]*domain.Order, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && !domain.ValidStatus(status) {
+		return nil, domain.ErrInvalidStatus
+	}
+	return s.repo.List(ctx, status)
+}
+
+// Get fetches an order by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Order, error) {
+	return s.repo.GetByID(ctx, strings.TrimSpace(id))
+}
+
+// TransitionInput moves an order to Status.
+type TransitionInput struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+	By     string `json:"-"`
+}
+
+// Transition moves an order to a new status if the state machine allows
+// it. Cancelling an order returns its stock, in the same repository write.
+func (s *Service) Transition(ctx context.Context, id string, input TransitionInput) (*domain.Order, error) {
+	order, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	to := strings.ToLower(strings.TrimSpace(input.Status))
+	if err := order.Transition(to, input.By, strings.TrimSpace(input.Note), s.nowFunc().UTC()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Transition(ctx, order); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(event.OrderStatusChanged, order.ID, order))
+	if to == domain.StatusCancelled {
+		s.stockMoved(ctx, order, 1)
+	}
+	return order, nil
+}
+
+// SetPaymentStatus records the status of the order's latest payment.
+func (s *Service) SetPaymentStatus(ctx context.Context, id, status string) (*domain.Order, error) {
+	if err := s.repo.SetPaymentStatus(ctx, strings.TrimSpace(id), status, s.nowFunc().UTC()); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// AddTrackingNumber records the tracking number of a parcel sent for the
+// order.
+func (s *Service) AddTrackingNumber(ctx context.Context, id, number string) (*domain.Order, error) {
+	if err := s.repo.AddTrackingNumber(ctx, strings.TrimSpace(id), number, s.nowFunc().UTC()); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// SetPromotion records the discount code redeemed on the order, or removes
+// it when code is empty, and takes the discount off the order's total.
+func (s *Service) SetPromotion(ctx context.Context, id, code string, discount float64) (*domain.Order, error) {
+	order, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	discount = min(roundCents(discount), order.Subtotal())
+	total := roundCents(order.Subtotal() - discount)
+	if err := s.repo.SetPromotion(ctx, order.ID, code, discount, total, s.nowFunc().UTC()); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, order.ID)
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
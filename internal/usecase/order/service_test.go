@@ -0,0 +1,250 @@
+package order_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	customerdomain "backoffice/backend/internal/domain/customer"
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/order"
+	productdomain "backoffice/backend/internal/domain/product"
+	"backoffice/backend/internal/infrastructure/memory"
+	"backoffice/backend/internal/usecase/order"
+	"backoffice/backend/internal/usecase/product"
+)
+
+// published records the types of the events the services publish.
+type published struct {
+	mu    sync.Mutex
+	types []string
+}
+
+func (p *published) Publish(_ context.Context, e event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.types = append(p.types, e.Type)
+}
+
+func (p *published) count(eventType string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, t := range p.types {
+		if t == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+// fixture is an order service whose stock comes from a product service on
+// the same memory store, stocked with rice by the piece and water in packs
+// of six.
+type fixture struct {
+	orders   *order.Service
+	products *product.Service
+	events   *published
+	rice     *productdomain.Product
+	water    *productdomain.Product
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewProductRepository()
+	events := &published{}
+	products := product.NewService(store)
+	products.SetReservations(store, time.Hour, time.Hour)
+	products.SetPublisher(events)
+	orders := order.NewService(memory.NewOrderRepository(store), products)
+	orders.SetPublisher(events)
+
+	rice, err := products.Create(ctx, product.CreateInput{Name: "Rice", SKU: "RICE-1", Price: 2.5, Quantity: 10})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	water, err := products.Create(ctx, product.CreateInput{Name: "Water", SKU: "WATER-6", Price: 0.5, PackSize: 6, Quantity: 12})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return &fixture{orders: orders, products: products, events: events, rice: rice, water: water}
+}
+
+// stock returns the quantities of rice and water on hand.
+func (f *fixture) stock(t *testing.T) (rice, water int) {
+	t.Helper()
+	ctx := context.Background()
+	r, err := f.products.Get(ctx, f.rice.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	w, err := f.products.Get(ctx, f.water.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	return r.Quantity, w.Quantity
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	placed, err := f.orders.Create(ctx, order.CreateInput{
+		Reference: " SHOP-1 ",
+		Lines: []order.LineInput{
+			{ProductID: f.rice.ID, Quantity: 2},
+			{ProductID: f.water.ID, Quantity: 1, Unit: productdomain.UnitPack},
+		},
+		CreatedBy: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if placed.Reference != "SHOP-1" || placed.Status != domain.StatusPending || placed.PaymentStatus != domain.PaymentUnpaid || len(placed.History) != 1 {
+		t.Fatalf("Create = %+v, want a pending unpaid order", placed)
+	}
+	if len(placed.Lines) != 2 || placed.Lines[1].Quantity != 6 || placed.Lines[1].Total != 3 || placed.Total != 8 {
+		t.Fatalf("lines = %+v, total %v; want the pack converted to 6 units and a total of 8", placed.Lines, placed.Total)
+	}
+	if rice, water := f.stock(t); rice != 8 || water != 6 {
+		t.Fatalf("stock = rice %d water %d, want 8 and 6", rice, water)
+	}
+	if n := f.events.count(event.OrderCreated); n != 1 {
+		t.Fatalf("%d %s events, want 1", n, event.OrderCreated)
+	}
+	if n := f.events.count(event.ProductUpdated); n != 2 {
+		t.Fatalf("%d %s events, want one per product", n, event.ProductUpdated)
+	}
+
+	// Four of the eight units of rice left are held for another order.
+	if _, err := f.products.Reserve(ctx, f.rice.ID, "user-2", product.ReserveInput{Quantity: 4}); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	for _, tc := range []struct {
+		name  string
+		input order.CreateInput
+		want  error
+	}{
+		{"no lines", order.CreateInput{}, order.ErrLinesRequired},
+		{"zero quantity", order.CreateInput{Lines: []order.LineInput{{ProductID: f.rice.ID}}}, order.ErrInvalidQuantity},
+		{"unknown product", order.CreateInput{Lines: []order.LineInput{{ProductID: "missing", Quantity: 1}}}, productdomain.ErrNotFound},
+		{"unknown customer", order.CreateInput{CustomerID: "customer-1", Lines: []order.LineInput{{ProductID: f.rice.ID, Quantity: 1}}}, customerdomain.ErrNotFound},
+		{"reserved stock", order.CreateInput{Lines: []order.LineInput{{ProductID: f.rice.ID, Quantity: 5}}}, productdomain.ErrInsufficientStock},
+		{"one product on two lines", order.CreateInput{Lines: []order.LineInput{
+			{ProductID: f.rice.ID, Quantity: 3},
+			{ProductID: f.rice.ID, Quantity: 2},
+		}}, productdomain.ErrInsufficientStock},
+		{"last line short", order.CreateInput{Lines: []order.LineInput{
+			{ProductID: f.rice.ID, Quantity: 1},
+			{ProductID: f.water.ID, Quantity: 2, Unit: productdomain.UnitPack},
+		}}, productdomain.ErrInsufficientStock},
+	} {
+		if _, err := f.orders.Create(ctx, tc.input); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+		if rice, water := f.stock(t); rice != 8 || water != 6 {
+			t.Errorf("%s: stock = rice %d water %d, want the failed order to take nothing", tc.name, rice, water)
+		}
+	}
+	if orders, err := f.orders.List(ctx, ""); err != nil || len(orders) != 1 {
+		t.Fatalf("List = %d orders, %v; want only the first", len(orders), err)
+	}
+}
+
+func TestCreateConcurrently(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 15)
+	for range 15 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.orders.Create(ctx, order.CreateInput{Lines: []order.LineInput{{ProductID: f.rice.ID, Quantity: 1}}})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	placed := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			placed++
+		case !errors.Is(err, productdomain.ErrInsufficientStock):
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if rice, _ := f.stock(t); placed != 10 || rice != 0 {
+		t.Fatalf("%d orders placed leaving %d rice, want 10 leaving none", placed, rice)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	place := func() *domain.Order {
+		t.Helper()
+		placed, err := f.orders.Create(ctx, order.CreateInput{Lines: []order.LineInput{
+			{ProductID: f.rice.ID, Quantity: 2},
+			{ProductID: f.water.ID, Quantity: 1, Unit: productdomain.UnitPack},
+		}})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return placed
+	}
+	move := func(id, status string) (*domain.Order, error) {
+		return f.orders.Transition(ctx, id, order.TransitionInput{Status: status, By: "user-1"})
+	}
+
+	placed := place()
+	if _, err := move(placed.ID, domain.StatusPaid); err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+	if _, err := move(placed.ID, domain.StatusDelivered); !errors.Is(err, domain.ErrIllegalTransition) {
+		t.Fatalf("deliver unshipped: err = %v, want %v", err, domain.ErrIllegalTransition)
+	}
+	cancelled, err := move(placed.ID, " Cancelled ")
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancelled.Status != domain.StatusCancelled || len(cancelled.History) != 3 {
+		t.Fatalf("cancelled = %s with %d history entries, want cancelled with 3", cancelled.Status, len(cancelled.History))
+	}
+	if rice, water := f.stock(t); rice != 10 || water != 12 {
+		t.Fatalf("stock = rice %d water %d, want cancelling to return all of it", rice, water)
+	}
+	if _, err := move(placed.ID, domain.StatusCancelled); !errors.Is(err, domain.ErrIllegalTransition) {
+		t.Fatalf("cancel twice: err = %v, want %v", err, domain.ErrIllegalTransition)
+	}
+	if rice, water := f.stock(t); rice != 10 || water != 12 {
+		t.Fatalf("stock = rice %d water %d, want the stock returned once", rice, water)
+	}
+	if n := f.events.count(event.OrderStatusChanged); n != 2 {
+		t.Fatalf("%d %s events, want 2", n, event.OrderStatusChanged)
+	}
+
+	// Stock is returned to the products that are still there.
+	placed = place()
+	if err := f.products.Delete(ctx, f.water.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := move(placed.ID, domain.StatusCancelled); err != nil {
+		t.Fatalf("cancel after a product was deleted: %v", err)
+	}
+	if rice, err := f.products.Get(ctx, f.rice.ID); err != nil || rice.Quantity != 10 {
+		t.Fatalf("rice = %+v, %v; want its stock back", rice, err)
+	}
+
+	if _, err := move("missing", domain.StatusPaid); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("missing order: err = %v, want %v", err, domain.ErrNotFound)
+	}
+	if _, err := move(placed.ID, "lost"); !errors.Is(err, domain.ErrInvalidStatus) {
+		t.Fatalf("unknown status: err = %v, want %v", err, domain.ErrInvalidStatus)
+	}
+}
@@ -0,0 +1,315 @@
+// Package payment takes payments for orders through the configured payment
+// providers and settles them from the providers' webhooks.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	orderdomain "backoffice/backend/internal/domain/order"
+	domain "backoffice/backend/internal/domain/payment"
+	orderusecase "backoffice/backend/internal/usecase/order"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrOrderNotPayable rejects paying for an order that is no longer
+	// pending or has been paid already.
+	ErrOrderNotPayable = errcode.New(errcode.Conflict, "order_not_payable", "order cannot be paid")
+	// ErrInvalidRefund rejects a negative refund amount.
+	ErrInvalidRefund = errcode.New(errcode.Invalid, "refund_amount_invalid", "refund amount must not be negative")
+	// ErrRefundExceeded rejects refunding more than is left of a payment.
+	ErrRefundExceeded = errcode.New(errcode.Unprocessable, "refund_amount_exceeded", "refund amount exceeds what is left to refund")
+)
+
+// Orders reads and updates the orders being paid for. The order service
+// implements it.
+type Orders interface {
+	Get(ctx context.Context, id string) (*orderdomain.Order, error)
+	Transition(ctx context.Context, id string, input orderusecase.TransitionInput) (*orderdomain.Order, error)
+	SetPaymentStatus(ctx context.Context, id, status string) (*orderdomain.Order, error)
+}
+
+// Service starts, settles and refunds payments.
+type Service struct {
+	repo      domain.Repository
+	orders    Orders
+	providers map[string]domain.Provider
+	// fallback is the provider used when a request names none.
+	fallback string
+	currency string
+	events   event.Publisher
+	nowFunc  func() time.Time
+}
+
+// NewService constructs a payment service charging in currency. The first
+// provider is used when a payment does not name one.
+func NewService(repo domain.Repository, orders Orders, currency string, providers ...domain.Provider) *Service {
+	s := &Service{
+		repo:      repo,
+		orders:    orders,
+		providers: make(map[string]domain.Provider, len(providers)),
+		currency:  strings.ToUpper(currency),
+		events:    event.Discard,
+		nowFunc:   time.Now,
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	if len(providers) > 0 {
+		s.fallback = providers[0].Name()
+	}
+	return s
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// Providers names the configured providers.
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Service) provider(name string) (domain.Provider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = s.fallback
+	}
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, domain.ErrUnknownProvider.With("supported", s.Providers())
+	}
+	return p, nil
+}
+
+// StartInput picks the provider to pay with.
+type StartInput struct {
+	Provider string `json:"provider"`
+}
+
+// Start opens a payment for the order's total. The customer completes it at
+// the returned payment's CheckoutURL, and the provider's webhook settles it.
+func (s *Service) Start(ctx context.Context, orderID string, input StartInput) (*domain.Payment, error) {
+	provider, err := s.provider(input.Provider)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != orderdomain.StatusPending || settled(order.PaymentStatus) {
+		return nil, ErrOrderNotPayable.With("status", order.Status).With("paymentStatus", order.PaymentStatus)
+	}
+
+	now := s.nowFunc().UTC()
+	payment := &domain.Payment{
+		ID:        uuid.NewString(),
+		OrderID:   order.ID,
+		Provider:  provider.Name(),
+		Status:    domain.StatusPending,
+		Amount:    order.Total,
+		Currency:  s.currency,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(ctx, payment); err != nil {
+		return nil, err
+	}
+	description := "Order " + order.ID
+	if order.Reference != "" {
+		description = "Order " + order.Reference
+	}
+	session, err := provider.Start(ctx, domain.Checkout{
+		PaymentID:   payment.ID,
+		OrderID:     order.ID,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Description: description,
+	})
+	if err != nil {
+		payment.Status = domain.StatusFailed
+		payment.FailureReason = err.Error()
+		payment.UpdatedAt = s.nowFunc().UTC()
+		if updateErr := s.repo.Update(ctx, payment); updateErr != nil {
+			err = errors.Join(err, updateErr)
+		}
+		return nil, fmt.Errorf("start %s payment: %w", provider.Name(), err)
+	}
+	payment.Reference = session.Reference
+	payment.CheckoutURL = session.CheckoutURL
+	payment.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return nil, err
+	}
+	if err := s.syncOrder(ctx, order.ID); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// List returns an order's payments, newest first.
+func (s *Service) List(ctx context.Context, orderID string) ([]*domain.Payment, error) {
+	order, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByOrder(ctx, order.ID)
+}
+
+// Get fetches a payment by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Payment, error) {
+	return s.repo.GetByID(ctx, strings.TrimSpace(id))
+}
+
+// Webhook settles a payment from a provider's signed webhook. A successful
+// payment moves its order from pending to paid. Providers deliver webhooks
+// at least once, so a payment that is already settled is returned as is.
+// Webhooks about anything but the result of a payment started here return
+// nil, so they can be acknowledged.
+func (s *Service) Webhook(ctx context.Context, providerName string, payload []byte, header http.Header) (*domain.Payment, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	outcome, err := provider.Webhook(payload, header)
+	if err != nil {
+		return nil, err
+	}
+	if outcome == nil {
+		return nil, nil
+	}
+	payment, err := s.repo.GetByReference(ctx, provider.Name(), outcome.Reference)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.StatusPending {
+		return payment, nil
+	}
+	payment.Status = outcome.Status
+	payment.FailureReason = outcome.Reason
+	payment.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return nil, err
+	}
+	if payment.Status == domain.StatusSucceeded {
+		s.events.Publish(ctx, event.New(event.PaymentSucceeded, payment.ID, payment))
+		_, err := s.orders.Transition(ctx, payment.OrderID, orderusecase.TransitionInput{
+			Status: orderdomain.StatusPaid,
+			Note:   fmt.Sprintf("%s payment %s", provider.Name(), payment.ID),
+		})
+		// An order cancelled meanwhile stays cancelled; its payment is
+		// recorded so it can be refunded.
+		if err != nil && !errors.Is(err, orderdomain.ErrIllegalTransition) && !errors.Is(err, orderdomain.ErrStatusChanged) {
+			return nil, err
+		}
+	} else {
+		s.events.Publish(ctx, event.New(event.PaymentFailed, payment.ID, payment))
+	}
+	if err := s.syncOrder(ctx, payment.OrderID); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// RefundInput refunds Amount of a payment, or all that is left of it when
+// Amount is zero.
+type RefundInput struct {
+	Amount float64 `json:"amount"`
+}
+
+// Refund returns money from a settled payment through its provider.
+func (s *Service) Refund(ctx context.Context, id string, input RefundInput) (*domain.Payment, error) {
+	if input.Amount < 0 {
+		return nil, ErrInvalidRefund
+	}
+	payment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	refundable := roundCents(payment.Refundable())
+	if refundable <= 0 {
+		return nil, domain.ErrNotRefundable.With("status", payment.Status)
+	}
+	amount := roundCents(input.Amount)
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount > refundable {
+		return nil, ErrRefundExceeded.With("refundable", refundable)
+	}
+	provider, err := s.provider(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.Refund(ctx, payment, amount); err != nil {
+		return nil, fmt.Errorf("refund %s payment: %w", provider.Name(), err)
+	}
+	payment.Refunded = roundCents(payment.Refunded + amount)
+	payment.Status = domain.StatusPartiallyRefunded
+	if payment.Refunded >= payment.Amount {
+		payment.Status = domain.StatusRefunded
+	}
+	payment.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(event.PaymentRefunded, payment.ID, payment))
+	if err := s.syncOrder(ctx, payment.OrderID); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// syncOrder records the order's payment status: that of its latest settled
+// payment, or of its latest payment when none has settled. A late webhook
+// about an abandoned attempt thus cannot hide a payment that went through.
+func (s *Service) syncOrder(ctx context.Context, orderID string) error {
+	payments, err := s.repo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	status := orderdomain.PaymentUnpaid
+	if len(payments) > 0 {
+		status = payments[0].Status
+	}
+	if i := slices.IndexFunc(payments, func(p *domain.Payment) bool { return settled(p.Status) }); i >= 0 {
+		status = payments[i].Status
+	}
+	_, err = s.orders.SetPaymentStatus(ctx, orderID, status)
+	return err
+}
+
+// settled reports whether money was taken: the payment succeeded, even if
+// it was refunded since.
+func settled(status string) bool {
+	switch status {
+	case domain.StatusSucceeded, domain.StatusPartiallyRefunded, domain.StatusRefunded:
+		return true
+	}
+	return false
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
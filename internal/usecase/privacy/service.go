@@ -0,0 +1,169 @@
+// Package privacy answers data protection requests about users, such as
+// subject access requests for everything held about one of them.
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	activitydomain "backoffice/backend/internal/domain/activity"
+	authdomain "backoffice/backend/internal/domain/auth"
+	consentdomain "backoffice/backend/internal/domain/consent"
+	"backoffice/backend/internal/domain/session"
+	viewdomain "backoffice/backend/internal/domain/view"
+	watchdomain "backoffice/backend/internal/domain/watch"
+	activityusecase "backoffice/backend/internal/usecase/activity"
+)
+
+// Archive format identifiers. Version changes whenever a field is removed
+// or changes meaning.
+const (
+	Format  = "backoffice-subject-access"
+	Version = 1
+)
+
+// Archive is everything held about one user. It carries no password hash
+// and no session tokens.
+type Archive struct {
+	Format    string             `json:"format"`
+	Version   int                `json:"version"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Profile   authdomain.Summary `json:"profile"`
+	Sessions  []*session.Session `json:"sessions"`
+	Activity  Activity           `json:"activity"`
+	Views     []*viewdomain.View `json:"views"`
+	// Watches are the products the user is notified about, and
+	// Notifications the latest notifications sent.
+	Watches       []*watchdomain.Watch        `json:"watches"`
+	Notifications []*watchdomain.Notification `json:"notifications"`
+	// Consents are the policy versions the user accepted.
+	Consents []*consentdomain.Consent `json:"consents"`
+}
+
+// Activity is the user's part of the audit trail: the changes they made,
+// and the changes others made to their account.
+type Activity struct {
+	Performed []*activitydomain.Entry `json:"performed"`
+	About     []*activitydomain.Entry `json:"about"`
+}
+
+// Users fetches the user. The user service implements it.
+type Users interface {
+	Get(ctx context.Context, id string) (*authdomain.User, error)
+}
+
+// Sessions lists the user's active sessions. The auth service implements
+// it.
+type Sessions interface {
+	Sessions(ctx context.Context, userID string) ([]*session.Session, error)
+}
+
+// AuditTrail pages through the audit trail. The activity service
+// implements it.
+type AuditTrail interface {
+	List(ctx context.Context, q activityusecase.Query) (*activityusecase.Page, error)
+}
+
+// Views lists the user's saved views. The view service implements it.
+type Views interface {
+	List(ctx context.Context, userID, resource string) ([]*viewdomain.View, error)
+}
+
+// Watches lists the user's product watches and notifications. The watch
+// service implements it.
+type Watches interface {
+	List(ctx context.Context, userID string) ([]*watchdomain.Watch, error)
+	Notifications(ctx context.Context, userID string) ([]*watchdomain.Notification, error)
+}
+
+// Consents lists the policy versions the user accepted. The consent service
+// implements it.
+type Consents interface {
+	Consents(ctx context.Context, userID string) ([]*consentdomain.Consent, error)
+}
+
+// Service compiles subject access archives.
+type Service struct {
+	users    Users
+	sessions Sessions
+	audit    AuditTrail
+	views    Views
+	watches  Watches
+	consents Consents
+	nowFunc  func() time.Time
+}
+
+// NewService constructs a privacy service over the services holding user
+// data.
+func NewService(users Users, sessions Sessions, audit AuditTrail, views Views, watches Watches, consents Consents) *Service {
+	return &Service{
+		users:    users,
+		sessions: sessions,
+		audit:    audit,
+		views:    views,
+		watches:  watches,
+		consents: consents,
+		nowFunc:  time.Now,
+	}
+}
+
+// Export compiles everything held about the user into one archive. It
+// returns the user service's not-found error for unknown users.
+func (s *Service) Export(ctx context.Context, userID string) (*Archive, error) {
+	user, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: s.nowFunc().UTC(),
+		Profile:   user.Summary(),
+	}
+
+	archive.Sessions, err = s.sessions.Sessions(ctx, user.ID)
+	if errors.Is(err, authdomain.ErrSessionsUnsupported) {
+		archive.Sessions, err = []*session.Session{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("exporting sessions: %w", err)
+	}
+	if archive.Activity.Performed, err = s.auditEntries(ctx, activityusecase.Query{ActorID: user.ID}); err != nil {
+		return nil, fmt.Errorf("exporting activity: %w", err)
+	}
+	if archive.Activity.About, err = s.auditEntries(ctx, activityusecase.Query{EntityType: "user", EntityID: user.ID}); err != nil {
+		return nil, fmt.Errorf("exporting activity: %w", err)
+	}
+	if archive.Views, err = s.views.List(ctx, user.ID, ""); err != nil {
+		return nil, fmt.Errorf("exporting views: %w", err)
+	}
+	if archive.Watches, err = s.watches.List(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("exporting watches: %w", err)
+	}
+	if archive.Notifications, err = s.watches.Notifications(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("exporting notifications: %w", err)
+	}
+	if archive.Consents, err = s.consents.Consents(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("exporting consents: %w", err)
+	}
+	return archive, nil
+}
+
+// auditEntries reads every page of the matching entries, newest first.
+func (s *Service) auditEntries(ctx context.Context, q activityusecase.Query) ([]*activitydomain.Entry, error) {
+	q.Limit = activityusecase.MaxLimit
+	entries := []*activitydomain.Entry{}
+	for {
+		page, err := s.audit.List(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page.Items...)
+		if page.NextCursor == "" {
+			return entries, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
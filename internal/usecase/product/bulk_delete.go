@@ -0,0 +1,45 @@
+package product
+
+import (
+	"context"
+
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/product"
+	"backoffice/backend/internal/domain/trash"
+)
+
+// BulkDelete moves every product in input to the trash in one transaction.
+// If any of them does not exist nothing is deleted, and the error lists the
+// missing ids under "ids". Each deleted product gets its own event, and so
+// its own activity entry.
+func (s *Service) BulkDelete(ctx context.Context, input trash.BulkDelete) (*trash.BulkDeleteReport, error) {
+	ids, err := input.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) < len(ids) {
+		found := make(map[string]bool, len(products))
+		for _, product := range products {
+			found[product.ID] = true
+		}
+		var missing []string
+		for _, id := range ids {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		return nil, domain.ErrNotFound.With("ids", missing)
+	}
+
+	if err := s.repo.DeleteMany(ctx, ids); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		s.events.Publish(ctx, event.New(event.ProductDeleted, id, map[string]string{"id": id}))
+	}
+	return &trash.BulkDeleteReport{Deleted: len(ids), IDs: ids}, nil
+}
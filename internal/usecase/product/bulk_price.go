@@ -0,0 +1,185 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/product"
+)
+
+// ErrInvalidPriceUpdate wraps bulk price updates that cannot be applied.
+var ErrInvalidPriceUpdate = errcode.New(errcode.Invalid, "price_update_invalid", "invalid bulk price update")
+
+// Price operations, applied in the order given.
+const (
+	// PriceSet replaces the price with Value.
+	PriceSet = "set"
+	// PriceIncreasePercent and PriceDecreasePercent change the price by
+	// Value percent, rounded to the cent.
+	PriceIncreasePercent = "increase_percent"
+	PriceDecreasePercent = "decrease_percent"
+	// PriceRound99 moves the price to .99 within its whole unit, so 12.30
+	// becomes 12.99.
+	PriceRound99 = "round_99"
+)
+
+// PriceOperations lists every operation in a stable order.
+var PriceOperations = []string{PriceSet, PriceIncreasePercent, PriceDecreasePercent, PriceRound99}
+
+// PriceFilter selects the products a bulk price update applies to. Every
+// criterion given must match, and at least one is required.
+type PriceFilter struct {
+	IDs        []string `json:"ids,omitempty"`
+	CategoryID string   `json:"categoryId,omitempty"`
+	SKUPrefix  string   `json:"skuPrefix,omitempty"`
+	MinPrice   *float64 `json:"minPrice,omitempty"`
+	MaxPrice   *float64 `json:"maxPrice,omitempty"`
+}
+
+// PriceOperation is one step of a bulk price update.
+type PriceOperation struct {
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+// BulkPriceInput is a bulk price update: the operations run in order on the
+// price of every product the filter selects.
+type BulkPriceInput struct {
+	Filter     PriceFilter      `json:"filter"`
+	Operations []PriceOperation `json:"operations"`
+}
+
+// BulkPriceReport lists the prices a bulk update changed, or would change
+// in a preview. Matched products whose price stays the same are counted but
+// not listed.
+type BulkPriceReport struct {
+	Preview bool                 `json:"preview"`
+	Matched int                  `json:"matched"`
+	Changed int                  `json:"changed"`
+	Items   []domain.PriceChange `json:"items"`
+}
+
+// Validate checks the filter and operations without reading any product.
+func (in BulkPriceInput) Validate() error {
+	f := in.Filter
+	if len(f.IDs) == 0 && strings.TrimSpace(f.CategoryID) == "" && strings.TrimSpace(f.SKUPrefix) == "" && f.MinPrice == nil && f.MaxPrice == nil {
+		return fmt.Errorf("%w: filter must select products by ids, categoryId, skuPrefix, minPrice or maxPrice", ErrInvalidPriceUpdate)
+	}
+	if len(in.Operations) == 0 {
+		return fmt.Errorf("%w: at least one operation is required", ErrInvalidPriceUpdate)
+	}
+	for i, op := range in.Operations {
+		switch {
+		case !slices.Contains(PriceOperations, op.Type):
+			return fmt.Errorf("%w: operation %d: type must be one of: %s", ErrInvalidPriceUpdate, i, strings.Join(PriceOperations, ", "))
+		case op.Type == PriceSet && op.Value < 0:
+			return fmt.Errorf("%w: operation %d: price must not be negative", ErrInvalidPriceUpdate, i)
+		case (op.Type == PriceIncreasePercent || op.Type == PriceDecreasePercent) && op.Value <= 0:
+			return fmt.Errorf("%w: operation %d: percentage must be positive", ErrInvalidPriceUpdate, i)
+		case op.Type == PriceDecreasePercent && op.Value > 100:
+			return fmt.Errorf("%w: operation %d: cannot decrease by more than 100%%", ErrInvalidPriceUpdate, i)
+		}
+	}
+	return nil
+}
+
+// BulkUpdatePrices applies input to every matching product in a single
+// transaction, or with preview only reports the old and new prices. A price
+// changed by someone else between reading and writing fails the update with
+// domain.ErrPriceChanged and nothing is written.
+func (s *Service) BulkUpdatePrices(ctx context.Context, input BulkPriceInput, preview bool) (*BulkPriceReport, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	// An id filter fetches just those products instead of the catalogue.
+	var products []*domain.Product
+	var err error
+	if len(input.Filter.IDs) > 0 {
+		products, err = s.repo.GetByIDs(ctx, input.Filter.IDs)
+	} else {
+		products, err = s.repo.List(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BulkPriceReport{Preview: preview, Items: []domain.PriceChange{}}
+	changed := map[string]*domain.Product{}
+	for _, product := range products {
+		if !input.Filter.matches(product) {
+			continue
+		}
+		report.Matched++
+		price := product.Price
+		for _, op := range input.Operations {
+			price = op.apply(price)
+		}
+		if price == product.Price {
+			continue
+		}
+		report.Items = append(report.Items, domain.PriceChange{
+			ProductID: product.ID,
+			SKU:       product.SKU,
+			Name:      product.Name,
+			OldPrice:  product.Price,
+			NewPrice:  price,
+		})
+		changed[product.ID] = product
+	}
+	sort.Slice(report.Items, func(i, j int) bool { return report.Items[i].SKU < report.Items[j].SKU })
+	report.Changed = len(report.Items)
+	if preview || report.Changed == 0 {
+		return report, nil
+	}
+
+	now := s.nowFunc().UTC()
+	if err := s.repo.UpdatePrices(ctx, report.Items, now); err != nil {
+		return nil, err
+	}
+	for _, item := range report.Items {
+		product := changed[item.ProductID]
+		product.Price = item.NewPrice
+		product.UpdatedAt = now
+		s.events.Publish(ctx, event.New(event.ProductUpdated, product.ID, product))
+	}
+	return report, nil
+}
+
+func (f PriceFilter) matches(p *domain.Product) bool {
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, p.ID) {
+		return false
+	}
+	if id := strings.TrimSpace(f.CategoryID); id != "" && p.CategoryID != id {
+		return false
+	}
+	if prefix := strings.TrimSpace(f.SKUPrefix); prefix != "" && !strings.HasPrefix(p.SKU, prefix) {
+		return false
+	}
+	if f.MinPrice != nil && p.Price < *f.MinPrice {
+		return false
+	}
+	if f.MaxPrice != nil && p.Price > *f.MaxPrice {
+		return false
+	}
+	return true
+}
+
+func (op PriceOperation) apply(price float64) float64 {
+	switch op.Type {
+	case PriceSet:
+		return roundCents(op.Value)
+	case PriceIncreasePercent:
+		return roundCents(price * (1 + op.Value/100))
+	case PriceDecreasePercent:
+		return roundCents(price * (1 - op.Value/100))
+	case PriceRound99:
+		return roundCents(math.Floor(price) + 0.99)
+	}
+	return price
+}
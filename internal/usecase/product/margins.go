@@ -0,0 +1,92 @@
+package product
+
+import (
+	"context"
+	"sort"
+
+	domain "backoffice/backend/internal/domain/product"
+)
+
+// MarginReport computes the margin of every product with a cost price and
+// totals them per category. Products are sorted by unit margin percent,
+// lowest first, so the ones sold closest to cost lead; categories by stock
+// margin, highest first. Category names are left for the caller to fill in.
+func (s *Service) MarginReport(ctx context.Context) (*domain.MarginReport, error) {
+	products, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.MarginReport{Products: []domain.ProductMargin{}, Categories: []domain.CategoryMargin{}}
+	byCategory := map[string]*domain.CategoryMargin{}
+	for _, p := range products {
+		if p.CostPrice == nil {
+			report.Uncosted++
+			continue
+		}
+		margin := p.Price - *p.CostPrice
+		report.Products = append(report.Products, domain.ProductMargin{
+			ProductID:     p.ID,
+			SKU:           p.SKU,
+			Name:          p.Name,
+			CategoryID:    p.CategoryID,
+			Price:         p.Price,
+			CostPrice:     *p.CostPrice,
+			Margin:        roundCents(margin),
+			MarginPercent: percentOf(margin, p.Price),
+			Quantity:      p.Quantity,
+			StockMargin:   roundCents(margin * float64(p.Quantity)),
+		})
+
+		line, ok := byCategory[p.CategoryID]
+		if !ok {
+			line = &domain.CategoryMargin{CategoryID: p.CategoryID}
+			byCategory[p.CategoryID] = line
+		}
+		for _, m := range []*domain.CategoryMargin{line, &report.Total} {
+			m.Products++
+			m.Quantity += p.Quantity
+			m.Revenue += p.Price * float64(p.Quantity)
+			m.Cost += *p.CostPrice * float64(p.Quantity)
+		}
+	}
+	for _, line := range byCategory {
+		report.Categories = append(report.Categories, *line)
+	}
+	for i := range report.Categories {
+		finishMargin(&report.Categories[i])
+	}
+	finishMargin(&report.Total)
+
+	sort.SliceStable(report.Products, func(i, j int) bool {
+		a, b := report.Products[i], report.Products[j]
+		if a.MarginPercent != b.MarginPercent {
+			return a.MarginPercent < b.MarginPercent
+		}
+		return a.SKU < b.SKU
+	})
+	sort.Slice(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i], report.Categories[j]
+		if a.Margin != b.Margin {
+			return a.Margin > b.Margin
+		}
+		return a.CategoryID < b.CategoryID
+	})
+	return report, nil
+}
+
+// finishMargin derives the margin of a category's totals and rounds them.
+func finishMargin(m *domain.CategoryMargin) {
+	m.Revenue = roundCents(m.Revenue)
+	m.Cost = roundCents(m.Cost)
+	m.Margin = roundCents(m.Revenue - m.Cost)
+	m.MarginPercent = percentOf(m.Margin, m.Revenue)
+}
+
+// percentOf is part as a percentage of whole, to two decimals; zero when
+// whole is.
+func percentOf(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return roundCents(part / whole * 100)
+}
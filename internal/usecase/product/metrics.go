@@ -0,0 +1,8 @@
+package product
+
+import "backoffice/backend/internal/metrics"
+
+// OrdersConfirmed counts reservations confirmed into orders, the API's
+// measure of order throughput.
+var OrdersConfirmed = metrics.Default.NewCounterVec("orders_confirmed_total",
+	"Reservations confirmed into orders.")
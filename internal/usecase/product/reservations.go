@@ -0,0 +1,181 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	domain "backoffice/backend/internal/domain/product"
+	"backoffice/backend/internal/errreport"
+
+	"github.com/google/uuid"
+)
+
+// Default reservation lifetimes, used until SetReservations is called with
+// configured values.
+const (
+	DefaultReservationTTL    = 15 * time.Minute
+	DefaultMaxReservationTTL = 24 * time.Hour
+)
+
+var reservationPurgeTags = map[string]string{"job": "reservation-purge"}
+
+var (
+	// ErrReservationsUnavailable is returned when the service has no
+	// reservation repository.
+	ErrReservationsUnavailable = errcode.New(errcode.Unavailable, "reservations_unavailable", "reservations are not supported")
+	// ErrInvalidReservation wraps rejected reservation requests.
+	ErrInvalidReservation = errcode.New(errcode.Invalid, "reservation_invalid", "invalid reservation")
+)
+
+// ReserveInput is the payload of a reservation request.
+type ReserveInput struct {
+	Quantity  int    `json:"quantity"`
+	Reference string `json:"reference"`
+	// TTLSeconds overrides the default lifetime, up to the configured
+	// maximum.
+	TTLSeconds int `json:"ttlSeconds"`
+}
+
+// SetReservations enables stock reservations stored in repo. Reservations
+// last ttl unless the caller asks for another lifetime of at most maxTTL.
+func (s *Service) SetReservations(repo domain.ReservationRepository, ttl, maxTTL time.Duration) {
+	s.reservations = repo
+	s.reservationTTL = ttl
+	s.maxReservationTTL = maxTTL
+}
+
+// Reserve holds stock of a product for a pending order. It fails with
+// ErrInsufficientStock when the product's available quantity is too low.
+func (s *Service) Reserve(ctx context.Context, productID, userID string, input ReserveInput) (*domain.Reservation, error) {
+	if s.reservations == nil {
+		return nil, ErrReservationsUnavailable
+	}
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalidReservation)
+	}
+	if input.Quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidReservation)
+	}
+	ttl := s.reservationTTL
+	if input.TTLSeconds < 0 {
+		return nil, fmt.Errorf("%w: ttlSeconds must be positive", ErrInvalidReservation)
+	}
+	if input.TTLSeconds > 0 {
+		ttl = time.Duration(input.TTLSeconds) * time.Second
+		if ttl > s.maxReservationTTL {
+			return nil, fmt.Errorf("%w: ttlSeconds must not exceed %d", ErrInvalidReservation, int(s.maxReservationTTL/time.Second))
+		}
+	}
+
+	now := s.nowFunc().UTC()
+	reservation := &domain.Reservation{
+		ID:        uuid.NewString(),
+		ProductID: productID,
+		Quantity:  input.Quantity,
+		Reference: strings.TrimSpace(input.Reference),
+		CreatedBy: userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.reservations.Reserve(ctx, reservation, now); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// ListReservations returns a product's active reservations.
+func (s *Service) ListReservations(ctx context.Context, productID string) ([]*domain.Reservation, error) {
+	if s.reservations == nil {
+		return nil, ErrReservationsUnavailable
+	}
+	if _, err := s.Get(ctx, productID); err != nil {
+		return nil, err
+	}
+	reservations, err := s.reservations.ListReservations(ctx, strings.TrimSpace(productID), s.nowFunc().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if reservations == nil {
+		reservations = []*domain.Reservation{}
+	}
+	return reservations, nil
+}
+
+// ReservationsFor returns the active reservations of each of productIDs,
+// which are not checked, in one lookup. Every id has an entry, empty when
+// it holds no reservations.
+func (s *Service) ReservationsFor(ctx context.Context, productIDs []string) (map[string][]*domain.Reservation, error) {
+	if s.reservations == nil {
+		return nil, ErrReservationsUnavailable
+	}
+	reservations, err := s.reservations.ListReservationsFor(ctx, productIDs, s.nowFunc().UTC())
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[string][]*domain.Reservation, len(productIDs))
+	for _, id := range productIDs {
+		byProduct[id] = []*domain.Reservation{}
+	}
+	for _, res := range reservations {
+		byProduct[res.ProductID] = append(byProduct[res.ProductID], res)
+	}
+	return byProduct, nil
+}
+
+// ReleaseReservation gives reserved stock back before the reservation
+// expires, for example when the order is cancelled.
+func (s *Service) ReleaseReservation(ctx context.Context, productID, id string) error {
+	if s.reservations == nil {
+		return ErrReservationsUnavailable
+	}
+	return s.reservations.Release(ctx, strings.TrimSpace(productID), strings.TrimSpace(id), s.nowFunc().UTC())
+}
+
+// ConfirmReservation deducts reserved stock from the product once the order
+// is placed.
+func (s *Service) ConfirmReservation(ctx context.Context, productID, id string) (*domain.Product, error) {
+	if s.reservations == nil {
+		return nil, ErrReservationsUnavailable
+	}
+	product, err := s.reservations.Confirm(ctx, strings.TrimSpace(productID), strings.TrimSpace(id), s.nowFunc().UTC())
+	if err != nil {
+		return nil, err
+	}
+	OrdersConfirmed.Inc()
+	// Confirming always deducts stock, so the product had some before.
+	s.publishUpdate(ctx, product, true)
+	return product, nil
+}
+
+// PurgeExpiredReservations deletes reservations that have expired. They no
+// longer count against stock, so this only reclaims space.
+func (s *Service) PurgeExpiredReservations(ctx context.Context) (int, error) {
+	if s.reservations == nil {
+		return 0, nil
+	}
+	return s.reservations.PurgeExpiredReservations(ctx, s.nowFunc().UTC())
+}
+
+// RunReservationPurge calls PurgeExpiredReservations every interval until ctx
+// is done.
+func (s *Service) RunReservationPurge(ctx context.Context, interval time.Duration) {
+	if s.reservations == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.PurgeExpiredReservations(ctx); err != nil && ctx.Err() == nil {
+			errreport.Error(ctx, fmt.Errorf("reservations: %w", err), reservationPurgeTags)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
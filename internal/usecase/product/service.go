@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
 	domain "backoffice/backend/internal/domain/product"
 
 	"github.com/google/uuid"
@@ -15,24 +18,46 @@ import (
 // Service encapsulates product use cases.
 type Service struct {
 	repo    domain.Repository
+	events  event.Publisher
 	nowFunc func() time.Time
+
+	reservations      domain.ReservationRepository
+	reservationTTL    time.Duration
+	maxReservationTTL time.Duration
+
+	translations domain.TranslationRepository
+	locale       string
 }
 
 // NewService constructs a product service.
 func NewService(repo domain.Repository) *Service {
 	return &Service{
-		repo:    repo,
-		nowFunc: time.Now,
+		repo:              repo,
+		events:            event.Discard,
+		nowFunc:           time.Now,
+		reservationTTL:    DefaultReservationTTL,
+		maxReservationTTL: DefaultMaxReservationTTL,
+		locale:            DefaultLocale,
 	}
 }
 
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
 // CreateInput contains the payload required for product creation.
 type CreateInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	SKU         string  `json:"sku"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	SKU         string   `json:"sku"`
+	Barcode     string   `json:"barcode"`
+	Price       float64  `json:"price"`
+	CostPrice   *float64 `json:"costPrice"`
+	Unit        string   `json:"unit"`
+	PackSize    int      `json:"packSize"`
+	Quantity    int      `json:"quantity"`
+	CategoryID  string   `json:"categoryId"`
 }
 
 // UpdateInput encapsulates partial product updates.
@@ -40,19 +65,25 @@ type UpdateInput struct {
 	Name        *string  `json:"name"`
 	Description *string  `json:"description"`
 	SKU         *string  `json:"sku"`
+	Barcode     *string  `json:"barcode"`
 	Price       *float64 `json:"price"`
+	CostPrice   *float64 `json:"costPrice"`
+	Unit        *string  `json:"unit"`
+	PackSize    *int     `json:"packSize"`
 	Quantity    *int     `json:"quantity"`
+	CategoryID  *string  `json:"categoryId"`
 }
 
 // Create stores a new product after validation.
 func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Product, error) {
 	input.Name = strings.TrimSpace(input.Name)
 	input.SKU = strings.TrimSpace(input.SKU)
+	input.Barcode = strings.TrimSpace(input.Barcode)
 	if input.Name == "" {
-		return nil, errors.New("name is required")
+		return nil, errcode.New(errcode.Invalid, "name_required", "name is required")
 	}
 	if input.SKU == "" {
-		return nil, errors.New("sku is required")
+		return nil, errcode.New(errcode.Invalid, "sku_required", "sku is required")
 	}
 
 	if _, err := s.repo.GetBySKU(ctx, input.SKU); err == nil {
@@ -60,6 +91,16 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Produc
 	} else if !errors.Is(err, domain.ErrNotFound) {
 		return nil, err
 	}
+	if err := s.checkBarcode(ctx, input.Barcode, ""); err != nil {
+		return nil, err
+	}
+	unit, packSize, err := packaging(input.Unit, input.PackSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCostPrice(input.CostPrice); err != nil {
+		return nil, err
+	}
 
 	now := s.nowFunc().UTC()
 	product := &domain.Product{
@@ -67,18 +108,72 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Produc
 		Name:        input.Name,
 		Description: input.Description,
 		SKU:         input.SKU,
+		Barcode:     input.Barcode,
 		Price:       input.Price,
+		CostPrice:   input.CostPrice,
+		Unit:        unit,
+		PackSize:    packSize,
 		Quantity:    input.Quantity,
+		CategoryID:  strings.TrimSpace(input.CategoryID),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
+	product.SetReserved(0)
 
 	if err := s.repo.Create(ctx, product); err != nil {
 		return nil, err
 	}
+	s.events.Publish(ctx, event.New(event.ProductCreated, product.ID, product))
 	return product, nil
 }
 
+// Upsert creates the product, or overwrites the one with the same SKU, and
+// reports whether it was created. It backs bulk ingestion, where integrations
+// resend their whole catalogue.
+func (s *Service) Upsert(ctx context.Context, input CreateInput) (*domain.Product, bool, error) {
+	input.SKU = strings.TrimSpace(input.SKU)
+	if input.SKU == "" {
+		return nil, false, errcode.New(errcode.Invalid, "sku_required", "sku is required")
+	}
+	existing, err := s.repo.GetBySKU(ctx, input.SKU)
+	if errors.Is(err, domain.ErrNotFound) {
+		product, err := s.Create(ctx, input)
+		return product, err == nil, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, false, errcode.New(errcode.Invalid, "name_required", "name is required")
+	}
+	barcode := strings.TrimSpace(input.Barcode)
+	if err := s.checkBarcode(ctx, barcode, existing.ID); err != nil {
+		return nil, false, err
+	}
+	unit, packSize, err := packaging(input.Unit, input.PackSize)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := checkCostPrice(input.CostPrice); err != nil {
+		return nil, false, err
+	}
+	inStock, read := existing.Quantity > 0, existing.Quantity
+	existing.Update(&name, &input.Description, nil, &input.Price, &input.Quantity)
+	existing.Barcode = barcode
+	existing.Unit, existing.PackSize = unit, packSize
+	if input.CostPrice != nil {
+		existing.CostPrice = input.CostPrice
+	}
+	existing.CategoryID = strings.TrimSpace(input.CategoryID)
+	if err := s.save(ctx, existing, read); err != nil {
+		return nil, false, err
+	}
+	s.publishUpdate(ctx, existing, inStock)
+	return existing, false, nil
+}
+
 // List retrieves all products.
 func (s *Service) List(ctx context.Context) ([]*domain.Product, error) {
 	return s.repo.List(ctx)
@@ -88,7 +183,7 @@ func (s *Service) List(ctx context.Context) ([]*domain.Product, error) {
 func (s *Service) Get(ctx context.Context, id string) (*domain.Product, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
-		return nil, fmt.Errorf("id is required")
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
 	}
 	return s.repo.GetByID(ctx, id)
 }
@@ -97,7 +192,7 @@ func (s *Service) Get(ctx context.Context, id string) (*domain.Product, error) {
 func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*domain.Product, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
-		return nil, fmt.Errorf("id is required")
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
 	}
 
 	product, err := s.repo.GetByID(ctx, id)
@@ -108,7 +203,7 @@ func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*do
 	if input.SKU != nil {
 		newSKU := strings.TrimSpace(*input.SKU)
 		if newSKU == "" {
-			return nil, errors.New("sku cannot be empty")
+			return nil, errcode.New(errcode.Invalid, "sku_empty", "sku cannot be empty")
 		}
 		if newSKU != product.SKU {
 			if _, err := s.repo.GetBySKU(ctx, newSKU); err == nil {
@@ -119,20 +214,214 @@ func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*do
 		}
 		*input.SKU = newSKU
 	}
+	if input.Barcode != nil {
+		barcode := strings.TrimSpace(*input.Barcode)
+		if err := s.checkBarcode(ctx, barcode, product.ID); err != nil {
+			return nil, err
+		}
+		product.Barcode = barcode
+	}
+	if input.Unit != nil {
+		unit, err := domain.ParseUnit(*input.Unit)
+		if err != nil {
+			return nil, err
+		}
+		product.Unit = unit
+	}
+	if input.PackSize != nil {
+		if *input.PackSize < 1 {
+			return nil, domain.ErrInvalidPackSize
+		}
+		product.PackSize = *input.PackSize
+	}
+	if input.CostPrice != nil {
+		if err := checkCostPrice(input.CostPrice); err != nil {
+			return nil, err
+		}
+		product.CostPrice = input.CostPrice
+	}
 
+	inStock, read := product.Quantity > 0, product.Quantity
 	product.Update(input.Name, input.Description, input.SKU, input.Price, input.Quantity)
+	if input.CategoryID != nil {
+		product.CategoryID = strings.TrimSpace(*input.CategoryID)
+	}
+
+	if err := s.save(ctx, product, read); err != nil {
+		return nil, err
+	}
+	s.publishUpdate(ctx, product, inStock)
+	return product, nil
+}
+
+// Lookup finds the product carrying barcode, as read by a scanner.
+func (s *Service) Lookup(ctx context.Context, barcode string) (*domain.Product, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, errcode.New(errcode.Invalid, "barcode_required", "barcode is required")
+	}
+	if !domain.ValidBarcode(barcode) {
+		return nil, domain.ErrInvalidBarcode
+	}
+	return s.repo.GetByBarcode(ctx, barcode)
+}
+
+// checkBarcode validates a barcode for the product with id, which is empty
+// for a new product. An empty barcode means the product has none.
+func (s *Service) checkBarcode(ctx context.Context, barcode, id string) error {
+	if barcode == "" {
+		return nil
+	}
+	if !domain.ValidBarcode(barcode) {
+		return domain.ErrInvalidBarcode
+	}
+	existing, err := s.repo.GetByBarcode(ctx, barcode)
+	switch {
+	case err == nil && existing.ID != id:
+		return domain.ErrDuplicateBarcode
+	case err != nil && !errors.Is(err, domain.ErrNotFound):
+		return err
+	}
+	return nil
+}
+
+// packaging validates the unit and pack size of a product being written in
+// full. Omitted values default to pieces, one to a pack.
+func packaging(unitName string, packSize int) (domain.Unit, int, error) {
+	unit, err := domain.ParseUnit(unitName)
+	if err != nil {
+		return "", 0, err
+	}
+	switch {
+	case packSize == 0:
+		packSize = 1
+	case packSize < 0:
+		return "", 0, domain.ErrInvalidPackSize
+	}
+	return unit, packSize, nil
+}
+
+// checkCostPrice rejects negative cost prices; nil means none was given.
+func checkCostPrice(cost *float64) error {
+	if cost != nil && *cost < 0 {
+		return errcode.New(errcode.Invalid, "cost_price_negative", "cost price cannot be negative")
+	}
+	return nil
+}
 
-	if err := s.repo.Update(ctx, product); err != nil {
+// ErrInvalidStockAdjustment wraps stock adjustments that change nothing.
+var ErrInvalidStockAdjustment = errcode.New(errcode.Invalid, "stock_adjustment_invalid", "invalid stock adjustment")
+
+// AdjustStock adds delta, which is negative for a deduction, to the
+// product's quantity. delta is in unit: the product's own unit when empty,
+// or UnitPack for whole packs, which are converted to base units. The change
+// is applied atomically by the repository, so concurrent deductions cannot
+// take the quantity below zero; the one that would fails with
+// ErrInsufficientStock.
+func (s *Service) AdjustStock(ctx context.Context, id string, delta int, unit string) (*domain.Product, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	if delta == 0 {
+		return nil, fmt.Errorf("%w: delta cannot be zero", ErrInvalidStockAdjustment)
+	}
+	if strings.TrimSpace(unit) != "" {
+		product, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if delta, err = product.ToBase(delta, unit); err != nil {
+			return nil, err
+		}
+	}
+	product, err := s.repo.AdjustQuantity(ctx, id, delta, s.nowFunc().UTC())
+	if err != nil {
 		return nil, err
 	}
+	s.publishUpdate(ctx, product, product.Quantity-delta > 0)
 	return product, nil
 }
 
+// StockMoved publishes the update of a product whose stock another
+// repository write moved by delta, such as an order taking its lines'
+// stock. A product deleted since has nothing left to announce.
+func (s *Service) StockMoved(ctx context.Context, id string, delta int) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return
+	}
+	s.publishUpdate(ctx, product, product.Quantity-delta > 0)
+}
+
+// save writes product and moves its stock from read, the quantity it was
+// loaded with, to product.Quantity as a relative adjustment, all in one
+// repository write. Stock moved by others since the read is kept instead of
+// overwritten, and a change that would now leave the quantity negative fails
+// with ErrInsufficientStock with nothing written.
+func (s *Service) save(ctx context.Context, product *domain.Product, read int) error {
+	return s.repo.Update(ctx, product, product.Quantity-read)
+}
+
+// publishUpdate announces a changed product, followed by a stock-out when
+// the product was in stock before the change and is not any more.
+func (s *Service) publishUpdate(ctx context.Context, product *domain.Product, wasInStock bool) {
+	s.events.Publish(ctx, event.New(event.ProductUpdated, product.ID, product))
+	if wasInStock && product.Quantity == 0 {
+		s.events.Publish(ctx, event.New(event.ProductOutOfStock, product.ID, product))
+	}
+}
+
 // Delete removes a product.
 func (s *Service) Delete(ctx context.Context, id string) error {
 	id = strings.TrimSpace(id)
 	if id == "" {
-		return fmt.Errorf("id is required")
+		return errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
 	}
-	return s.repo.Delete(ctx, id)
+	s.events.Publish(ctx, event.New(event.ProductDeleted, id, map[string]string{"id": id}))
+	return nil
+}
+
+// ErrFutureValuation rejects valuation dates that have not happened yet.
+var ErrFutureValuation = errcode.New(errcode.Invalid, "valuation_date_future", "valuation date is in the future")
+
+// StockValuation reports stock value per category, for current stock or, when
+// asOf is set, as recorded in the movement ledger at that instant.
+func (s *Service) StockValuation(ctx context.Context, asOf *time.Time) (*domain.StockValuation, error) {
+	if asOf != nil && asOf.After(s.nowFunc()) {
+		return nil, ErrFutureValuation
+	}
+	if asOf != nil {
+		utc := asOf.UTC()
+		asOf = &utc
+	}
+	lines, err := s.repo.StockValuation(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.StockValuation{AsOf: asOf, Lines: lines}
+	if report.Lines == nil {
+		report.Lines = []domain.ValuationLine{}
+	}
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		line.Value = roundCents(line.Value)
+		report.TotalProducts += line.Products
+		report.TotalQuantity += line.Quantity
+		report.TotalValue += line.Value
+	}
+	report.TotalValue = roundCents(report.TotalValue)
+	return report, nil
+}
+
+// LowStockCount reports how many products have at most quantity in stock.
+func (s *Service) LowStockCount(ctx context.Context, quantity int) (int, error) {
+	return s.repo.CountLowStock(ctx, quantity)
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
 }
@@ -0,0 +1,345 @@
+package product_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/product"
+	"backoffice/backend/internal/domain/trash"
+	"backoffice/backend/internal/infrastructure/memory"
+	"backoffice/backend/internal/mocks"
+	"backoffice/backend/internal/usecase/product"
+)
+
+// published records the types of the events a service publishes.
+type published struct {
+	mu    sync.Mutex
+	types []string
+}
+
+func (p *published) Publish(_ context.Context, e event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.types = append(p.types, e.Type)
+}
+
+func (p *published) count(eventType string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, t := range p.types {
+		if t == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+func newService() (*product.Service, *mocks.ProductRepository, *published) {
+	repo := &mocks.ProductRepository{Fallback: memory.NewProductRepository()}
+	events := &published{}
+	svc := product.NewService(repo)
+	svc.SetPublisher(events)
+	return svc, repo, events
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	svc, _, events := newService()
+
+	created, err := svc.Create(ctx, product.CreateInput{Name: " Sticky rice ", SKU: " RICE-1 ", Barcode: "4006381333931", Price: 2.5, Quantity: 10})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.Name != "Sticky rice" || created.SKU != "RICE-1" || created.Unit != domain.UnitPiece || created.PackSize != 1 {
+		t.Fatalf("Create = %+v, want trimmed fields and piece packaging", created)
+	}
+	if n := events.count(event.ProductCreated); n != 1 {
+		t.Fatalf("%d %s events, want 1", n, event.ProductCreated)
+	}
+
+	negative := -1.0
+	for _, tc := range []struct {
+		name  string
+		input product.CreateInput
+		want  error
+	}{
+		{"duplicate SKU", product.CreateInput{Name: "Rice", SKU: "RICE-1"}, domain.ErrDuplicateSKU},
+		{"duplicate barcode", product.CreateInput{Name: "Rice", SKU: "RICE-2", Barcode: "4006381333931"}, domain.ErrDuplicateBarcode},
+		{"bad check digit", product.CreateInput{Name: "Rice", SKU: "RICE-2", Barcode: "4006381333932"}, domain.ErrInvalidBarcode},
+		{"unknown unit", product.CreateInput{Name: "Rice", SKU: "RICE-2", Unit: "sack"}, domain.ErrInvalidUnit},
+		{"negative pack size", product.CreateInput{Name: "Rice", SKU: "RICE-2", PackSize: -1}, domain.ErrInvalidPackSize},
+	} {
+		if _, err := svc.Create(ctx, tc.input); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if _, err := svc.Create(ctx, product.CreateInput{Name: "Rice", SKU: "RICE-2", CostPrice: &negative}); err == nil {
+		t.Error("negative cost price: want an error")
+	}
+	if _, err := svc.Create(ctx, product.CreateInput{SKU: "RICE-2"}); err == nil {
+		t.Error("missing name: want an error")
+	}
+}
+
+func TestCreateLookupFailure(t *testing.T) {
+	svc, repo, _ := newService()
+	boom := errors.New("database down")
+	repo.GetBySKUFunc = func(context.Context, string) (*domain.Product, error) { return nil, boom }
+
+	if _, err := svc.Create(context.Background(), product.CreateInput{Name: "Rice", SKU: "RICE-1"}); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if n := repo.Count("Create"); n != 0 {
+		t.Fatalf("Create called %d times after the SKU lookup failed", n)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	svc, _, events := newService()
+	rice, err := svc.Create(ctx, product.CreateInput{Name: "Rice", SKU: "RICE-1", Price: 2.5, Quantity: 3})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := svc.Create(ctx, product.CreateInput{Name: "Noodles", SKU: "NOODLE-1"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	price, quantity := 3.0, 0
+	updated, err := svc.Update(ctx, rice.ID, product.UpdateInput{Price: &price, Quantity: &quantity})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Price != 3 || updated.Quantity != 0 {
+		t.Fatalf("Update = %+v", updated)
+	}
+	if n := events.count(event.ProductOutOfStock); n != 1 {
+		t.Fatalf("%d %s events after selling out, want 1", n, event.ProductOutOfStock)
+	}
+
+	taken := "NOODLE-1"
+	if _, err := svc.Update(ctx, rice.ID, product.UpdateInput{SKU: &taken}); !errors.Is(err, domain.ErrDuplicateSKU) {
+		t.Fatalf("taken SKU: err = %v, want %v", err, domain.ErrDuplicateSKU)
+	}
+	if _, err := svc.Update(ctx, "missing", product.UpdateInput{Price: &price}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("missing product: err = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestUpdateAfterConcurrentSale(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newService()
+	rice, err := svc.Create(ctx, product.CreateInput{Name: "Rice", SKU: "RICE-1", Price: 2.5, Quantity: 3})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// Two units sell between the update's read and its write.
+	repo.GetByIDFunc = func(ctx context.Context, id string) (*domain.Product, error) {
+		read, err := repo.Fallback.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := repo.Fallback.AdjustQuantity(ctx, id, -2, time.Now()); err != nil {
+			t.Fatalf("AdjustQuantity: %v", err)
+		}
+		return read, nil
+	}
+
+	price, quantity := 4.0, 0
+	if _, err := svc.Update(ctx, rice.ID, product.UpdateInput{Price: &price, Quantity: &quantity}); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("err = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+	stored, err := repo.Fallback.GetByID(ctx, rice.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Price != 2.5 || stored.Quantity != 1 {
+		t.Fatalf("stored = price %v quantity %d, want the refused update to write nothing", stored.Price, stored.Quantity)
+	}
+}
+
+func TestApplyStockFeed(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, events := newService()
+	rice, err := svc.Create(ctx, product.CreateInput{Name: "Rice", SKU: "RICE-1", Quantity: 3})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// Five units arrive between the feed's read and its write.
+	repo.GetBySKUFunc = func(ctx context.Context, sku string) (*domain.Product, error) {
+		read, err := repo.Fallback.GetBySKU(ctx, sku)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := repo.Fallback.AdjustQuantity(ctx, read.ID, 5, time.Now()); err != nil {
+			t.Fatalf("AdjustQuantity: %v", err)
+		}
+		return read, nil
+	}
+
+	report, err := svc.ApplyStockFeed(ctx, []product.StockLevel{{SKU: "RICE-1", Quantity: 4}, {SKU: "GONE-1", Quantity: 1}})
+	if err != nil {
+		t.Fatalf("ApplyStockFeed: %v", err)
+	}
+	if report.Updated != 1 || report.Unchanged != 0 || !slices.Equal(report.Unknown, []string{"GONE-1"}) {
+		t.Fatalf("report = %+v", report)
+	}
+	stored, err := repo.Fallback.GetByID(ctx, rice.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Quantity != 4 {
+		t.Fatalf("quantity = %d, want the feed's level 4", stored.Quantity)
+	}
+
+	repo.GetBySKUFunc = nil
+	report, err = svc.ApplyStockFeed(ctx, []product.StockLevel{{SKU: "RICE-1", Quantity: 4}})
+	if err != nil {
+		t.Fatalf("ApplyStockFeed: %v", err)
+	}
+	if report.Updated != 0 || report.Unchanged != 1 {
+		t.Fatalf("report = %+v, want the level unchanged", report)
+	}
+	if n := events.count(event.ProductUpdated); n != 1 {
+		t.Fatalf("%d %s events, want 1", n, event.ProductUpdated)
+	}
+}
+
+func TestStockKeepsReservations(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newService()
+	svc.SetReservations(repo.Fallback.(domain.ReservationRepository), time.Hour, time.Hour)
+	rice, err := svc.Create(ctx, product.CreateInput{Name: "Rice", SKU: "RICE-1", Price: 2.5, Quantity: 10})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := svc.Reserve(ctx, rice.ID, "user-1", product.ReserveInput{Quantity: 6}); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	report, err := svc.ApplyStockFeed(ctx, []product.StockLevel{{SKU: "RICE-1", Quantity: 5}})
+	if err != nil {
+		t.Fatalf("ApplyStockFeed: %v", err)
+	}
+	if report.Updated != 0 || !slices.Equal(report.BelowReserved, []string{"RICE-1"}) {
+		t.Fatalf("report = %+v, want RICE-1 below reserved", report)
+	}
+	if _, err := svc.AdjustStock(ctx, rice.ID, -5, ""); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("AdjustStock: err = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+	price, quantity := 4.0, 5
+	if _, err := svc.Update(ctx, rice.ID, product.UpdateInput{Price: &price, Quantity: &quantity}); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("Update: err = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+	stored, err := repo.Fallback.GetByID(ctx, rice.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Quantity != 10 || stored.Price != 2.5 || stored.Reserved != 6 {
+		t.Fatalf("stored = quantity %d price %v reserved %d, want the refused writes to change nothing", stored.Quantity, stored.Price, stored.Reserved)
+	}
+
+	// Lowering to exactly the reserved stock, or adding stock, is allowed.
+	report, err = svc.ApplyStockFeed(ctx, []product.StockLevel{{SKU: "RICE-1", Quantity: 6}})
+	if err != nil || report.Updated != 1 || len(report.BelowReserved) != 0 {
+		t.Fatalf("ApplyStockFeed = %+v, %v; want RICE-1 updated", report, err)
+	}
+	if _, err := svc.AdjustStock(ctx, rice.ID, 1, ""); err != nil {
+		t.Fatalf("AdjustStock up: %v", err)
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newService()
+	water, err := svc.Create(ctx, product.CreateInput{Name: "Water", SKU: "WATER-6", PackSize: 6, Quantity: 4})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	adjusted, err := svc.AdjustStock(ctx, water.ID, 2, domain.UnitPack)
+	if err != nil {
+		t.Fatalf("AdjustStock in packs: %v", err)
+	}
+	if adjusted.Quantity != 16 {
+		t.Fatalf("quantity = %d, want 4 + 2 packs of 6", adjusted.Quantity)
+	}
+	if _, err := svc.AdjustStock(ctx, water.ID, -17, ""); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("overdraw: err = %v, want %v", err, domain.ErrInsufficientStock)
+	}
+	if _, err := svc.AdjustStock(ctx, water.ID, 0, ""); !errors.Is(err, product.ErrInvalidStockAdjustment) {
+		t.Fatalf("zero delta: err = %v, want %v", err, product.ErrInvalidStockAdjustment)
+	}
+	if n := repo.Count("AdjustQuantity"); n != 2 {
+		t.Fatalf("AdjustQuantity called %d times, want 2", n)
+	}
+}
+
+func TestBulkDelete(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, events := newService()
+	var ids []string
+	for _, sku := range []string{"TEA-1", "TEA-2"} {
+		created, err := svc.Create(ctx, product.CreateInput{Name: "Tea", SKU: sku})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	_, err := svc.BulkDelete(ctx, trash.BulkDelete{IDs: append(ids, "missing")})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("missing product: err = %v, want %v", err, domain.ErrNotFound)
+	}
+	if e, _ := errcode.As(err); !slices.Equal(e.Meta["ids"].([]string), []string{"missing"}) {
+		t.Fatalf("missing ids = %v", e.Meta["ids"])
+	}
+	if n := repo.Count("DeleteMany"); n != 0 {
+		t.Fatalf("DeleteMany called %d times with a missing product, want 0", n)
+	}
+
+	report, err := svc.BulkDelete(ctx, trash.BulkDelete{IDs: []string{ids[0], " " + ids[1], ids[0]}})
+	if err != nil {
+		t.Fatalf("BulkDelete: %v", err)
+	}
+	if report.Deleted != 2 || !slices.Equal(report.IDs, ids) {
+		t.Fatalf("BulkDelete = %+v, want both products once", report)
+	}
+	if n := events.count(event.ProductDeleted); n != 2 {
+		t.Fatalf("%d %s events, want one per product", n, event.ProductDeleted)
+	}
+	if _, err := svc.BulkDelete(ctx, trash.BulkDelete{}); !errors.Is(err, trash.ErrInvalidBulkDelete) {
+		t.Fatalf("no ids: err = %v, want %v", err, trash.ErrInvalidBulkDelete)
+	}
+}
+
+func TestStockValuation(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newService()
+	repo.StockValuationFunc = func(context.Context, *time.Time) ([]domain.ValuationLine, error) {
+		return []domain.ValuationLine{
+			{Products: 2, Quantity: 5, Value: 10.004},
+			{Products: 1, Quantity: 1, Value: 0.333},
+		}, nil
+	}
+
+	report, err := svc.StockValuation(ctx, nil)
+	if err != nil {
+		t.Fatalf("StockValuation: %v", err)
+	}
+	if report.TotalProducts != 3 || report.TotalQuantity != 6 || report.TotalValue != 10.33 {
+		t.Fatalf("totals = %d products, %d units, %v", report.TotalProducts, report.TotalQuantity, report.TotalValue)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if _, err := svc.StockValuation(ctx, &future); !errors.Is(err, product.ErrFutureValuation) {
+		t.Fatalf("future date: err = %v, want %v", err, product.ErrFutureValuation)
+	}
+}
@@ -0,0 +1,80 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backoffice/backend/internal/domain/errcode"
+	domain "backoffice/backend/internal/domain/product"
+)
+
+// ErrInvalidStockFeed wraps stock feeds that cannot be applied at all.
+var ErrInvalidStockFeed = errcode.New(errcode.Unprocessable, "stock_feed_invalid", "invalid stock feed")
+
+// StockLevel is a supplier's quantity on hand for one SKU.
+type StockLevel struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// StockFeedReport describes what applying a stock feed changed.
+type StockFeedReport struct {
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	// Unknown lists SKUs that match no product; they are ignored.
+	Unknown []string `json:"unknown"`
+	// BelowReserved lists SKUs whose level is less than the stock held by
+	// their active reservations; their quantity is left as it was.
+	BelowReserved []string `json:"belowReserved"`
+}
+
+// ApplyStockFeed sets the quantity of each product named in levels. The feed
+// is checked in full before any product changes; it is not atomic after
+// that, so a failed write leaves the products before it updated.
+func (s *Service) ApplyStockFeed(ctx context.Context, levels []StockLevel) (*StockFeedReport, error) {
+	seen := map[string]bool{}
+	for i := range levels {
+		levels[i].SKU = strings.TrimSpace(levels[i].SKU)
+		level := levels[i]
+		switch {
+		case level.SKU == "":
+			return nil, fmt.Errorf("%w: item %d has no sku", ErrInvalidStockFeed, i)
+		case level.Quantity < 0:
+			return nil, fmt.Errorf("%w: sku %s has a negative quantity", ErrInvalidStockFeed, level.SKU)
+		case seen[level.SKU]:
+			return nil, fmt.Errorf("%w: sku %s appears twice", ErrInvalidStockFeed, level.SKU)
+		}
+		seen[level.SKU] = true
+	}
+
+	report := &StockFeedReport{Unknown: []string{}, BelowReserved: []string{}}
+	for _, level := range levels {
+		product, err := s.repo.GetBySKU(ctx, level.SKU)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			report.Unknown = append(report.Unknown, level.SKU)
+			continue
+		case err != nil:
+			return nil, err
+		}
+		// The level is absolute: it is set as-is rather than turned into a
+		// delta against the quantity read above, which may be stale by now.
+		product, previous, err := s.repo.SetQuantity(ctx, product.ID, level.Quantity, s.nowFunc().UTC())
+		if errors.Is(err, domain.ErrInsufficientStock) {
+			report.BelowReserved = append(report.BelowReserved, level.SKU)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("updating sku %s: %w", level.SKU, err)
+		}
+		if previous == level.Quantity {
+			report.Unchanged++
+			continue
+		}
+		s.publishUpdate(ctx, product, previous > 0)
+		report.Updated++
+	}
+	return report, nil
+}
@@ -0,0 +1,80 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"backoffice/backend/internal/domain/errcode"
+	domain "backoffice/backend/internal/domain/product"
+)
+
+// Outcomes of SyncBySKU.
+const (
+	SyncCreated   = "created"
+	SyncUpdated   = "updated"
+	SyncUnchanged = "unchanged"
+)
+
+// SyncBySKU brings the product with sku in line with an external system:
+// it is created from input when missing, otherwise only the fields input
+// sets are overwritten. input.SKU and input.CategoryID are ignored. The
+// returned outcome says which happened; unchanged products are not written
+// and publish no event.
+func (s *Service) SyncBySKU(ctx context.Context, sku string, input UpdateInput) (string, error) {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return "", errcode.New(errcode.Invalid, "sku_required", "sku is required")
+	}
+	if input.Price != nil && *input.Price < 0 {
+		return "", errcode.New(errcode.Invalid, "price_negative", "price cannot be negative")
+	}
+	if input.Quantity != nil && *input.Quantity < 0 {
+		return "", errcode.New(errcode.Invalid, "quantity_negative", "quantity cannot be negative")
+	}
+
+	product, err := s.repo.GetBySKU(ctx, sku)
+	if errors.Is(err, domain.ErrNotFound) {
+		create := CreateInput{SKU: sku}
+		if input.Name != nil {
+			create.Name = *input.Name
+		}
+		if input.Description != nil {
+			create.Description = *input.Description
+		}
+		if input.Price != nil {
+			create.Price = *input.Price
+		}
+		if input.Quantity != nil {
+			create.Quantity = *input.Quantity
+		}
+		if _, err := s.Create(ctx, create); err != nil {
+			return "", err
+		}
+		return SyncCreated, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return "", errcode.New(errcode.Invalid, "name_empty", "name cannot be empty")
+		}
+		input.Name = &name
+	}
+	if (input.Name == nil || *input.Name == product.Name) &&
+		(input.Description == nil || *input.Description == product.Description) &&
+		(input.Price == nil || *input.Price == product.Price) &&
+		(input.Quantity == nil || *input.Quantity == product.Quantity) {
+		return SyncUnchanged, nil
+	}
+	inStock, read := product.Quantity > 0, product.Quantity
+	product.Update(input.Name, input.Description, nil, input.Price, input.Quantity)
+	if err := s.save(ctx, product, read); err != nil {
+		return "", err
+	}
+	s.publishUpdate(ctx, product, inStock)
+	return SyncUpdated, nil
+}
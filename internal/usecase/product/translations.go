@@ -0,0 +1,152 @@
+package product
+
+import (
+	"context"
+	"strings"
+
+	"backoffice/backend/internal/domain/errcode"
+	domain "backoffice/backend/internal/domain/product"
+)
+
+// DefaultLocale is the locale products are written in until
+// SetTranslations is called with the configured one.
+const DefaultLocale = "en"
+
+var (
+	// ErrTranslationsUnavailable is returned when the service has no
+	// translation repository.
+	ErrTranslationsUnavailable = errcode.New(errcode.Unavailable, "translations_unavailable", "product translations are not supported")
+	// ErrTranslationNameRequired rejects a translation without a name.
+	ErrTranslationNameRequired = errcode.New(errcode.Invalid, "product_translation_name_required", "translated name is required")
+	// ErrBaseLocale rejects translating a product into the locale it is
+	// written in; the product itself is updated instead.
+	ErrBaseLocale = errcode.New(errcode.Invalid, "product_translation_base_locale", "products are already written in this locale")
+)
+
+// SetTranslations enables product translations stored in repo. Products'
+// own names and descriptions are in locale.
+func (s *Service) SetTranslations(repo domain.TranslationRepository, locale string) {
+	s.translations = repo
+	if normalized, ok := domain.NormalizeLocale(locale); ok {
+		s.locale = normalized
+	}
+}
+
+// Locale returns the locale products' own names and descriptions are in.
+func (s *Service) Locale() string {
+	return s.locale
+}
+
+// TranslationInput is a product's name and description in one locale.
+type TranslationInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ListTranslations returns every translation of a product.
+func (s *Service) ListTranslations(ctx context.Context, productID string) ([]*domain.Translation, error) {
+	if s.translations == nil {
+		return nil, ErrTranslationsUnavailable
+	}
+	product, err := s.Get(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.translations.ListTranslations(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if translations == nil {
+		translations = []*domain.Translation{}
+	}
+	return translations, nil
+}
+
+// PutTranslation creates or replaces a product's translation in locale.
+func (s *Service) PutTranslation(ctx context.Context, productID, locale, userID string, input TranslationInput) (*domain.Translation, error) {
+	if s.translations == nil {
+		return nil, ErrTranslationsUnavailable
+	}
+	locale, err := s.translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrTranslationNameRequired
+	}
+	product, err := s.Get(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	translation := &domain.Translation{
+		ProductID:   product.ID,
+		Locale:      locale,
+		Name:        name,
+		Description: strings.TrimSpace(input.Description),
+		UpdatedBy:   userID,
+		UpdatedAt:   s.nowFunc().UTC(),
+	}
+	if err := s.translations.PutTranslation(ctx, translation); err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+// DeleteTranslation removes a product's translation in locale.
+func (s *Service) DeleteTranslation(ctx context.Context, productID, locale string) error {
+	if s.translations == nil {
+		return ErrTranslationsUnavailable
+	}
+	locale, err := s.translationLocale(locale)
+	if err != nil {
+		return err
+	}
+	product, err := s.Get(ctx, productID)
+	if err != nil {
+		return err
+	}
+	return s.translations.DeleteTranslation(ctx, product.ID, locale)
+}
+
+func (s *Service) translationLocale(locale string) (string, error) {
+	normalized, ok := domain.NormalizeLocale(locale)
+	if !ok {
+		return "", domain.ErrInvalidLocale.With("locale", strings.TrimSpace(locale))
+	}
+	if normalized == s.locale {
+		return "", ErrBaseLocale.With("locale", normalized)
+	}
+	return normalized, nil
+}
+
+// Translate returns copies of products in the first locale of lang, a
+// comma-separated list of locales, each product has a translation for. A
+// regional locale falls back to its language, and the products' own
+// locale ends the chain.
+func (s *Service) Translate(ctx context.Context, lang string, products []*domain.Product) ([]*domain.Product, error) {
+	if s.translations == nil {
+		return nil, ErrTranslationsUnavailable
+	}
+	chain, err := domain.FallbackChain(lang)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	translations, err := s.translations.ListTranslationsFor(ctx, ids, chain)
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[string][]*domain.Translation)
+	for _, t := range translations {
+		byProduct[t.ProductID] = append(byProduct[t.ProductID], t)
+	}
+	translated := make([]*domain.Product, len(products))
+	for i, p := range products {
+		translated[i] = p.Translated(byProduct[p.ID], chain, s.locale)
+	}
+	return translated, nil
+}
@@ -0,0 +1,436 @@
+// Package promotion manages discount codes and redeems them on orders.
+package promotion
+
+import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	categorydomain "backoffice/backend/internal/domain/category"
+	"backoffice/backend/internal/domain/errcode"
+	orderdomain "backoffice/backend/internal/domain/order"
+	paymentdomain "backoffice/backend/internal/domain/payment"
+	productdomain "backoffice/backend/internal/domain/product"
+	domain "backoffice/backend/internal/domain/promotion"
+	orderusecase "backoffice/backend/internal/usecase/order"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidCode rejects a code that is too short, too long or has
+	// characters that are awkward to type.
+	ErrInvalidCode = errcode.New(errcode.Invalid, "promotion_code_invalid", "code must be 3 to 32 letters, digits, dashes or underscores")
+	// ErrInvalidKind rejects an unknown kind of discount.
+	ErrInvalidKind = errcode.New(errcode.Invalid, "promotion_kind_invalid", "kind must be percentage or fixed").With("supported", domain.Kinds)
+	// ErrInvalidValue rejects a discount that takes nothing off, or more
+	// than everything.
+	ErrInvalidValue = errcode.New(errcode.Invalid, "promotion_value_invalid", "value must be positive, and at most 100 for a percentage")
+	// ErrInvalidMinimum rejects a negative minimum subtotal.
+	ErrInvalidMinimum = errcode.New(errcode.Invalid, "promotion_minimum_invalid", "minimum subtotal must not be negative")
+	// ErrInvalidWindow rejects a validity window that ends before it starts.
+	ErrInvalidWindow = errcode.New(errcode.Invalid, "promotion_window_invalid", "endsAt must be after startsAt")
+	// ErrInvalidUsageLimit rejects a negative usage limit.
+	ErrInvalidUsageLimit = errcode.New(errcode.Invalid, "promotion_usage_limit_invalid", "usage limit must not be negative")
+	// ErrCodeRequired rejects redeeming or checking without a code.
+	ErrCodeRequired = errcode.New(errcode.Invalid, "promotion_code_required", "promotion code is required")
+	// ErrOrderNotDiscountable rejects changing the discount of an order that
+	// is no longer pending or whose payment has started.
+	ErrOrderNotDiscountable = errcode.New(errcode.Conflict, "order_not_discountable", "order discount can no longer be changed")
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// Orders reads the orders codes are redeemed on and records their
+// discount. The order service implements it.
+type Orders interface {
+	Get(ctx context.Context, id string) (*orderdomain.Order, error)
+	SetPromotion(ctx context.Context, id, code string, discount float64) (*orderdomain.Order, error)
+}
+
+// Products reads the products promotions are scoped to. The product
+// service implements it.
+type Products interface {
+	Get(ctx context.Context, id string) (*productdomain.Product, error)
+}
+
+// Categories reads the categories promotions are scoped to. The category
+// service implements it.
+type Categories interface {
+	Get(ctx context.Context, id string) (*categorydomain.Category, error)
+}
+
+// Service manages promotions and redeems them on orders.
+type Service struct {
+	repo       domain.Repository
+	orders     Orders
+	products   Products
+	categories Categories
+	nowFunc    func() time.Time
+}
+
+// NewService constructs a promotion service.
+func NewService(repo domain.Repository, orders Orders, products Products, categories Categories) *Service {
+	return &Service{
+		repo:       repo,
+		orders:     orders,
+		products:   products,
+		categories: categories,
+		nowFunc:    time.Now,
+	}
+}
+
+// Input describes a promotion; saving it replaces every field. Active
+// defaults to true.
+type Input struct {
+	Code        string     `json:"code"`
+	Description string     `json:"description"`
+	Kind        string     `json:"kind"`
+	Value       float64    `json:"value"`
+	MinSubtotal float64    `json:"minSubtotal"`
+	StartsAt    *time.Time `json:"startsAt"`
+	EndsAt      *time.Time `json:"endsAt"`
+	UsageLimit  int        `json:"usageLimit"`
+	ProductIDs  []string   `json:"productIds"`
+	CategoryIDs []string   `json:"categoryIds"`
+	Active      *bool      `json:"active"`
+	CreatedBy   string     `json:"-"`
+}
+
+// apply validates the input and copies it onto p.
+func (s *Service) apply(ctx context.Context, in Input, p *domain.Promotion) error {
+	code := normalizeCode(in.Code)
+	if !codePattern.MatchString(code) {
+		return ErrInvalidCode
+	}
+	kind := strings.ToLower(strings.TrimSpace(in.Kind))
+	if !slices.Contains(domain.Kinds, kind) {
+		return ErrInvalidKind
+	}
+	if in.Value <= 0 || (kind == domain.KindPercentage && in.Value > 100) {
+		return ErrInvalidValue
+	}
+	if in.MinSubtotal < 0 {
+		return ErrInvalidMinimum
+	}
+	if in.StartsAt != nil && in.EndsAt != nil && !in.EndsAt.After(*in.StartsAt) {
+		return ErrInvalidWindow
+	}
+	if in.UsageLimit < 0 {
+		return ErrInvalidUsageLimit
+	}
+	productIDs := cleanIDs(in.ProductIDs)
+	for _, id := range productIDs {
+		if _, err := s.products.Get(ctx, id); err != nil {
+			if errors.Is(err, productdomain.ErrNotFound) {
+				return productdomain.ErrNotFound.With("productId", id)
+			}
+			return err
+		}
+	}
+	categoryIDs := cleanIDs(in.CategoryIDs)
+	for _, id := range categoryIDs {
+		if _, err := s.categories.Get(ctx, id); err != nil {
+			if errors.Is(err, categorydomain.ErrNotFound) {
+				return categorydomain.ErrNotFound.With("categoryId", id)
+			}
+			return err
+		}
+	}
+
+	p.Code = code
+	p.Description = strings.TrimSpace(in.Description)
+	p.Kind = kind
+	p.Value = in.Value
+	p.MinSubtotal = in.MinSubtotal
+	p.StartsAt = utc(in.StartsAt)
+	p.EndsAt = utc(in.EndsAt)
+	p.UsageLimit = in.UsageLimit
+	p.ProductIDs = productIDs
+	p.CategoryIDs = categoryIDs
+	p.Active = in.Active == nil || *in.Active
+	return nil
+}
+
+// Create stores a new promotion.
+func (s *Service) Create(ctx context.Context, input Input) (*domain.Promotion, error) {
+	now := s.nowFunc().UTC()
+	p := &domain.Promotion{
+		ID:        uuid.NewString(),
+		CreatedBy: input.CreatedBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, input, p); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// List returns every promotion ordered by code.
+func (s *Service) List(ctx context.Context) ([]*domain.Promotion, error) {
+	return s.repo.List(ctx)
+}
+
+// Get fetches a promotion by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Promotion, error) {
+	return s.repo.GetByID(ctx, strings.TrimSpace(id))
+}
+
+// Update replaces a promotion's fields. Its usage count is kept.
+func (s *Service) Update(ctx context.Context, id string, input Input) (*domain.Promotion, error) {
+	p, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, input, p); err != nil {
+		return nil, err
+	}
+	p.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Delete removes a promotion. Orders it was redeemed on keep their
+// discount.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, strings.TrimSpace(id))
+}
+
+// Quote is what a promotion takes off a set of lines.
+type Quote struct {
+	Code     string  `json:"code"`
+	Subtotal float64 `json:"subtotal"`
+	// Eligible is the part of the subtotal the promotion covers.
+	Eligible   float64  `json:"eligible"`
+	Discount   float64  `json:"discount"`
+	Total      float64  `json:"total"`
+	ProductIDs []string `json:"productIds"`
+}
+
+// CheckInput asks what a code takes off an existing order, or off lines
+// priced at the products' current prices.
+type CheckInput struct {
+	Code    string                   `json:"code"`
+	OrderID string                   `json:"orderId"`
+	Lines   []orderusecase.LineInput `json:"lines"`
+}
+
+// Check quotes a code without redeeming it, or fails with the reason it
+// cannot be redeemed.
+func (s *Service) Check(ctx context.Context, input CheckInput) (*Quote, error) {
+	p, err := s.byCode(ctx, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	var lines []orderdomain.Line
+	if orderID := strings.TrimSpace(input.OrderID); orderID != "" {
+		order, err := s.orders.Get(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		lines = order.Lines
+	} else if lines, err = s.price(ctx, input.Lines); err != nil {
+		return nil, err
+	}
+	return s.quote(ctx, p, lines)
+}
+
+// price prices lines the way an order would, without taking stock.
+func (s *Service) price(ctx context.Context, in []orderusecase.LineInput) ([]orderdomain.Line, error) {
+	if len(in) == 0 {
+		return nil, orderusecase.ErrLinesRequired
+	}
+	lines := make([]orderdomain.Line, 0, len(in))
+	for _, l := range in {
+		if l.Quantity <= 0 {
+			return nil, orderusecase.ErrInvalidQuantity
+		}
+		product, err := s.products.Get(ctx, strings.TrimSpace(l.ProductID))
+		if err != nil {
+			return nil, err
+		}
+		quantity := l.Quantity
+		if strings.TrimSpace(l.Unit) != "" {
+			if quantity, err = product.ToBase(quantity, l.Unit); err != nil {
+				return nil, err
+			}
+		}
+		lines = append(lines, orderdomain.Line{ProductID: product.ID, Quantity: quantity, UnitPrice: product.Price, Total: roundCents(product.Price * float64(quantity))})
+	}
+	return lines, nil
+}
+
+// quote works out the discount on lines, or fails with the reason the
+// promotion cannot be redeemed on them.
+func (s *Service) quote(ctx context.Context, p *domain.Promotion, lines []orderdomain.Line) (*Quote, error) {
+	if err := p.Redeemable(s.nowFunc().UTC()); err != nil {
+		return nil, err
+	}
+	q := &Quote{Code: p.Code, ProductIDs: []string{}}
+	for _, l := range lines {
+		q.Subtotal += l.Total
+		var categoryID string
+		if len(p.CategoryIDs) > 0 {
+			product, err := s.products.Get(ctx, l.ProductID)
+			if err != nil && !errors.Is(err, productdomain.ErrNotFound) {
+				return nil, err
+			}
+			if product != nil {
+				categoryID = product.CategoryID
+			}
+		}
+		if p.Covers(l.ProductID, categoryID) {
+			q.Eligible += l.Total
+			if !slices.Contains(q.ProductIDs, l.ProductID) {
+				q.ProductIDs = append(q.ProductIDs, l.ProductID)
+			}
+		}
+	}
+	q.Subtotal = roundCents(q.Subtotal)
+	q.Eligible = roundCents(q.Eligible)
+	if len(q.ProductIDs) == 0 || q.Eligible <= 0 {
+		return nil, domain.ErrNotApplicable
+	}
+	if q.Eligible < p.MinSubtotal {
+		return nil, domain.ErrMinimumNotMet.With("minSubtotal", p.MinSubtotal).With("eligible", q.Eligible)
+	}
+	q.Discount = p.DiscountOn(q.Eligible)
+	q.Total = roundCents(q.Subtotal - q.Discount)
+	return q, nil
+}
+
+// RedeemInput names the code to redeem on an order.
+type RedeemInput struct {
+	Code string `json:"code"`
+}
+
+// Redeem takes a use of the code and its discount off the order, replacing
+// any code redeemed on it before. Redeeming the order's current code again
+// changes nothing.
+func (s *Service) Redeem(ctx context.Context, orderID string, input RedeemInput) (*orderdomain.Order, error) {
+	order, err := s.discountable(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.byCode(ctx, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	if order.PromotionCode == p.Code {
+		return order, nil
+	}
+	q, err := s.quote(ctx, p, order.Lines)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Redeem(ctx, p.ID, s.nowFunc().UTC()); err != nil {
+		return nil, err
+	}
+	updated, err := s.orders.SetPromotion(ctx, order.ID, p.Code, q.Discount)
+	if err != nil {
+		return nil, errors.Join(err, s.repo.Release(ctx, p.ID, s.nowFunc().UTC()))
+	}
+	if err := s.release(ctx, order.PromotionCode); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Remove takes the order's code off it and gives the use back.
+func (s *Service) Remove(ctx context.Context, orderID string) (*orderdomain.Order, error) {
+	order, err := s.discountable(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.PromotionCode == "" {
+		return order, nil
+	}
+	updated, err := s.orders.SetPromotion(ctx, order.ID, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.release(ctx, order.PromotionCode); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// discountable fetches an order whose discount may still change: it is
+// pending and no payment for it is pending or went through.
+func (s *Service) discountable(ctx context.Context, orderID string) (*orderdomain.Order, error) {
+	order, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	unpaid := order.PaymentStatus == "" || order.PaymentStatus == orderdomain.PaymentUnpaid || order.PaymentStatus == paymentdomain.StatusFailed
+	if order.Status != orderdomain.StatusPending || !unpaid {
+		return nil, ErrOrderNotDiscountable.With("status", order.Status).With("paymentStatus", order.PaymentStatus)
+	}
+	return order, nil
+}
+
+// release gives back a use of the code. A promotion deleted since has no
+// uses to give back.
+func (s *Service) release(ctx context.Context, code string) error {
+	if code == "" {
+		return nil
+	}
+	p, err := s.repo.GetByCode(ctx, code)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = s.repo.Release(ctx, p.ID, s.nowFunc().UTC())
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *Service) byCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	code = normalizeCode(code)
+	if code == "" {
+		return nil, ErrCodeRequired
+	}
+	return s.repo.GetByCode(ctx, code)
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// cleanIDs trims ids and drops blanks and repeats.
+func cleanIDs(ids []string) []string {
+	cleaned := []string{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !slices.Contains(cleaned, id) {
+			cleaned = append(cleaned, id)
+		}
+	}
+	return cleaned
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
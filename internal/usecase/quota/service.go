@@ -0,0 +1,223 @@
+// Package quota counts the requests API clients make each month against
+// their quotas. Counts are kept in memory and added to the database every
+// flush, so a request costs no database round trip; instances sharing the
+// database see each other's requests after their next flush.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	domain "backoffice/backend/internal/domain/quota"
+	"backoffice/backend/internal/errreport"
+)
+
+var flushTags = map[string]string{"job": "quota-flush"}
+
+// Status is a client's standing after a request.
+type Status struct {
+	// Limit is zero for clients without a quota.
+	Limit     int64
+	Remaining int64
+	ResetsAt  time.Time
+	// Allowed is false when the request is over quota and quotas are
+	// enforced; such requests are not counted.
+	Allowed bool
+}
+
+type usageKey struct {
+	clientID string
+	month    time.Time
+}
+
+// counter is a client's count for the current month.
+type counter struct {
+	month time.Time
+	// stored is the database total as of the last load or flush.
+	stored int64
+	loaded bool
+	warned bool
+}
+
+// Service tracks request counts per client and month.
+type Service struct {
+	repo    domain.Repository
+	nowFunc func() time.Time
+
+	mu       sync.Mutex
+	limits   map[string]int64
+	block    bool
+	counters map[string]*counter
+	// pending holds requests not yet added to the database.
+	pending map[usageKey]int64
+}
+
+// NewService constructs a quota service that tracks no clients until
+// SetLimits names them.
+func NewService(repo domain.Repository) *Service {
+	return &Service{
+		repo:     repo,
+		nowFunc:  time.Now,
+		limits:   map[string]int64{},
+		counters: map[string]*counter{},
+		pending:  map[usageKey]int64{},
+	}
+}
+
+// SetLimits replaces the tracked clients and their monthly limits; a limit
+// of zero counts the client's requests without limiting them.
+func (s *Service) SetLimits(limits map[string]int64) {
+	copied := make(map[string]int64, len(limits))
+	for client, limit := range limits {
+		copied[client] = limit
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = copied
+}
+
+// SetEnforcement rejects requests over quota when block is true and only
+// logs them otherwise.
+func (s *Service) SetEnforcement(block bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.block = block
+}
+
+// Use counts a request by clientID and reports the client's standing.
+// Requests by untracked clients are allowed and not counted.
+func (s *Service) Use(ctx context.Context, clientID string) Status {
+	s.mu.Lock()
+	limit, ok := s.limits[clientID]
+	s.mu.Unlock()
+	if !ok {
+		return Status{Allowed: true}
+	}
+	c, month := s.load(ctx, clientID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := usageKey{clientID: clientID, month: month}
+	used := c.stored + s.pending[key]
+	status := Status{Limit: limit, ResetsAt: month.AddDate(0, 1, 0), Allowed: true}
+	if limit > 0 && used >= limit && s.block {
+		status.Allowed = false
+		return status
+	}
+	s.pending[key]++
+	used++
+	if limit > 0 {
+		status.Remaining = max(limit-used, 0)
+		if used > limit && !c.warned {
+			c.warned = true
+			log.Printf("quota: client %s exceeded its quota of %d requests for %s", clientID, limit, month.Format("2006-01"))
+		}
+	}
+	return status
+}
+
+// Usage returns what clientID has used this month.
+func (s *Service) Usage(ctx context.Context, clientID string) (*domain.Usage, error) {
+	s.mu.Lock()
+	limit, ok := s.limits[clientID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, domain.ErrClientNotFound
+	}
+	c, month := s.load(ctx, clientID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage := &domain.Usage{
+		ClientID: clientID,
+		Month:    month,
+		Requests: c.stored + s.pending[usageKey{clientID: clientID, month: month}],
+		Limit:    limit,
+		ResetsAt: month.AddDate(0, 1, 0),
+		Enforced: s.block && limit > 0,
+	}
+	if limit > 0 {
+		remaining := max(limit-usage.Requests, 0)
+		usage.Remaining = &remaining
+	}
+	return usage, nil
+}
+
+// load returns clientID's counter for the current month, reading the
+// stored total the first time. A failed read is reported and counts from
+// zero until the next flush corrects it, so an unavailable database does
+// not turn away API clients.
+func (s *Service) load(ctx context.Context, clientID string) (*counter, time.Time) {
+	month := domain.MonthOf(s.nowFunc())
+	s.mu.Lock()
+	c := s.counters[clientID]
+	if c == nil || !c.month.Equal(month) {
+		c = &counter{month: month}
+		s.counters[clientID] = c
+	}
+	loaded := c.loaded
+	s.mu.Unlock()
+	if loaded {
+		return c, month
+	}
+
+	stored, err := s.repo.Get(ctx, clientID, month)
+	if err != nil {
+		errreport.Error(ctx, fmt.Errorf("quota: loading usage of client %s: %w", clientID, err), flushTags)
+	}
+	s.mu.Lock()
+	if !c.loaded {
+		c.stored, c.loaded = stored, true
+	}
+	s.mu.Unlock()
+	return c, month
+}
+
+// Flush adds the pending counts to the database. Counts that fail to save
+// are kept for the next flush.
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[usageKey]int64{}
+	s.mu.Unlock()
+
+	var errs []error
+	for key, requests := range pending {
+		total, err := s.repo.Add(ctx, key.clientID, key.month, requests)
+		s.mu.Lock()
+		if err != nil {
+			s.pending[key] += requests
+			errs = append(errs, fmt.Errorf("saving usage of client %s: %w", key.clientID, err))
+		} else if c := s.counters[key.clientID]; c != nil && c.month.Equal(key.month) {
+			c.stored, c.loaded = total, true
+		}
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Run calls Flush every interval until ctx is done, then flushes once more
+// so a shutdown does not lose the last counts.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			if err := s.Flush(flushCtx); err != nil {
+				errreport.Error(flushCtx, fmt.Errorf("quota: %w", err), flushTags)
+			}
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil && ctx.Err() == nil {
+				errreport.Error(ctx, fmt.Errorf("quota: %w", err), flushTags)
+			}
+		}
+	}
+}
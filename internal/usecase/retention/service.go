@@ -0,0 +1,138 @@
+// Package retention applies the retention policies: it lets admins adjust how
+// long each kind of record is kept and purges what has outlived its policy.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	domain "backoffice/backend/internal/domain/retention"
+	"backoffice/backend/internal/errreport"
+)
+
+const (
+	// Day is the unit policies are expressed in.
+	Day = 24 * time.Hour
+	// MaxDays bounds a policy at roughly ten years.
+	MaxDays = 3650
+)
+
+var purgeTags = map[string]string{"job": "retention-purge"}
+
+// Service reads and enforces retention policies.
+type Service struct {
+	repo     domain.Repository
+	purgers  map[string]domain.Purger
+	defaults map[string]int
+	nowFunc  func() time.Time
+}
+
+// NewService constructs a retention service. Purgers are keyed by target;
+// targets without one are not listed or enforced. Defaults apply to targets
+// no admin has set a policy for, and a missing default keeps records forever.
+func NewService(repo domain.Repository, purgers map[string]domain.Purger, defaults map[string]int) *Service {
+	return &Service{repo: repo, purgers: purgers, defaults: defaults, nowFunc: time.Now}
+}
+
+// Policies returns the effective policy for every target.
+func (s *Service) Policies(ctx context.Context) ([]domain.Policy, error) {
+	saved, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byTarget := make(map[string]domain.Policy, len(saved))
+	for _, p := range saved {
+		byTarget[p.Target] = p
+	}
+	policies := []domain.Policy{}
+	for _, target := range domain.Targets {
+		if _, ok := s.purgers[target]; !ok {
+			continue
+		}
+		policy, ok := byTarget[target]
+		if !ok {
+			policy = domain.Policy{Target: target, Days: s.defaults[target]}
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// SetPolicy keeps target's records for days days, 0 meaning forever.
+func (s *Service) SetPolicy(ctx context.Context, target string, days int, actorID string) (domain.Policy, error) {
+	if _, ok := s.purgers[target]; !ok {
+		return domain.Policy{}, domain.ErrUnknownTarget
+	}
+	if days < 0 || days > MaxDays {
+		return domain.Policy{}, errcode.New(errcode.Invalid, "retention_days_invalid", fmt.Sprintf("days must be between 0 and %d", MaxDays))
+	}
+	now := s.nowFunc().UTC()
+	policy := domain.Policy{Target: target, Days: days, UpdatedAt: &now, UpdatedBy: actorID}
+	if err := s.repo.SavePolicy(ctx, policy); err != nil {
+		return domain.Policy{}, err
+	}
+	return policy, nil
+}
+
+// Enforce purges every target's records older than its policy allows. With
+// dryRun nothing is deleted and the results count what would be. A target
+// that fails is reported in its result and does not stop the others.
+func (s *Service) Enforce(ctx context.Context, dryRun bool) ([]domain.Result, error) {
+	policies, err := s.Policies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading retention policies: %w", err)
+	}
+	now := s.nowFunc().UTC()
+	results := make([]domain.Result, 0, len(policies))
+	for _, policy := range policies {
+		result := domain.Result{Target: policy.Target, Days: policy.Days, DryRun: dryRun}
+		if policy.Days > 0 {
+			cutoff := now.Add(-time.Duration(policy.Days) * Day)
+			result.Cutoff = &cutoff
+			purger := s.purgers[policy.Target]
+			if dryRun {
+				result.Count, err = purger.CountBefore(ctx, cutoff)
+			} else {
+				result.Count, err = purger.PurgeBefore(ctx, cutoff)
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Run calls Enforce every interval until ctx is done.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.enforce(ctx); err != nil && ctx.Err() == nil {
+			errreport.Error(ctx, fmt.Errorf("retention: %w", err), purgeTags)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) enforce(ctx context.Context) error {
+	results, err := s.Enforce(ctx, false)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, result := range results {
+		if result.Error != "" {
+			errs = append(errs, fmt.Errorf("purging %s: %s", result.Target, result.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
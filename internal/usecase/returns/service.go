@@ -0,0 +1,229 @@
+// Package returns accepts goods back from shipped orders and restocks them
+// once they arrive.
+package returns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	orderdomain "backoffice/backend/internal/domain/order"
+	productdomain "backoffice/backend/internal/domain/product"
+	domain "backoffice/backend/internal/domain/returns"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrLinesRequired rejects a return without lines.
+	ErrLinesRequired = errcode.New(errcode.Invalid, "return_lines_required", "return must have at least one line")
+	// ErrInvalidQuantity rejects a line that returns nothing.
+	ErrInvalidQuantity = errcode.New(errcode.Invalid, "return_quantity_invalid", "returned quantity must be greater than zero")
+	// ErrOrderNotReturnable rejects returns against orders that have not
+	// shipped.
+	ErrOrderNotReturnable = errcode.New(errcode.Conflict, "order_not_returnable", "only shipped or delivered orders can be returned")
+	// ErrProductNotOnOrder rejects a line for a product the order does not
+	// contain.
+	ErrProductNotOnOrder = errcode.New(errcode.Unprocessable, "return_product_not_on_order", "product is not on the order")
+	// ErrQuantityExceeded rejects a line returning more than was ordered and
+	// not yet returned.
+	ErrQuantityExceeded = errcode.New(errcode.Unprocessable, "return_quantity_exceeded", "return quantity exceeds what is left to return")
+)
+
+// Orders looks up the order a return is made against. The order service
+// implements it.
+type Orders interface {
+	Get(ctx context.Context, id string) (*orderdomain.Order, error)
+}
+
+// Inventory receives returned stock. The product service implements it.
+type Inventory interface {
+	AdjustStock(ctx context.Context, id string, delta int, unit string) (*productdomain.Product, error)
+}
+
+// Service records returns and restocks the goods that come back.
+type Service struct {
+	repo      domain.Repository
+	orders    Orders
+	inventory Inventory
+	events    event.Publisher
+	nowFunc   func() time.Time
+}
+
+// NewService constructs a returns service.
+func NewService(repo domain.Repository, orders Orders, inventory Inventory) *Service {
+	return &Service{
+		repo:      repo,
+		orders:    orders,
+		inventory: inventory,
+		events:    event.Discard,
+		nowFunc:   time.Now,
+	}
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// LineInput returns Quantity units of a product for Reason.
+type LineInput struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason"`
+}
+
+// CreateInput describes a return to request.
+type CreateInput struct {
+	OrderID     string      `json:"orderId"`
+	Lines       []LineInput `json:"lines"`
+	Note        string      `json:"note"`
+	RequestedBy string      `json:"-"`
+}
+
+// Create requests a return of lines from a shipped or delivered order.
+// Lines for the same product are combined, and no product can be returned
+// beyond the quantity ordered less what other open or completed returns
+// already cover.
+func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Return, error) {
+	if len(input.Lines) == 0 {
+		return nil, ErrLinesRequired
+	}
+	order, err := s.orders.Get(ctx, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != orderdomain.StatusShipped && order.Status != orderdomain.StatusDelivered {
+		return nil, ErrOrderNotReturnable.With("status", order.Status)
+	}
+	ordered := map[string]orderdomain.Line{}
+	for _, line := range order.Lines {
+		if existing, ok := ordered[line.ProductID]; ok {
+			line.Quantity += existing.Quantity
+		}
+		ordered[line.ProductID] = line
+	}
+	returned, err := s.repo.Returned(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.nowFunc().UTC()
+	ret := &domain.Return{
+		ID:          uuid.NewString(),
+		OrderID:     order.ID,
+		Status:      domain.StatusRequested,
+		Note:        strings.TrimSpace(input.Note),
+		RequestedBy: input.RequestedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for i, in := range input.Lines {
+		in.ProductID = strings.TrimSpace(in.ProductID)
+		in.Reason = strings.ToLower(strings.TrimSpace(in.Reason))
+		line, ok := ordered[in.ProductID]
+		switch {
+		case in.Quantity <= 0:
+			err = ErrInvalidQuantity
+		case !domain.ValidReason(in.Reason):
+			err = domain.ErrInvalidReason
+		case !ok:
+			err = ErrProductNotOnOrder.With("productId", in.ProductID)
+		case returned[in.ProductID]+in.Quantity > line.Quantity:
+			err = ErrQuantityExceeded.With("productId", in.ProductID).With("returnable", line.Quantity-returned[in.ProductID])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		returned[in.ProductID] += in.Quantity
+		ret.Lines = append(ret.Lines, domain.Line{
+			ProductID: line.ProductID,
+			SKU:       line.SKU,
+			Name:      line.Name,
+			Quantity:  in.Quantity,
+			Reason:    in.Reason,
+		})
+	}
+	if err := s.repo.Create(ctx, ret); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(event.ReturnRequested, ret.ID, ret))
+	return ret, nil
+}
+
+// List returns the returns with the status and for the order, either of
+// which may be empty, newest first.
+func (s *Service) List(ctx context.Context, status, orderID string) ([]*domain.Return, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && !domain.ValidStatus(status) {
+		return nil, domain.ErrInvalidStatus
+	}
+	return s.repo.List(ctx, domain.Filter{Status: status, OrderID: strings.TrimSpace(orderID)})
+}
+
+// Get fetches a return by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Return, error) {
+	return s.repo.GetByID(ctx, strings.TrimSpace(id))
+}
+
+// Approve authorises a requested return, so the goods can be sent back.
+func (s *Service) Approve(ctx context.Context, id, adminID string) (*domain.Return, error) {
+	return s.move(ctx, id, domain.StatusApproved, adminID, "")
+}
+
+// Reject declines a requested return with an optional reason.
+func (s *Service) Reject(ctx context.Context, id, adminID, reason string) (*domain.Return, error) {
+	return s.move(ctx, id, domain.StatusRejected, adminID, strings.TrimSpace(reason))
+}
+
+// Receive records the goods of an approved return arriving and puts them
+// back into stock. Each restock is an ordinary stock adjustment, so it
+// appears in the stock movement ledger. Products deleted since the order
+// was placed are skipped.
+func (s *Service) Receive(ctx context.Context, id, userID string) (*domain.Return, error) {
+	ret, err := s.move(ctx, id, domain.StatusReceived, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, line := range ret.Lines {
+		_, err := s.inventory.AdjustStock(ctx, line.ProductID, line.Quantity, "")
+		if err != nil && !errors.Is(err, productdomain.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("restocking %s: %w", line.SKU, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("return %s received: %w", ret.ID, err)
+	}
+	return ret, nil
+}
+
+func (s *Service) move(ctx context.Context, id, status, by, reason string) (*domain.Return, error) {
+	ret, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from := ret.Status
+	if err := ret.Move(status, by, s.nowFunc().UTC()); err != nil {
+		return nil, err
+	}
+	if status == domain.StatusRejected {
+		ret.Reason = reason
+	}
+	if err := s.repo.Update(ctx, ret, from); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(returnEvents[status], ret.ID, ret))
+	return ret, nil
+}
+
+// returnEvents names the event published when a return reaches a status.
+var returnEvents = map[string]string{
+	domain.StatusApproved: event.ReturnApproved,
+	domain.StatusRejected: event.ReturnRejected,
+	domain.StatusReceived: event.ReturnReceived,
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	domain "backoffice/backend/internal/domain/search"
+)
+
+// Limits on the number of hits returned per group.
+const (
+	DefaultLimit = 5
+	MaxLimit     = 25
+)
+
+// minQueryLength keeps one-letter queries, which match nearly everything
+// and cannot use the trigram indexes, from reaching the database.
+const minQueryLength = 2
+
+var (
+	// ErrQueryTooShort rejects queries below the minimum length.
+	ErrQueryTooShort = fmt.Errorf("q must be at least %d characters", minQueryLength)
+	// ErrUnknownGroup rejects a requested group no source serves.
+	ErrUnknownGroup = errors.New("unknown search group")
+	// ErrInvalidLimit rejects limits outside 1..MaxLimit.
+	ErrInvalidLimit = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+)
+
+// Service searches several repositories at once.
+type Service struct {
+	sources map[string]domain.Source
+}
+
+// NewService constructs a search service over the given sources, keyed by
+// group name.
+func NewService(sources map[string]domain.Source) *Service {
+	return &Service{sources: sources}
+}
+
+// Query is a search request. An empty Groups searches every source.
+type Query struct {
+	Text   string
+	Limit  int
+	Groups []string
+}
+
+// Search runs the query against every requested source concurrently and
+// returns the hits grouped by source. Every searched group is present in the
+// result, empty when nothing matched.
+func (s *Service) Search(ctx context.Context, q Query) (map[string][]domain.Hit, error) {
+	text := strings.TrimSpace(q.Text)
+	if utf8.RuneCountInString(text) < minQueryLength {
+		return nil, ErrQueryTooShort
+	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, ErrInvalidLimit
+	}
+	groups := q.Groups
+	if len(groups) == 0 {
+		for group := range s.sources {
+			groups = append(groups, group)
+		}
+	}
+	for _, group := range groups {
+		if _, ok := s.sources[group]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownGroup, group)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string][]domain.Hit, len(groups))
+		errs    []error
+	)
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group string) {
+			defer wg.Done()
+			hits, err := s.sources[group].Search(ctx, text, limit)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("searching %s: %w", group, err))
+				return
+			}
+			if hits == nil {
+				hits = []domain.Hit{}
+			}
+			results[group] = hits
+		}(group)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
@@ -0,0 +1,417 @@
+// Package shipment buys shipping labels for orders from the configured
+// carriers and keeps the parcels' tracking up to date, by polling the
+// carriers and from their webhooks.
+package shipment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	customerdomain "backoffice/backend/internal/domain/customer"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	orderdomain "backoffice/backend/internal/domain/order"
+	domain "backoffice/backend/internal/domain/shipment"
+	"backoffice/backend/internal/errreport"
+	orderusecase "backoffice/backend/internal/usecase/order"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrOrderNotShippable rejects shipping an order that is not paid yet,
+	// or is cancelled or delivered.
+	ErrOrderNotShippable = errcode.New(errcode.Conflict, "order_not_shippable", "order cannot be shipped")
+	// ErrAddressRequired rejects shipping an order without a customer
+	// address to send it to.
+	ErrAddressRequired = errcode.New(errcode.Unprocessable, "shipment_address_required", "order has no shipping address")
+	// ErrInvalidWeight rejects a negative parcel weight.
+	ErrInvalidWeight = errcode.New(errcode.Invalid, "shipment_weight_invalid", "weight must not be negative")
+)
+
+// shippable lists the order statuses a parcel may be sent for. Shipped
+// orders may get further parcels.
+var shippable = []string{orderdomain.StatusPaid, orderdomain.StatusPicking, orderdomain.StatusShipped}
+
+var pollTags = map[string]string{"job": "shipment-tracking"}
+
+// Orders reads and updates the orders being shipped. The order service
+// implements it.
+type Orders interface {
+	Get(ctx context.Context, id string) (*orderdomain.Order, error)
+	Transition(ctx context.Context, id string, input orderusecase.TransitionInput) (*orderdomain.Order, error)
+	AddTrackingNumber(ctx context.Context, id, number string) (*orderdomain.Order, error)
+}
+
+// Customers reads the customers parcels are sent to. The customer service
+// implements it.
+type Customers interface {
+	Get(ctx context.Context, id string) (*customerdomain.Customer, error)
+	Addresses(ctx context.Context, customerID string) ([]*customerdomain.Address, error)
+	GetAddress(ctx context.Context, customerID, id string) (*customerdomain.Address, error)
+}
+
+// Service creates shipments and applies carriers' tracking updates.
+type Service struct {
+	repo      domain.Repository
+	orders    Orders
+	customers Customers
+	carriers  map[string]domain.Carrier
+	// fallback is the carrier used when a request names none.
+	fallback string
+	from     domain.Address
+	// weight is the parcel weight in grams when a request gives none.
+	weight  int
+	events  event.Publisher
+	nowFunc func() time.Time
+}
+
+// NewService constructs a shipment service sending parcels from the
+// address, weighing weightGrams unless a shipment says otherwise. The
+// first carrier is used when a shipment does not name one.
+func NewService(repo domain.Repository, orders Orders, customers Customers, from domain.Address, weightGrams int, carriers ...domain.Carrier) *Service {
+	s := &Service{
+		repo:      repo,
+		orders:    orders,
+		customers: customers,
+		carriers:  make(map[string]domain.Carrier, len(carriers)),
+		from:      from,
+		weight:    weightGrams,
+		events:    event.Discard,
+		nowFunc:   time.Now,
+	}
+	for _, c := range carriers {
+		s.carriers[c.Name()] = c
+	}
+	if len(carriers) > 0 {
+		s.fallback = carriers[0].Name()
+	}
+	return s
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// Carriers names the configured carriers.
+func (s *Service) Carriers() []string {
+	names := make([]string, 0, len(s.carriers))
+	for name := range s.carriers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Service) carrier(name string) (domain.Carrier, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = s.fallback
+	}
+	c, ok := s.carriers[name]
+	if !ok {
+		return nil, domain.ErrUnknownCarrier.With("supported", s.Carriers())
+	}
+	return c, nil
+}
+
+// CreateInput describes the parcel to send. Without an AddressID it goes
+// to the customer's default shipping address.
+type CreateInput struct {
+	Carrier     string `json:"carrier"`
+	Service     string `json:"service"`
+	AddressID   string `json:"addressId"`
+	WeightGrams int    `json:"weightGrams"`
+	CreatedBy   string `json:"-"`
+}
+
+// Create buys a label for a parcel of the order and adds its tracking
+// number to the order.
+func (s *Service) Create(ctx context.Context, orderID string, input CreateInput) (*domain.Shipment, error) {
+	carrier, err := s.carrier(input.Carrier)
+	if err != nil {
+		return nil, err
+	}
+	if input.WeightGrams < 0 {
+		return nil, ErrInvalidWeight
+	}
+	weight := input.WeightGrams
+	if weight == 0 {
+		weight = s.weight
+	}
+	order, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(shippable, order.Status) {
+		return nil, ErrOrderNotShippable.With("status", order.Status)
+	}
+	to, err := s.recipient(ctx, order, strings.TrimSpace(input.AddressID))
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+	reference := order.Reference
+	if reference == "" {
+		reference = order.ID
+	}
+	label, err := carrier.CreateLabel(ctx, domain.LabelRequest{
+		ShipmentID:  id,
+		OrderID:     order.ID,
+		Reference:   reference,
+		Service:     strings.TrimSpace(input.Service),
+		From:        s.from,
+		To:          *to,
+		WeightGrams: weight,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create %s label: %w", carrier.Name(), err)
+	}
+	now := s.nowFunc().UTC()
+	shipment := &domain.Shipment{
+		ID:             id,
+		OrderID:        order.ID,
+		Carrier:        carrier.Name(),
+		Service:        label.Service,
+		TrackingNumber: label.TrackingNumber,
+		LabelURL:       label.LabelURL,
+		Reference:      label.Reference,
+		Status:         domain.StatusLabelCreated,
+		Events:         []domain.TrackingEvent{{Status: domain.StatusLabelCreated, Description: "Label created", At: now}},
+		CreatedBy:      input.CreatedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.Create(ctx, shipment); err != nil {
+		return nil, err
+	}
+	if _, err := s.orders.AddTrackingNumber(ctx, order.ID, shipment.TrackingNumber); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, event.New(event.ShipmentCreated, shipment.ID, shipment))
+	return shipment, nil
+}
+
+// recipient resolves where the order's parcel goes.
+func (s *Service) recipient(ctx context.Context, order *orderdomain.Order, addressID string) (*domain.Address, error) {
+	if order.CustomerID == "" || s.customers == nil {
+		return nil, ErrAddressRequired
+	}
+	customer, err := s.customers.Get(ctx, order.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	var address *customerdomain.Address
+	if addressID != "" {
+		if address, err = s.customers.GetAddress(ctx, customer.ID, addressID); err != nil {
+			return nil, err
+		}
+	} else {
+		addresses, err := s.customers.Addresses(ctx, customer.ID)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(addresses, func(a *customerdomain.Address) bool { return a.DefaultShipping })
+		if i < 0 {
+			return nil, ErrAddressRequired
+		}
+		address = addresses[i]
+	}
+	name := address.Recipient
+	if name == "" {
+		name = customer.Name
+	}
+	return &domain.Address{
+		Name:       name,
+		Phone:      customer.Phone,
+		Email:      customer.Email,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}, nil
+}
+
+// List returns an order's shipments, newest first.
+func (s *Service) List(ctx context.Context, orderID string) ([]*domain.Shipment, error) {
+	order, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByOrder(ctx, order.ID)
+}
+
+// Get fetches a shipment by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Shipment, error) {
+	return s.repo.GetByID(ctx, strings.TrimSpace(id))
+}
+
+// Webhook applies the tracking update of a carrier's signed webhook.
+// Webhooks about anything else, or about parcels not shipped from here,
+// return nil so they can be acknowledged.
+func (s *Service) Webhook(ctx context.Context, carrierName string, payload []byte, header http.Header) (*domain.Shipment, error) {
+	carrier, err := s.carrier(carrierName)
+	if err != nil {
+		return nil, err
+	}
+	hooks, ok := carrier.(domain.WebhookCarrier)
+	if !ok {
+		return nil, domain.ErrWebhookUnsupported
+	}
+	tracking, err := hooks.Webhook(payload, header)
+	if err != nil {
+		return nil, err
+	}
+	if tracking == nil {
+		return nil, nil
+	}
+	shipment, err := s.repo.GetByTracking(ctx, carrier.Name(), tracking.TrackingNumber)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, shipment, tracking); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// Run polls the carriers for the tracking of open shipments every
+// interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Poll(ctx); err != nil && ctx.Err() == nil {
+			errreport.Error(ctx, fmt.Errorf("shipment tracking: %w", err), pollTags)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the tracking of every shipment that is not delivered or
+// returned and applies what changed. One parcel failing does not stop the
+// others from being polled.
+func (s *Service) Poll(ctx context.Context) error {
+	shipments, err := s.repo.ListOpen(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, shipment := range shipments {
+		if ctx.Err() != nil {
+			break
+		}
+		carrier, ok := s.carriers[shipment.Carrier]
+		if !ok {
+			// Shipments of a carrier no longer configured wait for it.
+			continue
+		}
+		tracking, err := carrier.Track(ctx, shipment)
+		if err == nil {
+			err = s.apply(ctx, shipment, tracking)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("shipment %s: %w", shipment.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply stores the carrier's tracking of a shipment if it changed. A new
+// status is published and moves the order along: a parcel on its way
+// ships the order, a delivered one delivers it.
+func (s *Service) apply(ctx context.Context, shipment *domain.Shipment, tracking *domain.Tracking) error {
+	if tracking == nil || !slices.Contains(domain.Statuses, tracking.Status) {
+		return nil
+	}
+	if domain.Final(shipment.Status) {
+		return nil
+	}
+	changed := tracking.Status != shipment.Status
+	events := shipment.Events
+	// Carriers that report the whole history have it replace what was
+	// stored, behind the label event recorded here; others only report the
+	// new status.
+	if len(tracking.Events) == 0 && changed {
+		events = append(slices.Clip(events), domain.TrackingEvent{Status: tracking.Status, At: s.nowFunc().UTC()})
+	}
+	if len(tracking.Events) > 0 {
+		events = append(events[:0:0], shipment.Events[:min(1, len(shipment.Events))]...)
+		for _, e := range tracking.Events {
+			e.At = e.At.UTC()
+			if len(events) == 0 || e != events[0] {
+				events = append(events, e)
+			}
+		}
+	}
+	if !changed && slices.Equal(events, shipment.Events) {
+		return nil
+	}
+	now := s.nowFunc().UTC()
+	shipment.Status = tracking.Status
+	shipment.Events = events
+	shipment.UpdatedAt = now
+	if shipment.Status == domain.StatusDelivered {
+		shipment.DeliveredAt = &now
+	}
+	if err := s.repo.Update(ctx, shipment); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	s.events.Publish(ctx, event.New(event.ShipmentStatusChanged, shipment.ID, shipment))
+	return s.advanceOrder(ctx, shipment)
+}
+
+// advanceOrder moves the shipment's order up to shipped, or delivered,
+// through each status in between. Orders that cannot move there, such as
+// cancelled ones, are left alone.
+func (s *Service) advanceOrder(ctx context.Context, shipment *domain.Shipment) error {
+	var path []string
+	switch shipment.Status {
+	case domain.StatusInTransit, domain.StatusOutForDelivery:
+		path = []string{orderdomain.StatusPicking, orderdomain.StatusShipped}
+	case domain.StatusDelivered:
+		path = []string{orderdomain.StatusPicking, orderdomain.StatusShipped, orderdomain.StatusDelivered}
+	default:
+		return nil
+	}
+	order, err := s.orders.Get(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	for _, status := range path {
+		if !orderdomain.CanTransition(order.Status, status) {
+			continue
+		}
+		order, err = s.orders.Transition(ctx, order.ID, orderusecase.TransitionInput{
+			Status: status,
+			Note:   fmt.Sprintf("%s shipment %s %s", shipment.Carrier, shipment.TrackingNumber, strings.ReplaceAll(shipment.Status, "_", " ")),
+		})
+		if errors.Is(err, orderdomain.ErrIllegalTransition) || errors.Is(err, orderdomain.ErrStatusChanged) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package stocksync
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	domain "backoffice/backend/internal/domain/stocksync"
+	productusecase "backoffice/backend/internal/usecase/product"
+)
+
+// Product fields a source record can fill.
+const (
+	FieldSKU         = "sku"
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldPrice       = "price"
+	FieldQuantity    = "quantity"
+)
+
+// Fields lists every mappable product field.
+var Fields = []string{FieldSKU, FieldName, FieldDescription, FieldPrice, FieldQuantity}
+
+// Mapping names the source field that holds each product field. Fields it
+// does not mention are read from the source field of the same name.
+type Mapping map[string]string
+
+// NewMapping validates a configured mapping.
+func NewMapping(fields map[string]string) (Mapping, error) {
+	m := Mapping{}
+	for field, source := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !isField(field) {
+			return nil, fmt.Errorf("mapping: unknown product field %q (use %s)", field, strings.Join(Fields, ", "))
+		}
+		if source = strings.TrimSpace(source); source == "" {
+			return nil, fmt.Errorf("mapping: %s needs a source field", field)
+		}
+		m[field] = source
+	}
+	return m, nil
+}
+
+func isField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Mapping) source(field string) string {
+	if source, ok := m[field]; ok {
+		return source
+	}
+	return field
+}
+
+// SKU returns the record's SKU, or "" when it has none.
+func (m Mapping) SKU(record domain.Record) string {
+	return strings.TrimSpace(record[m.source(FieldSKU)])
+}
+
+// Apply converts a record into a product update. Fields missing from the
+// record are left nil so the product keeps its current value.
+func (m Mapping) Apply(record domain.Record) (productusecase.UpdateInput, error) {
+	var input productusecase.UpdateInput
+	if v, ok := record[m.source(FieldName)]; ok {
+		name := strings.TrimSpace(v)
+		input.Name = &name
+	}
+	if v, ok := record[m.source(FieldDescription)]; ok {
+		description := strings.TrimSpace(v)
+		input.Description = &description
+	}
+	if v, ok := record[m.source(FieldPrice)]; ok && strings.TrimSpace(v) != "" {
+		price, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return input, fmt.Errorf("price %q is not a number", v)
+		}
+		input.Price = &price
+	}
+	if v, ok := record[m.source(FieldQuantity)]; ok && strings.TrimSpace(v) != "" {
+		quantity, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return input, fmt.Errorf("quantity %q is not a whole number", v)
+		}
+		input.Quantity = &quantity
+	}
+	return input, nil
+}
@@ -0,0 +1,301 @@
+// Package stocksync pulls product and stock data from external systems
+// through connectors, on a schedule or on demand, and keeps a history of
+// the runs.
+package stocksync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/stocksync"
+	"backoffice/backend/internal/errreport"
+	productusecase "backoffice/backend/internal/usecase/product"
+
+	"github.com/google/uuid"
+)
+
+// Limits on the number of runs returned per request.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// maxProblems caps the rejected records explained on a run.
+const maxProblems = 20
+
+var (
+	// ErrUnknownConnector rejects connector names that are not configured.
+	ErrUnknownConnector = errors.New("unknown connector")
+	// ErrRunInProgress rejects starting a connector that is still running.
+	ErrRunInProgress = errors.New("connector is already running")
+	// ErrInvalidLimit rejects limits outside 1..MaxLimit.
+	ErrInvalidLimit = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+)
+
+// ProductSyncer applies source records to the catalogue.
+type ProductSyncer interface {
+	SyncBySKU(ctx context.Context, sku string, input productusecase.UpdateInput) (string, error)
+}
+
+// Connector is a configured source.
+type Connector struct {
+	Name string
+	Type string
+	// Interval between scheduled runs; zero runs only on demand.
+	Interval time.Duration
+	Mapping  Mapping
+	Source   domain.Connector
+}
+
+// ConnectorInfo describes a connector to admins.
+type ConnectorInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Interval is empty for connectors that only run on demand.
+	Interval string `json:"interval,omitempty"`
+}
+
+// Service runs connectors and records their history.
+type Service struct {
+	runs       domain.RunRepository
+	products   ProductSyncer
+	connectors []Connector
+	timeout    time.Duration
+	events     event.Publisher
+	nowFunc    func() time.Time
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// NewService constructs a sync service; timeout bounds each run.
+func NewService(runs domain.RunRepository, products ProductSyncer, connectors []Connector, timeout time.Duration) *Service {
+	return &Service{
+		runs:       runs,
+		products:   products,
+		connectors: connectors,
+		timeout:    timeout,
+		events:     event.Discard,
+		nowFunc:    time.Now,
+		running:    map[string]bool{},
+	}
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// Connectors lists the configured connectors.
+func (s *Service) Connectors() []ConnectorInfo {
+	infos := make([]ConnectorInfo, 0, len(s.connectors))
+	for _, c := range s.connectors {
+		info := ConnectorInfo{Name: c.Name, Type: c.Type}
+		if c.Interval > 0 {
+			info.Interval = c.Interval.String()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// RunScheduled runs every connector with an interval, first immediately and
+// then once per interval, until ctx is cancelled. A connector still running
+// when its next run is due skips that run.
+func (s *Service) RunScheduled(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, c := range s.connectors {
+		if c.Interval <= 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(c Connector) {
+			defer wg.Done()
+			tags := map[string]string{"job": "stock-sync", "connector": c.Name}
+			ticker := time.NewTicker(c.Interval)
+			defer ticker.Stop()
+			for {
+				if run, err := s.start(ctx, c); err == nil {
+					s.execute(ctx, c, run)
+				} else if !errors.Is(err, ErrRunInProgress) && ctx.Err() == nil {
+					errreport.Error(ctx, fmt.Errorf("stock sync: %w", err), tags)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+}
+
+// Trigger starts connector name in the background and returns its run,
+// still running. The run outlives ctx, bounded by the service timeout.
+func (s *Service) Trigger(ctx context.Context, name string) (*domain.Run, error) {
+	c, ok := s.connector(name)
+	if !ok {
+		return nil, ErrUnknownConnector
+	}
+	run, err := s.start(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	started := *run
+	errreport.Go(context.WithoutCancel(ctx), "stock-sync", func(ctx context.Context) {
+		s.execute(ctx, c, run)
+	})
+	return &started, nil
+}
+
+// ListRuns returns the most recent runs, optionally of one connector.
+func (s *Service) ListRuns(ctx context.Context, connector string, limit int) ([]*domain.Run, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, ErrInvalidLimit
+	}
+	runs, err := s.runs.ListRuns(ctx, domain.RunFilter{Connector: connector, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	if runs == nil {
+		runs = []*domain.Run{}
+	}
+	return runs, nil
+}
+
+// GetRun fetches a run by id.
+func (s *Service) GetRun(ctx context.Context, id string) (*domain.Run, error) {
+	return s.runs.GetRun(ctx, id)
+}
+
+func (s *Service) connector(name string) (Connector, bool) {
+	for _, c := range s.connectors {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Connector{}, false
+}
+
+// start claims the connector and records a running run.
+func (s *Service) start(ctx context.Context, c Connector) (*domain.Run, error) {
+	s.mu.Lock()
+	if s.running[c.Name] {
+		s.mu.Unlock()
+		return nil, ErrRunInProgress
+	}
+	s.running[c.Name] = true
+	s.mu.Unlock()
+
+	run := &domain.Run{
+		ID:        uuid.NewString(),
+		Connector: c.Name,
+		Status:    domain.StatusRunning,
+		StartedAt: s.nowFunc().UTC(),
+		Problems:  []string{},
+	}
+	if err := s.runs.CreateRun(ctx, run); err != nil {
+		s.release(c.Name)
+		return nil, fmt.Errorf("recording run: %w", err)
+	}
+	return run, nil
+}
+
+func (s *Service) release(name string) {
+	s.mu.Lock()
+	delete(s.running, name)
+	s.mu.Unlock()
+}
+
+// execute fetches and applies the connector's records, then records the
+// outcome and releases the connector.
+func (s *Service) execute(ctx context.Context, c Connector, run *domain.Run) {
+	defer s.release(c.Name)
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.apply(ctx, c, run); err != nil {
+		run.Status = domain.StatusFailed
+		run.Error = err.Error()
+	} else {
+		run.Status = domain.StatusSucceeded
+	}
+	finishedAt := s.nowFunc().UTC()
+	run.FinishedAt = &finishedAt
+	// The outcome is recorded even when the run was cut short by ctx.
+	if err := s.runs.UpdateRun(context.WithoutCancel(ctx), run); err != nil {
+		errreport.Error(ctx, fmt.Errorf("stock sync: recording run %s: %w", run.ID, err), map[string]string{"job": "stock-sync", "connector": c.Name})
+	}
+	if run.Status == domain.StatusFailed {
+		s.events.Publish(context.WithoutCancel(ctx), event.New(event.SyncRunFailed, run.ID, run))
+	}
+}
+
+// apply updates the catalogue from the connector's records. Records are
+// deduplicated by SKU, the last one winning, since sources such as CSV
+// drops may list a product more than once.
+func (s *Service) apply(ctx context.Context, c Connector, run *domain.Run) error {
+	records, err := c.Source.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching: %w", err)
+	}
+	run.Fetched = len(records)
+
+	reject := func(format string, args ...any) {
+		run.Rejected++
+		if len(run.Problems) < maxProblems {
+			run.Problems = append(run.Problems, fmt.Sprintf(format, args...))
+		}
+	}
+	var skus []string
+	latest := map[string]domain.Record{}
+	for i, record := range records {
+		sku := c.Mapping.SKU(record)
+		if sku == "" {
+			reject("record %d has no sku", i+1)
+			continue
+		}
+		if _, seen := latest[sku]; seen {
+			run.Duplicates++
+		} else {
+			skus = append(skus, sku)
+		}
+		latest[sku] = record
+	}
+
+	for _, sku := range skus {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		input, err := c.Mapping.Apply(latest[sku])
+		if err != nil {
+			reject("sku %s: %v", sku, err)
+			continue
+		}
+		outcome, err := s.products.SyncBySKU(ctx, sku, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			reject("sku %s: %v", sku, err)
+			continue
+		}
+		switch outcome {
+		case productusecase.SyncCreated:
+			run.Created++
+		case productusecase.SyncUpdated:
+			run.Updated++
+		default:
+			run.Unchanged++
+		}
+	}
+	return nil
+}
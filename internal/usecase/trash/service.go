@@ -0,0 +1,115 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/trash"
+)
+
+// restoredEvents maps each kind to the event published when it is restored.
+var restoredEvents = map[string]string{
+	domain.KindUser:    event.UserRestored,
+	domain.KindProduct: event.ProductRestored,
+}
+
+// Service lists, restores and purges soft-deleted records across
+// repositories.
+type Service struct {
+	bins   map[string]domain.Bin
+	events event.Publisher
+}
+
+// NewService constructs a trash service over the given bins, keyed by kind.
+func NewService(bins map[string]domain.Bin) *Service {
+	return &Service{
+		bins:   bins,
+		events: event.Discard,
+	}
+}
+
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
+// List returns every trashed record, most recently deleted first.
+func (s *Service) List(ctx context.Context) ([]domain.Item, error) {
+	items := []domain.Item{}
+	for kind, bin := range s.bins {
+		deleted, err := bin.ListDeleted(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing trashed %ss: %w", kind, err)
+		}
+		items = append(items, deleted...)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// Restore takes a record out of the trash.
+func (s *Service) Restore(ctx context.Context, kind, id string) error {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	bin, id, err := s.lookup(kind, id)
+	if err != nil {
+		return err
+	}
+	if err := bin.Restore(ctx, id); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, event.New(restoredEvents[kind], id, map[string]string{"id": id}))
+	return nil
+}
+
+// Purge permanently deletes a trashed record.
+func (s *Service) Purge(ctx context.Context, kind, id string) error {
+	bin, id, err := s.lookup(kind, id)
+	if err != nil {
+		return err
+	}
+	return bin.Purge(ctx, id)
+}
+
+// CountBefore counts records trashed before cutoff, for a retention dry
+// run.
+func (s *Service) CountBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	total := 0
+	for kind, bin := range s.bins {
+		count, err := bin.CountDeletedBefore(ctx, cutoff)
+		if err != nil {
+			return total, fmt.Errorf("counting trashed %ss: %w", kind, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// PurgeBefore permanently deletes records trashed before cutoff and returns
+// how many were removed.
+func (s *Service) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	total := 0
+	for kind, bin := range s.bins {
+		purged, err := bin.PurgeDeletedBefore(ctx, cutoff)
+		total += purged
+		if err != nil {
+			return total, fmt.Errorf("purging trashed %ss: %w", kind, err)
+		}
+	}
+	return total, nil
+}
+
+func (s *Service) lookup(kind, id string) (domain.Bin, string, error) {
+	bin, ok := s.bins[strings.ToLower(strings.TrimSpace(kind))]
+	if !ok {
+		return nil, "", domain.ErrUnknownKind
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, "", fmt.Errorf("id is required")
+	}
+	return bin, id, nil
+}
@@ -3,10 +3,15 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	domain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/domain/trash"
+	"backoffice/backend/internal/i18n"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -15,6 +20,7 @@ import (
 // Service provides user management use cases for administrative workflows.
 type Service struct {
 	repo    domain.UserRepository
+	events  event.Publisher
 	nowFunc func() time.Time
 }
 
@@ -22,10 +28,16 @@ type Service struct {
 func NewService(repo domain.UserRepository) *Service {
 	return &Service{
 		repo:    repo,
+		events:  event.Discard,
 		nowFunc: time.Now,
 	}
 }
 
+// SetPublisher routes the service's domain events to p.
+func (s *Service) SetPublisher(p event.Publisher) {
+	s.events = p
+}
+
 // Filter captures supported filters for listing users.
 type Filter struct {
 	Role string
@@ -39,11 +51,24 @@ type CreateInput struct {
 	Role     string
 }
 
+var (
+	// ErrUnsupportedLocale rejects locales without a message catalog.
+	ErrUnsupportedLocale = errcode.New(errcode.Invalid, "locale_unsupported", "unsupported locale")
+	// ErrUnknownTimezone rejects timezones missing from the IANA database.
+	ErrUnknownTimezone = errcode.New(errcode.Invalid, "timezone_unknown", "unknown timezone")
+)
+
 // UpdateInput defines the payload to update a user.
 type UpdateInput struct {
 	Email *string
 	Name  *string
 	Role  *string
+	// Locale is a language with a message catalog, or "" to follow
+	// Accept-Language.
+	Locale *string
+	// Timezone is an IANA zone name such as "Asia/Vientiane", or "" for
+	// UTC.
+	Timezone *string
 }
 
 // List returns users matching the supplied filter.
@@ -68,7 +93,7 @@ func (s *Service) List(ctx context.Context, filter Filter) ([]*domain.User, erro
 func (s *Service) Get(ctx context.Context, id string) (*domain.User, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
-		return nil, errors.New("user id is required")
+		return nil, errcode.New(errcode.Invalid, "user_id_required", "user id required")
 	}
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -83,10 +108,13 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.User,
 	name := strings.TrimSpace(input.Name)
 	password := strings.TrimSpace(input.Password)
 	if email == "" {
-		return nil, errors.New("email is required")
+		return nil, errcode.New(errcode.Invalid, "email_required", "email is required")
 	}
 	if password == "" {
-		return nil, errors.New("password is required")
+		return nil, errcode.New(errcode.Invalid, "password_required", "password is required")
+	}
+	if len(password) > domain.MaxPasswordBytes {
+		return nil, domain.ErrPasswordTooLong
 	}
 
 	role, err := ensureRole(input.Role, true)
@@ -119,6 +147,7 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.User,
 	if err := s.repo.Create(ctx, user); err != nil {
 		return nil, err
 	}
+	s.events.Publish(ctx, event.New(event.UserCreated, user.ID, user.Summary()))
 
 	return sanitizeUser(user), nil
 }
@@ -127,7 +156,7 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.User,
 func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*domain.User, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
-		return nil, errors.New("user id is required")
+		return nil, errcode.New(errcode.Invalid, "user_id_required", "user id required")
 	}
 
 	user, err := s.repo.GetByID(ctx, id)
@@ -138,13 +167,28 @@ func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*do
 	if input.Email != nil {
 		email := strings.TrimSpace(strings.ToLower(*input.Email))
 		if email == "" {
-			return nil, errors.New("email is required")
+			return nil, errcode.New(errcode.Invalid, "email_required", "email is required")
 		}
 		user.Email = email
 	}
 	if input.Name != nil {
 		user.Name = strings.TrimSpace(*input.Name)
 	}
+	if input.Locale != nil {
+		locale := strings.TrimSpace(strings.ToLower(*input.Locale))
+		if catalog := i18n.Default(); locale != "" && !catalog.Supports(locale) {
+			return nil, fmt.Errorf("%w: %q (supported: %s)", ErrUnsupportedLocale.With("supported", catalog.Languages()), locale, strings.Join(catalog.Languages(), ", "))
+		}
+		user.Locale = locale
+	}
+	if input.Timezone != nil {
+		timezone := strings.TrimSpace(*input.Timezone)
+		if _, err := LoadTimezone(timezone); err != nil {
+			return nil, err
+		}
+		user.Timezone = timezone
+	}
+	previousRole := user.Role
 	if input.Role != nil {
 		role, err := ensureRole(*input.Role, true)
 		if err != nil {
@@ -152,22 +196,242 @@ func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*do
 		}
 		user.Role = role
 	}
+	if previousRole == domain.RoleAdmin && user.Role != domain.RoleAdmin {
+		if err := s.ensureOtherAdmin(ctx); err != nil {
+			return nil, err
+		}
+	}
 
 	user.UpdatedAt = s.nowFunc().UTC()
 	if err := s.repo.Update(ctx, user); err != nil {
 		return nil, err
 	}
+	s.events.Publish(ctx, event.New(event.UserUpdated, user.ID, user.Summary()))
+	if user.Role != previousRole {
+		s.events.Publish(ctx, event.New(event.UserRoleChanged, user.ID, map[string]any{
+			"user":         user.Summary(),
+			"previousRole": previousRole,
+		}))
+	}
 
 	return sanitizeUser(user), nil
 }
 
-// Delete removes the target user.
-func (s *Service) Delete(ctx context.Context, id string) error {
+// DeleteMode selects what Delete does with a user.
+type DeleteMode string
+
+const (
+	// DeleteModeTrash moves the user to the trash, from where it can be
+	// restored until it is purged. It is the default.
+	DeleteModeTrash DeleteMode = "trash"
+	// DeleteModeAnonymize scrubs the user's personal data and keeps the
+	// row for good, for users that orders, approvals or the audit trail
+	// still refer to.
+	DeleteModeAnonymize DeleteMode = "anonymize"
+)
+
+// ErrDeleteModeInvalid rejects delete modes other than trash and anonymize.
+var ErrDeleteModeInvalid = errcode.New(errcode.Invalid, "delete_mode_invalid", "mode must be trash or anonymize").With("supported", []DeleteMode{DeleteModeTrash, DeleteModeAnonymize})
+
+// ParseDeleteMode validates a delete mode; empty means DeleteModeTrash.
+func ParseDeleteMode(raw string) (DeleteMode, error) {
+	switch mode := DeleteMode(strings.ToLower(strings.TrimSpace(raw))); mode {
+	case "":
+		return DeleteModeTrash, nil
+	case DeleteModeTrash, DeleteModeAnonymize:
+		return mode, nil
+	}
+	return "", ErrDeleteModeInvalid
+}
+
+// AnonymizedName replaces the name of anonymized users.
+const AnonymizedName = "Anonymized user"
+
+// Delete removes the target user as mode says: to the trash, or by
+// anonymizing it. Either way the last admin cannot be removed.
+func (s *Service) Delete(ctx context.Context, id string, mode DeleteMode) error {
+	mode, err := ParseDeleteMode(string(mode))
+	if err != nil {
+		return err
+	}
 	id = strings.TrimSpace(id)
 	if id == "" {
-		return errors.New("user id is required")
+		return errcode.New(errcode.Invalid, "user_id_required", "user id required")
+	}
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.Role == domain.RoleAdmin {
+		if err := s.ensureOtherAdmin(ctx); err != nil {
+			return err
+		}
+	}
+	if mode == DeleteModeAnonymize {
+		err = s.repo.Anonymize(ctx, anonymize(user, s.nowFunc().UTC()))
+	} else {
+		err = s.repo.Delete(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	s.events.Publish(ctx, event.New(event.UserDeleted, id, map[string]string{"id": id, "mode": string(mode)}))
+	return nil
+}
+
+// anonymize returns user without personal data. The email stays unique and
+// under the reserved .invalid domain, and the empty password hash matches
+// no password.
+func anonymize(user *domain.User, now time.Time) *domain.User {
+	return &domain.User{
+		ID:           user.ID,
+		Email:        "anonymized-" + user.ID + "@anonymized.invalid",
+		Name:         AnonymizedName,
+		Role:         domain.RoleUser,
+		TokenVersion: user.TokenVersion,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    now,
+	}
+}
+
+// BulkDelete moves every user in input to the trash in one transaction. If
+// any of them does not exist nothing is deleted, and the error lists the
+// missing ids under "ids"; deleting every remaining admin fails with
+// ErrLastAdmin. Each deleted user gets its own event, and so its own
+// activity entry.
+func (s *Service) BulkDelete(ctx context.Context, input trash.BulkDelete) (*trash.BulkDeleteReport, error) {
+	ids, err := input.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(users))
+	admins := 0
+	for _, user := range users {
+		found[user.ID] = true
+		if user.Role == domain.RoleAdmin {
+			admins++
+		}
+	}
+	if len(users) < len(ids) {
+		var missing []string
+		for _, id := range ids {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		return nil, domain.ErrUserNotFound.With("ids", missing)
+	}
+	if admins > 0 {
+		count, err := s.AdminCount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if count <= admins {
+			return nil, domain.ErrLastAdmin
+		}
+	}
+
+	if err := s.repo.DeleteMany(ctx, ids); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		s.events.Publish(ctx, event.New(event.UserDeleted, id, map[string]string{"id": id}))
+	}
+	return &trash.BulkDeleteReport{Deleted: len(ids), IDs: ids}, nil
+}
+
+// RevokeTokens invalidates every token issued to the user so far.
+func (s *Service) RevokeTokens(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errcode.New(errcode.Invalid, "user_id_required", "user id required")
+	}
+	return s.repo.BumpTokenVersion(ctx, id)
+}
+
+// ErrStatsWindow rejects a signup history longer than Stats supports.
+var ErrStatsWindow = errcode.New(errcode.Invalid, "stats_days_invalid", "days must be at most 365")
+
+// StatsOptions selects the windows used by Stats.
+type StatsOptions struct {
+	// Days of signup history, including today (default 30, at most 365).
+	Days int
+	// ActiveDays is how recently a user must have logged in to count as
+	// active (default 30).
+	ActiveDays int
+}
+
+// Stats summarises users for the admin dashboard. Signups are reported for
+// every day in the window, including days without any.
+func (s *Service) Stats(ctx context.Context, opts StatsOptions) (*domain.UserStats, error) {
+	if opts.Days <= 0 {
+		opts.Days = 30
+	}
+	if opts.Days > 365 {
+		return nil, ErrStatsWindow
+	}
+	if opts.ActiveDays <= 0 {
+		opts.ActiveDays = 30
+	}
+
+	now := s.nowFunc().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	firstDay := today.AddDate(0, 0, -(opts.Days - 1))
+	stats, err := s.repo.Stats(ctx, now.AddDate(0, 0, -opts.ActiveDays), firstDay)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(stats.Signups))
+	for _, day := range stats.Signups {
+		counts[day.Date] = day.Count
+	}
+	stats.Signups = make([]domain.DailyCount, 0, opts.Days)
+	for day := firstDay; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		stats.Signups = append(stats.Signups, domain.DailyCount{Date: date, Count: counts[date]})
+	}
+	for _, role := range []domain.UserRole{domain.RoleUser, domain.RoleAdmin} {
+		if _, ok := stats.ByRole[role]; !ok {
+			stats.ByRole[role] = 0
+		}
+	}
+	return stats, nil
+}
+
+// AdminCount returns the number of users holding the admin role.
+func (s *Service) AdminCount(ctx context.Context) (int, error) {
+	return s.repo.CountByRole(ctx, domain.RoleAdmin)
+}
+
+// ensureOtherAdmin fails with ErrLastAdmin unless an admin would remain after
+// one is demoted or deleted.
+func (s *Service) ensureOtherAdmin(ctx context.Context) error {
+	count, err := s.AdminCount(ctx)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return domain.ErrLastAdmin
+	}
+	return nil
+}
+
+// LoadTimezone resolves an IANA zone name; "" is UTC. "Local" is rejected
+// since it names the server's zone, not the user's.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTimezone, name)
 	}
-	return s.repo.Delete(ctx, id)
+	return location, nil
 }
 
 func ensureRole(raw string, defaultToUser bool) (domain.UserRole, error) {
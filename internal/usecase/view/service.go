@@ -0,0 +1,223 @@
+// Package view manages the list views users save.
+package view
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backoffice/backend/internal/domain/errcode"
+	domain "backoffice/backend/internal/domain/view"
+
+	"github.com/google/uuid"
+)
+
+// Limits on a view.
+const (
+	maxNameLength = 100
+	maxFilters    = 20
+)
+
+var (
+	// ErrNameRequired rejects a view without a name.
+	ErrNameRequired = errcode.New(errcode.Invalid, "view_name_required", "view name is required")
+	// ErrNameTooLong rejects a view name longer than maxNameLength.
+	ErrNameTooLong = errcode.New(errcode.Invalid, "view_name_too_long", "view name is too long").With("maxLength", maxNameLength)
+	// ErrInvalidResource rejects a view of a list views cannot be saved for.
+	ErrInvalidResource = errcode.New(errcode.Invalid, "view_resource_invalid", "views can only be saved for the product and user lists").With("supported", domain.Resources)
+	// ErrTooManyFilters rejects a view with more than maxFilters filters.
+	ErrTooManyFilters = errcode.New(errcode.Invalid, "view_too_many_filters", "view has too many filters").With("max", maxFilters)
+	// ErrInvalidFilter rejects a filter on an unknown field, with an
+	// unknown operator or with a value of the wrong type.
+	ErrInvalidFilter = errcode.New(errcode.Invalid, "view_filter_invalid", "invalid view filter")
+	// ErrInvalidSort rejects sorting on an unknown field.
+	ErrInvalidSort = errcode.New(errcode.Invalid, "view_sort_invalid", "view cannot be sorted on this field")
+	// ErrWrongResource rejects applying a view to another list than the one
+	// it was saved for.
+	ErrWrongResource = errcode.New(errcode.Invalid, "view_resource_mismatch", "view belongs to another list")
+)
+
+// Service manages saved list views. Users only ever see their own.
+type Service struct {
+	repo    domain.Repository
+	nowFunc func() time.Time
+}
+
+// NewService constructs a view service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{repo: repo, nowFunc: time.Now}
+}
+
+// Input describes a view; saving it replaces every field. The resource of
+// an existing view cannot change.
+type Input struct {
+	Resource string          `json:"resource"`
+	Name     string          `json:"name"`
+	Filters  []domain.Filter `json:"filters"`
+	Sort     string          `json:"sort"`
+}
+
+// List returns the user's views of resource, or all of them when resource
+// is empty.
+func (s *Service) List(ctx context.Context, userID, resource string) ([]*domain.View, error) {
+	resource = strings.ToLower(strings.TrimSpace(resource))
+	if resource != "" && !slices.Contains(domain.Resources, resource) {
+		return nil, ErrInvalidResource
+	}
+	views, err := s.repo.List(ctx, userID, resource)
+	if err != nil {
+		return nil, err
+	}
+	if views == nil {
+		views = []*domain.View{}
+	}
+	return views, nil
+}
+
+// Create saves a new view for the user.
+func (s *Service) Create(ctx context.Context, userID string, input Input) (*domain.View, error) {
+	resource := strings.ToLower(strings.TrimSpace(input.Resource))
+	if !slices.Contains(domain.Resources, resource) {
+		return nil, ErrInvalidResource
+	}
+	now := s.nowFunc().UTC()
+	v := &domain.View{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Resource:  resource,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := apply(input, v); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Get fetches one of the user's views.
+func (s *Service) Get(ctx context.Context, userID, id string) (*domain.View, error) {
+	v, err := s.repo.GetByID(ctx, strings.TrimSpace(id))
+	if err != nil {
+		return nil, err
+	}
+	if v.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	return v, nil
+}
+
+// For fetches one of the user's views to apply to the resource list.
+func (s *Service) For(ctx context.Context, userID, id, resource string) (*domain.View, error) {
+	v, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if v.Resource != resource {
+		return nil, ErrWrongResource.With("resource", v.Resource)
+	}
+	return v, nil
+}
+
+// Update replaces one of the user's views.
+func (s *Service) Update(ctx context.Context, userID, id string, input Input) (*domain.View, error) {
+	v, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if resource := strings.ToLower(strings.TrimSpace(input.Resource)); resource != "" && resource != v.Resource {
+		return nil, ErrWrongResource.With("resource", v.Resource)
+	}
+	if err := apply(input, v); err != nil {
+		return nil, err
+	}
+	v.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.Update(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Delete removes one of the user's views.
+func (s *Service) Delete(ctx context.Context, userID, id string) error {
+	v, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, v.ID)
+}
+
+// apply validates the input against v's resource and copies it onto v.
+func apply(input Input, v *domain.View) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return ErrNameRequired
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return ErrNameTooLong
+	}
+	if len(input.Filters) > maxFilters {
+		return ErrTooManyFilters
+	}
+	fields := domain.Fields[v.Resource]
+	filters := make([]domain.Filter, 0, len(input.Filters))
+	for _, f := range input.Filters {
+		f, err := checkFilter(fields, f)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, f)
+	}
+	sortBy := strings.TrimSpace(input.Sort)
+	if field := strings.TrimPrefix(sortBy, "-"); sortBy != "" {
+		if _, ok := fields[field]; !ok {
+			return ErrInvalidSort.With("field", field).With("supported", fieldNames(fields))
+		}
+	}
+	v.Name = name
+	v.Filters = filters
+	v.Sort = sortBy
+	return nil
+}
+
+func checkFilter(fields map[string]string, f domain.Filter) (domain.Filter, error) {
+	f.Field = strings.TrimSpace(f.Field)
+	f.Op = strings.ToLower(strings.TrimSpace(f.Op))
+	typ, ok := fields[f.Field]
+	if !ok {
+		return f, ErrInvalidFilter.With("field", f.Field).With("supported", fieldNames(fields))
+	}
+	if !slices.Contains(domain.Ops, f.Op) || (f.Op == domain.OpContains && typ != domain.TypeString) {
+		return f, ErrInvalidFilter.With("field", f.Field).With("op", f.Op)
+	}
+	switch typ {
+	case domain.TypeNumber:
+		_, ok = f.Value.(float64)
+	case domain.TypeTime:
+		raw, _ := f.Value.(string)
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if ok = err == nil; ok {
+			f.Value = t.UTC().Format(time.RFC3339Nano)
+		}
+	default:
+		_, ok = f.Value.(string)
+	}
+	if !ok {
+		return f, ErrInvalidFilter.With("field", f.Field).With("type", typ)
+	}
+	return f, nil
+}
+
+func fieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
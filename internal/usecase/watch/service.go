@@ -0,0 +1,288 @@
+// Package watch lets users watch products and tells them when the price,
+// stock or status of one changes.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	authdomain "backoffice/backend/internal/domain/auth"
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	"backoffice/backend/internal/domain/mail"
+	productdomain "backoffice/backend/internal/domain/product"
+	domain "backoffice/backend/internal/domain/watch"
+	"backoffice/backend/internal/errreport"
+
+	"github.com/google/uuid"
+)
+
+// QueueSize bounds the product changes waiting to be checked against
+// watches; further ones are dropped.
+const QueueSize = 256
+
+// NotificationLimit caps how many notifications a user is shown.
+const NotificationLimit = 100
+
+var deliveryTags = map[string]string{"job": "product-watches"}
+
+// ErrInvalidField rejects watching a field other than price, stock and
+// status.
+var ErrInvalidField = errcode.New(errcode.Invalid, "watch_field_invalid", "watch fields must be price, stock or status").With("supported", domain.Fields)
+
+// Products loads the products being watched.
+type Products interface {
+	GetByID(ctx context.Context, id string) (*productdomain.Product, error)
+}
+
+// Users looks up whom to email about a change.
+type Users interface {
+	GetByID(ctx context.Context, id string) (*authdomain.User, error)
+}
+
+// Service manages watches. It is an event.Publisher: product events are
+// queued for Run, which compares them with what each watcher was last told
+// and notifies watchers of the fields that changed.
+type Service struct {
+	repo     domain.Repository
+	products Products
+	users    Users
+	sender   mail.Sender
+	queue    chan productChange
+	nowFunc  func() time.Time
+}
+
+// productChange is a product event waiting to be checked. Product is nil
+// when the event does not carry the product.
+type productChange struct {
+	productID string
+	product   *productdomain.Product
+	deleted   bool
+}
+
+// NewService constructs a watch service. Notifications are only kept for
+// users to list until SetSender is called.
+func NewService(repo domain.Repository, products Products) *Service {
+	return &Service{repo: repo, products: products, queue: make(chan productChange, QueueSize), nowFunc: time.Now}
+}
+
+// SetSender also emails each notification to the watcher, looked up in
+// users.
+func (s *Service) SetSender(sender mail.Sender, users Users) {
+	s.sender = sender
+	s.users = users
+}
+
+// Input chooses the fields to watch; none means all of them.
+type Input struct {
+	Fields []string `json:"fields"`
+}
+
+// Watch subscribes the user to changes of a product, or changes the fields
+// an existing watch follows. It reports whether the watch is new.
+func (s *Service) Watch(ctx context.Context, userID, productID string, input Input) (*domain.Watch, bool, error) {
+	fields, err := normalizeFields(input.Fields)
+	if err != nil {
+		return nil, false, err
+	}
+	product, err := s.products.GetByID(ctx, strings.TrimSpace(productID))
+	if err != nil {
+		return nil, false, err
+	}
+	now := s.nowFunc().UTC()
+	w := &domain.Watch{
+		UserID:      userID,
+		ProductID:   product.ID,
+		Fields:      fields,
+		ProductName: product.Name,
+		Last:        snapshotOf(product),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	existing, err := s.repo.Get(ctx, userID, product.ID)
+	switch {
+	case err == nil:
+		w.CreatedAt = existing.CreatedAt
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, false, err
+	}
+	if err := s.repo.Put(ctx, w); err != nil {
+		return nil, false, err
+	}
+	return w, existing == nil, nil
+}
+
+// Unwatch ends the user's watch of a product.
+func (s *Service) Unwatch(ctx context.Context, userID, productID string) error {
+	return s.repo.Delete(ctx, userID, strings.TrimSpace(productID))
+}
+
+// List returns the user's watches, newest first.
+func (s *Service) List(ctx context.Context, userID string) ([]*domain.Watch, error) {
+	watches, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if watches == nil {
+		watches = []*domain.Watch{}
+	}
+	return watches, nil
+}
+
+// Notifications returns the user's latest NotificationLimit notifications,
+// newest first.
+func (s *Service) Notifications(ctx context.Context, userID string) ([]*domain.Notification, error) {
+	notifications, err := s.repo.ListNotifications(ctx, userID, NotificationLimit)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []*domain.Notification{}
+	}
+	return notifications, nil
+}
+
+// ClearNotifications removes every notification of the user.
+func (s *Service) ClearNotifications(ctx context.Context, userID string) error {
+	return s.repo.ClearNotifications(ctx, userID)
+}
+
+// Publish queues product updates, deletions and restores and ignores other
+// events. It never blocks: when the queue is full the change is dropped,
+// and watchers are told about it with the product's next change.
+func (s *Service) Publish(_ context.Context, e event.Event) {
+	var change productChange
+	switch e.Type {
+	case event.ProductUpdated:
+		product, ok := e.Data.(*productdomain.Product)
+		if !ok {
+			return
+		}
+		copied := *product
+		change = productChange{productID: product.ID, product: &copied}
+	case event.ProductDeleted:
+		change = productChange{productID: e.Subject, deleted: true}
+	case event.ProductRestored:
+		change = productChange{productID: e.Subject}
+	default:
+		return
+	}
+	select {
+	case s.queue <- change:
+	default:
+		log.Printf("product watches: queue full, dropping %s of %s", e.Type, e.Subject)
+	}
+}
+
+// Run checks queued changes until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change := <-s.queue:
+			if err := s.notify(ctx, change); err != nil && ctx.Err() == nil {
+				errreport.Error(ctx, fmt.Errorf("product watches: checking %s: %w", change.productID, err), deliveryTags)
+			}
+		}
+	}
+}
+
+// notify tells every watcher of the product about the fields they follow
+// that changed since they were last told, then records what they were
+// told.
+func (s *Service) notify(ctx context.Context, change productChange) error {
+	watches, err := s.repo.ListByProduct(ctx, change.productID)
+	if err != nil || len(watches) == 0 {
+		return err
+	}
+	name, next := watches[0].ProductName, watches[0].Last
+	switch {
+	case change.deleted:
+		next.Status = domain.StatusDeleted
+	case change.product == nil:
+		product, err := s.products.GetByID(ctx, change.productID)
+		if err != nil {
+			return err
+		}
+		name, next = product.Name, snapshotOf(product)
+	default:
+		name, next = change.product.Name, snapshotOf(change.product)
+	}
+
+	now := s.nowFunc().UTC()
+	for _, w := range watches {
+		changes := w.Diff(next)
+		if len(changes) == 0 {
+			continue
+		}
+		n := &domain.Notification{
+			ID:          uuid.NewString(),
+			UserID:      w.UserID,
+			ProductID:   w.ProductID,
+			ProductName: name,
+			Changes:     changes,
+			CreatedAt:   now,
+		}
+		if err := s.repo.AddNotification(ctx, n); err != nil {
+			return err
+		}
+		s.email(ctx, n)
+	}
+	return s.repo.SetLast(ctx, change.productID, name, next)
+}
+
+// email sends n to its user when a sender is configured. Failures are
+// reported and not retried; the notification is kept either way.
+func (s *Service) email(ctx context.Context, n *domain.Notification) {
+	if s.sender == nil {
+		return
+	}
+	user, err := s.users.GetByID(ctx, n.UserID)
+	if err != nil {
+		errreport.Error(ctx, fmt.Errorf("product watches: looking up watcher %s: %w", n.UserID, err), deliveryTags)
+		return
+	}
+	lines := make([]string, len(n.Changes))
+	for i, c := range n.Changes {
+		lines[i] = fmt.Sprintf("%s: %v -> %v", c.Field, c.From, c.To)
+	}
+	msg := mail.Message{
+		To:      []string{user.Email},
+		Subject: "Watched product changed: " + n.ProductName,
+		Text:    fmt.Sprintf("%s (%s) changed:\n\n%s\n", n.ProductName, n.ProductID, strings.Join(lines, "\n")),
+	}
+	if err := s.sender.Send(ctx, msg); err != nil && ctx.Err() == nil {
+		errreport.Error(ctx, fmt.Errorf("product watches: emailing %s: %w", n.UserID, err), deliveryTags)
+	}
+}
+
+func snapshotOf(p *productdomain.Product) domain.Snapshot {
+	return domain.Snapshot{Price: p.Price, Stock: p.Quantity, Status: domain.StatusActive}
+}
+
+// normalizeFields validates fields and returns them in the order of
+// domain.Fields, or all of them when there are none.
+func normalizeFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return slices.Clone(domain.Fields), nil
+	}
+	for i, f := range fields {
+		fields[i] = strings.ToLower(strings.TrimSpace(f))
+		if !slices.Contains(domain.Fields, fields[i]) {
+			return nil, ErrInvalidField.With("field", fields[i])
+		}
+	}
+	normalized := make([]string, 0, len(domain.Fields))
+	for _, f := range domain.Fields {
+		if slices.Contains(fields, f) {
+			normalized = append(normalized, f)
+		}
+	}
+	return normalized, nil
+}
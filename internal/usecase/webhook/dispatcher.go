@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	domain "backoffice/backend/internal/domain/webhook"
+	"backoffice/backend/internal/errreport"
+)
+
+// Delivery headers sent with every webhook request.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+var dispatcherTags = map[string]string{"job": "webhook-dispatcher"}
+
+// DispatcherOptions tunes delivery.
+type DispatcherOptions struct {
+	// MaxAttempts before a delivery is marked failed.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled for each
+	// further attempt up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout bounds each HTTP request.
+	Timeout time.Duration
+	// PollInterval is how often the queue is checked when idle.
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// Dispatcher sends queued deliveries and reschedules failures.
+type Dispatcher struct {
+	service *Service
+	client  *http.Client
+	opts    DispatcherOptions
+}
+
+// NewDispatcher constructs a dispatcher for the service's queue.
+func NewDispatcher(service *Service, opts DispatcherOptions) *Dispatcher {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = 30 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 6 * time.Hour
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 20
+	}
+	return &Dispatcher{
+		service: service,
+		client:  &http.Client{Timeout: opts.Timeout},
+		opts:    opts,
+	}
+}
+
+// Run delivers due webhooks until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.service.wake:
+		}
+	}
+}
+
+func (d *Dispatcher) drain(ctx context.Context) {
+	// The lease must outlast a full batch of timed-out requests.
+	lease := d.opts.Timeout*time.Duration(d.opts.BatchSize) + time.Minute
+	for ctx.Err() == nil {
+		due, err := d.service.repo.ClaimDue(ctx, d.service.nowFunc().UTC(), lease, d.opts.BatchSize)
+		if err != nil {
+			errreport.Error(ctx, fmt.Errorf("webhooks: claiming deliveries: %w", err), dispatcherTags)
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+		for _, delivery := range due {
+			d.attempt(ctx, delivery)
+		}
+	}
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *domain.Delivery) {
+	sub, err := d.service.repo.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		errreport.Error(ctx, fmt.Errorf("webhooks: delivery %s: %w", delivery.ID, err), dispatcherTags)
+		return
+	}
+
+	status, sendErr := d.send(ctx, sub, delivery)
+	now := d.service.nowFunc().UTC()
+	delivery.Attempts++
+	delivery.LastStatusCode = status
+	delivery.UpdatedAt = now
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.StatusSucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= d.opts.MaxAttempts || !sub.Active:
+		delivery.Status = domain.StatusFailed
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.Status = domain.StatusPending
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	}
+	if err := d.service.repo.UpdateDelivery(ctx, delivery); err != nil {
+		errreport.Error(ctx, fmt.Errorf("webhooks: recording delivery %s: %w", delivery.ID, err), dispatcherTags)
+	}
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.opts.InitialBackoff
+	for i := 1; i < attempts && wait < d.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.opts.MaxBackoff)
+}
+
+func (d *Dispatcher) send(ctx context.Context, sub *domain.Subscription, delivery *domain.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(d.service.nowFunc().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "backoffice-webhooks/1")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign computes the hex HMAC-SHA256 of "timestamp.body" with secret.
+// Receivers recompute it and compare in constant time, rejecting stale
+// timestamps to prevent replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/webhook"
+	"backoffice/backend/internal/infrastructure/memory"
+)
+
+// receiver is a webhook endpoint that checks every request's signature and
+// answers with the next of its statuses, repeating the last one.
+type receiver struct {
+	t        *testing.T
+	secret   string
+	statuses []int
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		rc.t.Errorf("reading delivery: %v", err)
+	}
+	want := "sha256=" + Sign(rc.secret, r.Header.Get(HeaderTimestamp), body)
+	if got := r.Header.Get(HeaderSignature); !hmac.Equal([]byte(got), []byte(want)) {
+		rc.t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := rc.statuses[min(len(rc.requests), len(rc.statuses))-1]
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+// newDispatcher returns a dispatcher on a memory queue whose clock only
+// moves when the returned advance is called.
+func newDispatcher(t *testing.T, opts DispatcherOptions) (*Service, *Dispatcher, func(time.Duration)) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	svc := NewService(memory.NewWebhookRepository())
+	svc.nowFunc = func() time.Time { return now }
+	return svc, NewDispatcher(svc, opts), func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestDispatcherRetriesAndSigns(t *testing.T) {
+	ctx := context.Background()
+	rc := &receiver{t: t, secret: "whsec_test", statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusNoContent}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	svc, dispatcher, advance := newDispatcher(t, DispatcherOptions{MaxAttempts: 5, InitialBackoff: time.Minute, MaxBackoff: 10 * time.Minute})
+	sub, err := svc.Create(ctx, CreateInput{URL: server.URL, Events: []string{event.ProductCreated}, Secret: rc.secret})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	svc.Publish(ctx, event.New(event.ProductCreated, "product-1", map[string]any{"name": "Rice"}))
+	delivery := func() *domain.Delivery {
+		t.Helper()
+		deliveries, err := svc.Deliveries(ctx, sub.ID, 10)
+		if err != nil || len(deliveries) != 1 {
+			t.Fatalf("Deliveries = %d, %v; want one", len(deliveries), err)
+		}
+		return deliveries[0]
+	}
+
+	for i, step := range []struct {
+		wait     time.Duration
+		requests int
+		status   string
+		code     int
+	}{
+		{wait: 0, requests: 1, status: domain.StatusPending, code: http.StatusInternalServerError},
+		// The first retry waits InitialBackoff; nothing is sent before.
+		{wait: 59 * time.Second, requests: 1, status: domain.StatusPending, code: http.StatusInternalServerError},
+		{wait: time.Second, requests: 2, status: domain.StatusPending, code: http.StatusBadGateway},
+		// The second waits twice as long.
+		{wait: time.Minute, requests: 2, status: domain.StatusPending, code: http.StatusBadGateway},
+		{wait: time.Minute, requests: 3, status: domain.StatusSucceeded, code: http.StatusNoContent},
+		{wait: time.Hour, requests: 3, status: domain.StatusSucceeded, code: http.StatusNoContent},
+	} {
+		advance(step.wait)
+		dispatcher.drain(ctx)
+		got := delivery()
+		if rc.count() != step.requests || got.Status != step.status || got.LastStatusCode != step.code || got.Attempts != step.requests {
+			t.Fatalf("step %d: %d requests, delivery %s after %d attempts with %d; want %d requests, %s with %d",
+				i, rc.count(), got.Status, got.Attempts, got.LastStatusCode, step.requests, step.status, step.code)
+		}
+		if step.status == domain.StatusSucceeded && got.LastError != "" {
+			t.Fatalf("step %d: LastError = %q after success", i, got.LastError)
+		}
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	first := rc.requests[0]
+	if got := first.Header.Get(HeaderEvent); got != event.ProductCreated {
+		t.Errorf("%s = %q", HeaderEvent, got)
+	}
+	if got := first.Header.Get(HeaderDelivery); got != delivery().ID {
+		t.Errorf("%s = %q, want the delivery id", HeaderDelivery, got)
+	}
+	if got := rc.requests[2].Header.Get(HeaderTimestamp); got != strconv.FormatInt(time.Date(2026, 3, 1, 8, 3, 0, 0, time.UTC).Unix(), 10) {
+		t.Errorf("retry %s = %s, want the time of the retry", HeaderTimestamp, got)
+	}
+	var body struct {
+		Type          string         `json:"type"`
+		SchemaVersion int            `json:"schemaVersion"`
+		Data          map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rc.bodies[0], &body); err != nil {
+		t.Fatalf("decoding %s: %v", rc.bodies[0], err)
+	}
+	if body.Type != event.ProductCreated || body.SchemaVersion != domain.LatestPayloadVersion || body.Data["name"] != "Rice" {
+		t.Errorf("body = %s", rc.bodies[0])
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	ctx := context.Background()
+	rc := &receiver{t: t, secret: "whsec_test", statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	svc, dispatcher, advance := newDispatcher(t, DispatcherOptions{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Minute})
+	sub, err := svc.Create(ctx, CreateInput{URL: server.URL, Events: []string{domain.AllEvents}, Secret: rc.secret})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	svc.Publish(ctx, event.New(event.ProductDeleted, "product-1", "product-1"))
+	for range 5 {
+		dispatcher.drain(ctx)
+		advance(time.Minute)
+	}
+
+	deliveries, err := svc.Deliveries(ctx, sub.ID, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("Deliveries = %d, %v; want one", len(deliveries), err)
+	}
+	got := deliveries[0]
+	if rc.count() != 3 || got.Status != domain.StatusFailed || got.Attempts != 3 || got.LastStatusCode != http.StatusServiceUnavailable || got.LastError == "" {
+		t.Fatalf("%d requests, delivery %+v; want 3 and failed", rc.count(), got)
+	}
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := NewDispatcher(NewService(memory.NewWebhookRepository()), DispatcherOptions{InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute})
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		4:  4 * time.Minute,
+		5:  5 * time.Minute,
+		40: 5 * time.Minute,
+	} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256("secret", "1700000000.{}") computed independently.
+	const want = "b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163"
+	if got := Sign("secret", "1700000000", []byte("{}")); got != want {
+		t.Fatalf("Sign = %s, want %s", got, want)
+	}
+	if Sign("secret", "1700000001", []byte("{}")) == want {
+		t.Fatal("Sign ignores the timestamp")
+	}
+}
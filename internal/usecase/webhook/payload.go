@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/webhook"
+)
+
+// downgrades converts the data of an event from version v+1 to v, keyed by
+// v. A model change that alters payloads bumps LatestPayloadVersion and adds
+// the shim that undoes it here, so subscriptions pinned to older versions
+// keep receiving what they were built against.
+var downgrades = map[int]func(eventType string, data map[string]any){
+	domain.PayloadV1: func(eventType string, data map[string]any) {
+		if strings.HasPrefix(eventType, "product.") {
+			for _, field := range []string{"barcode", "unit", "packSize", "costPrice"} {
+				delete(data, field)
+			}
+		}
+	},
+}
+
+// envelope is the body of a delivery.
+type envelope struct {
+	event.Event
+	SchemaVersion int `json:"schemaVersion"`
+}
+
+// encodePayload renders e in the given schema version. Data that is not a
+// JSON object, such as a deletion's id, passes through every version as is.
+func encodePayload(e event.Event, version int) ([]byte, error) {
+	if version < domain.LatestPayloadVersion {
+		raw, err := json.Marshal(e.Data)
+		if err != nil {
+			return nil, err
+		}
+		var data map[string]any
+		if json.Unmarshal(raw, &data) == nil && data != nil {
+			for v := domain.LatestPayloadVersion - 1; v >= max(version, domain.PayloadV1); v-- {
+				downgrades[v](e.Type, data)
+			}
+			e.Data = data
+		}
+	}
+	return json.Marshal(envelope{Event: e, SchemaVersion: version})
+}
+
+// validVersion reports whether payloads can be produced in version.
+func validVersion(version int) bool {
+	return version >= domain.PayloadV1 && version <= domain.LatestPayloadVersion
+}
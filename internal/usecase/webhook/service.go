@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"backoffice/backend/internal/domain/errcode"
+	"backoffice/backend/internal/domain/event"
+	domain "backoffice/backend/internal/domain/webhook"
+	"backoffice/backend/internal/errreport"
+
+	"github.com/google/uuid"
+)
+
+// Service manages webhook subscriptions and queues deliveries for events.
+// It implements event.Publisher.
+type Service struct {
+	repo    domain.Repository
+	nowFunc func() time.Time
+	wake    chan struct{}
+}
+
+// NewService constructs a webhook service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{
+		repo:    repo,
+		nowFunc: time.Now,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+var _ event.Publisher = (*Service)(nil)
+
+// CreateInput contains the payload required to register a webhook.
+type CreateInput struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	// Secret signs deliveries; one is generated when empty.
+	Secret string `json:"secret"`
+	// Version pins the payload schema; zero means the latest.
+	Version int `json:"version"`
+}
+
+// UpdateInput encapsulates partial subscription updates.
+type UpdateInput struct {
+	URL         *string   `json:"url"`
+	Events      *[]string `json:"events"`
+	Description *string   `json:"description"`
+	Active      *bool     `json:"active"`
+	Version     *int      `json:"version"`
+	// RotateSecret replaces the signing secret with a newly generated one.
+	RotateSecret bool `json:"rotateSecret"`
+}
+
+// Create registers a subscription. The returned subscription carries the
+// signing secret, which is not exposed again afterwards.
+func (s *Service) Create(ctx context.Context, input CreateInput) (*domain.Subscription, error) {
+	endpoint, err := validateURL(input.URL)
+	if err != nil {
+		return nil, err
+	}
+	events, err := validateEvents(input.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret := strings.TrimSpace(input.Secret)
+	if secret == "" {
+		secret = generateSecret()
+	}
+	version := input.Version
+	if version == 0 {
+		version = domain.LatestPayloadVersion
+	}
+	if !validVersion(version) {
+		return nil, domain.ErrInvalidPayloadVersion.With("supported", supportedVersions())
+	}
+
+	now := s.nowFunc().UTC()
+	sub := &domain.Subscription{
+		ID:          uuid.NewString(),
+		URL:         endpoint,
+		Events:      events,
+		Description: strings.TrimSpace(input.Description),
+		Secret:      secret,
+		Active:      true,
+		Version:     version,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// List retrieves all subscriptions.
+func (s *Service) List(ctx context.Context) ([]*domain.Subscription, error) {
+	return s.repo.ListSubscriptions(ctx)
+}
+
+// Get fetches a subscription by id.
+func (s *Service) Get(ctx context.Context, id string) (*domain.Subscription, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	return s.repo.GetSubscription(ctx, id)
+}
+
+// Update applies partial updates to a subscription.
+func (s *Service) Update(ctx context.Context, id string, input UpdateInput) (*domain.Subscription, error) {
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.URL != nil {
+		endpoint, err := validateURL(*input.URL)
+		if err != nil {
+			return nil, err
+		}
+		sub.URL = endpoint
+	}
+	if input.Events != nil {
+		events, err := validateEvents(*input.Events)
+		if err != nil {
+			return nil, err
+		}
+		sub.Events = events
+	}
+	if input.Description != nil {
+		sub.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Active != nil {
+		sub.Active = *input.Active
+	}
+	if input.Version != nil {
+		if !validVersion(*input.Version) {
+			return nil, domain.ErrInvalidPayloadVersion.With("supported", supportedVersions())
+		}
+		sub.Version = *input.Version
+	}
+	if input.RotateSecret {
+		sub.Secret = generateSecret()
+	}
+	sub.UpdatedAt = s.nowFunc().UTC()
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Delete removes a subscription and its delivery log.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errcode.New(errcode.Invalid, "id_required", "id is required")
+	}
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+// Deliveries returns the most recent deliveries for a subscription.
+func (s *Service) Deliveries(ctx context.Context, subscriptionID string, limit int) ([]*domain.Delivery, error) {
+	if _, err := s.Get(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return s.repo.ListDeliveries(ctx, subscriptionID, limit)
+}
+
+// Redeliver queues a delivery for another attempt straight away, whatever
+// its current status.
+func (s *Service) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*domain.Delivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, strings.TrimSpace(deliveryID))
+	if err != nil {
+		return nil, err
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	now := s.nowFunc().UTC()
+	delivery.Status = domain.StatusPending
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	s.notify()
+	return delivery, nil
+}
+
+// Publish queues a delivery of e for every active subscription interested
+// in its type, in the payload version the subscription is pinned to.
+func (s *Service) Publish(ctx context.Context, e event.Event) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		errreport.Error(ctx, fmt.Errorf("webhooks: listing subscriptions for %s: %w", e.Type, err), nil)
+		return
+	}
+	payloads := map[int][]byte{}
+	now := s.nowFunc().UTC()
+	queued := false
+	for _, sub := range subs {
+		if !sub.Active || !sub.Matches(e.Type) {
+			continue
+		}
+		payload, ok := payloads[sub.Version]
+		if !ok {
+			if payload, err = encodePayload(e, sub.Version); err != nil {
+				errreport.Error(ctx, fmt.Errorf("webhooks: encoding %s as version %d: %w", e.Type, sub.Version, err), nil)
+				continue
+			}
+			payloads[sub.Version] = payload
+		}
+		delivery := &domain.Delivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: sub.ID,
+			EventID:        e.ID,
+			EventType:      e.Type,
+			Payload:        payload,
+			Status:         domain.StatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			errreport.Error(ctx, fmt.Errorf("webhooks: queueing %s for %s: %w", e.Type, sub.ID, err), nil)
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		s.notify()
+	}
+}
+
+// notify wakes the dispatcher without blocking.
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func validateURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errcode.New(errcode.Invalid, "url_required", "url is required")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", errcode.New(errcode.Invalid, "url_invalid", "url must be an absolute http or https URL")
+	}
+	return parsed.String(), nil
+}
+
+func validateEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, errcode.New(errcode.Invalid, "webhook_events_required", "at least one event type is required")
+	}
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(strings.ToLower(e))
+		if e != domain.AllEvents && !slices.Contains(event.Types, e) {
+			return nil, fmt.Errorf("%w: %q", errcode.New(errcode.Invalid, "event_type_unknown", "unknown event type").With("event", e), e)
+		}
+		if !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func supportedVersions() []int {
+	versions := make([]int, 0, domain.LatestPayloadVersion)
+	for v := domain.PayloadV1; v <= domain.LatestPayloadVersion; v++ {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func generateSecret() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return "whsec_" + hex.EncodeToString(buf)
+}
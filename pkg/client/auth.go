@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// LoginInput holds credentials. ClientID and Scope request a restricted
+// token (see TOKEN_CLIENTS on the server); leave them empty for a token
+// with the user's full access.
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	ClientID string `json:"clientId,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+}
+
+// RegisterInput describes a new account. Accept lists the policy versions
+// the user accepts; servers that track consent refuse the account unless it
+// covers every mandatory one (see Required).
+type RegisterInput struct {
+	Email    string      `json:"email"`
+	Password string      `json:"password"`
+	Name     string      `json:"name,omitempty"`
+	Accept   []PolicyRef `json:"accept,omitempty"`
+}
+
+// Login signs in and sends the token with every later request. The
+// credentials are kept to sign in again once the token can no longer be
+// renewed.
+func (c *Client) Login(ctx context.Context, input LoginInput) (*User, error) {
+	var out struct {
+		Token string `json:"token"`
+		User  User   `json:"user"`
+	}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/auth/login", body: input, out: &out, anonymous: true}); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.token = out.Token
+	c.creds = &credentials{email: input.Email, password: input.Password, clientID: input.ClientID, scope: input.Scope}
+	c.mu.Unlock()
+	return &out.User, nil
+}
+
+// Register creates an account. It does not sign in.
+func (c *Client) Register(ctx context.Context, input RegisterInput) (*User, error) {
+	var out struct {
+		User User `json:"user"`
+	}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/auth/register", body: input, out: &out, anonymous: true}); err != nil {
+		return nil, err
+	}
+	return &out.User, nil
+}
+
+// Renew exchanges the current token for a fresh one.
+func (c *Client) Renew(ctx context.Context) error {
+	token, err := c.renew(ctx, c.Token())
+	if err != nil {
+		return err
+	}
+	c.setToken(token)
+	return nil
+}
+
+func (c *Client) renew(ctx context.Context, token string) (string, error) {
+	var out struct {
+		Token string `json:"token"`
+	}
+	body := map[string]string{"token": token}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/auth/renew", body: body, out: &out, anonymous: true}); err != nil {
+		return "", err
+	}
+	return out.Token, nil
+}
+
+// Logout revokes the token on servers that keep sessions and forgets it
+// and the credentials either way.
+func (c *Client) Logout(ctx context.Context) error {
+	token := c.Token()
+	c.mu.Lock()
+	c.token, c.creds = "", nil
+	c.mu.Unlock()
+	if token == "" {
+		return nil
+	}
+	// The token is sent by hand: a rejected logout must not sign back in.
+	_, err := c.send(ctx, request{method: http.MethodPost, path: "/auth/logout"}, nil, token)
+	if IsStatus(err, http.StatusNotImplemented) {
+		// Signed tokens keep no session to revoke.
+		return nil
+	}
+	return err
+}
+
+// refresh replaces rejected, the token a request was refused with, and
+// returns the token to repeat the request with. When another request has
+// already replaced it, that token is used.
+func (c *Client) refresh(ctx context.Context, rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != rejected {
+		return c.token, nil
+	}
+	token, err := c.renew(ctx, rejected)
+	if err != nil && c.creds != nil {
+		var out struct {
+			Token string `json:"token"`
+		}
+		login := LoginInput{Email: c.creds.email, Password: c.creds.password, ClientID: c.creds.clientID, Scope: c.creds.scope}
+		_, err = c.do(ctx, request{method: http.MethodPost, path: "/auth/login", body: login, out: &out, anonymous: true})
+		token = out.Token
+	}
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	return token, nil
+}
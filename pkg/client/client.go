@@ -0,0 +1,260 @@
+// Package client is a typed Go client for the backoffice API, for internal
+// services that would otherwise hand-roll HTTP calls. It covers signing
+// in, users and products.
+//
+// Every call takes a context. Idempotent requests that fail with a
+// network error or a 502, 503 or 504 are retried with exponential
+// backoff, and any request answered 429 is retried after the server's
+// Retry-After. When a request is rejected with 401 the client renews its
+// token, or signs in again with the credentials it last logged in with,
+// and repeats the request once.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the retry policy.
+const (
+	DefaultMaxRetries = 2
+	DefaultBackoff    = 200 * time.Millisecond
+	maxBackoff        = 5 * time.Second
+)
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	http       *http.Client
+	userAgent  string
+	maxRetries int
+	backoff    time.Duration
+
+	mu    sync.Mutex
+	token string
+	creds *credentials
+}
+
+type credentials struct {
+	email, password, clientID, scope string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through hc instead of http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithToken starts the client with a token obtained elsewhere. Without
+// credentials from Login it can only be renewed, not replaced.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithRetries sets how many times a failed request is retried and the
+// delay before the first retry, which doubles for each further one. Zero
+// retries disables retrying.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = max(maxRetries, 0)
+		c.backoff = backoff
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New returns a client for the API at baseURL, e.g.
+// "https://api.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("client: base URL must be an absolute http(s) URL, got %q", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		http:       http.DefaultClient,
+		userAgent:  "backoffice-client",
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token returns the token the client currently sends, if any.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// Error is a response with a 4xx or 5xx status.
+type Error struct {
+	StatusCode int `json:"-"`
+	// Message is the server's error message, translated to the user's
+	// language.
+	Message string `json:"error"`
+	// Code identifies the error independently of the language; it is
+	// empty for errors without one.
+	Code      string         `json:"code"`
+	Meta      map[string]any `json:"meta"`
+	RequestID string         `json:"request_id"`
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("client: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("client: %d: %s", e.StatusCode, e.Message)
+}
+
+// IsStatus reports whether err is an Error with the status code.
+func IsStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// request describes one API call. Out, when set, receives the JSON body of
+// a successful response.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	out    any
+	// anonymous requests neither send the token nor refresh it.
+	anonymous bool
+}
+
+// do sends req, retrying and refreshing the token as described in the
+// package documentation, and returns the successful response's status.
+func (c *Client) do(ctx context.Context, req request) (int, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return 0, fmt.Errorf("client: encoding request: %w", err)
+		}
+	}
+	token := ""
+	if !req.anonymous {
+		token = c.Token()
+	}
+	status, err := c.send(ctx, req, body, token)
+	if !req.anonymous && token != "" && IsStatus(err, http.StatusUnauthorized) {
+		if token, err = c.refresh(ctx, token); err != nil {
+			return 0, err
+		}
+		status, err = c.send(ctx, req, body, token)
+	}
+	return status, err
+}
+
+// send makes the request with retries.
+func (c *Client) send(ctx context.Context, req request, body []byte, token string) (int, error) {
+	for attempt := 0; ; attempt++ {
+		status, wait, err := c.attempt(ctx, req, body, token)
+		if wait < 0 || attempt >= c.maxRetries {
+			return status, err
+		}
+		if wait == 0 {
+			wait = min(c.backoff<<attempt, maxBackoff)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt makes the request once. Wait is negative when the request must
+// not be retried, else the delay the server asked for or zero for the
+// default backoff.
+func (c *Client) attempt(ctx context.Context, req request, body []byte, token string) (status int, wait time.Duration, err error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return 0, -1, fmt.Errorf("client: building request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	idempotent := req.method != http.MethodPost && req.method != http.MethodPatch
+	res, err := c.http.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil || !idempotent {
+			return 0, -1, err
+		}
+		return 0, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		apiErr := &Error{StatusCode: res.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		switch {
+		case res.StatusCode == http.StatusTooManyRequests:
+			return 0, retryAfter(res.Header), apiErr
+		case idempotent && (res.StatusCode == http.StatusBadGateway || res.StatusCode == http.StatusServiceUnavailable || res.StatusCode == http.StatusGatewayTimeout):
+			return 0, retryAfter(res.Header), apiErr
+		}
+		return 0, -1, apiErr
+	}
+	if req.out != nil && res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(req.out); err != nil {
+			return 0, -1, fmt.Errorf("client: decoding %s %s response: %w", req.method, req.path, err)
+		}
+	}
+	return res.StatusCode, -1, nil
+}
+
+// retryAfter returns the delay a Retry-After header in seconds asks for,
+// or zero.
+func retryAfter(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
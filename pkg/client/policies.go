@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Policy is one published version of a policy document such as the terms
+// of service.
+type Policy struct {
+	Document    string    `json:"document"`
+	Version     string    `json:"version"`
+	Mandatory   bool      `json:"mandatory"`
+	URL         string    `json:"url,omitempty"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// Ref returns the reference that accepts the version.
+func (p Policy) Ref() PolicyRef {
+	return PolicyRef{Document: p.Document, Version: p.Version}
+}
+
+// PolicyRef names a policy version.
+type PolicyRef struct {
+	Document string `json:"document"`
+	Version  string `json:"version"`
+}
+
+// Policies returns every published policy version, newest first. Admin
+// only. Servers without consent tracking answer with a 404 Error.
+func (c *Client) Policies(ctx context.Context) ([]Policy, error) {
+	var out struct {
+		Items []Policy `json:"items"`
+	}
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/admin/policies", out: &out}); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// Required returns the newest mandatory version of each document in
+// policies, which are newest first: the versions a new account must accept.
+func Required(policies []Policy) []PolicyRef {
+	var refs []PolicyRef
+	seen := map[string]bool{}
+	for _, p := range policies {
+		if p.Mandatory && !seen[p.Document] {
+			seen[p.Document] = true
+			refs = append(refs, p.Ref())
+		}
+	}
+	return refs
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Product is a product with its stock.
+type Product struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	SKU         string  `json:"sku"`
+	Barcode     string  `json:"barcode,omitempty"`
+	Price       float64 `json:"price"`
+	// CostPrice is only returned to admins.
+	CostPrice  *float64 `json:"costPrice,omitempty"`
+	Unit       string   `json:"unit"`
+	PackSize   int      `json:"packSize"`
+	Quantity   int      `json:"quantity"`
+	Reserved   int      `json:"reserved"`
+	Available  int      `json:"available"`
+	CategoryID string   `json:"categoryId,omitempty"`
+	// Locale is the locale Name and Description are in, when the request
+	// asked for one.
+	Locale    string    `json:"locale,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NewProduct describes a product to create. Unit defaults to piece and
+// PackSize to 1.
+type NewProduct struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	SKU         string   `json:"sku"`
+	Barcode     string   `json:"barcode,omitempty"`
+	Price       float64  `json:"price"`
+	CostPrice   *float64 `json:"costPrice,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	PackSize    int      `json:"packSize,omitempty"`
+	Quantity    int      `json:"quantity"`
+	CategoryID  string   `json:"categoryId,omitempty"`
+}
+
+// ProductUpdate changes the fields that are set. An empty Barcode removes
+// the product's barcode.
+type ProductUpdate struct {
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	SKU         *string  `json:"sku,omitempty"`
+	Barcode     *string  `json:"barcode,omitempty"`
+	Price       *float64 `json:"price,omitempty"`
+	CostPrice   *float64 `json:"costPrice,omitempty"`
+	Unit        *string  `json:"unit,omitempty"`
+	PackSize    *int     `json:"packSize,omitempty"`
+	Quantity    *int     `json:"quantity,omitempty"`
+	CategoryID  *string  `json:"categoryId,omitempty"`
+}
+
+// ProductListOptions narrows and translates a product list.
+type ProductListOptions struct {
+	// Lang lists the locales to read names and descriptions in, most
+	// preferred first, e.g. "lo-LA,th".
+	Lang string
+	// View is the ID of one of the user's saved product views.
+	View string
+}
+
+// ListProducts returns the products, filtered and translated by opts,
+// which may be nil.
+func (c *Client) ListProducts(ctx context.Context, opts *ProductListOptions) ([]Product, error) {
+	query := url.Values{}
+	if opts != nil {
+		if opts.Lang != "" {
+			query.Set("lang", opts.Lang)
+		}
+		if opts.View != "" {
+			query.Set("view", opts.View)
+		}
+	}
+	var out struct {
+		Items []Product `json:"items"`
+	}
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/products", query: query, out: &out}); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+// GetProduct fetches a product.
+func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
+	var out Product
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/products/" + url.PathEscape(id), out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateProduct creates a product.
+func (c *Client) CreateProduct(ctx context.Context, input NewProduct) (*Product, error) {
+	var out Product
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/products", body: input, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateProduct changes a product.
+func (c *Client) UpdateProduct(ctx context.Context, id string, input ProductUpdate) (*Product, error) {
+	var out Product
+	if _, err := c.do(ctx, request{method: http.MethodPatch, path: "/products/" + url.PathEscape(id), body: input, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteProduct moves a product to the trash.
+func (c *Client) DeleteProduct(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/products/" + url.PathEscape(id)})
+	return err
+}
+
+// AdjustStock adds delta units to a product's stock, or deducts them when
+// delta is negative. It fails with a 409 Error rather than take the stock
+// below zero.
+func (c *Client) AdjustStock(ctx context.Context, id string, delta int) (*Product, error) {
+	var out Product
+	body := map[string]int{"delta": delta}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/products/" + url.PathEscape(id) + "/stock", body: body, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,20 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Kinds of records PurgeTrash removes.
+const (
+	TrashUser    = "user"
+	TrashProduct = "product"
+)
+
+// PurgeTrash deletes a trashed record for good. Admin only. It fails with a
+// 404 Error when the record is not in the trash.
+func (c *Client) PurgeTrash(ctx context.Context, kind, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/admin/trash/" + url.PathEscape(kind) + "/" + url.PathEscape(id)})
+	return err
+}
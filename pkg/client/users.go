@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// User is a user account.
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Locale    string    `json:"locale,omitempty"`
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserUpdate changes the fields that are set.
+type UserUpdate struct {
+	Email  *string `json:"email,omitempty"`
+	Name   *string `json:"name,omitempty"`
+	Role   *string `json:"role,omitempty"`
+	Locale *string `json:"locale,omitempty"`
+}
+
+// Me returns the signed-in user.
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var out struct {
+		User User `json:"user"`
+	}
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/users/me/role", out: &out}); err != nil {
+		return nil, err
+	}
+	return &out.User, nil
+}
+
+// ChangePassword changes the signed-in user's password. The credentials
+// kept by Login are updated to match.
+func (c *Client) ChangePassword(ctx context.Context, current, next string) error {
+	body := map[string]string{"current_password": current, "new_password": next}
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/users/change-password", body: body}); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.creds != nil {
+		c.creds.password = next
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// ListUsers returns every user, or those with role when it is not empty.
+// Admin only.
+func (c *Client) ListUsers(ctx context.Context, role string) ([]User, error) {
+	query := url.Values{}
+	if role != "" {
+		query.Set("role", role)
+	}
+	var out struct {
+		Users []User `json:"users"`
+	}
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/admin/users", query: query, out: &out}); err != nil {
+		return nil, err
+	}
+	return out.Users, nil
+}
+
+// GetUser fetches a user. Admin only.
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	var out User
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/admin/users/" + url.PathEscape(id), out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateUser changes a user. Admin only.
+func (c *Client) UpdateUser(ctx context.Context, id string, input UserUpdate) (*User, error) {
+	var out User
+	if _, err := c.do(ctx, request{method: http.MethodPatch, path: "/admin/users/" + url.PathEscape(id), body: input, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteUser moves a user to the trash. Admin only. When the server holds
+// deletions for a second admin, pending reports that the user is only
+// deleted once another admin approves.
+func (c *Client) DeleteUser(ctx context.Context, id string) (pending bool, err error) {
+	status, err := c.do(ctx, request{method: http.MethodDelete, path: "/admin/users/" + url.PathEscape(id)})
+	return status == http.StatusAccepted, err
+}